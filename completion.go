@@ -0,0 +1,408 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+// This file generates shell completion scripts for goyang.  The scripts are
+// generated from the registered formatters and commands, and the flags of
+// goyang and of each format, so they include any format registered with
+// register.
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pborman/getopt"
+)
+
+// shells are the shells for which --completion writes a script.
+var shells = []string{"bash", "zsh", "fish"}
+
+// An option describes a flag for the purpose of completion.
+type option struct {
+	short string // the short name, without the "-", or ""
+	long  string // the long name, without the "--", or ""
+	arg   string // the name of the value, or "" for a boolean flag
+	help  string
+}
+
+// names returns the names of o, with their dashes.
+func (o *option) names() []string {
+	var names []string
+	if o.short != "" {
+		names = append(names, "-"+o.short)
+	}
+	if o.long != "" {
+		names = append(names, "--"+o.long)
+	}
+	return names
+}
+
+// A choice is a possible value of an option.
+type choice struct {
+	value string
+	help  string
+}
+
+// completion holds what is completed by the script for a shell.
+type completion struct {
+	options  []*option
+	choices  map[string][]choice // values of an option, by its long name
+	commands []choice
+}
+
+// kind returns how the value of o is completed: "choice" for the values in
+// c.choices, "dir" for a directory, "file" for a file, or "" if it is not
+// completed.
+func (c *completion) kind(o *option) string {
+	switch {
+	case c.choices[o.long] != nil:
+		return "choice"
+	case strings.Contains(o.arg, "DIR"):
+		return "dir"
+	case strings.Contains(o.arg, "FILE"), o.arg == "CONFIG":
+		return "file"
+	}
+	return ""
+}
+
+// options returns the options of s.  getopt does not provide the names and
+// help of an option, so they are taken from the usage that s prints.
+func options(s *getopt.Set) []*option {
+	var buf bytes.Buffer
+	s.PrintOptions(&buf)
+	var opts []*option
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "-") {
+			// The help of the previous option continues on this line.
+			if n := len(opts); n > 0 && line != "" {
+				opts[n-1].help = strings.TrimSpace(opts[n-1].help + " " + line)
+			}
+			continue
+		}
+		usage, help := line, ""
+		if x := strings.Index(line, "  "); x >= 0 {
+			usage, help = line[:x], strings.TrimSpace(line[x:])
+		}
+		o := &option{help: help}
+		if strings.HasPrefix(usage, "--") {
+			o.long = usage[2:]
+		} else {
+			o.short, usage = usage[1:2], strings.TrimPrefix(usage[2:], ", --")
+			if strings.HasPrefix(usage, " ") {
+				o.arg = usage[1:]
+			} else {
+				o.long = usage
+			}
+		}
+		if x := strings.Index(o.long, "[="); x >= 0 {
+			o.long, o.arg = o.long[:x], strings.TrimSuffix(o.long[x+2:], "]")
+		} else if x := strings.Index(o.long, "="); x >= 0 {
+			o.long, o.arg = o.long[:x], o.long[x+1:]
+		}
+		opts = append(opts, o)
+	}
+	return opts
+}
+
+// newCompletion returns the completion of goyang, whose flags are in s.
+// The flags of each format are added to those in s.
+func newCompletion(s *getopt.Set, formats []string) *completion {
+	c := &completion{
+		choices: map[string][]choice{},
+	}
+	// s has the flags of the format given by --format, if any, so they
+	// are only included once.
+	seen := map[string]bool{}
+	var fopts []*option
+	for _, name := range formats {
+		f := formatters[name]
+		c.choices["format"] = append(c.choices["format"], choice{name, f.help})
+		if f.flags == nil {
+			continue
+		}
+		for _, o := range options(f.flags) {
+			o.help = fmt.Sprintf("%s (%s format)", o.help, name)
+			seen[o.long] = true
+			fopts = append(fopts, o)
+		}
+	}
+	for _, o := range options(s) {
+		if o.long == "" || !seen[o.long] {
+			c.options = append(c.options, o)
+		}
+	}
+	c.options = append(c.options, fopts...)
+	for _, f := range errorFormats {
+		c.choices["error-format"] = append(c.choices["error-format"], choice{value: f})
+	}
+	c.choices["completion"] = nil
+	for _, sh := range shells {
+		c.choices["completion"] = append(c.choices["completion"], choice{value: sh})
+	}
+
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		c.commands = append(c.commands, choice{name, commands[name].help})
+	}
+	return c
+}
+
+// write writes the completion script for shell to w.
+func (c *completion) write(w io.Writer, shell string) error {
+	switch shell {
+	case "bash":
+		c.writeBash(w)
+	case "zsh":
+		c.writeZsh(w)
+	case "fish":
+		c.writeFish(w)
+	default:
+		return fmt.Errorf("%s: unknown shell.  Choices are %s", shell, strings.Join(shells, ", "))
+	}
+	return nil
+}
+
+// values returns the values of choices.
+func values(choices []choice) []string {
+	var vs []string
+	for _, ch := range choices {
+		vs = append(vs, ch.value)
+	}
+	return vs
+}
+
+func (c *completion) writeBash(w io.Writer) {
+	var names, dirs, files, others []string
+	fmt.Fprintf(w, `# bash completion for goyang.  Use it in the current shell with
+#   source <(goyang --completion bash)
+
+_goyang() {
+	local cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]}
+	if ((COMP_CWORD > 1)); then
+		case ${COMP_WORDS[1]} in
+		%s)
+			COMPREPLY=($(compgen -f -- "$cur"))
+			return
+			;;
+		esac
+	fi
+	if [[ $prev == = ]]; then
+		prev=${COMP_WORDS[COMP_CWORD-2]}
+	fi
+	case $prev in
+`, strings.Join(values(c.commands), "|"))
+	for _, o := range c.options {
+		names = append(names, o.names()...)
+		if o.arg == "" {
+			continue
+		}
+		switch c.kind(o) {
+		case "choice":
+			fmt.Fprintf(w, "\t%s)\n\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n\t\treturn\n\t\t;;\n",
+				strings.Join(o.names(), "|"), strings.Join(values(c.choices[o.long]), " "))
+		case "dir":
+			dirs = append(dirs, o.names()...)
+		case "file":
+			files = append(files, o.names()...)
+		default:
+			others = append(others, o.names()...)
+		}
+	}
+	if len(dirs) > 0 {
+		fmt.Fprintf(w, "\t%s)\n\t\tCOMPREPLY=($(compgen -d -- \"$cur\"))\n\t\treturn\n\t\t;;\n", strings.Join(dirs, "|"))
+	}
+	if len(files) > 0 {
+		fmt.Fprintf(w, "\t%s)\n\t\tCOMPREPLY=($(compgen -f -- \"$cur\"))\n\t\treturn\n\t\t;;\n", strings.Join(files, "|"))
+	}
+	if len(others) > 0 {
+		fmt.Fprintf(w, "\t%s)\n\t\treturn\n\t\t;;\n", strings.Join(others, "|"))
+	}
+	fmt.Fprintf(w, `	esac
+	case $cur in
+	-*)
+		COMPREPLY=($(compgen -W %q -- "$cur"))
+		;;
+	*)
+		if ((COMP_CWORD == 1)); then
+			COMPREPLY=($(compgen -W %q -- "$cur"))
+		fi
+		COMPREPLY+=($(compgen -f -X '!*.yang' -- "$cur") $(compgen -d -- "$cur"))
+		;;
+	esac
+}
+
+complete -o filenames -F _goyang goyang
+`, strings.Join(names, " "), strings.Join(values(c.commands), " "))
+}
+
+// zshQuote returns s quoted for zsh with single quotes.
+func zshQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// zshEscape returns s with the characters in chars escaped by a backslash.
+func zshEscape(s, chars string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(chars, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (c *completion) writeZsh(w io.Writer) {
+	fmt.Fprintf(w, `#compdef goyang
+# zsh completion for goyang.  Use it in the current shell with
+#   source <(goyang --completion zsh)
+
+_goyang() {
+	local -a commands
+	commands=(
+`)
+	for _, ch := range c.commands {
+		fmt.Fprintf(w, "\t\t%s\n", zshQuote(zshEscape(ch.value, ":")+":"+ch.help))
+	}
+	fmt.Fprintf(w, `	)
+	if ((CURRENT > 2 && ${commands[(I)${words[2]}:*]})); then
+		_files
+		return
+	fi
+	local state
+	_arguments -s -S \
+`)
+	for _, o := range c.options {
+		var spec string
+		help := "[" + zshEscape(o.help, `[]\`) + "]"
+		switch {
+		case o.short != "" && o.long != "" && o.arg != "":
+			spec = fmt.Sprintf("'(-%s --%s)'{-%s+,--%s=}%s", o.short, o.long, o.short, o.long, zshQuote(help))
+		case o.short != "" && o.long != "":
+			spec = fmt.Sprintf("'(-%s --%s)'{-%s,--%s}%s", o.short, o.long, o.short, o.long, zshQuote(help))
+		case o.long != "" && o.arg != "":
+			spec = zshQuote("--" + o.long + "=" + help)
+		case o.long != "":
+			spec = zshQuote("--" + o.long + help)
+		case o.arg != "":
+			spec = zshQuote("-" + o.short + "+" + help)
+		default:
+			spec = zshQuote("-" + o.short + help)
+		}
+		if o.arg != "" {
+			var action string
+			switch c.kind(o) {
+			case "choice":
+				var vs, ds []string
+				for _, ch := range c.choices[o.long] {
+					vs = append(vs, zshEscape(ch.value, `: \`))
+					ds = append(ds, zshEscape(ch.value, `: \`)+`\:"`+zshEscape(ch.help, "\"\\$`")+`"`)
+				}
+				if c.choices[o.long][0].help != "" {
+					action = "((" + strings.Join(ds, " ") + "))"
+				} else {
+					action = "(" + strings.Join(vs, " ") + ")"
+				}
+			case "dir":
+				action = "_files -/"
+			case "file":
+				action = "_files"
+			}
+			spec += zshQuote(":" + zshEscape(o.arg, ":") + ":" + action)
+		}
+		fmt.Fprintf(w, "\t\t%s \\\n", spec)
+	}
+	fmt.Fprintf(w, `		'*:source:->source'
+	if [[ $state == source ]]; then
+		((CURRENT == 2)) && _describe -t commands command commands
+		_files -g '*.yang'
+	fi
+}
+
+compdef _goyang goyang
+`)
+}
+
+// fishQuote returns s quoted for fish with single quotes.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+func (c *completion) writeFish(w io.Writer) {
+	fmt.Fprintf(w, `# fish completion for goyang.  Use it in the current shell with
+#   goyang --completion fish | source
+
+function __goyang_commands
+`)
+	for _, ch := range c.commands {
+		fmt.Fprintf(w, "\tprintf '%%s\\t%%s\\n' %s %s\n", fishQuote(ch.value), fishQuote(ch.help))
+	}
+	fmt.Fprintf(w, "end\n\n")
+
+	var longs []string
+	for _, o := range c.options {
+		if o.long != "" {
+			longs = append(longs, o.long)
+		}
+		if c.kind(o) != "choice" {
+			continue
+		}
+		fmt.Fprintf(w, "function __goyang_%s\n", strings.Replace(o.long, "-", "_", -1))
+		for _, ch := range c.choices[o.long] {
+			if ch.help == "" {
+				fmt.Fprintf(w, "\techo %s\n", fishQuote(ch.value))
+				continue
+			}
+			fmt.Fprintf(w, "\tprintf '%%s\\t%%s\\n' %s %s\n", fishQuote(ch.value), fishQuote(ch.help))
+		}
+		fmt.Fprintf(w, "end\n\n")
+	}
+
+	fmt.Fprintf(w, "complete -c goyang -n '__fish_use_subcommand' -a '(__goyang_commands)'\n")
+	// Once a command is given, only files are completed.
+	fmt.Fprintf(w, "complete -c goyang -n '__fish_seen_subcommand_from %s' -F\n", strings.Join(values(c.commands), " "))
+	for _, o := range c.options {
+		line := "complete -c goyang -n 'not __fish_seen_subcommand_from " + strings.Join(values(c.commands), " ") + "'"
+		if o.short != "" {
+			line += " -s " + o.short
+		}
+		if o.long != "" {
+			line += " -l " + o.long
+		}
+		if o.arg != "" {
+			switch c.kind(o) {
+			case "choice":
+				line += fmt.Sprintf(" -x -a '(__goyang_%s)'", strings.Replace(o.long, "-", "_", -1))
+			case "dir":
+				line += " -x -a '(__fish_complete_directories)'"
+			case "file":
+				line += " -r -F"
+			default:
+				line += " -x"
+			}
+		}
+		fmt.Fprintf(w, "%s -d %s\n", line, fishQuote(o.help))
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A config is the contents of a goyang configuration file, named with
+// --config.  It is a JSON object whose fields supply the values of the
+// command line flags of the same names, e.g.,
+//
+//   {
+//     "path": ["yang/...", "vendor/*/yang"],
+//     "modules": ["openconfig-interfaces"],
+//     "deviation-modules": ["deviations/device.yang"],
+//     "disable-features": ["ietf-interfaces:if-mib"],
+//     "exclude-modules": ["ietf-*"],
+//     "format": "tree",
+//     "output-dir": "out"
+//   }
+//
+// Relative file and directory names are relative to the directory of the
+// configuration file, so the file can be shared along with the modules.
+type config struct {
+	Path             []string `json:"path"`
+	Modules          []string `json:"modules"`
+	DeviationModules []string `json:"deviation-modules"`
+	EnableFeatures   []string `json:"enable-features"`
+	DisableFeatures  []string `json:"disable-features"`
+	IncludeModules   []string `json:"include-modules"`
+	ExcludeModules   []string `json:"exclude-modules"`
+	Format           string   `json:"format"`
+	OutputFile       string   `json:"output-file"`
+	OutputDir        string   `json:"output-dir"`
+}
+
+// readConfig returns the configuration in the file name.  Unknown fields
+// are an error, as they are most likely misspelled.
+func readConfig(name string) (*config, error) {
+	fp, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+	var c config
+	dec := json.NewDecoder(fp)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	dir := filepath.Dir(name)
+	rel := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(dir, p)
+	}
+	// Module names, as opposed to file names, are found using the
+	// search path.
+	relFile := func(p string) string {
+		if strings.Contains(p, "/") || isSourceFile(p) {
+			return rel(p)
+		}
+		return p
+	}
+	for i, p := range c.Path {
+		// Keep the trailing /... that filepath.Join would remove.
+		c.Path[i] = rel(strings.TrimSuffix(p, "/..."))
+		if strings.HasSuffix(p, "/...") {
+			c.Path[i] += "/..."
+		}
+	}
+	for i, p := range c.Modules {
+		c.Modules[i] = relFile(p)
+	}
+	for i, p := range c.DeviationModules {
+		c.DeviationModules[i] = relFile(p)
+	}
+	c.OutputFile = rel(c.OutputFile)
+	c.OutputDir = rel(c.OutputDir)
+	return &c, nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/openconfig/goyang/pkg/yangdiff"
+	"github.com/pborman/getopt"
+)
+
+func init() {
+	registerCommand(&command{
+		name: "diff",
+		run:  runDiff,
+		help: "report the changes between two revisions of modules",
+	})
+}
+
+// runDiff implements "goyang diff OLD NEW".  OLD and NEW are each either a
+// .yang file or a directory whose .yang files, including those in its
+// subdirectories, are all read.  Each change is displayed along with
+// whether it is allowed by the module update rules of RFC 7950 section 11.
+// With --exit-code, the exit status is exitErrors if there are incompatible
+// changes.
+func runDiff(args []string) int {
+	flags := getopt.New()
+	flags.SetProgram("goyang diff")
+	flags.SetParameters("OLD NEW")
+	var paths []string
+	var exitCode, incompatible, help bool
+	flags.ListVarLong(&paths, "path", 'p', "comma separated list of directories to add to search path", "DIR[,DIR...]")
+	flags.BoolVarLong(&exitCode, "exit-code", 0, "exit with status 1 if there are incompatible changes")
+	flags.BoolVarLong(&incompatible, "incompatible", 0, "only display incompatible changes")
+	commonFlags(flags)
+	flags.BoolVarLong(&help, "help", 'h', "display help")
+	if err := flags.Getopt(append([]string{"goyang diff"}, args...), nil); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flags.PrintUsage(os.Stderr)
+		return exitUsage
+	}
+	if help {
+		flags.PrintUsage(os.Stderr)
+		return exitOK
+	}
+	if flags.NArgs() != 2 {
+		flags.PrintUsage(os.Stderr)
+		return exitUsage
+	}
+	for _, path := range paths {
+		addPath(path)
+	}
+
+	var sets [2]*yang.Modules
+	for i, name := range flags.Args() {
+		ms, status := readTree(name)
+		if status != exitOK {
+			return status
+		}
+		sets[i] = ms
+	}
+
+	cs := yangdiff.Modules(sets[0], sets[1])
+	if incompatible {
+		cs = yangdiff.Incompatible(cs)
+	}
+	for _, c := range cs {
+		fmt.Println(c)
+	}
+	if exitCode && len(yangdiff.Incompatible(cs)) > 0 {
+		return exitErrors
+	}
+	return exitOK
+}
+
+// readTree reads and processes the modules of name, which is either a
+// .yang file or a directory whose .yang files are all read.  Errors are
+// reported and an exit status other than exitOK is returned if the modules
+// cannot be read or processed.
+func readTree(name string) (*yang.Modules, int) {
+	var files []string
+	if fi, err := os.Stat(name); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, exitParse
+	} else if !fi.IsDir() {
+		files = []string{name}
+	} else if err := filepath.Walk(name, func(p string, fi os.FileInfo, err error) error {
+		if err == nil && !fi.IsDir() && isSourceFile(p) {
+			files = append(files, p)
+		}
+		return err
+	}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, exitParse
+	}
+	return readModules(files)
+}
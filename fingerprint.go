@@ -0,0 +1,152 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/pborman/getopt"
+)
+
+func init() {
+	registerCommand(&command{
+		name: "fingerprint",
+		run:  runFingerprint,
+		help: "display a content hash of each module and of the resolved schema",
+	})
+}
+
+// A moduleFingerprint is the fingerprint of a module, or of the schema.
+type moduleFingerprint struct {
+	Name     string `json:"name"`
+	Revision string `json:"revision,omitempty"`
+	SHA256   string `json:"sha256"`
+}
+
+// runFingerprint implements "goyang fingerprint MODULE...".  The named
+// modules, and the modules they import, are processed as goyang processes
+// them for display, applying the deviations of --deviation-module and the
+// features selected by --enable-feature and --disable-feature.  The SHA-256
+// hash of the JSON Entry tree of each module is displayed, followed by the
+// hash of the schema, which is computed from those of all the modules.
+// The hashes depend only on the resolved schema, not on the files or the
+// order the modules were read in, so equal hashes mean the same schema.
+func runFingerprint(args []string) int {
+	flags := getopt.New()
+	flags.SetProgram("goyang fingerprint")
+	flags.SetParameters("MODULE...")
+	var paths, deviations, enableFeatures, disableFeatures []string
+	format := "text"
+	var help bool
+	flags.ListVarLong(&paths, "path", 'p', "comma separated list of directories to add to search path", "DIR[,DIR...]")
+	flags.ListVarLong(&deviations, "deviation-module", 0, "apply the deviations in MODULE, which is not fingerprinted", "MODULE[,MODULE...]")
+	flags.ListVarLong(&enableFeatures, "enable-feature", 0, "enable only the named features of MODULE", "MODULE:FEATURE[,...]")
+	flags.ListVarLong(&disableFeatures, "disable-feature", 0, "disable the named features", "MODULE:FEATURE[,...]")
+	flags.StringVarLong(&format, "format", 0, "format of the fingerprints: text or json", "FORMAT")
+	commonFlags(flags)
+	flags.BoolVarLong(&help, "help", 'h', "display help")
+	if err := flags.Getopt(append([]string{"goyang fingerprint"}, args...), nil); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flags.PrintUsage(os.Stderr)
+		return exitUsage
+	}
+	if help {
+		flags.PrintUsage(os.Stderr)
+		return exitOK
+	}
+	if format != "text" && format != "json" {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", format)
+		return exitUsage
+	}
+	if flags.NArgs() == 0 {
+		flags.PrintUsage(os.Stderr)
+		return exitUsage
+	}
+	for _, path := range paths {
+		addPath(path)
+	}
+
+	ms := newModules()
+	if !readFiles(ms, flags.Args(), runtime.GOMAXPROCS(0)) {
+		return exitParse
+	}
+	deviationModules := readDeviationModules(ms, deviations)
+	if len(enableFeatures) > 0 || len(disableFeatures) > 0 {
+		ms.AddTransform(yang.PruneFeatures(enableFeatures, disableFeatures))
+	}
+	errs := ms.Process()
+	report(ms.Warnings())
+	if len(errs) > 0 {
+		report(errs)
+		return exitStatus(errs)
+	}
+
+	var mods []*yang.Module
+	seen := map[*yang.Module]bool{}
+	for _, m := range ms.Modules {
+		if !seen[m] && !deviationModules[m] {
+			seen[m] = true
+			mods = append(mods, m)
+		}
+	}
+	sort.Slice(mods, func(i, j int) bool { return mods[i].Name < mods[j].Name })
+
+	var fps []moduleFingerprint
+	schema := sha256.New()
+	for _, m := range mods {
+		h := sha256.New()
+		if err := yang.ToEntry(m).WriteJSON(h); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		fp := moduleFingerprint{
+			Name:     m.Name,
+			Revision: m.Current(),
+			SHA256:   hex.EncodeToString(h.Sum(nil)),
+		}
+		fps = append(fps, fp)
+		fmt.Fprintf(schema, "%s %s\n", fp.Name, fp.SHA256)
+	}
+	sum := hex.EncodeToString(schema.Sum(nil))
+
+	if format == "json" {
+		if fps == nil {
+			fps = []moduleFingerprint{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(struct {
+			Modules []moduleFingerprint `json:"modules"`
+			Schema  string              `json:"schema"`
+		}{fps, sum})
+		return exitOK
+	}
+	for _, fp := range fps {
+		rev := fp.Revision
+		if rev == "" {
+			rev = "-"
+		}
+		fmt.Printf("%s %s %s\n", fp.SHA256, fp.Name, rev)
+	}
+	fmt.Printf("%s schema\n", sum)
+	return exitOK
+}
@@ -0,0 +1,188 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/pborman/getopt"
+)
+
+func init() {
+	registerCommand(&command{
+		name: "fmt",
+		run:  runFmt,
+		help: "rewrite YANG files in canonical format",
+	})
+}
+
+// runFmt implements "goyang fmt [FILE...]".  Each file is parsed and
+// written back as canonically formatted YANG, see yang.Format.  With no
+// files, standard input is formatted to standard output.
+func runFmt(args []string) int {
+	flags := getopt.New()
+	flags.SetProgram("goyang fmt")
+	flags.SetParameters("[FILE...]")
+	indent := 2
+	var tabs, keepOrder, write, list, help bool
+	flags.IntVarLong(&indent, "indent", 0, "indent each level by N spaces", "N")
+	flags.BoolVarLong(&tabs, "tabs", 0, "indent each level by a tab")
+	flags.BoolVarLong(&keepOrder, "keep-order", 0, "keep the order of statements rather than using the RFC 7950 order")
+	flags.BoolVarLong(&write, "write", 'w', "write the result to the file rather than to standard output")
+	flags.BoolVarLong(&list, "list", 'l', "list files whose formatting differs and exit 1 if there are any")
+	flags.BoolVarLong(&help, "help", 'h', "display help")
+	if err := flags.Getopt(append([]string{"goyang fmt"}, args...), nil); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flags.PrintUsage(os.Stderr)
+		return exitUsage
+	}
+	if help {
+		flags.PrintUsage(os.Stderr)
+		return exitOK
+	}
+	if indent < 1 {
+		fmt.Fprintf(os.Stderr, "--indent must be at least 1\n")
+		return exitUsage
+	}
+	opts := yang.FormatOptions{
+		Indent:    strings.Repeat(" ", indent),
+		KeepOrder: keepOrder,
+	}
+	if tabs {
+		opts.Indent = "\t"
+	}
+	names := flags.Args()
+	if len(names) == 0 {
+		if write {
+			fmt.Fprintln(os.Stderr, "--write requires files")
+			return exitUsage
+		}
+		data, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		out, err := formatSource(string(data), "<STDIN>", opts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitParse
+		}
+		if list {
+			if !bytes.Equal(out, data) {
+				fmt.Println("<STDIN>")
+				return exitErrors
+			}
+			return exitOK
+		}
+		os.Stdout.Write(out)
+		return exitOK
+	}
+
+	status := exitOK
+	fail := func(s int) {
+		if s > status {
+			status = s
+		}
+	}
+	for _, name := range names {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			fail(exitFailure)
+			continue
+		}
+		out, err := formatSource(string(data), name, opts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			fail(exitParse)
+			continue
+		}
+		changed := !bytes.Equal(out, data)
+		if list && changed {
+			fmt.Println(name)
+			fail(exitErrors)
+		}
+		switch {
+		case write && changed:
+			// Formatting removes comments, which would silently
+			// lose them from the file.
+			if hasComments(string(data)) {
+				fmt.Fprintf(os.Stderr, "%s: not rewritten as formatting would remove its comments\n", name)
+				fail(exitFailure)
+				continue
+			}
+			if err := writeFile(name, func(w io.Writer) error {
+				_, err := w.Write(out)
+				return err
+			}); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				fail(exitFailure)
+			}
+		case !write && !list:
+			os.Stdout.Write(out)
+		}
+	}
+	return status
+}
+
+// formatSource returns the YANG source data, read from name, formatted
+// according to opts.
+func formatSource(data, name string, opts yang.FormatOptions) ([]byte, error) {
+	if yang.IsYIN(data, name) {
+		return nil, fmt.Errorf("%s: only YANG files can be formatted", name)
+	}
+	ss, err := yang.Parse(data, name)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	for x, s := range ss {
+		if x > 0 {
+			b.WriteByte('\n')
+		}
+		if err := opts.Format(s, &b); err != nil {
+			return nil, err
+		}
+	}
+	return b.Bytes(), nil
+}
+
+// hasComments reports whether the YANG source data contains a comment.
+func hasComments(data string) bool {
+	for i := 0; i < len(data); i++ {
+		switch data[i] {
+		case '"':
+			for i++; i < len(data) && data[i] != '"'; i++ {
+				if data[i] == '\\' {
+					i++
+				}
+			}
+		case '\'':
+			for i++; i < len(data) && data[i] != '\''; i++ {
+			}
+		case '/':
+			if i+1 < len(data) && (data[i+1] == '/' || data[i+1] == '*') {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,262 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/pborman/getopt"
+)
+
+func init() {
+	registerCommand(&command{
+		name: "graph",
+		run:  runGraph,
+		help: "display the dependency graph of modules in DOT or JSON",
+	})
+}
+
+// A graphEdge is a dependency of one module or submodule on another.
+type graphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"` // "import", "include", or "augment"
+}
+
+// A graphNode is a module or submodule in the dependency graph.
+type graphNode struct {
+	Name     string `json:"name"`
+	Kind     string `json:"kind"` // "module" or "submodule"
+	Revision string `json:"revision,omitempty"`
+	File     string `json:"file,omitempty"`
+	Depth    int    `json:"depth"` // distance from the named modules
+}
+
+// runGraph implements "goyang graph MODULE...".  The graph contains the
+// named modules and, transitively, the modules and submodules they
+// import, include, or augment, up to --depth edges away.
+func runGraph(args []string) int {
+	flags := getopt.New()
+	flags.SetProgram("goyang graph")
+	flags.SetParameters("MODULE...")
+	var paths, include, exclude []string
+	format := "dot"
+	var depth int
+	var help bool
+	flags.ListVarLong(&paths, "path", 'p', "comma separated list of directories to add to search path", "DIR[,DIR...]")
+	flags.StringVarLong(&format, "format", 0, "format of the graph: dot or json", "FORMAT")
+	flags.IntVarLong(&depth, "depth", 0, "only include modules at most N dependencies away from the named modules (0 is no limit)", "N")
+	flags.ListVarLong(&include, "include-module", 0, "only include modules whose name matches one of the glob patterns", "PATTERN[,PATTERN...]")
+	flags.ListVarLong(&exclude, "exclude-module", 0, "exclude modules whose name matches one of the glob patterns", "PATTERN[,PATTERN...]")
+	commonFlags(flags)
+	flags.BoolVarLong(&help, "help", 'h', "display help")
+	if err := flags.Getopt(append([]string{"goyang graph"}, args...), nil); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flags.PrintUsage(os.Stderr)
+		return exitUsage
+	}
+	if help {
+		flags.PrintUsage(os.Stderr)
+		return exitOK
+	}
+	if format != "dot" && format != "json" {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", format)
+		return exitUsage
+	}
+	if depth < 0 {
+		fmt.Fprintln(os.Stderr, "--depth must not be negative")
+		return exitUsage
+	}
+	if err := checkPatterns(append(append([]string{}, include...), exclude...)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	if flags.NArgs() == 0 {
+		flags.PrintUsage(os.Stderr)
+		return exitUsage
+	}
+	for _, path := range paths {
+		addPath(path)
+	}
+	ms, status := readModules(flags.Args())
+	if status != exitOK {
+		return status
+	}
+
+	nodes, edges := moduleGraph(namedModules(ms, flags.Args()), depth)
+	keep := map[string]bool{}
+	var kept []*graphNode
+	for _, n := range nodes {
+		if selected(n.Name, include, exclude) {
+			keep[n.Name] = true
+			kept = append(kept, n)
+		}
+	}
+	var keptEdges []graphEdge
+	for _, e := range edges {
+		if keep[e.From] && keep[e.To] {
+			keptEdges = append(keptEdges, e)
+		}
+	}
+	if format == "json" {
+		writeGraphJSON(os.Stdout, kept, keptEdges)
+	} else {
+		writeGraphDOT(os.Stdout, kept, keptEdges)
+	}
+	return exitOK
+}
+
+// moduleGraph returns the modules and submodules reachable from roots by
+// following imports, includes, and augments, at most depth edges away if
+// depth is not 0, and the edges between them.  Nodes are sorted by depth
+// and then by name, and edges by their ends and kind.
+func moduleGraph(roots []*yang.Module, depth int) ([]*graphNode, []graphEdge) {
+	byName := map[string]*graphNode{}
+	var nodes []*graphNode
+	var edges []graphEdge
+	var queue []*yang.Module
+	for _, m := range roots {
+		if byName[m.Name] == nil {
+			byName[m.Name] = newGraphNode(m, 0)
+			nodes = append(nodes, byName[m.Name])
+			queue = append(queue, m)
+		}
+	}
+	for len(queue) > 0 {
+		m := queue[0]
+		queue = queue[1:]
+		n := byName[m.Name]
+		for _, dep := range moduleDependencies(m) {
+			if dn := byName[dep.module.Name]; dn == nil {
+				if depth > 0 && n.Depth >= depth {
+					continue
+				}
+				dn = newGraphNode(dep.module, n.Depth+1)
+				byName[dn.Name] = dn
+				nodes = append(nodes, dn)
+				queue = append(queue, dep.module)
+			}
+			edges = append(edges, graphEdge{From: m.Name, To: dep.module.Name, Kind: dep.kind})
+		}
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].Depth != nodes[j].Depth {
+			return nodes[i].Depth < nodes[j].Depth
+		}
+		return nodes[i].Name < nodes[j].Name
+	})
+	sort.Slice(edges, func(i, j int) bool {
+		a, b := edges[i], edges[j]
+		switch {
+		case a.From != b.From:
+			return a.From < b.From
+		case a.To != b.To:
+			return a.To < b.To
+		}
+		return a.Kind < b.Kind
+	})
+	return nodes, edges
+}
+
+func newGraphNode(m *yang.Module, depth int) *graphNode {
+	return &graphNode{
+		Name:     m.Name,
+		Kind:     m.Kind(),
+		Revision: m.Current(),
+		File:     sourceFile(m),
+		Depth:    depth,
+	}
+}
+
+// A dependency is a module or submodule used by another.
+type dependency struct {
+	module *yang.Module
+	kind   string
+}
+
+// moduleDependencies returns the modules m imports, the submodules it
+// includes, and the modules whose nodes it augments, each once per kind.
+func moduleDependencies(m *yang.Module) []dependency {
+	var deps []dependency
+	seen := map[dependency]bool{}
+	add := func(dm *yang.Module, kind string) {
+		d := dependency{dm, kind}
+		if dm != nil && dm != m && !seen[d] {
+			seen[d] = true
+			deps = append(deps, d)
+		}
+	}
+	for _, i := range m.Import {
+		add(i.Module, "import")
+	}
+	for _, i := range m.Include {
+		add(i.Module, "include")
+	}
+	for _, a := range m.Augment {
+		// The target of an augment is in the module of the prefix of
+		// its first node.
+		first := strings.SplitN(strings.TrimPrefix(a.Name, "/"), "/", 2)[0]
+		if tm, _, err := yang.ResolvePrefixedName(a, first); err == nil {
+			add(tm, "augment")
+		}
+	}
+	return deps
+}
+
+// writeGraphDOT writes the graph to w in the DOT language of Graphviz.
+// Submodules are drawn as boxes, includes as dashed lines, and augments
+// as dotted lines.
+func writeGraphDOT(w io.Writer, nodes []*graphNode, edges []graphEdge) {
+	fmt.Fprintln(w, "digraph modules {")
+	for _, n := range nodes {
+		label := n.Name
+		if n.Revision != "" {
+			label += "\n" + n.Revision
+		}
+		shape := "ellipse"
+		if n.Kind == "submodule" {
+			shape = "box"
+		}
+		fmt.Fprintf(w, "  %q [label=%q, shape=%s];\n", n.Name, label, shape)
+	}
+	styles := map[string]string{"import": "solid", "include": "dashed", "augment": "dotted"}
+	for _, e := range edges {
+		fmt.Fprintf(w, "  %q -> %q [label=%q, style=%s];\n", e.From, e.To, e.Kind, styles[e.Kind])
+	}
+	fmt.Fprintln(w, "}")
+}
+
+// writeGraphJSON writes the graph to w as a JSON object with "modules" and
+// "edges" members.
+func writeGraphJSON(w io.Writer, nodes []*graphNode, edges []graphEdge) {
+	if nodes == nil {
+		nodes = []*graphNode{}
+	}
+	if edges == nil {
+		edges = []graphEdge{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(struct {
+		Modules []*graphNode `json:"modules"`
+		Edges   []graphEdge  `json:"edges"`
+	}{nodes, edges})
+}
@@ -0,0 +1,40 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+func init() {
+	register(&formatter{
+		name: "json",
+		f:    doJSON,
+		help: "display each module as a JSON Entry tree, one per line",
+	})
+}
+
+func doJSON(w io.Writer, entries []*yang.Entry) error {
+	for _, e := range entries {
+		if err := e.WriteJSON(w); err != nil {
+			return err
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
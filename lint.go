@@ -0,0 +1,178 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/openconfig/goyang/pkg/yanglint"
+	"github.com/pborman/getopt"
+)
+
+func init() {
+	registerCommand(&command{
+		name: "lint",
+		run:  runLint,
+		help: "check modules against style rules",
+	})
+}
+
+// runLint implements "goyang lint MODULE...".  Only the named modules, and
+// the submodules they include, are checked, not the modules they import.
+// The exit status is exitErrors if there is a finding with severity error,
+// or else exitWarnings if there is a finding with severity warning.
+func runLint(args []string) int {
+	flags := getopt.New()
+	flags.SetProgram("goyang lint")
+	flags.SetParameters("MODULE...")
+	var paths, ruleNames, overrides []string
+	format := "text"
+	var listRules, help bool
+	flags.ListVarLong(&paths, "path", 'p', "comma separated list of directories to add to search path", "DIR[,DIR...]")
+	flags.ListVarLong(&ruleNames, "rules", 0, "comma separated list of rules to run (default all)", "RULE[,RULE...]")
+	flags.ListVarLong(&overrides, "severity-overrides", 0, "comma separated list of rule severities, one of off, info, warning, or error", "RULE=SEVERITY[,...]")
+	flags.StringVarLong(&format, "format", 0, "format of the findings: text, json, or sarif", "FORMAT")
+	flags.BoolVarLong(&listRules, "list-rules", 0, "display the rules and exit")
+	commonFlags(flags)
+	flags.BoolVarLong(&help, "help", 'h', "display help")
+	if err := flags.Getopt(append([]string{"goyang lint"}, args...), nil); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flags.PrintUsage(os.Stderr)
+		return exitUsage
+	}
+	if help {
+		flags.PrintUsage(os.Stderr)
+		return exitOK
+	}
+	if listRules {
+		for _, r := range yanglint.Rules() {
+			fmt.Printf("%s (%v): %s\n", r.Name, r.Severity, r.Description)
+		}
+		return exitOK
+	}
+	switch format {
+	case "text", "json", "sarif":
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", format)
+		return exitUsage
+	}
+	if flags.NArgs() == 0 {
+		flags.PrintUsage(os.Stderr)
+		return exitUsage
+	}
+
+	c := yanglint.Config{Rules: ruleNames, Severity: map[string]yanglint.Severity{}}
+	for _, o := range overrides {
+		i := strings.Index(o, "=")
+		if i < 0 {
+			fmt.Fprintf(os.Stderr, "severity override %q is not RULE=SEVERITY\n", o)
+			return exitUsage
+		}
+		sev, err := yanglint.ParseSeverity(o[i+1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitUsage
+		}
+		c.Severity[o[:i]] = sev
+	}
+
+	for _, path := range paths {
+		addPath(path)
+	}
+	ms, status := readModules(flags.Args())
+	if status != exitOK {
+		return status
+	}
+
+	fs, err := yanglint.Lint(lintModules(ms, flags.Args()), c)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	switch format {
+	case "json":
+		if fs == nil {
+			fs = []*yanglint.Finding{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(fs)
+	case "sarif":
+		if err := yanglint.WriteSARIF(os.Stdout, fs); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+	default:
+		for _, f := range fs {
+			fmt.Println(f)
+		}
+	}
+	status = exitOK
+	for _, f := range fs {
+		switch f.Severity {
+		case yanglint.Error:
+			return exitErrors
+		case yanglint.Warning:
+			status = exitWarnings
+		}
+	}
+	return status
+}
+
+// lintModules returns the modules of ms named by names, followed by the
+// submodules they include.
+func lintModules(ms *yang.Modules, names []string) []*yang.Module {
+	var mods []*yang.Module
+	seen := map[*yang.Module]bool{}
+	var add func(m *yang.Module)
+	add = func(m *yang.Module) {
+		if m == nil || seen[m] {
+			return
+		}
+		seen[m] = true
+		mods = append(mods, m)
+		for _, i := range m.Include {
+			add(i.Module)
+		}
+	}
+	for _, m := range namedModules(ms, names) {
+		add(m)
+	}
+	return mods
+}
+
+// namedModules returns the modules and submodules of ms named by names,
+// which are module names or file names, e.g., "foo" or "dir/foo@2020-01-01.yang".
+func namedModules(ms *yang.Modules, names []string) []*yang.Module {
+	var mods []*yang.Module
+	for _, name := range names {
+		name = strings.TrimSuffix(strings.TrimSuffix(filepath.Base(name), ".yang"), ".yin")
+		if i := strings.Index(name, "@"); i >= 0 {
+			name = name[:i]
+		}
+		if m := ms.Modules[name]; m != nil {
+			mods = append(mods, m)
+		} else if m := ms.SubModules[name]; m != nil {
+			mods = append(mods, m)
+		}
+	}
+	return mods
+}
@@ -0,0 +1,154 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bench
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+// corpora returns the corpora to benchmark, failing b if they cannot be
+// loaded.
+func corpora(b *testing.B) []*Corpus {
+	cs, err := Corpora()
+	if err != nil {
+		b.Fatal(err)
+	}
+	return cs
+}
+
+// TestCorpora makes sure the generated corpora are valid, otherwise the
+// benchmarks measure error handling.
+func TestCorpora(t *testing.T) {
+	for _, c := range []*Corpus{OpenConfig(2), IETF(2), Vendor(2, 10)} {
+		ms, errs := c.Process()
+		if len(errs) > 0 {
+			t.Errorf("%s: unexpected errors: %v", c.Name, errs)
+			continue
+		}
+		for _, n := range c.Names() {
+			if c.Size() == 0 {
+				t.Errorf("%s: empty corpus", c.Name)
+			}
+			name := n[:len(n)-len(".yang")]
+			if _, errs := ms.GetModule(name); len(errs) > 0 {
+				t.Errorf("%s: %s: %v", c.Name, name, errs)
+			}
+		}
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goyang-bench")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := LoadDir("empty", dir); err == nil {
+		t.Errorf("LoadDir of an empty directory unexpectedly succeeded")
+	}
+
+	want := IETF(1)
+	for n, s := range want.Sources {
+		sub := filepath.Join(dir, "sub")
+		if err := os.MkdirAll(sub, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(sub, n), []byte(s), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "README"), []byte("not yang"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.Setenv("GOYANG_BENCH_CORPUS", os.Getenv("GOYANG_BENCH_CORPUS"))
+	os.Setenv("GOYANG_BENCH_CORPUS", dir)
+	cs, err := EnvCorpora()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cs) != 1 {
+		t.Fatalf("got %d corpora, want 1", len(cs))
+	}
+	if got := cs[0]; len(got.Sources) != len(want.Sources) || got.Size() != want.Size() {
+		t.Errorf("got %d sources of %d bytes, want %d sources of %d bytes", len(got.Sources), got.Size(), len(want.Sources), want.Size())
+	}
+	if _, errs := cs[0].Process(); len(errs) > 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	for _, c := range corpora(b) {
+		b.Run(c.Name, func(b *testing.B) {
+			b.SetBytes(c.Size())
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := c.Parse(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkProcess(b *testing.B) {
+	for _, c := range corpora(b) {
+		b.Run(c.Name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				ms, err := c.Parse()
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.StartTimer()
+				if errs := ms.Process(); len(errs) > 0 {
+					b.Fatal(errs)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkToEntry measures processing modules that have already been
+// processed once.  Types and identities are already resolved so the time
+// is dominated by building the Entry trees.
+func BenchmarkToEntry(b *testing.B) {
+	for _, c := range corpora(b) {
+		b.Run(c.Name, func(b *testing.B) {
+			ms, errs := c.Process()
+			if len(errs) > 0 {
+				b.Fatal(errs)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if errs := ms.Process(); len(errs) > 0 {
+					b.Fatal(errs)
+				}
+				for _, m := range ms.Modules {
+					yang.ToEntry(m)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bench provides corpora of YANG modules for measuring the
+// performance of the yang package.
+//
+// The package's benchmarks parse and process generated corpora shaped like
+// OpenConfig, IETF, and large vendor module sets.  Real module sets can be
+// added by setting the GOYANG_BENCH_CORPUS environment variable to a colon
+// separated list of directories.  To evaluate a change, run the benchmarks
+// before and after the change and compare the results, e.g., with benchstat:
+//
+//	go test -run=NONE -bench=. -count=10 ./pkg/bench > old.txt
+//	go test -run=NONE -bench=. -count=10 ./pkg/bench > new.txt
+//	benchstat old.txt new.txt
+//
+// The corpus loader may also be used to profile your own module sets.
+package bench
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+// A Corpus is a named set of YANG sources.
+type Corpus struct {
+	Name    string
+	Sources map[string]string // source name to contents
+}
+
+// Names returns the names of the sources in c, sorted.
+func (c *Corpus) Names() []string {
+	names := make([]string, 0, len(c.Sources))
+	for n := range c.Sources {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Size returns the total size, in bytes, of the sources in c.
+func (c *Corpus) Size() int64 {
+	var n int64
+	for _, s := range c.Sources {
+		n += int64(len(s))
+	}
+	return n
+}
+
+// Parse returns a new Modules with all the sources in c parsed into it.
+func (c *Corpus) Parse() (*yang.Modules, error) {
+	ms := yang.NewModules()
+	for _, n := range c.Names() {
+		if err := ms.Parse(c.Sources[n], n); err != nil {
+			return nil, err
+		}
+	}
+	return ms, nil
+}
+
+// Process parses and processes the sources in c.
+func (c *Corpus) Process() (*yang.Modules, []error) {
+	ms, err := c.Parse()
+	if err != nil {
+		return nil, []error{err}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		return nil, errs
+	}
+	return ms, nil
+}
+
+// LoadDir returns a corpus named name containing all the .yang files found
+// in dir and its subdirectories.
+func LoadDir(name, dir string) (*Corpus, error) {
+	c := &Corpus{Name: name, Sources: map[string]string{}}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".yang") {
+			return nil
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		c.Sources[path] = string(data)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(c.Sources) == 0 {
+		return nil, fmt.Errorf("%s: no .yang files found", dir)
+	}
+	return c, nil
+}
+
+// EnvCorpora returns a corpus for each directory listed in the
+// GOYANG_BENCH_CORPUS environment variable.  Each corpus is named by the
+// base name of its directory.
+func EnvCorpora() ([]*Corpus, error) {
+	var cs []*Corpus
+	for _, dir := range filepath.SplitList(os.Getenv("GOYANG_BENCH_CORPUS")) {
+		if dir == "" {
+			continue
+		}
+		c, err := LoadDir(filepath.Base(dir), dir)
+		if err != nil {
+			return nil, err
+		}
+		cs = append(cs, c)
+	}
+	return cs, nil
+}
+
+// Corpora returns the standard generated corpora followed by the corpora
+// named by GOYANG_BENCH_CORPUS.
+func Corpora() ([]*Corpus, error) {
+	cs := []*Corpus{
+		OpenConfig(20),
+		IETF(20),
+		Vendor(5, 200),
+	}
+	env, err := EnvCorpora()
+	if err != nil {
+		return nil, err
+	}
+	return append(cs, env...), nil
+}
@@ -0,0 +1,243 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bench
+
+// This file generates corpora that exercise the same features, in roughly
+// the same proportions, as commonly used module sets.  The generated
+// modules are deterministic so results are comparable between runs.
+
+import (
+	"fmt"
+	"strings"
+)
+
+// typesModule is shared by all the generated corpora.  Like
+// ietf-inet-types and ietf-yang-types, it defines types with large
+// patterns and unions that are used by many leaves.
+const typesModule = `module bench-types {
+  namespace "urn:bench:types";
+  prefix "bt";
+
+  identity KIND;
+  identity KIND-A { base KIND; }
+  identity KIND-B { base KIND; }
+  identity KIND-C { base KIND-A; }
+
+  typedef ipv4-address {
+    type string {
+      pattern '(([0-9]|[1-9][0-9]|1[0-9][0-9]|2[0-4][0-9]|25[0-5])\.){3}'
+            + '([0-9]|[1-9][0-9]|1[0-9][0-9]|2[0-4][0-9]|25[0-5])'
+            + '(%[\p{N}\p{L}]+)?';
+    }
+  }
+  typedef ipv6-address {
+    type string {
+      pattern '((:|[0-9a-fA-F]{0,4}):)([0-9a-fA-F]{0,4}:){0,5}'
+            + '((([0-9a-fA-F]{0,4}:)?(:|[0-9a-fA-F]{0,4}))|'
+            + '(((25[0-5]|2[0-4][0-9]|[01]?[0-9]?[0-9])\.){3}'
+            + '(25[0-5]|2[0-4][0-9]|[01]?[0-9]?[0-9])))'
+            + '(%[\p{N}\p{L}]+)?';
+    }
+  }
+  typedef ip-address {
+    type union {
+      type ipv4-address;
+      type ipv6-address;
+    }
+  }
+  typedef counter64 { type uint64; }
+  typedef mtu { type uint16 { range "68..9216"; } }
+  typedef percentage { type uint8 { range "0..100"; } }
+}
+`
+
+// newCorpus returns a corpus named name that contains typesModule.
+func newCorpus(name string) *Corpus {
+	return &Corpus{
+		Name:    name,
+		Sources: map[string]string{"bench-types.yang": typesModule},
+	}
+}
+
+// OpenConfig returns a corpus of n modules written in the OpenConfig style,
+// with config and state containers built from shared groupings.
+func OpenConfig(n int) *Corpus {
+	c := newCorpus(fmt.Sprintf("openconfig-%d", n))
+	addOpenConfig(c, n)
+	return c
+}
+
+func addOpenConfig(c *Corpus, n int) {
+	for i := 0; i < n; i++ {
+		c.Sources[fmt.Sprintf("oc-bench-%d.yang", i)] = fmt.Sprintf(`module oc-bench-%[1]d {
+  yang-version "1";
+  namespace "urn:bench:oc:%[1]d";
+  prefix "oc%[1]d";
+
+  import bench-types { prefix bt; }
+
+  description "Generated OpenConfig style module %[1]d.";
+
+  grouping item-config {
+    leaf name { type string; description "The name of the item."; }
+    leaf enabled { type boolean; default "true"; }
+    leaf mtu { type bt:mtu; }
+    leaf address { type bt:ip-address; }
+    leaf kind { type identityref { base bt:KIND; } }
+    leaf mode { type enumeration { enum A; enum B; enum C; } }
+  }
+
+  grouping item-state {
+    leaf in-octets { type bt:counter64; }
+    leaf out-octets { type bt:counter64; }
+    leaf utilization { type bt:percentage; }
+  }
+
+  grouping subitems-top {
+    container subitems {
+      list subitem {
+        key "name";
+        leaf name { type leafref { path "../config/name"; } }
+        container config { uses item-config; }
+        container state { config false; uses item-config; uses item-state; }
+      }
+    }
+  }
+
+  grouping items-top {
+    container items {
+      list item {
+        key "name";
+        leaf name { type leafref { path "../config/name"; } }
+        container config { uses item-config; }
+        container state { config false; uses item-config; uses item-state; }
+        uses subitems-top;
+      }
+    }
+  }
+
+  container top-%[1]d {
+    uses items-top;
+    container nested { uses items-top; }
+  }
+}
+`, i)
+	}
+}
+
+// IETF returns a corpus of n modules written in the IETF style, with
+// features, choices, RPCs, and notifications.
+func IETF(n int) *Corpus {
+	c := newCorpus(fmt.Sprintf("ietf-%d", n))
+	for i := 0; i < n; i++ {
+		c.Sources[fmt.Sprintf("ietf-bench-%d.yang", i)] = fmt.Sprintf(`module ietf-bench-%[1]d {
+  yang-version 1.1;
+  namespace "urn:ietf:params:xml:ns:yang:ietf-bench-%[1]d";
+  prefix "ib%[1]d";
+
+  import bench-types { prefix bt; }
+
+  organization "Generated";
+  contact "None.";
+  description "Generated IETF style module %[1]d.";
+
+  revision 2020-01-01 { description "Initial revision."; }
+
+  feature extended { description "Extended values."; }
+
+  typedef local-name { type string { length "1..64"; } }
+
+  container interfaces {
+    list interface {
+      key "name";
+      leaf name { type local-name; }
+      leaf description { type string; }
+      leaf enabled { type boolean; default "true"; }
+      leaf extended-value { if-feature extended; type uint32; }
+      choice address-type {
+        case v4 { leaf v4 { type bt:ipv4-address; } }
+        case v6 { leaf v6 { type bt:ipv6-address; } }
+      }
+      container statistics {
+        config false;
+        leaf discontinuity-time { type string; }
+        leaf in-octets { type bt:counter64; }
+        leaf out-octets { type bt:counter64; }
+      }
+    }
+  }
+
+  rpc reset-%[1]d {
+    input { leaf name { type local-name; } }
+    output { leaf result { type string; } }
+  }
+
+  notification changed-%[1]d {
+    leaf name { type local-name; }
+  }
+}
+`, i)
+	}
+	return c
+}
+
+// Vendor returns a corpus that contains OpenConfig(n) along with, for each
+// OpenConfig module, a vendor module that augments it with leaves leaves
+// and deviates some of its nodes.
+func Vendor(n, leaves int) *Corpus {
+	c := newCorpus(fmt.Sprintf("vendor-%d-%d", n, leaves))
+	addOpenConfig(c, n)
+	for i := 0; i < n; i++ {
+		var b strings.Builder
+		for l := 0; l < leaves; l++ {
+			fmt.Fprintf(&b, "    leaf vendor-leaf-%d { type %s }\n", l, vendorTypes[l%len(vendorTypes)])
+		}
+		c.Sources[fmt.Sprintf("vendor-bench-%d.yang", i)] = fmt.Sprintf(`module vendor-bench-%[1]d {
+  namespace "urn:bench:vendor:%[1]d";
+  prefix "vb%[1]d";
+
+  import bench-types { prefix bt; }
+  import oc-bench-%[1]d { prefix oc; }
+
+  augment "/oc:top-%[1]d/oc:items/oc:item/oc:config" {
+%[2]s  }
+
+  augment "/oc:top-%[1]d/oc:nested/oc:items/oc:item/oc:config" {
+%[2]s  }
+
+  deviation "/oc:top-%[1]d/oc:items/oc:item/oc:config/oc:mtu" {
+    deviate replace { type uint16 { range "68..1500"; } }
+  }
+
+  deviation "/oc:top-%[1]d/oc:items/oc:item/oc:state/oc:utilization" {
+    deviate not-supported;
+  }
+}
+`, i, b.String())
+	}
+	return c
+}
+
+// vendorTypes are the type statements, less the type keyword, of the
+// leaves added by vendor modules.
+var vendorTypes = []string{
+	"string;",
+	"uint32;",
+	"boolean;",
+	"bt:counter64;",
+	"bt:ip-address;",
+	`string { length "1..255"; }`,
+	`int32 { range "-100..100"; }`,
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cisco decodes the extensions defined by the Cisco cisco-semver
+// module into typed metadata.
+package cisco
+
+import (
+	"errors"
+
+	"github.com/openconfig/goyang/pkg/dialect"
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+// Module is the name of the module that defines the Cisco semantic
+// versioning extensions.
+const Module = "cisco-semver"
+
+// Key is the key in the Annotation field of an Entry that Annotate stores
+// the entry's *Info under.
+const Key = "cisco"
+
+// Info is the Cisco metadata of an entry.
+type Info struct {
+	ModuleVersion string            // cisco-semver:module-version
+	Other         []*yang.Statement // all other cisco-semver extensions
+}
+
+// Decode returns the Cisco metadata of e, or nil if e does not use any
+// cisco-semver extensions.  The module-version extension is used at the top
+// of a module, so it is found on the module's Entry.
+func Decode(e *yang.Entry, mode dialect.Mode) (*Info, []error) {
+	var info *Info
+	errs := dialect.Decode(e, Module, mode, func(ext *yang.Statement) error {
+		if info == nil {
+			info = &Info{}
+		}
+		if dialect.Name(ext) != "module-version" {
+			info.Other = append(info.Other, ext)
+			return nil
+		}
+		arg, err := dialect.Argument(ext)
+		switch {
+		case err != nil:
+			return err
+		case info.ModuleVersion != "":
+			return errors.New("duplicate statement")
+		}
+		info.ModuleVersion = arg
+		return nil
+	})
+	return info, errs
+}
+
+// Annotate decodes the Cisco metadata of e and all of its descendants.
+// The metadata of each entry that uses cisco-semver extensions is stored
+// in the entry's Annotation field and may be retrieved with Get.
+func Annotate(e *yang.Entry, mode dialect.Mode) []error {
+	return dialect.Walk(e, func(e *yang.Entry) []error {
+		info, errs := Decode(e, mode)
+		if info != nil {
+			dialect.Annotate(e, Key, info)
+		}
+		return errs
+	})
+}
+
+// Get returns the Cisco metadata that Annotate stored in e, or nil.
+func Get(e *yang.Entry) *Info {
+	info, _ := e.Annotation[Key].(*Info)
+	return info
+}
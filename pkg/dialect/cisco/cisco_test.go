@@ -0,0 +1,73 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cisco
+
+import (
+	"testing"
+
+	"github.com/openconfig/goyang/pkg/dialect"
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+func TestAnnotate(t *testing.T) {
+	ms := yang.NewModules()
+	for n, text := range map[string]string{
+		"cisco-semver": `
+			module cisco-semver {
+				prefix "cisco-semver";
+				namespace "http://cisco.com/ns/yang/cisco-semver";
+				extension module-version { argument semver; }
+			}
+		`,
+		"dev": `
+			module dev {
+				prefix "d";
+				namespace "urn:d";
+				import cisco-semver { prefix "cisco-semver"; }
+				cisco-semver:module-version "2.1.0";
+				cisco-semver:later "x";
+				leaf l { type string; }
+			}
+		`,
+	} {
+		if err := ms.Parse(text, n+".yang"); err != nil {
+			t.Fatalf("%s: %v", n, err)
+		}
+	}
+	e, errs := ms.GetModule("dev")
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	if errs := Annotate(e, dialect.Strict); len(errs) != 1 {
+		t.Errorf("strict: got errors %v, want 1 for cisco-semver:later", errs)
+	}
+	if errs := Annotate(e, dialect.Tolerant); len(errs) != 0 {
+		t.Errorf("tolerant: got errors %v, want none", errs)
+	}
+	info := Get(e)
+	if info == nil {
+		t.Fatal("no metadata on the module")
+	}
+	if got, want := info.ModuleVersion, "2.1.0"; got != want {
+		t.Errorf("got ModuleVersion %q, want %q", got, want)
+	}
+	if len(info.Other) != 1 {
+		t.Errorf("got Other %v, want cisco-semver:later", info.Other)
+	}
+	if info := Get(e.Dir["l"]); info != nil {
+		t.Errorf("l: got %+v, want nil", info)
+	}
+}
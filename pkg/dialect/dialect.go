@@ -0,0 +1,141 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dialect provides the support shared by the vendor dialect
+// packages, such as tailf, cisco, and junos.  Each dialect package decodes
+// the extensions defined by a vendor's extension modules into typed
+// metadata that is stored in the Annotation field of the entries the
+// extensions are used in.
+package dialect
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+// A Mode determines how a dialect handles vendor statements it does not
+// know.
+type Mode int
+
+const (
+	// Strict reports an error for each unknown or malformed vendor
+	// statement.
+	Strict Mode = iota
+	// Tolerant never reports errors.  Unknown vendor statements are
+	// retained, undecoded, in the metadata and malformed statements are
+	// ignored.
+	Tolerant
+)
+
+// Decode calls decode for each extension used in e that is defined by the
+// module named module, in the order they appear in e.  In Strict mode the
+// errors returned by decode are returned, along with an error for each
+// extension that uses the prefix of module but is not one of the
+// extensions module defines.  In Tolerant mode Decode never returns
+// errors; unknown extensions are still passed to decode, which is expected
+// to retain them undecoded.
+func Decode(e *yang.Entry, module string, mode Mode, decode func(ext *yang.Statement) error) []error {
+	if e.Node == nil || yang.RootNode(e.Node) == nil {
+		return nil
+	}
+	var errs []error
+	for _, ext := range e.Exts {
+		i := strings.Index(ext.Keyword, ":")
+		if i < 0 {
+			continue
+		}
+		m := yang.FindModuleByPrefix(e.Node, ext.Keyword[:i])
+		if m == nil || m.Name != module {
+			continue
+		}
+		err := decode(ext)
+		if mode == Tolerant {
+			continue
+		}
+		if !defines(m, Name(ext)) {
+			errs = append(errs, fmt.Errorf("%s: %s: unknown %s extension", ext.Location(), ext.Keyword, module))
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s: %v", ext.Location(), ext.Keyword, err))
+		}
+	}
+	return errs
+}
+
+// defines reports whether m, or one of its submodules, defines the
+// extension name.
+func defines(m *yang.Module, name string) bool {
+	for _, x := range m.Extension {
+		if x.Name == name {
+			return true
+		}
+	}
+	for _, i := range m.Include {
+		if i.Module != nil && defines(i.Module, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Name returns the name of the extension used by ext, i.e., its keyword
+// without the prefix.
+func Name(ext *yang.Statement) string {
+	return ext.Keyword[strings.Index(ext.Keyword, ":")+1:]
+}
+
+// Argument returns the argument of ext, or an error if ext has no
+// argument.
+func Argument(ext *yang.Statement) (string, error) {
+	if !ext.HasArgument {
+		return "", errors.New("missing argument")
+	}
+	return ext.Argument, nil
+}
+
+// Walk calls fn for e and all of its descendants, including the input and
+// output of RPCs.  Children are visited in name order.  The errors returned
+// by fn are collected and returned.
+func Walk(e *yang.Entry, fn func(*yang.Entry) []error) []error {
+	errs := fn(e)
+	names := make([]string, 0, len(e.Dir))
+	for n := range e.Dir {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		errs = append(errs, Walk(e.Dir[n], fn)...)
+	}
+	if e.RPC != nil {
+		for _, ce := range []*yang.Entry{e.RPC.Input, e.RPC.Output} {
+			if ce != nil {
+				errs = append(errs, Walk(ce, fn)...)
+			}
+		}
+	}
+	return errs
+}
+
+// Annotate stores v in the Annotation of e under key.
+func Annotate(e *yang.Entry, key string, v interface{}) {
+	if e.Annotation == nil {
+		e.Annotation = map[string]interface{}{}
+	}
+	e.Annotation[key] = v
+}
@@ -0,0 +1,164 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dialect
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/openconfig/gnmi/errdiff"
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+func process(t *testing.T, mods map[string]string, name string) *yang.Entry {
+	t.Helper()
+	ms := yang.NewModules()
+	for n, text := range mods {
+		if err := ms.Parse(text, n+".yang"); err != nil {
+			t.Fatalf("%s: %v", n, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	e, errs := ms.GetModule(name)
+	if len(errs) > 0 {
+		t.Fatalf("%s: %v", name, errs)
+	}
+	return e
+}
+
+var testModules = map[string]string{
+	"vendor-ext": `
+		module vendor-ext {
+			prefix "vx";
+			namespace "urn:vx";
+			include vendor-ext-sub;
+			extension note { argument "text"; }
+		}
+	`,
+	"vendor-ext-sub": `
+		submodule vendor-ext-sub {
+			belongs-to vendor-ext { prefix "vx"; }
+			extension flag;
+		}
+	`,
+	"other-ext": `
+		module other-ext {
+			prefix "ox";
+			namespace "urn:ox";
+			extension note { argument "text"; }
+		}
+	`,
+	"dev": `
+		module dev {
+			prefix "d";
+			namespace "urn:d";
+			import vendor-ext { prefix "v"; }
+			import other-ext { prefix "o"; }
+			container c {
+				v:note "one";
+				v:flag;
+				o:note "other";
+				v:unknown;
+				leaf b { type string; v:note "bad"; }
+			}
+			rpc r { input { leaf i { type string; v:flag; } } }
+		}
+	`,
+}
+
+func TestDecode(t *testing.T) {
+	e := process(t, testModules, "dev")
+
+	for _, tt := range []struct {
+		name    string
+		mode    Mode
+		want    []string
+		wantErr string
+	}{{
+		name:    "strict",
+		mode:    Strict,
+		want:    []string{"v:note", "v:flag", "v:unknown"},
+		wantErr: "v:unknown: unknown vendor-ext extension",
+	}, {
+		name: "tolerant",
+		mode: Tolerant,
+		want: []string{"v:note", "v:flag", "v:unknown"},
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			errs := Decode(e.Dir["c"], "vendor-ext", tt.mode, func(ext *yang.Statement) error {
+				got = append(got, ext.Keyword)
+				return nil
+			})
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			var err error
+			if len(errs) > 0 {
+				err = errs[0]
+			}
+			if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+				t.Error(diff)
+			}
+			if len(errs) > 1 {
+				t.Errorf("got %d errors, want at most 1: %v", len(errs), errs)
+			}
+		})
+	}
+
+	decodeErr := func(ext *yang.Statement) error {
+		if ext.Argument == "bad" {
+			return errors.New("bad note")
+		}
+		return nil
+	}
+	if errs := Decode(e.Dir["c"].Dir["b"], "vendor-ext", Strict, decodeErr); len(errs) != 1 || !strings.Contains(errs[0].Error(), "v:note: bad note") {
+		t.Errorf("strict: got errors %v, want v:note: bad note", errs)
+	}
+	if errs := Decode(e.Dir["c"].Dir["b"], "vendor-ext", Tolerant, decodeErr); len(errs) != 0 {
+		t.Errorf("tolerant: got errors %v, want none", errs)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	e := process(t, testModules, "dev")
+	var got []string
+	Walk(e, func(e *yang.Entry) []error {
+		got = append(got, e.Path())
+		return nil
+	})
+	want := "/dev /dev/c /dev/c/b /dev/r /dev/r/input /dev/r/input/i"
+	if strings.Join(got, " ") != want {
+		t.Errorf("got %v, want %s", got, want)
+	}
+
+	var found []string
+	Walk(e, func(e *yang.Entry) []error {
+		Decode(e, "vendor-ext", Tolerant, func(ext *yang.Statement) error {
+			Annotate(e, "found", Name(ext))
+			return nil
+		})
+		if v, ok := e.Annotation["found"]; ok {
+			found = append(found, e.Name+"="+v.(string))
+		}
+		return nil
+	})
+	if got, want := strings.Join(found, " "), "c=unknown b=note i=flag"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package junos decodes the extensions defined by the Juniper
+// junos-extension and junos-common-odl-extensions modules into typed
+// metadata.
+package junos
+
+import (
+	"errors"
+
+	"github.com/openconfig/goyang/pkg/dialect"
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+const (
+	// Module is the name of the module that defines the Junos
+	// constraint extensions.
+	Module = "junos-extension"
+	// DisplayModule is the name of the module that defines the Junos
+	// display hints.
+	DisplayModule = "junos-common-odl-extensions"
+)
+
+// Key is the key in the Annotation field of an Entry that Annotate stores
+// the entry's *Info under.
+const Key = "junos"
+
+// Info is the Junos metadata of an entry.
+type Info struct {
+	Must           []string          // junos:must, in order
+	MustMessage    string            // junos:must-message
+	PosixPattern   string            // junos:posix-pattern
+	PatternMessage string            // junos:pattern-message
+	Display        map[string]string // display hints by extension name
+	Other          []*yang.Statement // all other junos-extension extensions
+}
+
+// Decode returns the Junos metadata of e, or nil if e does not use any
+// Junos extensions.
+func Decode(e *yang.Entry, mode dialect.Mode) (*Info, []error) {
+	var info *Info
+	errs := dialect.Decode(e, Module, mode, func(ext *yang.Statement) error {
+		if info == nil {
+			info = &Info{}
+		}
+		return info.decode(ext)
+	})
+	errs = append(errs, dialect.Decode(e, DisplayModule, mode, func(ext *yang.Statement) error {
+		if info == nil {
+			info = &Info{}
+		}
+		if info.Display == nil {
+			info.Display = map[string]string{}
+		}
+		info.Display[dialect.Name(ext)] = ext.Argument
+		return nil
+	})...)
+	return info, errs
+}
+
+// decode adds the junos-extension extension ext to i.
+func (i *Info) decode(ext *yang.Statement) error {
+	set := func(p *string) error {
+		arg, err := dialect.Argument(ext)
+		switch {
+		case err != nil:
+			return err
+		case *p != "":
+			return errors.New("duplicate statement")
+		}
+		*p = arg
+		return nil
+	}
+	switch dialect.Name(ext) {
+	case "must":
+		arg, err := dialect.Argument(ext)
+		if err != nil {
+			return err
+		}
+		i.Must = append(i.Must, arg)
+	case "must-message":
+		return set(&i.MustMessage)
+	case "posix-pattern":
+		return set(&i.PosixPattern)
+	case "pattern-message":
+		return set(&i.PatternMessage)
+	default:
+		i.Other = append(i.Other, ext)
+	}
+	return nil
+}
+
+// Annotate decodes the Junos metadata of e and all of its descendants.
+// The metadata of each entry that uses Junos extensions is stored in the
+// entry's Annotation field and may be retrieved with Get.
+func Annotate(e *yang.Entry, mode dialect.Mode) []error {
+	return dialect.Walk(e, func(e *yang.Entry) []error {
+		info, errs := Decode(e, mode)
+		if info != nil {
+			dialect.Annotate(e, Key, info)
+		}
+		return errs
+	})
+}
+
+// Get returns the Junos metadata that Annotate stored in e, or nil.
+func Get(e *yang.Entry) *Info {
+	info, _ := e.Annotation[Key].(*Info)
+	return info
+}
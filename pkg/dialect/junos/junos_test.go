@@ -0,0 +1,87 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package junos
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/goyang/pkg/dialect"
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+func TestAnnotate(t *testing.T) {
+	ms := yang.NewModules()
+	for n, text := range map[string]string{
+		"junos-extension": `
+			module junos-extension {
+				prefix "junos";
+				namespace "http://yang.juniper.net/junos/common/types";
+				extension must { argument stmt; }
+				extension must-message { argument message; }
+				extension posix-pattern { argument value; }
+				extension pattern-message { argument value; }
+			}
+		`,
+		"junos-common-odl-extensions": `
+			module junos-common-odl-extensions {
+				prefix "junos-odl";
+				namespace "http://yang.juniper.net/junos/common/odl";
+				extension format { argument value; }
+			}
+		`,
+		"dev": `
+			module dev {
+				prefix "d";
+				namespace "urn:d";
+				import junos-extension { prefix "junos"; }
+				import junos-common-odl-extensions { prefix "junos-odl"; }
+				leaf l {
+					type string;
+					junos:must "(!.. disable)";
+					junos:must-message "must not be disabled";
+					junos:posix-pattern "^[a-z]+$";
+					junos:pattern-message "lower case only";
+					junos-odl:format "%s";
+				}
+				leaf plain { type string; }
+			}
+		`,
+	} {
+		if err := ms.Parse(text, n+".yang"); err != nil {
+			t.Fatalf("%s: %v", n, err)
+		}
+	}
+	e, errs := ms.GetModule("dev")
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if errs := Annotate(e, dialect.Strict); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	want := &Info{
+		Must:           []string{"(!.. disable)"},
+		MustMessage:    "must not be disabled",
+		PosixPattern:   "^[a-z]+$",
+		PatternMessage: "lower case only",
+		Display:        map[string]string{"format": "%s"},
+	}
+	if diff := cmp.Diff(want, Get(e.Dir["l"])); diff != "" {
+		t.Errorf("l (-want, +got):\n%s", diff)
+	}
+	if info := Get(e.Dir["plain"]); info != nil {
+		t.Errorf("plain: got %+v, want nil", info)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tailf decodes the extensions defined by the Tail-f tailf-common
+// module, as used by Cisco NSO and ConfD, into typed metadata.
+package tailf
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/dialect"
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+// Module is the name of the module that defines the Tail-f extensions.
+const Module = "tailf-common"
+
+// Key is the key in the Annotation field of an Entry that Annotate stores
+// the entry's *Info under.
+const Key = "tailf"
+
+// Info is the Tail-f metadata of an entry.
+type Info struct {
+	Info        string            // tailf:info, the help text of the node
+	InfoHTML    string            // tailf:info-html
+	AltName     string            // tailf:alt-name
+	DisplayWhen string            // tailf:display-when
+	Callpoint   string            // tailf:callpoint
+	Actionpoint string            // tailf:actionpoint
+	Hidden      []string          // tailf:hidden, the hide groups of the node
+	CLI         map[string]string // tailf:cli-*, by name less "cli-"
+	Other       []*yang.Statement // all other tailf-common extensions
+}
+
+// Decode returns the Tail-f metadata of e, or nil if e does not use any
+// Tail-f extensions.
+func Decode(e *yang.Entry, mode dialect.Mode) (*Info, []error) {
+	var info *Info
+	errs := dialect.Decode(e, Module, mode, func(ext *yang.Statement) error {
+		if info == nil {
+			info = &Info{}
+		}
+		return info.decode(ext)
+	})
+	return info, errs
+}
+
+// decode adds the extension ext to i.
+func (i *Info) decode(ext *yang.Statement) error {
+	name := dialect.Name(ext)
+	set := func(p *string) error {
+		arg, err := dialect.Argument(ext)
+		switch {
+		case err != nil:
+			return err
+		case *p != "":
+			return errors.New("duplicate statement")
+		}
+		*p = arg
+		return nil
+	}
+	switch {
+	case name == "info":
+		return set(&i.Info)
+	case name == "info-html":
+		return set(&i.InfoHTML)
+	case name == "alt-name":
+		return set(&i.AltName)
+	case name == "display-when":
+		return set(&i.DisplayWhen)
+	case name == "callpoint":
+		return set(&i.Callpoint)
+	case name == "actionpoint":
+		return set(&i.Actionpoint)
+	case name == "hidden":
+		arg, err := dialect.Argument(ext)
+		if err != nil {
+			return err
+		}
+		i.Hidden = append(i.Hidden, arg)
+	case strings.HasPrefix(name, "cli-"):
+		if i.CLI == nil {
+			i.CLI = map[string]string{}
+		}
+		i.CLI[strings.TrimPrefix(name, "cli-")] = ext.Argument
+	default:
+		i.Other = append(i.Other, ext)
+	}
+	return nil
+}
+
+// Annotate decodes the Tail-f metadata of e and all of its descendants.
+// The metadata of each entry that uses Tail-f extensions is stored in the
+// entry's Annotation field and may be retrieved with Get.
+func Annotate(e *yang.Entry, mode dialect.Mode) []error {
+	return dialect.Walk(e, func(e *yang.Entry) []error {
+		info, errs := Decode(e, mode)
+		if info != nil {
+			dialect.Annotate(e, Key, info)
+		}
+		return errs
+	})
+}
+
+// Get returns the Tail-f metadata that Annotate stored in e, or nil.
+func Get(e *yang.Entry) *Info {
+	info, _ := e.Annotation[Key].(*Info)
+	return info
+}
@@ -0,0 +1,140 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tailf
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/openconfig/goyang/pkg/dialect"
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+var mods = map[string]string{
+	"tailf-common": `
+		module tailf-common {
+			prefix "tailf";
+			namespace "http://tail-f.com/yang/common";
+			include tailf-cli-extensions;
+			extension info { argument text; }
+			extension hidden { argument tag; }
+			extension callpoint { argument id; }
+			extension export { argument agent; }
+		}
+	`,
+	"tailf-cli-extensions": `
+		submodule tailf-cli-extensions {
+			belongs-to tailf-common { prefix "tailf"; }
+			extension cli-add-mode;
+			extension cli-mode-name { argument name; }
+		}
+	`,
+	"dev": `
+		module dev {
+			prefix "d";
+			namespace "urn:d";
+			import tailf-common { prefix "tailf"; }
+			container c {
+				tailf:info "Configure c";
+				tailf:hidden debug;
+				tailf:hidden full;
+				tailf:cli-add-mode;
+				tailf:cli-mode-name "config-c";
+				tailf:export netconf;
+				leaf l { type string; tailf:callpoint cp; tailf:cli-new-thing; }
+				leaf plain { type string; }
+			}
+		}
+	`,
+}
+
+func TestAnnotate(t *testing.T) {
+	ms := yang.NewModules()
+	for n, text := range mods {
+		if err := ms.Parse(text, n+".yang"); err != nil {
+			t.Fatalf("%s: %v", n, err)
+		}
+	}
+	e, errs := ms.GetModule("dev")
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	if errs := Annotate(e, dialect.Strict); len(errs) != 1 {
+		t.Errorf("strict: got errors %v, want 1 for tailf:cli-new-thing", errs)
+	}
+	if errs := Annotate(e, dialect.Tolerant); len(errs) != 0 {
+		t.Errorf("tolerant: got errors %v, want none", errs)
+	}
+
+	ignore := cmpopts.IgnoreFields(Info{}, "Other")
+	c := Get(e.Dir["c"])
+	want := &Info{
+		Info:   "Configure c",
+		Hidden: []string{"debug", "full"},
+		CLI:    map[string]string{"add-mode": "", "mode-name": "config-c"},
+	}
+	if diff := cmp.Diff(want, c, ignore); diff != "" {
+		t.Errorf("c (-want, +got):\n%s", diff)
+	}
+	if len(c.Other) != 1 || c.Other[0].Keyword != "tailf:export" {
+		t.Errorf("c: got Other %v, want tailf:export", c.Other)
+	}
+	want = &Info{
+		Callpoint: "cp",
+		CLI:       map[string]string{"new-thing": ""},
+	}
+	if diff := cmp.Diff(want, Get(e.Dir["c"].Dir["l"]), ignore); diff != "" {
+		t.Errorf("l (-want, +got):\n%s", diff)
+	}
+	if info := Get(e.Dir["c"].Dir["plain"]); info != nil {
+		t.Errorf("plain: got %+v, want nil", info)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	ms := yang.NewModules()
+	for n, text := range map[string]string{
+		"tailf-common":         mods["tailf-common"],
+		"tailf-cli-extensions": mods["tailf-cli-extensions"],
+		"bad": `
+			module bad {
+				prefix "b";
+				namespace "urn:b";
+				import tailf-common { prefix "tailf"; }
+				container c { tailf:info "one"; tailf:info "two"; tailf:hidden; }
+			}
+		`,
+	} {
+		if err := ms.Parse(text, n+".yang"); err != nil {
+			t.Fatalf("%s: %v", n, err)
+		}
+	}
+	e, errs := ms.GetModule("bad")
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	info, errs := Decode(e.Dir["c"], dialect.Strict)
+	if len(errs) != 2 {
+		t.Errorf("got errors %v, want duplicate statement and missing argument", errs)
+	}
+	if info.Info != "one" {
+		t.Errorf("got Info %q, want %q", info.Info, "one")
+	}
+	if _, errs := Decode(e.Dir["c"], dialect.Tolerant); len(errs) != 0 {
+		t.Errorf("tolerant: got errors %v, want none", errs)
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gnmiutil converts between yang Entry trees and gNMI paths.
+//
+// A schema path names a schema node and has no keys.  A data path names a
+// data node and includes the keys of each list along the path.  In both,
+// choice and case statements, which are not data nodes, do not appear.
+// Element names may be prefixed by the name of the module defining them,
+// as in "openconfig-interfaces:interfaces"; the prefix is ignored.
+package gnmiutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+// SchemaPath returns the gNMI schema path of e, relative to the module e is
+// defined in.
+func SchemaPath(e *yang.Entry) *gpb.Path {
+	p := &gpb.Path{}
+	for _, ae := range dataAncestors(e) {
+		p.Elem = append(p.Elem, &gpb.PathElem{Name: ae.Name})
+	}
+	return p
+}
+
+// DataPath returns the gNMI data path of e, relative to the module e is
+// defined in.  keys supplies the key values of each list along the path,
+// starting at the top.  Each map must contain exactly the keys of its
+// list.
+func DataPath(e *yang.Entry, keys ...map[string]string) (*gpb.Path, error) {
+	p := &gpb.Path{}
+	for _, ae := range dataAncestors(e) {
+		pe := &gpb.PathElem{Name: ae.Name}
+		if ae.IsList() && ae.Key != "" {
+			if len(keys) == 0 {
+				return nil, fmt.Errorf("%s: no keys for list %s", e.Path(), ae.Name)
+			}
+			if err := checkKeys(ae, keys[0]); err != nil {
+				return nil, err
+			}
+			pe.Key = keys[0]
+			keys = keys[1:]
+		}
+		p.Elem = append(p.Elem, pe)
+	}
+	if len(keys) > 0 {
+		return nil, fmt.Errorf("%s: %d unused sets of keys", e.Path(), len(keys))
+	}
+	return p, nil
+}
+
+// dataAncestors returns the data nodes from the top of e's module down to
+// e, excluding the module itself.
+func dataAncestors(e *yang.Entry) []*yang.Entry {
+	var es []*yang.Entry
+	for ; e != nil && e.Parent != nil; e = e.Parent {
+		if !e.IsChoice() && !e.IsCase() {
+			es = append(es, e)
+		}
+	}
+	for i, j := 0, len(es)-1; i < j; i, j = i+1, j-1 {
+		es[i], es[j] = es[j], es[i]
+	}
+	return es
+}
+
+// checkKeys returns an error if keys are not the keys of the list e.
+func checkKeys(e *yang.Entry, keys map[string]string) error {
+	names := strings.Fields(e.Key)
+	if len(keys) != len(names) {
+		return fmt.Errorf("%s: got %d keys, want %d (%s)", e.Path(), len(keys), len(names), e.Key)
+	}
+	for _, n := range names {
+		if _, ok := keys[n]; !ok {
+			return fmt.Errorf("%s: missing key %s", e.Path(), n)
+		}
+	}
+	return nil
+}
+
+// A PathError is returned when a path does not match a schema.
+type PathError struct {
+	Path        *gpb.Path
+	Elem        int      // index of the element in error
+	Err         string   // description of the error
+	Suggestions []string // names that are near misses for the element
+}
+
+func (e *PathError) Error() string {
+	var b strings.Builder
+	b.WriteString(PathString(e.Path))
+	fmt.Fprintf(&b, ": element %d: %s", e.Elem, e.Err)
+	switch len(e.Suggestions) {
+	case 0:
+	case 1:
+		fmt.Fprintf(&b, " (did you mean %s?)", e.Suggestions[0])
+	default:
+		fmt.Fprintf(&b, " (did you mean one of %s?)", strings.Join(e.Suggestions, ", "))
+	}
+	return b.String()
+}
+
+// FindEntry returns the entry of root, normally a module, that p names.
+// p may be either a schema path or a data path.  If p does not name an
+// entry, or p has keys that do not match the lists along the path, a
+// *PathError is returned.
+func FindEntry(root *yang.Entry, p *gpb.Path) (*yang.Entry, error) {
+	e := root
+	for i, pe := range p.GetElem() {
+		name := pe.Name
+		if x := strings.Index(name, ":"); x >= 0 {
+			name = name[x+1:]
+		}
+		children := dataChildren(e)
+		ce := children[name]
+		if ce == nil {
+			perr := &PathError{Path: p, Elem: i, Err: fmt.Sprintf("%s has no child %s", e.Path(), name)}
+			names := make([]string, 0, len(children))
+			for n := range children {
+				names = append(names, n)
+			}
+			perr.Suggestions = suggest(name, names)
+			return nil, perr
+		}
+		if len(pe.Key) > 0 {
+			if !ce.IsList() {
+				return nil, &PathError{Path: p, Elem: i, Err: fmt.Sprintf("%s is not a list but has keys", ce.Path())}
+			}
+			if err := checkKeys(ce, pe.Key); err != nil {
+				names := strings.Fields(ce.Key)
+				var bad []string
+				for k := range pe.Key {
+					bad = append(bad, k)
+				}
+				sort.Strings(bad)
+				perr := &PathError{Path: p, Elem: i, Err: err.Error()}
+				for _, k := range bad {
+					perr.Suggestions = append(perr.Suggestions, suggest(k, names)...)
+				}
+				return nil, perr
+			}
+		}
+		e = ce
+	}
+	return e, nil
+}
+
+// ValidatePath returns an error if p, a schema or data path, does not name
+// an entry in root.
+func ValidatePath(root *yang.Entry, p *gpb.Path) error {
+	_, err := FindEntry(root, p)
+	return err
+}
+
+// dataChildren returns the data node children of e, looking through any
+// choice and case statements.
+func dataChildren(e *yang.Entry) map[string]*yang.Entry {
+	children := map[string]*yang.Entry{}
+	var add func(*yang.Entry)
+	add = func(e *yang.Entry) {
+		for n, ce := range e.Dir {
+			if ce.IsChoice() || ce.IsCase() {
+				add(ce)
+				continue
+			}
+			children[n] = ce
+		}
+	}
+	add(e)
+	return children
+}
+
+// PathString returns p in the string form used by gNMI, e.g.,
+// "/interfaces/interface[name=eth0]/config".
+func PathString(p *gpb.Path) string {
+	if len(p.GetElem()) == 0 {
+		return "/"
+	}
+	var b strings.Builder
+	for _, pe := range p.Elem {
+		b.WriteByte('/')
+		b.WriteString(pe.Name)
+		keys := make([]string, 0, len(pe.Key))
+		for k := range pe.Key {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(&b, "[%s=%s]", k, pe.Key[k])
+		}
+	}
+	return b.String()
+}
+
+// suggest returns the names that are close to name, closest first.
+func suggest(name string, names []string) []string {
+	max := len(name) / 3
+	if max < 1 {
+		max = 1
+	}
+	type match struct {
+		name string
+		d    int
+	}
+	var ms []match
+	for _, n := range names {
+		if d := distance(name, n); d <= max {
+			ms = append(ms, match{n, d})
+		}
+	}
+	sort.Slice(ms, func(i, j int) bool {
+		if ms[i].d != ms[j].d {
+			return ms[i].d < ms[j].d
+		}
+		return ms[i].name < ms[j].name
+	})
+	var s []string
+	for _, m := range ms {
+		s = append(s, m.name)
+	}
+	return s
+}
+
+// distance returns the edit distance between a and b, counting the
+// insertion, deletion, or substitution of a byte, or the transposition of
+// two adjacent bytes, as a single edit.
+func distance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min3(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] && d[i-2][j-2]+1 < d[i][j] {
+				d[i][j] = d[i-2][j-2] + 1
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
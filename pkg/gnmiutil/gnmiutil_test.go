@@ -0,0 +1,234 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnmiutil
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/gnmi/errdiff"
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+const testModule = `
+module test {
+	prefix "t";
+	namespace "urn:t";
+	container interfaces {
+		list interface {
+			key "name";
+			leaf name { type string; }
+			container config { leaf mtu { type uint16; } }
+			list address {
+				key "ip prefix-length";
+				leaf ip { type string; }
+				leaf prefix-length { type uint8; }
+			}
+			choice mode {
+				case routed { leaf vrf { type string; } }
+				leaf bridge { type string; }
+			}
+		}
+	}
+}
+`
+
+func testEntry(t *testing.T) *yang.Entry {
+	t.Helper()
+	ms := yang.NewModules()
+	if err := ms.Parse(testModule, "test.yang"); err != nil {
+		t.Fatal(err)
+	}
+	e, errs := ms.GetModule("test")
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	return e
+}
+
+// parsePath returns the gNMI path for a simple string path, used to keep
+// the test tables short.  Keys are not supported.
+func parsePath(s string) *gpb.Path {
+	p := &gpb.Path{}
+	for _, n := range splitPath(s) {
+		p.Elem = append(p.Elem, &gpb.PathElem{Name: n})
+	}
+	return p
+}
+
+func splitPath(s string) []string {
+	var names []string
+	start := 1
+	for i := 1; i <= len(s); i++ {
+		if i == len(s) || s[i] == '/' {
+			if i > start {
+				names = append(names, s[start:i])
+			}
+			start = i + 1
+		}
+	}
+	return names
+}
+
+func TestSchemaPath(t *testing.T) {
+	e := testEntry(t)
+	for _, tt := range []struct {
+		find string
+		want string
+	}{
+		{"/interfaces/interface/config/mtu", "/interfaces/interface/config/mtu"},
+		{"/interfaces/interface/mode/routed/vrf", "/interfaces/interface/vrf"},
+		{"/interfaces/interface/mode/bridge/bridge", "/interfaces/interface/bridge"},
+	} {
+		ce := e.Find(tt.find)
+		if ce == nil {
+			t.Errorf("%s: not found", tt.find)
+			continue
+		}
+		if got := PathString(SchemaPath(ce)); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.find, got, tt.want)
+		}
+		// The schema path must lead back to the same entry.
+		if got, err := FindEntry(e, SchemaPath(ce)); err != nil || got != ce {
+			t.Errorf("%s: FindEntry got %v, %v, want %s", tt.find, got, err, ce.Path())
+		}
+	}
+}
+
+func TestDataPath(t *testing.T) {
+	e := testEntry(t)
+	addr := e.Find("/interfaces/interface/address/ip")
+	for _, tt := range []struct {
+		name    string
+		keys    []map[string]string
+		want    string
+		wantErr string
+	}{{
+		name: "keys",
+		keys: []map[string]string{{"name": "eth0"}, {"ip": "10.0.0.1", "prefix-length": "24"}},
+		want: "/interfaces/interface[name=eth0]/address[ip=10.0.0.1][prefix-length=24]/ip",
+	}, {
+		name:    "too few",
+		keys:    []map[string]string{{"name": "eth0"}},
+		wantErr: "no keys for list address",
+	}, {
+		name:    "too many",
+		keys:    []map[string]string{{"name": "eth0"}, {"ip": "10.0.0.1", "prefix-length": "24"}, {"x": "y"}},
+		wantErr: "1 unused sets of keys",
+	}, {
+		name:    "wrong key",
+		keys:    []map[string]string{{"nam": "eth0"}, {"ip": "10.0.0.1", "prefix-length": "24"}},
+		wantErr: "missing key name",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := DataPath(addr, tt.keys...)
+			if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+				t.Fatal(diff)
+			}
+			if err != nil {
+				return
+			}
+			if got := PathString(p); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			if err := ValidatePath(e, p); err != nil {
+				t.Errorf("ValidatePath: %v", err)
+			}
+		})
+	}
+}
+
+func TestFindEntry(t *testing.T) {
+	e := testEntry(t)
+	keyed := func(p *gpb.Path, elem int, keys map[string]string) *gpb.Path {
+		p.Elem[elem].Key = keys
+		return p
+	}
+	for _, tt := range []struct {
+		name            string
+		path            *gpb.Path
+		want            string
+		wantErr         string
+		wantSuggestions []string
+	}{{
+		name: "prefixed",
+		path: parsePath("/test:interfaces/interface/config"),
+		want: "/test/interfaces/interface/config",
+	}, {
+		name: "keyed",
+		path: keyed(parsePath("/interfaces/interface/config"), 1, map[string]string{"name": "eth0"}),
+		want: "/test/interfaces/interface/config",
+	}, {
+		name:            "near miss",
+		path:            parsePath("/interfaces/interfaces/config"),
+		wantErr:         "element 1: /test/interfaces has no child interfaces (did you mean interface?)",
+		wantSuggestions: []string{"interface"},
+	}, {
+		name:    "no suggestion",
+		path:    parsePath("/interfaces/interface/statistics"),
+		wantErr: "has no child statistics",
+	}, {
+		name:    "case name",
+		path:    parsePath("/interfaces/interface/routed"),
+		wantErr: "has no child routed",
+	}, {
+		name:    "keys on container",
+		path:    keyed(parsePath("/interfaces/interface/config"), 2, map[string]string{"name": "eth0"}),
+		wantErr: "/test/interfaces/interface/config is not a list but has keys",
+	}, {
+		name:            "misspelled key",
+		path:            keyed(parsePath("/interfaces/interface"), 1, map[string]string{"nmae": "eth0"}),
+		wantErr:         "missing key name",
+		wantSuggestions: []string{"name"},
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindEntry(e, tt.path)
+			if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+				t.Fatal(diff)
+			}
+			if err != nil {
+				perr, ok := err.(*PathError)
+				if !ok {
+					t.Fatalf("got error of type %T, want *PathError", err)
+				}
+				if diff := cmp.Diff(tt.wantSuggestions, perr.Suggestions); diff != "" {
+					t.Errorf("Suggestions (-want, +got):\n%s", diff)
+				}
+				return
+			}
+			if got.Path() != tt.want {
+				t.Errorf("got %s, want %s", got.Path(), tt.want)
+			}
+		})
+	}
+}
+
+func TestDistance(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"interface", "interfaces", 1},
+		{"name", "nmae", 1},
+		{"kitten", "sitting", 3},
+	} {
+		if got := distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("distance(%q, %q) got %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package restconf converts between yang Entry trees and RESTCONF resource
+// paths.
+//
+// An api-path (RFC 8040 section 3.5.3) identifies a data resource relative
+// to the RESTCONF datastore resource, e.g., "/restconf/data".  Each node is
+// named by its identifier, which is qualified by its module name when it is
+// the top node or is defined in a different module than its parent.  A
+// list instance is identified by appending "=" and its comma separated key
+// values, in the order of the list's key statement, and a leaf-list
+// instance by appending "=" and its value.  Choice and case statements do
+// not appear in api-paths.
+//
+// Key values are given as lists of strings, one list for each list or
+// leaf-list instance along the path, starting at the top.
+package restconf
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+// APIPath returns the api-path of e.  keys supplies the key values of each
+// list along the path to e.  Keys must be given for all lists above e.  If
+// e is itself a list or leaf-list, keys for it may be omitted, in which
+// case the path identifies the list as a whole.
+func APIPath(e *yang.Entry, keys ...[]string) (string, error) {
+	es := dataAncestors(e)
+	if len(es) == 0 {
+		return "/", nil
+	}
+	var b strings.Builder
+	parentModule := ""
+	for i, ae := range es {
+		mod, err := ae.InstantiatingModule()
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte('/')
+		if mod != parentModule {
+			b.WriteString(mod)
+			b.WriteByte(':')
+		}
+		parentModule = mod
+		b.WriteString(ae.Name)
+
+		want := keyCount(ae)
+		if want == 0 {
+			continue
+		}
+		if len(keys) == 0 {
+			if i == len(es)-1 {
+				break
+			}
+			return "", fmt.Errorf("%s: no keys for %s", e.Path(), ae.Name)
+		}
+		if len(keys[0]) != want {
+			return "", fmt.Errorf("%s: got %d keys for %s, want %d", e.Path(), len(keys[0]), ae.Name, want)
+		}
+		for x, v := range keys[0] {
+			if x == 0 {
+				b.WriteByte('=')
+			} else {
+				b.WriteByte(',')
+			}
+			b.WriteString(escape(v))
+		}
+		keys = keys[1:]
+	}
+	if len(keys) > 0 {
+		return "", fmt.Errorf("%s: %d unused sets of keys", e.Path(), len(keys))
+	}
+	return b.String(), nil
+}
+
+// ParseAPIPath returns the entry identified by the api-path p along with
+// the key values of each list and leaf-list instance in p.  The modules of
+// ms must have been processed.
+func ParseAPIPath(ms *yang.Modules, p string) (*yang.Entry, [][]string, error) {
+	if !strings.HasPrefix(p, "/") {
+		return nil, nil, fmt.Errorf("%s: api-path must start with /", p)
+	}
+	if p == "/" {
+		return nil, nil, fmt.Errorf("%s: api-path names the datastore", p)
+	}
+	var e *yang.Entry
+	var keys [][]string
+	parentModule := ""
+	for i, seg := range strings.Split(p[1:], "/") {
+		id, vals, hasKeys := seg, "", false
+		if x := strings.Index(seg, "="); x >= 0 {
+			id, vals, hasKeys = seg[:x], seg[x+1:], true
+		}
+		mod, name := "", id
+		if x := strings.Index(id, ":"); x >= 0 {
+			mod, name = id[:x], id[x+1:]
+		}
+		if i == 0 {
+			if mod == "" {
+				return nil, nil, fmt.Errorf("%s: %s: top node must be qualified by its module name", p, id)
+			}
+			m := ms.Modules[mod]
+			if m == nil {
+				return nil, nil, fmt.Errorf("%s: unknown module %s", p, mod)
+			}
+			e = yang.ToEntry(m)
+		}
+		if mod == "" {
+			mod = parentModule
+		}
+		ce := dataChildren(e)[name]
+		if ce == nil {
+			return nil, nil, fmt.Errorf("%s: %s has no child %s", p, e.Path(), name)
+		}
+		if m, err := ce.InstantiatingModule(); err != nil {
+			return nil, nil, err
+		} else if m != mod {
+			return nil, nil, fmt.Errorf("%s: %s is defined in module %s, not %s", p, name, m, mod)
+		}
+		parentModule = mod
+		e = ce
+
+		if !hasKeys {
+			continue
+		}
+		want := keyCount(e)
+		if want == 0 {
+			return nil, nil, fmt.Errorf("%s: %s is not a list or leaf-list but has keys", p, name)
+		}
+		var kv []string
+		for _, v := range strings.Split(vals, ",") {
+			u, err := unescape(v)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %s: %v", p, name, err)
+			}
+			kv = append(kv, u)
+		}
+		if len(kv) != want {
+			return nil, nil, fmt.Errorf("%s: got %d keys for %s, want %d", p, len(kv), name, want)
+		}
+		keys = append(keys, kv)
+	}
+	return e, keys, nil
+}
+
+// keyCount returns the number of key values that identify an instance of
+// e, i.e., the number of keys of a list or 1 for a leaf-list.  Zero is
+// returned for all other entries, including lists without keys.
+func keyCount(e *yang.Entry) int {
+	switch {
+	case e.IsLeafList():
+		return 1
+	case e.IsList():
+		return len(strings.Fields(e.Key))
+	}
+	return 0
+}
+
+// dataAncestors returns the data nodes from the top of e's module down to
+// e, excluding the module itself.
+func dataAncestors(e *yang.Entry) []*yang.Entry {
+	var es []*yang.Entry
+	for ; e != nil && e.Parent != nil; e = e.Parent {
+		if !e.IsChoice() && !e.IsCase() {
+			es = append(es, e)
+		}
+	}
+	for i, j := 0, len(es)-1; i < j; i, j = i+1, j-1 {
+		es[i], es[j] = es[j], es[i]
+	}
+	return es
+}
+
+// dataChildren returns the data node children of e, looking through any
+// choice and case statements.
+func dataChildren(e *yang.Entry) map[string]*yang.Entry {
+	children := map[string]*yang.Entry{}
+	var add func(*yang.Entry)
+	add = func(e *yang.Entry) {
+		for n, ce := range e.Dir {
+			if ce.IsChoice() || ce.IsCase() {
+				add(ce)
+				continue
+			}
+			children[n] = ce
+		}
+	}
+	add(e)
+	return children
+}
+
+// escape percent-encodes all the bytes of s other than the unreserved
+// characters of RFC 3986, as required for key values (RFC 8040 section
+// 3.5.3.1).
+func escape(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '.', c == '_', c == '~':
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&0xf])
+		}
+	}
+	return b.String()
+}
+
+// unescape decodes the percent-encoded bytes of s.
+func unescape(s string) (string, error) {
+	if !strings.Contains(s, "%") {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+2 >= len(s) {
+			return "", fmt.Errorf("invalid escape in %q", s)
+		}
+		h, ok1 := unhex(s[i+1])
+		l, ok2 := unhex(s[i+2])
+		if !ok1 || !ok2 {
+			return "", fmt.Errorf("invalid escape in %q", s)
+		}
+		b.WriteByte(h<<4 | l)
+		i += 2
+	}
+	return b.String(), nil
+}
+
+func unhex(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
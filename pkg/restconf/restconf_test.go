@@ -0,0 +1,176 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restconf
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/gnmi/errdiff"
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+func testModules(t *testing.T) *yang.Modules {
+	t.Helper()
+	ms := yang.NewModules()
+	for n, text := range map[string]string{
+		"example-jukebox": `
+			module example-jukebox {
+				prefix "jbox";
+				namespace "urn:jbox";
+				container jukebox {
+					container library {
+						list artist {
+							key "name";
+							leaf name { type string; }
+							list album {
+								key "name year";
+								leaf name { type string; }
+								leaf year { type uint16; }
+								leaf-list genre { type string; }
+							}
+						}
+					}
+					choice source {
+						case radio { leaf station { type string; } }
+					}
+				}
+			}
+		`,
+		"example-augment": `
+			module example-augment {
+				prefix "aug";
+				namespace "urn:aug";
+				import example-jukebox { prefix "jbox"; }
+				augment "/jbox:jukebox/jbox:library" {
+					container stats { leaf plays { type uint32; } }
+				}
+			}
+		`,
+	} {
+		if err := ms.Parse(text, n+".yang"); err != nil {
+			t.Fatalf("%s: %v", n, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	return ms
+}
+
+func TestAPIPath(t *testing.T) {
+	ms := testModules(t)
+	root := yang.ToEntry(ms.Modules["example-jukebox"])
+	for _, tt := range []struct {
+		name    string
+		find    string
+		keys    [][]string
+		want    string
+		wantErr string
+	}{{
+		name: "container",
+		find: "/jukebox/library",
+		want: "/example-jukebox:jukebox/library",
+	}, {
+		name: "list instance",
+		find: "/jukebox/library/artist/album/year",
+		keys: [][]string{{"Foo Fighters"}, {"Wasting Light", "2011"}},
+		want: "/example-jukebox:jukebox/library/artist=Foo%20Fighters/album=Wasting%20Light,2011/year",
+	}, {
+		name: "whole list",
+		find: "/jukebox/library/artist",
+		want: "/example-jukebox:jukebox/library/artist",
+	}, {
+		name: "leaf-list instance",
+		find: "/jukebox/library/artist/album/genre",
+		keys: [][]string{{"a/b"}, {"c,d", "1999"}, {"rock=roll"}},
+		want: "/example-jukebox:jukebox/library/artist=a%2Fb/album=c%2Cd,1999/genre=rock%3Droll",
+	}, {
+		name: "choice",
+		find: "/jukebox/source/radio/station",
+		want: "/example-jukebox:jukebox/station",
+	}, {
+		name: "augment",
+		find: "/jukebox/library/stats/plays",
+		want: "/example-jukebox:jukebox/library/example-augment:stats/plays",
+	}, {
+		name:    "missing keys",
+		find:    "/jukebox/library/artist/name",
+		wantErr: "no keys for artist",
+	}, {
+		name:    "wrong number of keys",
+		find:    "/jukebox/library/artist/album",
+		keys:    [][]string{{"a"}, {"b"}},
+		wantErr: "got 1 keys for album, want 2",
+	}, {
+		name:    "extra keys",
+		find:    "/jukebox/library",
+		keys:    [][]string{{"a"}},
+		wantErr: "1 unused sets of keys",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			e := root.Find(tt.find)
+			if e == nil {
+				t.Fatalf("%s not found", tt.find)
+			}
+			got, err := APIPath(e, tt.keys...)
+			if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+				t.Fatal(diff)
+			}
+			if err != nil {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+
+			// Parsing the path must lead back to e and its keys.
+			pe, keys, err := ParseAPIPath(ms, got)
+			if err != nil {
+				t.Fatalf("ParseAPIPath(%s): %v", got, err)
+			}
+			if pe != e {
+				t.Errorf("ParseAPIPath(%s) got %s, want %s", got, pe.Path(), e.Path())
+			}
+			if diff := cmp.Diff(tt.keys, keys); diff != "" {
+				t.Errorf("ParseAPIPath(%s) keys (-want, +got):\n%s", got, diff)
+			}
+		})
+	}
+}
+
+func TestParseAPIPathErrors(t *testing.T) {
+	ms := testModules(t)
+	for _, tt := range []struct {
+		path    string
+		wantErr string
+	}{
+		{"jukebox", "must start with /"},
+		{"/", "names the datastore"},
+		{"/jukebox", "top node must be qualified"},
+		{"/no-such:jukebox", "unknown module no-such"},
+		{"/example-jukebox:jukebox/libary", "has no child libary"},
+		{"/example-jukebox:jukebox/library/stats", "stats is defined in module example-augment, not example-jukebox"},
+		{"/example-jukebox:jukebox=x", "jukebox is not a list or leaf-list but has keys"},
+		{"/example-jukebox:jukebox/library/artist=a,b", "got 2 keys for artist, want 1"},
+		{"/example-jukebox:jukebox/library/artist=a%2", "invalid escape"},
+		{"/example-jukebox:jukebox/library/artist=a%zz", "invalid escape"},
+	} {
+		_, _, err := ParseAPIPath(ms, tt.path)
+		if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+			t.Errorf("%s: %s", tt.path, diff)
+		}
+	}
+}
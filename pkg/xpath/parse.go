@@ -0,0 +1,566 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpath
+
+// This file implements the lexer and parser of XPath expressions, which
+// follow the grammar of section 3 of the XPath 1.0 recommendation.
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A SyntaxError reports an expression that cannot be parsed.
+type SyntaxError struct {
+	Expr   string // the expression being parsed
+	Offset int    // the byte offset in Expr of the error
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("xpath %q: offset %d: %s", e.Expr, e.Offset, e.Msg)
+}
+
+// Parse parses the XPath expression s.  The returned error, if any, is a
+// *SyntaxError.
+func Parse(s string) (Expr, error) {
+	toks, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{s: s, toks: toks}
+	x, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf(t, "unexpected %s", t)
+	}
+	return x, nil
+}
+
+// A tokenKind is the kind of a token.
+type tokenKind int
+
+const (
+	tokEOF      tokenKind = iota
+	tokSym                // punctuation and symbolic operators
+	tokOp                 // operator names and the multiply operator
+	tokName               // a name test, e.g., "*", "pfx:*", or "pfx:name"
+	tokFunc               // a function name, which is followed by "("
+	tokNodeType           // a node type, which is followed by "("
+	tokAxis               // an axis name, which is followed by "::"
+	tokLiteral            // a string literal, without its quotes
+	tokNumber             // a number
+	tokVar                // a variable reference, without the "$"
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokLiteral:
+		return strconv.Quote(t.text)
+	case tokVar:
+		return strconv.Quote("$" + t.text)
+	}
+	return strconv.Quote(t.text)
+}
+
+var axes = map[string]bool{
+	"ancestor":           true,
+	"ancestor-or-self":   true,
+	"attribute":          true,
+	"child":              true,
+	"descendant":         true,
+	"descendant-or-self": true,
+	"following":          true,
+	"following-sibling":  true,
+	"namespace":          true,
+	"parent":             true,
+	"preceding":          true,
+	"preceding-sibling":  true,
+	"self":               true,
+}
+
+var nodeTypes = map[string]bool{
+	"comment":                true,
+	"node":                   true,
+	"processing-instruction": true,
+	"text":                   true,
+}
+
+// lex returns the tokens of s, ending with a tokEOF token.
+func lex(s string) ([]token, error) {
+	var toks []token
+	// operand reports whether the next token must be an operand rather
+	// than an operator, which resolves the meaning of "*" and of names
+	// such as "and" (XPath 1.0 section 3.7).
+	operand := func() bool {
+		if len(toks) == 0 {
+			return true
+		}
+		switch t := toks[len(toks)-1]; t.kind {
+		case tokOp, tokAxis:
+			return true
+		case tokSym:
+			return t.text != ")" && t.text != "]" && t.text != "." && t.text != ".."
+		}
+		return false
+	}
+	errorf := func(pos int, format string, v ...interface{}) error {
+		return &SyntaxError{Expr: s, Offset: pos, Msg: fmt.Sprintf(format, v...)}
+	}
+	for i := 0; i < len(s); {
+		c := s[i]
+		start := i
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(s[i+1:], c)
+			if end < 0 {
+				return nil, errorf(i, "unterminated literal")
+			}
+			toks = append(toks, token{tokLiteral, s[i+1 : i+1+end], start})
+			i += end + 2
+			continue
+		case isDigit(c) || (c == '.' && i+1 < len(s) && isDigit(s[i+1])):
+			for i < len(s) && isDigit(s[i]) {
+				i++
+			}
+			if i < len(s) && s[i] == '.' {
+				i++
+				for i < len(s) && isDigit(s[i]) {
+					i++
+				}
+			}
+			toks = append(toks, token{tokNumber, s[start:i], start})
+			continue
+		case c == '$':
+			i++
+			n := scanQName(s[i:])
+			if n == 0 {
+				return nil, errorf(start, "missing variable name")
+			}
+			i += n
+			toks = append(toks, token{tokVar, s[start+1 : i], start})
+			continue
+		case c == '*':
+			i++
+			if operand() {
+				toks = append(toks, token{tokName, "*", start})
+			} else {
+				toks = append(toks, token{tokOp, "*", start})
+			}
+			continue
+		case isNameStart(c):
+			n := scanNCName(s[i:])
+			name := s[i : i+n]
+			i += n
+			if !operand() {
+				switch name {
+				case "and", "or", "div", "mod":
+					toks = append(toks, token{tokOp, name, start})
+					continue
+				}
+				return nil, errorf(start, "unexpected name %q, expected an operator", name)
+			}
+			// A colon following a name is part of a QName or a
+			// name test of the form "pfx:*", unless it starts
+			// "::".
+			if i+1 < len(s) && s[i] == ':' && s[i+1] != ':' {
+				if s[i+1] == '*' {
+					toks = append(toks, token{tokName, s[start : i+2], start})
+					i += 2
+					continue
+				}
+				n := scanNCName(s[i+1:])
+				if n == 0 {
+					return nil, errorf(i, "invalid name")
+				}
+				i += n + 1
+			}
+			qname := s[start:i]
+			rest := strings.TrimLeft(s[i:], " \t\n\r")
+			switch {
+			case strings.HasPrefix(rest, "::"):
+				if !axes[qname] {
+					return nil, errorf(start, "unknown axis %q", qname)
+				}
+				toks = append(toks, token{tokAxis, qname, start})
+			case strings.HasPrefix(rest, "("):
+				if nodeTypes[qname] {
+					toks = append(toks, token{tokNodeType, qname, start})
+				} else {
+					toks = append(toks, token{tokFunc, qname, start})
+				}
+			default:
+				toks = append(toks, token{tokName, qname, start})
+			}
+			continue
+		}
+		// Symbols, longest first.
+		sym := ""
+		for _, op := range []string{"..", "::", "//", "!=", "<=", ">=", "(", ")", "[", "]", ".", "@", ",", "/", "|", "+", "-", "=", "<", ">"} {
+			if strings.HasPrefix(s[i:], op) {
+				sym = op
+				break
+			}
+		}
+		if sym == "" {
+			return nil, errorf(i, "unexpected character %q", c)
+		}
+		i += len(sym)
+		toks = append(toks, token{tokSym, sym, start})
+	}
+	return append(toks, token{tokEOF, "", len(s)}), nil
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+func isNameStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c >= 0x80
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || isDigit(c) || c == '-' || c == '.'
+}
+
+// scanNCName returns the length of the NCName at the start of s.
+func scanNCName(s string) int {
+	if s == "" || !isNameStart(s[0]) {
+		return 0
+	}
+	i := 1
+	for i < len(s) && isNameChar(s[i]) {
+		i++
+	}
+	return i
+}
+
+// scanQName returns the length of the QName at the start of s.
+func scanQName(s string) int {
+	n := scanNCName(s)
+	if n > 0 && n+1 < len(s) && s[n] == ':' {
+		if m := scanNCName(s[n+1:]); m > 0 {
+			return n + 1 + m
+		}
+	}
+	return n
+}
+
+// A parser parses a list of tokens.
+type parser struct {
+	s    string
+	toks []token
+}
+
+func (p *parser) peek() token { return p.toks[0] }
+
+func (p *parser) next() token {
+	t := p.toks[0]
+	if t.kind != tokEOF {
+		p.toks = p.toks[1:]
+	}
+	return t
+}
+
+// is reports whether the next token is of kind k and has the text text.
+func (p *parser) is(k tokenKind, text string) bool {
+	t := p.peek()
+	return t.kind == k && t.text == text
+}
+
+func (p *parser) expect(text string) error {
+	if !p.is(tokSym, text) {
+		t := p.peek()
+		return p.errorf(t, "unexpected %s, expected %q", t, text)
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) errorf(t token, format string, v ...interface{}) error {
+	return &SyntaxError{Expr: p.s, Offset: t.pos, Msg: fmt.Sprintf(format, v...)}
+}
+
+// binaryOps lists the binary operators by increasing precedence, except
+// for the union operator, which binds more tightly than unary minus.
+var binaryOps = [][]string{
+	{"or"},
+	{"and"},
+	{"=", "!="},
+	{"<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "div", "mod"},
+}
+
+func (p *parser) expr() (Expr, error) {
+	return p.binary(0)
+}
+
+// binary parses the left associative binary operators of binaryOps[level]
+// and above.
+func (p *parser) binary(level int) (Expr, error) {
+	if level == len(binaryOps) {
+		return p.unary()
+	}
+	x, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokOp && t.kind != tokSym || !contains(binaryOps[level], t.text) {
+			return x, nil
+		}
+		p.next()
+		y, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		x = &BinaryExpr{Op: t.text, Left: x, Right: y}
+	}
+}
+
+func contains(l []string, s string) bool {
+	for _, e := range l {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) unary() (Expr, error) {
+	if p.is(tokSym, "-") {
+		p.next()
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &NegateExpr{X: x}, nil
+	}
+	x, err := p.path()
+	if err != nil {
+		return nil, err
+	}
+	for p.is(tokSym, "|") {
+		p.next()
+		y, err := p.path()
+		if err != nil {
+			return nil, err
+		}
+		x = &BinaryExpr{Op: "|", Left: x, Right: y}
+	}
+	return x, nil
+}
+
+// path parses a PathExpr, which is either a location path or a filter
+// expression optionally followed by a relative location path.
+func (p *parser) path() (Expr, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokVar, t.kind == tokLiteral, t.kind == tokNumber, t.kind == tokFunc, t.kind == tokSym && t.text == "(":
+		x, err := p.filter()
+		if err != nil {
+			return nil, err
+		}
+		if !p.is(tokSym, "/") && !p.is(tokSym, "//") {
+			return x, nil
+		}
+		px := &PathExpr{Filter: x}
+		if err := p.relative(px); err != nil {
+			return nil, err
+		}
+		return px, nil
+	}
+	px := &PathExpr{}
+	if p.is(tokSym, "/") {
+		p.next()
+		px.Absolute = true
+		if !p.startsStep() {
+			return px, nil
+		}
+	} else if p.is(tokSym, "//") {
+		p.next()
+		px.Absolute = true
+		px.Steps = append(px.Steps, descendantOrSelf())
+	}
+	s, err := p.step()
+	if err != nil {
+		return nil, err
+	}
+	px.Steps = append(px.Steps, s)
+	if err := p.relative(px); err != nil {
+		return nil, err
+	}
+	return px, nil
+}
+
+// relative parses the steps following "/" or "//" and adds them to px.
+func (p *parser) relative(px *PathExpr) error {
+	for {
+		switch {
+		case p.is(tokSym, "/"):
+		case p.is(tokSym, "//"):
+			px.Steps = append(px.Steps, descendantOrSelf())
+		default:
+			return nil
+		}
+		p.next()
+		s, err := p.step()
+		if err != nil {
+			return err
+		}
+		px.Steps = append(px.Steps, s)
+	}
+}
+
+func descendantOrSelf() *Step {
+	return &Step{Axis: "descendant-or-self", NodeType: "node"}
+}
+
+// startsStep reports whether the next token starts a step.
+func (p *parser) startsStep() bool {
+	switch t := p.peek(); t.kind {
+	case tokName, tokNodeType, tokAxis:
+		return true
+	case tokSym:
+		return t.text == "." || t.text == ".." || t.text == "@"
+	}
+	return false
+}
+
+func (p *parser) step() (*Step, error) {
+	switch {
+	case p.is(tokSym, "."):
+		p.next()
+		return &Step{Axis: "self", NodeType: "node"}, nil
+	case p.is(tokSym, ".."):
+		p.next()
+		return &Step{Axis: "parent", NodeType: "node"}, nil
+	}
+	s := &Step{Axis: "child"}
+	if p.is(tokSym, "@") {
+		p.next()
+		s.Axis = "attribute"
+	} else if p.peek().kind == tokAxis {
+		s.Axis = p.next().text
+		if err := p.expect("::"); err != nil {
+			return nil, err
+		}
+	}
+	switch t := p.next(); t.kind {
+	case tokName:
+		s.Name = t.text
+	case tokNodeType:
+		s.NodeType = t.text
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		if t.text == "processing-instruction" && p.peek().kind == tokLiteral {
+			s.Literal = p.next().text
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, p.errorf(t, "unexpected %s, expected a node test", t)
+	}
+	preds, err := p.predicates()
+	if err != nil {
+		return nil, err
+	}
+	s.Predicates = preds
+	return s, nil
+}
+
+func (p *parser) predicates() ([]Expr, error) {
+	var preds []Expr
+	for p.is(tokSym, "[") {
+		p.next()
+		x, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		preds = append(preds, x)
+	}
+	return preds, nil
+}
+
+// filter parses a primary expression followed by any predicates.
+func (p *parser) filter() (Expr, error) {
+	var x Expr
+	switch t := p.next(); t.kind {
+	case tokVar:
+		x = &VariableRef{Name: t.text}
+	case tokLiteral:
+		x = &Literal{Value: t.text}
+	case tokNumber:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, p.errorf(t, "invalid number %s", t.text)
+		}
+		x = &Number{Value: f}
+	case tokFunc:
+		fc := &FunctionCall{Name: t.text}
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		for !p.is(tokSym, ")") {
+			if len(fc.Args) > 0 {
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+			a, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			fc.Args = append(fc.Args, a)
+		}
+		p.next()
+		x = fc
+	default: // "("
+		var err error
+		if x, err = p.expr(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	}
+	preds, err := p.predicates()
+	if err != nil {
+		return nil, err
+	}
+	if len(preds) == 0 {
+		return x, nil
+	}
+	return &FilterExpr{Primary: x, Predicates: preds}, nil
+}
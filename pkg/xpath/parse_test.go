@@ -0,0 +1,120 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpath
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+func TestParse(t *testing.T) {
+	name := func(n string, preds ...Expr) *Step { return &Step{Axis: "child", Name: n, Predicates: preds} }
+	path := func(abs bool, steps ...*Step) *PathExpr { return &PathExpr{Absolute: abs, Steps: steps} }
+	parent := &Step{Axis: "parent", NodeType: "node"}
+	current := &FunctionCall{Name: "current"}
+
+	tests := []struct {
+		in      string
+		want    Expr
+		wantErr string
+	}{{
+		in:   "../a:name",
+		want: path(false, parent, name("a:name")),
+	}, {
+		in:   "/if:interfaces/if:interface",
+		want: path(true, name("if:interfaces"), name("if:interface")),
+	}, {
+		in: "/a/b[name = current()/../c]/d",
+		want: path(true,
+			name("a"),
+			name("b", &BinaryExpr{Op: "=", Left: path(false, name("name")), Right: &PathExpr{Filter: current, Steps: []*Step{parent, name("c")}}}),
+			name("d")),
+	}, {
+		in:   "count(*) > 1 and not(x)",
+		want: &BinaryExpr{Op: "and", Left: &BinaryExpr{Op: ">", Left: &FunctionCall{Name: "count", Args: []Expr{path(false, name("*"))}}, Right: &Number{1}}, Right: &FunctionCall{Name: "not", Args: []Expr{path(false, name("x"))}}},
+	}, {
+		in:   "a * b div 2 mod 3",
+		want: &BinaryExpr{Op: "mod", Left: &BinaryExpr{Op: "div", Left: &BinaryExpr{Op: "*", Left: path(false, name("a")), Right: path(false, name("b"))}, Right: &Number{2}}, Right: &Number{3}},
+	}, {
+		in:   "-a - -1.5",
+		want: &BinaryExpr{Op: "-", Left: &NegateExpr{path(false, name("a"))}, Right: &NegateExpr{&Number{1.5}}},
+	}, {
+		in:   "a or b and c",
+		want: &BinaryExpr{Op: "or", Left: path(false, name("a")), Right: &BinaryExpr{Op: "and", Left: path(false, name("b")), Right: path(false, name("c"))}},
+	}, {
+		in:   "a|b",
+		want: &BinaryExpr{Op: "|", Left: path(false, name("a")), Right: path(false, name("b"))},
+	}, {
+		in:   "//x",
+		want: path(true, descendantOrSelf(), name("x")),
+	}, {
+		in:   "/",
+		want: path(true),
+	}, {
+		in:   "derived-from-or-self(../type, 'ianaift:ethernetCsmacd')",
+		want: &FunctionCall{Name: "derived-from-or-self", Args: []Expr{path(false, parent, name("type")), &Literal{"ianaift:ethernetCsmacd"}}},
+	}, {
+		in:   "ancestor::c:config/@a:*",
+		want: path(false, &Step{Axis: "ancestor", Name: "c:config"}, &Step{Axis: "attribute", Name: "a:*"}),
+	}, {
+		in:   "($x | y)[1]/text()",
+		want: &PathExpr{Filter: &FilterExpr{Primary: &BinaryExpr{Op: "|", Left: &VariableRef{"x"}, Right: path(false, name("y"))}, Predicates: []Expr{&Number{1}}}, Steps: []*Step{{Axis: "child", NodeType: "text"}}},
+	}, {
+		in:   ". = \"it's\"",
+		want: &BinaryExpr{Op: "=", Left: path(false, &Step{Axis: "self", NodeType: "node"}), Right: &Literal{"it's"}},
+	}, {
+		in:      "a and",
+		wantErr: "offset 5: unexpected end of expression, expected a node test",
+	}, {
+		in:      "a b",
+		wantErr: `offset 2: unexpected name "b", expected an operator`,
+	}, {
+		in:      "f(a",
+		wantErr: `offset 3: unexpected end of expression, expected ","`,
+	}, {
+		in:      "'abc",
+		wantErr: "offset 0: unterminated literal",
+	}, {
+		in:      "a[1",
+		wantErr: `expected "]"`,
+	}, {
+		in:      "up::a",
+		wantErr: `unknown axis "up"`,
+	}, {
+		in:      "a # b",
+		wantErr: "offset 2: unexpected character '#'",
+	}, {
+		in:      "a)",
+		wantErr: `unexpected ")"`,
+	}}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+			t.Errorf("Parse(%q): %s", tt.in, diff)
+			continue
+		}
+		if err != nil {
+			if _, ok := err.(*SyntaxError); !ok {
+				t.Errorf("Parse(%q): got error of type %T, want *SyntaxError", tt.in, err)
+			}
+			continue
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("Parse(%q) (-want, +got):\n%s", tt.in, diff)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package xpath parses the XPath 1.0 expressions used by YANG when and must
+// statements and leafref paths (RFC 7950 section 6.4).
+//
+// Parse returns the abstract syntax tree of an expression.  Abbreviated
+// steps are expanded, e.g., ".." is parsed as the step parent::node(), but
+// the String method of each Expr writes the abbreviated form back out.
+// Function calls, including the YANG functions such as current() and
+// deref(), are not checked, nor are prefixes resolved.
+package xpath
+
+import (
+	"strconv"
+	"strings"
+)
+
+// An Expr is a node in the abstract syntax tree of an XPath expression.
+// String returns the expression in XPath syntax.
+type Expr interface {
+	String() string
+}
+
+// A BinaryExpr is an expression using a binary operator.  Op is one of
+// "or", "and", "=", "!=", "<", "<=", ">", ">=", "+", "-", "*", "div",
+// "mod", and "|".
+type BinaryExpr struct {
+	Op          string
+	Left, Right Expr
+}
+
+// A NegateExpr is the negation of X, as in "-X".
+type NegateExpr struct {
+	X Expr
+}
+
+// A Literal is a string literal.
+type Literal struct {
+	Value string
+}
+
+// A Number is a numeric literal.
+type Number struct {
+	Value float64
+}
+
+// A VariableRef is a reference to the variable Name, as in "$Name".
+type VariableRef struct {
+	Name string
+}
+
+// A FunctionCall is a call to the function Name, which may have a prefix.
+type FunctionCall struct {
+	Name string
+	Args []Expr
+}
+
+// A FilterExpr is a primary expression, e.g., a function call or a
+// parenthesized expression, filtered by predicates.
+type FilterExpr struct {
+	Primary    Expr
+	Predicates []Expr
+}
+
+// A PathExpr is a location path.  If Filter is not nil, the path is
+// relative to the nodes selected by Filter.  Otherwise the path is
+// absolute if Absolute is set, and is relative to the context node if it
+// is not.
+type PathExpr struct {
+	Filter   Expr
+	Absolute bool
+	Steps    []*Step
+}
+
+// A Step is a step of a location path.  The nodes selected by the step are
+// those along Axis matching either Name, which is "*", "prefix:*", or a
+// possibly prefixed node name, or NodeType, which is one of "node",
+// "text", "comment", or "processing-instruction".  Literal is the optional
+// argument of a processing-instruction node type test.
+type Step struct {
+	Axis       string
+	Name       string
+	NodeType   string
+	Literal    string
+	Predicates []Expr
+}
+
+func (x *BinaryExpr) String() string {
+	op := x.Op
+	if op != "|" {
+		op = " " + op + " "
+	}
+	return operand(x.Left, x.Op, false) + op + operand(x.Right, x.Op, true)
+}
+
+func (x *NegateExpr) String() string {
+	return "-" + operand(x.X, "-x", false)
+}
+
+func (x *Literal) String() string {
+	if strings.Contains(x.Value, "'") {
+		return `"` + x.Value + `"`
+	}
+	return "'" + x.Value + "'"
+}
+
+func (x *Number) String() string {
+	return strconv.FormatFloat(x.Value, 'f', -1, 64)
+}
+
+func (x *VariableRef) String() string {
+	return "$" + x.Name
+}
+
+func (x *FunctionCall) String() string {
+	args := make([]string, len(x.Args))
+	for i, a := range x.Args {
+		args[i] = a.String()
+	}
+	return x.Name + "(" + strings.Join(args, ", ") + ")"
+}
+
+func (x *FilterExpr) String() string {
+	return primary(x.Primary) + predicates(x.Predicates)
+}
+
+func (x *PathExpr) String() string {
+	var b strings.Builder
+	if x.Filter != nil {
+		b.WriteString(primary(x.Filter))
+	}
+	for i, s := range x.Steps {
+		if i > 0 || x.Filter != nil || x.Absolute {
+			b.WriteByte('/')
+		}
+		b.WriteString(s.String())
+	}
+	if x.Absolute && len(x.Steps) == 0 {
+		b.WriteByte('/')
+	}
+	return b.String()
+}
+
+// String returns s in its abbreviated form, if it has one.  A
+// descendant-or-self::node() step without predicates is written as "", so
+// that the path "a//b" is written as the steps "a", "", and "b" separated
+// by slashes.
+func (s *Step) String() string {
+	if len(s.Predicates) == 0 && s.NodeType == "node" {
+		switch s.Axis {
+		case "self":
+			return "."
+		case "parent":
+			return ".."
+		case "descendant-or-self":
+			return ""
+		}
+	}
+	var b strings.Builder
+	switch s.Axis {
+	case "child":
+	case "attribute":
+		b.WriteByte('@')
+	default:
+		b.WriteString(s.Axis + "::")
+	}
+	if s.NodeType != "" {
+		b.WriteString(s.NodeType + "(")
+		if s.Literal != "" {
+			b.WriteString((&Literal{s.Literal}).String())
+		}
+		b.WriteByte(')')
+	} else {
+		b.WriteString(s.Name)
+	}
+	b.WriteString(predicates(s.Predicates))
+	return b.String()
+}
+
+// predicates returns ps in XPath syntax.
+func predicates(ps []Expr) string {
+	var b strings.Builder
+	for _, p := range ps {
+		b.WriteString("[" + p.String() + "]")
+	}
+	return b.String()
+}
+
+// precedence returns the precedence of the binary operator op.  Higher
+// values bind more tightly.
+func precedence(op string) int {
+	switch op {
+	case "or":
+		return 1
+	case "and":
+		return 2
+	case "=", "!=":
+		return 3
+	case "<", "<=", ">", ">=":
+		return 4
+	case "+", "-":
+		return 5
+	case "*", "div", "mod":
+		return 6
+	case "|":
+		return 8
+	}
+	return 7 // unary minus
+}
+
+// operand returns x, an operand of op, in XPath syntax.  x is parenthesized
+// if it binds less tightly than op, or, as right is set for the right hand
+// operand of the left associative binary operators, equally tightly.
+func operand(x Expr, op string, right bool) string {
+	var p int
+	switch x := x.(type) {
+	case *BinaryExpr:
+		p = precedence(x.Op)
+	case *NegateExpr:
+		p = precedence("-x")
+	default:
+		return x.String()
+	}
+	if q := precedence(op); p < q || (right && p == q) {
+		return "(" + x.String() + ")"
+	}
+	return x.String()
+}
+
+// primary returns x, the primary expression of a FilterExpr or the filter
+// of a PathExpr, in XPath syntax, parenthesized if needed.
+func primary(x Expr) string {
+	switch x.(type) {
+	case *BinaryExpr, *NegateExpr, *PathExpr:
+		return "(" + x.String() + ")"
+	}
+	return x.String()
+}
+
+// Walk calls fn for x and, if fn returns true, for each of the expressions
+// within x, including the predicates of each step of a path, in the order
+// they appear in the expression.
+func Walk(x Expr, fn func(Expr) bool) {
+	if x == nil || !fn(x) {
+		return
+	}
+	switch x := x.(type) {
+	case *BinaryExpr:
+		Walk(x.Left, fn)
+		Walk(x.Right, fn)
+	case *NegateExpr:
+		Walk(x.X, fn)
+	case *FunctionCall:
+		for _, a := range x.Args {
+			Walk(a, fn)
+		}
+	case *FilterExpr:
+		Walk(x.Primary, fn)
+		for _, p := range x.Predicates {
+			Walk(p, fn)
+		}
+	case *PathExpr:
+		Walk(x.Filter, fn)
+		for _, s := range x.Steps {
+			for _, p := range s.Predicates {
+				Walk(p, fn)
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpath
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestString(t *testing.T) {
+	for _, tt := range []struct {
+		in, want string
+	}{
+		{"../a:name", "../a:name"},
+		{"/a/b[name=current()/../c]/d", "/a/b[name = current()/../c]/d"},
+		{"a//b", "a//b"},
+		{"//b", "//b"},
+		{"/", "/"},
+		{"child::a/attribute::b/self::node()", "a/@b/."},
+		{"ancestor::*[1]", "ancestor::*[1]"},
+		{"(a or b) and c", "(a or b) and c"},
+		{"a - (b - c)", "a - (b - c)"},
+		{"(a - b) - c", "a - b - c"},
+		{"-(a + b)", "-(a + b)"},
+		{"--a", "--a"},
+		{"(a | b)[1]/c", "(a|b)[1]/c"},
+		{"(a | b)/c", "(a|b)/c"},
+		{"processing-instruction('x')", "processing-instruction('x')"},
+		{"\"it's\" != 'a'", "\"it's\" != 'a'"},
+		{"1.50 * $v", "1.5 * $v"},
+	} {
+		x, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		if got := x.String(); got != tt.want {
+			t.Errorf("Parse(%q).String() = %q, want %q", tt.in, got, tt.want)
+		}
+		// The String form must parse to the same expression.
+		y, err := Parse(x.String())
+		if err != nil {
+			t.Errorf("Parse(%q): %v", x.String(), err)
+			continue
+		}
+		if diff := cmp.Diff(x, y); diff != "" {
+			t.Errorf("%q does not round trip (-want, +got):\n%s", tt.in, diff)
+		}
+	}
+}
+
+func TestWalk(t *testing.T) {
+	x, err := Parse("count(/a/b[c = current()/../d]) > $n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	walk := func(skip string) []string {
+		var got []string
+		Walk(x, func(x Expr) bool {
+			got = append(got, x.String())
+			return x.String() != skip
+		})
+		return got
+	}
+	want := []string{
+		"count(/a/b[c = current()/../d]) > $n",
+		"count(/a/b[c = current()/../d])",
+		"/a/b[c = current()/../d]",
+		"c = current()/../d",
+		"c",
+		"current()/../d",
+		"current()",
+		"$n",
+	}
+	if diff := cmp.Diff(want, walk("")); diff != "" {
+		t.Errorf("Walk (-want, +got):\n%s", diff)
+	}
+	want = []string{
+		"count(/a/b[c = current()/../d]) > $n",
+		"count(/a/b[c = current()/../d])",
+		"$n",
+	}
+	if diff := cmp.Diff(want, walk("count(/a/b[c = current()/../d])")); diff != "" {
+		t.Errorf("Walk without descending into count (-want, +got):\n%s", diff)
+	}
+}
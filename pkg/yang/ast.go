@@ -23,7 +23,6 @@ package yang
 // convert generic Statements into an AST.
 
 import (
-	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -88,16 +87,6 @@ func BuildAST(s *Statement) (Node, error) {
 // build builds and returns an AST from the statement s, with parent p, or
 // returns an error.  The type of value returned depends on the keyword in s.
 func build(s *Statement, p reflect.Value) (v reflect.Value, err error) {
-	defer func() {
-		// If we are returning a real Node then call addTypedefs
-		// if the node possibly contains typedefs.
-		if err != nil || v == nilValue {
-			return
-		}
-		if t, ok := v.Interface().(Typedefer); ok {
-			addTypedefs(t)
-		}
-	}()
 	kind := s.Keyword
 	if k := aliases[s.Keyword]; k != "" {
 		kind = k
@@ -109,7 +98,7 @@ func build(s *Statement, p reflect.Value) (v reflect.Value, err error) {
 		if strings.Index(s.Keyword, ":") > 0 {
 			return nilValue, nil
 		}
-		return nilValue, fmt.Errorf("%s: unknown statement: %s", s.Location(), s.Keyword)
+		return nilValue, errorf(s, ErrUnknownStatement, "unknown statement: %s", s.Keyword)
 	}
 	y := typeMap[t]
 	found := map[string]bool{}
@@ -160,25 +149,25 @@ func build(s *Statement, p reflect.Value) (v reflect.Value, err error) {
 			// Keyword is not known but it has a prefix so it might
 			// be an extension.
 			if y.addext == nil {
-				return nilValue, fmt.Errorf("%s: no extension function", ss.Location())
+				return nilValue, errorf(ss, ErrInternal, "no extension function")
 			}
 			y.addext(ss, v, p)
 		default:
-			return nilValue, fmt.Errorf("%s: unknown %s field: %s", ss.Location(), s.Keyword, ss.Keyword)
+			return nilValue, errorf(ss, ErrUnexpectedSubstatement, "unknown %s field: %s", s.Keyword, ss.Keyword)
 		}
 	}
 
 	// Make sure all of our required field are there.
 	for _, r := range y.required {
 		if !found[r] {
-			return nilValue, fmt.Errorf("%s: missing required %s field: %s", s.Location(), s.Keyword, r)
+			return nilValue, errorf(s, ErrMissingSubstatement, "missing required %s field: %s", s.Keyword, r)
 		}
 	}
 
 	// Make sure required fields based on our keyword are there (module vs submodule)
 	for _, r := range y.sRequired[s.Keyword] {
 		if !found[r] {
-			return nilValue, fmt.Errorf("%s: missing required %s field: %s", s.Location(), s.Keyword, r)
+			return nilValue, errorf(s, ErrMissingSubstatement, "missing required %s field: %s", s.Keyword, r)
 		}
 	}
 
@@ -189,7 +178,7 @@ func build(s *Statement, p reflect.Value) (v reflect.Value, err error) {
 		}
 		for _, r := range or {
 			if found[r] {
-				return nilValue, fmt.Errorf("%s: unknown %s field: %s", s.Location(), s.Keyword, r)
+				return nilValue, errorf(s, ErrUnexpectedSubstatement, "unknown %s field: %s", s.Keyword, r)
 			}
 		}
 	}
@@ -363,7 +352,7 @@ func initTypes(at reflect.Type) {
 				}
 				fv := v.Elem().Field(i)
 				if fv.String() != "" {
-					return errors.New(s.Keyword + ": already set")
+					return errorf(nil, ErrDuplicateSubstatement, "%s: already set", s.Keyword)
 				}
 
 				v.Elem().Field(i).SetString(s.Argument)
@@ -396,7 +385,7 @@ func initTypes(at reflect.Type) {
 				}
 				fv := v.Elem().Field(i)
 				if !fv.IsNil() {
-					return errors.New(s.Keyword + ": already set")
+					return errorf(nil, ErrDuplicateSubstatement, "%s: already set", s.Keyword)
 				}
 
 				// Use build to build the value for this field.
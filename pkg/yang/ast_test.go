@@ -36,6 +36,8 @@ type MainNode struct {
 
 func (MainNode) Kind() string             { return "main_node" }
 func (m *MainNode) ParentNode() Node      { return m.Parent }
+func (m *MainNode) ParentModule() *Module { return parentModule(m) }
+func (m *MainNode) SchemaPath() string    { return schemaPath(m) }
 func (m *MainNode) NName() string         { return m.Name }
 func (m *MainNode) Statement() *Statement { return m.Source }
 func (m *MainNode) Exts() []*Statement    { return m.Extensions }
@@ -116,6 +118,8 @@ type SubNode struct {
 
 func (SubNode) Kind() string             { return "sub_node" }
 func (s *SubNode) ParentNode() Node      { return s.Parent }
+func (s *SubNode) ParentModule() *Module { return parentModule(s) }
+func (s *SubNode) SchemaPath() string    { return schemaPath(s) }
 func (s *SubNode) NName() string         { return s.Name }
 func (s *SubNode) Statement() *Statement { return s.Source }
 func (s *SubNode) Exts() []*Statement    { return s.Extensions }
@@ -153,6 +157,8 @@ type ReqNode struct {
 
 func (ReqNode) Kind() string             { return "req_node" }
 func (s *ReqNode) ParentNode() Node      { return s.Parent }
+func (s *ReqNode) ParentModule() *Module { return parentModule(s) }
+func (s *ReqNode) SchemaPath() string    { return schemaPath(s) }
 func (s *ReqNode) NName() string         { return s.Name }
 func (s *ReqNode) Statement() *Statement { return s.Source }
 func (s *ReqNode) Exts() []*Statement    { return s.Extensions }
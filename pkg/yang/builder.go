@@ -0,0 +1,116 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements building Entry trees in code, without parsing YANG
+// text.
+
+import (
+	"reflect"
+	"strings"
+)
+
+// NewModule returns the Entry of a new module named name with the given
+// prefix and namespace, containing children.  The module is the only member
+// of its own Modules, so that functions such as InstantiatingModule and
+// QualifiedPath work on the entries below it.
+//
+// NewModule, NewContainer, NewList, NewLeaf and NewLeafList build Entry
+// trees for tests and applications that define schemas in code, e.g.,
+//
+//   m := NewModule("example", "ex", "urn:example",
+//   	NewList("interface", "name").AddChild(
+//   		NewLeaf("name", BaseTypedefs["string"].YangType),
+//   		NewLeaf("mtu", BaseTypedefs["uint16"].YangType),
+//   	),
+//   )
+//
+// Each entry is backed by a minimal Node of the matching kind, with no
+// Statement.  The Nodes only record names and parents, so ToEntry must not
+// be called on them.
+func NewModule(name, prefix, namespace string, children ...*Entry) *Entry {
+	m := &Module{
+		Name:      name,
+		Prefix:    &Value{Name: prefix},
+		Namespace: &Value{Name: namespace},
+	}
+	m.Prefix.Parent = m
+	m.Namespace.Parent = m
+	ms := NewModules()
+	ms.Modules[name] = m
+	m.modules = ms
+	return newDirectory(m).AddChild(children...)
+}
+
+// NewContainer returns the Entry of a new container named name with the
+// given children.
+func NewContainer(name string, children ...*Entry) *Entry {
+	return newDirectory(&Container{Name: name}).AddChild(children...)
+}
+
+// NewList returns the Entry of a new list named name whose instances are
+// identified by the leaves named keys.  The key leaves are added with
+// AddChild.
+func NewList(name string, keys ...string) *Entry {
+	l := &List{Name: name}
+	e := newDirectory(l)
+	e.ListAttr = NewDefaultListAttr()
+	if len(keys) > 0 {
+		e.Key = strings.Join(keys, " ")
+		l.Key = &Value{Name: e.Key, Parent: l}
+	}
+	return e
+}
+
+// NewLeaf returns the Entry of a new leaf named name of type t.
+func NewLeaf(name string, t *YangType) *Entry {
+	l := &Leaf{Name: name}
+	l.Type = &Type{Name: t.Name, Parent: l, YangType: t}
+	e := newLeaf(l)
+	e.Type = t
+	return e
+}
+
+// NewLeafList returns the Entry of a new leaf-list named name of type t.
+func NewLeafList(name string, t *YangType) *Entry {
+	l := &LeafList{Name: name}
+	l.Type = &Type{Name: t.Name, Parent: l, YangType: t}
+	e := newLeaf(l)
+	e.Type = t
+	e.ListAttr = NewDefaultListAttr()
+	return e
+}
+
+// AddChild adds children to e, which must be a directory entry, and
+// returns e.  Adding a child with the same name as an existing child of e
+// records an error in e.Errors.
+func (e *Entry) AddChild(children ...*Entry) *Entry {
+	for _, ce := range children {
+		setParentNode(ce.Node, e.Node)
+		e.add(ce.Name, ce)
+	}
+	return e
+}
+
+// setParentNode sets the Parent field of n to p.
+func setParentNode(n, p Node) {
+	v := reflect.ValueOf(n)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return
+	}
+	if f := v.Elem().FieldByName("Parent"); f.IsValid() && f.CanSet() {
+		f.Set(reflect.ValueOf(&p).Elem())
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"testing"
+)
+
+func TestBuilders(t *testing.T) {
+	str := BaseTypedefs["string"].YangType
+	u16 := BaseTypedefs["uint16"].YangType
+	m := NewModule("example", "ex", "urn:example",
+		NewContainer("interfaces",
+			NewList("interface", "name").AddChild(
+				NewLeaf("name", str),
+				NewLeaf("mtu", u16),
+				NewLeafList("alias", str),
+			),
+		),
+	)
+	if len(m.Errors) > 0 {
+		t.Fatalf("NewModule: unexpected errors: %v", m.Errors)
+	}
+
+	for _, tt := range []struct {
+		path          string
+		wantQualified string
+		wantList      bool
+		wantLeafList  bool
+		wantContainer bool
+		wantLeaf      bool
+	}{{
+		path:          "interfaces",
+		wantQualified: "/example:interfaces",
+		wantContainer: true,
+	}, {
+		path:          "interfaces/interface",
+		wantQualified: "/example:interfaces/interface",
+		wantList:      true,
+	}, {
+		path:          "interfaces/interface/mtu",
+		wantQualified: "/example:interfaces/interface/mtu",
+		wantLeaf:      true,
+	}, {
+		path:          "interfaces/interface/alias",
+		wantQualified: "/example:interfaces/interface/alias",
+		wantLeafList:  true,
+	}} {
+		e := m.Find(tt.path)
+		if e == nil {
+			t.Errorf("Find(%q): not found", tt.path)
+			continue
+		}
+		if got, want := e.Path(), "/example/"+tt.path; got != want {
+			t.Errorf("%s: got path %s, want %s", tt.path, got, want)
+		}
+		if got := e.QualifiedPath(); got != tt.wantQualified {
+			t.Errorf("%s: got qualified path %s, want %s", tt.path, got, tt.wantQualified)
+		}
+		if got := e.IsList(); got != tt.wantList {
+			t.Errorf("%s: got IsList %v, want %v", tt.path, got, tt.wantList)
+		}
+		if got := e.IsLeafList(); got != tt.wantLeafList {
+			t.Errorf("%s: got IsLeafList %v, want %v", tt.path, got, tt.wantLeafList)
+		}
+		if got := e.IsContainer(); got != tt.wantContainer {
+			t.Errorf("%s: got IsContainer %v, want %v", tt.path, got, tt.wantContainer)
+		}
+		if got := e.IsLeaf(); got != tt.wantLeaf {
+			t.Errorf("%s: got IsLeaf %v, want %v", tt.path, got, tt.wantLeaf)
+		}
+		if got := e.Namespace(); got == nil || got.Name != "urn:example" {
+			t.Errorf("%s: got namespace %v, want urn:example", tt.path, got)
+		}
+		if got, err := e.InstantiatingModule(); err != nil || got != "example" {
+			t.Errorf("%s: got instantiating module %q, %v, want example", tt.path, got, err)
+		}
+	}
+
+	l := m.Find("interfaces/interface")
+	if l.Key != "name" {
+		t.Errorf("got key %q, want name", l.Key)
+	}
+	if got := l.Dir["mtu"].Type.Kind; got != Yuint16 {
+		t.Errorf("got mtu type %v, want %v", got, Yuint16)
+	}
+
+	l.AddChild(NewLeaf("mtu", u16))
+	if len(l.Errors) != 1 {
+		t.Errorf("adding a duplicate child: got errors %v, want 1 error", l.Errors)
+	}
+}
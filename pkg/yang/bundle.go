@@ -0,0 +1,178 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements reading modules from zip and tar archives.
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// AddBundle adds a resolver, see AddResolver, that reads modules from the
+// archive file name, a zip file (.zip) or a gzip compressed tar file
+// (.tar.gz or .tgz).  The .yang and .yin files in the archive are read when
+// AddBundle is called, and are found as they are in a Path directory of the
+// form dir/..., e.g., the latest module@revision.yang file is used for a
+// module named without a revision.  A file in the archive is named by the
+// archive's name followed by its path in the archive, e.g.,
+// "vendor.zip/models/vendor-interfaces.yang".
+func (ms *Modules) AddBundle(name string) error {
+	var files map[string]string
+	var err error
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		files, err = readZip(name, ms.opts.MaxFileSize)
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		files, err = readTarGz(name, ms.opts.MaxFileSize)
+	default:
+		return fmt.Errorf("%s: not a .zip, .tar.gz, or .tgz file", name)
+	}
+	switch err.(type) {
+	case nil:
+	case *fileSizeError:
+		return err
+	default:
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	b := &bundleResolver{archive: name, files: files}
+	for p := range files {
+		b.paths = append(b.paths, p)
+	}
+	// Sort the paths so the first match in a bundle does not depend on
+	// the order of the files in the archive.
+	sort.Strings(b.paths)
+	ms.AddResolver(b)
+	return nil
+}
+
+// A bundleResolver is a ModuleResolver that reads modules from an archive.
+type bundleResolver struct {
+	archive string
+	files   map[string]string // the contents of each .yang and .yin file
+	paths   []string          // the sorted keys of files
+}
+
+func (b *bundleResolver) Resolve(ctx context.Context, name string) (string, string, error) {
+	if strings.Contains(name, "/") {
+		p := path.Clean(strings.TrimPrefix(name, "/"))
+		if data, ok := b.files[p]; ok {
+			return filepath.Join(b.archive, p), data, nil
+		}
+		return "", "", errorf(nil, ErrFileNotFound, "no such file: %s", name)
+	}
+	for _, n := range sourceNames(name) {
+		if p := matchPath(b.paths, n); p != "" {
+			return filepath.Join(b.archive, p), b.files[p], nil
+		}
+	}
+	return "", "", errorf(nil, ErrFileNotFound, "no such file: %s", name)
+}
+
+// isBundleSource reports whether the archive member name is a .yang or
+// .yin file.
+func isBundleSource(name string) bool {
+	return strings.HasSuffix(name, ".yang") || strings.HasSuffix(name, ".yin")
+}
+
+// readMember returns the contents of the archive member name read from r.
+// It returns a *fileSizeError, without reading the entire member, if the
+// member is larger than max bytes.  Members are limited as they are read,
+// as their size in the archive header may not be true.
+func readMember(archive, name string, r io.Reader, max int) ([]byte, error) {
+	if max > 0 {
+		r = io.LimitReader(r, int64(max)+1)
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	if max > 0 && len(data) > max {
+		return nil, &fileSizeError{name: archive + "/" + path.Clean(name), max: max}
+	}
+	return data, nil
+}
+
+// readZip returns the contents of the .yang and .yin files in the zip file
+// name, indexed by their cleaned paths.  Members larger than max bytes are
+// an error.
+func readZip(name string, max int) (map[string]string, error) {
+	zr, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	files := map[string]string{}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !isBundleSource(f.Name) {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := readMember(name, f.Name, r, max)
+		r.Close()
+		if err != nil {
+			return nil, err
+		}
+		files[path.Clean(f.Name)] = string(data)
+	}
+	return files, nil
+}
+
+// readTarGz returns the contents of the .yang and .yin files in the gzip
+// compressed tar file name, indexed by their cleaned paths.  Members larger
+// than max bytes are an error.
+func readTarGz(name string, max int) (map[string]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(gr)
+	files := map[string]string{}
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !h.FileInfo().Mode().IsRegular() || !isBundleSource(h.Name) {
+			continue
+		}
+		data, err := readMember(name, h.Name, tr, max)
+		if err != nil {
+			return nil, err
+		}
+		files[path.Clean(h.Name)] = string(data)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+// bundleFiles are the files written to the test archives.
+var bundleFiles = []struct {
+	name, data string
+}{
+	{"models/bundle-a.yang", `module bundle-a {
+  namespace "urn:bundle-a";
+  prefix "a";
+  import bundle-b { prefix "b"; }
+  leaf l { type b:t; }
+}`},
+	{"models/deps/bundle-b@2019-01-01.yang", `module bundle-b {
+  namespace "urn:bundle-b";
+  prefix "b";
+  revision 2019-01-01;
+  typedef t { type int8; }
+}`},
+	{"models/deps/bundle-b@2018-01-01.yang", `module bundle-b {
+  namespace "urn:bundle-b";
+  prefix "b";
+  revision 2018-01-01;
+}`},
+	{"README", "not a module"},
+}
+
+func writeZip(name string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(f)
+	for _, bf := range bundleFiles {
+		w, err := zw.Create(bf.name)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write([]byte(bf.data)); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func writeTarGz(name string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	if err := tw.WriteHeader(&tar.Header{Name: "./models/", Typeflag: tar.TypeDir, Mode: 0755}); err != nil {
+		return err
+	}
+	for _, bf := range bundleFiles {
+		if err := tw.WriteHeader(&tar.Header{Name: "./" + bf.name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(bf.data))}); err != nil {
+			return err
+		}
+		if _, err := tw.Write([]byte(bf.data)); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func TestAddBundle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bundle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, tt := range []struct {
+		name  string
+		write func(string) error
+	}{
+		{"bundle.zip", writeZip},
+		{"bundle.tar.gz", writeTarGz},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			archive := filepath.Join(dir, tt.name)
+			if err := tt.write(archive); err != nil {
+				t.Fatal(err)
+			}
+			ms := NewModules()
+			if err := ms.AddBundle(archive); err != nil {
+				t.Fatal(err)
+			}
+			if err := ms.Read("bundle-a"); err != nil {
+				t.Fatal(err)
+			}
+			if errs := ms.Process(); len(errs) > 0 {
+				t.Fatal(errs)
+			}
+			if got, want := ms.Modules["bundle-b"].Current(), "2019-01-01"; got != want {
+				t.Errorf("read revision %s of bundle-b, want %s", got, want)
+			}
+			if got, want := Source(ms.Modules["bundle-a"]), archive+"/models/bundle-a.yang:1:1"; got != want {
+				t.Errorf("bundle-a is from %s, want %s", got, want)
+			}
+			if err := ms.Read("models/deps/bundle-b@2018-01-01.yang"); err != nil {
+				t.Error(err)
+			}
+			if diff := errdiff.Substring(ms.Read("README"), "no such file: README"); diff != "" {
+				t.Error(diff)
+			}
+
+			// Members larger than MaxFileSize are not read in full.
+			defer func(max int) { ParseOptions.MaxFileSize = max }(ParseOptions.MaxFileSize)
+			ParseOptions.MaxFileSize = 50
+			err := NewModules().AddBundle(archive)
+			if code := ErrorCode(err); code != ErrLimitExceeded {
+				t.Errorf("AddBundle with MaxFileSize 50: got error %v with code %q, want %q", err, code, ErrLimitExceeded)
+			}
+			if diff := errdiff.Substring(err, archive+"/models/bundle-a.yang: file is larger than the maximum of 50 bytes"); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	ms := NewModules()
+	for _, tt := range []struct {
+		name    string
+		wantErr string
+	}{
+		{"bundle.rar", "bundle.rar: not a .zip, .tar.gz, or .tgz file"},
+		{filepath.Join(dir, "missing.zip"), "missing.zip: open"},
+	} {
+		if diff := errdiff.Substring(ms.AddBundle(tt.name), tt.wantErr); diff != "" {
+			t.Errorf("AddBundle(%q): %s", tt.name, diff)
+		}
+	}
+}
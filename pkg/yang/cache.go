@@ -0,0 +1,170 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the caches used to memoize typedef and grouping
+// lookups.  Only lookups made at module scope are cached as their result
+// depends solely on the module and the name being looked up.  Lookups in
+// nested scopes (e.g., a typedef defined within a container) are cheap and
+// are always performed directly.
+//
+// It also implements the table used to share identical resolved YangTypes.
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A scopedName is the key used by the resolution caches.  It names the
+// module the lookup was made from and the name (possibly prefixed) that
+// was looked up.
+type scopedName struct {
+	m    *Module
+	name string
+}
+
+// CacheStats contains statistics about the typedef and grouping resolution
+// caches of a Modules.  A hit is a lookup that was satisfied by the cache, a
+// miss is a lookup that had to search the module and its imports or includes.
+type CacheStats struct {
+	TypedefHits    int
+	TypedefMisses  int
+	GroupingHits   int
+	GroupingMisses int
+	SharedTypes    int // number of resolved types replaced by a shared type
+}
+
+// CacheStats returns the current resolution cache statistics of ms.
+func (ms *Modules) CacheStats() CacheStats {
+	return ms.cacheStats
+}
+
+// cachedTypedef returns the typedef previously found for name in m, or nil.
+// It returns nil if m is not part of a Modules.
+func cachedTypedef(m *Module, name string) *Typedef {
+	if m == nil || m.modules == nil {
+		return nil
+	}
+	ms := m.modules
+	if td := ms.typedefCache[scopedName{m, name}]; td != nil {
+		ms.cacheStats.TypedefHits++
+		return td
+	}
+	ms.cacheStats.TypedefMisses++
+	return nil
+}
+
+// cacheTypedef records that name resolves to td when looked up from m.
+func cacheTypedef(m *Module, name string, td *Typedef) {
+	if m == nil || m.modules == nil || td == nil {
+		return
+	}
+	m.modules.typedefCache[scopedName{m, name}] = td
+}
+
+// cachedGrouping returns the grouping previously found for name in m, or
+// nil.  It returns nil if m is not part of a Modules.
+func cachedGrouping(m *Module, name string) *Grouping {
+	if m == nil || m.modules == nil {
+		return nil
+	}
+	ms := m.modules
+	if g := ms.groupingCache[scopedName{m, name}]; g != nil {
+		ms.cacheStats.GroupingHits++
+		return g
+	}
+	ms.cacheStats.GroupingMisses++
+	return nil
+}
+
+// cacheGrouping records that name resolves to g when looked up from m.
+func cacheGrouping(m *Module, name string, g *Grouping) {
+	if m == nil || m.modules == nil || g == nil {
+		return
+	}
+	m.modules.groupingCache[scopedName{m, name}] = g
+}
+
+// A typeKey identifies a resolved YangType.  Two YangTypes with the same key
+// are indistinguishable and can be shared.
+type typeKey struct {
+	name             string
+	kind             TypeKind
+	base             *Type
+	identityBase     *Identity
+	root             *YangType // nil if the type is its own root
+	bit              *EnumType
+	enum             *EnumType
+	units            string
+	dflt             string
+	fractionDigits   int
+	length           string
+	optionalInstance bool
+	path             string
+	pattern          string
+	posixPattern     string
+	invertedPattern  string
+	rng              string
+	union            string
+}
+
+// newTypeKey returns the typeKey of y.
+func newTypeKey(y *YangType) typeKey {
+	k := typeKey{
+		name:             y.Name,
+		kind:             y.Kind,
+		base:             y.Base,
+		identityBase:     y.IdentityBase,
+		root:             y.Root,
+		bit:              y.Bit,
+		enum:             y.Enum,
+		units:            y.Units,
+		dflt:             y.Default,
+		fractionDigits:   y.FractionDigits,
+		length:           fmt.Sprintf("%#v", y.Length),
+		optionalInstance: y.OptionalInstance,
+		path:             y.Path,
+		pattern:          strings.Join(y.Pattern, "\x00"),
+		posixPattern:     strings.Join(y.POSIXPattern, "\x00"),
+		invertedPattern:  strings.Join(y.InvertedPattern, "\x00"),
+		rng:              fmt.Sprintf("%#v", y.Range),
+	}
+	if y.Root == y {
+		k.root = nil
+	}
+	// Union members have already been shared, so comparing them by
+	// address is sufficient.
+	for _, ut := range y.Type {
+		k.union += fmt.Sprintf("%p,", ut)
+	}
+	return k
+}
+
+// sharedType returns the YangType previously resolved in the context of m
+// that is identical to y, or y if there is none.  It returns y if m is not
+// part of a Modules.
+func sharedType(m *Module, y *YangType) *YangType {
+	if m == nil || m.modules == nil || y == nil {
+		return y
+	}
+	ms := m.modules
+	k := newTypeKey(y)
+	if sy := ms.typeCache[k]; sy != nil {
+		ms.cacheStats.SharedTypes++
+		return sy
+	}
+	ms.typeCache[k] = y
+	return y
+}
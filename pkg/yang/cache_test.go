@@ -0,0 +1,130 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import "testing"
+
+func TestCacheStats(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"cache-base": `
+			module cache-base {
+				prefix "b";
+				namespace "urn:b";
+				typedef base-type { type string; }
+				grouping base-group { leaf g { type base-type; } }
+			}
+		`,
+		"cache-test": `
+			module cache-test {
+				prefix "t";
+				namespace "urn:t";
+				import cache-base { prefix cb; }
+				typedef local-type { type string; }
+				grouping local-group { leaf l { type local-type; } }
+				container c {
+					leaf a { type local-type; }
+					leaf b { type local-type; }
+					leaf c { type cb:base-type; }
+					leaf d { type cb:base-type; }
+					container one { uses local-group; uses cb:base-group; }
+					container two { uses local-group; uses cb:base-group; }
+				}
+			}
+		`,
+	} {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatalf("cannot parse %s: %v", name, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	got := ms.CacheStats()
+	if got.TypedefHits == 0 {
+		t.Errorf("got %d typedef cache hits, want > 0 (stats %+v)", got.TypedefHits, got)
+	}
+	if got.GroupingHits == 0 {
+		t.Errorf("got %d grouping cache hits, want > 0 (stats %+v)", got.GroupingHits, got)
+	}
+
+	// The cached lookups must resolve to the same types as uncached ones.
+	e := ToEntry(ms.Modules["cache-test"])
+	for _, tt := range []struct {
+		path string
+		want string
+	}{
+		{"c/a", "local-type"},
+		{"c/b", "local-type"},
+		{"c/c", "base-type"},
+		{"c/d", "base-type"},
+		{"c/one/l", "local-type"},
+		{"c/two/g", "base-type"},
+	} {
+		le := e.Find(tt.path)
+		if le == nil {
+			t.Errorf("%s: not found", tt.path)
+			continue
+		}
+		if le.Type.Name != tt.want {
+			t.Errorf("%s: got type %s, want %s", tt.path, le.Type.Name, tt.want)
+		}
+	}
+}
+
+func TestSharedTypes(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module shared-test {
+			prefix "t";
+			namespace "urn:t";
+			typedef address { type string { length "1..64"; } }
+			leaf a { type address; }
+			leaf b { type address; }
+			leaf c { type address { length "1..8"; } }
+			leaf d { type string { length "1..8"; } }
+			leaf e { type string { length "1..8"; } }
+			leaf f { type union { type address; type int8; } }
+			leaf g { type union { type address; type int8; } }
+			leaf h { type enumeration { enum one; } }
+			leaf i { type enumeration { enum one; } }
+		}
+	`, "shared-test.yang"); err != nil {
+		t.Fatalf("cannot parse: %v", err)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	e := ToEntry(ms.Modules["shared-test"])
+	for _, tt := range []struct {
+		a, b string
+		want bool
+	}{
+		{"a", "b", true},
+		{"a", "c", false},
+		{"c", "d", false}, // different names and bases
+		{"d", "e", true},
+		{"f", "g", true},
+		{"h", "i", false}, // enumerations are not compared
+	} {
+		if got := e.Dir[tt.a].Type == e.Dir[tt.b].Type; got != tt.want {
+			t.Errorf("%s and %s: got shared %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+	if got := ms.CacheStats().SharedTypes; got == 0 {
+		t.Errorf("got %d shared types, want > 0", got)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements iterating over the children of an Entry in order.
+
+import "sort"
+
+// dataKeywords are the keywords of statements that define children of an
+// Entry.
+var dataKeywords = map[string]bool{
+	"action":       true,
+	"anydata":      true,
+	"anyxml":       true,
+	"case":         true,
+	"choice":       true,
+	"container":    true,
+	"leaf":         true,
+	"leaf-list":    true,
+	"list":         true,
+	"notification": true,
+	"rpc":          true,
+}
+
+// Children returns the children of e, i.e., the entries of e.Dir, in the
+// order they are declared in the source.  The children instantiated by a
+// uses statement are in place of the uses statement, and for a module,
+// those defined in an included submodule are in place of the include
+// statement.  Children added by augments follow, in the order the augments
+// were applied, and any remaining children are last, sorted by name.
+func (e *Entry) Children() []*Entry {
+	if e == nil || len(e.Dir) == 0 {
+		return nil
+	}
+	children := make([]*Entry, 0, len(e.Dir))
+	seen := map[*Entry]bool{}
+	add := func(name string) {
+		if ce := e.Dir[name]; ce != nil && !seen[ce] {
+			seen[ce] = true
+			children = append(children, ce)
+		}
+	}
+
+	// The uses statements that instantiated children of e, by their
+	// statement.
+	uses := map[*Statement]*Uses{}
+	for _, ce := range e.Dir {
+		for _, u := range ce.usedAt {
+			uses[u.Source] = u
+		}
+	}
+	visiting := map[*Statement]bool{}
+	var visit func(s *Statement)
+	visit = func(s *Statement) {
+		if s == nil || visiting[s] {
+			return
+		}
+		visiting[s] = true
+		defer delete(visiting, s)
+		for _, ss := range s.SubStatements() {
+			switch {
+			case dataKeywords[ss.Keyword]:
+				add(ss.Argument)
+			case ss.Keyword == "uses":
+				if u := uses[ss]; u != nil {
+					if g := FindGrouping(u, u.Name, map[string]bool{}); g != nil {
+						visit(g.Source)
+					}
+				}
+			case ss.Keyword == "include":
+				if m, ok := e.Node.(*Module); ok {
+					for _, i := range m.Include {
+						if i.Source == ss && i.Module != nil {
+							visit(i.Module.Source)
+						}
+					}
+				}
+			}
+		}
+	}
+	if e.Node != nil {
+		visit(e.Node.Statement())
+	}
+	for _, ae := range e.Augmented {
+		if ae.Node != nil {
+			visit(ae.Node.Statement())
+		}
+	}
+	if len(children) < len(e.Dir) {
+		var rest []*Entry
+		for _, ce := range e.Dir {
+			if !seen[ce] {
+				rest = append(rest, ce)
+			}
+		}
+		sort.Slice(rest, func(i, j int) bool { return rest[i].Name < rest[j].Name })
+		children = append(children, rest...)
+	}
+	return children
+}
+
+// SortedChildren returns the children of e, i.e., the entries of e.Dir,
+// sorted by name.
+func (e *Entry) SortedChildren() []*Entry {
+	if e == nil || len(e.Dir) == 0 {
+		return nil
+	}
+	children := make([]*Entry, 0, len(e.Dir))
+	for _, ce := range e.Dir {
+		children = append(children, ce)
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].Name < children[j].Name })
+	return children
+}
@@ -0,0 +1,95 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestChildren(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"o": `module o {
+  prefix "o";
+  namespace "urn:o";
+  include o-sub;
+  grouping inner { leaf i2 { type string; } leaf i1 { type string; } }
+  grouping outer { leaf g2 { type string; } uses inner; leaf g1 { type string; } }
+  container z {
+    leaf zeta { type string; }
+    uses outer;
+    container alpha;
+    choice m { leaf y { type string; } case b { leaf x { type string; } } }
+    leaf-list beta { type string; }
+  }
+  leaf last { type string; }
+}`,
+		"o-sub": `submodule o-sub { belongs-to o { prefix "o"; } container from-sub; }`,
+		"p": `module p {
+  prefix "p";
+  namespace "urn:p";
+  import o { prefix "o"; }
+  augment "/o:z" { leaf aug2 { type string; } leaf aug1 { type string; } }
+}`,
+	} {
+		if err := ms.Parse(text, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	names := func(es []*Entry) []string {
+		var s []string
+		for _, e := range es {
+			s = append(s, e.Name)
+		}
+		return s
+	}
+	o := ToEntry(ms.Modules["o"])
+	for _, tt := range []struct {
+		desc       string
+		e          *Entry
+		want       []string
+		wantSorted []string
+	}{{
+		desc:       "module",
+		e:          o,
+		want:       []string{"from-sub", "z", "last"},
+		wantSorted: []string{"from-sub", "last", "z"},
+	}, {
+		desc:       "container",
+		e:          o.Dir["z"],
+		want:       []string{"zeta", "g2", "i2", "i1", "g1", "alpha", "m", "beta", "aug2", "aug1"},
+		wantSorted: []string{"alpha", "aug1", "aug2", "beta", "g1", "g2", "i1", "i2", "m", "zeta"},
+	}, {
+		desc:       "choice",
+		e:          o.Dir["z"].Dir["m"],
+		want:       []string{"y", "b"},
+		wantSorted: []string{"b", "y"},
+	}, {
+		desc: "leaf",
+		e:    o.Dir["last"],
+	}} {
+		if diff := cmp.Diff(tt.want, names(tt.e.Children())); diff != "" {
+			t.Errorf("%s: Children (-want, +got):\n%s", tt.desc, diff)
+		}
+		if diff := cmp.Diff(tt.wantSorted, names(tt.e.SortedChildren())); diff != "" {
+			t.Errorf("%s: SortedChildren (-want, +got):\n%s", tt.desc, diff)
+		}
+	}
+}
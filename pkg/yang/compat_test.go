@@ -0,0 +1,181 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file pins the parts of the API that are used by upstream consumers
+// of github.com/openconfig/goyang, such as ygot, so this package remains a
+// drop in replacement for them.  If this file does not compile then a
+// change has broken compatibility.
+
+import (
+	"io"
+	"testing"
+)
+
+// Methods, with their upstream signatures.
+var (
+	_ func(*Entry) bool               = (*Entry).IsDir
+	_ func(*Entry) bool               = (*Entry).IsLeaf
+	_ func(*Entry) bool               = (*Entry).IsLeafList
+	_ func(*Entry) bool               = (*Entry).IsList
+	_ func(*Entry) bool               = (*Entry).IsContainer
+	_ func(*Entry) bool               = (*Entry).IsChoice
+	_ func(*Entry) bool               = (*Entry).IsCase
+	_ func(*Entry) bool               = (*Entry).ReadOnly
+	_ func(*Entry) string             = (*Entry).Path
+	_ func(*Entry) string             = (*Entry).DefaultValue
+	_ func(*Entry, string) *Entry     = (*Entry).Find
+	_ func(*Entry) *Value             = (*Entry).Namespace
+	_ func(*Entry) (string, error)    = (*Entry).InstantiatingModule
+	_ func(*Entry) *Modules           = (*Entry).Modules
+	_ func(*Entry) []error            = (*Entry).GetErrors
+	_ func(*Entry) (string, bool)     = (*Entry).GetWhenXPath
+	_ func(*Entry, io.Writer)         = (*Entry).Print
+	_ func(*YangType, *YangType) bool = (*YangType).Equal
+
+	_ func() *Modules                                  = NewModules
+	_ func(*Modules, string) error                     = (*Modules).Read
+	_ func(*Modules, string, string) error             = (*Modules).Parse
+	_ func(*Modules, string) (*Entry, []error)         = (*Modules).GetModule
+	_ func(*Modules, Node) *Module                     = (*Modules).FindModule
+	_ func(*Modules, string) (*Module, error)          = (*Modules).FindModuleByNamespace
+	_ func(*Modules, string) (*Module, error)          = (*Modules).FindModuleByPrefix
+	_ func(string, ...string) (*Entry, []error)        = GetModule
+	_ func(Node) *Entry                                = ToEntry
+	_ func(Node, string) *Module                       = FindModuleByPrefix
+	_ func(Node, string, string) ([]*Statement, error) = MatchingExtensions
+	_ func(Node) *Module                               = RootNode
+	_ func(Node) string                                = Source
+	_ func(Node, string) (Node, error)                 = FindNode
+	_ func(...string)                                  = AddPath
+	_ func(string) ([]string, error)                   = PathsWithModules
+	_ func(string) string                              = CamelCase
+	_ func(*Identity) string                           = (*Identity).PrefixedName
+	_ func(*EnumType) map[string]int64                 = (*EnumType).NameMap
+	_ func(*EnumType) map[int64]string                 = (*EnumType).ValueMap
+	_ func(*EnumType) []string                         = (*EnumType).Names
+	_ func(*EnumType) []int64                          = (*EnumType).Values
+)
+
+// useProcess calls Process the way upstream consumers do.  Process returns
+// Errors rather than the upstream []error, so the method value and
+// interfaces declaring Process() []error are not compatible, see
+// README.md.  Assigning or appending the result, as here, is.
+func useProcess(ms *Modules) []error {
+	var errs []error = ms.Process()
+	return append(errs, ms.Process()...)
+}
+
+// useFields refers to the fields used by upstream consumers, with their
+// upstream types.
+func useFields(e *Entry, y *YangType, m *Module, i *Identity) {
+	var (
+		_ *Entry            = e.Parent
+		_ Node              = e.Node
+		_ string            = e.Name
+		_ string            = e.Description
+		_ string            = e.Default
+		_ string            = e.Units
+		_ []error           = e.Errors
+		_ EntryKind         = e.Kind
+		_ TriState          = e.Config
+		_ *Value            = e.Prefix
+		_ TriState          = e.Mandatory
+		_ map[string]*Entry = e.Dir
+		_ string            = e.Key
+		_ *YangType         = e.Type
+		_ []*Statement      = e.Exts
+		_ *ListAttr         = e.ListAttr
+		_ *RPCEntry         = e.RPC
+		_ []*Identity       = e.Identities
+		_ []*Entry          = e.Augments
+		_ []*Entry          = e.Augmented
+		_ []*DeviatedEntry  = e.Deviations
+		_ []*UsesStmt       = e.Uses
+
+		_ map[string]interface{}   = e.Annotation
+		_ map[string][]interface{} = e.Extra
+
+		_ string      = y.Name
+		_ TypeKind    = y.Kind
+		_ *Type       = y.Base
+		_ *Identity   = y.IdentityBase
+		_ *YangType   = y.Root
+		_ *EnumType   = y.Bit
+		_ *EnumType   = y.Enum
+		_ string      = y.Units
+		_ string      = y.Default
+		_ int         = y.FractionDigits
+		_ YangRange   = y.Length
+		_ bool        = y.OptionalInstance
+		_ string      = y.Path
+		_ []string    = y.Pattern
+		_ []string    = y.POSIXPattern
+		_ YangRange   = y.Range
+		_ []*YangType = y.Type
+
+		_ string       = m.Name
+		_ *Value       = m.Namespace
+		_ *Value       = m.Prefix
+		_ *BelongsTo   = m.BelongsTo
+		_ []*Import    = m.Import
+		_ []*Revision  = m.Revision
+		_ []*Statement = m.Extensions
+
+		_ string      = i.Name
+		_ []*Value    = i.Base
+		_ []*Identity = i.Values
+
+		_ bool = ParseOptions.IgnoreSubmoduleCircularDependencies
+		_ bool = ParseOptions.StoreUses
+	)
+}
+
+func TestUpstreamAPI(t *testing.T) {
+	// Most checks are made by the compiler, this makes sure the kinds
+	// that upstream consumers switch on keep their names.
+	useFields(&Entry{}, &YangType{}, &Module{}, &Identity{})
+	if errs := useProcess(NewModules()); len(errs) > 0 {
+		t.Errorf("Process of no modules: got %v, want no errors", errs)
+	}
+	for k, want := range map[EntryKind]string{
+		LeafEntry:         "Leaf",
+		DirectoryEntry:    "Directory",
+		AnyDataEntry:      "AnyData",
+		AnyXMLEntry:       "AnyXML",
+		CaseEntry:         "Case",
+		ChoiceEntry:       "Choice",
+		InputEntry:        "Input",
+		NotificationEntry: "Notification",
+		OutputEntry:       "Output",
+	} {
+		if got := EntryKindToName[k]; got != want {
+			t.Errorf("EntryKindToName[%d] got %q, want %q", k, got, want)
+		}
+	}
+	for k, want := range map[TypeKind]string{
+		Yint8:               "int8",
+		Ystring:             "string",
+		Yunion:              "union",
+		Yleafref:            "leafref",
+		Yidentityref:        "identityref",
+		Ydecimal64:          "decimal64",
+		YinstanceIdentifier: "instance-identifier",
+	} {
+		if got := k.String(); got != want {
+			t.Errorf("TypeKind %d got %q, want %q", k, got, want)
+		}
+	}
+}
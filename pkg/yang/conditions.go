@@ -0,0 +1,102 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the structured form of when and must statements.
+
+import (
+	"reflect"
+
+	"github.com/openconfig/goyang/pkg/xpath"
+)
+
+// A Condition is a when or must statement (RFC 7950 sections 7.21.5 and
+// 7.5.3).
+type Condition struct {
+	Keyword string // "when" or "must"
+	Expr    string // the XPath expression, as written
+	// XPath is the parsed form of Expr.  It is nil if Expr cannot be
+	// parsed, in which case Err is the reason.
+	XPath xpath.Expr `json:"-"`
+	Err   error      `json:"-"`
+
+	Description  string `json:",omitempty"`
+	Reference    string `json:",omitempty"`
+	ErrorMessage string `json:",omitempty"` // must only
+	ErrorAppTag  string `json:",omitempty"` // must only
+
+	// Node is the statement, a *Value for when and a *Must for must.
+	Node Node `json:"-"`
+}
+
+// newCondition returns the Condition for the when or must statement n.
+func newCondition(keyword string, n Node) *Condition {
+	c := &Condition{Keyword: keyword, Expr: n.NName(), Node: n}
+	c.XPath, c.Err = xpath.Parse(c.Expr)
+	switch n := n.(type) {
+	case *Value:
+		c.Description = n.Description.asString()
+	case *Must:
+		c.Description = n.Description.asString()
+		c.Reference = n.Reference.asString()
+		c.ErrorMessage = n.ErrorMessage.asString()
+		c.ErrorAppTag = n.ErrorAppTag.asString()
+	}
+	return c
+}
+
+// NodeConditions returns the when and must statements of n, which is
+// typically a data definition statement such as a *Container or *Leaf.
+// when is nil if n has no when statement.
+func NodeConditions(n Node) (when *Condition, must []*Condition) {
+	v := reflect.ValueOf(n)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil, nil
+	}
+	v = v.Elem()
+	if f := v.FieldByName("When"); f.IsValid() {
+		if w, ok := f.Interface().(*Value); ok && w != nil {
+			when = newCondition("when", w)
+		}
+	}
+	if f := v.FieldByName("Must"); f.IsValid() {
+		if ms, ok := f.Interface().([]*Must); ok {
+			for _, m := range ms {
+				must = append(must, newCondition("must", m))
+			}
+		}
+	}
+	return when, must
+}
+
+// checkXPath returns warnings for the when and must statements of the
+// modules of ms whose expressions cannot be parsed.
+func (ms *Modules) checkXPath() []error {
+	var ws []error
+	for _, m := range ms.sortedModules() {
+		walkNodes(m, func(n Node) {
+			when, must := NodeConditions(n)
+			if when != nil {
+				must = append([]*Condition{when}, must...)
+			}
+			for _, c := range must {
+				if c.Err != nil {
+					ws = append(ws, warnf(c.Node, WarnBadXPath, "%s: %v", c.Keyword, c.Err))
+				}
+			}
+		})
+	}
+	return ws
+}
@@ -0,0 +1,111 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestConditions(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`module cond {
+  prefix "c";
+  namespace "urn:cond";
+  container c {
+    when "../enabled = 'true'" { description "only when enabled"; }
+    must "count(item) <= 10" {
+      error-message "too many items";
+      error-app-tag "too-many";
+      description "at most 10 items";
+      reference "RFC 0000";
+    }
+    must "not(item[name = 'x'])";
+    leaf-list item { type string; must ". != 'y'"; }
+    leaf bad { type string; when "a b"; }
+  }
+  leaf enabled { type boolean; }
+}`, "cond.yang"); err != nil {
+		t.Fatal(err)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	var warnings []string
+	for _, w := range ms.Warnings() {
+		warnings = append(warnings, w.Error())
+	}
+	wantWarnings := []string{`cond.yang:14:29: when: xpath "a b": offset 2: unexpected name "b", expected an operator`}
+	if diff := cmp.Diff(wantWarnings, warnings); diff != "" {
+		t.Errorf("Warnings() (-want, +got):\n%s", diff)
+	}
+
+	c := ToEntry(ms.Modules["cond"]).Dir["c"]
+	opts := cmpopts.IgnoreFields(Condition{}, "XPath", "Err", "Node")
+	want := &Condition{Keyword: "when", Expr: "../enabled = 'true'", Description: "only when enabled"}
+	if diff := cmp.Diff(want, c.When, opts); diff != "" {
+		t.Errorf("When (-want, +got):\n%s", diff)
+	}
+	if got, want := c.When.XPath.String(), "../enabled = 'true'"; got != want {
+		t.Errorf("When.XPath = %s, want %s", got, want)
+	}
+	wantMust := []*Condition{{
+		Keyword:      "must",
+		Expr:         "count(item) <= 10",
+		Description:  "at most 10 items",
+		Reference:    "RFC 0000",
+		ErrorMessage: "too many items",
+		ErrorAppTag:  "too-many",
+	}, {
+		Keyword: "must",
+		Expr:    "not(item[name = 'x'])",
+	}}
+	if diff := cmp.Diff(wantMust, c.Must, opts); diff != "" {
+		t.Errorf("Must (-want, +got):\n%s", diff)
+	}
+	for _, m := range c.Must {
+		if m.XPath == nil || m.Err != nil {
+			t.Errorf("%s: got XPath %v, error %v", m.Expr, m.XPath, m.Err)
+		}
+		if _, ok := m.Node.(*Must); !ok {
+			t.Errorf("%s: Node is %T, want *Must", m.Expr, m.Node)
+		}
+	}
+
+	item := c.Dir["item"]
+	if len(item.Must) != 1 || item.Must[0].Expr != ". != 'y'" || item.When != nil {
+		t.Errorf("leaf-list item: got when %v, must %v", item.When, item.Must)
+	}
+	bad := c.Dir["bad"]
+	if bad.When == nil || bad.When.XPath != nil || bad.When.Err == nil || !strings.Contains(bad.When.Err.Error(), "expected an operator") {
+		t.Errorf("leaf bad: got when %+v", bad.When)
+	}
+	if c.Dir["enabled"] != nil || ToEntry(ms.Modules["cond"]).Dir["enabled"].When != nil {
+		t.Errorf("leaf enabled has a when statement")
+	}
+
+	// The conditions of AST nodes are available without building entries.
+	when, must := NodeConditions(ms.Modules["cond"].Container[0])
+	if when == nil || len(must) != 2 {
+		t.Errorf("NodeConditions: got when %v, %d musts, want a when and 2 musts", when, len(must))
+	}
+	if when, must := NodeConditions(ms.Modules["cond"]); when != nil || must != nil {
+		t.Errorf("NodeConditions of a module: got %v, %v, want nil, nil", when, must)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the checks of the constraints of RFC 7950 that
+// Process does not otherwise enforce, see Options.StrictConformance.
+
+import "strings"
+
+// checkConformance returns the errors for the entries of the modules of ms
+// that do not conform to RFC 7950.
+func (ms *Modules) checkConformance() []error {
+	var errs []error
+	for _, m := range ms.sortedModules() {
+		walkEntries(ToEntry(m), func(e *Entry) bool {
+			errs = append(errs, checkEntryConformance(e)...)
+			return true
+		})
+	}
+	return errs
+}
+
+// checkEntryConformance returns the errors for e that do not conform to
+// RFC 7950.
+func checkEntryConformance(e *Entry) []error {
+	var errs []error
+	fail := func(code Code, format string, v ...interface{}) {
+		errs = append(errs, errorf(e.Node, code, format, v...))
+	}
+	if la := e.ListAttr; la != nil && la.MinElements > la.MaxElements {
+		fail(ErrInvalidArgument, "min-elements %d of %s is greater than max-elements %d", la.MinElements, e.Name, la.MaxElements)
+	}
+	switch {
+	case e.IsList():
+		errs = append(errs, checkListConformance(e)...)
+	case e.IsLeaf():
+		// RFC 7950 section 7.6.5
+		if e.Default != "" && e.Mandatory == TSTrue {
+			fail(ErrInvalidArgument, "mandatory leaf %s has a default", e.Name)
+		}
+	case e.IsChoice() && e.Default != "":
+		// RFC 7950 section 7.9.3
+		if e.Mandatory == TSTrue {
+			fail(ErrInvalidArgument, "mandatory choice %s has a default", e.Name)
+		}
+		c := e.Dir[e.Default]
+		if c == nil {
+			fail(ErrInvalidArgument, "default case %s of choice %s not found", e.Default, e.Name)
+			break
+		}
+		walkEntries(c, func(ce *Entry) bool {
+			switch {
+			case ce == c:
+				return true
+			case ce.Mandatory == TSTrue, ce.ListAttr != nil && ce.ListAttr.MinElements > 0:
+				fail(ErrInvalidArgument, "default case %s of choice %s has mandatory node %s", e.Default, e.Name, ce.Name)
+			}
+			// Only the nodes directly under the case, i.e., not
+			// within a container or list, are checked.
+			return ce.IsChoice() || ce.IsCase()
+		})
+	}
+	return errs
+}
+
+// checkListConformance returns the errors for the key and unique
+// statements of the list e (RFC 7950 sections 7.8.2 and 7.8.3).
+func checkListConformance(e *Entry) []error {
+	var errs []error
+	fail := func(code Code, format string, v ...interface{}) {
+		errs = append(errs, errorf(e.Node, code, format, v...))
+	}
+	config := e.IsConfig()
+	if e.Key == "" && config {
+		fail(ErrMissingSubstatement, "list %s represents configuration but has no key", e.Name)
+	}
+	seen := map[string]bool{}
+	for _, k := range strings.Fields(e.Key) {
+		_, k = getPrefix(k)
+		c := e.Dir[k]
+		switch {
+		case seen[k]:
+			fail(ErrInvalidArgument, "key %s of list %s is given more than once", k, e.Name)
+		case c == nil || !c.IsLeaf():
+			fail(ErrInvalidArgument, "key %s of list %s is not a leaf of the list", k, e.Name)
+		case c.IsConfig() != config:
+			fail(ErrInvalidArgument, "key %s of list %s does not have the config of the list", k, e.Name)
+		}
+		seen[k] = true
+	}
+
+	l, ok := e.Node.(*List)
+	if !ok {
+		return errs
+	}
+	for _, u := range l.Unique {
+		var leaves []*Entry
+		for _, id := range strings.Fields(u.Name) {
+			c := e
+			for _, part := range strings.Split(id, "/") {
+				_, part = getPrefix(part)
+				if c = c.Dir[part]; c == nil {
+					break
+				}
+			}
+			if c == nil || !c.IsLeaf() {
+				errs = append(errs, errorf(u, ErrInvalidArgument, "unique %s of list %s does not refer to a leaf", id, e.Name))
+				continue
+			}
+			leaves = append(leaves, c)
+		}
+		for _, c := range leaves[1:] {
+			if c.IsConfig() != leaves[0].IsConfig() {
+				errs = append(errs, errorf(u, ErrInvalidArgument, "unique %q of list %s refers to both config and state leaves", u.Name, e.Name))
+				break
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,145 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStrictConformance(t *testing.T) {
+	defer func(o Options) { ParseOptions = o }(ParseOptions)
+
+	for _, tt := range []struct {
+		desc string
+		in   string
+		want []string
+	}{{
+		desc: "conforming",
+		in: `
+  list l {
+    key "k";
+    unique "a c/b";
+    leaf k { type string; }
+    leaf a { type string; }
+    container c { leaf b { type string; } }
+  }
+  list s {
+    config false;
+    leaf a { type string; }
+  }
+  leaf-list ll { type string; min-elements 1; max-elements 2; }
+  choice ch {
+    default x;
+    case x { leaf x { type string; } }
+    case y { leaf y { mandatory true; type string; } }
+  }`,
+	}, {
+		desc: "list without key",
+		in: `
+  list l { leaf a { type string; } }`,
+		want: []string{"c.yang:4:3: list l represents configuration but has no key"},
+	}, {
+		desc: "invalid keys",
+		in: `
+  list l {
+    key "c:a a b c";
+    leaf a { type string; }
+    leaf-list b { type string; }
+    container c;
+  }`,
+		want: []string{
+			"c.yang:4:3: key a of list l is given more than once",
+			"c.yang:4:3: key b of list l is not a leaf of the list",
+			"c.yang:4:3: key c of list l is not a leaf of the list",
+		},
+	}, {
+		desc: "state key in config list",
+		in: `
+  list l {
+    key "a";
+    leaf a { config false; type string; }
+  }`,
+		want: []string{"c.yang:4:3: key a of list l does not have the config of the list"},
+	}, {
+		desc: "invalid unique",
+		in: `
+  list l {
+    key "k";
+    unique "k x";
+    unique "k s";
+    leaf k { type string; }
+    leaf s { config false; type string; }
+  }`,
+		want: []string{
+			"c.yang:6:5: unique x of list l does not refer to a leaf",
+			`c.yang:7:5: unique "k s" of list l refers to both config and state leaves`,
+		},
+	}, {
+		desc: "mandatory with default",
+		in: `
+  leaf a { mandatory true; default "x"; type string; }
+  leaf-list c { min-elements 3; max-elements 2; type string; }`,
+		want: []string{
+			"c.yang:4:3: mandatory leaf a has a default",
+			"c.yang:5:3: min-elements 3 of c is greater than max-elements 2",
+		},
+	}, {
+		desc: "invalid choice default",
+		in: `
+  choice a {
+    mandatory true;
+    default x;
+    case x { leaf x { type string; } }
+  }
+  choice b {
+    default y;
+    case x { leaf x { type string; } }
+  }
+  choice c {
+    default z;
+    case z {
+      leaf z { mandatory true; type string; }
+      container n { leaf m { mandatory true; type string; } }
+    }
+  }`,
+		want: []string{
+			"c.yang:4:3: mandatory choice a has a default",
+			"c.yang:9:3: default case y of choice b not found",
+			"c.yang:13:3: default case z of choice c has mandatory node z",
+		},
+	}} {
+		parse := func() *Modules {
+			ms := NewModules()
+			if err := ms.Parse("module c {\n  prefix \"c\";\n  namespace \"urn:c\";"+tt.in+"\n}", "c.yang"); err != nil {
+				t.Fatalf("%s: %v", tt.desc, err)
+			}
+			return ms
+		}
+		ParseOptions.StrictConformance = false
+		if errs := parse().Process(); len(errs) > 0 {
+			t.Fatalf("%s: Process without StrictConformance: %v", tt.desc, errs)
+		}
+		ParseOptions.StrictConformance = true
+		var got []string
+		for _, err := range parse().Process() {
+			got = append(got, err.Error())
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("%s (-want, +got):\n%s", tt.desc, diff)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package yang
+
+// This file implements the structured form of the diagnostics reported by
+// this package.
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Severity is the severity of a diagnostic.
+type Severity int
+
+const (
+	// SeverityError is the severity of a diagnostic that prevents the
+	// modules from being processed.
+	SeverityError Severity = iota
+	// SeverityWarning is the severity of a diagnostic reporting a problem
+	// that was recovered from or a questionable construct.
+	SeverityWarning
+	// SeverityIgnore is the severity of a diagnostic that is not
+	// reported, see Options.Severities.
+	SeverityIgnore
+)
+
+var severityNames = map[Severity]string{
+	SeverityError:   "error",
+	SeverityWarning: "warning",
+	SeverityIgnore:  "ignore",
+}
+
+func (s Severity) String() string {
+	if n := severityNames[s]; n != "" {
+		return n
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// A Code identifies the kind of problem reported by a diagnostic.  Codes
+// are stable: the text of a message may change between releases but its
+// code does not.
+type Code string
+
+// The codes of the diagnostics reported by this package.
+const (
+	// Errors reading and parsing source files.
+	ErrFileNotFound    Code = "file-not-found"
+	ErrSyntax          Code = "syntax"
+	ErrLimitExceeded   Code = "limit-exceeded"
+	ErrNotModule       Code = "not-module"
+	ErrDuplicateModule Code = "duplicate-module"
+
+	// Errors in the structure of statements.
+	ErrUnknownStatement       Code = "unknown-statement"
+	ErrUnexpectedSubstatement Code = "unexpected-substatement"
+	ErrMissingSubstatement    Code = "missing-substatement"
+	ErrDuplicateSubstatement  Code = "duplicate-substatement"
+	ErrInvalidArgument        Code = "invalid-argument"
+	ErrYangVersion            Code = "yang-version"
+
+	// Errors resolving references.
+	ErrUnknownModule      Code = "unknown-module"
+	ErrUnknownPrefix      Code = "unknown-prefix"
+	ErrUnknownType        Code = "unknown-type"
+	ErrUnknownGrouping    Code = "unknown-grouping"
+	ErrUnknownIdentity    Code = "unknown-identity"
+	ErrRecursiveGrouping  Code = "recursive-grouping"
+	ErrCircularDependency Code = "circular-dependency"
+
+	// Errors in types.
+	ErrBadRange          Code = "bad-range"
+	ErrBadLength         Code = "bad-length"
+	ErrBadPattern        Code = "bad-pattern"
+	ErrBadFractionDigits Code = "bad-fraction-digits"
+	ErrBadEnum           Code = "bad-enum"
+	ErrRecursiveType     Code = "recursive-type"
+
+	// Errors building the tree of entries.
+	ErrDuplicateNode          Code = "duplicate-node"
+	ErrAugmentTargetMissing   Code = "augment-target-missing"
+	ErrDeviationTargetMissing Code = "deviation-target-missing"
+	ErrBadDeviation           Code = "bad-deviation"
+	ErrBadExtension           Code = "bad-extension"
+	ErrTransform              Code = "transform"
+	ErrInternal               Code = "internal"
+	ErrTooManyErrors          Code = "too-many-errors"
+
+	// Warnings.
+	WarnRevisionNotFound   Code = "revision-not-found"
+	WarnCircularDependency Code = "ignored-circular-dependency"
+	WarnDeprecated         Code = "deprecated"
+	WarnObsolete           Code = "obsolete"
+	WarnBadXPath           Code = "bad-xpath"
+	WarnUnusedImport       Code = "unused-import"
+	WarnImportNoRevision   Code = "import-without-revision"
+	WarnNoDescription      Code = "missing-description"
+	WarnBadRevisionDate    Code = "bad-revision-date"
+	WarnDuplicateRevision  Code = "duplicate-revision"
+)
+
+// An Error is a diagnostic reported while processing modules.  Its text
+// is the same as that of the unstructured errors returned by this package,
+// i.e., the location of the offending statement followed by a message.
+// When the location is not known, the statement path is used instead.
+type Error struct {
+	Severity Severity
+	Code     Code
+	File     string // file of the offending statement, "" if not known
+	Line     int    // 1's based line of the offending statement, 0 if not known
+	Col      int    // 1's based column of the offending statement, 0 if not known
+	Path     string // statement path of the offending statement
+	Msg      string
+	Node     Node // the offending statement, or nil if not known
+
+	format  string        // built-in format of Msg, see Modules.localize
+	args    []interface{} // arguments of format
+	dropped int           // errors not reported, see DroppedErrors
+}
+
+// A Position is the location of a statement in a source file.
+type Position struct {
+	File string
+	Line int // 1's based line number, 0 if not known
+	Col  int // 1's based column number, 0 if not known
+}
+
+// String returns p in the form used in the text of errors, e.g.,
+// "a.yang:3:7", or "unknown" if p is the zero Position.
+func (p Position) String() string {
+	switch {
+	case p.File == "" && p.Line == 0:
+		return "unknown"
+	case p.File == "":
+		return fmt.Sprintf("line %d:%d", p.Line, p.Col)
+	case p.Line == 0:
+		return p.File
+	default:
+		return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Col)
+	}
+}
+
+// Position returns the position of the offending statement, as given by
+// e.File, e.Line and e.Col.  It is the zero Position if the location is
+// not known.
+func (e *Error) Position() Position {
+	return Position{File: e.File, Line: e.Line, Col: e.Col}
+}
+
+func (e *Error) Error() string {
+	var pos string
+	switch p := e.Position(); {
+	case p != Position{}:
+		pos = p.String()
+	case e.Path != "":
+		pos = e.Path
+	default:
+		return e.Msg
+	}
+	return pos + ": " + e.Msg
+}
+
+// StatementPath returns the path of statements from the module or
+// submodule statement down to n, e.g.,
+//
+//   module foo / container bar / leaf baz / type string
+//
+// Each statement is named by its keyword and argument.
+func StatementPath(n Node) string {
+	var parts []string
+	for ; n != nil; n = n.ParentNode() {
+		part := n.Kind()
+		if name := n.NName(); name != "" {
+			part += " " + name
+		}
+		parts = append(parts, part)
+	}
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(parts, " / ")
+}
+
+// Errors is a list of errors, such as the errors returned by Process.
+// Errors is a []error, so it can be used wherever a []error is expected.
+//
+// A nil Errors assigned to a variable of type error is not a nil error, use
+// the Err method to obtain an error that is nil when there are no errors.
+type Errors []error
+
+// Error returns the text of the errors in e, one per line.
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the errors in e.  It allows errors.Is and errors.As to
+// match any of the errors in e in Go 1.20 and later.
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// Err returns e as an error, or nil if e is empty.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// ErrorCode returns the code of err, or "" if err was not reported by this
+// package.  The code of an Errors, such as the syntax errors returned by
+// Parse, is the code of its first error.
+func ErrorCode(err error) Code {
+	switch err := err.(type) {
+	case *Error:
+		return err.Code
+	case Errors:
+		if len(err) > 0 {
+			return ErrorCode(err[0])
+		}
+	case *fileSizeError:
+		return ErrLimitExceeded
+	}
+	return ""
+}
+
+// DroppedErrors returns the number of errors that were not reported in
+// place of the ErrTooManyErrors error err, or 0 if err is not such an
+// error.  See Options.MaxErrors.
+func DroppedErrors(err error) int {
+	if e, ok := err.(*Error); ok && e.Code == ErrTooManyErrors {
+		return e.dropped
+	}
+	return 0
+}
+
+// errorf returns an error with code about n.  The message is prefixed by
+// the location of n unless n is nil.  It is formatted with the catalog of
+// ParseOptions until the Modules reporting the error localizes it.
+func errorf(n Node, code Code, format string, v ...interface{}) *Error {
+	e := &Error{
+		Code:   code,
+		Msg:    msgf(ParseOptions.Catalog, code, format, v...),
+		format: format,
+		args:   v,
+	}
+	if n != nil {
+		p := n.Statement().Position()
+		e.File, e.Line, e.Col = p.File, p.Line, p.Col
+		e.Path = StatementPath(n)
+		e.Node = n
+	}
+	return e
+}
+
+// errorfAt returns an error with code at pos, for errors found before
+// there is a statement to report them about, such as syntax errors.
+func errorfAt(pos Position, code Code, format string, v ...interface{}) *Error {
+	e := errorf(nil, code, format, v...)
+	e.File, e.Line, e.Col = pos.File, pos.Line, pos.Col
+	return e
+}
+
+// warnf returns a warning with code about n.
+func warnf(n Node, code Code, format string, v ...interface{}) *Error {
+	e := errorf(n, code, format, v...)
+	e.Severity = SeverityWarning
+	return e
+}
+
+// A Diagnostic is the structured form of an error or warning, suitable for
+// encoding as JSON for editors and CI systems.
+type Diagnostic struct {
+	Code     Code   `json:"code,omitempty"`
+	Severity string `json:"severity"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Diagnostics returns the structured form of errs.  Lists of errors, such
+// as the syntax errors returned by Parse, are expanded into one Diagnostic
+// per error.  Errors not reported by this package have no location.
+func Diagnostics(errs []error) []Diagnostic {
+	var ds []Diagnostic
+	for _, err := range errs {
+		switch err := err.(type) {
+		case Errors:
+			ds = append(ds, Diagnostics(err)...)
+		case *Error:
+			p := err.Position()
+			ds = append(ds, Diagnostic{
+				Code:     err.Code,
+				Severity: err.Severity.String(),
+				File:     p.File,
+				Line:     p.Line,
+				Column:   p.Col,
+				Path:     err.Path,
+				Message:  err.Msg,
+			})
+		default:
+			ds = append(ds, Diagnostic{
+				Severity: SeverityError.String(),
+				Message:  err.Error(),
+			})
+		}
+	}
+	return ds
+}
+
+// WriteDiagnosticsJSON writes the structured form of errs to w as JSON, one
+// object per line.
+func WriteDiagnosticsJSON(w io.Writer, errs []error) error {
+	enc := json.NewEncoder(w)
+	for _, d := range Diagnostics(errs) {
+		if err := enc.Encode(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
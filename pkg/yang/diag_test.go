@@ -0,0 +1,489 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package yang
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSeverityString(t *testing.T) {
+	for s, want := range map[Severity]string{
+		SeverityError:   "error",
+		SeverityWarning: "warning",
+		Severity(42):    "Severity(42)",
+	} {
+		if got := s.String(); got != want {
+			t.Errorf("Severity(%d): got %q, want %q", int(s), got, want)
+		}
+	}
+}
+
+func TestErrorText(t *testing.T) {
+	for _, tt := range []struct {
+		err  *Error
+		want string
+	}{
+		{&Error{File: "a.yang", Line: 1, Col: 2, Msg: "bad"}, "a.yang:1:2: bad"},
+		{&Error{File: "a.yang", Msg: "bad"}, "a.yang: bad"},
+		{&Error{Path: "module a / leaf l", Msg: "bad"}, "module a / leaf l: bad"},
+		{&Error{Severity: SeverityWarning, Msg: "bad"}, "bad"},
+	} {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("%#v: got %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	var none Errors
+	if err := none.Err(); err != nil {
+		t.Errorf("empty Errors: got error %v, want nil", err)
+	}
+
+	e1 := &Error{File: "b.yang", Line: 10, Col: 1, Msg: "second"}
+	e2 := fmt.Errorf("b.yang:2:1: first")
+	errs := Errors(errorSort([]error{e1, e2, fmt.Errorf("b.yang:10:1: second")}))
+	if got, want := errs.Error(), "b.yang:2:1: first\nb.yang:10:1: second"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := errs.Err(); got == nil {
+		t.Errorf("Err: got nil, want errors")
+	}
+	if got := errs.Unwrap(); len(got) != 2 || got[0] != e2 || got[1] != e1 {
+		t.Errorf("Unwrap: got %v, want [%v %v]", got, e2, e1)
+	}
+}
+
+func TestErrorPath(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module a {
+			prefix "a";
+			namespace "urn:a";
+			container c {
+				list l {
+					key "k";
+					leaf k { type frob; }
+				}
+			}
+		}
+	`, "a.yang"); err != nil {
+		t.Fatal(err)
+	}
+	errs := ms.Process()
+	if len(errs) != 1 {
+		t.Fatalf("got errors %v, want 1 error", errs)
+	}
+	err, ok := errs[0].(*Error)
+	if !ok {
+		t.Fatalf("got %T, want *Error", errs[0])
+	}
+	if got, want := err.Path, "module a / container c / list l / leaf k / type frob"; got != want {
+		t.Errorf("got path %q, want %q", got, want)
+	}
+	if got, want := err.Position(), (Position{File: "a.yang", Line: 8, Col: 15}); got != want {
+		t.Errorf("got position %+v, want %+v", got, want)
+	}
+	if n, ok := err.Node.(*Type); !ok || n.Name != "frob" {
+		t.Errorf("got node %v, want type frob", err.Node)
+	}
+
+	// Without a location the path identifies the statement.
+	err.File, err.Line, err.Col = "", 0, 0
+	if got := err.Position(); got != (Position{}) {
+		t.Errorf("got position %+v for an unknown location, want none", got)
+	}
+	if got, want := err.Error(), "module a / container c / list l / leaf k / type frob: unknown type: a:frob"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestErrorPosition(t *testing.T) {
+	for _, tt := range []struct {
+		file      string
+		line, col int
+		pos       string
+	}{
+		{"a.yang", 3, 7, "a.yang:3:7"},
+		{"dir:1/a.yang", 3, 7, "dir:1/a.yang:3:7"},
+		{`C:\models\a.yang`, 12, 1, `C:\models\a.yang:12:1`},
+		{"", 2, 1, "line 2:1"},
+		{"a.yang", 0, 0, "a.yang"},
+		{"", 0, 0, "unknown"},
+	} {
+		err := errorf(FakeStatement("leaf", tt.file, tt.line, tt.col), ErrSyntax, "bad")
+		if got, want := err.Position(), (Position{File: tt.file, Line: tt.line, Col: tt.col}); got != want {
+			t.Errorf("%s: got position %+v, want %+v", tt.pos, got, want)
+		}
+		if got := err.Position().String(); got != tt.pos {
+			t.Errorf("%s: got position text %q", tt.pos, got)
+		}
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		in   string
+		want Code
+	}{{
+		name: "syntax",
+		in:   `module a { prefix "a"; namespace "urn:a"; leaf l { type string; }`,
+		want: ErrSyntax,
+	}, {
+		name: "unexpected substatement",
+		in:   `module a { prefix "a"; namespace "urn:a"; frob x; }`,
+		want: ErrUnexpectedSubstatement,
+	}, {
+		name: "missing substatement",
+		in:   `module a { prefix "a"; }`,
+		want: ErrMissingSubstatement,
+	}, {
+		name: "unknown type",
+		in:   `module a { prefix "a"; namespace "urn:a"; leaf l { type frob; } }`,
+		want: ErrUnknownType,
+	}, {
+		name: "unknown prefix",
+		in:   `module a { prefix "a"; namespace "urn:a"; leaf l { type x:frob; } }`,
+		want: ErrUnknownPrefix,
+	}, {
+		name: "bad range",
+		in:   `module a { prefix "a"; namespace "urn:a"; leaf l { type int8 { range "1..1000"; } } }`,
+		want: ErrBadRange,
+	}, {
+		name: "unknown grouping",
+		in:   `module a { prefix "a"; namespace "urn:a"; container c { uses g; } }`,
+		want: ErrUnknownGrouping,
+	}, {
+		name: "augment target missing",
+		in:   `module a { prefix "a"; namespace "urn:a"; augment "/a:c" { leaf l { type string; } } }`,
+		want: ErrAugmentTargetMissing,
+	}, {
+		name: "deviation target missing",
+		in:   `module a { prefix "a"; namespace "urn:a"; deviation "/a:c" { deviate not-supported; } }`,
+		want: ErrDeviationTargetMissing,
+	}, {
+		name: "unknown module",
+		in:   `module a { prefix "a"; namespace "urn:a"; import missing-module { prefix "m"; } }`,
+		want: ErrUnknownModule,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := NewModules()
+			var errs []error
+			if err := ms.Parse(tt.in, "a.yang"); err != nil {
+				errs = []error{err}
+			} else {
+				errs = ms.Process()
+			}
+			if len(errs) == 0 {
+				t.Fatalf("got no errors, want %s", tt.want)
+			}
+			for _, err := range errs {
+				if got := ErrorCode(err); got != tt.want {
+					t.Errorf("%v: got code %q, want %q", err, got, tt.want)
+				}
+			}
+		})
+	}
+
+	if got := ErrorCode(errors.New("other")); got != "" {
+		t.Errorf("ErrorCode of a foreign error: got %q, want \"\"", got)
+	}
+	if got := ErrorCode(NewModules().Read("no-such-module-anywhere")); got != ErrFileNotFound {
+		t.Errorf("Read of a missing module: got %q, want %q", got, ErrFileNotFound)
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	errs := []error{
+		&Error{Code: ErrUnknownType, File: "a.yang", Line: 3, Col: 7, Path: "module a / leaf l / type t", Msg: "unknown type: a:t"},
+		&Error{Severity: SeverityWarning, Code: WarnRevisionNotFound, Line: 2, Col: 1, Msg: "revision"},
+		Errors{
+			&Error{Code: ErrSyntax, File: "b.yang", Line: 1, Col: 2, Msg: "syntax error"},
+			&Error{Code: ErrSyntax, File: "b.yang", Line: 4, Col: 1, Msg: "unexpected }"},
+		},
+		&Error{Code: ErrLimitExceeded, File: "c.yang", Msg: "too big"},
+		Errors{&Error{Code: ErrTransform, Msg: "transform"}},
+		errors.New("d.yang:5:6: other"),
+	}
+	want := []Diagnostic{
+		{Code: ErrUnknownType, Severity: "error", File: "a.yang", Line: 3, Column: 7, Path: "module a / leaf l / type t", Message: "unknown type: a:t"},
+		{Code: WarnRevisionNotFound, Severity: "warning", Line: 2, Column: 1, Message: "revision"},
+		{Code: ErrSyntax, Severity: "error", File: "b.yang", Line: 1, Column: 2, Message: "syntax error"},
+		{Code: ErrSyntax, Severity: "error", File: "b.yang", Line: 4, Column: 1, Message: "unexpected }"},
+		{Code: ErrLimitExceeded, Severity: "error", File: "c.yang", Message: "too big"},
+		{Code: ErrTransform, Severity: "error", Message: "transform"},
+		{Severity: "error", Message: "d.yang:5:6: other"},
+	}
+	if diff := cmp.Diff(want, Diagnostics(errs)); diff != "" {
+		t.Errorf("Diagnostics (-want, +got):\n%s", diff)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDiagnosticsJSON(&buf, errs[:1]); err != nil {
+		t.Fatal(err)
+	}
+	wantJSON := `{"code":"unknown-type","severity":"error","file":"a.yang","line":3,"column":7,"path":"module a / leaf l / type t","message":"unknown type: a:t"}` + "\n"
+	if got := buf.String(); got != wantJSON {
+		t.Errorf("WriteDiagnosticsJSON: got %s, want %s", got, wantJSON)
+	}
+}
+
+func TestErrorOrder(t *testing.T) {
+	mods := map[string]string{
+		"c.yang": `module c { prefix "c"; namespace "urn:c";
+			leaf l1 { type missing; }
+			leaf l2 { type int8 { range "1..500"; } }
+		}`,
+		"a.yang": `module a { prefix "a"; namespace "urn:a";
+			augment "/a:missing" { leaf l { type string; } }
+			leaf l1 { type string; }
+		}`,
+		"b.yang": `module b { prefix "b"; namespace "urn:b";
+			leaf l1 { type gone; }
+		}`,
+	}
+	want := []string{
+		"b.yang:2:14: unknown type: b:gone",
+		"c.yang:2:14: unknown type: c:missing",
+		"c.yang:3:26: bad range: 1..500 not within -128..127",
+	}
+	for i := 0; i < 10; i++ {
+		ms := NewModules()
+		for name, text := range mods {
+			if err := ms.Parse(text, name); err != nil {
+				t.Fatal(err)
+			}
+		}
+		var got []string
+		for _, err := range ms.Process() {
+			got = append(got, err.Error())
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("run %d: errors (-want, +got):\n%s", i, diff)
+		}
+	}
+}
+
+func TestErrorSortSamePosition(t *testing.T) {
+	errs := []error{
+		&Error{File: "a.yang", Line: 3, Col: 1, Code: ErrUnknownType, Msg: "unknown type: b"},
+		&Error{File: "a.yang", Line: 3, Col: 1, Code: ErrBadRange, Msg: "bad range: 1..500"},
+		&Error{File: "a.yang", Line: 3, Col: 1, Code: ErrUnknownType, Msg: "unknown type: a"},
+		&Error{File: "a.yang", Line: 3, Col: 1, Code: ErrBadEnum, Msg: "unknown type: b"},
+		&Error{File: "a.yang", Line: 2, Col: 1, Code: ErrUnknownType, Msg: "unknown type: c"},
+		&Error{File: "a.yang", Line: 3, Col: 1, Code: ErrUnknownType, Msg: "unknown type: b"},
+	}
+	want := []string{
+		"a.yang:2:1: unknown type: c",
+		"a.yang:3:1: unknown type: b", // bad-enum
+		"a.yang:3:1: bad range: 1..500",
+		"a.yang:3:1: unknown type: a",
+	}
+	// Every rotation of errs sorts the same way.
+	for i := range errs {
+		in := append(append([]error{}, errs[i:]...), errs[:i]...)
+		var got []string
+		for _, err := range errorSort(in) {
+			got = append(got, err.Error())
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("rotation %d: errors (-want, +got):\n%s", i, diff)
+		}
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	defer func() { ParseOptions.IgnoreSubmoduleCircularDependencies = false }()
+
+	for _, tt := range []struct {
+		name      string
+		inModules map[string]string
+		inIgnore  bool
+		want      []string
+	}{{
+		name: "no warnings",
+		inModules: map[string]string{
+			"a": `module a { prefix "a"; namespace "urn:a"; import b { prefix "b"; } leaf l { type b:t; } }`,
+			"b": `module b { prefix "b"; namespace "urn:b"; revision 2020-01-01; typedef t { type string; } }`,
+		},
+	}, {
+		name: "import revision not found",
+		inModules: map[string]string{
+			"a": `module a {
+				prefix "a";
+				namespace "urn:a";
+				import b { prefix "b"; revision-date 2019-06-01; }
+				leaf l { type b:t; }
+			}`,
+			"b": `module b { prefix "b"; namespace "urn:b"; revision 2020-01-01; typedef t { type string; } }`,
+		},
+		want: []string{"a:4:5: revision 2019-06-01 of b not found, using revision 2020-01-01"},
+	}, {
+		name: "unused import",
+		inModules: map[string]string{
+			"a": `module a {
+				prefix "a";
+				namespace "urn:a";
+				import b { prefix "b"; }
+				import c { prefix "c"; }
+				import d { prefix "d"; }
+				description "b:t is not a use of b";
+				c:e;
+				leaf l { type string; must "count(d:x) > 0"; }
+			}`,
+			"b": `module b { prefix "b"; namespace "urn:b"; }`,
+			"c": `module c { prefix "c"; namespace "urn:c"; extension e; }`,
+			"d": `module d { prefix "d"; namespace "urn:d"; }`,
+		},
+		want: []string{"a:4:5: prefix b of imported module b is not used"},
+	}, {
+		name: "ignored circular include",
+		inModules: map[string]string{
+			"a":  `module a { prefix "a"; namespace "urn:a"; include s1; }`,
+			"s1": `submodule s1 { belongs-to a { prefix "a"; } include s2; }`,
+			"s2": `submodule s2 { belongs-to a { prefix "a"; } include s1; }`,
+		},
+		inIgnore: true,
+		want:     []string{"s2:1:1: ignoring circular dependency, importing s1"},
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			ParseOptions.IgnoreSubmoduleCircularDependencies = tt.inIgnore
+			ms := NewModules()
+			for name, text := range tt.inModules {
+				if err := ms.Parse(text, name); err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+			}
+			if errs := ms.Process(); len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			var got []string
+			for _, w := range ms.Warnings() {
+				if e, ok := w.(*Error); !ok || e.Severity != SeverityWarning {
+					t.Errorf("%v: not a warning", w)
+				}
+				got = append(got, w.Error())
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got warnings %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWarningsAsErrors(t *testing.T) {
+	defer func() { ParseOptions.WarningsAsErrors = false }()
+	ParseOptions.WarningsAsErrors = true
+
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"a": `module a { prefix "a"; namespace "urn:a"; import b { prefix "b"; revision-date 2019-06-01; } leaf l { type b:t; } }`,
+		"b": `module b { prefix "b"; namespace "urn:b"; revision 2020-01-01; typedef t { type string; } }`,
+	} {
+		if err := ms.Parse(text, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	errs := ms.Process()
+	if len(errs) != 1 {
+		t.Fatalf("got errors %v, want 1 error", errs)
+	}
+	if e, ok := errs[0].(*Error); !ok || e.Severity != SeverityError || e.Code != WarnRevisionNotFound {
+		t.Errorf("got %#v, want promoted %s warning", errs[0], WarnRevisionNotFound)
+	}
+	if ws := ms.Warnings(); len(ws) != 0 {
+		t.Errorf("got warnings %v, want none", ws)
+	}
+}
+
+func TestSeverities(t *testing.T) {
+	defer func(o Options) { ParseOptions = o }(ParseOptions)
+
+	const a = `module a {
+  prefix "a";
+  namespace "urn:a";
+  import b { prefix "b"; revision-date 2019-06-01; }
+  import c { prefix "c"; }
+  description "a";
+  leaf l { type b:t; }
+}`
+	const b = `module b { prefix "b"; namespace "urn:b"; revision 2020-01-01; typedef t { type string; } }`
+	const c = `module c { prefix "c"; namespace "urn:c"; description "c"; }`
+
+	for _, tt := range []struct {
+		name       string
+		severities map[Code]Severity
+		wantErrs   []string
+		wantWarns  []string
+	}{{
+		name: "defaults",
+		wantWarns: []string{
+			"a:4:3: revision 2019-06-01 of b not found, using revision 2020-01-01",
+			"a:5:3: prefix c of imported module c is not used",
+		},
+	}, {
+		name: "promote, demote, and ignore",
+		severities: map[Code]Severity{
+			WarnRevisionNotFound: SeverityError,
+			WarnUnusedImport:     SeverityIgnore,
+			WarnNoDescription:    SeverityWarning,
+			WarnImportNoRevision: SeverityWarning,
+		},
+		wantErrs: []string{"a:4:3: revision 2019-06-01 of b not found, using revision 2020-01-01"},
+		wantWarns: []string{
+			"a:5:3: import of c has no revision-date",
+			"a:7:3: leaf l has no description",
+			"b:1:1: module b has no description",
+			"b:1:64: typedef t has no description",
+		},
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			ParseOptions.Severities = tt.severities
+			ms := NewModules()
+			for name, text := range map[string]string{"a": a, "b": b, "c": c} {
+				if err := ms.Parse(text, name); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var gotErrs, gotWarns []string
+			for _, err := range ms.Process() {
+				gotErrs = append(gotErrs, err.Error())
+			}
+			for _, w := range ms.Warnings() {
+				gotWarns = append(gotWarns, w.Error())
+			}
+			if diff := cmp.Diff(tt.wantErrs, gotErrs); diff != "" {
+				t.Errorf("errors (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantWarns, gotWarns); diff != "" {
+				t.Errorf("warnings (-want, +got):\n%s", diff)
+			}
+			ds := ms.Diagnostics()
+			if len(ds) != len(tt.wantErrs)+len(tt.wantWarns) {
+				t.Errorf("got %d diagnostics, want %d", len(ds), len(tt.wantErrs)+len(tt.wantWarns))
+			}
+			for _, d := range ds {
+				if d.Code == WarnRevisionNotFound && d.Severity != ms.severityOf(d.Code, SeverityWarning).String() {
+					t.Errorf("diagnostic %+v has the wrong severity", d)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the diagnostics about questionable but valid
+// constructs, and the adjustment of the severity of diagnostics by
+// Options.Severities.
+
+import "regexp"
+
+// defaultSeverities are the severities of the diagnostics that are not
+// reported at the severity they are created with unless the Severities
+// option says otherwise.
+var defaultSeverities = map[Code]Severity{
+	WarnImportNoRevision: SeverityIgnore,
+	WarnNoDescription:    SeverityIgnore,
+}
+
+// severityOf returns the severity that diagnostics with code, which are
+// created with severity def, are reported at by ms.
+func (ms *Modules) severityOf(code Code, def Severity) Severity {
+	if s, ok := ms.opts.Severities[code]; ok {
+		return s
+	}
+	if s, ok := defaultSeverities[code]; ok {
+		return s
+	}
+	return def
+}
+
+// enabled reports whether warnings with code are reported by ms.
+func (ms *Modules) enabled(code Code) bool {
+	return ms.severityOf(code, SeverityWarning) != SeverityIgnore
+}
+
+// adjustSeverities returns the errors and warnings of errs and ws after
+// changing their severities as given by severityOf.  Both lists are
+// sorted.
+func (ms *Modules) adjustSeverities(errs, ws []error) ([]error, []error) {
+	var nerrs, nws []error
+	for _, list := range [][]error{errs, ws} {
+		for _, err := range list {
+			e, ok := err.(*Error)
+			if !ok {
+				nerrs = append(nerrs, err)
+				continue
+			}
+			switch s := ms.severityOf(e.Code, e.Severity); s {
+			case SeverityIgnore:
+				continue
+			case e.Severity:
+			default:
+				ne := *e
+				ne.Severity = s
+				e = &ne
+			}
+			if e.Severity == SeverityWarning {
+				nws = append(nws, e)
+			} else {
+				nerrs = append(nerrs, e)
+			}
+		}
+	}
+	return errorSort(nerrs), errorSort(nws)
+}
+
+// Diagnostics returns the errors and warnings reported by the last call to
+// Process, sorted by location.
+func (ms *Modules) Diagnostics() []Diagnostic {
+	all := append(append([]error{}, ms.errors...), ms.warnings...)
+	return Diagnostics(errorSort(all))
+}
+
+// describedKeywords are the keywords of the statements that should have a
+// description.
+var describedKeywords = map[string]bool{
+	"action":       true,
+	"anydata":      true,
+	"anyxml":       true,
+	"choice":       true,
+	"container":    true,
+	"extension":    true,
+	"feature":      true,
+	"grouping":     true,
+	"identity":     true,
+	"leaf":         true,
+	"leaf-list":    true,
+	"list":         true,
+	"module":       true,
+	"notification": true,
+	"rpc":          true,
+	"submodule":    true,
+	"typedef":      true,
+}
+
+// unprefixedKeywords are the keywords of the statements whose arguments
+// are not checked for uses of prefixes, as a colon in them does not follow
+// a prefix.
+var unprefixedKeywords = map[string]bool{
+	"contact":       true,
+	"description":   true,
+	"error-message": true,
+	"namespace":     true,
+	"organization":  true,
+	"pattern":       true,
+	"reference":     true,
+}
+
+// prefixRE matches the prefixes of the prefixed names in an argument.
+var prefixRE = regexp.MustCompile(`(?:^|[^A-Za-z0-9_.-])([A-Za-z_][A-Za-z0-9_.-]*):`)
+
+// checkModules returns the warnings about the imports and descriptions of
+// the modules and submodules of ms: imports whose prefix is not used,
+// imports without a revision-date, and definitions without a description.
+func (ms *Modules) checkModules() []error {
+	var ws []error
+	unused, norev, nodesc := ms.enabled(WarnUnusedImport), ms.enabled(WarnImportNoRevision), ms.enabled(WarnNoDescription)
+	for _, m := range ms.sortedModules() {
+		if m.Source == nil {
+			continue
+		}
+		used := map[string]bool{}
+		var walk func(*Statement, string)
+		walk = func(s *Statement, path string) {
+			if path != "" {
+				path += " / "
+			}
+			path += s.Keyword + " " + s.Argument
+			if nodesc && describedKeywords[s.Keyword] && !hasSubstatement(s, "description") {
+				w := warnf(s, WarnNoDescription, "%s %s has no description", s.Keyword, s.Argument)
+				w.Path = path
+				ws = append(ws, w)
+			}
+			if p := prefixRE.FindStringSubmatch(s.Keyword); p != nil {
+				used[p[1]] = true
+			}
+			if !unprefixedKeywords[s.Keyword] {
+				for _, p := range prefixRE.FindAllStringSubmatch(s.Argument, -1) {
+					used[p[1]] = true
+				}
+			}
+			if s.Keyword == "import" {
+				return
+			}
+			for _, ss := range s.statements {
+				walk(ss, path)
+			}
+		}
+		walk(m.Source, "")
+		for _, i := range m.Import {
+			if unused && i.Prefix != nil && !used[i.Prefix.Name] {
+				ws = append(ws, warnf(i, WarnUnusedImport, "prefix %s of imported module %s is not used", i.Prefix.Name, i.Name))
+			}
+			if norev && i.RevisionDate == nil {
+				ws = append(ws, warnf(i, WarnImportNoRevision, "import of %s has no revision-date", i.Name))
+			}
+		}
+	}
+	return ws
+}
+
+// hasSubstatement reports whether s has a substatement with keyword.
+func hasSubstatement(s *Statement, keyword string) bool {
+	for _, ss := range s.statements {
+		if ss.Keyword == keyword {
+			return true
+		}
+	}
+	return false
+}
@@ -44,6 +44,32 @@
 // More complicated uses cases should use NewModules and then some combination
 // of Modules.GetModule, Modules.Read, Modules.Parse, and Modules.GetErrors.
 //
+// Each Modules has its own Options and search path.  NewModules copies them
+// from the ParseOptions and Path variables, while NewModulesWithOptions is
+// given the options and starts with an empty search path, which is extended
+// by Modules.AddPath.  Modules with different options may be used at the
+// same time:
+//
+//	ms := yang.NewModulesWithOptions(yang.Options{StrictConformance: true})
+//	ms.AddPath("models/...")
+//	e, errs := ms.GetModule("module-name")
+//
 // The GetErrors method is mandatory, however, both yang.GetModule and
 // Modules.GetModule automatically call Modules.GetErrors.
+//
+// Reading and processing a large set of modules can take a while.  The
+// ParseContext, ParseYINContext, Modules.ReadContext, Modules.ReadAllContext,
+// Modules.ParseContext, Modules.ParseReaderContext, Modules.ProcessContext,
+// Modules.GetModuleContext, and Entry.WriteJSONContext variants return
+// ctx.Err() once their context is done, so that, e.g., a server can bound
+// the time spent on a schema uploaded by a user:
+//
+//	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+//	defer cancel()
+//	if err := ms.ParseContext(ctx, source, name); err != nil {
+//		return err
+//	}
+//	if errs := ms.ProcessContext(ctx); len(errs) > 0 {
+//		return errs
+//	}
 package yang
@@ -0,0 +1,123 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements Dump, which writes a description of a Modules for
+// troubleshooting.
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Dump writes a readable description of the modules and submodules of ms
+// to w, for troubleshooting problems loading modules.  For each module it
+// lists the imports and includes, typedefs, groupings, identities, and
+// features, followed by a list of the items that could not be resolved:
+// imports and includes of modules that were not found, typedefs whose
+// types were not resolved, and augments that were not applied.  Typedefs
+// are only resolved, and augments applied, by Process.
+func (ms *Modules) Dump(w io.Writer) error {
+	dw := &dumpWriter{w: w}
+	var unresolved []string
+	for _, m := range ms.sortedModules() {
+		dw.printf("%s %s %s\n", m.Kind(), m.FullName(), Source(m))
+		if m.BelongsTo != nil {
+			dw.printf("  belongs-to %s\n", m.BelongsTo.Name)
+		}
+		if m.Namespace != nil {
+			dw.printf("  namespace %s\n", m.Namespace.Name)
+		}
+		dw.printf("  prefix %s\n", m.GetPrefix())
+		for _, i := range m.Import {
+			if i.Module == nil {
+				dw.printf("  import %s as %s: not loaded\n", i.Name, i.Prefix.Name)
+				unresolved = append(unresolved, fmt.Sprintf("%s: import %s: module not loaded", Source(i), i.Name))
+				continue
+			}
+			dw.printf("  import %s as %s: %s\n", i.Name, i.Prefix.Name, Source(i.Module))
+		}
+		for _, i := range m.Include {
+			if i.Module == nil {
+				dw.printf("  include %s: not loaded\n", i.Name)
+				unresolved = append(unresolved, fmt.Sprintf("%s: include %s: submodule not loaded", Source(i), i.Name))
+				continue
+			}
+			dw.printf("  include %s: %s\n", i.Name, Source(i.Module))
+		}
+		walkNodes(m, func(n Node) {
+			switch n := n.(type) {
+			case *Typedef:
+				if n.YangType == nil {
+					dw.printf("  typedef %s: %s unresolved %s\n", dumpName(n), n.Type.Name, Source(n))
+					unresolved = append(unresolved, fmt.Sprintf("%s: typedef %s: type %s is not resolved", Source(n), n.Name, n.Type.Name))
+					return
+				}
+				dw.printf("  typedef %s: %s (%s) %s\n", dumpName(n), n.Type.Name, n.YangType.Kind, Source(n))
+			case *Grouping:
+				dw.printf("  grouping %s %s\n", dumpName(n), Source(n))
+			}
+		})
+		for _, i := range m.Identity {
+			var bases []string
+			for _, b := range i.Base {
+				bases = append(bases, b.Name)
+			}
+			if len(bases) > 0 {
+				dw.printf("  identity %s: base %s %s\n", i.PrefixedName(), strings.Join(bases, ", "), Source(i))
+			} else {
+				dw.printf("  identity %s %s\n", i.PrefixedName(), Source(i))
+			}
+		}
+		for _, f := range m.Feature {
+			dw.printf("  feature %s %s\n", f.Name, Source(f))
+		}
+		if e := ms.entries.cache[m]; e != nil {
+			for _, a := range e.Augments {
+				unresolved = append(unresolved, fmt.Sprintf("%s: augment %s: not applied", Source(a.Node), a.Name))
+			}
+		}
+	}
+	if len(unresolved) > 0 {
+		dw.printf("unresolved\n")
+		for _, u := range unresolved {
+			dw.printf("  %s\n", u)
+		}
+	}
+	return dw.err
+}
+
+// dumpName returns the name of n, qualified by the path of the statement it
+// is defined in if it is not defined at the top level of its module.
+func dumpName(n Node) string {
+	p := n.ParentNode()
+	if _, ok := p.(*Module); ok || p == nil {
+		return n.NName()
+	}
+	return n.NName() + " in " + StatementPath(p)
+}
+
+// A dumpWriter writes to w until the first error.
+type dumpWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (dw *dumpWriter) printf(format string, v ...interface{}) {
+	if dw.err == nil {
+		_, dw.err = fmt.Fprintf(dw.w, format, v...)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDump(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"a": `module a {
+  prefix "a";
+  namespace "urn:a";
+  import b { prefix "b"; }
+  include a-sub;
+  revision 2020-01-01;
+  typedef t { type b:u; }
+  grouping g {
+    typedef inner { type string; }
+    leaf l { type inner; }
+  }
+  identity id { base b:root; }
+  feature f;
+  augment "/b:c" { leaf x { type string; } }
+  augment "/b:missing" { leaf y { type string; } }
+}`,
+		"a-sub": `submodule a-sub { belongs-to a { prefix "a"; } }`,
+		"b": `module b {
+  prefix "b";
+  namespace "urn:b";
+  typedef u { type int8; }
+  identity root;
+  container c;
+}`,
+	} {
+		if err := ms.Parse(text, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The augment of a missing node is reported by Process.
+	ms.Process()
+
+	var buf bytes.Buffer
+	if err := ms.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `submodule a-sub a-sub:1:1
+  belongs-to a
+  prefix a
+module a@2020-01-01 a:1:1
+  namespace urn:a
+  prefix a
+  import b as b: b:1:1
+  include a-sub: a-sub:1:1
+  grouping g a:8:3
+  typedef inner in module a / grouping g: string (string) a:9:5
+  typedef t: b:u (int8) a:7:3
+  identity a:id: base b:root a:12:3
+  feature f a:13:3
+module b b:1:1
+  namespace urn:b
+  prefix b
+  typedef u: int8 (int8) b:4:3
+  identity b:root b:5:3
+unresolved
+  a:15:3: augment /b:missing: not applied
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("(-want, +got):\n%s", diff)
+	}
+}
+
+func TestDumpUnprocessed(t *testing.T) {
+	// Typedefs are kept in a global dictionary and resolved by every
+	// call to Process, so this one must be valid.
+	ms := NewModules()
+	if err := ms.Parse(`module a { prefix "a"; namespace "urn:a"; import z { prefix "z"; } typedef t { type string; } }`, "a"); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := ms.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `module a a:1:1
+  namespace urn:a
+  prefix a
+  import z as z: not loaded
+  typedef t: string unresolved a:1:68
+unresolved
+  a:1:43: import z: module not loaded
+  a:1:68: typedef t: type string is not resolved
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("(-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package yang
+
+// This file implements the modules whose source is compiled into this
+// package.  An embedded module is used when a module of the same name
+// cannot be found on Path.
+
+import (
+	"sort"
+	"strings"
+)
+
+// An embeddedModule is the source of a module compiled into this package.
+type embeddedModule struct {
+	revision string // the most recent revision of the module
+	source   string
+	library  bool // only used after UseBuiltinModules is called
+}
+
+// embeddedModules contains the embedded modules, indexed by module name.
+var embeddedModules = map[string]embeddedModule{
+	"ietf-datastores":    {"2018-02-14", ietfDatastores, false},
+	"ietf-origin":        {"2018-02-14", ietfOrigin, false},
+	"ietf-yang-metadata": {"2016-08-05", ietfYangMetadata, false},
+
+	"ietf-inet-types":   {"2013-07-15", ietfInetTypes, true},
+	"ietf-restconf":     {"2017-01-26", ietfRestconf, true},
+	"ietf-yang-library": {"2019-01-04", ietfYangLibrary, true},
+	"ietf-yang-types":   {"2013-07-15", ietfYangTypes, true},
+}
+
+// UseBuiltinModules causes ms to read the standard modules embedded in this
+// package when they are not found on Path.  The embedded modules are
+// ietf-inet-types, ietf-yang-types (RFC 6991), ietf-yang-library (RFC 8525)
+// and ietf-restconf (RFC 8040), as well as ietf-datastores, ietf-origin and
+// ietf-yang-metadata, which are always available.  Modules found on Path
+// take precedence over the embedded ones.
+func (ms *Modules) UseBuiltinModules() {
+	ms.useBuiltin = true
+}
+
+// BuiltinModules returns the sorted names of all the modules embedded in
+// this package.
+func BuiltinModules() []string {
+	var names []string
+	for name := range embeddedModules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// findEmbedded returns the file name and source of the embedded module
+// named name.  Like the names passed to findFile, name may include a
+// revision (e.g., "ietf-origin@2018-02-14") and a ".yang" suffix.  If a
+// revision is given it must match the revision of the embedded module.
+// The modules of the standard library are only found if library is true.
+func findEmbedded(name string, library bool) (string, string, bool) {
+	name = strings.TrimSuffix(name, ".yang")
+	rev := ""
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, rev = name[:i], name[i+1:]
+	}
+	em, ok := embeddedModules[name]
+	if !ok || (em.library && !library) || (rev != "" && rev != em.revision) {
+		return "", "", false
+	}
+	return "embedded:" + name + "@" + em.revision + ".yang", em.source, true
+}
+
+// The embedded modules are abridged copies of the published modules:
+// descriptions, contact information and revision history have been
+// shortened or removed.
+
+// ietfDatastores is the ietf-datastores module from RFC 8342.
+const ietfDatastores = `module ietf-datastores {
+  yang-version 1.1;
+  namespace "urn:ietf:params:xml:ns:yang:ietf-datastores";
+  prefix ds;
+
+  organization "IETF Network Modeling (NETMOD) Working Group";
+  description
+    "This YANG module defines a set of identities for identifying
+     datastores.";
+
+  revision 2018-02-14 {
+    reference "RFC 8342: Network Management Datastore Architecture (NMDA)";
+  }
+
+  identity datastore {
+    description "Abstract base identity for datastore identities.";
+  }
+
+  identity conventional {
+    base datastore;
+    description "Abstract base identity for conventional configuration
+                 datastores.";
+  }
+
+  identity running {
+    base conventional;
+    description "The running configuration datastore.";
+  }
+
+  identity candidate {
+    base conventional;
+    description "The candidate configuration datastore.";
+  }
+
+  identity startup {
+    base conventional;
+    description "The startup configuration datastore.";
+  }
+
+  identity intended {
+    base conventional;
+    description "The intended configuration datastore.";
+  }
+
+  identity dynamic {
+    base datastore;
+    description "Abstract base identity for dynamic configuration
+                 datastores.";
+  }
+
+  identity operational {
+    base datastore;
+    description "The operational state datastore.";
+  }
+
+  typedef datastore-ref {
+    type identityref {
+      base datastore;
+    }
+    description "A datastore identity reference.";
+  }
+}
+`
+
+// ietfOrigin is the ietf-origin module from RFC 8342.
+const ietfOrigin = `module ietf-origin {
+  yang-version 1.1;
+  namespace "urn:ietf:params:xml:ns:yang:ietf-origin";
+  prefix or;
+
+  import ietf-yang-metadata {
+    prefix md;
+  }
+
+  organization "IETF Network Modeling (NETMOD) Working Group";
+  description
+    "This YANG module defines a set of origin identities and a metadata
+     annotation to record the origin of values stored in the operational
+     state datastore.";
+
+  revision 2018-02-14 {
+    reference "RFC 8342: Network Management Datastore Architecture (NMDA)";
+  }
+
+  identity origin {
+    description "Abstract base identity for the origin annotation.";
+  }
+
+  identity static {
+    base origin;
+    description "Denotes data from static configuration (e.g., <intended>).";
+  }
+
+  identity dynamic {
+    base origin;
+    description "Denotes data from dynamic configuration protocols or dynamic
+                 datastores (e.g., DHCP).";
+  }
+
+  identity intended {
+    base static;
+    description "Denotes configuration from the intended configuration
+                 datastore.";
+  }
+
+  identity learned {
+    base dynamic;
+    description "Denotes configuration learned from protocol interactions
+                 with other devices.";
+  }
+
+  identity system {
+    base dynamic;
+    description "Denotes configuration generated by the system itself.";
+  }
+
+  identity default {
+    base dynamic;
+    description "Denotes configuration that does not have a configured or
+                 learned value but has a default value in use.";
+  }
+
+  identity unknown {
+    base dynamic;
+    description "Denotes configuration for which the system cannot identify
+                 the origin.";
+  }
+
+  typedef origin-ref {
+    type identityref {
+      base origin;
+    }
+    description "An origin identity reference.";
+  }
+
+  md:annotation origin {
+    type origin-ref;
+    description "The 'origin' annotation can be present on any configuration
+                 data node in the operational state datastore.";
+  }
+}
+`
+
+// ietfYangMetadata is the ietf-yang-metadata module from RFC 7952.
+const ietfYangMetadata = `module ietf-yang-metadata {
+  yang-version 1.1;
+  namespace "urn:ietf:params:xml:ns:yang:ietf-yang-metadata";
+  prefix md;
+
+  organization "IETF NETMOD (NETCONF Data Modeling Language) Working Group";
+  description
+    "This YANG module defines an 'extension' statement that allows for
+     defining metadata annotations.";
+
+  revision 2016-08-05 {
+    reference "RFC 7952: Defining and Using Metadata with YANG";
+  }
+
+  extension annotation {
+    argument name;
+    description "This extension allows for defining metadata annotations in
+                 YANG modules.";
+  }
+}
+`
@@ -0,0 +1,669 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package yang
+
+// This file contains the embedded modules read after UseBuiltinModules is
+// called.  See embedded.go.
+
+// ietfYangTypes is the ietf-yang-types module from RFC 6991.
+const ietfYangTypes = `module ietf-yang-types {
+  namespace "urn:ietf:params:xml:ns:yang:ietf-yang-types";
+  prefix "yang";
+
+  organization "IETF NETMOD (NETCONF Data Modeling Language) Working Group";
+  description
+    "This module contains a collection of generally useful derived
+     YANG data types.";
+
+  revision 2013-07-15 {
+    reference "RFC 6991: Common YANG Data Types";
+  }
+
+  /*** collection of counter and gauge types ***/
+
+  typedef counter32 {
+    type uint32;
+  }
+
+  typedef zero-based-counter32 {
+    type yang:counter32;
+    default "0";
+  }
+
+  typedef counter64 {
+    type uint64;
+  }
+
+  typedef zero-based-counter64 {
+    type yang:counter64;
+    default "0";
+  }
+
+  typedef gauge32 {
+    type uint32;
+  }
+
+  typedef gauge64 {
+    type uint64;
+  }
+
+  /*** collection of identifier-related types ***/
+
+  typedef object-identifier {
+    type string {
+      pattern '(([0-1](\.[1-3]?[0-9]))|(2\.(0|([1-9]\d*))))'
+            + '(\.(0|([1-9]\d*)))*';
+    }
+  }
+
+  typedef object-identifier-128 {
+    type object-identifier {
+      pattern '\d*(\.\d*){1,127}';
+    }
+  }
+
+  typedef yang-identifier {
+    type string {
+      length "1..max";
+      pattern '[a-zA-Z_][a-zA-Z0-9\-_.]*';
+      pattern '.|..|[^xX].*|.[^mM].*|..[^lL].*';
+    }
+  }
+
+  /*** collection of types related to date and time ***/
+
+  typedef date-and-time {
+    type string {
+      pattern '\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?'
+            + '(Z|[\+\-]\d{2}:\d{2})';
+    }
+  }
+
+  typedef timeticks {
+    type uint32;
+  }
+
+  typedef timestamp {
+    type yang:timeticks;
+  }
+
+  /*** collection of generic address types ***/
+
+  typedef phys-address {
+    type string {
+      pattern '([0-9a-fA-F]{2}(:[0-9a-fA-F]{2})*)?';
+    }
+  }
+
+  typedef mac-address {
+    type string {
+      pattern '[0-9a-fA-F]{2}(:[0-9a-fA-F]{2}){5}';
+    }
+  }
+
+  /*** collection of XML-specific types ***/
+
+  typedef xpath1.0 {
+    type string;
+  }
+
+  /*** collection of string types ***/
+
+  typedef hex-string {
+    type string {
+      pattern '([0-9a-fA-F]{2}(:[0-9a-fA-F]{2})*)?';
+    }
+  }
+
+  typedef uuid {
+    type string {
+      pattern '[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-'
+            + '[0-9a-fA-F]{4}-[0-9a-fA-F]{12}';
+    }
+  }
+
+  typedef dotted-quad {
+    type string {
+      pattern
+        '(([0-9]|[1-9][0-9]|1[0-9][0-9]|2[0-4][0-9]|25[0-5])\.){3}'
+      + '([0-9]|[1-9][0-9]|1[0-9][0-9]|2[0-4][0-9]|25[0-5])';
+    }
+  }
+}
+`
+
+// ietfInetTypes is the ietf-inet-types module from RFC 6991.
+const ietfInetTypes = `module ietf-inet-types {
+  namespace "urn:ietf:params:xml:ns:yang:ietf-inet-types";
+  prefix "inet";
+
+  organization "IETF NETMOD (NETCONF Data Modeling Language) Working Group";
+  description
+    "This module contains a collection of generally useful derived
+     YANG data types for Internet addresses and related things.";
+
+  revision 2013-07-15 {
+    reference "RFC 6991: Common YANG Data Types";
+  }
+
+  /*** collection of types related to protocol fields ***/
+
+  typedef ip-version {
+    type enumeration {
+      enum unknown {
+        value "0";
+      }
+      enum ipv4 {
+        value "1";
+      }
+      enum ipv6 {
+        value "2";
+      }
+    }
+  }
+
+  typedef dscp {
+    type uint8 {
+      range "0..63";
+    }
+  }
+
+  typedef ipv6-flow-label {
+    type uint32 {
+      range "0..1048575";
+    }
+  }
+
+  typedef port-number {
+    type uint16 {
+      range "0..65535";
+    }
+  }
+
+  /*** collection of types related to autonomous systems ***/
+
+  typedef as-number {
+    type uint32;
+  }
+
+  /*** collection of types related to IP addresses and hostnames ***/
+
+  typedef ip-address {
+    type union {
+      type inet:ipv4-address;
+      type inet:ipv6-address;
+    }
+  }
+
+  typedef ipv4-address {
+    type string {
+      pattern
+        '(([0-9]|[1-9][0-9]|1[0-9][0-9]|2[0-4][0-9]|25[0-5])\.){3}'
+      +  '([0-9]|[1-9][0-9]|1[0-9][0-9]|2[0-4][0-9]|25[0-5])'
+      + '(%[\p{N}\p{L}]+)?';
+    }
+  }
+
+  typedef ipv6-address {
+    type string {
+      pattern '((:|[0-9a-fA-F]{0,4}):)([0-9a-fA-F]{0,4}:){0,5}'
+            + '((([0-9a-fA-F]{0,4}:)?(:|[0-9a-fA-F]{0,4}))|'
+            + '(((25[0-5]|2[0-4][0-9]|[01]?[0-9]?[0-9])\.){3}'
+            + '(25[0-5]|2[0-4][0-9]|[01]?[0-9]?[0-9])))'
+            + '(%[\p{N}\p{L}]+)?';
+      pattern '(([^:]+:){6}(([^:]+:[^:]+)|(.*\..*)))|'
+            + '((([^:]+:)*[^:]+)?::(([^:]+:)*[^:]+)?)'
+            + '(%.+)?';
+    }
+  }
+
+  typedef ip-address-no-zone {
+    type union {
+      type inet:ipv4-address-no-zone;
+      type inet:ipv6-address-no-zone;
+    }
+  }
+
+  typedef ipv4-address-no-zone {
+    type inet:ipv4-address {
+      pattern '[0-9\.]*';
+    }
+  }
+
+  typedef ipv6-address-no-zone {
+    type inet:ipv6-address {
+      pattern '[0-9a-fA-F:\.]*';
+    }
+  }
+
+  typedef ip-prefix {
+    type union {
+      type inet:ipv4-prefix;
+      type inet:ipv6-prefix;
+    }
+  }
+
+  typedef ipv4-prefix {
+    type string {
+      pattern
+         '(([0-9]|[1-9][0-9]|1[0-9][0-9]|2[0-4][0-9]|25[0-5])\.){3}'
+       +  '([0-9]|[1-9][0-9]|1[0-9][0-9]|2[0-4][0-9]|25[0-5])'
+       + '/(([0-9])|([1-2][0-9])|(3[0-2]))';
+    }
+  }
+
+  typedef ipv6-prefix {
+    type string {
+      pattern '((:|[0-9a-fA-F]{0,4}):)([0-9a-fA-F]{0,4}:){0,5}'
+            + '((([0-9a-fA-F]{0,4}:)?(:|[0-9a-fA-F]{0,4}))|'
+            + '(((25[0-5]|2[0-4][0-9]|[01]?[0-9]?[0-9])\.){3}'
+            + '(25[0-5]|2[0-4][0-9]|[01]?[0-9]?[0-9])))'
+            + '(/(([0-9])|([0-9]{2})|(1[0-1][0-9])|(12[0-8])))';
+      pattern '(([^:]+:){6}(([^:]+:[^:]+)|(.*\..*)))|'
+            + '((([^:]+:)*[^:]+)?::(([^:]+:)*[^:]+)?)'
+            + '(/.+)';
+    }
+  }
+
+  /*** collection of domain name and URI types ***/
+
+  typedef domain-name {
+    type string {
+      length "1..253";
+      pattern
+        '((([a-zA-Z0-9_]([a-zA-Z0-9\-_]){0,61})?[a-zA-Z0-9]\.)*'
+      + '([a-zA-Z0-9_]([a-zA-Z0-9\-_]){0,61})?[a-zA-Z0-9]\.?)'
+      + '|\.';
+    }
+  }
+
+  typedef host {
+    type union {
+      type inet:ip-address;
+      type inet:domain-name;
+    }
+  }
+
+  typedef uri {
+    type string;
+  }
+}
+`
+
+// ietfYangLibrary is the ietf-yang-library module from RFC 8525.
+const ietfYangLibrary = `module ietf-yang-library {
+  yang-version 1.1;
+  namespace "urn:ietf:params:xml:ns:yang:ietf-yang-library";
+  prefix yanglib;
+
+  import ietf-yang-types {
+    prefix yang;
+  }
+  import ietf-inet-types {
+    prefix inet;
+  }
+  import ietf-datastores {
+    prefix ds;
+  }
+
+  organization "IETF NETCONF (Network Configuration) Working Group";
+  description
+    "This module provides information about the YANG modules,
+     datastores, and datastore schemas used by a network management
+     server.";
+
+  revision 2019-01-04 {
+    reference "RFC 8525: YANG Library";
+  }
+
+  /*
+   * Typedefs
+   */
+
+  typedef revision-identifier {
+    type string {
+      pattern '\d{4}-\d{2}-\d{2}';
+    }
+  }
+
+  /*
+   * Groupings
+   */
+
+  grouping module-identification-leafs {
+    leaf name {
+      type yang:yang-identifier;
+      mandatory true;
+    }
+    leaf revision {
+      type revision-identifier;
+    }
+  }
+
+  grouping location-leaf-list {
+    leaf-list location {
+      type inet:uri;
+    }
+  }
+
+  grouping module-implementation-parameters {
+    leaf-list feature {
+      type yang:yang-identifier;
+    }
+    leaf-list deviation {
+      type leafref {
+        path "../../module/name";
+      }
+    }
+  }
+
+  grouping module-set-parameters {
+    leaf name {
+      type string;
+    }
+    list module {
+      key "name";
+      uses module-identification-leafs;
+      leaf namespace {
+        type inet:uri;
+        mandatory true;
+      }
+      uses location-leaf-list;
+      list submodule {
+        key "name";
+        uses module-identification-leafs;
+        uses location-leaf-list;
+      }
+      uses module-implementation-parameters;
+    }
+    list import-only-module {
+      key "name revision";
+      leaf name {
+        type yang:yang-identifier;
+      }
+      leaf revision {
+        type union {
+          type revision-identifier;
+          type string {
+            length "0";
+          }
+        }
+      }
+      leaf namespace {
+        type inet:uri;
+        mandatory true;
+      }
+      uses location-leaf-list;
+      list submodule {
+        key "name";
+        uses module-identification-leafs;
+        uses location-leaf-list;
+      }
+    }
+  }
+
+  grouping yang-library-parameters {
+    list module-set {
+      key "name";
+      uses module-set-parameters;
+    }
+    list schema {
+      key "name";
+      leaf name {
+        type string;
+      }
+      leaf-list module-set {
+        type leafref {
+          path "../../module-set/name";
+        }
+      }
+    }
+    list datastore {
+      key "name";
+      leaf name {
+        type ds:datastore-ref;
+      }
+      leaf schema {
+        type leafref {
+          path "../../schema/name";
+        }
+        mandatory true;
+      }
+    }
+  }
+
+  /*
+   * Top-level container
+   */
+
+  container yang-library {
+    config false;
+    uses yang-library-parameters;
+    leaf content-id {
+      type string;
+      mandatory true;
+    }
+  }
+
+  /*
+   * Notifications
+   */
+
+  notification yang-library-update {
+    leaf content-id {
+      type leafref {
+        path "/yanglib:yang-library/yanglib:content-id";
+      }
+      mandatory true;
+    }
+  }
+
+  /*
+   * Legacy groupings
+   */
+
+  grouping module-list {
+    status deprecated;
+    grouping common-leafs {
+      status deprecated;
+      leaf name {
+        type yang:yang-identifier;
+        status deprecated;
+      }
+      leaf revision {
+        type union {
+          type revision-identifier;
+          type string {
+            length "0";
+          }
+        }
+        status deprecated;
+      }
+    }
+
+    grouping schema-leaf {
+      status deprecated;
+      leaf schema {
+        type inet:uri;
+        status deprecated;
+      }
+    }
+
+    list module {
+      key "name revision";
+      status deprecated;
+      uses common-leafs {
+        status deprecated;
+      }
+      uses schema-leaf {
+        status deprecated;
+      }
+      leaf namespace {
+        type inet:uri;
+        mandatory true;
+        status deprecated;
+      }
+      leaf-list feature {
+        type yang:yang-identifier;
+        status deprecated;
+      }
+      list deviation {
+        key "name revision";
+        status deprecated;
+        uses common-leafs {
+          status deprecated;
+        }
+      }
+      leaf conformance-type {
+        type enumeration {
+          enum implement;
+          enum import;
+        }
+        mandatory true;
+        status deprecated;
+      }
+      list submodule {
+        key "name revision";
+        status deprecated;
+        uses common-leafs {
+          status deprecated;
+        }
+        uses schema-leaf {
+          status deprecated;
+        }
+      }
+    }
+  }
+
+  /*
+   * Legacy operational state data nodes
+   */
+
+  container modules-state {
+    config false;
+    status deprecated;
+    leaf module-set-id {
+      type string;
+      mandatory true;
+      status deprecated;
+    }
+    uses module-list {
+      status deprecated;
+    }
+  }
+
+  /*
+   * Legacy notifications
+   */
+
+  notification yang-library-change {
+    status deprecated;
+    leaf module-set-id {
+      type leafref {
+        path "/yanglib:modules-state/yanglib:module-set-id";
+      }
+      mandatory true;
+      status deprecated;
+    }
+  }
+}
+`
+
+// ietfRestconf is the ietf-restconf module from RFC 8040.
+const ietfRestconf = `module ietf-restconf {
+  yang-version 1.1;
+  namespace "urn:ietf:params:xml:ns:yang:ietf-restconf";
+  prefix "rc";
+
+  organization "IETF NETCONF (Network Configuration) Working Group";
+  description
+    "This module contains conceptual YANG specifications for basic
+     RESTCONF media type definitions used in RESTCONF protocol
+     messages.";
+
+  revision 2017-01-26 {
+    reference "RFC 8040: RESTCONF Protocol.";
+  }
+
+  extension yang-data {
+    argument name {
+      yin-element true;
+    }
+    description
+      "This extension is used to specify a YANG data template that
+       represents conceptual data defined in YANG.";
+  }
+
+  rc:yang-data yang-errors {
+    uses errors;
+  }
+
+  rc:yang-data yang-api {
+    uses restconf;
+  }
+
+  grouping errors {
+    description "A grouping that contains a YANG container representing
+                 the syntax and semantics of a YANG Patch error report
+                 within a response message.";
+
+    container errors {
+      list error {
+        leaf error-type {
+          type enumeration {
+            enum transport;
+            enum rpc;
+            enum protocol;
+            enum application;
+          }
+          mandatory true;
+        }
+        leaf error-tag {
+          type string;
+          mandatory true;
+        }
+        leaf error-app-tag {
+          type string;
+        }
+        leaf error-path {
+          type instance-identifier;
+        }
+        leaf error-message {
+          type string;
+        }
+        anydata error-info;
+      }
+    }
+  }
+
+  grouping restconf {
+    description "Conceptual grouping representing the RESTCONF root
+                 resource.";
+
+    container restconf {
+      container data {
+      }
+      container operations {
+      }
+      leaf yang-library-version {
+        type string {
+          pattern '\d{4}-\d{2}-\d{2}';
+        }
+        config false;
+        mandatory true;
+      }
+    }
+  }
+}
+`
@@ -0,0 +1,135 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindEmbedded(t *testing.T) {
+	for _, tt := range []struct {
+		in      string
+		library bool
+		want    string
+	}{
+		{"ietf-origin", false, "embedded:ietf-origin@2018-02-14.yang"},
+		{"ietf-origin.yang", false, "embedded:ietf-origin@2018-02-14.yang"},
+		{"ietf-datastores@2018-02-14", false, "embedded:ietf-datastores@2018-02-14.yang"},
+		{"ietf-datastores@2017-01-01", false, ""},
+		{"ietf-interfaces", false, ""},
+		{"dir/ietf-origin.yang", false, ""},
+		{"ietf-inet-types", false, ""},
+		{"ietf-inet-types", true, "embedded:ietf-inet-types@2013-07-15.yang"},
+		{"ietf-yang-library@2019-01-04", true, "embedded:ietf-yang-library@2019-01-04.yang"},
+		{"ietf-origin", true, "embedded:ietf-origin@2018-02-14.yang"},
+	} {
+		got, _, ok := findEmbedded(tt.in, tt.library)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("findEmbedded(%q, %v): got %q, %v, want %q", tt.in, tt.library, got, ok, tt.want)
+		}
+	}
+}
+
+const builtinUse = `
+module builtin-use {
+	prefix "b";
+	namespace "urn:b";
+	import ietf-inet-types { prefix "inet"; }
+	import ietf-yang-types { prefix "yang"; }
+	import ietf-yang-library { prefix "yanglib"; }
+	import ietf-restconf { prefix "rc"; }
+	leaf address { type inet:ip-address; }
+	leaf mac { type yang:mac-address; }
+	leaf when { type yang:date-and-time; }
+}
+`
+
+func TestUseBuiltinModules(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(builtinUse, "builtin-use.yang"); err != nil {
+		t.Fatal(err)
+	}
+	errs := ms.Process()
+	if len(errs) == 0 || !strings.Contains(errs[0].Error(), "no such module: ietf-inet-types") {
+		t.Errorf("without UseBuiltinModules: got %v, want no such module: ietf-inet-types", errs)
+	}
+
+	ms = NewModules()
+	ms.UseBuiltinModules()
+	if err := ms.Parse(builtinUse, "builtin-use.yang"); err != nil {
+		t.Fatal(err)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	// ietf-datastores is imported by ietf-yang-library.
+	for _, name := range []string{"ietf-inet-types", "ietf-yang-types", "ietf-yang-library", "ietf-restconf", "ietf-datastores"} {
+		m := ms.Modules[name]
+		if m == nil {
+			t.Errorf("%s not read", name)
+			continue
+		}
+		if got := m.Current(); got != embeddedModules[name].revision {
+			t.Errorf("%s: got revision %s, want %s", name, got, embeddedModules[name].revision)
+		}
+	}
+
+	e := ToEntry(ms.Modules["builtin-use"])
+	for _, tt := range []struct {
+		leaf  string
+		value string
+		want  bool
+	}{
+		{"address", "192.0.2.1", true},
+		{"address", "2001:db8::1", true},
+		{"address", "192.0.2.256", false},
+		{"mac", "00:11:22:33:44:55", true},
+		{"mac", "00:11:22:33:44", false},
+		{"when", "2020-06-01T12:00:00Z", true},
+		{"when", "2020-06-01", false},
+	} {
+		match := false
+		for _, y := range flattenUnion(e.Dir[tt.leaf].Type) {
+			p, err := y.CompiledPatterns()
+			if err != nil {
+				t.Fatalf("%s: %v", tt.leaf, err)
+			}
+			if p.MatchString(tt.value) {
+				match = true
+			}
+		}
+		if match != tt.want {
+			t.Errorf("%s %q: got match %v, want %v", tt.leaf, tt.value, match, tt.want)
+		}
+	}
+
+	yl := ToEntry(ms.Modules["ietf-yang-library"]).Dir["yang-library"]
+	if yl == nil || yl.Dir["datastore"] == nil || yl.Dir["module-set"].Dir["module"] == nil {
+		t.Errorf("ietf-yang-library: yang-library container not built")
+	}
+}
+
+// flattenUnion returns the member types of y if it is a union, or y itself.
+func flattenUnion(y *YangType) []*YangType {
+	if len(y.Type) == 0 {
+		return []*YangType{y}
+	}
+	var ys []*YangType
+	for _, t := range y.Type {
+		ys = append(ys, flattenUnion(t)...)
+	}
+	return ys
+}
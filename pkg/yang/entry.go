@@ -22,7 +22,6 @@ package yang
 // TODO(borman): handle types, leafrefs, and extensions
 
 import (
-	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -82,6 +81,7 @@ type Entry struct {
 	Default     string    `json:",omitempty"` // default from node, if any
 	Units       string    `json:",omitempty"` // units associated with the type, if any
 	Errors      []error   `json:"-"`          // list of errors encountered on this node
+	Warnings    []error   `json:"-"`          // list of warnings encountered on this node
 	Kind        EntryKind // kind of Entry
 	Config      TriState  // config state of this entry, if known
 	Prefix      *Value    `json:",omitempty"` // prefix to use from this point down
@@ -113,14 +113,55 @@ type Entry struct {
 	deviatePresence deviationPresence
 	Uses            []*UsesStmt `json:",omitempty"` // Uses merged into this entry.
 
+	// Structures are the sx:structure data structures defined in this
+	// entry, this is set if the Entry is a module only.
+	Structures map[string]*Entry `json:",omitempty"`
+
+	// TelemetryAtomic is set if the node has the oc-ext:telemetry-atomic
+	// extension, i.e., the node and its descendants are updated atomically.
+	TelemetryAtomic bool `json:",omitempty"`
+	// Operational is set if the node has the oc-ext:operational extension,
+	// i.e., the node reflects operational state even though it is config.
+	Operational bool `json:",omitempty"`
+
+	// DefaultDenyWrite is set if the node has the
+	// nacm:default-deny-write extension, i.e., by default only
+	// privileged users may write the node.
+	DefaultDenyWrite bool `json:",omitempty"`
+	// DefaultDenyAll is set if the node has the nacm:default-deny-all
+	// extension, i.e., by default only privileged users may read, write,
+	// or execute the node.
+	DefaultDenyAll bool `json:",omitempty"`
+
+	// SMIv2 is set if the node, or the typedefs of its type, have any
+	// of the ietf-yang-smiv2 extensions.
+	SMIv2 *SMIv2 `json:",omitempty"`
+
 	// Extra maps all the unsupported fields to their values
 	Extra map[string][]interface{} `json:"-"`
 
+	// When and Must are the when and must statements of the node.  For
+	// compatibility, Extra also holds the statements as they appear in
+	// the node.
+	When *Condition   `json:",omitempty"`
+	Must []*Condition `json:",omitempty"`
+
 	// Annotation stores annotated values, and is not populated by this
 	// library but rather can be used by calling code where additional
 	// information should be stored alongside the Entry.
 	Annotation map[string]interface{} `json:",omitempty"`
 
+	// usedAt are the uses statements that instantiated this Entry from
+	// a grouping, innermost first.
+	usedAt []*Uses
+
+	// deviatedBy are the deviations that modified this Entry, in the
+	// order they were applied.
+	deviatedBy []*AppliedDeviation
+
+	// augmentedBy is the augment that added this Entry to its parent.
+	augmentedBy *Augment
+
 	// namespace stores the namespace of the Entry if it overrides the
 	// root namespace within the schema tree. This is the case where an
 	// entry is augmented into the tree, and it retains the namespace of
@@ -202,6 +243,28 @@ func (e *Entry) IsCase() bool {
 	return e.Kind == CaseEntry
 }
 
+// IsNotification returns true if the entry is a notification, either at the
+// top level of a module or, in YANG 1.1, within a container or list.
+func (e *Entry) IsNotification() bool {
+	return e.Kind == NotificationEntry
+}
+
+// Notifications returns the notifications defined by e and its
+// descendants, including those within containers and lists, depth first
+// with the children of each entry in name order.  The Path of each notification is its position in the
+// schema tree, e.g., "/m/interfaces/interface/link-down".
+func (e *Entry) Notifications() []*Entry {
+	var ns []*Entry
+	walkEntries(e, func(ce *Entry) bool {
+		if ce.IsNotification() {
+			ns = append(ns, ce)
+			return false
+		}
+		return true
+	})
+	return ns
+}
+
 // Print prints e to w in human readable form.
 func (e *Entry) Print(w io.Writer) {
 	if e.Description != "" {
@@ -217,6 +280,10 @@ func (e *Entry) Print(w io.Writer) {
 		fmt.Fprintf(w, "%s ", e.Type.Name)
 	}
 	switch {
+	case e.Kind == AnyDataEntry || e.Kind == AnyXMLEntry:
+		// The contents of anydata and anyxml nodes are not modeled.
+		fmt.Fprintf(w, "%s %s\n", e.Node.Kind(), e.Name)
+		return
 	case e.Dir == nil && e.ListAttr != nil:
 		fmt.Fprintf(w, "[]%s\n", e.Name)
 		return
@@ -303,16 +370,16 @@ func newLeaf(n Node) *Entry {
 
 // newError returns an error node using format and v to create the error
 // contained in the node.  The location of the error is prepended.
-func newError(n Node, format string, v ...interface{}) *Entry {
+func newError(n Node, code Code, format string, v ...interface{}) *Entry {
 	e := &Entry{Node: n}
-	e.errorf("%s: "+format, append([]interface{}{Source(n)}, v...)...)
+	e.errorf(n, code, format, v...)
 	return e
 }
 
-// errorf appends the entry constructed from string and v to the list of errors
-// on e.
-func (e *Entry) errorf(format string, v ...interface{}) {
-	e.Errors = append(e.Errors, fmt.Errorf(format, v...))
+// errorf appends the error with code about n constructed from format and v
+// to the list of errors on e.
+func (e *Entry) errorf(n Node, code Code, format string, v ...interface{}) {
+	e.Errors = append(e.Errors, errorf(n, code, format, v...))
 }
 
 // addError appends err to the list of errors on e if err is not nil.
@@ -322,7 +389,13 @@ func (e *Entry) addError(err error) {
 	}
 }
 
-// importErrors imports all the errors from c and its children into e.
+// addWarning appends the warning w to e's list of warnings.
+func (e *Entry) addWarning(w *Error) {
+	e.Warnings = append(e.Warnings, w)
+}
+
+// importErrors imports all the errors and warnings from c and its children
+// into e.
 func (e *Entry) importErrors(c *Entry) {
 	if c == nil {
 		return
@@ -330,6 +403,7 @@ func (e *Entry) importErrors(c *Entry) {
 	for _, err := range c.Errors {
 		e.addError(err)
 	}
+	e.Warnings = append(e.Warnings, c.Warnings...)
 	// TODO(borman): need to determine if the extensions have errors
 	// for _, ce := range e.Exts {
 	// 	e.importErrors(ce)
@@ -373,11 +447,39 @@ func (e *Entry) GetErrors() []error {
 	return errorSort(errs)
 }
 
+// checkWarnings calls f on every warning found in the tree e and its
+// children.
+func (e *Entry) checkWarnings(f func(error)) {
+	if e == nil {
+		return
+	}
+	for _, e := range e.Dir {
+		e.checkWarnings(f)
+	}
+	for _, w := range e.Warnings {
+		f(w)
+	}
+}
+
+// GetWarnings returns a sorted list of warnings found in e.  Warnings do
+// not prevent e from being used.
+func (e *Entry) GetWarnings() []error {
+	seen := map[error]bool{}
+	var ws []error
+	e.checkWarnings(func(w error) {
+		if !seen[w] {
+			ws = append(ws, w)
+			seen[w] = true
+		}
+	})
+	return errorSort(ws)
+}
+
 // add adds the directory entry key assigned to the provided value.
 func (e *Entry) add(key string, value *Entry) *Entry {
 	value.Parent = e
 	if e.Dir[key] != nil {
-		e.errorf("%s: duplicate key from %s: %s", Source(e.Node), Source(value.Node), key)
+		e.errorf(e.Node, ErrDuplicateNode, "duplicate key from %s: %s", Source(value.Node), key)
 		return e
 	}
 	e.Dir[key] = value
@@ -387,7 +489,7 @@ func (e *Entry) add(key string, value *Entry) *Entry {
 // delete removes the directory entry key from the entry.
 func (e *Entry) delete(key string) {
 	if _, ok := e.Dir[key]; !ok {
-		e.errorf("%s: unknown child key %s", Source(e.Node), key)
+		e.errorf(e.Node, ErrInternal, "unknown child key %s", key)
 	}
 	delete(e.Dir, key)
 }
@@ -435,15 +537,52 @@ func (e *Entry) GetWhenXPath() (string, bool) {
 	return "", false
 }
 
-// entryCache is used to prevent unnecessary recursion into previously
-// converted nodes.
-var entryCache = map[Node]*Entry{}
+// An entryState is the state kept by ToEntry while converting the nodes of
+// a Modules into Entry trees.  Each Modules has its own entryState, which is
+// reset by Process.
+type entryState struct {
+	// cache is used to prevent unnecessary recursion into previously
+	// converted nodes.
+	cache map[Node]*Entry
+
+	// mergedSubmodule is used to prevent re-parsing a submodule that has
+	// already been merged into a particular entity when circular
+	// dependencies are being ignored. The keys of the map are a string
+	// that is formed by concatenating the name of the including
+	// (sub)module and the included submodule.
+	mergedSubmodule map[string]bool
+
+	// groupingsInUse contains the groupings that ToEntry is currently
+	// expanding.  It is used to detect groupings that use themselves and
+	// to limit the nesting of uses statements.
+	groupingsInUse map[*Grouping]bool
+}
 
-// mergedSubmodule is used to prevent re-parsing a submodule that has already
-// been merged into a particular entity when circular dependencies are being
-// ignored. The keys of the map are a string that is formed by concatenating
-// the name of the including (sub)module and the included submodule.
-var mergedSubmodule = map[string]bool{}
+// newEntryState returns a new, empty, entryState.
+func newEntryState() *entryState {
+	return &entryState{
+		cache:           map[Node]*Entry{},
+		mergedSubmodule: map[string]bool{},
+		groupingsInUse:  map[*Grouping]bool{},
+	}
+}
+
+// entryStateOf returns the entryState of the Modules that n was read into.
+// Nodes of a module that is not part of a Modules, e.g., one built directly
+// by BuildAST, use the state of their module.  Nodes that are not in a
+// module at all get a new state for each call.
+func entryStateOf(n Node) *entryState {
+	m := RootNode(n)
+	switch {
+	case m == nil:
+		return newEntryState()
+	case m.modules != nil && m.modules.entries != nil:
+		return m.modules.entries
+	case m.entries == nil:
+		m.entries = newEntryState()
+	}
+	return m.entries
+}
 
 // deviationType specifies an enumerated value covering the different substatements
 // to the deviate statement.
@@ -494,6 +633,125 @@ type DeviatedEntry struct {
 	*Entry
 }
 
+// A typedDeviate is the Entry of a deviate statement and its type.
+type typedDeviate struct {
+	Type  deviationType
+	Entry *Entry
+}
+
+// deviates returns the deviate entries of d in the order that their
+// deviate statements appear in the deviation statement, so that they are
+// applied in the same order each time.  Entries that are not built from
+// a deviate statement of d follow, ordered by type.
+func (d *DeviatedEntry) deviates() []typedDeviate {
+	var dts []deviationType
+	for dt := range d.Deviate {
+		dts = append(dts, dt)
+	}
+	sort.Slice(dts, func(i, j int) bool { return dts[i] < dts[j] })
+	var tds []typedDeviate
+	for _, dt := range dts {
+		for _, de := range d.Deviate[dt] {
+			tds = append(tds, typedDeviate{Type: dt, Entry: de})
+		}
+	}
+
+	index := map[Node]int{}
+	if dv, ok := d.Node.(*Deviation); ok {
+		for i, sd := range dv.Deviate {
+			index[sd] = i + 1
+		}
+	}
+	order := func(td typedDeviate) int {
+		if i, ok := index[td.Entry.Node]; ok {
+			return i
+		}
+		return len(index) + 1
+	}
+	sort.SliceStable(tds, func(i, j int) bool { return order(tds[i]) < order(tds[j]) })
+	return tds
+}
+
+// An AppliedDeviation records a deviate statement that modified an Entry.
+type AppliedDeviation struct {
+	Module    *Module       // Module is the module containing the deviation.
+	Deviation *Deviation    // Deviation is the deviation statement.
+	Deviate   *Deviate      // Deviate is the deviate statement that was applied.
+	Type      deviationType // Type specifies the deviation type.
+	// Changes describes each property of the Entry that the deviate
+	// statement changed, e.g., "type string -> int32" or
+	// "max-elements unbounded -> 10".
+	Changes []string
+}
+
+// deviationState holds the properties of an Entry that deviate statements
+// change.
+type deviationState struct {
+	config, mandatory TriState
+	dflt, units       string
+	min, max          uint64
+	typ               *YangType
+}
+
+// deviationState returns the current deviationState of e.
+func (e *Entry) deviationState() deviationState {
+	s := deviationState{
+		config:    e.Config,
+		mandatory: e.Mandatory,
+		dflt:      e.Default,
+		units:     e.Units,
+		typ:       e.Type,
+	}
+	if e.ListAttr != nil {
+		s.min, s.max = e.ListAttr.MinElements, e.ListAttr.MaxElements
+	}
+	return s
+}
+
+// changes returns descriptions of the differences between s and t, the
+// state following s.
+func (s deviationState) changes(t deviationState) []string {
+	var cs []string
+	change := func(what, from, to string) {
+		if from != to {
+			cs = append(cs, fmt.Sprintf("%s %s -> %s", what, from, to))
+		}
+	}
+	count := func(n uint64) string {
+		if n == math.MaxUint64 {
+			return "unbounded"
+		}
+		return strconv.FormatUint(n, 10)
+	}
+	typeName := func(y *YangType) string {
+		if y == nil {
+			return "none"
+		}
+		return y.Name
+	}
+	quote := func(s string) string { return strconv.Quote(s) }
+	change("config", s.config.String(), t.config.String())
+	change("mandatory", s.mandatory.String(), t.mandatory.String())
+	change("default", quote(s.dflt), quote(t.dflt))
+	change("units", quote(s.units), quote(t.units))
+	change("min-elements", count(s.min), count(t.min))
+	change("max-elements", count(s.max), count(t.max))
+	if s.typ != t.typ {
+		cs = append(cs, fmt.Sprintf("type %s -> %s", typeName(s.typ), typeName(t.typ)))
+	}
+	return cs
+}
+
+// DeviatedBy returns the deviations that modified e, in the order they
+// were applied.  The entry of a node removed by a not-supported deviation
+// records the deviation, as does the entry of its parent.
+func (e *Entry) DeviatedBy() []*AppliedDeviation {
+	if e == nil {
+		return nil
+	}
+	return e.deviatedBy
+}
+
 // semCheckMaxElements checks whether the max-element argument is valid, and returns the specified value.
 func semCheckMaxElements(v *Value) (uint64, error) {
 	if v == nil || v.Name == "unbounded" {
@@ -501,10 +759,10 @@ func semCheckMaxElements(v *Value) (uint64, error) {
 	}
 	val, err := strconv.ParseUint(v.Name, 10, 64)
 	if err != nil {
-		return val, fmt.Errorf(`%s: invalid max-elements value %q (expect "unbounded" or a positive integer): %v`, Source(v), v.Name, err)
+		return val, errorf(v, ErrInvalidArgument, `invalid max-elements value %q (expect "unbounded" or a positive integer): %v`, v.Name, err)
 	}
 	if val == 0 {
-		return val, fmt.Errorf(`%s: invalid max-elements value 0 (expect "unbounded" or a positive integer)`, Source(v))
+		return val, errorf(v, ErrInvalidArgument, `invalid max-elements value 0 (expect "unbounded" or a positive integer)`)
 	}
 	return val, nil
 }
@@ -516,7 +774,7 @@ func semCheckMinElements(v *Value) (uint64, error) {
 	}
 	val, err := strconv.ParseUint(v.Name, 10, 64)
 	if err != nil {
-		return val, fmt.Errorf(`%s: invalid min-elements value %q (expect a non-negative integer): %v`, Source(v), v.Name, err)
+		return val, errorf(v, ErrInvalidArgument, `invalid min-elements value %q (expect a non-negative integer): %v`, v.Name, err)
 	}
 	return val, nil
 }
@@ -529,23 +787,32 @@ func semCheckMinElements(v *Value) (uint64, error) {
 // if there were any errors.
 func ToEntry(n Node) (e *Entry) {
 	if n == nil {
-		err := errors.New("ToEntry called with nil")
+		err := errorf(nil, ErrInternal, "ToEntry called with nil")
 		return &Entry{
 			Node:   &ErrorNode{Error: err},
 			Errors: []error{err},
 		}
 	}
-	if e := entryCache[n]; e != nil {
+	es := entryStateOf(n)
+	if e := es.cache[n]; e != nil {
 		return e
 	}
 	defer func() {
-		entryCache[n] = e
+		es.cache[n] = e
 	}()
+	if g, ok := n.(*Grouping); ok {
+		es.groupingsInUse[g] = true
+		defer delete(es.groupingsInUse, g)
+	}
 
 	// Copy in the extensions from our Node, if any.
 	defer func(n Node) {
 		if e != nil {
+			e.When, e.Must = NodeConditions(n)
 			e.Exts = append(e.Exts, n.Exts()...)
+			e.setOpenConfigExtensions(n)
+			e.setNACMExtensions(n)
+			e.setSMIv2Extensions(n)
 		}
 	}(n)
 
@@ -561,7 +828,7 @@ func ToEntry(n Node) (e *Entry) {
 			case "false":
 				return TSFalse, nil
 			default:
-				return TSUnset, fmt.Errorf("%s: invalid config value: %s", Source(n), v.Name)
+				return TSUnset, errorf(n, ErrInvalidArgument, "invalid config value: %s", v.Name)
 			}
 		}
 		return TSUnset, nil
@@ -582,7 +849,7 @@ func ToEntry(n Node) (e *Entry) {
 			e.Default = s.Default.Name
 		}
 		e.Type = s.Type.YangType
-		entryCache[n] = e
+		es.cache[n] = e
 		e.Config, err = tristateValue(s.Config)
 		e.addError(err)
 		e.Prefix = getRootPrefix(e)
@@ -625,12 +892,22 @@ func ToEntry(n Node) (e *Entry) {
 	case *Uses:
 		g := FindGrouping(s, s.Name, map[string]bool{})
 		if g == nil {
-			return newError(n, "unknown group: %s", s.Name)
+			return newError(n, ErrUnknownGrouping, "unknown group: %s%s", s.Name, didYouMean(s.Name, groupingNames(s, s.Name)))
+		}
+		if es.groupingsInUse[g] {
+			return newError(n, ErrRecursiveGrouping, "grouping %s uses itself", s.Name)
+		}
+		if max := optionsOf(s).MaxUsesDepth; max > 0 && len(es.groupingsInUse) >= max {
+			return newError(n, ErrLimitExceeded, "uses of %s nested more than %d deep", s.Name, max)
 		}
+		tracef(s, "expanding grouping %s at %s", s.Name, Source(g))
 		// We need to return a duplicate so we resolve properly
 		// when the group is used in multiple locations and the
 		// grouping has a leafref that references outside the group.
 		e := ToEntry(g).dup()
+		for _, ce := range e.Dir {
+			ce.addUsedAt(s)
+		}
 		addExtraKeywordsToLeafEntry(n, e)
 		return e
 	}
@@ -689,7 +966,7 @@ func ToEntry(n Node) (e *Entry) {
 		name := strings.Split(yang, ",")[0]
 		switch name {
 		case "":
-			e.addError(fmt.Errorf("%s: nil statement", Source(n)))
+			e.errorf(n, ErrInternal, "nil statement")
 		case "config":
 			e.Config, err = tristateValue(fv.Interface())
 			e.addError(err)
@@ -758,14 +1035,14 @@ func ToEntry(n Node) (e *Entry) {
 				includedToSrc := n.NName() + ":" + a.Module.Name
 
 				switch {
-				case mergedSubmodule[srcToIncluded]:
+				case es.mergedSubmodule[srcToIncluded]:
 					// We have already merged this module, so don't try and do it
 					// again.
 					continue
-				case !mergedSubmodule[includedToSrc] && a.Module.NName() != n.NName():
+				case !es.mergedSubmodule[includedToSrc] && a.Module.NName() != n.NName():
 					// We have not merged A->B, and B != B hence go ahead and merge.
 					includedToParent := a.Module.Name + ":" + a.Module.BelongsTo.Name
-					if mergedSubmodule[includedToParent] {
+					if es.mergedSubmodule[includedToParent] {
 						// Don't try and re-import submodules that have already been imported
 						// into the top-level module. Note that this ensures that we get to the
 						// top the tree (whichever the actual module for the chain of
@@ -774,13 +1051,14 @@ func ToEntry(n Node) (e *Entry) {
 						// walking through a sub-cycle of the include graph.
 						continue
 					}
-					mergedSubmodule[srcToIncluded] = true
-					mergedSubmodule[includedToParent] = true
+					es.mergedSubmodule[srcToIncluded] = true
+					es.mergedSubmodule[includedToParent] = true
 					e.merge(a.Module.Prefix, nil, ToEntry(a.Module))
-				case ParseOptions.IgnoreSubmoduleCircularDependencies:
+				case optionsOf(n).IgnoreSubmoduleCircularDependencies:
+					e.addWarning(warnf(n, WarnCircularDependency, "ignoring circular dependency, importing %s", a.Module.NName()))
 					continue
 				default:
-					e.addError(fmt.Errorf("%s: has a circular dependency, importing %s", n.NName(), a.Module.NName()))
+					e.errorf(nil, ErrCircularDependency, "%s: has a circular dependency, importing %s", n.NName(), a.Module.NName())
 				}
 			}
 		case "leaf":
@@ -848,7 +1126,7 @@ func ToEntry(n Node) (e *Entry) {
 			for _, a := range fv.Interface().([]*Uses) {
 				grouping := ToEntry(a)
 				e.merge(nil, nil, grouping)
-				if ParseOptions.StoreUses {
+				if optionsOf(a).StoreUses {
 					e.Uses = append(e.Uses, &UsesStmt{a, grouping.shallowDup()})
 				}
 			}
@@ -857,13 +1135,13 @@ func ToEntry(n Node) (e *Entry) {
 			// (e.g., leaf type resolution) is done outside of this case.
 			n, ok := n.(*Deviate)
 			if !ok {
-				e.addError(fmt.Errorf("unexpected type found, only valid under Deviate, is %T", n))
+				e.errorf(n, ErrUnexpectedSubstatement, "unexpected type found, only valid under Deviate, is %T", n)
 				continue
 			}
 
 			if n.Type != nil {
 				if errs := n.Type.resolve(); errs != nil {
-					e.addError(fmt.Errorf("deviation has unresolvable type, %v", errs))
+					e.errorf(n, ErrBadDeviation, "deviation has unresolvable type, %v", errs)
 					continue
 				}
 				e.Type = n.Type.YangType
@@ -879,7 +1157,7 @@ func ToEntry(n Node) (e *Entry) {
 			}
 			d, ok := fv.Interface().(*Value)
 			if !ok {
-				e.addError(fmt.Errorf("%s: unexpected default type in %s:%s", Source(n), n.Kind(), n.NName()))
+				e.errorf(n, ErrInternal, "unexpected default type in %s:%s", n.Kind(), n.NName())
 			}
 			e.Default = d.asString()
 		case "typedef":
@@ -906,7 +1184,7 @@ func ToEntry(n Node) (e *Entry) {
 
 					dt, ok := toDeviation[d.Statement().Argument]
 					if !ok {
-						e.addError(fmt.Errorf("%s: unknown deviation type in %s:%s", Source(n), n.Kind(), n.NName()))
+						e.errorf(n, ErrBadDeviation, "unknown deviation type in %s:%s", n.Kind(), n.NName())
 						continue
 					}
 
@@ -920,7 +1198,7 @@ func ToEntry(n Node) (e *Entry) {
 		case "mandatory":
 			v, ok := fv.Interface().(*Value)
 			if !ok {
-				e.addError(fmt.Errorf("%s: did not get expected value type", Source(n)))
+				e.errorf(n, ErrInternal, "did not get expected value type")
 			}
 			e.Mandatory, err = tristateValue(v)
 			e.addError(err)
@@ -932,7 +1210,7 @@ func ToEntry(n Node) (e *Entry) {
 			// corresponding logic.
 			v, ok := fv.Interface().(*Value)
 			if !ok {
-				e.addError(fmt.Errorf("%s: max or min elements had wrong type, %s:%s", Source(n), n.Kind(), n.NName()))
+				e.errorf(n, ErrInternal, "max or min elements had wrong type, %s:%s", n.Kind(), n.NName())
 				continue
 			}
 
@@ -958,7 +1236,7 @@ func ToEntry(n Node) (e *Entry) {
 		case "units":
 			v, ok := fv.Interface().(*Value)
 			if !ok {
-				e.addError(fmt.Errorf("%s: units had wrong type, %s:%s", Source(n), n.Kind(), n.NName()))
+				e.errorf(n, ErrInternal, "units had wrong type, %s:%s", n.Kind(), n.NName())
 			}
 			if v != nil {
 				e.Units = v.asString()
@@ -987,7 +1265,7 @@ func ToEntry(n Node) (e *Entry) {
 			// These are meta-keywords used internally
 			continue
 		default:
-			e.addError(fmt.Errorf("%s: unexpected statement: %s", Source(n), name))
+			e.errorf(n, ErrUnexpectedSubstatement, "unexpected statement: %s", name)
 			continue
 
 		}
@@ -995,7 +1273,7 @@ func ToEntry(n Node) (e *Entry) {
 		found = true
 	}
 	if !found {
-		return newError(n, "%T: cannot be converted to a *Entry", n)
+		return newError(n, ErrInternal, "%T: cannot be converted to a *Entry", n)
 	}
 	// If prefix isn't set, provide it based on our root node (module)
 	if e.Prefix == nil {
@@ -1041,6 +1319,12 @@ func getRootPrefix(e *Entry) *Value {
 // and the augments skipped.  If addErrors is true then missing augments will
 // generate errors.
 func (e *Entry) Augment(addErrors bool) (processed, skipped int) {
+	return e.augment(addErrors, nil)
+}
+
+// augment is Augment, but if find is not nil it is used to find the target
+// of each augment rather than Find.
+func (e *Entry) augment(addErrors bool, find func(a *Entry) *Entry) (processed, skipped int) {
 	// Now process the augments we found
 	// NOTE(borman): is it possible this will fail if the augment refers
 	// to some removed sibling that has not been processed?  Perhaps this
@@ -1051,10 +1335,15 @@ func (e *Entry) Augment(addErrors bool) (processed, skipped int) {
 	// progress)
 	var sa []*Entry
 	for _, a := range e.Augments {
-		ae := a.Find(a.Name)
+		var ae *Entry
+		if find != nil {
+			ae = find(a)
+		} else {
+			ae = a.Find(a.Name)
+		}
 		if ae == nil {
 			if addErrors {
-				e.errorf("%s: augment %s not found", Source(a.Node), a.Name)
+				e.errorf(a.Node, ErrAugmentTargetMissing, "augment %s not found%s", a.Name, augmentSuggestion(a))
 			}
 			skipped++
 			sa = append(sa, a)
@@ -1065,7 +1354,15 @@ func (e *Entry) Augment(addErrors bool) (processed, skipped int) {
 		// augment since the nodes have this namespace even though they
 		// are merged into another entry.
 		processed++
+		tracef(a.Node, "augmenting %s with %s", ae.Path(), a.Name)
 		ae.merge(nil, a.Namespace(), a)
+		if an, ok := a.Node.(*Augment); ok {
+			for k, ce := range a.Dir {
+				if me := ae.Dir[k]; me != nil && me.Node == ce.Node {
+					me.augmentedBy = an
+				}
+			}
+		}
 		ae.Augmented = append(ae.Augmented, a.shallowDup())
 	}
 	e.Augments = sa
@@ -1080,98 +1377,111 @@ func (e *Entry) ApplyDeviate() []error {
 	for _, d := range e.Deviations {
 		deviatedNode := e.Find(d.DeviatedPath)
 		if deviatedNode == nil {
-			appendErr(fmt.Errorf("cannot find target node to deviate, %s", d.DeviatedPath))
+			appendErr(errorf(d.Node, ErrDeviationTargetMissing, "cannot find target node to deviate, %s", d.DeviatedPath))
 			continue
 		}
 
-		for dt, dv := range d.Deviate {
-			for _, devSpec := range dv {
-				switch dt {
-				case DeviationAdd, DeviationReplace:
-					if devSpec.Config != TSUnset {
-						deviatedNode.Config = devSpec.Config
-					}
+		for _, td := range d.deviates() {
+			dt, devSpec := td.Type, td.Entry
+			tracef(devSpec.Node, "deviate %s %s", dt, deviatedNode.Path())
+			ad := &AppliedDeviation{Type: dt}
+			ad.Deviation, _ = d.Node.(*Deviation)
+			ad.Deviate, _ = devSpec.Node.(*Deviate)
+			if d.Node != nil {
+				ad.Module = d.Node.ParentModule()
+			}
+			before := deviatedNode.deviationState()
+			switch dt {
+			case DeviationAdd, DeviationReplace:
+				if devSpec.Config != TSUnset {
+					deviatedNode.Config = devSpec.Config
+				}
 
-					if devSpec.Default != "" {
-						deviatedNode.Default = ""
-					}
+				if devSpec.Default != "" {
+					deviatedNode.Default = ""
+				}
 
-					if devSpec.Mandatory != TSUnset {
-						deviatedNode.Mandatory = devSpec.Mandatory
-					}
+				if devSpec.Mandatory != TSUnset {
+					deviatedNode.Mandatory = devSpec.Mandatory
+				}
 
-					if devSpec.deviatePresence.hasMinElements {
-						if !deviatedNode.IsList() && !deviatedNode.IsLeafList() {
-							appendErr(fmt.Errorf("tried to deviate min-elements on a non-list type %s", deviatedNode.Kind))
-							continue
-						}
-						deviatedNode.ListAttr.MinElements = devSpec.ListAttr.MinElements
+				if devSpec.deviatePresence.hasMinElements {
+					if !deviatedNode.IsList() && !deviatedNode.IsLeafList() {
+						appendErr(errorf(devSpec.Node, ErrBadDeviation, "tried to deviate min-elements on a non-list type %s", deviatedNode.Kind))
+						break
 					}
+					deviatedNode.ListAttr.MinElements = devSpec.ListAttr.MinElements
+				}
 
-					if devSpec.deviatePresence.hasMaxElements {
-						if !deviatedNode.IsList() && !deviatedNode.IsLeafList() {
-							appendErr(fmt.Errorf("tried to deviate max-elements on a non-list type %s", deviatedNode.Kind))
-							continue
-						}
-						deviatedNode.ListAttr.MaxElements = devSpec.ListAttr.MaxElements
+				if devSpec.deviatePresence.hasMaxElements {
+					if !deviatedNode.IsList() && !deviatedNode.IsLeafList() {
+						appendErr(errorf(devSpec.Node, ErrBadDeviation, "tried to deviate max-elements on a non-list type %s", deviatedNode.Kind))
+						break
 					}
+					deviatedNode.ListAttr.MaxElements = devSpec.ListAttr.MaxElements
+				}
 
-					if devSpec.Units != "" {
-						deviatedNode.Units = devSpec.Units
-					}
+				if devSpec.Units != "" {
+					deviatedNode.Units = devSpec.Units
+				}
 
-					if devSpec.Type != nil {
-						deviatedNode.Type = devSpec.Type
-					}
+				if devSpec.Type != nil {
+					deviatedNode.Type = devSpec.Type
+				}
 
-				case DeviationNotSupported:
-					dp := deviatedNode.Parent
-					if dp == nil {
-						appendErr(fmt.Errorf("%s: node %s does not have a valid parent, but deviate not-supported references one", Source(e.Node), e.Name))
-						continue
-					}
-					dp.delete(deviatedNode.Name)
-				case DeviationDelete:
-					if devSpec.Config != TSUnset {
-						deviatedNode.Config = TSUnset
-					}
+			case DeviationNotSupported:
+				dp := deviatedNode.Parent
+				if dp == nil {
+					appendErr(errorf(e.Node, ErrBadDeviation, "node %s does not have a valid parent, but deviate not-supported references one", e.Name))
+					break
+				}
+				dp.delete(deviatedNode.Name)
+				ad.Changes = []string{"removed " + deviatedNode.Name}
+				dp.deviatedBy = append(dp.deviatedBy, ad)
+			case DeviationDelete:
+				if devSpec.Config != TSUnset {
+					deviatedNode.Config = TSUnset
+				}
 
-					if devSpec.Default == "" {
-						deviatedNode.Default = ""
-					}
+				if devSpec.Default == "" {
+					deviatedNode.Default = ""
+				}
 
-					if devSpec.Mandatory != TSUnset {
-						deviatedNode.Mandatory = TSUnset
-					}
+				if devSpec.Mandatory != TSUnset {
+					deviatedNode.Mandatory = TSUnset
+				}
 
-					if devSpec.deviatePresence.hasMinElements {
-						if !deviatedNode.IsList() && !deviatedNode.IsLeafList() {
-							appendErr(fmt.Errorf("tried to deviate min-elements on a non-list type %s", deviatedNode.Kind))
-							continue
-						}
-						if deviatedNode.ListAttr.MinElements != devSpec.ListAttr.MinElements {
-							// Argument value must match:
-							// https://tools.ietf.org/html/rfc7950#section-7.20.3.2
-							appendErr(fmt.Errorf("min-element value %d differs from deviation's min-element value %d for entry %v", devSpec.ListAttr.MinElements, deviatedNode.ListAttr.MinElements, d.DeviatedPath))
-						}
-						deviatedNode.ListAttr.MinElements = 0
+				if devSpec.deviatePresence.hasMinElements {
+					if !deviatedNode.IsList() && !deviatedNode.IsLeafList() {
+						appendErr(errorf(devSpec.Node, ErrBadDeviation, "tried to deviate min-elements on a non-list type %s", deviatedNode.Kind))
+						break
 					}
-
-					if devSpec.deviatePresence.hasMaxElements {
-						if !deviatedNode.IsList() && !deviatedNode.IsLeafList() {
-							appendErr(fmt.Errorf("tried to deviate max-elements on a non-list type %s", deviatedNode.Kind))
-							continue
-						}
-						if deviatedNode.ListAttr.MaxElements != devSpec.ListAttr.MaxElements {
-							appendErr(fmt.Errorf("max-element value %d differs from deviation's max-element value %d for entry %v", devSpec.ListAttr.MaxElements, deviatedNode.ListAttr.MaxElements, d.DeviatedPath))
-						}
-						deviatedNode.ListAttr.MaxElements = math.MaxUint64
+					if deviatedNode.ListAttr.MinElements != devSpec.ListAttr.MinElements {
+						// Argument value must match:
+						// https://tools.ietf.org/html/rfc7950#section-7.20.3.2
+						appendErr(errorf(devSpec.Node, ErrBadDeviation, "min-element value %d differs from deviation's min-element value %d for entry %v", devSpec.ListAttr.MinElements, deviatedNode.ListAttr.MinElements, d.DeviatedPath))
 					}
+					deviatedNode.ListAttr.MinElements = 0
+				}
 
-				default:
-					appendErr(fmt.Errorf("invalid deviation type %s", dt))
+				if devSpec.deviatePresence.hasMaxElements {
+					if !deviatedNode.IsList() && !deviatedNode.IsLeafList() {
+						appendErr(errorf(devSpec.Node, ErrBadDeviation, "tried to deviate max-elements on a non-list type %s", deviatedNode.Kind))
+						break
+					}
+					if deviatedNode.ListAttr.MaxElements != devSpec.ListAttr.MaxElements {
+						appendErr(errorf(devSpec.Node, ErrBadDeviation, "max-element value %d differs from deviation's max-element value %d for entry %v", devSpec.ListAttr.MaxElements, deviatedNode.ListAttr.MaxElements, d.DeviatedPath))
+					}
+					deviatedNode.ListAttr.MaxElements = math.MaxUint64
 				}
+
+			default:
+				appendErr(errorf(d.Node, ErrBadDeviation, "invalid deviation type %s", dt))
+			}
+			if dt != DeviationNotSupported {
+				ad.Changes = before.changes(deviatedNode.deviationState())
 			}
+			deviatedNode.deviatedBy = append(deviatedNode.deviatedBy, ad)
 		}
 	}
 
@@ -1198,6 +1508,9 @@ func (e *Entry) FixChoice() {
 					Prefix: ce.Prefix,
 					Dir:    map[string]*Entry{ce.Name: ce},
 					Extra:  map[string][]interface{}{},
+					usedAt: ce.usedAt,
+
+					augmentedBy: ce.augmentedBy,
 				}
 				ce.Parent = ne
 				e.Dir[k] = ne
@@ -1209,6 +1522,80 @@ func (e *Entry) FixChoice() {
 	}
 }
 
+// addUsedAt records that e and its descendants were instantiated by the
+// uses statement u.  The entries are duplicates made for u, so their lists
+// may be extended without affecting other uses of the same grouping.
+func (e *Entry) addUsedAt(u *Uses) {
+	e.usedAt = append(append([]*Uses{}, e.usedAt...), u)
+	for _, ce := range e.Dir {
+		ce.addUsedAt(u)
+	}
+}
+
+// Statement returns the statement e was built from, or nil if e has no
+// Node.  For an entry instantiated from a grouping, this is the statement
+// within the grouping; see UsedAt for the uses statements.  For an entry
+// added by an augment, it is the statement within the augment, which is
+// an ancestor of the statement.
+func (e *Entry) Statement() *Statement {
+	if e == nil || e.Node == nil {
+		return nil
+	}
+	return e.Node.Statement()
+}
+
+// UsedAt returns the uses statements that instantiated e from a grouping,
+// innermost first, or nil if e was not instantiated from a grouping.  For
+// example, given:
+//
+//   grouping inner { leaf l { type string; } }
+//   grouping outer { uses inner; }
+//   container c { uses outer; }
+//
+// UsedAt of the entry of leaf l in container c returns "uses inner"
+// followed by "uses outer".
+func (e *Entry) UsedAt() []*Uses {
+	if e == nil {
+		return nil
+	}
+	return e.usedAt
+}
+
+// AugmentedBy returns the augment statement that added e, or the nearest
+// ancestor of e, to the Entry tree, or nil if e was not added by an
+// augment.  The Name of the augment is its target path as written, and
+// ParentModule returns the module the augment is defined in.
+func (e *Entry) AugmentedBy() *Augment {
+	for ; e != nil; e = e.Parent {
+		if e.augmentedBy != nil {
+			return e.augmentedBy
+		}
+	}
+	return nil
+}
+
+// Position returns the position of the statement e was built from, see
+// Statement.  It is the zero Position if e has no Node or the position is
+// not known.
+func (e *Entry) Position() Position {
+	return e.Statement().Position()
+}
+
+// ExpandedAt returns the positions of the statements that placed e in its
+// Entry tree without containing it: the uses statements returned by UsedAt,
+// innermost first, followed by the augment returned by AugmentedBy.  It
+// returns nil if e is defined where it appears in the tree.
+func (e *Entry) ExpandedAt() []Position {
+	var ps []Position
+	for _, u := range e.UsedAt() {
+		ps = append(ps, u.Statement().Position())
+	}
+	if a := e.AugmentedBy(); a != nil {
+		ps = append(ps, a.Statement().Position())
+	}
+	return ps
+}
+
 // ReadOnly returns true if e is a read-only variable (config == false).
 // If Config is unset in e, then false is returned if e has no parent,
 // otherwise the value parent's ReadOnly is returned.
@@ -1226,10 +1613,75 @@ func (e *Entry) ReadOnly() bool {
 	}
 }
 
+// IsConfig returns true if e represents configuration, following the rules
+// of RFC 7950 section 7.21.1: config is inherited from the nearest ancestor
+// that sets it, and a top level node that does not set it is configuration.
+// Nodes within an rpc, action, or notification, and the nodes of sx:structure
+// data structures, are never configuration, regardless of their config
+// statements.  Deviations of config are included as they are applied to
+// Config by Process.
+func (e *Entry) IsConfig() bool {
+	if e == nil {
+		return false
+	}
+	config := true
+	for a := e; a != nil; a = a.Parent {
+		switch {
+		case a.RPC != nil, a.Kind == InputEntry, a.Kind == OutputEntry, a.Kind == NotificationEntry:
+			return false
+		case a.Parent != nil && a.Parent.Structures[a.Name] == a:
+			return false
+		case a.Config == TSFalse:
+			config = false
+		}
+	}
+	return config
+}
+
+// importPrefixes returns a map from the prefixes used within the module of
+// the root entry e to the prefixes the referenced modules use for
+// themselves.  The map is computed once per module, as resolving each
+// augment and deviation of a module needs it.
+func importPrefixes(e *Entry) (map[string]string, error) {
+	m := e.Node.(*Module)
+	ms := m.modules
+	if pfxMap := ms.importPrefixes[m]; pfxMap != nil {
+		return pfxMap, nil
+	}
+	pfxMap := map[string]string{
+		// Seed the map with the local module - we use GetPrefix just
+		// in case the module is a submodule.
+		m.GetPrefix(): e.Prefix.Name,
+	}
+
+	// Add a map between the prefix used in the import statement, and
+	// the prefix that is used in the module itself.
+	for _, i := range m.Import {
+		// Resolve the module using the current module set, since we may
+		// not have populated the Module for the entry yet.
+		im, ok := ms.Modules[i.Name]
+		if !ok {
+			return nil, errorf(nil, ErrUnknownModule, "cannot find a module with name %s", i.Name)
+		}
+		pfxMap[i.Prefix.Name] = im.Prefix.Name
+	}
+	ms.importPrefixes[m] = pfxMap
+	return pfxMap, nil
+}
+
 // Find finds the Entry named by name relative to e.
 func (e *Entry) Find(name string) *Entry {
+	found, _, _ := e.find(name)
+	return found
+}
+
+// find is Find, but if the named Entry is not found because an Entry on
+// the way to it has no child with the name given by the path, that Entry
+// and the rest of the path, starting with the missing child, are also
+// returned, as by walk.
+func (e *Entry) find(name string) (found, parent *Entry, rest []string) {
 	if e == nil || name == "" {
-		return nil
+		return nil, nil, nil
 	}
 	parts := strings.Split(name, "/")
 
@@ -1244,24 +1696,10 @@ func (e *Entry) Find(name string) *Entry {
 		// Since this module might use a different prefix that isn't
 		// the prefix that the module itself uses then we need to resolve
 		// the module into its local prefix to find it.
-		pfxMap := map[string]string{
-			// Seed the map with the local module - we use GetPrefix just
-			// in case the module is a submodule.
-			e.Node.(*Module).GetPrefix(): e.Prefix.Name,
-		}
-
-		// Add a map between the prefix used in the import statement, and
-		// the prefix that is used in the module itself.
-		for _, i := range e.Node.(*Module).Import {
-			// Resolve the module using the current module set, since we may
-			// not have populated the Module for the entry yet.
-			m, ok := e.Node.(*Module).modules.Modules[i.Name]
-			if !ok {
-				e.addError(fmt.Errorf("cannot find a module with name %s when looking at imports in %s", i.Name, e.Path()))
-				return nil
-			}
-
-			pfxMap[i.Prefix.Name] = m.Prefix.Name
+		pfxMap, err := importPrefixes(e)
+		if err != nil {
+			e.errorf(nil, ErrUnknownModule, "%v when looking at imports in %s", err, e.Path())
+			return nil, nil, nil
 		}
 
 		if prefix, _ := getPrefix(parts[0]); prefix != "" {
@@ -1269,13 +1707,17 @@ func (e *Entry) Find(name string) *Entry {
 			if !ok {
 				// This is an undefined prefix within our context, so
 				// we can't do anything about resolving it.
-				e.addError(fmt.Errorf("invalid module prefix %s within module %s, defined prefix map: %v", prefix, e.Name, pfxMap))
-				return nil
+				var names []string
+				for p := range pfxMap {
+					names = append(names, p)
+				}
+				e.errorf(nil, ErrUnknownPrefix, "invalid module prefix %s within module %s, defined prefix map: %v%s", prefix, e.Name, pfxMap, didYouMean(prefix, names))
+				return nil, nil, nil
 			}
 			m, err := e.Modules().FindModuleByPrefix(pfx)
 			if err != nil {
 				e.addError(err)
-				return nil
+				return nil, nil, nil
 			}
 			if e.Node.(*Module) != m {
 				e = ToEntry(m)
@@ -1283,10 +1725,20 @@ func (e *Entry) Find(name string) *Entry {
 		}
 	}
 
-	for _, part := range parts {
+	return e.walk(parts)
+}
+
+// walk returns the Entry found by following the elements of a path, parts,
+// from e.  If it is not found because an Entry on the way to it has no
+// child named by an element of parts, that Entry and the elements of parts
+// from that one on are also returned.  The Entry can then only be found
+// once that child has been added, after which the walk can be continued
+// from the returned Entry.
+func (e *Entry) walk(parts []string) (found, parent *Entry, rest []string) {
+	for i, part := range parts {
 		switch {
 		case e == nil:
-			return nil
+			return nil, nil, nil
 		case part == ".":
 		case part == "..":
 			e = e.Parent
@@ -1303,21 +1755,100 @@ func (e *Entry) Find(name string) *Entry {
 			switch part {
 			case ".":
 			case "", "..":
-				return nil
+				return nil, nil, nil
 			default:
+				if e.Dir[part] == nil {
+					return nil, e, parts[i:]
+				}
 				e = e.Dir[part]
 			}
 		}
 	}
-	return e
+	return e, nil, nil
 }
 
-// Path returns the path to e. A nil Entry returns "".
+// Path returns the path to e. A nil Entry returns "".  If the
+// QualifiedPaths option of the Modules of e is set, Path returns
+// e.QualifiedPath().
 func (e *Entry) Path() string {
 	if e == nil {
 		return ""
 	}
-	return e.Parent.Path() + "/" + e.Name
+	if e.options().QualifiedPaths {
+		return e.QualifiedPath()
+	}
+	return e.path()
+}
+
+// path returns the path to e starting with the name of its module.
+func (e *Entry) path() string {
+	if e == nil {
+		return ""
+	}
+	return e.Parent.path() + "/" + e.Name
+}
+
+// options returns the options of the Modules of e, which is that of the
+// node of its root entry, or ParseOptions if e is not part of a Modules.
+func (e *Entry) options() *Options {
+	for e.Parent != nil {
+		e = e.Parent
+	}
+	return optionsOf(e.Node)
+}
+
+// QualifiedName returns the name of e as used in the JSON encoding of data
+// (RFC 7951 section 4).  The name is qualified by the name of the module
+// defining e, e.g., "openconfig-interfaces:interfaces", if e is a top level
+// data node or its module differs from that of its parent data node, as is
+// the case for nodes added by an augment from another module.  Otherwise
+// e.Name is returned.
+func (e *Entry) QualifiedName() string {
+	p := e.dataParent()
+	if p == nil {
+		return e.Name
+	}
+	mod, err := e.InstantiatingModule()
+	if err != nil {
+		return e.Name
+	}
+	if p.Parent != nil {
+		if pm, err := p.InstantiatingModule(); err == nil && pm == mod {
+			return e.Name
+		}
+	}
+	return mod + ":" + e.Name
+}
+
+// QualifiedPath returns the data path to e with each node named by
+// QualifiedName, e.g., "/openconfig-interfaces:interfaces/interface".
+// Unlike Path, the path does not start with the name of the module and
+// choice and case entries are omitted.  The path of a module entry is "/".
+// A nil Entry returns "".
+func (e *Entry) QualifiedPath() string {
+	if e == nil {
+		return ""
+	}
+	var parts []string
+	for ; e.Parent != nil; e = e.Parent {
+		if !e.IsChoice() && !e.IsCase() {
+			parts = append(parts, e.QualifiedName())
+		}
+	}
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return "/" + strings.Join(parts, "/")
+}
+
+// dataParent returns the nearest ancestor of e that is not a choice or
+// case, or nil if e has no parent.
+func (e *Entry) dataParent() *Entry {
+	p := e.Parent
+	for p != nil && (p.IsChoice() || p.IsCase()) {
+		p = p.Parent
+	}
+	return p
 }
 
 // Namespace returns the YANG/XML namespace Value for e as mounted in the Entry
@@ -1365,6 +1896,17 @@ func (e *Entry) InstantiatingModule() (string, error) {
 	return ns.Name, nil
 }
 
+// DefinedIn returns the module or submodule that the node e was built from
+// is defined in, e.g., the submodule of a node defined in a submodule, or
+// the module of a grouping for an entry instantiated from the grouping.
+// Nil is returned if e has no Node.  Source(e.Node) returns the file.
+func (e *Entry) DefinedIn() *Module {
+	if e == nil || e.Node == nil {
+		return nil
+	}
+	return RootNode(e.Node)
+}
+
 // shallowDup makes a shallow duplicate of e (only direct children are
 // duplicated; grandchildren and deeper descendants are deleted).
 func (e *Entry) shallowDup() *Entry {
@@ -1422,7 +1964,7 @@ func (e *Entry) merge(prefix *Value, namespace *Value, oe *Entry) {
 			v.namespace = namespace
 		}
 		if se := e.Dir[k]; se != nil {
-			er := newError(oe.Node, `Duplicate node %q in %q from:
+			er := newError(oe.Node, ErrDuplicateNode, `Duplicate node %q in %q from:
    %s: %s
    %s: %s`, k, e.Name, Source(v.Node), v.Name, Source(se.Node), se.Name)
 			e.addError(er.Errors[0])
@@ -1507,12 +2049,20 @@ func (s sortedErrors) Less(i, j int) bool {
 			return false
 		}
 	}
-	return false
+	// Errors at the same position are ordered by their code and then by
+	// their message, so the order does not depend on the order in which
+	// they were found.
+	if ci, cj := ErrorCode(s[i].err), ErrorCode(s[j].err); ci != cj {
+		return ci < cj
+	}
+	return s[i].s < s[j].s
 }
 
 // errorSort sorts the strings in the errors slice assuming each line starts
-// with file:line:col.  Line and column number are sorted numerically.
-// Duplicate errors are stripped.
+// with file:line:col.  Line and column number are sorted numerically, and
+// errors at the same position are sorted by their code and message, so the
+// order is the same on every run.  Errors with identical messages are
+// stripped.
 func errorSort(errors []error) []error {
 	switch len(errors) {
 	case 0:
@@ -1524,17 +2074,17 @@ func errorSort(errors []error) []error {
 	for x, err := range errors {
 		elist[x] = sError{err.Error(), err}
 	}
-	sort.Sort(elist)
-	errors = make([]error, len(errors))
-	i := 0
+	sort.Stable(elist)
+	errors = make([]error, 0, len(errors))
+	seen := map[string]bool{}
 	for _, err := range elist {
-		if i > 0 && reflect.DeepEqual(err.err, errors[i-1]) {
+		if seen[err.s] {
 			continue
 		}
-		errors[i] = err.err
-		i++
+		seen[err.s] = true
+		errors = append(errors, err.err)
 	}
-	return errors[:i]
+	return errors
 }
 
 // DefaultValue returns the schema default value for e, if any. If the leaf
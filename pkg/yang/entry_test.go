@@ -161,7 +161,6 @@ module base {
 
 func TestBadYang(t *testing.T) {
 	for _, tt := range badInputs {
-		typeDict = typeDictionary{dict: map[Node]map[string]*Typedef{}}
 		ms := NewModules()
 		if err := ms.Parse(tt.in, tt.name); err != nil {
 			t.Fatalf("unexpected error %s", err)
@@ -1479,7 +1478,6 @@ func TestFullModuleProcess(t *testing.T) {
 
 	for _, tt := range tests {
 		ms := NewModules()
-		mergedSubmodule = map[string]bool{}
 
 		ParseOptions.IgnoreSubmoduleCircularDependencies = tt.inIgnoreCircDeps
 		for n, m := range tt.inModules {
@@ -3129,7 +3127,6 @@ func TestDeviation(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
 			ms := NewModules()
-			mergedSubmodule = map[string]bool{}
 
 			for name, mod := range tt.inFiles {
 				if err := ms.Parse(mod, name); err != nil {
@@ -3493,3 +3490,693 @@ func TestLess(t *testing.T) {
 		}
 	}
 }
+
+func TestIsConfig(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"ietf-yang-structure-ext": structureExtModule,
+		"c": `module c {
+  prefix "c";
+  namespace "urn:c";
+  import ietf-yang-structure-ext { prefix "sx"; }
+
+  container top {
+    leaf cfg { type string; }
+    container state {
+      config false;
+      leaf counter { type uint32; }
+    }
+    choice ch {
+      case one { leaf in-case { type string; } }
+    }
+    action reset {
+      input { leaf force { type boolean; } }
+      output { leaf done { type boolean; } }
+    }
+    notification changed { leaf what { type string; } }
+    leaf deviated { type string; }
+  }
+  rpc ping {
+    input { leaf host { type string; } }
+  }
+  sx:structure msg { leaf body { type string; } }
+  deviation /c:top/c:deviated { deviate add { config false; } }
+}`,
+	} {
+		if err := ms.Parse(text, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	e := ToEntry(ms.Modules["c"])
+	for _, tt := range []struct {
+		path string
+		want bool
+	}{
+		{"/top", true},
+		{"/top/cfg", true},
+		{"/top/state", false},
+		{"/top/state/counter", false},
+		{"/top/ch/one/in-case", true},
+		{"/top/reset", false},
+		{"/top/reset/input/force", false},
+		{"/top/reset/output/done", false},
+		{"/top/changed/what", false},
+		{"/top/deviated", false},
+		{"/ping/input/host", false},
+	} {
+		ce := e.Find(tt.path)
+		if ce == nil {
+			t.Errorf("%s: not found", tt.path)
+			continue
+		}
+		if got := ce.IsConfig(); got != tt.want {
+			t.Errorf("%s: got IsConfig %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	if got := e.Structures["msg"].Dir["body"].IsConfig(); got {
+		t.Errorf("structure leaf: got IsConfig %v, want false", got)
+	}
+	var nilEntry *Entry
+	if nilEntry.IsConfig() {
+		t.Error("nil Entry: got IsConfig true, want false")
+	}
+}
+
+func TestUsedAt(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`module u {
+  prefix "u";
+  namespace "urn:u";
+  grouping inner { leaf l { type string; } }
+  grouping outer { container o { uses inner; } }
+  container c { uses outer; }
+  container d { uses inner; leaf own { type string; } }
+  augment "/u:d" { leaf added { type string; } }
+  grouping gc { choice ch { leaf l { type string; } } }
+  container e { uses gc; }
+}`, "u.yang"); err != nil {
+		t.Fatal(err)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	e := ToEntry(ms.Modules["u"])
+	usedAt := func(e *Entry) []string {
+		var s []string
+		for _, u := range e.UsedAt() {
+			s = append(s, Source(u)+" "+u.Name)
+		}
+		return s
+	}
+	for _, tt := range []struct {
+		path     string
+		wantStmt string
+		wantUses []string
+	}{
+		{"/c/o", "u.yang:5:20", []string{"u.yang:6:17 outer"}},
+		{"/c/o/l", "u.yang:4:20", []string{"u.yang:5:34 inner", "u.yang:6:17 outer"}},
+		{"/d/l", "u.yang:4:20", []string{"u.yang:7:17 inner"}},
+		{"/d/own", "u.yang:7:29", nil},
+		{"/d/added", "u.yang:8:20", nil},
+		{"/e/ch/l", "u.yang:9:29", []string{"u.yang:10:17 gc"}},
+		{"/e/ch/l/l", "u.yang:9:29", []string{"u.yang:10:17 gc"}},
+	} {
+		ce := e.Find(tt.path)
+		if ce == nil {
+			t.Errorf("%s: not found", tt.path)
+			continue
+		}
+		s := ce.Statement()
+		if got := fmt.Sprintf("%s:%d:%d", s.file, s.line, s.col); got != tt.wantStmt {
+			t.Errorf("%s: got statement at %s, want %s", tt.path, got, tt.wantStmt)
+		}
+		if diff := cmp.Diff(tt.wantUses, usedAt(ce)); diff != "" {
+			t.Errorf("%s: UsedAt (-want, +got):\n%s", tt.path, diff)
+		}
+	}
+	var nilEntry *Entry
+	if nilEntry.Statement() != nil || nilEntry.UsedAt() != nil {
+		t.Error("nil Entry: got non-nil Statement or UsedAt")
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`module n {
+  yang-version 1.1;
+  prefix "n";
+  namespace "urn:n";
+  notification top;
+  container c {
+    notification changed { leaf what { type string; } }
+    list l {
+      key "k";
+      leaf k { type string; }
+      notification gone;
+    }
+  }
+  grouping g { notification from-grouping; }
+  container d { uses g; }
+  augment "/n:d" { notification augmented; }
+}`, "n.yang"); err != nil {
+		t.Fatal(err)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	var got []string
+	for _, e := range ToEntry(ms.Modules["n"]).Notifications() {
+		got = append(got, e.Path())
+	}
+	want := []string{
+		"/n/c/changed",
+		"/n/c/l/gone",
+		"/n/d/augmented",
+		"/n/d/from-grouping",
+		"/n/top",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Notifications (-want, +got):\n%s", diff)
+	}
+	if e := ToEntry(ms.Modules["n"]).Find("/n:c/n:changed/n:what"); e == nil || e.IsNotification() || e.Parent == nil || !e.Parent.IsNotification() {
+		t.Errorf("leaf what: not found within notification changed")
+	}
+}
+
+func TestPrintAnyNodes(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`module a {
+  yang-version 1.1;
+  prefix "a";
+  namespace "urn:a";
+  container c {
+    anydata d { config false; }
+    anyxml x;
+  }
+}`, "a.yang"); err != nil {
+		t.Fatal(err)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	c := ToEntry(ms.Modules["a"]).Dir["c"]
+	if got, want := c.Dir["d"].Kind, AnyDataEntry; got != want {
+		t.Errorf("anydata: got kind %v, want %v", got, want)
+	}
+	if got, want := c.Dir["x"].Kind, AnyXMLEntry; got != want {
+		t.Errorf("anyxml: got kind %v, want %v", got, want)
+	}
+	var buf bytes.Buffer
+	c.Print(&buf)
+	want := `rw: c {
+  RO: anydata d
+  rw: anyxml x
+}
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("Print (-want, +got):\n%s", diff)
+	}
+}
+
+func TestEntryPosition(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`module p {
+  prefix "p";
+  namespace "urn:p";
+  grouping inner { leaf l { type string; } }
+  grouping outer { container o { uses inner; } }
+  container c { uses outer; }
+  container d { leaf own { type string; } }
+  augment "/p:d" {
+    uses inner;
+    container a { leaf b { type string; } }
+  }
+}`, "p.yang"); err != nil {
+		t.Fatal(err)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	e := ToEntry(ms.Modules["p"])
+	for _, tt := range []struct {
+		path         string
+		want         Position
+		wantExpanded []Position
+	}{
+		{"/c", Position{"p.yang", 6, 3}, nil},
+		{"/c/o/l", Position{"p.yang", 4, 20}, []Position{{"p.yang", 5, 34}, {"p.yang", 6, 17}}},
+		{"/d/own", Position{"p.yang", 7, 17}, nil},
+		{"/d/l", Position{"p.yang", 4, 20}, []Position{{"p.yang", 9, 5}, {"p.yang", 8, 3}}},
+		{"/d/a/b", Position{"p.yang", 10, 19}, []Position{{"p.yang", 8, 3}}},
+	} {
+		ce := e.Find(tt.path)
+		if ce == nil {
+			t.Errorf("%s: not found", tt.path)
+			continue
+		}
+		if got := ce.Position(); got != tt.want {
+			t.Errorf("%s: got Position %v, want %v", tt.path, got, tt.want)
+		}
+		if diff := cmp.Diff(tt.wantExpanded, ce.ExpandedAt()); diff != "" {
+			t.Errorf("%s: ExpandedAt (-want, +got):\n%s", tt.path, diff)
+		}
+	}
+	var nilEntry *Entry
+	if got := nilEntry.Position(); got != (Position{}) {
+		t.Errorf("nil Entry: got Position %v, want the zero Position", got)
+	}
+}
+
+func TestSubmoduleAccessors(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"m": `module m {
+  prefix "m";
+  namespace "urn:m";
+  include m-a;
+  include m-b;
+  container top { uses from-b; }
+}`,
+		"m-a": `submodule m-a {
+  belongs-to m { prefix "m"; }
+  include m-c;
+  container a;
+}`,
+		"m-b": `submodule m-b {
+  belongs-to m { prefix "m"; }
+  include m-c;
+  grouping from-b { leaf b { type string; } }
+}`,
+		"m-c": `submodule m-c {
+  belongs-to m { prefix "m"; }
+  container c;
+}`,
+	} {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	m := ms.Modules["m"]
+	var subs []string
+	for _, s := range m.Submodules() {
+		subs = append(subs, s.Name)
+		if got := s.BelongsToModule(); got != m {
+			t.Errorf("%s.BelongsToModule() = %v, want m", s.Name, got)
+		}
+	}
+	if want := []string{"m-a", "m-c", "m-b"}; !cmp.Equal(subs, want) {
+		t.Errorf("Submodules() = %v, want %v", subs, want)
+	}
+	if got := m.BelongsToModule(); got != m {
+		t.Errorf("m.BelongsToModule() = %v, want m", got)
+	}
+
+	e := ToEntry(m)
+	for _, tt := range []struct {
+		path string
+		want string
+	}{
+		{"/top", "m"},
+		{"/a", "m-a"},
+		{"/c", "m-c"},
+		{"/top/b", "m-b"},
+	} {
+		ce := e.Find(tt.path)
+		if ce == nil {
+			t.Errorf("%s not found", tt.path)
+			continue
+		}
+		if got := ce.DefinedIn(); got == nil || got.Name != tt.want {
+			t.Errorf("%s: DefinedIn() = %v, want %s", tt.path, got, tt.want)
+		}
+	}
+
+	orphan := NewModules()
+	if err := orphan.Parse(`submodule o-sub { belongs-to o { prefix "o"; } }`, "o-sub.yang"); err != nil {
+		t.Fatal(err)
+	}
+	if got := orphan.SubModules["o-sub"].BelongsToModule(); got != nil {
+		t.Errorf("BelongsToModule() of an orphan submodule = %v, want nil", got)
+	}
+}
+
+func TestQualifiedPath(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"qa": `module qa {
+  prefix "a";
+  namespace "urn:qa";
+  container top {
+    choice ch { case one { leaf in-case { type string; } } }
+  }
+  rpc op { input { leaf arg { type string; } } }
+}`,
+		"qb": `module qb {
+  prefix "b";
+  namespace "urn:qb";
+  import qa { prefix "a"; }
+  grouping g { leaf from-g { type string; } }
+  augment "/a:top" { container added { leaf l { type string; } uses g; } }
+}`,
+	} {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	e := ToEntry(ms.Modules["qa"])
+	for _, tt := range []struct {
+		path     string
+		wantName string
+		want     string
+	}{
+		{"", "qa", "/"},
+		{"/top", "qa:top", "/qa:top"},
+		{"/top/ch/one/in-case", "in-case", "/qa:top/in-case"},
+		{"/top/added", "qb:added", "/qa:top/qb:added"},
+		{"/top/added/l", "l", "/qa:top/qb:added/l"},
+		{"/top/added/from-g", "from-g", "/qa:top/qb:added/from-g"},
+		{"/op/input/arg", "arg", "/qa:op/input/arg"},
+	} {
+		ce := e
+		if tt.path != "" {
+			ce = e.Find(tt.path)
+		}
+		if ce == nil {
+			t.Errorf("%s not found", tt.path)
+			continue
+		}
+		if got := ce.QualifiedName(); got != tt.wantName {
+			t.Errorf("%s: QualifiedName() = %q, want %q", tt.path, got, tt.wantName)
+		}
+		if got := ce.QualifiedPath(); got != tt.want {
+			t.Errorf("%s: QualifiedPath() = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	ce := e.Find("/top/added/l")
+	if got, want := ce.Path(), "/qa/top/added/l"; got != want {
+		t.Errorf("Path() = %q, want %q", got, want)
+	}
+	ms.opts.QualifiedPaths = true
+	if got, want := ce.Path(), "/qa:top/qb:added/l"; got != want {
+		t.Errorf("Path() with QualifiedPaths = %q, want %q", got, want)
+	}
+}
+
+func TestDeviatedBy(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"dv": `module dv {
+  prefix "d";
+  namespace "urn:dv";
+  container c {
+    leaf l { type string; units "s"; }
+    leaf-list ll { type string; max-elements 10; }
+    leaf gone { type string; }
+    leaf same { type string; }
+  }
+}`,
+		"dv-dev": `module dv-dev {
+  prefix "dd";
+  namespace "urn:dv-dev";
+  import dv { prefix "d"; }
+  deviation "/d:c/d:l" {
+    deviate replace { type int32; }
+    deviate add { config false; }
+  }
+  deviation "/d:c/d:ll" { deviate replace { max-elements 5; } }
+  deviation "/d:c/d:gone" { deviate not-supported; }
+}`,
+	} {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Deviations are applied by Process, so find the entry of the node
+	// that is removed before they are.
+	var gone *Entry
+	ms.AddHook(ProcessHookFunc(func(stage ProcessStage, ms *Modules) []error {
+		if stage == AfterAugments {
+			gone = ToEntry(ms.Modules["dv"]).Dir["c"].Dir["gone"]
+		}
+		return nil
+	}))
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	c := ToEntry(ms.Modules["dv"]).Dir["c"]
+	if c.Dir["gone"] != nil {
+		t.Fatal("gone was not removed")
+	}
+	describe := func(e *Entry) []string {
+		var s []string
+		for _, d := range e.DeviatedBy() {
+			s = append(s, fmt.Sprintf("%s %s %s %s: %s", d.Module.Name, Source(d.Deviation), Source(d.Deviate), d.Type, strings.Join(d.Changes, ", ")))
+		}
+		return s
+	}
+	for _, tt := range []struct {
+		desc string
+		e    *Entry
+		want []string
+	}{{
+		desc: "l",
+		e:    c.Dir["l"],
+		want: []string{
+			"dv-dev dv-dev.yang:5:3 dv-dev.yang:6:5 replace: type string -> int32",
+			"dv-dev dv-dev.yang:5:3 dv-dev.yang:7:5 add: config unset -> false",
+		},
+	}, {
+		desc: "ll",
+		e:    c.Dir["ll"],
+		want: []string{"dv-dev dv-dev.yang:9:3 dv-dev.yang:9:27 replace: max-elements 10 -> 5"},
+	}, {
+		desc: "parent of removed node",
+		e:    c,
+		want: []string{"dv-dev dv-dev.yang:10:3 dv-dev.yang:10:29 not-supported: removed gone"},
+	}, {
+		desc: "removed node",
+		e:    gone,
+		want: []string{"dv-dev dv-dev.yang:10:3 dv-dev.yang:10:29 not-supported: removed gone"},
+	}, {
+		desc: "not deviated",
+		e:    c.Dir["same"],
+	}} {
+		if diff := cmp.Diff(tt.want, describe(tt.e)); diff != "" {
+			t.Errorf("%s: DeviatedBy() (-want, +got):\n%s", tt.desc, diff)
+		}
+	}
+}
+
+func TestDeviateOrder(t *testing.T) {
+	// The deviate statements of a deviation are applied in the order they
+	// appear, even when statements of different types are interleaved.
+	// Applying all adds before the delete would leave config unset.
+	for i := 0; i < 10; i++ {
+		ms := NewModules()
+		for name, text := range map[string]string{
+			"do": `module do {
+  prefix "d";
+  namespace "urn:do";
+  container c {
+    leaf l { type string; units "s"; }
+  }
+}`,
+			"do-dev": `module do-dev {
+  prefix "dd";
+  namespace "urn:do-dev";
+  import do { prefix "d"; }
+  deviation "/d:c/d:l" {
+    deviate add { config false; }
+    deviate replace { units "m"; }
+    deviate delete { config false; }
+    deviate replace { type int32; }
+    deviate add { config false; }
+    deviate replace { units "km"; }
+  }
+}`,
+		} {
+			if err := ms.Parse(text, name+".yang"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if errs := ms.Process(); len(errs) > 0 {
+			t.Fatal(errs)
+		}
+		l := ToEntry(ms.Modules["do"]).Dir["c"].Dir["l"]
+		if l.Config != TSFalse || l.Units != "km" || l.Type.Kind != Yint32 {
+			t.Errorf("#%d: got config %v, units %q, type %v, want config false, units \"km\", type int32", i, l.Config, l.Units, l.Type.Kind)
+		}
+		var got []string
+		for _, d := range l.DeviatedBy() {
+			got = append(got, fmt.Sprintf("%s %s", Source(d.Deviate), d.Type))
+		}
+		want := []string{
+			"do-dev.yang:6:5 add",
+			"do-dev.yang:7:5 replace",
+			"do-dev.yang:8:5 delete",
+			"do-dev.yang:9:5 replace",
+			"do-dev.yang:10:5 add",
+			"do-dev.yang:11:5 replace",
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("#%d: DeviatedBy() (-want, +got):\n%s", i, diff)
+		}
+	}
+}
+
+func TestAugmentedBy(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"ab": `module ab {
+  prefix "a";
+  namespace "urn:ab";
+  container c {
+    leaf own { type string; }
+    choice ch;
+  }
+}`,
+		"ab-aug": `module ab-aug {
+  prefix "x";
+  namespace "urn:ab-aug";
+  import ab { prefix "a"; }
+  augment "/a:c" { container added { leaf l { type string; } } }
+  augment "/a:c/a:ch" { leaf in-choice { type string; } }
+}`,
+	} {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	e := ToEntry(ms.Modules["ab"])
+	for _, tt := range []struct {
+		path string
+		want string
+	}{
+		{"/c", ""},
+		{"/c/own", ""},
+		{"/c/added", "ab-aug ab-aug.yang:5:3 /a:c"},
+		{"/c/added/l", "ab-aug ab-aug.yang:5:3 /a:c"},
+		{"/c/ch/in-choice", "ab-aug ab-aug.yang:6:3 /a:c/a:ch"},
+		{"/c/ch/in-choice/in-choice", "ab-aug ab-aug.yang:6:3 /a:c/a:ch"},
+	} {
+		ce := e.Find(tt.path)
+		if ce == nil {
+			t.Errorf("%s not found", tt.path)
+			continue
+		}
+		got := ""
+		if a := ce.AugmentedBy(); a != nil {
+			got = a.ParentModule().Name + " " + Source(a) + " " + a.Name
+		}
+		if got != tt.want {
+			t.Errorf("%s: AugmentedBy() = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestAugmentChain(t *testing.T) {
+	// Each module augments the node added by the augment of the module
+	// after it, so the augments are applied in the reverse of the order
+	// of the modules, one in each pass.
+	const n = 12
+	ms := NewModules()
+	if err := ms.Parse(`module chain { prefix "c"; namespace "urn:chain"; container root; }`, "chain.yang"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		var imports, target strings.Builder
+		fmt.Fprintf(&imports, `import chain { prefix "c"; }`)
+		target.WriteString("/c:root")
+		for j := n - 1; j > i; j-- {
+			fmt.Fprintf(&imports, ` import m%02d { prefix "p%02d"; }`, j, j)
+			fmt.Fprintf(&target, "/p%02d:c%02d", j, j)
+		}
+		text := fmt.Sprintf(`module m%02d {
+  prefix "m";
+  namespace "urn:m%02d";
+  %s
+  augment "%s" { container c%02d { leaf l { type string; } } }
+}`, i, i, imports.String(), target.String(), i)
+		if err := ms.Parse(text, fmt.Sprintf("m%02d.yang", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	e := ToEntry(ms.Modules["chain"]).Dir["root"]
+	for i := n - 1; i >= 0; i-- {
+		name := fmt.Sprintf("c%02d", i)
+		if e = e.Dir[name]; e == nil {
+			t.Fatalf("%s not found", name)
+		}
+		if len(e.Parent.Augmented) != 1 {
+			t.Errorf("%s: got %d augments of its parent, want 1", name, len(e.Parent.Augmented))
+		}
+	}
+	if e.Dir["l"] == nil {
+		t.Errorf("leaf l not found in %s", e.Path())
+	}
+}
+
+func TestFindMissing(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`module fm {
+  prefix "f";
+  namespace "urn:fm";
+  container a { container b; }
+  rpc r { input { leaf i { type string; } } }
+}`, "fm.yang"); err != nil {
+		t.Fatal(err)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	root := ToEntry(ms.Modules["fm"])
+	for _, tt := range []struct {
+		path       string
+		wantFound  string
+		wantParent string
+		wantRest   []string
+	}{
+		{path: "/f:a/f:b", wantFound: "/fm/a/b"},
+		{path: "/f:a/f:b/f:c/f:d", wantParent: "/fm/a/b", wantRest: []string{"f:c", "f:d"}},
+		{path: "/f:x", wantParent: "/fm", wantRest: []string{"f:x"}},
+		{path: "/f:r/f:input/f:i", wantFound: "/fm/r/input/i"},
+		{path: "/f:r/f:input/f:j", wantParent: "/fm/r/input", wantRest: []string{"f:j"}},
+		// Only missing children are reported.
+		{path: "/f:a/../../..", wantParent: ""},
+		{path: "/g:a"},
+	} {
+		found, parent, rest := root.find(tt.path)
+		if got := found.Path(); got != tt.wantFound {
+			t.Errorf("%s: found %q, want %q", tt.path, got, tt.wantFound)
+		}
+		if got := parent.Path(); got != tt.wantParent {
+			t.Errorf("%s: got parent %q, want %q", tt.path, got, tt.wantParent)
+		}
+		if diff := cmp.Diff(tt.wantRest, rest); diff != "" {
+			t.Errorf("%s: rest (-want, +got):\n%s", tt.path, diff)
+		}
+		if parent != nil {
+			// The walk continues from the parent once the child has
+			// been added.
+			if f, _, _ := parent.walk(rest); f != nil {
+				t.Errorf("%s: walk from the parent found %s", tt.path, f.Path())
+			}
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the registry of extension handlers.  Handlers allow
+// known extensions to be decoded once, while processing, rather than by
+// every consumer of the Exts of nodes and entries.
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// An ExtensionHandler is called by Process for each use of the extension it
+// is registered for.  s is the extension statement and n is the node that s
+// was found in.  A non-nil error is reported as a processing error.
+//
+// Handlers are called after types and identities are resolved, and before
+// Entry trees are built.  Handlers are called each time Process is called.
+type ExtensionHandler func(s *Statement, n Node) error
+
+// extensionKey identifies an extension by the name of the module that
+// defines it and its name.
+type extensionKey struct {
+	module string
+	name   string
+}
+
+var extensionHandlers = struct {
+	mu sync.RWMutex
+	m  map[extensionKey]ExtensionHandler
+}{m: map[extensionKey]ExtensionHandler{}}
+
+// RegisterExtension registers handler to be called for each use of the
+// extension name defined in the module named module.  RegisterExtension
+// panics if handler is nil or a handler is already registered for the
+// extension.
+func RegisterExtension(module, name string, handler func(*Statement, Node) error) {
+	if handler == nil {
+		panic(fmt.Sprintf("RegisterExtension: nil handler for %s:%s", module, name))
+	}
+	k := extensionKey{module, name}
+	extensionHandlers.mu.Lock()
+	defer extensionHandlers.mu.Unlock()
+	if extensionHandlers.m[k] != nil {
+		panic(fmt.Sprintf("RegisterExtension: handler for %s:%s registered twice", module, name))
+	}
+	extensionHandlers.m[k] = handler
+}
+
+// extensionHandler returns the handler registered for ext, which is used in
+// n, or nil.
+func extensionHandler(ext *Statement, n Node) ExtensionHandler {
+	prefix, name := getPrefix(ext.Keyword)
+	if prefix == "" {
+		return nil
+	}
+	m := FindModuleByPrefix(n, prefix)
+	if m == nil {
+		return nil
+	}
+	extensionHandlers.mu.RLock()
+	defer extensionHandlers.mu.RUnlock()
+	return extensionHandlers.m[extensionKey{belongsTo(m).Name, name}]
+}
+
+// belongsTo returns the module that m belongs to, which is m itself unless
+// m is a submodule.
+func belongsTo(m *Module) *Module {
+	if m.Kind() == "submodule" && m.BelongsTo != nil && m.modules != nil {
+		if bm := m.modules.Modules[m.BelongsTo.Name]; bm != nil {
+			return bm
+		}
+	}
+	return m
+}
+
+// extensionFrom returns the name of extension ext, used in n, if ext is
+// defined by the module named module.  Otherwise "" is returned.
+func extensionFrom(ext *Statement, n Node, module string) string {
+	prefix, name := getPrefix(ext.Keyword)
+	if prefix == "" || RootNode(n) == nil {
+		return ""
+	}
+	if m := FindModuleByPrefix(n, prefix); m == nil || belongsTo(m).Name != module {
+		return ""
+	}
+	return name
+}
+
+// handleExtensions calls the registered extension handlers for all the
+// extensions used in the modules and submodules of ms.
+func (ms *Modules) handleExtensions() []error {
+	extensionHandlers.mu.RLock()
+	none := len(extensionHandlers.m) == 0
+	extensionHandlers.mu.RUnlock()
+	if none {
+		return nil
+	}
+
+	var errs []error
+	for _, m := range ms.sortedModules() {
+		walkNodes(m, func(n Node) {
+			for _, ext := range n.Exts() {
+				if h := extensionHandler(ext, n); h != nil {
+					if err := h(ext, n); err != nil {
+						errs = append(errs, errorf(ext, ErrBadExtension, "%s: %v", ext.Keyword, err))
+					}
+				}
+			}
+		})
+	}
+	return errs
+}
+
+// An ExtensionUse is a use of an extension returned by FindExtensionUses.
+type ExtensionUse struct {
+	Statement *Statement // the extension statement
+	Node      Node       // the node the extension is used in
+	Argument  string     // the argument of the extension statement, if any
+}
+
+// FindExtensionUses returns each use of the extension name, defined in the
+// module named module, in the modules and submodules of ms.  The uses are
+// ordered by the full name of the module they are found in and then by
+// their position in the module.  Extensions used within the arguments of
+// other extensions are not found.
+func (ms *Modules) FindExtensionUses(module, name string) []ExtensionUse {
+	var uses []ExtensionUse
+	for _, m := range ms.sortedModules() {
+		start := len(uses)
+		walkNodes(m, func(n Node) {
+			for _, ext := range n.Exts() {
+				if extensionFrom(ext, n, module) == name {
+					uses = append(uses, ExtensionUse{Statement: ext, Node: n, Argument: ext.Argument})
+				}
+			}
+		})
+		mu := uses[start:]
+		sort.SliceStable(mu, func(i, j int) bool { return before(mu[i].Statement, mu[j].Statement) })
+	}
+	return uses
+}
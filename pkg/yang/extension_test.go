@@ -0,0 +1,182 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+// unregisterExtension removes the handler for module:name, it is used to
+// clean up after tests.
+func unregisterExtension(module, name string) {
+	extensionHandlers.mu.Lock()
+	delete(extensionHandlers.m, extensionKey{module, name})
+	extensionHandlers.mu.Unlock()
+}
+
+func TestRegisterExtension(t *testing.T) {
+	var got []string
+	RegisterExtension("ext-defs", "note", func(s *Statement, n Node) error {
+		if s.Argument == "bad" {
+			return errors.New("bad note")
+		}
+		got = append(got, n.Kind()+" "+n.NName()+": "+s.Argument)
+		return nil
+	})
+	defer unregisterExtension("ext-defs", "note")
+
+	mods := map[string]string{
+		"ext-defs": `
+			module ext-defs {
+				prefix "d";
+				namespace "urn:d";
+				extension note { argument "text"; }
+				extension other { argument "text"; }
+			}
+		`,
+		"ext-sub": `
+			submodule ext-sub {
+				belongs-to ext-use { prefix "u"; }
+				import ext-defs { prefix "xd"; }
+				leaf s { type string; xd:note "in submodule"; }
+			}
+		`,
+		"ext-use": `
+			module ext-use {
+				prefix "u";
+				namespace "urn:u";
+				import ext-defs { prefix "x"; }
+				include ext-sub;
+				x:note "on module";
+				grouping g { leaf gl { type string; x:note "in grouping"; } }
+				container c {
+					x:note "on container";
+					x:other "not handled";
+					leaf l { type string { x:note "on type"; } }
+					uses g;
+				}
+			}
+		`,
+	}
+	ms := NewModules()
+	for name, text := range mods {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatalf("cannot parse %s: %v", name, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	want := []string{
+		"leaf s: in submodule",
+		"module ext-use: on module",
+		"container c: on container",
+		"type string: on type",
+		"leaf gl: in grouping",
+	}
+	if diff := cmp.Diff(want, got, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+		t.Errorf("handled extensions (-want, +got):\n%s", diff)
+	}
+
+	// Errors returned by handlers are processing errors.
+	ms = NewModules()
+	for name, text := range mods {
+		if name == "ext-use" {
+			text = strings.Replace(text, `"on container"`, `"bad"`, 1)
+		}
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatalf("cannot parse %s: %v", name, err)
+		}
+	}
+	var err error
+	if errs := ms.Process(); len(errs) > 0 {
+		err = errs[0]
+	}
+	if diff := errdiff.Substring(err, "x:note: bad note"); diff != "" {
+		t.Error(diff)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("registering a handler twice did not panic")
+		}
+	}()
+	RegisterExtension("ext-defs", "note", func(*Statement, Node) error { return nil })
+}
+
+func TestFindExtensionUses(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"fx-defs": `module fx-defs {
+  prefix "d";
+  namespace "urn:d";
+  extension note { argument "text"; }
+  extension flag;
+  d:note "on the module";
+}`,
+		"fx-use": `module fx-use {
+  prefix "u";
+  namespace "urn:u";
+  import fx-defs { prefix "fx"; }
+  include fx-sub;
+  container c {
+    fx:flag;
+    leaf l { type string; fx:note "on a leaf"; }
+  }
+  typedef t { type string; fx:note "on a typedef"; }
+}`,
+		"fx-sub": `submodule fx-sub {
+  belongs-to fx-use { prefix "u"; }
+  import fx-defs { prefix "d"; }
+  leaf s { type string { d:note "on a type"; } }
+}`,
+	} {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	uses := func(module, name string) []string {
+		var s []string
+		for _, u := range ms.FindExtensionUses(module, name) {
+			s = append(s, Source(u.Statement)+" "+StatementPath(u.Node)+": "+u.Argument)
+		}
+		return s
+	}
+	want := []string{
+		"fx-defs.yang:6:3 module fx-defs: on the module",
+		"fx-sub.yang:4:26 submodule fx-sub / leaf s / type string: on a type",
+		"fx-use.yang:8:27 module fx-use / container c / leaf l: on a leaf",
+		"fx-use.yang:10:28 module fx-use / typedef t: on a typedef",
+	}
+	if diff := cmp.Diff(want, uses("fx-defs", "note")); diff != "" {
+		t.Errorf("note (-want, +got):\n%s", diff)
+	}
+	want = []string{"fx-use.yang:7:5 module fx-use / container c: "}
+	if diff := cmp.Diff(want, uses("fx-defs", "flag")); diff != "" {
+		t.Errorf("flag (-want, +got):\n%s", diff)
+	}
+	if got := uses("fx-use", "note"); got != nil {
+		t.Errorf("fx-use:note: got %v, want nil", got)
+	}
+}
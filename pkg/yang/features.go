@@ -0,0 +1,414 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements listing the features of a set of modules and
+// evaluating their if-feature statements (RFC 7950 section 7.20.2), which
+// is used to prune the entries of disabled features.
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// A FeatureInfo describes a feature returned by Modules.Features.
+type FeatureInfo struct {
+	Feature *Feature
+	// Module is the module the feature belongs to.  RootNode(Feature)
+	// returns the module or submodule it is defined in.
+	Module *Module
+	// DependsOn lists the features referenced by the if-feature
+	// statements of the feature, as "module:feature", sorted by name.
+	DependsOn []string
+
+	exprs []*featureExpr
+}
+
+// Name returns the name of the feature qualified by the name of its
+// module, e.g., "ietf-interfaces:if-mib".
+func (f *FeatureInfo) Name() string {
+	return f.Module.Name + ":" + f.Feature.Name
+}
+
+// Features returns all the features defined in the modules and submodules
+// of ms, sorted by their qualified names.  Errors are returned for
+// if-feature statements that cannot be parsed or that reference features
+// using unknown prefixes.  The modules of ms should have been processed,
+// otherwise references to features of other modules cannot be resolved.
+func (ms *Modules) Features() ([]*FeatureInfo, []error) {
+	var fs []*FeatureInfo
+	var errs []error
+	for _, m := range ms.sortedModules() {
+		for _, f := range m.Feature {
+			fi := &FeatureInfo{Feature: f, Module: belongsTo(m)}
+			deps := map[string]bool{}
+			for _, v := range f.IfFeature {
+				x, err := parseFeatureExpr(v, v.Name)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				fi.exprs = append(fi.exprs, x)
+				x.features(deps)
+			}
+			for d := range deps {
+				fi.DependsOn = append(fi.DependsOn, d)
+			}
+			sort.Strings(fi.DependsOn)
+			fs = append(fs, fi)
+		}
+	}
+	sort.Slice(fs, func(i, j int) bool { return fs[i].Name() < fs[j].Name() })
+	return fs, errs
+}
+
+// ActiveFeatures returns the features that are active when the features
+// named by enabled, as "module:feature", are enabled.  Enabling a feature
+// also enables the features its if-feature statements require, i.e., the
+// features they reference that are not part of an "or" or "not"
+// expression, and so on.  Features whose if-feature statements are still
+// false, e.g., because they reference a feature using "not", are not
+// active, nor are the features that depend on them.  The active features
+// are returned sorted by name.  An error is returned if enabled names an
+// unknown feature or if the features of ms cannot be listed.
+func (ms *Modules) ActiveFeatures(enabled []string) ([]string, error) {
+	fs, errs := ms.Features()
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	known := map[string]*FeatureInfo{}
+	for _, f := range fs {
+		known[f.Name()] = f
+	}
+	active := map[string]bool{}
+	for _, name := range enabled {
+		if known[name] == nil {
+			return nil, fmt.Errorf("unknown feature %s", name)
+		}
+		active[name] = true
+	}
+
+	// Enable required features until no more are added, then disable
+	// features that are not satisfied until no more are removed.
+	for changed := true; changed; {
+		changed = false
+		for _, f := range fs {
+			if !active[f.Name()] {
+				continue
+			}
+			for _, x := range f.exprs {
+				for _, name := range x.required(active) {
+					if known[name] != nil && !active[name] {
+						active[name] = true
+						changed = true
+					}
+				}
+			}
+		}
+	}
+	disableUnsatisfied(fs, active)
+
+	var names []string
+	for name := range active {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// disableUnsatisfied removes the features whose if-feature statements are
+// false from active, until no more are removed.
+func disableUnsatisfied(fs []*FeatureInfo, active map[string]bool) {
+	for changed := true; changed; {
+		changed = false
+		for _, f := range fs {
+			if !active[f.Name()] {
+				continue
+			}
+			for _, x := range f.exprs {
+				if !x.eval(active) {
+					delete(active, f.Name())
+					changed = true
+					break
+				}
+			}
+		}
+	}
+}
+
+// PruneFeatures returns a Transform that removes the entries whose
+// if-feature statements are false, along with their descendants.  The
+// features are selected as by pyang's --features option: if enabled names
+// any feature of a module, as "module:feature", only the named features of
+// that module are enabled, and "module:" enables none of them.  All the
+// features of the other modules are enabled.  The features required by the
+// enabled features are enabled as described by ActiveFeatures.  Finally,
+// the features named by disabled, and those that depend on them, are
+// disabled.
+//
+// The if-feature statements of an entry's node, of the uses statements
+// that instantiated it, and of the augment that added it are all
+// evaluated.  It is an error to name an unknown module or feature.
+func PruneFeatures(enabled, disabled []string) Transform {
+	return TransformFunc(func(e *Entry) error {
+		ms := e.Modules()
+		if ms == nil {
+			return fmt.Errorf("%s is not part of a Modules", e.Name)
+		}
+		active, err := ms.selectFeatures(enabled, disabled)
+		if err != nil {
+			return err
+		}
+		var errs []error
+		strip := StripNodes(func(e *Entry) bool {
+			ok, err := featuresEnabled(e, active)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			return !ok
+		})
+		strip.Transform(e)
+		if len(errs) > 0 {
+			return errs[0]
+		}
+		return nil
+	})
+}
+
+// selectFeatures returns the features of ms that are active when they are
+// selected by enabled and disabled as described by PruneFeatures.
+func (ms *Modules) selectFeatures(enabled, disabled []string) (map[string]bool, error) {
+	fs, errs := ms.Features()
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	known := map[string]bool{}
+	for _, f := range fs {
+		known[f.Name()] = true
+	}
+	listed := map[string]bool{} // modules named by enabled
+	var start []string
+	for _, name := range enabled {
+		x := strings.Index(name, ":")
+		if x < 0 {
+			return nil, fmt.Errorf("feature %s is not qualified by its module name", name)
+		}
+		mod := name[:x]
+		if ms.Modules[mod] == nil {
+			return nil, fmt.Errorf("unknown module %s in feature %s", mod, name)
+		}
+		listed[mod] = true
+		if name[x+1:] != "" {
+			start = append(start, name)
+		}
+	}
+	for _, f := range fs {
+		if !listed[f.Module.Name] {
+			start = append(start, f.Name())
+		}
+	}
+	names, err := ms.ActiveFeatures(start)
+	if err != nil {
+		return nil, err
+	}
+	active := map[string]bool{}
+	for _, name := range names {
+		active[name] = true
+	}
+	for _, name := range disabled {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown feature %s", name)
+		}
+		delete(active, name)
+	}
+	disableUnsatisfied(fs, active)
+	return active, nil
+}
+
+// featuresEnabled reports whether all the if-feature statements that apply
+// to e are true when the features in active are enabled.
+func featuresEnabled(e *Entry, active map[string]bool) (bool, error) {
+	nodes := []Node{e.Node}
+	for _, u := range e.usedAt {
+		nodes = append(nodes, u)
+	}
+	if e.augmentedBy != nil {
+		nodes = append(nodes, e.augmentedBy)
+	}
+	for _, n := range nodes {
+		for _, v := range ifFeatures(n) {
+			x, err := parseFeatureExpr(v, v.Name)
+			if err != nil {
+				return true, err
+			}
+			if !x.eval(active) {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// ifFeatures returns the if-feature statements of n.
+func ifFeatures(n Node) []*Value {
+	v := reflect.ValueOf(n)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil
+	}
+	if f := v.Elem().FieldByName("IfFeature"); f.IsValid() {
+		if vs, ok := f.Interface().([]*Value); ok {
+			return vs
+		}
+	}
+	return nil
+}
+
+// A featureExpr is a parsed if-feature expression.
+type featureExpr struct {
+	op   string // "and", "or", "not", or "" for a feature
+	name string // the qualified name of the feature when op is ""
+	args []*featureExpr
+}
+
+// eval returns the value of x when the features in active are enabled.
+func (x *featureExpr) eval(active map[string]bool) bool {
+	switch x.op {
+	case "and":
+		return x.args[0].eval(active) && x.args[1].eval(active)
+	case "or":
+		return x.args[0].eval(active) || x.args[1].eval(active)
+	case "not":
+		return !x.args[0].eval(active)
+	}
+	return active[x.name]
+}
+
+// features adds the names of the features referenced by x to names.
+func (x *featureExpr) features(names map[string]bool) {
+	if x.op == "" {
+		names[x.name] = true
+	}
+	for _, a := range x.args {
+		a.features(names)
+	}
+}
+
+// required returns the features that must be enabled for x to be true,
+// and which are not already in active.  Features that are part of an
+// "or" or "not" expression are not required.
+func (x *featureExpr) required(active map[string]bool) []string {
+	switch x.op {
+	case "":
+		if !active[x.name] {
+			return []string{x.name}
+		}
+	case "and":
+		return append(x.args[0].required(active), x.args[1].required(active)...)
+	}
+	return nil
+}
+
+// parseFeatureExpr parses the if-feature expression s, found at n.  The
+// names of the features in s are resolved relative to n.
+func parseFeatureExpr(n Node, s string) (*featureExpr, error) {
+	p := &featureParser{
+		n:      n,
+		s:      s,
+		tokens: strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(s)),
+	}
+	x, err := p.or()
+	if err == nil && len(p.tokens) > 0 {
+		err = p.errorf("unexpected %q", p.tokens[0])
+	}
+	return x, err
+}
+
+// A featureParser parses if-feature expressions:
+//
+//   expr   = term ["or" expr]
+//   term   = factor ["and" term]
+//   factor = "not" factor | "(" expr ")" | identifier-ref
+type featureParser struct {
+	n      Node
+	s      string
+	tokens []string
+}
+
+func (p *featureParser) errorf(format string, v ...interface{}) error {
+	return errorf(p.n, ErrInvalidArgument, "invalid if-feature %q: %s", p.s, fmt.Sprintf(format, v...))
+}
+
+func (p *featureParser) next() string {
+	if len(p.tokens) == 0 {
+		return ""
+	}
+	return p.tokens[0]
+}
+
+func (p *featureParser) binary(op string, operand func() (*featureExpr, error), rest func() (*featureExpr, error)) (*featureExpr, error) {
+	x, err := operand()
+	if err != nil || p.next() != op {
+		return x, err
+	}
+	p.tokens = p.tokens[1:]
+	y, err := rest()
+	if err != nil {
+		return nil, err
+	}
+	return &featureExpr{op: op, args: []*featureExpr{x, y}}, nil
+}
+
+func (p *featureParser) or() (*featureExpr, error) {
+	return p.binary("or", p.and, p.or)
+}
+
+func (p *featureParser) and() (*featureExpr, error) {
+	return p.binary("and", p.factor, p.and)
+}
+
+func (p *featureParser) factor() (*featureExpr, error) {
+	tok := p.next()
+	if tok == "" {
+		return nil, p.errorf("unexpected end of expression")
+	}
+	p.tokens = p.tokens[1:]
+	switch tok {
+	case "not":
+		x, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		return &featureExpr{op: "not", args: []*featureExpr{x}}, nil
+	case "(":
+		x, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, p.errorf("missing )")
+		}
+		p.tokens = p.tokens[1:]
+		return x, nil
+	case ")", "and", "or":
+		return nil, p.errorf("unexpected %q", tok)
+	}
+	m, name, err := ResolvePrefixedName(p.n, tok)
+	if err != nil {
+		return nil, err
+	}
+	return &featureExpr{name: m.Name + ":" + name}, nil
+}
@@ -0,0 +1,241 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+func TestFeatures(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"f": `module f {
+  yang-version 1.1;
+  prefix "f";
+  namespace "urn:f";
+  import r { prefix "rp"; }
+  include f-sub;
+  feature a;
+  feature b { if-feature a; }
+  feature c { if-feature "b and rp:remote"; }
+  feature d { if-feature "not a"; }
+  feature e { if-feature "a or d"; }
+  feature g { if-feature "(b or d) and not rp:remote"; }
+}`,
+		"f-sub": `submodule f-sub {
+  yang-version 1.1;
+  belongs-to f { prefix "f"; }
+  feature s { if-feature f:c; }
+}`,
+		"r": `module r { prefix "r"; namespace "urn:r"; feature remote; }`,
+	} {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	fs, errs := ms.Features()
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	got := map[string][]string{}
+	for _, f := range fs {
+		got[f.Name()+" "+RootNode(f.Feature).Name] = f.DependsOn
+	}
+	want := map[string][]string{
+		"f:a f":      nil,
+		"f:b f":      {"f:a"},
+		"f:c f":      {"f:b", "r:remote"},
+		"f:d f":      {"f:a"},
+		"f:e f":      {"f:a", "f:d"},
+		"f:g f":      {"f:b", "f:d", "r:remote"},
+		"f:s f-sub":  {"f:c"},
+		"r:remote r": nil,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Features() (-want, +got):\n%s", diff)
+	}
+
+	for _, tt := range []struct {
+		enabled []string
+		want    []string
+		wantErr string
+	}{
+		{enabled: nil, want: nil},
+		{enabled: []string{"f:a"}, want: []string{"f:a"}},
+		{enabled: []string{"f:c"}, want: []string{"f:a", "f:b", "f:c", "r:remote"}},
+		{enabled: []string{"f:s"}, want: []string{"f:a", "f:b", "f:c", "f:s", "r:remote"}},
+		{enabled: []string{"f:d"}, want: []string{"f:d"}},
+		{enabled: []string{"f:a", "f:d"}, want: []string{"f:a"}},
+		{enabled: []string{"f:e"}, want: nil},
+		{enabled: []string{"f:e", "f:d"}, want: []string{"f:d", "f:e"}},
+		{enabled: []string{"f:g", "f:b"}, want: []string{"f:a", "f:b", "f:g"}},
+		{enabled: []string{"f:g", "f:c"}, want: []string{"f:a", "f:b", "f:c", "r:remote"}},
+		{enabled: []string{"f:x"}, wantErr: "unknown feature f:x"},
+	} {
+		got, err := ms.ActiveFeatures(tt.enabled)
+		if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+			t.Errorf("ActiveFeatures(%v): %s", tt.enabled, diff)
+			continue
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("ActiveFeatures(%v) (-want, +got):\n%s", tt.enabled, diff)
+		}
+	}
+}
+
+func TestPruneFeatures(t *testing.T) {
+	mods := map[string]string{
+		"pf": `module pf {
+  prefix "pf";
+  namespace "urn:pf";
+  feature a;
+  feature b { if-feature a; }
+  grouping g {
+    leaf gl { type string; }
+  }
+  container c {
+    leaf always { type string; }
+    leaf la { if-feature a; type string; }
+    leaf lb { if-feature b; type string; }
+    leaf lnot { if-feature "not a"; type string; }
+    uses g { if-feature b; }
+  }
+  augment /pf:c {
+    if-feature a;
+    leaf aug { type string; }
+  }
+}`,
+		"po": `module po {
+  prefix "po";
+  namespace "urn:po";
+  feature x;
+  leaf lx { if-feature x; type string; }
+}`,
+	}
+
+	for _, tt := range []struct {
+		desc     string
+		enabled  []string
+		disabled []string
+		want     []string
+		wantErr  string
+	}{{
+		desc: "all enabled",
+		want: []string{"c/always", "c/la", "c/lb", "c/gl", "c/aug", "lx"},
+	}, {
+		desc:    "none of pf",
+		enabled: []string{"pf:"},
+		want:    []string{"c/always", "c/lnot", "lx"},
+	}, {
+		desc:    "b requires a",
+		enabled: []string{"pf:b", "po:"},
+		want:    []string{"c/always", "c/la", "c/lb", "c/gl", "c/aug"},
+	}, {
+		desc:     "disabling a disables b",
+		disabled: []string{"pf:a"},
+		want:     []string{"c/always", "c/lnot", "lx"},
+	}, {
+		desc:    "unknown module",
+		enabled: []string{"nope:a"},
+		wantErr: "unknown module nope",
+	}, {
+		desc:     "unknown feature",
+		disabled: []string{"pf:z"},
+		wantErr:  "unknown feature pf:z",
+	}} {
+		t.Run(tt.desc, func(t *testing.T) {
+			ms := NewModules()
+			for name, text := range mods {
+				if err := ms.Parse(text, name+".yang"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			ms.AddTransform(PruneFeatures(tt.enabled, tt.disabled))
+			errs := ms.Process()
+			var err error
+			if len(errs) > 0 {
+				err = errs[0]
+			}
+			if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+				t.Fatalf("Process: %s", diff)
+			}
+			if err != nil {
+				return
+			}
+			all := []string{"c/always", "c/la", "c/lb", "c/lnot", "c/gl", "c/aug", "lx"}
+			want := map[string]bool{}
+			for _, p := range tt.want {
+				want[p] = true
+			}
+			for _, p := range all {
+				e := ToEntry(ms.Modules["pf"]).Find(p)
+				if p == "lx" {
+					e = ToEntry(ms.Modules["po"]).Find(p)
+				}
+				if got := e != nil; got != want[p] {
+					t.Errorf("%s: got present %v, want %v", p, got, want[p])
+				}
+			}
+		})
+	}
+}
+
+func TestParseFeatureExpr(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`module p { prefix "p"; namespace "urn:p"; }`, "p.yang"); err != nil {
+		t.Fatal(err)
+	}
+	m := ms.Modules["p"]
+	for _, tt := range []struct {
+		in      string
+		active  []string
+		want    bool
+		wantErr string
+	}{
+		{in: "x", active: []string{"p:x"}, want: true},
+		{in: "p:x", want: false},
+		{in: "x and y or z", active: []string{"p:z"}, want: true},
+		{in: "x and (y or z)", active: []string{"p:z"}, want: false},
+		{in: "not not x", active: []string{"p:x"}, want: true},
+		{in: "(x", wantErr: "missing )"},
+		{in: "x y", wantErr: `unexpected "y"`},
+		{in: "x and", wantErr: "unexpected end of expression"},
+		{in: "or x", wantErr: `unexpected "or"`},
+		{in: "q:x", wantErr: "unknown prefix q"},
+	} {
+		x, err := parseFeatureExpr(m, tt.in)
+		if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+			t.Errorf("%q: %s", tt.in, diff)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		active := map[string]bool{}
+		for _, a := range tt.active {
+			active[a] = true
+		}
+		if got := x.eval(active); got != tt.want {
+			t.Errorf("%q with %v: got %v, want %v", tt.in, tt.active, got, tt.want)
+		}
+	}
+}
@@ -16,36 +16,73 @@ package yang
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 )
 
-// TODO(borman): encapsulate all of this someday so you can parse
-// two completely independent yang files with different Paths.
-
-// Path is the list of directories to look for .yang files in.
+// Path is the default list of directories to look for .yang files in.
+// NewModules copies it to the search path of the new Modules.
+//
+// Deprecated: use the AddPath and Path methods of Modules.
 var Path []string
 var pathMap = map[string]bool{} // prevent adding dups in Path
 
+// pathMu protects Path and pathMap.
+var pathMu sync.Mutex
+
 // AddPath adds the directories specified in p, a colon separated list
 // of directory names, to Path, if they are not already in Path. Using
 // multiple arguments is also supported.
+//
+// Deprecated: use the AddPath method of Modules.
 func AddPath(paths ...string) {
+	pathMu.Lock()
+	defer pathMu.Unlock()
+	Path = addPaths(Path, pathMap, paths)
+}
+
+// addPaths returns dirs with the directories in paths, each a colon
+// separated list of directory names, that are not in seen appended.  The
+// appended directories are added to seen.
+func addPaths(dirs []string, seen map[string]bool, paths []string) []string {
 	for _, path := range paths {
 		for _, p := range strings.Split(path, ":") {
-			if !pathMap[p] {
-				pathMap[p] = true
-				Path = append(Path, p)
+			if !seen[p] {
+				seen[p] = true
+				dirs = append(dirs, p)
 			}
 		}
 	}
+	return dirs
+}
+
+// AddPath adds the directories specified in paths, each a colon separated
+// list of directory names, to the search path of ms, if they are not
+// already in it.  The search path is where Read, and the processing of
+// import and include statements, look for modules.
+func (ms *Modules) AddPath(paths ...string) {
+	ms.pathMu.Lock()
+	defer ms.pathMu.Unlock()
+	ms.path = addPaths(ms.path, ms.pathMap, paths)
+}
+
+// Path returns the search path of ms.  A directory is added to it by
+// AddPath, and by Read when a module is found in that directory without
+// searching.
+func (ms *Modules) Path() []string {
+	ms.pathMu.Lock()
+	defer ms.pathMu.Unlock()
+	return append([]string(nil), ms.path...)
 }
 
 // PathsWithModules returns all paths under and including the
-// root containing files with a ".yang" extension, as well as
+// root containing files with a ".yang" or ".yin" extension, as well as
 // any error encountered
 func PathsWithModules(root string) (paths []string, err error) {
 	pm := map[string]bool{}
@@ -55,7 +92,7 @@ func PathsWithModules(root string) (paths []string, err error) {
 			if info == nil {
 				return nil
 			}
-			if !info.IsDir() && strings.HasSuffix(p, ".yang") {
+			if !info.IsDir() && (strings.HasSuffix(p, ".yang") || strings.HasSuffix(p, ".yin")) {
 				dir := filepath.Dir(p)
 				if !pm[dir] {
 					pm[dir] = true
@@ -70,44 +107,122 @@ func PathsWithModules(root string) (paths []string, err error) {
 }
 
 // readFile makes testing of findFile easier.
-var readFile = ioutil.ReadFile
+var readFile = readLimitedFile
+
+// A fileSizeError is returned when a file is larger than the MaxFileSize
+// option.
+type fileSizeError struct {
+	name string
+	max  int
+}
+
+func (e *fileSizeError) Error() string {
+	return fmt.Sprintf("%s: file is larger than the maximum of %d bytes", e.name, e.max)
+}
+
+// readLimitedFile returns the contents of the named file.  It returns a
+// *fileSizeError, without reading the entire file, if the file is larger
+// than max bytes.  A max of 0 means no limit.
+func readLimitedFile(name string, max int) ([]byte, error) {
+	if max <= 0 {
+		return ioutil.ReadFile(name)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(f, int64(max)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > max {
+		return nil, &fileSizeError{name: name, max: max}
+	}
+	return data, nil
+}
 
 // scanDir makes testing of findFile easier.
 var scanDir = findInDir
 
-// findFile returns the name and contents of the .yang file associated with
-// name, or an error.  If name is a module name rather than a file name (it does
-// not have a .yang extension and there is no / in name), .yang is appended to
-// the the name.  The directory that the .yang file is found in is added to Path
-// if not already in Path. If a file is not found by exact match, directories
-// are scanned for "name@revision-date.yang" files, the latest (sorted by
+// findFile returns the name and contents of the .yang or .yin file
+// associated with name, or an error.  If name is a module name rather than a
+// file name (it does not have a .yang or .yin extension and there is no / in
+// name), .yang is appended to the the name, and, if no such file is found,
+// .yin.  The directory that the file is found in is added to the search
+// path of ms, see AddPath. If a file is not found by exact match, directories are
+// scanned for "name@revision-date.yang" files, the latest (sorted by
 // YYYY-MM-DD revision-date) of these will be selected.
 //
 // If a path has the form dir/... then dir and all direct or indirect
 // subdirectories of dir are searched.
 //
-// The current directory (.) is always checked first, no matter the value of
-// Path.
-func findFile(name string) (string, string, error) {
+// The current directory (.) is always checked first, no matter the search
+// path.
+func (ms *Modules) findFile(name string) (string, string, error) {
+	if strings.Contains(name, "/") || strings.HasSuffix(name, ".yang") || strings.HasSuffix(name, ".yin") {
+		return ms.findSource(name, false)
+	}
+	fname, data, err := ms.findSource(name+".yang", true)
+	if ErrorCode(err) == ErrFileNotFound {
+		if yname, ydata, yerr := ms.findSource(name+".yin", true); yerr == nil {
+			return yname, ydata, nil
+		}
+	}
+	return fname, data, err
+}
+
+// foundDir returns the directory that findFile adds to the search path
+// when it finds name without searching, or "" if it would not.
+func foundDir(name string) string {
+	exists := func(name string) bool {
+		fi, err := os.Stat(name)
+		return err == nil && fi.Mode().IsRegular()
+	}
+	if strings.Contains(name, "/") || strings.HasSuffix(name, ".yang") || strings.HasSuffix(name, ".yin") {
+		if exists(name) {
+			return filepath.Dir(name)
+		}
+		return ""
+	}
+	for _, ext := range []string{".yang", ".yin"} {
+		n := name + ext
+		if best := scanDir(".", n, false); best != "" {
+			n = best
+		}
+		if exists(n) {
+			return filepath.Dir(n)
+		}
+	}
+	return ""
+}
+
+// findSource returns the name and contents of the file name, as described
+// by findFile.  If scan is set, the current directory is first scanned for
+// name.
+func (ms *Modules) findSource(name string, scan bool) (string, string, error) {
 	slash := strings.Index(name, "/")
-	if slash < 0 && !strings.HasSuffix(name, ".yang") {
-		name += ".yang"
+	if scan {
 		if best := scanDir(".", name, false); best != "" {
 			// we found a matching candidate in the local directory
 			name = best
 		}
 	}
 
-	switch data, err := readFile(name); true {
-	case err == nil:
-		AddPath(filepath.Dir(name))
+	max := ms.opts.MaxFileSize
+	switch data, err := readFile(name, max); err.(type) {
+	case nil:
+		ms.AddPath(filepath.Dir(name))
 		return name, string(data), nil
-	case slash >= 0:
-		// If there are any /'s in the name then don't search Path.
-		return "", "", fmt.Errorf("no such file: %s", name)
+	case *fileSizeError:
+		return "", "", err
+	}
+	if slash >= 0 {
+		// If there are any /'s in the name then don't search the path.
+		return "", "", errorf(nil, ErrFileNotFound, "no such file: %s", name)
 	}
 
-	for _, dir := range Path {
+	for _, dir := range ms.Path() {
 		var n string
 		if filepath.Base(dir) == "..." {
 			n = scanDir(filepath.Dir(dir), name, true)
@@ -117,11 +232,14 @@ func findFile(name string) (string, string, error) {
 		if n == "" {
 			continue
 		}
-		if data, err := readFile(n); err == nil {
+		switch data, err := readFile(n, max); err.(type) {
+		case nil:
 			return n, string(data), nil
+		case *fileSizeError:
+			return "", "", err
 		}
 	}
-	return "", "", fmt.Errorf("no such file: %s", name)
+	return "", "", errorf(nil, ErrFileNotFound, "no such file: %s", name)
 }
 
 // findInDir looks for a file named name in dir or any of its subdirectories if
@@ -132,9 +250,9 @@ func findInDir(dir, name string, recurse bool) string {
 		return ""
 	}
 	var candidates []string
-	mname := name
-	if strings.HasSuffix(mname, ".yang") {
-		mname = name[:len(name)-len(".yang")]
+	mname, ext := name, ".yang"
+	if e := filepath.Ext(name); e == ".yang" || e == ".yin" {
+		mname, ext = name[:len(name)-len(e)], e
 	}
 
 	for _, fi := range fis {
@@ -144,7 +262,7 @@ func findInDir(dir, name string, recurse bool) string {
 				return filepath.Join(dir, name)
 			} else if !strings.Contains(name, "@") {
 				// the query had no revision-date so look for candidate revisions
-				if strings.HasPrefix(fn, mname+"@") && strings.HasSuffix(fn, ".yang") {
+				if strings.HasPrefix(fn, mname+"@") && strings.HasSuffix(fn, ext) {
 					candidates = append(candidates, fn)
 				}
 			}
@@ -163,3 +281,39 @@ func findInDir(dir, name string, recurse bool) string {
 	sort.Strings(candidates)
 	return filepath.Join(dir, candidates[len(candidates)-1])
 }
+
+// sourceNames returns the file names that name, a module name or the name
+// of a file without a directory, is looked for as, in the order they are
+// tried.
+func sourceNames(name string) []string {
+	if ext := path.Ext(name); ext == ".yang" || ext == ".yin" {
+		return []string{name}
+	}
+	return []string{name + ".yang", name + ".yin"}
+}
+
+// matchPath returns the first of the slash separated paths whose base name
+// is name.  If there is none and name has no revision, the path of the
+// latest revision of name is returned, as by findInDir.  It returns "" if
+// there is neither.
+func matchPath(paths []string, name string) string {
+	ext := path.Ext(name)
+	mname := strings.TrimSuffix(name, ext)
+	var candidates []string
+	for _, p := range paths {
+		base := path.Base(p)
+		switch {
+		case base == name:
+			return p
+		case !strings.Contains(name, "@") && strings.HasPrefix(base, mname+"@") && strings.HasSuffix(base, ext):
+			candidates = append(candidates, p)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	// The revision-date in a file name (RFC 7950 section 5.2) sorts in
+	// the order of the revisions.
+	sort.Slice(candidates, func(i, j int) bool { return path.Base(candidates[i]) < path.Base(candidates[j]) })
+	return candidates[len(candidates)-1]
+}
@@ -16,7 +16,6 @@ package yang
 
 import (
 	"errors"
-	"io/ioutil"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -41,7 +40,7 @@ func TestFindFile(t *testing.T) {
 	}{
 		{
 			name:  "one",
-			check: []string{"one.yang"},
+			check: []string{"one.yang", "one.yin"},
 		},
 		{
 			name:  "./two",
@@ -51,22 +50,30 @@ func TestFindFile(t *testing.T) {
 			name:  "three.yang",
 			check: []string{"three.yang"},
 		},
+		{
+			name:  "three.yin",
+			check: []string{"three.yin"},
+		},
 		{
 			name:  "four",
 			path:  []string{"dir1", "dir2"},
-			check: []string{"four.yang", "dir1" + sep + "four.yang", "dir2" + sep + "four.yang"},
+			check: []string{
+				"four.yang", "dir1" + sep + "four.yang", "dir2" + sep + "four.yang",
+				"four.yin", "dir1" + sep + "four.yin", "dir2" + sep + "four.yin",
+			},
 		},
 	} {
 		var checked []string
-		Path = tt.path
-		readFile = func(path string) ([]byte, error) {
+		ms := NewModulesWithOptions(Options{})
+		ms.AddPath(tt.path...)
+		readFile = func(path string, _ int) ([]byte, error) {
 			checked = append(checked, path)
 			return nil, errors.New("no such file")
 		}
 		scanDir = func(dir, name string, recurse bool) string {
 			return filepath.Join(dir, name)
 		}
-		if _, _, err := findFile(tt.name); err == nil {
+		if _, _, err := ms.findFile(tt.name); err == nil {
 			t.Errorf("%s unexpectedly succeeded", tt.name)
 			continue
 		}
@@ -81,7 +88,7 @@ func TestScanForPathsAndAddModules(t *testing.T) {
 	defer testPathReset()
 
 	// disable any readFile mock setup by other tests
-	readFile = ioutil.ReadFile
+	readFile = readLimitedFile
 
 	// Scan the directory tree for YANG modules
 	paths, err := PathsWithModules("../../testdata")
@@ -17,8 +17,6 @@ package yang
 // This file has functions that search the AST for specified nodes.
 
 import (
-	"fmt"
-	"os"
 	"reflect"
 	"strings"
 )
@@ -50,18 +48,25 @@ func trimPrefix(n Node, name string) string {
 func FindGrouping(n Node, name string, seen map[string]bool) *Grouping {
 	name = trimPrefix(n, name)
 	for n != nil {
+		// Once we reach the module the result only depends on the
+		// module and name, so it may already be known.
+		m, _ := n.(*Module)
+		if g := cachedGrouping(m, name); g != nil {
+			return g
+		}
 		// Grab the Grouping field of the underlying structure.  n is
 		// always a pointer to a structure,
 		e := reflect.ValueOf(n).Elem()
 		if !e.IsValid() {
 			// TODO(borman): we should return an error somehow
-			fmt.Fprintf(os.Stderr, "%s: unknown grouping\n", name)
+			logf(optionsOf(n), LevelError, nil, "%s: unknown grouping", name)
 			return nil
 		}
 		v := e.FieldByName("Grouping")
 		if v.IsValid() {
 			for _, g := range v.Interface().([]*Grouping) {
 				if g.Name == name {
+					cacheGrouping(m, name, g)
 					return g
 				}
 			}
@@ -76,6 +81,7 @@ func FindGrouping(n Node, name string, seen map[string]bool) *Grouping {
 					continue
 				}
 				if g := FindGrouping(i.Module, pname, seen); g != nil {
+					cacheGrouping(m, name, g)
 					return g
 				}
 			}
@@ -91,6 +97,7 @@ func FindGrouping(n Node, name string, seen map[string]bool) *Grouping {
 				}
 				seen[i.Module.Name] = true
 				if g := FindGrouping(i.Module, name, seen); g != nil {
+					cacheGrouping(m, name, g)
 					return g
 				}
 			}
@@ -0,0 +1,254 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements writing statements as canonically formatted YANG.
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+)
+
+// FormatOptions control how Format writes statements.
+type FormatOptions struct {
+	// Indent is written once for each level of nesting.  If empty, two
+	// spaces are used.
+	Indent string
+	// KeepOrder causes substatements to be written in the order they
+	// were parsed in rather than the canonical order.
+	KeepOrder bool
+}
+
+// Format writes s to w as canonically formatted YANG using the default
+// FormatOptions.  See FormatOptions.Format.
+func Format(s *Statement, w io.Writer) error {
+	return FormatOptions{}.Format(s, w)
+}
+
+// Format writes s, and its substatements, to w as canonically formatted
+// YANG.  If s has no keyword, as when it holds the statements returned by
+// Parse, its substatements are written.
+//
+// Substatements are written in the order given by the grammar of RFC 7950
+// section 14, e.g., the type of a leaf before its description.  Statements
+// the grammar does not order, such as the data definition statements of a
+// container, keep the order they were parsed in.  An extension statement
+// stays with the statement it follows.
+//
+// Arguments are written unquoted if they are simple tokens, such as
+// identifiers, numbers, and paths, and otherwise in double quotes.  An
+// argument with a backslash is written in single quotes, if it can be, so
+// patterns need not be escaped.  The arguments of description, reference,
+// contact, organization, error-message and pattern statements are always
+// quoted.  Lines after the first of a multi-line argument are aligned with
+// its first character.  Comments are not part of a Statement, so they are
+// not written.
+func (o FormatOptions) Format(s *Statement, w io.Writer) error {
+	if o.Indent == "" {
+		o.Indent = "  "
+	}
+	bw := bufio.NewWriter(w)
+	if s.Keyword == "" {
+		for x, ss := range o.order(s) {
+			if x > 0 {
+				bw.WriteByte('\n')
+			}
+			o.write(bw, ss, "")
+		}
+	} else {
+		o.write(bw, s, "")
+	}
+	return bw.Flush()
+}
+
+// write writes s to w with each line indented by indent.  Blocks in the
+// body of a module or submodule are separated by blank lines.
+func (o FormatOptions) write(w *bufio.Writer, s *Statement, indent string) {
+	w.WriteString(indent)
+	w.WriteString(s.Keyword)
+	if s.HasArgument {
+		w.WriteByte(' ')
+		w.WriteString(quoteArgument(s.Keyword, s.Argument, indent+strings.Repeat(" ", len(s.Keyword)+2)))
+	}
+	ss := o.order(s)
+	if len(ss) == 0 {
+		w.WriteString(";\n")
+		return
+	}
+	w.WriteString(" {\n")
+	top := s.Keyword == "module" || s.Keyword == "submodule"
+	for x, cs := range ss {
+		if top && x > 0 && (len(cs.statements) > 0 || len(ss[x-1].statements) > 0) {
+			w.WriteByte('\n')
+		}
+		o.write(w, cs, indent+o.Indent)
+	}
+	w.WriteString(indent)
+	w.WriteString("}\n")
+}
+
+// order returns the substatements of s in the order they are written.
+func (o FormatOptions) order(s *Statement) []*Statement {
+	ss := s.statements
+	ranks := canonicalOrder[s.Keyword]
+	if o.KeepOrder || ranks == nil {
+		return ss
+	}
+	// An extension statement has the rank of the statement it follows.
+	rank := make(map[*Statement]int, len(ss))
+	last := -1
+	for _, cs := range ss {
+		if r, ok := ranks[cs.Keyword]; ok {
+			last = r
+		} else if !strings.Contains(cs.Keyword, ":") {
+			// Statements the grammar does not order are last.
+			last = len(ranks)
+		}
+		rank[cs] = last
+	}
+	sorted := append([]*Statement{}, ss...)
+	sort.SliceStable(sorted, func(i, j int) bool { return rank[sorted[i]] < rank[sorted[j]] })
+	return sorted
+}
+
+// dataDef are the data definition statements, which may appear in any
+// order, and keep the order they are given in.
+const dataDef = "container|leaf|leaf-list|list|choice|anydata|anyxml|uses"
+
+// canonicalOrders lists the substatements of each statement in the order
+// of the grammar of RFC 7950 section 14.  Statements separated by | may be
+// given in any order.  Substatements that are not listed, e.g., the body
+// statements of a module, are written after those that are, in the order
+// they are given in.
+var canonicalOrders = map[string]string{
+	"module":       "yang-version namespace prefix import|include organization contact description reference revision",
+	"submodule":    "yang-version belongs-to import|include organization contact description reference revision",
+	"import":       "prefix revision-date description reference",
+	"include":      "revision-date description reference",
+	"revision":     "description reference",
+	"belongs-to":   "prefix",
+	"extension":    "argument status description reference",
+	"argument":     "yin-element",
+	"identity":     "if-feature base status description reference",
+	"feature":      "if-feature status description reference",
+	"typedef":      "type units default status description reference",
+	"type":         "fraction-digits range length pattern path require-instance base enum|bit|type",
+	"range":        "error-message error-app-tag description reference",
+	"length":       "error-message error-app-tag description reference",
+	"pattern":      "modifier error-message error-app-tag description reference",
+	"enum":         "if-feature value status description reference",
+	"bit":          "if-feature position status description reference",
+	"must":         "error-message error-app-tag description reference",
+	"when":         "description reference",
+	"container":    "when if-feature must presence config status description reference typedef|grouping " + dataDef + " action notification",
+	"leaf":         "when if-feature type units must default config mandatory status description reference",
+	"leaf-list":    "when if-feature type units must default config min-elements max-elements ordered-by status description reference",
+	"list":         "when if-feature must key unique config min-elements max-elements ordered-by status description reference typedef|grouping " + dataDef + " action notification",
+	"choice":       "when if-feature default config mandatory status description reference case|" + dataDef,
+	"case":         "when if-feature status description reference " + dataDef,
+	"anydata":      "when if-feature must config mandatory status description reference",
+	"anyxml":       "when if-feature must config mandatory status description reference",
+	"grouping":     "status description reference typedef|grouping " + dataDef + " action notification",
+	"uses":         "when if-feature status description reference refine augment",
+	"refine":       "if-feature must presence default config mandatory min-elements max-elements description reference",
+	"augment":      "when if-feature status description reference case|action|notification|" + dataDef,
+	"rpc":          "if-feature status description reference typedef|grouping input output",
+	"action":       "if-feature status description reference typedef|grouping input output",
+	"input":        "must typedef|grouping " + dataDef,
+	"output":       "must typedef|grouping " + dataDef,
+	"notification": "if-feature must status description reference typedef|grouping " + dataDef,
+	"deviation":    "description reference deviate",
+	"deviate":      "type units must unique default config mandatory min-elements max-elements",
+}
+
+// canonicalOrder maps a keyword to the ranks of its substatements, see
+// canonicalOrders.
+var canonicalOrder = func() map[string]map[string]int {
+	m := map[string]map[string]int{}
+	for keyword, order := range canonicalOrders {
+		ranks := map[string]int{}
+		for r, group := range strings.Fields(order) {
+			for _, k := range strings.Split(group, "|") {
+				ranks[k] = r
+			}
+		}
+		m[keyword] = ranks
+	}
+	return m
+}()
+
+// alwaysQuoted are the keywords whose arguments are always quoted.
+var alwaysQuoted = map[string]bool{
+	"contact":       true,
+	"description":   true,
+	"error-message": true,
+	"organization":  true,
+	"pattern":       true,
+	"reference":     true,
+}
+
+// quoteArgument returns arg, the argument of keyword, quoted as needed.
+// Lines after the first are indented by indent.
+func quoteArgument(keyword, arg, indent string) string {
+	if !alwaysQuoted[keyword] && isSimpleArgument(arg) {
+		return arg
+	}
+	if strings.Contains(arg, `\`) && !strings.ContainsAny(arg, "'\n") {
+		return "'" + arg + "'"
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for x, line := range strings.Split(arg, "\n") {
+		if x > 0 {
+			b.WriteByte('\n')
+			if line != "" {
+				b.WriteString(indent)
+			}
+		}
+		for _, c := range line {
+			switch c {
+			case '"':
+				b.WriteString(`\"`)
+			case '\\':
+				b.WriteString(`\\`)
+			case '\t':
+				b.WriteString(`\t`)
+			default:
+				b.WriteRune(c)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// isSimpleArgument reports whether arg can be written without quotes in
+// canonical form.
+func isSimpleArgument(arg string) bool {
+	if arg == "" || strings.Contains(arg, "//") || strings.Contains(arg, "/*") || strings.Contains(arg, "*/") {
+		return false
+	}
+	for _, c := range arg {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.ContainsRune("_-.:/@", c):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,171 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFormat(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		in   string
+		opts FormatOptions
+		want string
+	}{{
+		name: "canonical order and quoting",
+		in: `module m { prefix 'm'; namespace "urn:m"; description 'A module.';
+  leaf l { description "A leaf with a \"quoted\" word"; type string { pattern '\d+'; length "1..10"; } config false; }
+  container c {
+    leaf b { type int8; }
+    presence "enabled";
+    leaf a { type int8; default "-1"; }
+  }
+  typedef t { type string; }
+}`,
+		want: `module m {
+  namespace urn:m;
+  prefix m;
+  description "A module.";
+
+  leaf l {
+    type string {
+      length 1..10;
+      pattern '\d+';
+    }
+    config false;
+    description "A leaf with a \"quoted\" word";
+  }
+
+  container c {
+    presence enabled;
+    leaf b {
+      type int8;
+    }
+    leaf a {
+      type int8;
+      default -1;
+    }
+  }
+
+  typedef t {
+    type string;
+  }
+}
+`,
+	}, {
+		name: "indent and keep order",
+		in:   `container c { description "x"; when "../a = 'b'"; leaf l { type string; } }`,
+		opts: FormatOptions{Indent: "\t", KeepOrder: true},
+		want: "container c {\n\tdescription \"x\";\n\twhen \"../a = 'b'\";\n\tleaf l {\n\t\ttype string;\n\t}\n}\n",
+	}, {
+		name: "multi-line strings",
+		in: `leaf l {
+    description "first
+      second
+
+      	third";
+    must "a and
+          b" { error-message 'x\y'; }
+}`,
+		want: `leaf l {
+  must "a and
+        b" {
+    error-message 'x\y';
+  }
+  description "first
+               second
+
+               third";
+}
+`,
+	}, {
+		name: "extensions follow their statement",
+		in: `leaf l {
+  description "d";
+  e:first;
+  type string;
+  e:second "a b";
+}`,
+		want: `leaf l {
+  type string;
+  e:second "a b";
+  description "d";
+  e:first;
+}
+`,
+	}, {
+		name: "several statements",
+		in:   `module a { } module b { }`,
+		want: "module a;\n\nmodule b;\n",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			ss, err := Parse(tt.in, "test.yang")
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := &Statement{statements: ss}
+			if len(ss) == 1 {
+				s = ss[0]
+			}
+			var b strings.Builder
+			if err := tt.opts.Format(s, &b); err != nil {
+				t.Fatal(err)
+			}
+			got := b.String()
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Format (-want, +got):\n%s", diff)
+			}
+
+			// Formatting must not change what the input means, and
+			// formatted input must be unchanged by formatting.
+			fs, err := Parse(got, "formatted.yang")
+			if err != nil {
+				t.Fatalf("formatted output does not parse: %v", err)
+			}
+			if diff := cmp.Diff(statementArguments(ss), statementArguments(fs)); diff != "" {
+				t.Errorf("formatted statements differ (-want, +got):\n%s", diff)
+			}
+			b.Reset()
+			if err := tt.opts.Format(&Statement{statements: fs}, &b); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(got, b.String()); diff != "" {
+				t.Errorf("Format is not idempotent (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// statementArguments returns the sorted paths, made of keywords and
+// arguments, of the statements in ss and their substatements.
+func statementArguments(ss []*Statement) []string {
+	var args []string
+	var walk func(string, []*Statement)
+	walk = func(path string, ss []*Statement) {
+		for _, s := range ss {
+			p := path + "/" + s.Keyword + "=" + s.Argument
+			args = append(args, p)
+			walk(p, s.statements)
+		}
+	}
+	walk("", ss)
+	sort.Strings(args)
+	return args
+}
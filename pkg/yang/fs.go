@@ -0,0 +1,85 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.16
+// +build go1.16
+
+package yang
+
+// This file implements reading modules from an fs.FS, such as an
+// embed.FS.  It requires Go 1.16, which introduced the io/fs package.
+
+import (
+	"context"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// AddSource adds a resolver, see AddResolver, that reads modules from
+// fsys.  Modules are found in fsys as they are in a Path directory of the form dir/...,
+// i.e., fsys and all of its subdirectories are searched.  A name with a /
+// in it is read from that path in fsys.
+//
+// For example, the modules in a package's yang directory can be compiled
+// into the program and read with:
+//
+//	//go:embed yang
+//	var modules embed.FS
+//
+//	ms := yang.NewModules()
+//	ms.AddSource(modules)
+//	err := ms.Read("my-module")
+func (ms *Modules) AddSource(fsys fs.FS) {
+	ms.AddResolver(fsResolver{fsys})
+}
+
+// An fsResolver is a ModuleResolver that reads modules from an fs.FS.
+type fsResolver struct {
+	fsys fs.FS
+}
+
+func (r fsResolver) Resolve(ctx context.Context, name string) (string, string, error) {
+	return findInFS(r.fsys, name)
+}
+
+// findInFS returns the name, in fsys, and contents of the .yang or .yin
+// file associated with name, as described by findFile.
+func findInFS(fsys fs.FS, name string) (string, string, error) {
+	if strings.Contains(name, "/") {
+		name = path.Clean(strings.TrimPrefix(name, "/"))
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return "", "", errorf(nil, ErrFileNotFound, "no such file: %s", name)
+		}
+		return name, string(data), nil
+	}
+	var paths []string
+	fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			paths = append(paths, p)
+		}
+		return nil
+	})
+	for _, n := range sourceNames(name) {
+		if p := matchPath(paths, n); p != "" {
+			data, err := fs.ReadFile(fsys, p)
+			if err != nil {
+				return "", "", err
+			}
+			return p, string(data), nil
+		}
+	}
+	return "", "", errorf(nil, ErrFileNotFound, "no such file: %s", name)
+}
@@ -0,0 +1,95 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.16
+// +build go1.16
+
+package yang
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+func TestAddSource(t *testing.T) {
+	fsys := fstest.MapFS{
+		"fs-a.yang": {Data: []byte(`module fs-a {
+  namespace "urn:fs-a";
+  prefix "a";
+  import fs-b { prefix "b"; }
+  include fs-sub;
+  leaf l { type b:t; }
+}`)},
+		"fs-sub.yin": {Data: []byte(`<submodule name="fs-sub" xmlns="urn:ietf:params:xml:ns:yang:yin:1">
+  <belongs-to module="fs-a"><prefix value="a"/></belongs-to>
+</submodule>`)},
+		"deps/fs-b@2019-01-01.yang": {Data: []byte(`module fs-b {
+  namespace "urn:fs-b";
+  prefix "b";
+  revision 2019-01-01;
+  typedef t { type int8; }
+}`)},
+		"deps/old/fs-b@2018-01-01.yang": {Data: []byte(`module fs-b {
+  namespace "urn:fs-b";
+  prefix "b";
+  revision 2018-01-01;
+}`)},
+		"other/fs-c.yang": {Data: []byte(`module fs-c {
+  namespace "urn:fs-c";
+  prefix "c";
+}`)},
+	}
+
+	ms := NewModules()
+	if err := ms.Read("fs-a"); err == nil {
+		t.Fatal("read fs-a without a source")
+	}
+	ms.AddSource(fsys)
+	if err := ms.Read("fs-a"); err != nil {
+		t.Fatal(err)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if got, want := ms.Modules["fs-b"].Current(), "2019-01-01"; got != want {
+		t.Errorf("read revision %s of fs-b, want %s", got, want)
+	}
+	if ms.SubModules["fs-sub"] == nil {
+		t.Error("fs-sub was not included")
+	}
+
+	for _, tt := range []struct {
+		name    string
+		want    string
+		wantErr string
+	}{
+		{name: "other/fs-c.yang", want: "other/fs-c.yang"},
+		{name: "fs-c.yang", want: "other/fs-c.yang"},
+		{name: "fs-b@2018-01-01", want: "deps/old/fs-b@2018-01-01.yang"},
+		{name: "fs-b", want: "deps/fs-b@2019-01-01.yang"},
+		{name: "fs-sub", want: "fs-sub.yin"},
+		{name: "fs-c/", wantErr: "no such file: fs-c"},
+		{name: "fs-d", wantErr: "no such file: fs-d"},
+	} {
+		got, _, err := findInFS(fsys, tt.name)
+		if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+			t.Errorf("findInFS(%q): %s", tt.name, diff)
+		}
+		if got != tt.want {
+			t.Errorf("findInFS(%q): got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
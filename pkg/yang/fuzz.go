@@ -0,0 +1,58 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build gofuzz
+// +build gofuzz
+
+package yang
+
+// This file contains the targets for fuzzing the package with go-fuzz
+// (https://github.com/dvyukov/go-fuzz):
+//
+//   go-fuzz-build -func FuzzParse github.com/openconfig/goyang/pkg/yang
+//   go-fuzz -func FuzzParse
+//
+// The testdata directory makes a good initial corpus.  Panics are not
+// recovered from while fuzzing so that go-fuzz reports them.
+
+import "context"
+
+// fuzzOptions are the options used while fuzzing.  The limits keep
+// pathological inputs from exhausting the stack or memory.
+var fuzzOptions = Options{
+	NoPanicRecovery:   true,
+	MaxStatementDepth: 100,
+	MaxUsesDepth:      100,
+	MaxFileSize:       1 << 20,
+}
+
+// FuzzParse parses data as a YANG file.
+func FuzzParse(data []byte) int {
+	if _, err := parse(context.Background(), string(data), "fuzz.yang", &fuzzOptions); err != nil {
+		return 0
+	}
+	return 1
+}
+
+// FuzzProcess parses data as a YANG module and processes it.
+func FuzzProcess(data []byte) int {
+	ms := NewModulesWithOptions(fuzzOptions)
+	if err := ms.Parse(string(data), "fuzz.yang"); err != nil {
+		return 0
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		return 0
+	}
+	return 1
+}
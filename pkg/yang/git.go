@@ -0,0 +1,212 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements reading modules from a git repository.
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// A GitSource is a git repository that modules are read from, see
+// AddGitSource.  The git command must be installed.
+type GitSource struct {
+	// Repository is the URL of the repository, as passed to git fetch,
+	// e.g., "https://github.com/openconfig/public".
+	Repository string
+
+	// Ref is the branch, tag, or commit of Repository to read modules
+	// from.  If empty, HEAD is used.
+	Ref string
+
+	// CacheDir is the directory the commits of repositories are checked
+	// out in.  It is required.
+	CacheDir string
+
+	// Git is the path of the git command.  If empty, git is found in
+	// $PATH.
+	Git string
+}
+
+// commitPattern matches a full commit hash.
+var commitPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// AddGitSource adds a resolver, see AddResolver, that reads modules from
+// the commit of g.Repository named by g.Ref.  The commit is checked out in
+// a directory of g.CacheDir named by its hash, and is not fetched again
+// while that directory exists, so only the name of a branch or tag is
+// looked up in the repository when the commit has already been fetched.
+// Modules are found in the checkout as they are in a Path directory of the
+// form dir/....  AddGitSource returns the hash of the commit.
+func (ms *Modules) AddGitSource(ctx context.Context, g *GitSource) (string, error) {
+	if g.CacheDir == "" {
+		return "", fmt.Errorf("%s: no cache directory", g.Repository)
+	}
+	commit, dir, err := g.checkout(ctx)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", g.Repository, err)
+	}
+	r, err := newDirResolver(dir)
+	if err != nil {
+		return "", err
+	}
+	ms.AddResolver(r)
+	return commit, nil
+}
+
+// checkout returns the hash of the commit named by g.Ref and the directory
+// it is checked out in, fetching it if it is not in g.CacheDir.
+func (g *GitSource) checkout(ctx context.Context) (string, string, error) {
+	ref := g.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	commit := ref
+	if !commitPattern.MatchString(ref) {
+		out, err := g.git(ctx, "", "ls-remote", "--", g.Repository, ref, ref+"^{}")
+		if err != nil {
+			return "", "", err
+		}
+		// Use the first ref that matches, or, if it is an annotated
+		// tag, the commit it refers to.
+		commit = ""
+		var name string
+		for _, line := range strings.Split(out, "\n") {
+			f := strings.Fields(line)
+			switch {
+			case len(f) != 2:
+			case name == "" && !strings.HasSuffix(f[1], "^{}"):
+				commit, name = f[0], f[1]
+			case f[1] == name+"^{}":
+				commit = f[0]
+			}
+		}
+		if commit == "" {
+			return "", "", fmt.Errorf("no such ref: %s", ref)
+		}
+	}
+	dir := filepath.Join(g.CacheDir, commit)
+	if fi, err := os.Stat(dir); err == nil && fi.IsDir() {
+		return commit, dir, nil
+	}
+
+	if err := os.MkdirAll(g.CacheDir, 0755); err != nil {
+		return "", "", err
+	}
+	tmp, err := ioutil.TempDir(g.CacheDir, "."+commit+".")
+	if err != nil {
+		return "", "", err
+	}
+	defer os.RemoveAll(tmp)
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"fetch", "-q", "--depth", "1", "--", g.Repository, commit},
+		{"checkout", "-q", commit},
+	} {
+		if _, err := g.git(ctx, tmp, args...); err != nil {
+			return "", "", err
+		}
+	}
+	if err := os.RemoveAll(filepath.Join(tmp, ".git")); err != nil {
+		return "", "", err
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		return "", "", err
+	}
+	return commit, dir, nil
+}
+
+// git runs git with args in dir and returns its standard output.
+func (g *GitSource) git(ctx context.Context, dir string, args ...string) (string, error) {
+	git := g.Git
+	if git == "" {
+		git = "git"
+	}
+	cmd := exec.CommandContext(ctx, git, args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %v", args[0], err)
+	}
+	return stdout.String(), nil
+}
+
+// A dirResolver is a ModuleResolver that reads modules from a directory
+// and its subdirectories.
+type dirResolver struct {
+	dir   string
+	paths []string // the slash separated paths of the .yang and .yin files in dir
+}
+
+// newDirResolver returns a dirResolver for dir.
+func newDirResolver(dir string) (*dirResolver, error) {
+	r := &dirResolver{dir: dir}
+	err := filepath.Walk(dir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() && isBundleSource(p) {
+			rel, err := filepath.Rel(dir, p)
+			if err != nil {
+				return err
+			}
+			r.paths = append(r.paths, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	return r, err
+}
+
+func (r *dirResolver) Resolve(ctx context.Context, name string) (string, string, error) {
+	p := ""
+	if strings.Contains(name, "/") {
+		want := path.Clean(strings.TrimPrefix(name, "/"))
+		for _, rp := range r.paths {
+			if rp == want {
+				p = rp
+				break
+			}
+		}
+	} else {
+		for _, n := range sourceNames(name) {
+			if p = matchPath(r.paths, n); p != "" {
+				break
+			}
+		}
+	}
+	if p == "" {
+		return "", "", errorf(nil, ErrFileNotFound, "no such file: %s", name)
+	}
+	fname := filepath.Join(r.dir, filepath.FromSlash(p))
+	data, err := readFile(fname, maxFileSize(ctx))
+	if err != nil {
+		return "", "", err
+	}
+	return fname, string(data), nil
+}
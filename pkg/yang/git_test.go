@@ -0,0 +1,122 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+func TestAddGitSource(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir, err := ioutil.TempDir("", "git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	repo := filepath.Join(dir, "repo")
+	cache := filepath.Join(dir, "cache")
+
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repo
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	commit := func(name, data string) string {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(repo, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(repo, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		git("add", name)
+		git("commit", "-q", "-m", name)
+		return git("rev-parse", "HEAD")
+	}
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	git("init", "-q")
+	first := commit("models/git-b.yang", `module git-b {
+  namespace "urn:git-b";
+  prefix "b";
+}`)
+	git("tag", "-a", "-m", "v1", "v1")
+	second := commit("models/git-b.yang", `module git-b {
+  namespace "urn:git-b";
+  prefix "b";
+  typedef t { type int8; }
+}`)
+
+	url := "file://" + repo
+	for _, tt := range []struct {
+		ref        string
+		wantCommit string
+		wantTypes  int
+		wantErr    string
+	}{
+		{ref: "", wantCommit: second, wantTypes: 1},
+		{ref: "v1", wantCommit: first},
+		{ref: first, wantCommit: first},
+		{ref: "no-such-ref", wantErr: "no such ref: no-such-ref"},
+	} {
+		ms := NewModules()
+		got, err := ms.AddGitSource(context.Background(), &GitSource{Repository: url, Ref: tt.ref, CacheDir: cache})
+		if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+			t.Errorf("AddGitSource(%q): %s", tt.ref, diff)
+		}
+		if err != nil {
+			continue
+		}
+		if got != tt.wantCommit {
+			t.Errorf("AddGitSource(%q): got commit %s, want %s", tt.ref, got, tt.wantCommit)
+		}
+		if err := ms.Read("git-b"); err != nil {
+			t.Errorf("AddGitSource(%q): %v", tt.ref, err)
+			continue
+		}
+		if got := len(ms.Modules["git-b"].Typedef); got != tt.wantTypes {
+			t.Errorf("AddGitSource(%q): got %d typedefs, want %d", tt.ref, got, tt.wantTypes)
+		}
+	}
+
+	// A commit that has been checked out is not fetched again.
+	os.RemoveAll(repo)
+	ms := NewModules()
+	if _, err := ms.AddGitSource(context.Background(), &GitSource{Repository: url, Ref: first, CacheDir: cache}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ms.Read("models/git-b.yang"); err != nil {
+		t.Error(err)
+	}
+	if _, err := ms.AddGitSource(context.Background(), &GitSource{Repository: url, Ref: first}); err == nil {
+		t.Error("AddGitSource without a cache directory succeeded")
+	}
+}
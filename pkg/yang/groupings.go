@@ -0,0 +1,74 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements listing the groupings of a set of modules and where
+// each of them is used.
+
+import "sort"
+
+// Groupings returns all the groupings defined in the modules and
+// submodules of ms, including groupings nested in other statements.  The
+// groupings are ordered by the full name of their module and then in the
+// order they appear in the module.  RootNode and Source return where each
+// grouping is defined.
+func (ms *Modules) Groupings() []*Grouping {
+	var gs []*Grouping
+	for _, m := range ms.sortedModules() {
+		start := len(gs)
+		walkNodes(m, func(n Node) {
+			if g, ok := n.(*Grouping); ok {
+				gs = append(gs, g)
+			}
+		})
+		mgs := gs[start:]
+		sort.SliceStable(mgs, func(i, j int) bool { return before(mgs[i], mgs[j]) })
+	}
+	return gs
+}
+
+// UsesOf returns the uses statements in the modules and submodules of ms
+// that refer to g, in the same order as Groupings.  Uses statements within
+// groupings are included, whether or not the grouping they are in is used.
+// The modules of ms should have been processed, otherwise the uses of
+// groupings from other modules are not found.  An empty result means g is
+// never used.
+func (ms *Modules) UsesOf(g *Grouping) []*Uses {
+	var us []*Uses
+	for _, m := range ms.sortedModules() {
+		start := len(us)
+		walkNodes(m, func(n Node) {
+			if u, ok := n.(*Uses); ok && FindGrouping(u, u.Name, map[string]bool{}) == g {
+				us = append(us, u)
+			}
+		})
+		mus := us[start:]
+		sort.SliceStable(mus, func(i, j int) bool { return before(mus[i], mus[j]) })
+	}
+	return us
+}
+
+// before reports whether a appears before b in the file they are defined
+// in.  Nodes without a statement are not ordered.
+func before(a, b Node) bool {
+	as, bs := a.Statement(), b.Statement()
+	if as == nil || bs == nil {
+		return false
+	}
+	if as.line != bs.line {
+		return as.line < bs.line
+	}
+	return as.col < bs.col
+}
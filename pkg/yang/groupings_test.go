@@ -0,0 +1,87 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGroupings(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"g": `module g {
+  prefix "g";
+  namespace "urn:g";
+  include g-sub;
+  grouping used { leaf l { type string; } }
+  grouping unused { leaf u { type string; } }
+  grouping wrapper { uses used; }
+  container c {
+    grouping local { leaf n { type string; } }
+    uses local;
+    uses wrapper;
+    uses from-sub;
+  }
+}`,
+		"g-sub": `submodule g-sub {
+  belongs-to g { prefix "g"; }
+  grouping from-sub { leaf s { type string; } }
+}`,
+		"h": `module h {
+  prefix "h";
+  namespace "urn:h";
+  import g { prefix "gp"; }
+  container d { uses gp:used; }
+}`,
+	} {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	var got []string
+	uses := map[string][]string{}
+	for _, g := range ms.Groupings() {
+		name := RootNode(g).Name + " " + dumpName(g)
+		got = append(got, name)
+		for _, u := range ms.UsesOf(g) {
+			uses[name] = append(uses[name], Source(u))
+		}
+	}
+	want := []string{
+		"g used",
+		"g unused",
+		"g wrapper",
+		"g local in module g / container c",
+		"g-sub from-sub",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Groupings() (-want, +got):\n%s", diff)
+	}
+	wantUses := map[string][]string{
+		"g used":                            {"g.yang:7:22", "h.yang:5:17"},
+		"g wrapper":                         {"g.yang:11:5"},
+		"g local in module g / container c": {"g.yang:10:5"},
+		"g-sub from-sub":                    {"g.yang:12:5"},
+	}
+	if diff := cmp.Diff(wantUses, uses); diff != "" {
+		t.Errorf("UsesOf (-want, +got):\n%s", diff)
+	}
+}
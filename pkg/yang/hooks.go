@@ -0,0 +1,79 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the hooks called while processing modules.
+
+// A ProcessStage identifies a point during Process at which hooks are
+// called.
+type ProcessStage int
+
+const (
+	// AfterTypedefs is once includes, imports, identities, and types
+	// have been resolved.
+	AfterTypedefs ProcessStage = iota
+	// BeforeEntries is just before the Entry trees are built, after
+	// extension handlers have been called.
+	BeforeEntries
+	// AfterAugments is once the Entry trees are built and augments
+	// have been applied, but before deviations are applied.
+	AfterAugments
+)
+
+var processStageNames = map[ProcessStage]string{
+	AfterTypedefs: "after-typedefs",
+	BeforeEntries: "before-entries",
+	AfterAugments: "after-augments",
+}
+
+func (s ProcessStage) String() string {
+	if n := processStageNames[s]; n != "" {
+		return n
+	}
+	return "unknown-stage"
+}
+
+// A ProcessHook is called by Process at each ProcessStage.  Hooks may
+// inspect or modify the modules, and once Entry trees are built the
+// entries, of ms to add their own semantic checks or transformations.
+// Errors returned by a hook are reported as processing errors.  Processing
+// stops following the AfterTypedefs or BeforeEntries stages if there were
+// any errors.
+type ProcessHook interface {
+	ProcessHook(stage ProcessStage, ms *Modules) []error
+}
+
+// A ProcessHookFunc is a function used as a ProcessHook.
+type ProcessHookFunc func(stage ProcessStage, ms *Modules) []error
+
+// ProcessHook calls f(stage, ms).
+func (f ProcessHookFunc) ProcessHook(stage ProcessStage, ms *Modules) []error {
+	return f(stage, ms)
+}
+
+// AddHook adds h to the hooks called when ms is processed.  Hooks are
+// called in the order they were added.
+func (ms *Modules) AddHook(h ProcessHook) {
+	ms.hooks = append(ms.hooks, h)
+}
+
+// runHooks calls the hooks of ms for stage and returns their errors.
+func (ms *Modules) runHooks(stage ProcessStage) []error {
+	var errs []error
+	for _, h := range ms.hooks {
+		errs = append(errs, h.ProcessHook(stage, ms)...)
+	}
+	return errs
+}
@@ -0,0 +1,166 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+var hookModules = map[string]string{
+	"hook-base": `
+		module hook-base {
+			prefix "b";
+			namespace "urn:b";
+			typedef BadName { type string; }
+			container c { leaf l { type BadName; } }
+		}
+	`,
+	"hook-aug": `
+		module hook-aug {
+			prefix "a";
+			namespace "urn:a";
+			import hook-base { prefix "b"; }
+			augment "/b:c" { leaf added { type string; } }
+		}
+	`,
+}
+
+func newHookModules(t *testing.T) *Modules {
+	t.Helper()
+	ms := NewModules()
+	for n, text := range hookModules {
+		if err := ms.Parse(text, n+".yang"); err != nil {
+			t.Fatalf("%s: %v", n, err)
+		}
+	}
+	return ms
+}
+
+func TestProcessHooks(t *testing.T) {
+	ms := newHookModules(t)
+	var stages []string
+	ms.AddHook(ProcessHookFunc(func(stage ProcessStage, ms *Modules) []error {
+		stages = append(stages, stage.String())
+		switch stage {
+		case AfterTypedefs:
+			// Types are resolved.
+			if td := ms.Modules["hook-base"].Typedef[0]; td.YangType == nil {
+				t.Errorf("%s: typedef %s is not resolved", stage, td.Name)
+			}
+		case AfterAugments:
+			// Augments are applied, transform the augmented leaf.
+			e := ToEntry(ms.Modules["hook-base"]).Find("/c/added")
+			if e == nil {
+				t.Errorf("%s: augment not applied", stage)
+				break
+			}
+			e.Description = "set by hook"
+		}
+		return nil
+	}))
+	var second []string
+	ms.AddHook(ProcessHookFunc(func(stage ProcessStage, ms *Modules) []error {
+		second = append(second, stage.String())
+		return nil
+	}))
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	want := "after-typedefs before-entries after-augments"
+	if got := strings.Join(stages, " "); got != want {
+		t.Errorf("got stages %s, want %s", got, want)
+	}
+	if got := strings.Join(second, " "); got != want {
+		t.Errorf("second hook got stages %s, want %s", got, want)
+	}
+	if got := ToEntry(ms.Modules["hook-base"]).Find("/c/added").Description; got != "set by hook" {
+		t.Errorf("got description %q, want %q", got, "set by hook")
+	}
+}
+
+// lowerCaseTypedefs is a custom semantic check.
+func lowerCaseTypedefs(stage ProcessStage, ms *Modules) []error {
+	if stage != AfterTypedefs {
+		return nil
+	}
+	var errs []error
+	for _, m := range ms.sortedModules() {
+		for _, td := range m.Typedef {
+			if strings.ToLower(td.Name) != td.Name {
+				errs = append(errs, errors.New(Source(td)+": typedef "+td.Name+" is not lower case"))
+			}
+		}
+	}
+	return errs
+}
+
+func TestProcessHookErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		hook       ProcessHookFunc
+		wantErr    string
+		wantStages string
+	}{{
+		name:       "after typedefs",
+		hook:       lowerCaseTypedefs,
+		wantErr:    "typedef BadName is not lower case",
+		wantStages: "after-typedefs",
+	}, {
+		name: "before entries",
+		hook: func(stage ProcessStage, ms *Modules) []error {
+			if stage == BeforeEntries {
+				return []error{errors.New("stop")}
+			}
+			return nil
+		},
+		wantErr:    "stop",
+		wantStages: "after-typedefs before-entries",
+	}, {
+		name: "after augments",
+		hook: func(stage ProcessStage, ms *Modules) []error {
+			if stage == AfterAugments {
+				return []error{errors.New("late")}
+			}
+			return nil
+		},
+		wantErr:    "late",
+		wantStages: "after-typedefs before-entries after-augments",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := newHookModules(t)
+			var stages []string
+			ms.AddHook(ProcessHookFunc(func(stage ProcessStage, ms *Modules) []error {
+				stages = append(stages, stage.String())
+				return nil
+			}))
+			ms.AddHook(tt.hook)
+			errs := ms.Process()
+			if len(errs) != 1 {
+				t.Fatalf("got errors %v, want 1", errs)
+			}
+			if diff := errdiff.Substring(errs[0], tt.wantErr); diff != "" {
+				t.Error(diff)
+			}
+			if got := strings.Join(stages, " "); got != tt.wantStages {
+				t.Errorf("got stages %s, want %s", got, tt.wantStages)
+			}
+		})
+	}
+}
@@ -16,22 +16,11 @@ package yang
 
 import (
 	"fmt"
-	"sync"
 )
 
 // This file implements data structures and functions that relate to the
 // identity type.
 
-// identityDictionary stores a global set of identities that have been resolved
-// to be identified by their module and name.
-type identityDictionary struct {
-	mu   sync.Mutex
-	dict map[string]resolvedIdentity
-}
-
-// Global dictionary of resolved identities
-var identities = identityDictionary{dict: map[string]resolvedIdentity{}}
-
 // resolvedIdentity is an Identity that has been disambiguated.
 type resolvedIdentity struct {
 	Module   *Module
@@ -54,22 +43,19 @@ func newResolvedIdentity(m *Module, i *Identity) (string, *resolvedIdentity) {
 	return i.PrefixedName(), r
 }
 
-func appendIfNotIn(ids []*Identity, chk *Identity) []*Identity {
-	for _, id := range ids {
-		if id == chk {
-			return ids
-		}
+// addChildren recursively adds the identity r, and all identities derived
+// from r, to ids.  seen contains the identities already in ids.
+func addChildren(r *Identity, ids []*Identity, seen map[*Identity]bool) []*Identity {
+	if seen[r] {
+		// r was added along with all of its children.
+		return ids
 	}
-	return append(ids, chk)
-}
-
-// addChildren recursively adds the identity r to ids.
-func addChildren(r *Identity, ids []*Identity) []*Identity {
-	ids = appendIfNotIn(ids, r)
+	seen[r] = true
+	ids = append(ids, r)
 
 	// Iterate through the values of r.
 	for _, ch := range r.Values {
-		ids = addChildren(ch, ids)
+		ids = addChildren(ch, ids, seen)
 	}
 	return ids
 }
@@ -81,24 +67,30 @@ func (mod *Module) findIdentityBase(baseStr string) (*resolvedIdentity, []error)
 	var ok bool
 	var errs []error
 
+	// The identities are those resolved by the Modules that mod was read
+	// into.  A module that was not read into a Modules has none.
+	var identities map[string]resolvedIdentity
+	if mod.modules != nil {
+		identities = mod.modules.identities
+	}
+
 	basePrefix, baseName := getPrefix(baseStr)
 	rootPrefix := mod.GetPrefix()
-	source := Source(mod)
 
 	switch basePrefix {
 	case "", rootPrefix:
 		// This is a local identity which is defined within the current
 		// module
 		keyName := fmt.Sprintf("%s:%s", rootPrefix, baseName)
-		base, ok = identities.dict[keyName]
+		base, ok = identities[keyName]
 		if !ok {
-			errs = append(errs, fmt.Errorf("%s: can't resolve the local base %s as %s", source, baseStr, keyName))
+			errs = append(errs, errorf(mod, ErrUnknownIdentity, "can't resolve the local base %s as %s", baseStr, keyName))
 		}
 	default:
 		// The identity we are looking for is prefix:basename.  If
 		// we already know prefix:basename then just use it.  If not,
 		// try again within the module identified by prefix.
-		if id, ok := identities.dict[baseStr]; ok {
+		if id, ok := identities[baseStr]; ok {
 			base = id
 			break
 		}
@@ -106,46 +98,41 @@ func (mod *Module) findIdentityBase(baseStr string) (*resolvedIdentity, []error)
 		extmod := FindModuleByPrefix(mod, basePrefix)
 		if extmod == nil {
 			errs = append(errs,
-				fmt.Errorf("%s: can't find external module with prefix %s", source, basePrefix))
+				errorf(mod, ErrUnknownPrefix, "can't find external module with prefix %s%s", basePrefix, didYouMean(basePrefix, prefixNames(mod))))
 			break
 		}
 
-		// Run through the identities within the remote module and find the
-		// one that matches the base that we have been specified.
-		for _, rid := range extmod.Identities() {
-			if rid.Name == baseName {
-				key := rid.PrefixedName()
-				if id, ok := identities.dict[key]; ok {
-					base = id
-				} else {
-					errs = append(errs, fmt.Errorf("%s: can't find base %s", source, baseStr))
-				}
-				break
-			}
+		// Look up the identity by the prefix the remote module uses
+		// for itself.  Identities are indexed by that prefix.
+		if id, ok := identities[fmt.Sprintf("%s:%s", extmod.GetPrefix(), baseName)]; ok && RootNode(id.Identity) == extmod {
+			base = id
 		}
 		// Error if we did not find the identity that had the name specified in
 		// the module it was expected to be in.
 		if base.isEmpty() {
-			errs = append(errs, fmt.Errorf("%s: can't resolve remote base %s", source, baseStr))
+			errs = append(errs, errorf(mod, ErrUnknownIdentity, "can't resolve remote base %s", baseStr))
 		}
 	}
 	return &base, errs
 }
 
 func (ms *Modules) resolveIdentities() []error {
-	defer identities.mu.Unlock()
-	identities.mu.Lock()
-
 	var errs []error
+	ms.identities = map[string]resolvedIdentity{}
 
 	// Across all modules, read the identity values that have been extracted
 	// from them, and compile them into a "fully resolved" map that means that
 	// we can look them up based on the 'real' prefix of the module and the
 	// name of the identity.
-	for _, mod := range ms.Modules {
+	var resolved []*Identity
+	add := func(m *Module, i *Identity) {
+		keyName, r := newResolvedIdentity(m, i)
+		ms.identities[keyName] = *r
+		resolved = append(resolved, i)
+	}
+	for _, mod := range sortModules(ms.Modules) {
 		for _, i := range mod.Identities() {
-			keyName, r := newResolvedIdentity(mod, i)
-			identities.dict[keyName] = *r
+			add(mod, i)
 		}
 
 		// Hoist up all identities in our included submodules.
@@ -156,8 +143,7 @@ func (ms *Modules) resolveIdentities() []error {
 				continue
 			}
 			for _, i := range in.Module.Identities() {
-				keyName, r := newResolvedIdentity(in.Module, i)
-				identities.dict[keyName] = *r
+				add(in.Module, i)
 			}
 		}
 	}
@@ -166,32 +152,46 @@ func (ms *Modules) resolveIdentities() []error {
 	// fully resolved identity statement. The intention here is to make sure
 	// that the Children slice is fully populated with pointers to all identities
 	// that have a base, so that we can do inheritance of these later.
-	for _, i := range identities.dict {
-		if i.Identity.Base != nil {
-			// This identity inherits from one or more other identities.
-
-			root := RootNode(i.Identity)
-			for _, b := range i.Identity.Base {
-				base, baseErr := root.findIdentityBase(b.asString())
-
-				if baseErr != nil {
-					errs = append(errs, baseErr...)
-					continue
-				}
+	//
+	// Modules are stored under both their name and their full name so the
+	// same identity may be found more than once.
+	linked := map[*Identity]bool{}
+	for _, i := range resolved {
+		if linked[i] {
+			continue
+		}
+		linked[i] = true
+		if i.Base == nil {
+			continue
+		}
+		// This identity inherits from one or more other identities.
+		root := RootNode(i)
+		for _, b := range i.Base {
+			base, baseErr := root.findIdentityBase(b.asString())
 
-				// Append this value to the children of the base identity.
-				base.Identity.Values = append(base.Identity.Values, i.Identity)
+			if baseErr != nil {
+				errs = append(errs, baseErr...)
+				continue
 			}
+
+			// Append this value to the children of the base identity.
+			base.Identity.Values = append(base.Identity.Values, i)
 		}
 	}
 
 	// Do a final sweep through the identities to build up their children.
-	for _, i := range identities.dict {
+	done := map[*Identity]bool{}
+	for _, i := range resolved {
+		if done[i] {
+			continue
+		}
+		done[i] = true
 		newValues := []*Identity{}
-		for _, j := range i.Identity.Values {
-			newValues = addChildren(j, newValues)
+		seen := map[*Identity]bool{}
+		for _, j := range i.Values {
+			newValues = addChildren(j, newValues, seen)
 		}
-		i.Identity.Values = newValues
+		i.Values = newValues
 	}
 
 	return errs
@@ -15,6 +15,7 @@
 package yang
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 
@@ -559,3 +560,40 @@ func TestIdentityTree(t *testing.T) {
 		}
 	}
 }
+
+func TestIdentityManyModules(t *testing.T) {
+	// Each module derives an identity from the identity of the previous
+	// module, so the first identity has all others as values.
+	const n = 50
+	ms := NewModules()
+	for i := 0; i < n; i++ {
+		var imp, base string
+		if i > 0 {
+			imp = fmt.Sprintf("import chain%d { prefix p%d; }", i-1, i-1)
+			base = fmt.Sprintf("base p%d:id%d;", i-1, i-1)
+		}
+		text := fmt.Sprintf(`
+			module chain%d {
+				namespace "urn:chain%d";
+				prefix "c%d";
+				%s
+				identity id%d { %s }
+			}`, i, i, i, imp, i, base)
+		if err := ms.Parse(text, fmt.Sprintf("chain%d.yang", i)); err != nil {
+			t.Fatalf("cannot parse chain%d: %v", i, err)
+		}
+	}
+	// Processing more than once must not duplicate any values.
+	for p := 0; p < 2; p++ {
+		if errs := ms.Process(); len(errs) != 0 {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+		id := ms.Modules["chain0"].Identity[0]
+		if got := len(id.Values); got != n-1 {
+			t.Errorf("pass %d: got %d values for id0, want %d", p, got, n-1)
+		}
+		if !id.IsDefined(fmt.Sprintf("id%d", n-1)) {
+			t.Errorf("pass %d: id%d is not a value of id0", p, n-1)
+		}
+	}
+}
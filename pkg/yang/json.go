@@ -0,0 +1,155 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements streaming the JSON form of an Entry tree.
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// WriteJSON writes e and all of its descendants to w as JSON.  The result
+// is the same JSON object that json.Marshal(e) produces, except that the
+// fields holding child entries (Dir, RPC, Augments, Augmented, and
+// Structures) are written last.  Unlike json.Marshal, WriteJSON only
+// encodes a single entry at a time and writes it out before moving on to
+// the next, so the encoded form of the entire tree is never held in memory.
+func (e *Entry) WriteJSON(w io.Writer) error {
+	return e.WriteJSONContext(context.Background(), w)
+}
+
+// WriteJSONContext is like WriteJSON but stops writing and returns
+// ctx.Err() once ctx is done.  The JSON written to w is then incomplete.
+func (e *Entry) WriteJSONContext(ctx context.Context, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if err := writeEntryJSON(ctx, bw, e); err != nil {
+		bw.Flush()
+		return err
+	}
+	return bw.Flush()
+}
+
+// writeEntryJSON writes the JSON form of e to w.  Errors writing to w are
+// retained by w and returned by its Flush method.
+func writeEntryJSON(ctx context.Context, w *bufio.Writer, e *Entry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if e == nil {
+		w.WriteString("null")
+		return nil
+	}
+
+	// Encode all the fields that do not contain entries.
+	ne := *e
+	ne.Dir = nil
+	ne.RPC = nil
+	ne.Augments = nil
+	ne.Augmented = nil
+	ne.Structures = nil
+	b, err := json.Marshal(&ne)
+	if err != nil {
+		return err
+	}
+	// Strip the closing brace so the remaining fields can be added.
+	b = b[:len(b)-1]
+	w.Write(b)
+	needComma := len(b) > 1
+
+	field := func(name string) {
+		if needComma {
+			w.WriteByte(',')
+		}
+		needComma = true
+		w.WriteString(`"` + name + `":`)
+	}
+	entries := func(name string, es []*Entry) error {
+		if len(es) == 0 {
+			return nil
+		}
+		field(name)
+		w.WriteByte('[')
+		for x, ce := range es {
+			if x > 0 {
+				w.WriteByte(',')
+			}
+			if err := writeEntryJSON(ctx, w, ce); err != nil {
+				return err
+			}
+		}
+		w.WriteByte(']')
+		return nil
+	}
+
+	dir := func(name string, d map[string]*Entry) error {
+		if len(d) == 0 {
+			return nil
+		}
+		field(name)
+		names := make([]string, 0, len(d))
+		for k := range d {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		w.WriteByte('{')
+		for x, k := range names {
+			if x > 0 {
+				w.WriteByte(',')
+			}
+			kb, err := json.Marshal(k)
+			if err != nil {
+				return err
+			}
+			w.Write(kb)
+			w.WriteByte(':')
+			if err := writeEntryJSON(ctx, w, d[k]); err != nil {
+				return err
+			}
+		}
+		w.WriteByte('}')
+		return nil
+	}
+
+	if err := dir("Dir", e.Dir); err != nil {
+		return err
+	}
+	if e.RPC != nil {
+		field("RPC")
+		w.WriteString(`{"Input":`)
+		if err := writeEntryJSON(ctx, w, e.RPC.Input); err != nil {
+			return err
+		}
+		w.WriteString(`,"Output":`)
+		if err := writeEntryJSON(ctx, w, e.RPC.Output); err != nil {
+			return err
+		}
+		w.WriteByte('}')
+	}
+	if err := entries("Augments", e.Augments); err != nil {
+		return err
+	}
+	if err := entries("Augmented", e.Augmented); err != nil {
+		return err
+	}
+	if err := dir("Structures", e.Structures); err != nil {
+		return err
+	}
+	w.WriteByte('}')
+	return nil
+}
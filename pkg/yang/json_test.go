@@ -0,0 +1,87 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteJSON(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module json-test {
+			prefix "j";
+			namespace "urn:j";
+			container c {
+				description "a \"quoted\" container";
+				leaf a { type string; }
+				list l {
+					key "k";
+					leaf k { type int8 { range "1..10"; } }
+				}
+			}
+			rpc r {
+				input { leaf in { type string; } }
+			}
+			augment "/j:c" {
+				leaf aug { type string; }
+			}
+		}
+	`, "json-test.yang"); err != nil {
+		t.Fatalf("cannot parse: %v", err)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	e := ToEntry(ms.Modules["json-test"])
+
+	var buf bytes.Buffer
+	if err := e.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	want, err := json.Marshal(e)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+
+	// The field order differs, so compare the decoded forms.
+	var got, wantv interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("WriteJSON produced invalid JSON: %v\n%s", err, &buf)
+	}
+	if err := json.Unmarshal(want, &wantv); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(wantv, got); diff != "" {
+		t.Errorf("WriteJSON (-want, +got):\n%s", diff)
+	}
+
+	buf.Reset()
+	small := &Entry{
+		Name:       "s",
+		Dir:        map[string]*Entry{"x": {Name: "x"}},
+		Structures: map[string]*Entry{"st": {Name: "st"}},
+	}
+	if err := small.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	if got, want := buf.String(), `{"Name":"s","Kind":0,"Config":0,"Dir":{"x":{"Name":"x","Kind":0,"Config":0}},"Structures":{"st":{"Name":"st","Kind":0,"Config":0}}}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the checks of the revisions of modules and the
+// leniency toward the quirks of real-world modules given by
+// Options.Lenient.
+
+import (
+	"strings"
+	"time"
+)
+
+// revisionDate is the layout of the date of a revision (RFC 7950 section
+// 7.1.9).
+const revisionDate = "2006-01-02"
+
+// checkSources returns the warnings about the revision and revision-date
+// statements of the modules of ms, along with the warnings about the
+// statements that were skipped when the modules were parsed.  Revision
+// dates that are not YYYY-MM-DD and duplicate revisions are common in
+// real-world modules, so they are reported as warnings rather than
+// errors.
+func (ms *Modules) checkSources() []error {
+	var ws []error
+	checkDate := func(n Node, kind, date string) {
+		if _, err := time.Parse(revisionDate, date); err != nil {
+			ws = append(ws, warnf(n, WarnBadRevisionDate, "invalid %s %q, must be YYYY-MM-DD", kind, date))
+		}
+	}
+	for _, m := range ms.sortedModules() {
+		ws = append(ws, m.skipped...)
+		seen := map[string]bool{}
+		for _, r := range m.Revision {
+			checkDate(r, "revision date", r.Name)
+			if seen[r.Name] {
+				ws = append(ws, warnf(r, WarnDuplicateRevision, "duplicate revision %s of %s", r.Name, m.Name))
+			}
+			seen[r.Name] = true
+		}
+		for _, i := range m.Import {
+			if i.RevisionDate != nil {
+				checkDate(i.RevisionDate, "revision-date", i.RevisionDate.Name)
+			}
+		}
+		for _, i := range m.Include {
+			if i.RevisionDate != nil {
+				checkDate(i.RevisionDate, "revision-date", i.RevisionDate.Name)
+			}
+		}
+	}
+	return ws
+}
+
+// skipUnknown removes the substatements of s, and recursively of its
+// substatements, that build would reject as unknown.  The remaining
+// substatements are copied to a new list so that the removal is not seen
+// through other references to the original one.  It returns a warning for
+// each statement removed.
+func skipUnknown(s *Statement) []error {
+	kind := s.Keyword
+	if k := aliases[kind]; k != "" {
+		kind = k
+	}
+	t := nameMap[kind]
+	if t == nil {
+		return nil
+	}
+	y := typeMap[t]
+	var ws []error
+	kept := make([]*Statement, 0, len(s.statements))
+	for _, ss := range s.statements {
+		if !knownSubstatement(y, s.Keyword, ss.Keyword) {
+			ws = append(ws, warnf(ss, ErrUnexpectedSubstatement, "ignoring unknown %s field: %s", s.Keyword, ss.Keyword))
+			continue
+		}
+		ws = append(ws, skipUnknown(ss)...)
+		kept = append(kept, ss)
+	}
+	s.statements = kept
+	return ws
+}
+
+// knownSubstatement reports whether build accepts a substatement with
+// keyword in a statement with keyword parent, whose type is described by
+// y.
+func knownSubstatement(y *yangStatement, parent, keyword string) bool {
+	if strings.Contains(keyword, ":") {
+		return true
+	}
+	if y.funcs[keyword] == nil {
+		return false
+	}
+	for n, rs := range y.sRequired {
+		if n == parent {
+			continue
+		}
+		for _, r := range rs {
+			if r == keyword {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,167 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLenient(t *testing.T) {
+	defer func(o Options) { ParseOptions = o }(ParseOptions)
+
+	const in = `module q {
+  prefix "q";
+  namespace "urn:q";
+  revision 2020-02-30;
+  revision 2019-01-01;
+  revision 2019-01-01;
+  container c {
+    vendor-thing x;
+    leaf l { type string; units "s"; max-length 10; }
+  }
+}`
+
+	for _, tt := range []struct {
+		desc         string
+		lenient      bool
+		wantParseErr string
+		wantErrs     []string
+		wantWarnings []string
+	}{{
+		desc:         "default",
+		wantParseErr: "q.yang:8:5: unknown container field: vendor-thing",
+	}, {
+		desc:    "lenient",
+		lenient: true,
+		wantWarnings: []string{
+			`q.yang:4:3: invalid revision date "2020-02-30", must be YYYY-MM-DD`,
+			"q.yang:6:3: duplicate revision 2019-01-01 of q",
+			"q.yang:8:5: ignoring unknown container field: vendor-thing",
+			"q.yang:9:38: ignoring unknown leaf field: max-length",
+		},
+	}} {
+		ParseOptions.Lenient = tt.lenient
+		ms := NewModules()
+		err := ms.Parse(in, "q.yang")
+		var gotErr string
+		if err != nil {
+			gotErr = err.Error()
+		}
+		if gotErr != tt.wantParseErr {
+			t.Errorf("%s: Parse got error %q, want %q", tt.desc, gotErr, tt.wantParseErr)
+		}
+		if err != nil {
+			continue
+		}
+		var gotErrs, gotWarnings []string
+		for _, err := range ms.Process() {
+			gotErrs = append(gotErrs, err.Error())
+		}
+		for _, w := range ms.Warnings() {
+			gotWarnings = append(gotWarnings, w.Error())
+		}
+		if diff := cmp.Diff(tt.wantErrs, gotErrs); diff != "" {
+			t.Errorf("%s: Process errors (-want, +got):\n%s", tt.desc, diff)
+		}
+		if diff := cmp.Diff(tt.wantWarnings, gotWarnings); diff != "" {
+			t.Errorf("%s: Process warnings (-want, +got):\n%s", tt.desc, diff)
+		}
+		if e := ToEntry(ms.Modules["q"]); e.Dir["c"] == nil || e.Dir["c"].Dir["l"] == nil {
+			t.Errorf("%s: leaf c/l not found", tt.desc)
+		}
+	}
+}
+
+func TestRevisionWarnings(t *testing.T) {
+	defer func(o Options) { ParseOptions = o }(ParseOptions)
+
+	const r = `module r {
+  prefix "r";
+  namespace "urn:r";
+  import s { prefix "s"; revision-date 2020-1-1; }
+  revision 2020-13-01;
+  revision 2019-01-01;
+  revision 2019-01-01;
+}`
+	diags := []string{
+		`r.yang:4:26: invalid revision-date "2020-1-1", must be YYYY-MM-DD`,
+		`r.yang:5:3: invalid revision date "2020-13-01", must be YYYY-MM-DD`,
+		"r.yang:7:3: duplicate revision 2019-01-01 of r",
+	}
+
+	for _, tt := range []struct {
+		desc         string
+		severities   map[Code]Severity
+		wantErrs     []string
+		wantWarnings []string
+	}{{
+		desc:         "default",
+		wantWarnings: diags,
+	}, {
+		desc:       "errors",
+		severities: map[Code]Severity{WarnBadRevisionDate: SeverityError, WarnDuplicateRevision: SeverityError},
+		wantErrs:   diags,
+	}, {
+		desc:         "ignored",
+		severities:   map[Code]Severity{WarnBadRevisionDate: SeverityIgnore},
+		wantWarnings: diags[2:],
+	}} {
+		ParseOptions.Severities = tt.severities
+		ms := NewModules()
+		if err := ms.Parse(r, "r.yang"); err != nil {
+			t.Fatal(err)
+		}
+		if err := ms.Parse(`module s { prefix "s"; namespace "urn:s"; }`, "s.yang"); err != nil {
+			t.Fatal(err)
+		}
+		var gotErrs, gotWarnings []string
+		for _, err := range ms.Process() {
+			gotErrs = append(gotErrs, err.Error())
+		}
+		for _, w := range ms.Warnings() {
+			// Only the diagnostics about revisions are of interest.
+			if c := ErrorCode(w); c == WarnBadRevisionDate || c == WarnDuplicateRevision {
+				gotWarnings = append(gotWarnings, w.Error())
+			}
+		}
+		if diff := cmp.Diff(tt.wantErrs, gotErrs); diff != "" {
+			t.Errorf("%s: Process errors (-want, +got):\n%s", tt.desc, diff)
+		}
+		if diff := cmp.Diff(tt.wantWarnings, gotWarnings); diff != "" {
+			t.Errorf("%s: Process warnings (-want, +got):\n%s", tt.desc, diff)
+		}
+	}
+}
+
+func TestSkipUnknownCopies(t *testing.T) {
+	ss, err := Parse(`container c { vendor-thing x; leaf l { type string; } }`, "c.yang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := ss[0]
+	orig := c.SubStatements()
+	want := append([]*Statement(nil), orig...)
+	if ws := skipUnknown(c); len(ws) != 1 {
+		t.Fatalf("got warnings %v, want 1 warning", ws)
+	}
+	if diff := cmp.Diff(want, orig, cmp.Comparer(func(a, b *Statement) bool { return a == b })); diff != "" {
+		t.Errorf("skipUnknown changed the original substatements (-want, +got):\n%s", diff)
+	}
+	if got := c.SubStatements(); len(got) != 1 || got[0].Keyword != "leaf" {
+		t.Errorf("got substatements %v, want leaf l", got)
+	}
+}
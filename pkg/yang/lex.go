@@ -26,10 +26,7 @@ package yang
 //    '}'
 
 import (
-	"bytes"
 	"fmt"
-	"io"
-	"os"
 	"reflect"
 	"runtime"
 	"strings"
@@ -39,7 +36,7 @@ import (
 const (
 	eof       = 0x7fffffff // end of file, also an invalid rune
 	maxErrors = 8
-	tooMany   = "too many errors...\n"
+	tooMany   = "too many errors..."
 
 	openBrace  = '{'
 	closeBrace = '}'
@@ -51,8 +48,8 @@ type stateFn func(*lexer) stateFn
 
 // A lexer holds the internal state of the lexer.
 type lexer struct {
-	errout io.Writer // destination for errors, defaults to os.Stderr
-	errcnt int       // number of errors encountered
+	errs   Errors // errors encountered, see Errorf
+	errcnt int    // number of errors encountered
 
 	file  string // name of file we are processing
 	input string // contents of the file
@@ -61,12 +58,14 @@ type lexer struct {
 	line  int    // the current line number (1's based)
 	col   int    // the current column number (0 based, add 1 before displaying)
 
+	opts      *Options    // options of the parse, ParseOptions by default
 	debug     bool        // set to true to include internal debugging
 	inPattern bool        // set when parsing the argument to a pattern
 	items     chan *token // channel of scanned items.
 	tcol      int         // column with tabs expanded (for multi-line strings)
 	scol      int         // starting col of current token
 	sline     int         // starting line of current token
+	soff      int         // starting byte offset of current token
 	state     stateFn     // current state of the lexer
 	width     int         // width of last rune read from input.
 }
@@ -106,6 +105,12 @@ type token struct {
 	File string // the source file the token is from
 	Line int    // the source line number the token is from
 	Col  int    // the source column number the token is from (8 space tabs)
+
+	// Offset and End are the byte offsets in the source of the start of
+	// the token and of the byte following it.  The span includes the
+	// quotes of a quoted string.
+	Offset int
+	End    int
 }
 
 // Code returns the code of t.  If t is nil, tEOF is returned.
@@ -116,6 +121,20 @@ func (t *token) Code() code {
 	return t.code
 }
 
+// position returns the location of t.
+func (t *token) position() Position {
+	return Position{File: t.File, Line: t.Line, Col: t.Col}
+}
+
+// name returns the text of t, or its code if it has no text, to name t in
+// errors.
+func (t *token) name() string {
+	if t.Text == "" {
+		return t.code.String()
+	}
+	return t.Text
+}
+
 // String returns the location, code, and text of t as a string.
 func (t *token) String() string {
 	var s []string
@@ -133,10 +152,6 @@ func (t *token) String() string {
 	return strings.Join(s, "")
 }
 
-// A note on writing to errout.  Errors should always be written to errout
-// in a single Write call.  The test code makes this assumption for testing
-// expected errors.
-
 // newLexer imports the provided input into the lexer l at its the current
 // location, returning the lexer.  If l is nil then a new lexer is returned.
 // The provided path should indicate where the source originated.
@@ -146,12 +161,12 @@ func newLexer(input, path string) *lexer {
 		input += "\n"
 	}
 	return &lexer{
-		file:   path,
-		input:  input,
-		line:   1, // humans start with 1
-		items:  make(chan *token, 3),
-		state:  lexGround,
-		errout: os.Stderr,
+		file:  path,
+		input: input,
+		line:  1, // humans start with 1
+		items: make(chan *token, 3),
+		state: lexGround,
+		opts:  &ParseOptions,
 	}
 }
 
@@ -173,7 +188,7 @@ func (l *lexer) NextToken() *token {
 				if len(input) > 8 {
 					input = input[:8] + "..."
 				}
-				fmt.Fprintf(os.Stderr, "%d:%d: state %s %q\n", l.line, l.col+1, name, input)
+				logf(l.opts, LevelDebug, []Field{{"file", l.file}}, "%d:%d: state %s %q", l.line, l.col+1, name, input)
 			}
 			l.state = l.state(l)
 		}
@@ -189,7 +204,7 @@ func (l *lexer) emit(c code) {
 // All input up to the current cursor (pos) is consumed.
 func (l *lexer) emitText(c code, text string) {
 	if l.debug {
-		fmt.Fprintf(os.Stderr, "%v: %q\n", c, text)
+		logf(l.opts, LevelDebug, []Field{{"file", l.file}}, "%v: %q", c, text)
 	}
 	l.items <- &token{
 		code: c,
@@ -197,6 +212,9 @@ func (l *lexer) emitText(c code, text string) {
 		File: l.file,
 		Line: l.sline,
 		Col:  l.scol + 1,
+
+		Offset: l.soff,
+		End:    l.pos,
 	}
 	l.consume()
 }
@@ -297,27 +315,18 @@ func (l *lexer) updateCursor(n int) {
 	l.col += utf8.RuneCountInString(s[strings.LastIndex(s, "\n")+1:])
 }
 
-// Errorf writes an error on l.errout and increments the error count.
-// If too many errors (8) are encountered then lexing will stop and
-// eof is returned as the next token.
+// Errorf records a syntax error at the current location in l.errs and
+// increments the error count.  If too many errors (8) are encountered then
+// lexing will stop and eof is returned as the next token.
 func (l *lexer) Errorf(f string, v ...interface{}) {
-	buf := &bytes.Buffer{}
-
 	if l.debug {
-		// For internal debugging, print the file and line number
+		// For internal debugging, log the file and line number
 		// of the call to Errorf
 		_, name, line, _ := runtime.Caller(1)
-
-		fmt.Fprintf(buf, "%s:%d: ", name, line)
-	}
-	fmt.Fprintf(buf, "%s:%d:%d: ", l.file, l.line, l.col+1)
-	fmt.Fprintf(buf, f, v...)
-	b := buf.Bytes()
-	if b[len(b)-1] != '\n' {
-		buf.Write([]byte{'\n'})
+		logf(l.opts, LevelDebug, []Field{{"file", l.file}}, "%d:%d: error reported at %s:%d", l.line, l.col+1, name, line)
 	}
 	l.emit(tError)
-	l.adderror(buf.Bytes())
+	l.adderror(errorfAt(Position{File: l.file, Line: l.line, Col: l.col + 1}, ErrSyntax, f, v...))
 }
 
 func (l *lexer) ErrorfAt(line, col int, f string, v ...interface{}) {
@@ -329,18 +338,18 @@ func (l *lexer) ErrorfAt(line, col int, f string, v ...interface{}) {
 	l.Errorf(f, v...)
 }
 
-// adderror writes out the error string err and increases the error count.
-// If more than maxErrors are encountered, a "too many errors" message is
-// displayed and processing stops (by clearing the input).
-func (l *lexer) adderror(err []byte) {
+// adderror records the error err and increases the error count.  If more
+// than maxErrors are encountered, a "too many errors" error is recorded
+// and processing stops (by clearing the input).
+func (l *lexer) adderror(err error) {
 	if l.errcnt >= maxErrors {
 		l.pos = 0
 		l.start = 0
 		l.input = ""
-		l.errout.Write([]byte(tooMany))
+		l.errs = append(l.errs, errorf(nil, ErrTooManyErrors, tooMany))
 		return
 	}
-	l.errout.Write(err)
+	l.errs = append(l.errs, err)
 	l.errcnt++
 }
 
@@ -354,6 +363,7 @@ func lexGround(l *lexer) stateFn {
 	l.consume()
 	l.sline = l.line
 	l.scol = l.col
+	l.soff = l.pos
 
 	switch c := l.peek(); c {
 	case eof:
@@ -366,8 +376,9 @@ func lexGround(l *lexer) stateFn {
 		l.next()
 		l.consume() // Toss the leading '
 		l.skipTo("'")
-		l.emit(tString)
+		text := l.input[l.start:l.pos]
 		l.next() // Either EOF or the matching '
+		l.emitText(tString, text)
 		return lexGround
 	case '"':
 		l.next()
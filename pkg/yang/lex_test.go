@@ -15,7 +15,6 @@
 package yang
 
 import (
-	"bytes"
 	"runtime"
 	"testing"
 )
@@ -198,15 +197,13 @@ func TestLexErrors(t *testing.T) {
 		{line(),
 			`1: "no closing quote`,
 			1,
-			`test.yang:1:4: missing closing "
-`,
+			`test.yang:1:4: missing closing "`,
 		},
 		{line(),
 			`1: on another line
 2: there is "no closing quote\"`,
 			1,
-			`test.yang:2:13: missing closing "
-`,
+			`test.yang:2:13: missing closing "`,
 		},
 		{line(),
 			`1:
@@ -217,8 +214,7 @@ func TestLexErrors(t *testing.T) {
 6: "I'ld eat ivy too.
 5: So saith the sage.`,
 			1,
-			`test.yang:6:4: missing closing "
-`,
+			`test.yang:6:4: missing closing "`,
 		},
 		{line(),
 			`1:
@@ -227,21 +223,17 @@ func TestLexErrors(t *testing.T) {
 4: "Another quoted string"
 `,
 			1,
-			`test.yang:4:26: missing closing "
-`,
+			`test.yang:4:26: missing closing "`,
 		},
 	} {
 		l := newLexer(tt.in, "test.yang")
-		errbuf := &bytes.Buffer{}
-		l.errout = errbuf
 		for l.NextToken() != nil {
 
 		}
 		if l.errcnt != tt.errcnt {
 			t.Errorf("%d: got %d errors, want %v", tt.line, l.errcnt, tt.errcnt)
 		}
-		errs := errbuf.String()
-		if errs != tt.errs {
+		if errs := l.errs.Error(); errs != tt.errs {
 			t.Errorf("%d: got errors:\n%s\nwant:\n%s", tt.line, errs, tt.errs)
 		}
 	}
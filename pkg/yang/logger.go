@@ -0,0 +1,136 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the logger used for goyang's internal messages.
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// A Level is the importance of a logged message.
+type Level int
+
+const (
+	// LevelDebug messages trace the inner workings of the package.
+	LevelDebug Level = iota
+	// LevelInfo messages report normal progress.
+	LevelInfo
+	// LevelWarn messages report unexpected conditions that goyang was
+	// able to work around.
+	LevelWarn
+	// LevelError messages report problems that could not be reported
+	// as an error to the caller.
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+func (l Level) String() string {
+	if n := levelNames[l]; n != "" {
+		return n
+	}
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// A Field is a key/value pair attached to a logged message.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// A Logger receives the internal messages of the package.  Set the Logger
+// option to route them into an application's own logging.
+// Log may be called from multiple goroutines.
+type Logger interface {
+	Log(level Level, msg string, fields ...Field)
+}
+
+// A LoggerFunc is a function used as a Logger.
+type LoggerFunc func(level Level, msg string, fields ...Field)
+
+// Log calls f(level, msg, fields...).
+func (f LoggerFunc) Log(level Level, msg string, fields ...Field) {
+	f(level, msg, fields...)
+}
+
+// NewLogger returns a Logger that writes messages of at least level min
+// to w, one per line, in the form:
+//
+//   level: msg key=value ...
+func NewLogger(w io.Writer, min Level) Logger {
+	return &writerLogger{w: w, min: min}
+}
+
+type writerLogger struct {
+	mu  sync.Mutex
+	w   io.Writer
+	min Level
+}
+
+func (l *writerLogger) Log(level Level, msg string, fields ...Field) {
+	if level < l.min {
+		return
+	}
+	b := []byte(level.String() + ": " + msg)
+	for _, f := range fields {
+		b = append(b, fmt.Sprintf(" %s=%v", f.Key, f.Value)...)
+	}
+	b = append(b, '\n')
+	l.mu.Lock()
+	l.w.Write(b)
+	l.mu.Unlock()
+}
+
+// defaultLogger is used when the Logger option is nil.  It preserves the
+// historical behavior of writing warnings and errors to standard error.
+var defaultLogger = NewLogger(os.Stderr, LevelWarn)
+
+// logger returns the Logger selected by o.
+func logger(o *Options) Logger {
+	if o.Logger != nil {
+		return o.Logger
+	}
+	return defaultLogger
+}
+
+// logf formats a message and logs it at level with fields to the Logger
+// selected by o.
+func logf(o *Options, level Level, fields []Field, format string, v ...interface{}) {
+	logger(o).Log(level, fmt.Sprintf(format, v...), fields...)
+}
+
+// tracef logs a message about n at LevelDebug if the Debug option of n's
+// Modules is set.  It is used to trace how types, groupings, augments, and
+// deviations are resolved and applied.
+func tracef(n Node, format string, v ...interface{}) {
+	o := optionsOf(n)
+	if !o.Debug {
+		return
+	}
+	var fields []Field
+	if n != nil {
+		fields = []Field{{"pos", Source(n)}, {"path", StatementPath(n)}}
+	}
+	logf(o, LevelDebug, fields, format, v...)
+}
@@ -0,0 +1,117 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLevelString(t *testing.T) {
+	for _, tt := range []struct {
+		level Level
+		want  string
+	}{
+		{LevelDebug, "debug"},
+		{LevelInfo, "info"},
+		{LevelWarn, "warn"},
+		{LevelError, "error"},
+		{Level(42), "level(42)"},
+	} {
+		if got := tt.level.String(); got != tt.want {
+			t.Errorf("Level(%d).String(): got %q, want %q", int(tt.level), got, tt.want)
+		}
+	}
+}
+
+func TestNewLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger(&buf, LevelInfo)
+	l.Log(LevelDebug, "hidden")
+	l.Log(LevelInfo, "loaded", Field{"module", "a"}, Field{"count", 2})
+	l.Log(LevelError, "broken")
+	want := "info: loaded module=a count=2\nerror: broken\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLoggerOption(t *testing.T) {
+	type message struct {
+		Level  Level
+		Msg    string
+		Fields []Field
+	}
+	var got []message
+	opts := &Options{Logger: LoggerFunc(func(level Level, msg string, fields ...Field) {
+		got = append(got, message{level, msg, fields})
+	})}
+	logf(opts, LevelWarn, []Field{{"file", "a.yang"}}, "%s: odd", "x")
+	want := []message{{LevelWarn, "x: odd", []Field{{"file", "a.yang"}}}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want, +got):\n%s", diff)
+	}
+}
+
+func TestDebugTrace(t *testing.T) {
+	defer func(o Options) { ParseOptions = o }(ParseOptions)
+
+	var buf bytes.Buffer
+	ParseOptions.Logger = NewLogger(&buf, LevelDebug)
+	ParseOptions.Debug = true
+
+	ms := NewModules()
+	if err := ms.Parse(`module d {
+  prefix d;
+  namespace urn:d;
+  typedef name { type string; }
+  grouping g { leaf l { type name; } }
+  container c { uses g; }
+  augment /d:c { leaf m { type int8; } }
+  deviation /d:c/d:m { deviate not-supported; }
+}`, "d.yang"); err != nil {
+		t.Fatal(err)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	for _, want := range []string{
+		"debug: type string is builtin pos=d.yang:4:18 path=module d / typedef name / type string\n",
+		"debug: type name is local typedef name at d.yang:4:3 pos=d.yang:5:25 path=module d / grouping g / leaf l / type name\n",
+		"debug: expanding grouping g at d.yang:5:3 pos=d.yang:6:17 path=module d / container c / uses g\n",
+		"debug: augmenting /d/c with /d:c pos=d.yang:7:3 path=module d / augment /d:c\n",
+		"debug: deviate not-supported /d/c/m pos=d.yang:8:24 path=module d / deviation /d:c/d:m / deviate not-supported\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("trace does not contain %q:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	ParseOptions.Debug = false
+	ms = NewModules()
+	if err := ms.Parse(`module e { prefix e; namespace urn:e; leaf l { type string; } }`, "e.yang"); err != nil {
+		t.Fatal(err)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("got trace without Debug:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the message catalog used to customize the text of
+// errors and warnings.
+
+import "fmt"
+
+// A Catalog supplies the text of errors and warnings.  Set the Catalog
+// option to change their phrasing or to translate them.
+//
+// Message returns the format to use in place of format, the built-in
+// English format of a message reported with code.  The returned format is
+// given the same arguments as format, so it must use the same verbs.
+// Arguments may be reordered with explicit argument indexes, e.g., %[2]s.
+// The location of the offending statement is not part of the format and
+// is always added before the message.
+type Catalog interface {
+	Message(code Code, format string) string
+}
+
+// Messages is a Catalog that maps built-in formats to their replacements.
+// Formats that are not in the map are left unchanged.  For example:
+//
+//   ms := yang.NewModulesWithOptions(yang.Options{
+//       Catalog: yang.Messages{
+//           "unknown type: %s": "type %s is not defined",
+//       },
+//   })
+type Messages map[string]string
+
+// Message returns m[format], or format if it is not in m.
+func (m Messages) Message(_ Code, format string) string {
+	if f, ok := m[format]; ok {
+		return f
+	}
+	return format
+}
+
+// msgf formats a message reported with code using the format supplied by
+// c, if not nil, in place of format.
+func msgf(c Catalog, code Code, format string, v ...interface{}) string {
+	if c != nil {
+		format = c.Message(code, format)
+	}
+	return fmt.Sprintf(format, v...)
+}
+
+// localize replaces the messages of the errors in errs that were reported
+// by this package with those supplied by the Catalog option of ms.  It is
+// called before errors are returned by the methods of ms, as errors are
+// first formatted with ParseOptions.Catalog when they are created.
+func (ms *Modules) localize(errs ...error) {
+	for _, err := range errs {
+		switch err := err.(type) {
+		case *Error:
+			if err.format != "" {
+				err.Msg = msgf(ms.opts.Catalog, err.Code, err.format, err.args...)
+			}
+		case Errors:
+			ms.localize(err...)
+		}
+	}
+}
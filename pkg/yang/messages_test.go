@@ -0,0 +1,70 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"testing"
+
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+func TestCatalog(t *testing.T) {
+	opts := Options{Catalog: Messages{
+		"unknown type: %s%s":       "type %[1]s is not defined%[2]s",
+		"missing %d closing brace": "%d brace is not closed",
+		"unexpected %c":            "stray %c",
+		"no such file: %s":         "%s does not exist",
+	}}
+	for _, tt := range []struct {
+		name string
+		in   string
+		want string
+	}{{
+		name: "process",
+		in:   `module m { prefix "m"; namespace "urn:m"; leaf l { type nope; } }`,
+		want: "m.yang:1:52: type m:nope is not defined",
+	}, {
+		name: "parser",
+		in:   `module m { prefix "m"; namespace "urn:m";`,
+		want: "m.yang:2:0: 1 brace is not closed",
+	}, {
+		name: "extra brace",
+		in:   `module m { prefix "m"; namespace "urn:m"; } }`,
+		want: "stray }",
+	}, {
+		name: "not in catalog",
+		in:   `module m { prefix "m"; namespace "urn:m"; container c { uses nope; } }`,
+		want: "unknown group: nope",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := NewModulesWithOptions(opts)
+			err := ms.Parse(tt.in, "m.yang")
+			if err == nil {
+				if errs := ms.Process(); len(errs) > 0 {
+					err = errs
+				}
+			}
+			if diff := errdiff.Substring(err, tt.want); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	// Errors that are not about a statement use the catalog too.
+	err := NewModulesWithOptions(opts).Read("no-such-module")
+	if diff := errdiff.Substring(err, "no-such-module.yang does not exist"); diff != "" {
+		t.Error(diff)
+	}
+}
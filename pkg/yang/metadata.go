@@ -0,0 +1,148 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements support for metadata annotations as defined by the
+// ietf-yang-metadata module (RFC 7952).
+
+import (
+	"reflect"
+	"strings"
+)
+
+// yangMetadata is the name of the module that defines the annotation
+// extension.
+const yangMetadata = "ietf-yang-metadata"
+
+// An Annotation is a metadata annotation defined by an md:annotation
+// statement.
+type Annotation struct {
+	Name        string
+	Module      *Module    // the module or submodule defining the annotation
+	Source      *Statement // the md:annotation statement
+	Type        *YangType  // the resolved type of the annotation's values
+	Units       string
+	Description string
+	Reference   string
+	Status      string
+	IfFeature   []string
+}
+
+// JSONName returns the name used for a in the JSON encoding of instance
+// data, i.e., the name of a's module, a colon, and a's name (RFC 7952
+// section 5.2.1).
+func (a *Annotation) JSONName() string {
+	return belongsTo(a.Module).Name + ":" + a.Name
+}
+
+// Namespace returns the XML namespace of a, which is the namespace of a's
+// module (RFC 7952 section 5.1).
+func (a *Annotation) Namespace() string {
+	return belongsTo(a.Module).Namespace.asString()
+}
+
+// setAnnotations sets m.Annotations from the md:annotation statements of m,
+// resolving the type of each annotation.
+func (m *Module) setAnnotations() []error {
+	m.Annotations = nil
+	var errs []error
+	for _, ext := range m.Extensions {
+		if extensionFrom(ext, m, yangMetadata) != "annotation" {
+			continue
+		}
+		a, err := m.newAnnotation(ext)
+		if err != nil {
+			errs = append(errs, err...)
+			continue
+		}
+		m.Annotations = append(m.Annotations, a)
+	}
+	return errs
+}
+
+// newAnnotation returns the annotation defined by the md:annotation
+// statement s found in m.
+func (m *Module) newAnnotation(s *Statement) (*Annotation, []error) {
+	a := &Annotation{
+		Name:   s.Argument,
+		Module: m,
+		Source: s,
+	}
+	var t *Type
+	for _, ss := range s.SubStatements() {
+		switch ss.Keyword {
+		case "type":
+			if t != nil {
+				return nil, []error{errorf(ss, ErrDuplicateSubstatement, "annotation %s has multiple types", a.Name)}
+			}
+			v, err := build(ss, reflect.ValueOf(m))
+			if err != nil {
+				return nil, []error{err}
+			}
+			t = v.Interface().(*Type)
+		case "units":
+			a.Units = ss.Argument
+		case "description":
+			a.Description = ss.Argument
+		case "reference":
+			a.Reference = ss.Argument
+		case "status":
+			a.Status = ss.Argument
+		case "if-feature":
+			a.IfFeature = append(a.IfFeature, ss.Argument)
+		default:
+			if !strings.Contains(ss.Keyword, ":") {
+				return nil, []error{errorf(ss, ErrUnexpectedSubstatement, "unknown annotation field: %s", ss.Keyword)}
+			}
+		}
+	}
+	if t == nil {
+		return nil, []error{errorf(s, ErrMissingSubstatement, "annotation %s has no type", a.Name)}
+	}
+	if errs := t.resolve(); len(errs) > 0 {
+		return nil, errs
+	}
+	a.Type = t.YangType
+	return a, nil
+}
+
+// FindAnnotation returns the annotation named name that is defined by the
+// module named module, or its submodules, or nil if there is no such
+// annotation.  In JSON encoded instance data annotations are named
+// "module:name".  Process must be called before FindAnnotation.
+func (ms *Modules) FindAnnotation(module, name string) *Annotation {
+	for _, m := range ms.sortedModules() {
+		if belongsTo(m).Name != module {
+			continue
+		}
+		for _, a := range m.Annotations {
+			if a.Name == name {
+				return a
+			}
+		}
+	}
+	return nil
+}
+
+// FindAnnotationByNamespace is like FindAnnotation but the module is
+// identified by its namespace, as annotations are in XML encoded instance
+// data.
+func (ms *Modules) FindAnnotationByNamespace(ns, name string) *Annotation {
+	m, err := ms.FindModuleByNamespace(ns)
+	if err != nil {
+		return nil
+	}
+	return ms.FindAnnotation(m.Name, name)
+}
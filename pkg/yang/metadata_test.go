@@ -0,0 +1,162 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"testing"
+
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+const metadataModule = `
+module ietf-yang-metadata {
+	namespace "urn:ietf:params:xml:ns:yang:ietf-yang-metadata";
+	prefix "md";
+	extension annotation { argument name; }
+}
+`
+
+func TestAnnotations(t *testing.T) {
+	mods := map[string]string{
+		"ietf-yang-metadata": metadataModule,
+		"md-sub": `
+			submodule md-sub {
+				belongs-to md-use { prefix "u"; }
+				import ietf-yang-metadata { prefix "md"; }
+				md:annotation weight { type uint8 { range "0..10"; } }
+			}
+		`,
+		"md-use": `
+			module md-use {
+				prefix "u";
+				namespace "urn:u";
+				import ietf-yang-metadata { prefix "md"; }
+				include md-sub;
+				typedef level { type enumeration { enum low; enum high; } }
+				md:annotation last-modified {
+					type string;
+					units "seconds";
+					description "Time of the last change.";
+					reference "RFC 7952";
+					status deprecated;
+					if-feature timestamps;
+				}
+				md:annotation level { type level; }
+				feature timestamps;
+			}
+		`,
+	}
+	ms := NewModules()
+	for name, text := range mods {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	if got := len(ms.Modules["md-use"].Annotations); got != 2 {
+		t.Errorf("md-use: got %d annotations, want 2", got)
+	}
+	if got := len(ms.SubModules["md-sub"].Annotations); got != 1 {
+		t.Errorf("md-sub: got %d annotations, want 1", got)
+	}
+
+	a := ms.FindAnnotation("md-use", "last-modified")
+	if a == nil {
+		t.Fatalf("last-modified not found")
+	}
+	if a.Type.Kind != Ystring || a.Units != "seconds" || a.Description != "Time of the last change." || a.Reference != "RFC 7952" || a.Status != "deprecated" || len(a.IfFeature) != 1 {
+		t.Errorf("last-modified: got %+v", a)
+	}
+	if got, want := a.JSONName(), "md-use:last-modified"; got != want {
+		t.Errorf("JSONName: got %q, want %q", got, want)
+	}
+	if got, want := a.Namespace(), "urn:u"; got != want {
+		t.Errorf("Namespace: got %q, want %q", got, want)
+	}
+
+	if a := ms.FindAnnotation("md-use", "level"); a == nil || a.Type.Kind != Yenum || !a.Type.Enum.IsDefined("high") {
+		t.Errorf("level: got %+v, want an enumeration", a)
+	}
+
+	a = ms.FindAnnotationByNamespace("urn:u", "weight")
+	if a == nil {
+		t.Fatalf("weight not found")
+	}
+	if a.Type.Kind != Yuint8 || a.Type.Range.String() != "0..10" {
+		t.Errorf("weight: got type %v %v, want uint8 0..10", a.Type.Kind, a.Type.Range)
+	}
+	if got, want := a.JSONName(), "md-use:weight"; got != want {
+		t.Errorf("JSONName: got %q, want %q", got, want)
+	}
+
+	if a := ms.FindAnnotation("ietf-yang-metadata", "weight"); a != nil {
+		t.Errorf("got annotation %s from the wrong module", a.JSONName())
+	}
+	if a := ms.FindAnnotationByNamespace("urn:none", "weight"); a != nil {
+		t.Errorf("got annotation %s from an unknown namespace", a.JSONName())
+	}
+}
+
+func TestAnnotationErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		in      string
+		wantErr string
+	}{{
+		name:    "no type",
+		in:      `md:annotation a;`,
+		wantErr: "annotation a has no type",
+	}, {
+		name:    "two types",
+		in:      `md:annotation a { type string; type int8; }`,
+		wantErr: "annotation a has multiple types",
+	}, {
+		name:    "unknown type",
+		in:      `md:annotation a { type missing; }`,
+		wantErr: "unknown type: b:missing",
+	}, {
+		name:    "unknown field",
+		in:      `md:annotation a { type string; default "x"; }`,
+		wantErr: "unknown annotation field: default",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := NewModules()
+			if err := ms.Parse(metadataModule, "ietf-yang-metadata.yang"); err != nil {
+				t.Fatal(err)
+			}
+			mod := `
+				module md-bad {
+					prefix "b";
+					namespace "urn:b";
+					import ietf-yang-metadata { prefix "md"; }
+					` + tt.in + `
+				}
+			`
+			if err := ms.Parse(mod, "md-bad.yang"); err != nil {
+				t.Fatal(err)
+			}
+			var err error
+			if errs := ms.Process(); len(errs) > 0 {
+				err = errs[0]
+			}
+			if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
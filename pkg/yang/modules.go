@@ -18,7 +18,15 @@ package yang
 // include and import statements, which must be done prior to turning the
 // module into an Entry tree.
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"sync"
+	"time"
+)
 
 // Modules contains information about all the top level modules and
 // submodules that are read into it via its Read method.
@@ -28,46 +36,313 @@ type Modules struct {
 	includes   map[*Module]bool   // Modules we have already done include on
 	byPrefix   map[string]*Module // Cache of prefix lookup
 	byNS       map[string]*Module // Cache of namespace lookup
+
+	importPrefixes map[*Module]map[string]string // Cache of import prefixes
+
+	typedefCache  map[scopedName]*Typedef  // Cache of module level typedef lookup
+	groupingCache map[scopedName]*Grouping // Cache of module level grouping lookup
+	typeCache     map[typeKey]*YangType    // Shared resolved types
+	cacheStats    CacheStats               // Statistics of the above caches
+
+	sources     map[*Module]*sourceStats // Parse statistics of each module
+	processTime time.Duration            // Duration of the last Process
+
+	hooks      []ProcessHook // Hooks called by Process
+	transforms []Transform   // Transforms applied by Process
+	useBuiltin bool          // Read the embedded standard modules
+	errors     []error       // Errors from the last Process
+	warnings   []error       // Warnings from the last Process
+	progress   ProgressFunc  // Called with the progress of Read and Process
+	parsed     int           // Number of modules and submodules parsed
+
+	references map[Node][]Node // Reverse reference index, see ReferencesTo
+
+	identities    map[string]resolvedIdentity // Resolved identities by prefixed name
+	entries       *entryState                 // State of ToEntry for the modules
+	typedefsInUse map[*Typedef]bool           // Typedefs being resolved, see Typedef.resolve
+
+	resolvers []ModuleResolver // Consulted when a module is not on the path
+
+	opts    Options         // Options of ms, see NewModulesWithOptions
+	pathMu  sync.Mutex      // Protects path and pathMap, see AddPath
+	path    []string        // Directories searched for modules
+	pathMap map[string]bool // Directories in path
 }
 
-// NewModules returns a newly created and initialized Modules.
+// NewModules returns a newly created and initialized Modules.  Its options
+// are a copy of ParseOptions and its search path is a copy of Path.
 func NewModules() *Modules {
+	ms := NewModulesWithOptions(ParseOptions)
+	pathMu.Lock()
+	dirs := Path
+	pathMu.Unlock()
+	ms.AddPath(dirs...)
+	return ms
+}
+
+// NewModulesWithOptions returns a newly created and initialized Modules
+// that uses opts, regardless of ParseOptions.  Its search path is empty,
+// see AddPath.  The maps of opts, such as Severities, are not copied, so
+// they must not be changed while ms is in use.
+func NewModulesWithOptions(opts Options) *Modules {
 	return &Modules{
 		Modules:    map[string]*Module{},
 		SubModules: map[string]*Module{},
 		includes:   map[*Module]bool{},
 		byPrefix:   map[string]*Module{},
 		byNS:       map[string]*Module{},
+
+		importPrefixes: map[*Module]map[string]string{},
+
+		typedefCache:  map[scopedName]*Typedef{},
+		groupingCache: map[scopedName]*Grouping{},
+		typeCache:     map[typeKey]*YangType{},
+		sources:       map[*Module]*sourceStats{},
+
+		identities:    map[string]resolvedIdentity{},
+		entries:       newEntryState(),
+		typedefsInUse: map[*Typedef]bool{},
+
+		opts:    opts,
+		pathMap: map[string]bool{},
 	}
 }
 
+// Options returns the options of ms.
+func (ms *Modules) Options() Options {
+	return ms.opts
+}
+
 // Read reads the named yang module into ms.  The name can be the name of an
 // actual .yang file or a module/submodule name (the base name of a .yang file,
 // e.g., foo.yang is named foo).  An error is returned if the file is not
 // found or there was an error parsing the file.
+//
+// The ietf-datastores, ietf-origin and ietf-yang-metadata modules are
+// embedded in this package.  If one of them is not found, on the search
+// path of ms (see AddPath) or by a
+// resolver (see AddResolver), the embedded copy is read instead.  See
+// UseBuiltinModules for the other embedded modules.
 func (ms *Modules) Read(name string) error {
-	name, data, err := findFile(name)
+	return ms.ReadContext(context.Background(), name)
+}
+
+// ReadContext is like Read but returns ctx.Err() if ctx is done before the
+// module has been read.
+func (ms *Modules) ReadContext(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	fname, data, err := ms.find(ctx, name)
 	if err != nil {
+		ms.localize(err)
 		return err
 	}
-	return ms.Parse(data, name)
+	return ms.ParseContext(ctx, data, fname)
+}
+
+// find returns the name and contents of the source associated with name,
+// as described by Read.
+func (ms *Modules) find(ctx context.Context, name string) (string, string, error) {
+	fname, data, err := ms.findFile(name)
+	if err != nil {
+		fname, data, err = ms.resolve(ctx, name, err)
+	}
+	if err != nil {
+		ename, edata, ok := findEmbedded(name, ms.useBuiltin)
+		if !ok {
+			return "", "", err
+		}
+		fname, data = ename, edata
+	}
+	return fname, data, nil
+}
+
+// ReadAll reads the modules named by names into ms, as Read does.  Up to
+// jobs files are found, read, and parsed at once, and the modules are then
+// added to ms in the order of names.  The directories of the named files are
+// added to the search path of ms, in the order of names, before any are
+// read, so the files found by searching do not depend on jobs.  The errors
+// for each name that could not be read are returned.  Once the MaxErrors
+// option of ms names could not be read, the remaining names are neither
+// read nor added.
+func (ms *Modules) ReadAll(names []string, jobs int) Errors {
+	return ms.ReadAllContext(context.Background(), names, jobs)
+}
+
+// ReadAllContext is like ReadAll but stops reading modules once ctx is
+// done, in which case ctx.Err() is returned for each module not read.
+func (ms *Modules) ReadAllContext(ctx context.Context, names []string, jobs int) Errors {
+	if jobs < 1 {
+		jobs = 1
+	}
+	if jobs > len(names) {
+		jobs = len(names)
+	}
+	for _, name := range names {
+		if dir := foundDir(name); dir != "" {
+			ms.AddPath(dir)
+		}
+	}
+
+	type result struct {
+		src *parsedSource
+		err error
+	}
+	results := make([]result, len(names))
+	next := make(chan int)
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed []error // errors so far, to stop at the MaxErrors option
+	)
+	for j := 0; j < jobs; j++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				r := &results[i]
+				if r.err = ctx.Err(); r.err == nil {
+					var fname, data string
+					if fname, data, r.err = ms.find(ctx, names[i]); r.err == nil {
+						r.src, r.err = parseSource(ctx, data, fname, &ms.opts)
+					}
+				}
+				if r.err != nil {
+					mu.Lock()
+					failed = append(failed, r.err)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	// Names are handed out in order, so when a name is not read because
+	// of MaxErrors, the errors were for earlier names and the loop below
+	// stops before reaching it.
+	for i := range names {
+		mu.Lock()
+		stop := ms.tooManyErrors(failed)
+		mu.Unlock()
+		if stop {
+			break
+		}
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	var errs Errors
+	for _, r := range results {
+		if r.src != nil {
+			ms.register(r.src)
+		}
+		if r.err != nil {
+			errs = append(errs, r.err)
+			if ms.tooManyErrors(errs) {
+				break
+			}
+		}
+	}
+	ms.localize(errs...)
+	return errs
 }
 
 // Parse parses data as YANG source and adds it to ms.  The name should reflect
-// the source of data.
+// the source of data.  If IsYIN reports that data is written in YIN, it is
+// parsed as YIN.
 func (ms *Modules) Parse(data, name string) error {
-	ss, err := Parse(data, name)
+	return ms.ParseContext(context.Background(), data, name)
+}
+
+// ParseReader is like Parse but reads the source from r.
+func (ms *Modules) ParseReader(r io.Reader, name string) error {
+	return ms.ParseReaderContext(context.Background(), r, name)
+}
+
+// ParseReaderContext is like ParseReader but returns ctx.Err() if ctx is
+// done before the source has been parsed.  Reading r is not interrupted
+// when ctx is done.
+func (ms *Modules) ParseReaderContext(ctx context.Context, r io.Reader, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if max := ms.opts.MaxFileSize; max > 0 {
+		r = io.LimitReader(r, int64(max)+1)
+	}
+	data, err := ioutil.ReadAll(r)
 	if err != nil {
 		return err
 	}
+	return ms.ParseContext(ctx, string(data), name)
+}
+
+// ParseContext is like Parse but returns ctx.Err() if ctx is done before
+// data has been parsed.
+func (ms *Modules) ParseContext(ctx context.Context, data, name string) error {
+	src, err := parseSource(ctx, data, name, &ms.opts)
+	if src != nil {
+		ms.register(src)
+	}
+	ms.localize(err)
+	return err
+}
+
+// A parsedSource is the result of parsing a source file.
+type parsedSource struct {
+	nodes []Node // the modules and submodules in the source
+	stats *sourceStats
+}
+
+// parseSource parses data, the source read from name, with opts and builds
+// its modules and submodules.  If there is an error, the modules and
+// submodules built before the error, if any, are also returned.  Unlike
+// adding them to a Modules, parseSource may be called concurrently.
+func parseSource(ctx context.Context, data, name string, opts *Options) (src *parsedSource, err error) {
+	defer recoverError(opts, &err)
+	if max := opts.MaxFileSize; max > 0 && len(data) > max {
+		return nil, &fileSizeError{name: name, max: max}
+	}
+	start := time.Now()
+	parse := parse
+	if IsYIN(data, name) {
+		parse = parseYIN
+	}
+	ss, err := parse(ctx, data, name, opts)
+	if err != nil {
+		return nil, err
+	}
+	src = &parsedSource{stats: &sourceStats{size: len(data)}}
+	defer func() { src.stats.parseTime = time.Since(start) }()
 	for _, s := range ss {
+		var skipped []error
+		if opts.Lenient {
+			skipped = skipUnknown(s)
+		}
 		n, err := BuildAST(s)
 		if err != nil {
-			return err
+			return src, err
+		}
+		if m, ok := n.(*Module); ok {
+			m.skipped = skipped
+			if err := m.buildStructures(); err != nil {
+				return src, err
+			}
 		}
+		src.nodes = append(src.nodes, n)
+	}
+	return src, nil
+}
+
+// register adds the modules and submodules of src to ms.
+func (ms *Modules) register(src *parsedSource) {
+	for _, n := range src.nodes {
 		ms.add(n)
+		if m, ok := n.(*Module); ok {
+			ms.sources[m] = src.stats
+		}
+		ms.parsed++
+		ms.reportProgress(PhaseParse, n.NName(), ms.parsed, 0)
 	}
-	return nil
 }
 
 // GetModule returns the Entry of the module named by name.  GetModule will
@@ -78,17 +353,25 @@ func (ms *Modules) Parse(data, name string) error {
 // then looking up the module name.  It is safe to call Read and Process prior
 // to calling GetModule.
 func (ms *Modules) GetModule(name string) (*Entry, []error) {
+	return ms.GetModuleContext(context.Background(), name)
+}
+
+// GetModuleContext is like GetModule but stops reading and processing
+// modules once ctx is done.
+func (ms *Modules) GetModuleContext(ctx context.Context, name string) (*Entry, []error) {
 	if ms.Modules[name] == nil {
-		if err := ms.Read(name); err != nil {
+		if err := ms.ReadContext(ctx, name); err != nil {
 			return nil, []error{err}
 		}
 		if ms.Modules[name] == nil {
-			return nil, []error{fmt.Errorf("module not found: %s", name)}
+			err := errorf(nil, ErrUnknownModule, "module not found: %s", name)
+			ms.localize(err)
+			return nil, []error{err}
 		}
 	}
 	// Make sure that the modules have all been processed and have no
 	// errors.
-	if errs := ms.Process(); len(errs) != 0 {
+	if errs := ms.ProcessContext(ctx); len(errs) != 0 {
 		return nil, errs
 	}
 	return ToEntry(ms.Modules[name]), nil
@@ -130,7 +413,7 @@ func (ms *Modules) add(n Node) error {
 	case "submodule":
 		m = ms.SubModules
 	default:
-		return fmt.Errorf("not a module or submodule: %s is of type %s", name, kind)
+		return errorf(nil, ErrNotModule, "not a module or submodule: %s is of type %s", name, kind)
 	}
 
 	mod := n.(*Module)
@@ -138,7 +421,7 @@ func (ms *Modules) add(n Node) error {
 	mod.modules = ms
 
 	if o := m[fullName]; o != nil {
-		return fmt.Errorf("duplicate %s %s at %s and %s", kind, fullName, Source(o), Source(n))
+		return errorf(nil, ErrDuplicateModule, "duplicate %s %s at %s and %s", kind, fullName, Source(o), Source(n))
 	}
 	m[fullName] = mod
 	if fullName == name {
@@ -154,6 +437,77 @@ func (ms *Modules) add(n Node) error {
 	return nil
 }
 
+// Merge adds the modules and submodules of other to ms.  A module of other
+// with the same name and revision as a module already in ms is the same
+// module read twice; the copy in ms is kept and the modules of other that
+// import it use it instead.  Merge returns an error for each module of other
+// that conflicts with ms, i.e., a module with a different revision of a
+// module in ms, or with the namespace of a different module in ms.  If any
+// errors are returned, ms is not changed.
+//
+// The merged modules belong to ms and other must not be used afterwards.
+// Process must be called on ms after Merge to build the Entry trees of the
+// combined modules.
+func (ms *Modules) Merge(other *Modules) Errors {
+	if other == nil || other == ms {
+		return nil
+	}
+	var errs Errors
+	var add []*Module
+	for _, set := range []struct {
+		mine, theirs map[string]*Module
+	}{
+		{ms.Modules, other.Modules},
+		{ms.SubModules, other.SubModules},
+	} {
+		for _, om := range sortModules(set.theirs) {
+			kind, fullName := om.Kind(), om.FullName()
+			if m := set.mine[fullName]; m != nil {
+				if m != om && om.Namespace != nil && m.Namespace != nil && om.Namespace.Name != m.Namespace.Name {
+					errs = append(errs, errorf(om, ErrDuplicateModule, "%s %s has namespace %s, not %s as at %s", kind, fullName, om.Namespace.Name, m.Namespace.Name, Source(m)))
+				}
+				continue
+			}
+			if m := set.mine[om.Name]; m != nil {
+				errs = append(errs, errorf(om, ErrDuplicateModule, "conflicting revisions of %s %s: %s and %s at %s", kind, om.Name, fullName, m.FullName(), Source(m)))
+				continue
+			}
+			if om.Namespace != nil {
+				for _, m := range sortModules(set.mine) {
+					if m.Namespace != nil && m.Namespace.Name == om.Namespace.Name {
+						errs = append(errs, errorf(om, ErrDuplicateModule, "%s %s has the same namespace %s as %s at %s", kind, fullName, om.Namespace.Name, m.FullName(), Source(m)))
+						break
+					}
+				}
+			}
+			add = append(add, om)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	for _, om := range add {
+		if err := ms.add(om); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if s := other.sources[om]; s != nil {
+			ms.sources[om] = s
+		}
+	}
+
+	// Lookups made before the merge may now resolve differently.
+	ms.byPrefix = map[string]*Module{}
+	ms.byNS = map[string]*Module{}
+	ms.importPrefixes = map[*Module]map[string]string{}
+	ms.typedefCache = map[scopedName]*Typedef{}
+	ms.groupingCache = map[scopedName]*Grouping{}
+	ms.includes = map[*Module]bool{}
+	ms.references = nil
+	return errs
+}
+
 // FindModule returns the Module/Submodule specified by n, which must be a
 // *Include or *Import.  If n is a *Include then a submodule is returned.  If n
 // is a *Import then a module is returned.
@@ -207,7 +561,7 @@ func (ms *Modules) FindModuleByNamespace(ns string) (*Module, error) {
 		return m, nil
 	}
 	var found *Module
-	for _, m := range ms.Modules {
+	for _, m := range sortModules(ms.Modules) {
 		if m.Namespace.Name == ns {
 			switch {
 			case m == found:
@@ -236,7 +590,7 @@ func (ms *Modules) FindModuleByPrefix(prefix string) (*Module, error) {
 		return m, nil
 	}
 	var found *Module
-	for _, m := range ms.Modules {
+	for _, m := range sortModules(ms.Modules) {
 		if m.Prefix.Name == prefix {
 			switch {
 			case m == found:
@@ -262,28 +616,37 @@ func (ms *Modules) FindModuleByPrefix(prefix string) (*Module, error) {
 //
 // Process must be called once all the source modules have been read in and
 // prior to converting Node tree into an Entry tree.
-func (ms *Modules) process() []error {
+func (ms *Modules) process(ctx context.Context) []error {
 	var mods []*Module
 	var errs []error
 
 	// Collect the list of modules we know about now so when we range
 	// below we don't pick up new modules.  We assume the user tells
 	// us explicitly which modules they are interested in.
-	for _, m := range ms.Modules {
-		mods = append(mods, m)
-	}
+	mods = sortModules(ms.Modules)
 	for _, m := range mods {
+		if err := ctx.Err(); err != nil {
+			return []error{err}
+		}
 		if err := ms.include(m); err != nil {
 			errs = append(errs, err)
 		}
 	}
+	if ms.opts.StrictYangVersion {
+		for _, m := range sortModules(ms.Modules, ms.SubModules) {
+			errs = append(errs, checkYangVersion(m)...)
+		}
+	}
+	if ms.tooManyErrors(errs) {
+		return errs
+	}
 
 	// Resolve identities before resolving typedefs, otherwise when we resolve a
 	// typedef that has an identityref within it, then the identity dictionary
 	// has not yet been built.
 	errs = append(errs, ms.resolveIdentities()...)
 	// Append any errors found trying to resolve typedefs
-	errs = append(errs, resolveTypedefs()...)
+	errs = append(errs, ms.resolveTypedefs()...)
 
 	return errs
 }
@@ -291,10 +654,11 @@ func (ms *Modules) process() []error {
 // Process processes all the modules and submodules that have been read into
 // ms.  While processing, if an include or import is found for which there
 // is no matching module, Process attempts to locate the source file (using
-// Path) and automatically load them.  If a file cannot be found then an
-// error is returned.  When looking for a source file, Process searches for a
-// file using the module's or submodule's name with ".yang" appended.  After
-// searching the current directory, the directories in Path are searched.
+// the search path of ms) and automatically load them.  If a file cannot be
+// found then an error is returned.  When looking for a source file, Process
+// searches for a file using the module's or submodule's name with ".yang"
+// appended.  After searching the current directory, the directories of the
+// search path are searched, see AddPath.
 //
 // Process builds Entry trees for each modules and submodules in ms.  These
 // trees are accessed using the ToEntry function.  Process does augmentation
@@ -316,23 +680,113 @@ func (ms *Modules) process() []error {
 // Process may return multiple errors if multiple errors were encountered
 // while processing.  Even though multiple errors may be returned, this does
 // not mean these are all the errors.  Process will terminate processing early
-// based on the type and location of the error.
-func (ms *Modules) Process() []error {
-	// Reset globals that may remain stale if multiple Process() calls are
+// based on the type and location of the error.  The errors are sorted by
+// file, line and column and duplicates are removed.  Modules are processed
+// in a fixed order, so the same modules always produce the same errors.
+func (ms *Modules) Process() Errors {
+	return ms.ProcessContext(context.Background())
+}
+
+// ProcessContext is like Process but stops processing once ctx is done.  If
+// processing was stopped, the only error returned is ctx.Err() and the
+// Entry trees of ms are incomplete.
+func (ms *Modules) ProcessContext(ctx context.Context) (errs Errors) {
+	if err := ctx.Err(); err != nil {
+		return []error{err}
+	}
+	defer recoverErrors(&ms.opts, &errs)
+	start := time.Now()
+	defer func() { ms.processTime = time.Since(start) }()
+
+	// Reset the state that may remain stale if multiple Process() calls are
 	// made by the same caller.
-	mergedSubmodule = map[string]bool{}
-	entryCache = map[Node]*Entry{}
+	ms.entries = newEntryState()
+	ms.warnings = nil
+	ms.references = nil
+
+	errs, ms.warnings = ms.adjustSeverities(ms.processModules(ctx), ms.collectWarnings())
+	if ms.opts.WarningsAsErrors && len(ms.warnings) > 0 {
+		for _, w := range ms.warnings {
+			errs = append(errs, promote(w))
+		}
+		ms.warnings = nil
+		errs = errorSort(errs)
+	}
+	ms.errors = ms.limitErrors(errs)
+	ms.localize(ms.errors...)
+	ms.localize(ms.warnings...)
+	return ms.errors
+}
+
+// tooManyErrors reports whether errs has reached the MaxErrors option of ms.
+func (ms *Modules) tooManyErrors(errs []error) bool {
+	max := ms.opts.MaxErrors
+	return max > 0 && len(errs) >= max
+}
+
+// limitErrors returns errs truncated to the MaxErrors option of ms.  If
+// errs is truncated an ErrTooManyErrors error saying how many errors were
+// dropped is added to the end.  As Process stops looking for errors once it
+// has found enough of them, there may be more errors than that.
+func (ms *Modules) limitErrors(errs []error) []error {
+	max := ms.opts.MaxErrors
+	if max <= 0 || len(errs) <= max {
+		return errs
+	}
+	dropped := len(errs) - max
+	e := errorf(nil, ErrTooManyErrors, "at least %d more errors were not reported, only the first %d are reported", dropped, max)
+	e.dropped = dropped
+	return append(errs[:max:max], e)
+}
 
-	errs := ms.process()
+// promote returns the warning w as an error.
+func promote(w error) error {
+	if e, ok := w.(*Error); ok {
+		ne := *e
+		ne.Severity = SeverityError
+		return &ne
+	}
+	return w
+}
+
+// processModules does the work of ProcessContext once the results of any
+// previous call have been reset.
+func (ms *Modules) processModules(ctx context.Context) []error {
+	errs := ms.process(ctx)
 	if len(errs) > 0 {
 		return errorSort(errs)
 	}
+	if errs := ms.runHooks(AfterTypedefs); len(errs) > 0 {
+		return errorSort(errs)
+	}
 
-	for _, m := range ms.Modules {
-		errs = append(errs, ToEntry(m).GetErrors()...)
+	if errs := ms.handleExtensions(); len(errs) > 0 {
+		return errorSort(errs)
+	}
+	for _, m := range ms.sortedModules() {
+		m.setOpenConfigExtensions()
+		m.setSMIv2Aliases()
+		errs = append(errs, m.setAnnotations()...)
+	}
+	if len(errs) > 0 {
+		return errorSort(errs)
+	}
+	if errs := ms.runHooks(BeforeEntries); len(errs) > 0 {
+		return errorSort(errs)
 	}
-	for _, m := range ms.SubModules {
+
+	// Modules are always handled in the same order so the same errors are
+	// reported each time.
+	sorted := ms.sortedModules()
+	for i, m := range sorted {
+		if err := ctx.Err(); err != nil {
+			return []error{err}
+		}
 		errs = append(errs, ToEntry(m).GetErrors()...)
+		ms.reportProgress(PhaseEntries, m.Name, i+1, len(sorted))
+		if ms.tooManyErrors(errs) {
+			break
+		}
 	}
 
 	if len(errs) > 0 {
@@ -340,39 +794,63 @@ func (ms *Modules) Process() []error {
 	}
 
 	// Now handle all the augments.  We don't have a good way to know
-	// what order to process them in, so repeat until no progress is made
-
-	mods := make([]*Module, 0, len(ms.Modules)+len(ms.SubModules))
-	for _, m := range ms.Modules {
-		mods = append(mods, m)
-	}
-	for _, m := range ms.SubModules {
-		mods = append(mods, m)
+	// what order to process them in, so repeat until no progress is made.
+	// An augment whose target is not found is indexed by the entry that
+	// lacks the next node of the target's path.  It is not looked for
+	// again until another augment has added that node, and then only the
+	// rest of the path is followed, so each node of the path is found
+	// once rather than once in each pass.
+
+	type waiter struct {
+		parent *Entry   // the entry lacking the next node
+		rest   []string // the rest of the path, from the next node on
+	}
+	waiting := map[*Entry]waiter{}
+	find := func(a *Entry) *Entry {
+		var ae, parent *Entry
+		var rest []string
+		if w, ok := waiting[a]; ok {
+			if _, name := getPrefix(w.rest[0]); w.parent.Dir[name] == nil {
+				return nil
+			}
+			ae, parent, rest = w.parent.walk(w.rest)
+		} else {
+			ae, parent, rest = a.find(a.Name)
+		}
+		if parent != nil {
+			waiting[a] = waiter{parent, rest}
+		} else {
+			delete(waiting, a)
+		}
+		return ae
 	}
+
+	mods := append([]*Module{}, sorted...)
 	for len(mods) > 0 {
+		if err := ctx.Err(); err != nil {
+			return []error{err}
+		}
 		var processed int
-		for i := 0; i < len(mods); {
-			m := mods[i]
-			p, s := ToEntry(m).Augment(false)
+		var remaining []*Module
+		for i, m := range mods {
+			p, s := ToEntry(m).augment(false, find)
 			processed += p
-			if s == 0 {
-				mods[i] = mods[len(mods)-1]
-				mods = mods[:len(mods)-1]
-				continue
+			if s != 0 {
+				remaining = append(remaining, m)
 			}
-			i++
+			ms.reportProgress(PhaseAugments, m.Name, i+1, len(mods))
 		}
+		mods = remaining
 		if processed == 0 {
 			break
 		}
 	}
 
+	errs = append(errs, ms.buildStructureEntries()...)
+
 	// Now fix up all the choice statements to add in the missing case
 	// statements.
-	for _, m := range ms.Modules {
-		ToEntry(m).FixChoice()
-	}
-	for _, m := range ms.SubModules {
+	for _, m := range sorted {
 		ToEntry(m).FixChoice()
 	}
 
@@ -382,26 +860,63 @@ func (ms *Modules) Process() []error {
 		ToEntry(m).Augment(true)
 		errs = append(errs, ToEntry(m).GetErrors()...)
 	}
+	errs = append(errs, ms.runHooks(AfterAugments)...)
 
 	// The deviation statement is only valid under a module or submodule,
 	// which allows us to avoid having to process it within ToEntry, and
 	// rather we can just walk all modules and submodules *after* entries
 	// are resolved. This means we do not need to concern ourselves that
 	// an entry does not exist.
+	if err := ctx.Err(); err != nil {
+		return []error{err}
+	}
 	dvP := map[string]bool{} // cache the modules we've handled since we have both modname and modname@revision-date
-	for _, devmods := range []map[string]*Module{ms.Modules, ms.SubModules} {
-		for _, m := range devmods {
-			e := ToEntry(m)
-			if !dvP[e.Name] {
-				errs = append(errs, e.ApplyDeviate()...)
-				dvP[e.Name] = true
-			}
+	for i, m := range sorted {
+		e := ToEntry(m)
+		if !dvP[e.Name] {
+			errs = append(errs, e.ApplyDeviate()...)
+			dvP[e.Name] = true
 		}
+		ms.reportProgress(PhaseDeviations, m.Name, i+1, len(sorted))
+	}
+	ms.warnings = append(ms.warnings, ms.checkStatus()...)
+	ms.warnings = append(ms.warnings, ms.checkXPath()...)
+	ms.warnings = append(ms.warnings, ms.checkModules()...)
+	ms.warnings = append(ms.warnings, ms.checkSources()...)
+	if ms.opts.StrictConformance {
+		errs = append(errs, ms.checkConformance()...)
+	}
+	if ms.opts.PruneObsolete {
+		ms.pruneObsolete()
+	}
+	if len(errs) == 0 {
+		errs = ms.applyTransforms()
 	}
 
 	return errorSort(errs)
 }
 
+// Warnings returns the sorted warnings reported by the last call to
+// Process.  Unlike errors, warnings do not prevent the modules from being
+// used.  Warnings about an entry are also found with Entry.GetWarnings.
+// If the WarningsAsErrors option is set, Process returns the warnings as
+// errors instead and Warnings returns nil.
+func (ms *Modules) Warnings() []error {
+	return ms.warnings
+}
+
+// collectWarnings returns the warnings about the modules of ms and their
+// entries.  Duplicate warnings are removed.
+func (ms *Modules) collectWarnings() []error {
+	ws := ms.warnings
+	for _, m := range ms.sortedModules() {
+		if e := ms.entries.cache[m]; e != nil {
+			ws = append(ws, e.GetWarnings()...)
+		}
+	}
+	return errorSort(ws)
+}
+
 // include resolves all the include and import statements for m.  It returns
 // an error if m, or recursively, any of the modules it includes or imports,
 // reference a module that cannot be found.
@@ -415,12 +930,13 @@ func (ms *Modules) include(m *Module) error {
 	for _, i := range m.Include {
 		im := ms.FindModule(i)
 		if im == nil {
-			return fmt.Errorf("no such submodule: %s", i.Name)
+			return errorf(i, ErrUnknownModule, "no such submodule: %s", i.Name)
 		}
 		// Process the include statements in our included module.
 		if err := ms.include(im); err != nil {
 			return err
 		}
+		ms.checkRevision(i, i.RevisionDate, im)
 		i.Module = im
 	}
 
@@ -429,14 +945,48 @@ func (ms *Modules) include(m *Module) error {
 	for _, i := range m.Import {
 		im := ms.FindModule(i)
 		if im == nil {
-			return fmt.Errorf("no such module: %s", i.Name)
+			return errorf(i, ErrUnknownModule, "no such module: %s", i.Name)
 		}
 		// Process the include statements in our included module.
 		if err := ms.include(im); err != nil {
 			return err
 		}
-
+		ms.checkRevision(i, i.RevisionDate, im)
 		i.Module = im
 	}
 	return nil
 }
+
+// checkRevision adds a warning to ms if the revision of the module im
+// found for the import or include statement n is not the revision rev
+// requested by n.  FindModule falls back to any revision of the module.
+func (ms *Modules) checkRevision(n Node, rev *Value, im *Module) {
+	if rev == nil || rev.Name == im.Current() {
+		return
+	}
+	ms.warnings = append(ms.warnings, warnf(n, WarnRevisionNotFound, "revision %s of %s not found, using revision %s", rev.Name, im.Name, im.Current()))
+}
+
+// sortedModules returns the modules and submodules of ms, each once, sorted
+// by their full name.  Modules are found in ms under both their name and
+// their name@revision.
+func (ms *Modules) sortedModules() []*Module {
+	return sortModules(ms.Modules, ms.SubModules)
+}
+
+// sortModules returns the modules found in mms, each once, sorted by their
+// full name.
+func sortModules(mms ...map[string]*Module) []*Module {
+	seen := map[*Module]bool{}
+	var mods []*Module
+	for _, mm := range mms {
+		for _, m := range mm {
+			if !seen[m] {
+				seen[m] = true
+				mods = append(mods, m)
+			}
+		}
+	}
+	sort.Slice(mods, func(i, j int) bool { return mods[i].FullName() < mods[j].FullName() })
+	return mods
+}
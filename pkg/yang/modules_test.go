@@ -15,8 +15,17 @@
 package yang
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 var testdataFindModulesText = map[string]string{
@@ -182,3 +191,396 @@ func TestModulesTotalProcess(t *testing.T) {
 		})
 	}
 }
+
+// cancelAfter is a context that is cancelled after its Err method has been
+// called n times.
+type cancelAfter struct {
+	context.Context
+	n int
+}
+
+func (c *cancelAfter) Err() error {
+	if c.n == 0 {
+		return context.Canceled
+	}
+	c.n--
+	return nil
+}
+
+func TestModulesContext(t *testing.T) {
+	const mod = `module ctx-test { prefix "c"; namespace "urn:c"; leaf a { type string; } }`
+
+	// A large module to check that parsing is stopped part way through.
+	var big strings.Builder
+	big.WriteString(`module ctx-big { prefix "b"; namespace "urn:b";`)
+	for i := 0; i < 10*ctxCheckInterval; i++ {
+		big.WriteString(` leaf l { type string; }`)
+	}
+	big.WriteString(`}`)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := ParseContext(cancelled, big.String(), "ctx-big.yang"); err != context.Canceled {
+		t.Errorf("ParseContext: got error %v, want %v", err, context.Canceled)
+	}
+	later := &cancelAfter{Context: context.Background(), n: 1}
+	if _, err := ParseContext(later, big.String(), "ctx-big.yang"); err != context.Canceled {
+		t.Errorf("ParseContext: got error %v when cancelled while parsing, want %v", err, context.Canceled)
+	}
+	ms := NewModules()
+	if err := ms.ParseContext(cancelled, mod, "ctx-test.yang"); err != context.Canceled {
+		t.Errorf("Modules.ParseContext: got error %v, want %v", err, context.Canceled)
+	}
+	if err := ms.ReadContext(cancelled, "ctx-test"); err != context.Canceled {
+		t.Errorf("Modules.ReadContext: got error %v, want %v", err, context.Canceled)
+	}
+	if err := ms.ParseReaderContext(cancelled, strings.NewReader(mod), "ctx-test.yang"); err != context.Canceled {
+		t.Errorf("Modules.ParseReaderContext: got error %v, want %v", err, context.Canceled)
+	}
+	if ms.Modules["ctx-test"] != nil {
+		t.Errorf("module added after the context was cancelled")
+	}
+
+	if err := ms.ParseContext(context.Background(), mod, "ctx-test.yang"); err != nil {
+		t.Fatalf("cannot parse: %v", err)
+	}
+	if errs := ms.ProcessContext(cancelled); len(errs) != 1 || errs[0] != context.Canceled {
+		t.Errorf("ProcessContext: got errors %v, want %v", errs, context.Canceled)
+	}
+	if _, errs := ms.GetModuleContext(cancelled, "ctx-test"); len(errs) != 1 || errs[0] != context.Canceled {
+		t.Errorf("GetModuleContext: got errors %v, want %v", errs, context.Canceled)
+	}
+	e, errs := ms.GetModuleContext(context.Background(), "ctx-test")
+	if len(errs) != 0 {
+		t.Fatalf("GetModuleContext: unexpected errors: %v", errs)
+	}
+	var buf bytes.Buffer
+	if err := e.WriteJSONContext(cancelled, &buf); err != context.Canceled {
+		t.Errorf("WriteJSONContext: got error %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestModulesParseReader(t *testing.T) {
+	ms := NewModules()
+	if err := ms.ParseReader(strings.NewReader(`module reader { prefix "r"; namespace "urn:r"; }`), "reader.yang"); err != nil {
+		t.Fatal(err)
+	}
+	if ms.Modules["reader"] == nil {
+		t.Error("module reader was not added")
+	}
+
+	ms.opts.MaxFileSize = 10
+	if err := ms.ParseReader(strings.NewReader(`module big { prefix "b"; namespace "urn:b"; }`), "big.yang"); err == nil || !strings.Contains(err.Error(), "big.yang: file is larger than the maximum of 10 bytes") {
+		t.Errorf("ParseReader of a large module: got error %v", err)
+	}
+}
+
+func TestModulesReadAll(t *testing.T) {
+	dir, err := ioutil.TempDir("", "readall")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var names, want []string
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("readall-%02d", i)
+		src := fmt.Sprintf(`module %s { prefix "p%d"; namespace "urn:%s"; leaf l { type string; } }`, name, i, name)
+		if err := ioutil.WriteFile(filepath.Join(dir, name+".yang"), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, filepath.Join(dir, name+".yang"))
+		want = append(want, name)
+	}
+	names = append(names, filepath.Join(dir, "missing.yang"))
+	if err := ioutil.WriteFile(filepath.Join(dir, "bad.yang"), []byte("module bad {"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	names = append(names, filepath.Join(dir, "bad.yang"))
+
+	ms := NewModules()
+	var got []string
+	ms.SetProgressFunc(func(p Progress) {
+		if p.Phase == PhaseParse {
+			got = append(got, p.Module)
+		}
+	})
+	errs := ms.ReadAll(names, 4)
+	if len(errs) != 2 || !strings.Contains(errs[0].Error(), "no such file") || !strings.Contains(errs[1].Error(), "bad.yang") {
+		t.Errorf("got errors %v, want errors for missing.yang and bad.yang", errs)
+	}
+	// Modules are added in the order they are named.
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("modules added (-want, +got):\n%s", diff)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Errorf("Process: %v", errs)
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	errs = NewModules().ReadAllContext(cancelled, names[:3], 2)
+	if len(errs) != 3 || errs[0] != context.Canceled {
+		t.Errorf("ReadAllContext: got errors %v, want %v for each module", errs, context.Canceled)
+	}
+
+	// Once MaxErrors names could not be read, the rest are not added.
+	defer func(max int) { ParseOptions.MaxErrors = max }(ParseOptions.MaxErrors)
+	ParseOptions.MaxErrors = 1
+	ms = NewModules()
+	errs = ms.ReadAll([]string{filepath.Join(dir, "missing.yang"), names[0], names[1]}, 4)
+	ParseOptions.MaxErrors = 0
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "no such file") {
+		t.Errorf("ReadAll with MaxErrors 1: got errors %v, want one error for missing.yang", errs)
+	}
+	if len(ms.Modules) != 0 {
+		t.Errorf("ReadAll with MaxErrors 1: got modules %v, want none", ms.Modules)
+	}
+}
+
+func TestModulesReadAllPath(t *testing.T) {
+	// The directories of the named files are added to Path in the order
+	// of the names, so the module found by searching Path for a name does
+	// not depend on jobs.
+	for i, jobs := range []int{1, 2, 8, 8, 8} {
+		// Path is global, so each iteration needs its own module name.
+		clash := fmt.Sprintf("readall-clash-%d", i)
+		var dirs []string
+		for _, d := range []string{"first", "second"} {
+			dir, err := ioutil.TempDir("", "readall-"+d)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			dirs = append(dirs, dir)
+			for _, m := range []string{d, clash} {
+				src := fmt.Sprintf(`module %s { prefix "p"; namespace "urn:%s"; }`, m, m)
+				if err := ioutil.WriteFile(filepath.Join(dir, m+".yang"), []byte(src), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		ms := NewModules()
+		names := []string{clash, filepath.Join(dirs[0], "first.yang"), filepath.Join(dirs[1], "second.yang")}
+		if errs := ms.ReadAll(names, jobs); len(errs) > 0 {
+			t.Fatalf("jobs %d: %v", jobs, errs)
+		}
+		if got, want := Source(ms.Modules[clash]), filepath.Join(dirs[0], clash+".yang")+":1:1"; got != want {
+			t.Errorf("jobs %d: read %s from %s, want %s", jobs, clash, got, want)
+		}
+	}
+}
+
+func TestModulesMerge(t *testing.T) {
+	const types = `module merge-types {
+  prefix t;
+  namespace urn:merge-types;
+  revision 2020-01-01;
+  typedef merge-name { type string; }
+}`
+	base := map[string]string{
+		"merge-types": types,
+		"merge-base": `module merge-base {
+  prefix b;
+  namespace urn:merge-base;
+  import merge-types { prefix t; }
+  container c { leaf l { type t:merge-name; } }
+}`,
+	}
+
+	parse := func(t *testing.T, mods map[string]string) *Modules {
+		t.Helper()
+		ms := NewModules()
+		for name, text := range mods {
+			if err := ms.Parse(text, name+".yang"); err != nil {
+				t.Fatalf("Parse(%s): %v", name, err)
+			}
+		}
+		if errs := ms.Process(); len(errs) > 0 {
+			t.Fatalf("Process: %v", errs)
+		}
+		return ms
+	}
+
+	for _, tt := range []struct {
+		desc    string
+		other   map[string]string
+		wantErr string
+	}{{
+		desc: "add-on with shared import",
+		other: map[string]string{
+			"merge-types": types,
+			"merge-addon": `module merge-addon {
+  prefix a;
+  namespace urn:merge-addon;
+  import merge-types { prefix t; }
+  import merge-base { prefix b; }
+  augment /b:c { leaf x { type t:merge-name; } }
+}`,
+			"merge-base": base["merge-base"],
+		},
+	}, {
+		desc: "conflicting revision",
+		other: map[string]string{
+			"merge-types": `module merge-types {
+  prefix t;
+  namespace urn:merge-types;
+  revision 2021-01-01;
+}`,
+		},
+		wantErr: "conflicting revisions of module merge-types: merge-types@2021-01-01 and merge-types@2020-01-01",
+	}, {
+		desc: "conflicting namespace",
+		other: map[string]string{
+			"merge-other": `module merge-other { prefix o; namespace urn:merge-base; }`,
+		},
+		wantErr: "module merge-other has the same namespace urn:merge-base as merge-base",
+	}, {
+		desc: "same module with a different namespace",
+		other: map[string]string{
+			"merge-base": `module merge-base { prefix b; namespace urn:other; }`,
+		},
+		wantErr: "module merge-base has namespace urn:other, not urn:merge-base",
+	}} {
+		t.Run(tt.desc, func(t *testing.T) {
+			ms := parse(t, base)
+			n := len(ms.Modules)
+			errs := ms.Merge(parse(t, tt.other))
+			if tt.wantErr != "" {
+				if len(errs) != 1 || !strings.Contains(errs[0].Error(), tt.wantErr) {
+					t.Fatalf("got errors %v, want error containing %q", errs, tt.wantErr)
+				}
+				if len(ms.Modules) != n {
+					t.Errorf("got %d modules after failed Merge, want %d", len(ms.Modules), n)
+				}
+				return
+			}
+			if len(errs) > 0 {
+				t.Fatalf("Merge: %v", errs)
+			}
+			if errs := ms.Process(); len(errs) > 0 {
+				t.Fatalf("Process after Merge: %v", errs)
+			}
+			a := ms.Modules["merge-addon"]
+			if a == nil {
+				t.Fatalf("merge-addon not merged")
+			}
+			if got, want := a.Import[0].Module, ms.Modules["merge-types"]; got != want {
+				t.Errorf("merge-addon imports %p, want the merge-types of ms (%p)", got, want)
+			}
+			x := ToEntry(ms.Modules["merge-base"]).Find("c/x")
+			if x == nil {
+				t.Fatalf("augmented leaf c/x not found")
+			}
+			if got, want := x.Type.Kind, Ystring; got != want {
+				t.Errorf("got type %v for c/x, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestIndependentModules(t *testing.T) {
+	// Two independent Modules with different modules of the same name and
+	// prefix must not see each other's typedefs and identities.
+	src := func(id, typ string) string {
+		return `module independent {
+  prefix ind;
+  namespace urn:independent;
+  identity base;
+  identity ` + id + ` { base base; }
+  typedef t { type ` + typ + `; }
+  leaf id { type identityref { base base; } }
+  leaf v { type t; }
+}`
+	}
+	tests := []struct {
+		id   string
+		typ  string
+		kind TypeKind
+		ms   *Modules
+	}{
+		{id: "first", typ: "string", kind: Ystring},
+		{id: "second", typ: "int8", kind: Yint8},
+	}
+	// Read and process both before checking either.
+	for i, tt := range tests {
+		ms := NewModules()
+		if err := ms.Parse(src(tt.id, tt.typ), tt.id+".yang"); err != nil {
+			t.Fatalf("Parse(%s): %v", tt.id, err)
+		}
+		if errs := ms.Process(); len(errs) > 0 {
+			t.Fatalf("Process(%s): %v", tt.id, errs)
+		}
+		tests[i].ms = ms
+	}
+	for _, tt := range tests {
+		e := ToEntry(tt.ms.Modules["independent"])
+		if got := e.Dir["v"].Type.Kind; got != tt.kind {
+			t.Errorf("%s: got type %v, want %v", tt.id, got, tt.kind)
+		}
+		var got []string
+		for _, v := range e.Dir["id"].Type.IdentityBase.Values {
+			got = append(got, v.Name)
+		}
+		if len(got) != 1 || got[0] != tt.id {
+			t.Errorf("%s: got derived identities %v, want [%s]", tt.id, got, tt.id)
+		}
+	}
+
+	// An identity of another Modules is unknown.
+	ms := NewModules()
+	if err := ms.Parse(`module independent {
+  prefix ind;
+  namespace urn:independent;
+  leaf id { type identityref { base first; } }
+}`, "third.yang"); err != nil {
+		t.Fatalf("Parse(third): %v", err)
+	}
+	if errs := ms.Process(); len(errs) == 0 || !strings.Contains(errs.Error(), "can't resolve") {
+		t.Errorf("Process(third): got errors %v, want unresolved identity first", errs)
+	}
+}
+
+func TestConcurrentProcess(t *testing.T) {
+	// Independent Modules must be able to be processed at the same time.
+	// Run with -race to check that they share no state.
+	const src = `module concurrent {
+  prefix c;
+  namespace urn:concurrent;
+  identity base;
+  identity derived { base base; }
+  typedef a { type b; }
+  typedef b { type string { length 1..10; } }
+  typedef loop { type loop; }
+  grouping g { leaf l { type a; } }
+  container c {
+    uses g;
+    leaf id { type identityref { base base; } }
+  }
+}`
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ms := NewModules()
+			if err := ms.Parse(src, "concurrent.yang"); err != nil {
+				errs[i] = err
+				return
+			}
+			if perrs := ms.Process(); len(perrs) > 0 {
+				errs[i] = perrs
+			}
+			if e := ToEntry(ms.Modules["concurrent"]); e.Dir["c"] == nil || e.Dir["c"].Dir["l"] == nil {
+				t.Errorf("#%d: leaf c/l is missing", i)
+			}
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err == nil || !strings.Contains(err.Error(), "typedef loop is based on itself") {
+			t.Errorf("#%d: got error %v, want typedef loop is based on itself", i, err)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements support for the extensions defined in the
+// ietf-netconf-acm module (RFC 8341).
+
+// netconfACM is the name of the module that defines the NACM extensions.
+const netconfACM = "ietf-netconf-acm"
+
+// setNACMExtensions sets the fields of e that are derived from the NACM
+// extensions used in n, the node e was created from.
+func (e *Entry) setNACMExtensions(n Node) {
+	for _, ext := range n.Exts() {
+		switch extensionFrom(ext, n, netconfACM) {
+		case "default-deny-write":
+			e.DefaultDenyWrite = true
+		case "default-deny-all":
+			e.DefaultDenyAll = true
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNACMExtensions(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"ietf-netconf-acm": `
+			module ietf-netconf-acm {
+				prefix "nacm";
+				namespace "urn:ietf:params:xml:ns:yang:ietf-netconf-acm";
+				extension default-deny-write;
+				extension default-deny-all;
+			}
+		`,
+		"other-acm": `
+			module other-acm {
+				prefix "o";
+				namespace "urn:o";
+				extension default-deny-all;
+			}
+		`,
+		"nacm-use": `
+			module nacm-use {
+				prefix "u";
+				namespace "urn:u";
+				import ietf-netconf-acm { prefix "nacm"; }
+				import other-acm { prefix "o"; }
+				container system {
+					nacm:default-deny-write;
+					leaf hostname { type string; }
+					leaf secret { type string; nacm:default-deny-all; }
+					leaf other { type string; o:default-deny-all; }
+				}
+				rpc reboot { nacm:default-deny-all; }
+			}
+		`,
+	} {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	var buf bytes.Buffer
+	if err := ms.Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	entries, err := Load(&buf)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	for _, tt := range []struct {
+		path      string
+		denyWrite bool
+		denyAll   bool
+	}{
+		{path: "/system", denyWrite: true},
+		{path: "/system/hostname"},
+		{path: "/system/secret", denyAll: true},
+		{path: "/system/other"},
+		{path: "/reboot", denyAll: true},
+	} {
+		for source, root := range map[string]*Entry{
+			"processed": ToEntry(ms.Modules["nacm-use"]),
+			"loaded":    entries["nacm-use"],
+		} {
+			e := root.Find(tt.path)
+			if e == nil {
+				t.Errorf("%s %s: not found", source, tt.path)
+				continue
+			}
+			if e.DefaultDenyWrite != tt.denyWrite {
+				t.Errorf("%s %s: DefaultDenyWrite got %v, want %v", source, tt.path, e.DefaultDenyWrite, tt.denyWrite)
+			}
+			if e.DefaultDenyAll != tt.denyAll {
+				t.Errorf("%s %s: DefaultDenyAll got %v, want %v", source, tt.path, e.DefaultDenyAll, tt.denyAll)
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package yang
+
+// This file implements support for the datastore and origin identities of
+// the Network Management Datastore Architecture (NMDA, RFC 8342).
+
+import (
+	"sort"
+	"strings"
+)
+
+// Names of the modules defining the NMDA datastore and origin identities.
+const (
+	DatastoresModule = "ietf-datastores"
+	OriginModule     = "ietf-origin"
+)
+
+// Names of the datastore identities defined by ietf-datastores.
+const (
+	DatastoreRunning     = "running"
+	DatastoreCandidate   = "candidate"
+	DatastoreStartup     = "startup"
+	DatastoreIntended    = "intended"
+	DatastoreOperational = "operational"
+)
+
+// Names of the origin identities defined by ietf-origin.
+const (
+	OriginIntended = "intended"
+	OriginDynamic  = "dynamic"
+	OriginSystem   = "system"
+	OriginLearned  = "learned"
+	OriginDefault  = "default"
+	OriginUnknown  = "unknown"
+)
+
+// ReadNMDA reads the ietf-datastores and ietf-origin modules into ms, using
+// the embedded copies of the modules if they are not found on Path.  It
+// only needs to be called when none of the modules in ms import them.
+func (ms *Modules) ReadNMDA() error {
+	for _, name := range []string{DatastoresModule, OriginModule} {
+		if ms.Modules[name] != nil {
+			continue
+		}
+		if err := ms.Read(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Datastores returns the identities derived from ds:datastore, including
+// those defined by modules other than ietf-datastores, sorted by module and
+// identity name.  Nil is returned if ietf-datastores is not in ms.  The
+// modules of ms must have been processed.
+func (ms *Modules) Datastores() []*Identity {
+	return ms.derivedIdentities(DatastoresModule, "datastore")
+}
+
+// Datastore returns the datastore identity named name, or nil if there is
+// none.  The name is either the name of an identity defined by
+// ietf-datastores, e.g., "running", or a name qualified by the name of the
+// defining module, e.g., "ietf-datastores:running".
+func (ms *Modules) Datastore(name string) *Identity {
+	return findDerived(ms.Datastores(), DatastoresModule, name)
+}
+
+// Origins returns the identities derived from or:origin, including those
+// defined by modules other than ietf-origin, sorted by module and identity
+// name.  Nil is returned if ietf-origin is not in ms.  The modules of ms must
+// have been processed.
+func (ms *Modules) Origins() []*Identity {
+	return ms.derivedIdentities(OriginModule, "origin")
+}
+
+// Origin returns the origin identity named name, or nil if there is none.
+// The name is either the name of an identity defined by ietf-origin, e.g.,
+// "learned", or a name qualified by the name of the defining module, e.g.,
+// "ietf-origin:learned".
+func (ms *Modules) Origin(name string) *Identity {
+	return findDerived(ms.Origins(), OriginModule, name)
+}
+
+// derivedIdentities returns the identities derived from the identity base
+// of the named module, sorted by module and identity name.
+func (ms *Modules) derivedIdentities(module, base string) []*Identity {
+	m := ms.Modules[module]
+	if m == nil {
+		return nil
+	}
+	for _, i := range m.Identity {
+		if i.Name != base {
+			continue
+		}
+		ids := append([]*Identity{}, i.Values...)
+		sort.Slice(ids, func(x, y int) bool {
+			return identityName(ids[x]) < identityName(ids[y])
+		})
+		return ids
+	}
+	return nil
+}
+
+// findDerived returns the identity in ids named name.  An unqualified name
+// refers to an identity of module.
+func findDerived(ids []*Identity, module, name string) *Identity {
+	if !strings.Contains(name, ":") {
+		name = module + ":" + name
+	}
+	for _, i := range ids {
+		if identityName(i) == name {
+			return i
+		}
+	}
+	return nil
+}
+
+// identityName returns the name of i qualified by the name of the module
+// that defines it.
+func identityName(i *Identity) string {
+	return belongsTo(RootNode(i)).Name + ":" + i.Name
+}
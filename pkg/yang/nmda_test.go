@@ -0,0 +1,110 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package yang
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNMDAIdentities(t *testing.T) {
+	ms := NewModules()
+	// ietf-datastores and ietf-origin are not on Path and are read from
+	// the embedded copies when imported.
+	if err := ms.Parse(`
+		module nmda-use {
+			prefix "n";
+			namespace "urn:n";
+			import ietf-datastores { prefix "ds"; }
+			import ietf-origin { prefix "or"; }
+			identity ephemeral { base ds:dynamic; }
+			identity bgp { base or:learned; }
+			leaf datastore { type ds:datastore-ref; }
+		}
+	`, "nmda-use.yang"); err != nil {
+		t.Fatal(err)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	var got []string
+	for _, i := range ms.Datastores() {
+		got = append(got, identityName(i))
+	}
+	want := []string{
+		"ietf-datastores:candidate",
+		"ietf-datastores:conventional",
+		"ietf-datastores:dynamic",
+		"ietf-datastores:intended",
+		"ietf-datastores:operational",
+		"ietf-datastores:running",
+		"ietf-datastores:startup",
+		"nmda-use:ephemeral",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Datastores (-want, +got):\n%s", diff)
+	}
+
+	for _, tt := range []struct {
+		name string
+		find func(string) *Identity
+		want string
+	}{
+		{DatastoreRunning, ms.Datastore, "ietf-datastores:running"},
+		{"ietf-datastores:operational", ms.Datastore, "ietf-datastores:operational"},
+		{"nmda-use:ephemeral", ms.Datastore, "nmda-use:ephemeral"},
+		{"ephemeral", ms.Datastore, ""},
+		{OriginLearned, ms.Origin, "ietf-origin:learned"},
+		{OriginIntended, ms.Origin, "ietf-origin:intended"},
+		{"nmda-use:bgp", ms.Origin, "nmda-use:bgp"},
+		{DatastoreRunning, ms.Origin, ""},
+	} {
+		i := tt.find(tt.name)
+		switch {
+		case i == nil && tt.want != "":
+			t.Errorf("%s: not found", tt.name)
+		case i != nil && identityName(i) != tt.want:
+			t.Errorf("%s: got %s, want %s", tt.name, identityName(i), tt.want)
+		}
+	}
+
+	a := ms.FindAnnotation(OriginModule, "origin")
+	if a == nil {
+		t.Fatal("origin annotation not found")
+	}
+	if got := a.Type.IdentityBase; got == nil || got.Name != "origin" {
+		t.Errorf("origin annotation: got base %v, want origin", got)
+	}
+}
+
+func TestReadNMDA(t *testing.T) {
+	ms := NewModules()
+	if got := ms.Datastores(); got != nil {
+		t.Errorf("Datastores before ReadNMDA: got %v, want nil", got)
+	}
+	if err := ms.ReadNMDA(); err != nil {
+		t.Fatal(err)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if ms.Datastore(DatastoreCandidate) == nil {
+		t.Errorf("candidate datastore not found")
+	}
+	if ms.Origin(OriginSystem) == nil {
+		t.Errorf("system origin not found")
+	}
+}
@@ -36,6 +36,14 @@ type Node interface {
 	// ParentNode returns the parent of this Node, or nil if the
 	// Node has no parent.
 	ParentNode() Node
+	// ParentModule returns the module this Node is defined in.  A Node
+	// defined in a submodule returns the module the submodule belongs to,
+	// if that module has been read.  Nil is returned if the Node is not
+	// part of a module.
+	ParentModule() *Module
+	// SchemaPath returns the schema node identifier of this Node, e.g.,
+	// "/if:interfaces/if:interface".  See SchemaPath for details.
+	SchemaPath() string
 	// Exts returns the list of extension statements found.
 	Exts() []*Statement
 }
@@ -55,6 +63,8 @@ type ErrorNode struct {
 
 func (ErrorNode) Kind() string             { return "error" }
 func (s *ErrorNode) ParentNode() Node      { return s.Parent }
+func (s *ErrorNode) ParentModule() *Module { return parentModule(s) }
+func (s *ErrorNode) SchemaPath() string    { return schemaPath(s) }
 func (s *ErrorNode) NName() string         { return "error" }
 func (s *ErrorNode) Statement() *Statement { return &Statement{} }
 func (s *ErrorNode) Exts() []*Statement    { return nil }
@@ -124,6 +134,39 @@ func FindModuleByPrefix(n Node, prefix string) *Module {
 	return nil
 }
 
+// ResolvePrefixedName resolves the possibly prefixed name, e.g.,
+// "pfx:name", as used in n.  It returns the module whose namespace the name
+// is in along with the name without its prefix.  An unprefixed name, or a
+// name prefixed by the prefix of n's own module, is in the module n belongs
+// to, even when n is defined in a submodule (RFC 7950 section 7.1.4 and
+// 7.2.2).  Otherwise the prefix must be that of a module imported by the
+// module or submodule n was defined in, and that module must have been
+// read.  If n is defined in a submodule whose module has not been read, the
+// submodule is returned in place of its module.
+func ResolvePrefixedName(n Node, name string) (*Module, string, error) {
+	prefix, local := getPrefix(name)
+	if local == "" || strings.Contains(local, ":") {
+		return nil, "", errorf(n, ErrInvalidArgument, "invalid prefixed name %q", name)
+	}
+	root := RootNode(n)
+	if root == nil {
+		return nil, "", errorf(n, ErrInternal, "%s is not in a module", name)
+	}
+	if prefix == "" || prefix == root.GetPrefix() {
+		return belongsTo(root), local, nil
+	}
+	for _, i := range root.Import {
+		if i.Prefix == nil || i.Prefix.Name != prefix {
+			continue
+		}
+		if i.Module == nil {
+			return nil, "", errorf(n, ErrUnknownModule, "module %s imported with prefix %s has not been read", i.Name, prefix)
+		}
+		return i.Module, local, nil
+	}
+	return nil, "", errorf(n, ErrUnknownPrefix, "unknown prefix %s in %s%s", prefix, name, didYouMean(prefix, prefixNames(n)))
+}
+
 // MatchingExtensions returns the subset of the given node's extensions
 // that match the given module and identifier.
 func MatchingExtensions(n Node, module, identifier string) ([]*Statement, error) {
@@ -151,6 +194,80 @@ func RootNode(n Node) *Module {
 	return nil
 }
 
+// parentModule implements the ParentModule method of Node.
+func parentModule(n Node) *Module {
+	m := RootNode(n)
+	if m == nil {
+		return nil
+	}
+	return belongsTo(m)
+}
+
+// schemaKinds are the kinds of statements that are schema nodes and so are
+// part of a schema node identifier.
+var schemaKinds = map[string]bool{
+	"action":       true,
+	"anydata":      true,
+	"anyxml":       true,
+	"case":         true,
+	"choice":       true,
+	"container":    true,
+	"input":        true,
+	"leaf":         true,
+	"leaf-list":    true,
+	"list":         true,
+	"notification": true,
+	"output":       true,
+	"rpc":          true,
+}
+
+// schemaPath implements the SchemaPath method of Node.  It returns the
+// absolute schema node identifier (RFC 7950 section 6.5) of n, with each
+// node qualified by the prefix of the module it is defined in.  Statements
+// that are not schema nodes, such as typedef or must, return the path of
+// the schema node they are part of, which is "/" for the module itself.
+// Nodes defined within an augment are given the path of the augment's
+// target, and nodes within a deviation that of the deviation's target.
+// The empty string is returned for nodes within a grouping, which are not
+// part of the schema tree until the grouping is used, and for nodes that
+// are not part of a module.
+func schemaPath(n Node) string {
+	var path []string
+	for ; n != nil; n = n.ParentNode() {
+		switch n := n.(type) {
+		case *Module:
+			return "/" + strings.Join(path, "/")
+		case *Grouping:
+			return ""
+		case *Augment:
+			if strings.HasPrefix(n.Name, "/") {
+				return strings.TrimSuffix(n.Name, "/") + joinPath(path)
+			}
+			// The augment of a uses statement is relative to the
+			// node the grouping is used in.
+			return strings.TrimSuffix(schemaPath(n.ParentNode()), "/") + "/" + n.Name + joinPath(path)
+		case *Deviation:
+			return n.Name
+		}
+		if schemaKinds[n.Kind()] {
+			name := n.NName()
+			if k := n.Kind(); k == "input" || k == "output" {
+				name = k
+			}
+			path = append([]string{RootNode(n).GetPrefix() + ":" + name}, path...)
+		}
+	}
+	return ""
+}
+
+// joinPath returns the elements of path joined by and preceded by slashes.
+func joinPath(path []string) string {
+	if len(path) == 0 {
+		return ""
+	}
+	return "/" + strings.Join(path, "/")
+}
+
 // NodePath returns the full path of the node from the module name.
 func NodePath(n Node) string {
 	var path string
@@ -374,3 +491,32 @@ Loop:
 		}
 	}
 }
+
+// walkNodes calls fn for n and, recursively, for every node in the AST
+// rooted at n.  Extension statements, and modules that are imported or
+// included, are not walked.
+func walkNodes(n Node, fn func(Node)) {
+	fn(n)
+	v := reflect.ValueOf(n).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		ft := t.Field(i)
+		switch strings.Split(ft.Tag.Get("yang"), ",")[0] {
+		case "", "Name", "Statement", "Parent", "Ext":
+			continue
+		}
+		f := v.Field(i)
+		switch ft.Type.Kind() {
+		case reflect.Ptr:
+			if cn, ok := f.Interface().(Node); ok && !f.IsNil() {
+				walkNodes(cn, fn)
+			}
+		case reflect.Slice:
+			for j := 0; j < f.Len(); j++ {
+				if cn, ok := f.Index(j).Interface().(Node); ok && !f.Index(j).IsNil() {
+					walkNodes(cn, fn)
+				}
+			}
+		}
+	}
+}
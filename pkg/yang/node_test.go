@@ -394,3 +394,151 @@ func TestNode(t *testing.T) {
 		})
 	}
 }
+
+func TestSchemaPath(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"a": `module a {
+  prefix "a";
+  namespace "urn:a";
+  import b { prefix "b"; }
+  include a-sub;
+  typedef t { type string; }
+  container c {
+    leaf l { type t; must "true()"; }
+    choice ch { case one { leaf x { type string; } } }
+    uses b:g { augment "in" { leaf y { type string; } } }
+  }
+  grouping local { leaf z { type string; } }
+  augment "/b:top" { leaf extra { type string; } }
+  rpc r { input { leaf i { type string; } } }
+  deviation "/b:top/b:gone" { deviate not-supported; }
+}`,
+		"a-sub": `submodule a-sub { belongs-to a { prefix "as"; } container sub; }`,
+		"b": `module b {
+  prefix "b";
+  namespace "urn:b";
+  container top { leaf gone { type string; } }
+  grouping g { container in; }
+}`,
+	} {
+		if err := ms.Parse(text, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	a, sub := ms.Modules["a"], ms.SubModules["a-sub"]
+	c := a.Container[0]
+	for _, tt := range []struct {
+		desc string
+		in   Node
+		want string
+	}{
+		{"module", a, "/"},
+		{"typedef", a.Typedef[0], "/"},
+		{"container", c, "/a:c"},
+		{"leaf", c.Leaf[0], "/a:c/a:l"},
+		{"type", c.Leaf[0].Type, "/a:c/a:l"},
+		{"must", c.Leaf[0].Must[0], "/a:c/a:l"},
+		{"case", c.Choice[0].Case[0].Leaf[0], "/a:c/a:ch/a:one/a:x"},
+		{"uses augment", c.Uses[0].Augment.Leaf[0], "/a:c/in/a:y"},
+		{"grouping", a.Grouping[0].Leaf[0], ""},
+		{"augment", a.Augment[0].Leaf[0], "/b:top/a:extra"},
+		{"rpc", a.RPC[0].Input.Leaf[0], "/a:r/a:input/a:i"},
+		{"deviation", a.Deviation[0].Deviate[0], "/b:top/b:gone"},
+		{"submodule", sub.Container[0], "/as:sub"},
+		{"statement", a.Source, ""},
+	} {
+		if got := tt.in.SchemaPath(); got != tt.want {
+			t.Errorf("%s: got SchemaPath %q, want %q", tt.desc, got, tt.want)
+		}
+	}
+
+	for _, tt := range []struct {
+		desc string
+		in   Node
+		want *Module
+	}{
+		{"module", a, a},
+		{"leaf", c.Leaf[0], a},
+		{"submodule", sub, a},
+		{"submodule node", sub.Container[0], a},
+		{"statement", a.Source, nil},
+	} {
+		if got := tt.in.ParentModule(); got != tt.want {
+			t.Errorf("%s: got ParentModule %v, want %v", tt.desc, got, tt.want)
+		}
+	}
+}
+
+func TestResolvePrefixedName(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"r": `module r {
+  prefix "r";
+  namespace "urn:r";
+  import s { prefix "other"; }
+  include r-sub;
+  leaf l { type string; }
+}`,
+		"r-sub": `submodule r-sub {
+  belongs-to r { prefix "rs"; }
+  import s { prefix "s"; }
+  leaf sl { type string; }
+}`,
+		"s": `module s { prefix "s"; namespace "urn:s"; }`,
+	} {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	l := ms.Modules["r"].Leaf[0]
+	sl := ms.SubModules["r-sub"].Leaf[0]
+
+	tests := []struct {
+		desc       string
+		n          Node
+		in         string
+		wantModule string
+		wantName   string
+		wantErr    string
+	}{
+		{desc: "unprefixed", n: l, in: "foo", wantModule: "r", wantName: "foo"},
+		{desc: "own prefix", n: l, in: "r:foo", wantModule: "r", wantName: "foo"},
+		{desc: "import", n: l, in: "other:foo", wantModule: "s", wantName: "foo"},
+		{desc: "unprefixed in submodule", n: sl, in: "foo", wantModule: "r", wantName: "foo"},
+		{desc: "belongs-to prefix", n: sl, in: "rs:foo", wantModule: "r", wantName: "foo"},
+		{desc: "import in submodule", n: sl, in: "s:foo", wantModule: "s", wantName: "foo"},
+		{desc: "module prefix in submodule", n: sl, in: "r:foo", wantErr: "unknown prefix r in r:foo; did you mean rs?"},
+		{desc: "import of module only", n: sl, in: "other:foo", wantErr: "unknown prefix other in other:foo"},
+		{desc: "invalid", n: l, in: "r:", wantErr: `invalid prefixed name "r:"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			m, name, err := ResolvePrefixedName(tt.n, tt.in)
+			if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+				t.Fatal(diff)
+			}
+			if err != nil {
+				return
+			}
+			if m.Name != tt.wantModule || name != tt.wantName {
+				t.Errorf("got %s, %s, want %s, %s", m.Name, name, tt.wantModule, tt.wantName)
+			}
+		})
+	}
+
+	unread := NewModules()
+	if err := unread.Parse(`module u { prefix "u"; namespace "urn:u"; import missing { prefix "m"; } leaf l { type string; } }`, "u.yang"); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err := ResolvePrefixedName(unread.Modules["u"].Leaf[0], "m:foo")
+	if diff := errdiff.Substring(err, "module missing imported with prefix m has not been read"); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements support for the extensions defined in the
+// openconfig-extensions module.  The posix-pattern extension is handled
+// along with the other type restrictions in types.go.
+
+// openconfigExtensions is the name of the module that defines the
+// OpenConfig extensions.
+const openconfigExtensions = "openconfig-extensions"
+
+// ocExtension returns the name of the OpenConfig extension ext, used in n,
+// or "" if ext is not an OpenConfig extension.
+func ocExtension(ext *Statement, n Node) string {
+	return extensionFrom(ext, n, openconfigExtensions)
+}
+
+// setOpenConfigExtensions sets the fields of m that are derived from the
+// OpenConfig extensions used in m.
+func (m *Module) setOpenConfigExtensions() {
+	m.OpenConfigVersion = ""
+	m.CatalogOrganization = ""
+	m.RegexpPOSIX = false
+	for _, ext := range m.Extensions {
+		switch ocExtension(ext, m) {
+		case "openconfig-version":
+			m.OpenConfigVersion = ext.Argument
+		case "catalog-organization":
+			m.CatalogOrganization = ext.Argument
+		case "regexp-posix":
+			m.RegexpPOSIX = true
+		}
+	}
+}
+
+// setOpenConfigExtensions sets the fields of e that are derived from the
+// OpenConfig extensions used in n, the node e was created from.
+func (e *Entry) setOpenConfigExtensions(n Node) {
+	for _, ext := range n.Exts() {
+		switch ocExtension(ext, n) {
+		case "telemetry-atomic":
+			e.TelemetryAtomic = true
+		case "operational":
+			e.Operational = true
+		}
+	}
+}
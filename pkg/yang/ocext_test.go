@@ -0,0 +1,111 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import "testing"
+
+func TestOpenConfigExtensions(t *testing.T) {
+	mods := map[string]string{
+		"openconfig-extensions": `
+			module openconfig-extensions {
+				prefix "oc-ext";
+				namespace "http://openconfig.net/yang/openconfig-ext";
+				extension openconfig-version { argument "semver"; }
+				extension catalog-organization { argument "org"; }
+				extension regexp-posix;
+				extension telemetry-atomic;
+				extension operational;
+			}
+		`,
+		"other-ext": `
+			module other-ext {
+				prefix "o";
+				namespace "urn:o";
+				extension operational;
+			}
+		`,
+		"oc-use": `
+			module oc-use {
+				prefix "u";
+				namespace "urn:u";
+				import openconfig-extensions { prefix "oc-ext"; }
+				import other-ext { prefix "o"; }
+				oc-ext:openconfig-version "1.2.3";
+				oc-ext:catalog-organization "openconfig";
+				oc-ext:regexp-posix;
+				grouping g {
+					container gc { oc-ext:telemetry-atomic; }
+				}
+				container c {
+					oc-ext:telemetry-atomic;
+					leaf l { type string; oc-ext:operational; }
+					leaf other { type string; o:operational; }
+					uses g;
+				}
+				augment "/c" {
+					leaf a { type string; oc-ext:operational; }
+				}
+			}
+		`,
+	}
+	ms := NewModules()
+	for name, text := range mods {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	m := ms.Modules["oc-use"]
+	if got, want := m.OpenConfigVersion, "1.2.3"; got != want {
+		t.Errorf("OpenConfigVersion: got %q, want %q", got, want)
+	}
+	if got, want := m.CatalogOrganization, "openconfig"; got != want {
+		t.Errorf("CatalogOrganization: got %q, want %q", got, want)
+	}
+	if !m.RegexpPOSIX {
+		t.Errorf("RegexpPOSIX: got false, want true")
+	}
+	if m := ms.Modules["other-ext"]; m.OpenConfigVersion != "" || m.RegexpPOSIX {
+		t.Errorf("other-ext: got OpenConfigVersion %q, RegexpPOSIX %v, want none", m.OpenConfigVersion, m.RegexpPOSIX)
+	}
+
+	e := ToEntry(m)
+	for _, tt := range []struct {
+		path            string
+		telemetryAtomic bool
+		operational     bool
+	}{
+		{path: "/c", telemetryAtomic: true},
+		{path: "/c/l", operational: true},
+		{path: "/c/other"},
+		{path: "/c/gc", telemetryAtomic: true},
+		{path: "/c/a", operational: true},
+	} {
+		ce := e.Find(tt.path)
+		if ce == nil {
+			t.Errorf("%s: not found", tt.path)
+			continue
+		}
+		if ce.TelemetryAtomic != tt.telemetryAtomic {
+			t.Errorf("%s: TelemetryAtomic: got %v, want %v", tt.path, ce.TelemetryAtomic, tt.telemetryAtomic)
+		}
+		if ce.Operational != tt.operational {
+			t.Errorf("%s: Operational: got %v, want %v", tt.path, ce.Operational, tt.operational)
+		}
+	}
+}
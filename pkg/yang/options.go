@@ -27,9 +27,98 @@ type Options struct {
 	// generated within the schema to store the logical grouping from which it
 	// is derived.
 	StoreUses bool
+	// WarningsAsErrors causes Process to report warnings, such as the use
+	// of deprecated constructs or problems that were recovered from, as
+	// errors.
+	WarningsAsErrors bool
+	// Severities overrides the severity of the diagnostics with the given
+	// codes.  Process reports a diagnostic whose severity is
+	// SeverityError as an error, one whose severity is SeverityWarning as
+	// a warning, and does not report one whose severity is
+	// SeverityIgnore.  The import-without-revision and
+	// missing-description warnings are ignored unless given a severity
+	// here.  Demoting an error does not change how Process handles the
+	// problem, so the Entry trees may be incomplete.
+	Severities map[Code]Severity
+	// StrictYangVersion causes Process to report an error for each
+	// statement that the yang-version of its module does not allow, e.g.,
+	// an action in a module without "yang-version 1.1", for yang-version
+	// arguments other than 1 and 1.1, and for modules that include a
+	// submodule of a different YANG version.
+	StrictYangVersion bool
+	// StrictConformance causes Process to report an error for each
+	// violation of a constraint of RFC 7950 that is otherwise not
+	// checked, e.g., a configuration list without a key, a key or unique
+	// statement that does not refer to leaves of the list, or a mandatory
+	// leaf or choice with a default.
+	StrictConformance bool
+	// Lenient accepts the quirks of real-world modules that violate RFC
+	// 7950, so that they can still be converted to Entry trees.  Unknown
+	// substatements are skipped with a warning when parsing rather than
+	// being reported as errors.
+	Lenient bool
+	// PruneObsolete causes Process to remove the entries of nodes whose
+	// status is obsolete, along with their descendants, from the Entry
+	// trees of modules.
+	PruneObsolete bool
+	// Logger receives the internal messages of the package.  If nil,
+	// warnings and errors are written to standard error.
+	Logger Logger
+	// Catalog, if not nil, supplies the text of errors and warnings in
+	// place of the built-in English text.
+	Catalog Catalog
+	// Debug causes the resolution of types, the expansion of groupings,
+	// and the application of augments and deviations to be logged to
+	// Logger at LevelDebug.
+	Debug bool
+	// NoPanicRecovery disables converting a panic within Parse, Read or
+	// Process into an error.  The panic, and its stack trace, then
+	// reaches the caller, which is useful when debugging goyang itself.
+	NoPanicRecovery bool
+	// QualifiedPaths causes the Path method of Entry to return the path
+	// returned by QualifiedPath, i.e., a path whose nodes are qualified by
+	// their module name where RFC 7951 requires it, rather than a path
+	// starting with the name of the module.
+	QualifiedPaths bool
+
+	// The following limits protect against pathological modules, such as
+	// modules from untrusted sources.  A limit of zero means no limit.
+
+	// MaxStatementDepth is the maximum depth to which statements may be
+	// nested within a single file.  The module statement itself is at a
+	// depth of 1.
+	MaxStatementDepth int
+	// MaxUsesDepth is the maximum depth to which uses statements may be
+	// nested, i.e., how many groupings may be in the process of being
+	// expanded at once.  A grouping that uses itself is always an error.
+	MaxUsesDepth int
+	// MaxFileSize is the maximum size, in bytes, of a source file.
+	MaxFileSize int
+	// MaxErrors is the maximum number of errors reported by Process.
+	// Once that many errors have been found, Process stops as soon as it
+	// can and the errors following the first MaxErrors are replaced by a
+	// single ErrTooManyErrors error that says how many errors were not
+	// reported, see DroppedErrors.  As Process stops early, that is the
+	// number of errors it found, there may be more.
+	MaxErrors int
 }
 
-// ParseOptions sets the options for the current YANG module parsing. It can be
-// directly set by the caller to influence how goyang will behave in the presence
-// of certain exceptional cases.
+// ParseOptions is the default for the options of a Modules.  NewModules
+// copies it, so changing it does not affect the Modules that already exist.
+// It also provides the options for the functions that are not given a
+// Modules, such as Parse and BuildAST.
+//
+// Deprecated: use NewModulesWithOptions to give each Modules its own
+// options.
 var ParseOptions = Options{}
+
+// optionsOf returns the options of the Modules that n was added to, or
+// ParseOptions if n is nil or not part of a Modules.
+func optionsOf(n Node) *Options {
+	if n != nil {
+		if m := RootNode(n); m != nil && m.modules != nil {
+			return &m.modules.opts
+		}
+	}
+	return &ParseOptions
+}
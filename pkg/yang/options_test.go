@@ -0,0 +1,293 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+// nested returns a module with depth nested containers.
+func nested(depth int) string {
+	return fmt.Sprintf(`module nested { prefix "n"; namespace "urn:n"; %s leaf l { type string; } %s }`,
+		strings.Repeat("container c {", depth), strings.Repeat("}", depth))
+}
+
+func TestLimits(t *testing.T) {
+	defer func(o Options) { ParseOptions = o }(ParseOptions)
+
+	dir, err := ioutil.TempDir("", "goyang-limits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	big := filepath.Join(dir, "nested.yang")
+	if err := ioutil.WriteFile(big, []byte(nested(10)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	usesChain := `
+		module uses-chain {
+			prefix "u";
+			namespace "urn:u";
+			grouping g1 { uses g2; }
+			grouping g2 { uses g3; }
+			grouping g3 { leaf l { type string; } }
+			container c { uses g1; }
+		}`
+
+	for _, tt := range []struct {
+		desc    string
+		opts    Options
+		in      string // module to parse
+		read    string // file to read
+		wantErr string
+	}{{
+		desc: "no limits",
+		in:   nested(100),
+	}, {
+		desc: "within statement depth",
+		opts: Options{MaxStatementDepth: 102},
+		in:   nested(100),
+	}, {
+		desc:    "statement depth exceeded",
+		opts:    Options{MaxStatementDepth: 50},
+		in:      nested(100),
+		wantErr: "nested more than 50 deep",
+	}, {
+		desc: "within uses depth",
+		opts: Options{MaxUsesDepth: 3},
+		in:   usesChain,
+	}, {
+		desc:    "uses depth exceeded",
+		opts:    Options{MaxUsesDepth: 2},
+		in:      usesChain,
+		wantErr: "uses of g3 nested more than 2 deep",
+	}, {
+		desc: "grouping uses itself",
+		in: `
+			module uses-self {
+				prefix "u";
+				namespace "urn:u";
+				grouping g { container c { uses g; } }
+				container top { uses g; }
+			}`,
+		wantErr: "grouping g uses itself",
+	}, {
+		desc:    "parsed file too large",
+		opts:    Options{MaxFileSize: 20},
+		in:      nested(1),
+		wantErr: "larger than the maximum of 20 bytes",
+	}, {
+		desc: "read file within size",
+		opts: Options{MaxFileSize: 1000},
+		read: big,
+	}, {
+		desc:    "read file too large",
+		opts:    Options{MaxFileSize: 20},
+		read:    big,
+		wantErr: "larger than the maximum of 20 bytes",
+	}} {
+		t.Run(tt.desc, func(t *testing.T) {
+			ParseOptions = tt.opts
+			ms := NewModules()
+			var err error
+			if tt.read != "" {
+				err = ms.Read(tt.read)
+			} else {
+				err = ms.Parse(tt.in, "test.yang")
+			}
+			if err == nil {
+				if errs := ms.Process(); len(errs) > 0 {
+					err = errs[0]
+				}
+			}
+			if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestStatementDepthError(t *testing.T) {
+	opts := &Options{MaxStatementDepth: 2}
+	for _, tt := range []struct {
+		desc  string
+		parse func(ctx context.Context, input, path string, opts *Options) ([]*Statement, error)
+		in    string
+		want  Position
+	}{{
+		desc:  "yang",
+		parse: parse,
+		in: `module m {
+  container a {
+    container b { }
+  }
+}`,
+		want: Position{File: "test", Line: 3, Col: 5},
+	}, {
+		desc:  "yin",
+		parse: parseYIN,
+		in: `<module name="m" xmlns="urn:ietf:params:xml:ns:yang:yin:1">
+  <container name="a">
+    <container name="b"/>
+  </container>
+</module>`,
+		want: Position{File: "test", Line: 3, Col: 5},
+	}} {
+		// The error is at the statement that is nested too deep.
+		_, err := tt.parse(context.Background(), tt.in, "test", opts)
+		e, ok := err.(*Error)
+		if !ok {
+			t.Errorf("%s: got %T %v, want *Error", tt.desc, err, err)
+			continue
+		}
+		if e.Code != ErrLimitExceeded || e.Position() != tt.want {
+			t.Errorf("%s: got %s at %+v, want %s at %+v", tt.desc, e.Code, e.Position(), ErrLimitExceeded, tt.want)
+		}
+		if got, want := e.Msg, "statements nested more than 2 deep"; got != want {
+			t.Errorf("%s: got message %q, want %q", tt.desc, got, want)
+		}
+	}
+}
+
+func TestMaxErrors(t *testing.T) {
+	defer func(o Options) { ParseOptions = o }(ParseOptions)
+
+	const in = `module bad {
+  prefix "b";
+  namespace "urn:b";
+  leaf a { type t1; }
+  leaf b { type t2; }
+  leaf c { type t3; }
+  leaf d { type t4; }
+}`
+	for _, tt := range []struct {
+		max     int
+		want    []string
+		dropped int // errors counted by the ErrTooManyErrors error
+	}{{
+		max: 0,
+		want: []string{
+			"bad.yang:4:12: unknown type: b:t1",
+			"bad.yang:5:12: unknown type: b:t2",
+			"bad.yang:6:12: unknown type: b:t3",
+			"bad.yang:7:12: unknown type: b:t4",
+		},
+	}, {
+		max: 4,
+		want: []string{
+			"bad.yang:4:12: unknown type: b:t1",
+			"bad.yang:5:12: unknown type: b:t2",
+			"bad.yang:6:12: unknown type: b:t3",
+			"bad.yang:7:12: unknown type: b:t4",
+		},
+	}, {
+		max: 2,
+		want: []string{
+			"bad.yang:4:12: unknown type: b:t1",
+			"bad.yang:5:12: unknown type: b:t2",
+			"at least 2 more errors were not reported, only the first 2 are reported",
+		},
+		dropped: 2,
+	}} {
+		ParseOptions.MaxErrors = tt.max
+		ms := NewModules()
+		if err := ms.Parse(in, "bad.yang"); err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		dropped := 0
+		for _, err := range ms.Process() {
+			got = append(got, err.Error())
+			dropped += DroppedErrors(err)
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("MaxErrors %d (-want, +got):\n%s", tt.max, diff)
+		}
+		if dropped != tt.dropped {
+			t.Errorf("MaxErrors %d: got %d dropped errors, want %d", tt.max, dropped, tt.dropped)
+		}
+	}
+}
+
+func TestModulesOptions(t *testing.T) {
+	defer func(o Options) { ParseOptions = o }(ParseOptions)
+
+	dir, err := ioutil.TempDir("", "goyang-options")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var dirs []string
+	for _, name := range []string{"a", "b"} {
+		d := filepath.Join(dir, name)
+		if err := os.Mkdir(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+		m := fmt.Sprintf(`module m { prefix "m"; namespace "urn:m"; leaf %s { type string; } }`, name)
+		if err := ioutil.WriteFile(filepath.Join(d, "m.yang"), []byte(m), 0644); err != nil {
+			t.Fatal(err)
+		}
+		dirs = append(dirs, d)
+	}
+
+	// Each Modules has its own options and search path.
+	ms1 := NewModulesWithOptions(Options{MaxFileSize: 20})
+	ms1.AddPath(dirs[0])
+	ms2 := NewModulesWithOptions(Options{})
+	ms2.AddPath(dirs[1])
+	if diff := errdiff.Substring(ms1.Read("m"), "larger than the maximum of 20 bytes"); diff != "" {
+		t.Errorf("Read with MaxFileSize 20: %s", diff)
+	}
+	if err := ms2.Read("m"); err != nil {
+		t.Fatalf("Read without MaxFileSize: %v", err)
+	}
+	if got, want := ms2.Path(), dirs[1:]; !cmp.Equal(got, want) {
+		t.Errorf("Path() = %v, want %v", got, want)
+	}
+	if errs := ms2.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if e := ToEntry(ms2.Modules["m"]); e.Dir["b"] == nil {
+		t.Errorf("read m from the search path of the other Modules, got leaves %v", e.Dir)
+	}
+	for _, d := range dirs {
+		for _, p := range Path {
+			if p == d {
+				t.Errorf("AddPath of a Modules added %s to Path", d)
+			}
+		}
+	}
+
+	// NewModules copies ParseOptions.
+	ParseOptions = Options{MaxFileSize: 20}
+	ms := NewModules()
+	ParseOptions = Options{}
+	if got := ms.Options().MaxFileSize; got != 20 {
+		t.Errorf("NewModules: got MaxFileSize %d, want 20", got)
+	}
+	if diff := errdiff.Substring(ms.Parse(nested(1), "nested.yang"), "larger than the maximum of 20 bytes"); diff != "" {
+		t.Errorf("Parse after changing ParseOptions: %s", diff)
+	}
+}
@@ -23,7 +23,7 @@ package yang
 
 import (
 	"bytes"
-	"errors"
+	"context"
 	"fmt"
 	"io"
 	"strings"
@@ -31,13 +31,21 @@ import (
 
 // a parser is used to parse the contents of a single .yang file.
 type parser struct {
-	lex    *lexer
-	errout *bytes.Buffer
+	lex    *lexer   // the lexer, whose errs also has the errors of the parser
 	tokens []*token // stack of pushed tokens (for backing up)
 
 	// Depth of statements in nested braces
 	statementDepth int
 
+	opts *Options // options of the parse
+
+	// ctx is checked for cancellation every ctxCheckInterval statements.
+	// Once ctx is done, or a limit in opts is exceeded, err is set and
+	// parsing stops.
+	ctx        context.Context
+	statements int
+	err        error
+
 	// hitBrace is returned when we encounter a '}'.  The statement location
 	// is updated with the location of the '}'.  The brace may be legitimate
 	// but only the caller will know if it is.  That is, the brace may be
@@ -58,6 +66,20 @@ type Statement struct {
 	file string
 	line int // 1's based line number
 	col  int // 1's based column number
+
+	// The spans of the statement, from its keyword through its
+	// terminating ; or }, of its keyword, and of its argument.
+	span        Span
+	keywordSpan Span
+	argSpan     Span
+}
+
+// A Span is a range of bytes in the source of a statement, from Start up
+// to, but not including, End.  The zero Span means the range is not known,
+// e.g., for statements that were not parsed from YANG source.
+type Span struct {
+	Start int
+	End   int
 }
 
 // FakeStatement returns a statement filled in with keyword, file, line and col.
@@ -74,6 +96,8 @@ func (s *Statement) NName() string         { return s.Argument }
 func (s *Statement) Kind() string          { return s.Keyword }
 func (s *Statement) Statement() *Statement { return s }
 func (s *Statement) ParentNode() Node      { return nil }
+func (s *Statement) ParentModule() *Module { return nil }
+func (s *Statement) SchemaPath() string    { return "" }
 func (s *Statement) Exts() []*Statement    { return nil }
 
 // Arg returns the optional argument to s.  It returns false if s has no
@@ -95,18 +119,30 @@ func (s *Statement) String() string {
 
 // Location returns the location in the source where s was defined.
 func (s *Statement) Location() string {
-	switch {
-	case s.file == "" && s.line == 0:
-		return "unknown"
-	case s.file == "":
-		return fmt.Sprintf("line %d:%d", s.line, s.col)
-	case s.line == 0:
-		return s.file
-	default:
-		return fmt.Sprintf("%s:%d:%d", s.file, s.line, s.col)
+	return s.Position().String()
+}
+
+// Position returns the position in the source where s was defined.  It is
+// the zero Position if s is nil.
+func (s *Statement) Position() Position {
+	if s == nil {
+		return Position{}
 	}
+	return Position{File: s.file, Line: s.line, Col: s.col}
 }
 
+// Span returns the span of s in its source, from the start of its keyword
+// through its terminating semicolon or closing brace.
+func (s *Statement) Span() Span { return s.span }
+
+// KeywordSpan returns the span of the keyword of s in its source.
+func (s *Statement) KeywordSpan() Span { return s.keywordSpan }
+
+// ArgumentSpan returns the span of the argument of s in its source,
+// including any quotes, or the zero Span if s has no argument.  The span
+// of a concatenated argument, e.g., "a" + "b", covers all of its parts.
+func (s *Statement) ArgumentSpan() Span { return s.argSpan }
+
 // Write writes the tree in s to w, each line indented by ident.  Children
 // nodes are indented further by a tab.  Typically indent is "" at the top
 // level.  Write is intended to display the contents of Statement, but
@@ -169,35 +205,75 @@ var ignoreMe = &Statement{}
 // Parse parses the input as generic YANG and returns the statements parsed.
 // The path parameter should be the source name where input was read from (e.g.,
 // the file name the input was read from).  If one more more errors are
-// encountered, nil and an error are returned.  The error's text includes all
-// errors encountered.
+// encountered, nil and an error are returned.  The error is an *Error, or,
+// when more than one error was encountered, an Errors with one *Error for
+// each error.  Its text includes all errors encountered: after a syntax error
+// the rest of the statement is skipped and parsing resumes with the next
+// statement.
 func Parse(input, path string) ([]*Statement, error) {
-	var statements []*Statement
+	return ParseContext(context.Background(), input, path)
+}
+
+// ctxCheckInterval is the number of statements parsed between checks of
+// whether the parse has been cancelled.
+const ctxCheckInterval = 256
+
+// ParseContext is like Parse but returns nil and ctx.Err() if ctx is done
+// before the input has been parsed.
+func ParseContext(ctx context.Context, input, path string) ([]*Statement, error) {
+	return parse(ctx, input, path, &ParseOptions)
+}
+
+// parse implements ParseContext using opts.
+func parse(ctx context.Context, input, path string, opts *Options) (statements []*Statement, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			statements = nil
+		}
+	}()
+	defer recoverError(opts, &err)
 	p := &parser{
 		lex:      newLexer(input, path),
-		errout:   &bytes.Buffer{},
 		hitBrace: &Statement{},
+		opts:     opts,
+		ctx:      ctx,
 	}
-	p.lex.errout = p.errout
+	p.lex.opts = opts
 Loop:
 	for {
 		switch ns := p.nextStatement(); ns {
 		case nil:
 			break Loop
 		case p.hitBrace:
-			fmt.Fprintf(p.errout, "%s:%d:%d: unexpected %c\n", ns.file, ns.line, ns.col, closeBrace)
+			p.errorf(ns.Position(), "unexpected %c", closeBrace)
+		case ignoreMe:
 		default:
 			statements = append(statements, ns)
 		}
 	}
 
+	if p.err != nil {
+		return nil, p.err
+	}
+
 	p.checkStatementDepth()
 
-	if p.errout.Len() == 0 {
+	switch errs := p.lex.errs; len(errs) {
+	case 0:
 		return statements, nil
+	case 1:
+		return nil, errs[0]
+	default:
+		return nil, errs
 	}
-	return nil, errors.New(strings.TrimSpace(p.errout.String()))
+}
 
+// errorf records a syntax error at pos.
+func (p *parser) errorf(pos Position, format string, v ...interface{}) {
+	p.lex.errs = append(p.lex.errs, errorfAt(pos, ErrSyntax, format, v...))
 }
 
 // push pushes tokens t back on the input stream so they will be the next
@@ -259,6 +335,7 @@ func (p *parser) next() *token {
 			// concatenate the text and drop the nt and st tokens
 			// try again
 			t.Text += st.Text
+			t.End = st.End
 		default:
 			p.push(st, nt)
 			return t
@@ -270,6 +347,14 @@ func (p *parser) next() *token {
 // nextStatement returns the next statement in the input, which may in turn
 // recurse to read sub statements.
 func (p *parser) nextStatement() *Statement {
+	if p.err != nil {
+		return nil
+	}
+	if p.statements++; p.statements%ctxCheckInterval == 0 {
+		if p.err = p.ctx.Err(); p.err != nil {
+			return nil
+		}
+	}
 	t := p.next()
 	switch t.Code() {
 	case tEOF:
@@ -279,10 +364,12 @@ func (p *parser) nextStatement() *Statement {
 		p.hitBrace.file = t.File
 		p.hitBrace.line = t.Line
 		p.hitBrace.col = t.Col
+		p.hitBrace.span = Span{t.Offset, t.End}
 		return p.hitBrace
 	case tIdentifier:
 	default:
-		fmt.Fprintf(p.errout, "%v: not an identifier\n", t)
+		p.errorf(t.position(), "%s: not an identifier", t.name())
+		p.skip(t)
 		return ignoreMe
 	}
 
@@ -291,6 +378,8 @@ func (p *parser) nextStatement() *Statement {
 		file:    t.File,
 		line:    t.Line,
 		col:     t.Col,
+
+		keywordSpan: Span{t.Offset, t.End},
 	}
 
 	// The keyword "pattern" must be treated special.  When
@@ -303,32 +392,70 @@ func (p *parser) nextStatement() *Statement {
 	case tString, tIdentifier:
 		s.HasArgument = true
 		s.Argument = t.Text
+		s.argSpan = Span{t.Offset, t.End}
 		t = p.next()
 	}
 	switch t.Code() {
 	case tEOF:
-		fmt.Fprintf(p.errout, "%s: unexpected EOF\n", s.file)
+		p.errorf(Position{File: s.file}, "unexpected EOF")
 		return nil
 	case ';':
+		s.span = Span{s.keywordSpan.Start, t.End}
 		return s
 	case openBrace:
 		p.statementDepth += 1
+		if max := p.opts.MaxStatementDepth; max > 0 && p.statementDepth > max {
+			p.err = errorfAt(s.Position(), ErrLimitExceeded, "statements nested more than %d deep", max)
+			return nil
+		}
 		for {
 			switch ns := p.nextStatement(); ns {
 			case nil:
 				return nil
 			case p.hitBrace:
+				s.span = Span{s.keywordSpan.Start, ns.span.End}
 				return s
+			case ignoreMe:
 			default:
 				s.statements = append(s.statements, ns)
 			}
 		}
 	default:
-		fmt.Fprintf(p.errout, "%v: syntax error\n", t)
+		p.errorf(t.position(), "%s: syntax error", t.name())
+		p.skip(t)
 		return ignoreMe
 	}
 }
 
+// skip skips the rest of a statement with a syntax error, starting with
+// its token t, so that parsing can continue with the next statement.  The
+// tokens up to and including the next ; are skipped, or, if a { comes
+// first, up to and including its matching }.  A } that ends the enclosing
+// statement is not skipped.
+func (p *parser) skip(t *token) {
+	depth := 0
+	for ; ; t = p.next() {
+		switch t.Code() {
+		case tEOF:
+			return
+		case ';':
+			if depth == 0 {
+				return
+			}
+		case openBrace:
+			depth++
+		case closeBrace:
+			if depth == 0 {
+				p.push(t)
+				return
+			}
+			if depth--; depth == 0 {
+				return
+			}
+		}
+	}
+}
+
 // Checks that we have a statement depth of 0. It's an error to exit
 // the parser with a depth of > 0, it means we are missing closing
 // braces. Note: the parser will error out for the case where we
@@ -338,14 +465,13 @@ func (p *parser) nextStatement() *Statement {
 // we may exit early due to those errors -- and therefore there *might*
 // not really be a mismatched brace issue.
 func (p *parser) checkStatementDepth() {
-	if p.errout.Len() > 0 || p.statementDepth < 1 {
+	if len(p.lex.errs) > 0 || p.statementDepth < 1 {
 		return
 	}
 
-	plural := ""
+	format := "missing %d closing brace"
 	if p.statementDepth > 1 {
-		plural = "s"
+		format = "missing %d closing braces"
 	}
-	fmt.Fprintf(p.errout, "%s:%d:%d: missing %d closing brace%s\n",
-		p.lex.file, p.lex.line, p.lex.col, p.statementDepth, plural)
+	p.errorf(Position{File: p.lex.file, Line: p.lex.line, Col: p.lex.col}, format, p.statementDepth)
 }
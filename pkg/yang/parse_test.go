@@ -17,6 +17,8 @@ package yang
 import (
 	"bytes"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func (s1 *Statement) equal(s2 *Statement) bool {
@@ -239,9 +241,24 @@ id
 		{line: line(), in: `
 statement one two { }
 `,
-			err: `test.yang:2:15: two: syntax error
-test.yang:2:19: {: not an identifier
-test.yang:2:21: unexpected }`,
+			err: `test.yang:2:15: two: syntax error`,
+		},
+		// After a syntax error parsing continues with the next
+		// statement so all the errors are reported.
+		{line: line(), in: `
+module m {
+  leaf a b c;
+  leaf ok { type string; }
+  container c {
+    "x" { y; }
+    leaf d e { f; }
+  }
+  leaf g h }
+`,
+			err: `test.yang:3:10: b: syntax error
+test.yang:6:5: x: not an identifier
+test.yang:7:12: e: syntax error
+test.yang:9:10: h: syntax error`,
 		},
 		{line: line(), in: `
     }
@@ -263,7 +280,6 @@ foo {
 	}
 }`,
 			err: `test.yang:2:1: {: not an identifier
-test.yang:4:1: unexpected }
 test.yang:6:7: invalid escape sequence: \V
 test.yang:9:9: invalid escape sequence: \3
 test.yang:9:9: missing closing "
@@ -319,6 +335,72 @@ module base {
 	}
 }
 
+func TestParseErrors(t *testing.T) {
+	// Each syntax error is an *Error at the location of the error.
+	_, err := Parse(`
+module m {
+  leaf a b c;
+  "x" { y; }
+  }
+}
+`, "test.yang")
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("got %T %v, want Errors", err, err)
+	}
+	want := []Diagnostic{
+		{Code: ErrSyntax, Severity: "error", File: "test.yang", Line: 3, Column: 10, Message: "b: syntax error"},
+		{Code: ErrSyntax, Severity: "error", File: "test.yang", Line: 4, Column: 3, Message: "x: not an identifier"},
+		{Code: ErrSyntax, Severity: "error", File: "test.yang", Line: 6, Column: 1, Message: "unexpected }"},
+	}
+	if diff := cmp.Diff(want, Diagnostics(errs)); diff != "" {
+		t.Errorf("Diagnostics (-want, +got):\n%s", diff)
+	}
+
+	// A single error is returned as is.
+	_, err = Parse(`module m { leaf a b c; }`, "test.yang")
+	if e, ok := err.(*Error); !ok || e.Position() != (Position{File: "test.yang", Line: 1, Col: 19}) {
+		t.Errorf("got %T %v, want an *Error at test.yang:1:19", err, err)
+	}
+}
+
+func TestStatementSpans(t *testing.T) {
+	const in = `module m {
+  description "héllo" + 'wörld';
+  leaf l { type string; }
+  pattern '[a-z]+';
+  presence;
+}`
+	ss, err := Parse(in, "m.yang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := func(sp Span) string { return in[sp.Start:sp.End] }
+	type spans struct{ Statement, Keyword, Argument string }
+	get := func(s *Statement) spans {
+		return spans{text(s.Span()), text(s.KeywordSpan()), text(s.ArgumentSpan())}
+	}
+	m := ss[0]
+	for _, tt := range []struct {
+		s    *Statement
+		want spans
+	}{
+		{m, spans{in, "module", "m"}},
+		{m.statements[0], spans{`description "héllo" + 'wörld';`, "description", `"héllo" + 'wörld'`}},
+		{m.statements[1], spans{"leaf l { type string; }", "leaf", "l"}},
+		{m.statements[1].statements[0], spans{"type string;", "type", "string"}},
+		{m.statements[2], spans{"pattern '[a-z]+';", "pattern", "'[a-z]+'"}},
+		{m.statements[3], spans{"presence;", "presence", ""}},
+	} {
+		if diff := cmp.Diff(tt.want, get(tt.s)); diff != "" {
+			t.Errorf("%s: spans (-want, +got):\n%s", tt.s.Keyword, diff)
+		}
+	}
+	if got := m.statements[3].ArgumentSpan(); got != (Span{}) {
+		t.Errorf("presence: got ArgumentSpan %v, want the zero Span", got)
+	}
+}
+
 func TestWrite(t *testing.T) {
 Testing:
 	for _, tt := range []struct {
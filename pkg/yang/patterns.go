@@ -0,0 +1,174 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the lazy compilation of the patterns of a YangType.
+// Patterns are only compiled when first needed, and identical sets of
+// patterns, which are common in large models, share a single compiled form.
+// The shared forms are kept in a cache of bounded size, so long running
+// programs that load many modules do not keep every pattern set ever
+// compiled.
+
+import (
+	"container/list"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Patterns contains the compiled patterns of a YangType.  A string is valid
+// only if it matches all of the patterns and none of the inverted patterns.
+type Patterns struct {
+	XSD      []*regexp.Regexp // compiled from YangType.Pattern
+	POSIX    []*regexp.Regexp // compiled from YangType.POSIXPattern
+	Inverted []*regexp.Regexp // compiled from YangType.InvertedPattern
+}
+
+// MatchString reports whether s matches all the patterns in p and none of
+// its inverted patterns.  A nil p matches all strings.
+func (p *Patterns) MatchString(s string) bool {
+	if p == nil {
+		return true
+	}
+	for _, re := range p.XSD {
+		if !re.MatchString(s) {
+			return false
+		}
+	}
+	for _, re := range p.POSIX {
+		if !re.MatchString(s) {
+			return false
+		}
+	}
+	for _, re := range p.Inverted {
+		if re.MatchString(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// compiledPatterns is a set of patterns that is compiled at most once.
+type compiledPatterns struct {
+	once     sync.Once
+	patterns *Patterns
+	err      error
+}
+
+// lazyPatterns holds the compiled patterns of a single YangType.  It is
+// only a cache of the shared compiledPatterns so that the shared set is
+// looked up at most once per YangType.
+type lazyPatterns struct {
+	once sync.Once
+	cp   *compiledPatterns
+}
+
+// maxPatternSets is the number of pattern sets kept in patternCache.
+var maxPatternSets = 4096
+
+// patternCache contains the most recently used compiled pattern sets, keyed
+// by patternKey.  The elements of lru are *patternCacheEntry, the most
+// recently used first.  A type that already has its compiled patterns
+// keeps them when they are evicted, only new types no longer share them.
+var patternCache = struct {
+	mu  sync.Mutex
+	m   map[string]*list.Element
+	lru *list.List
+}{m: map[string]*list.Element{}, lru: list.New()}
+
+// A patternCacheEntry is an element of patternCache.lru.
+type patternCacheEntry struct {
+	key string
+	cp  *compiledPatterns
+}
+
+// patternKey returns the key in patternCache of the pattern set of y.
+func patternKey(y *YangType) string {
+	return strings.Join(y.Pattern, "\x00") + "\x01" + strings.Join(y.POSIXPattern, "\x00") +
+		"\x01" + strings.Join(y.InvertedPattern, "\x00")
+}
+
+// sharedPatterns returns the possibly not yet compiled shared pattern set
+// with the same patterns as y.
+func sharedPatterns(y *YangType) *compiledPatterns {
+	key := patternKey(y)
+	patternCache.mu.Lock()
+	defer patternCache.mu.Unlock()
+	if el := patternCache.m[key]; el != nil {
+		patternCache.lru.MoveToFront(el)
+		return el.Value.(*patternCacheEntry).cp
+	}
+	cp := &compiledPatterns{}
+	patternCache.m[key] = patternCache.lru.PushFront(&patternCacheEntry{key: key, cp: cp})
+	for patternCache.lru.Len() > maxPatternSets {
+		el := patternCache.lru.Back()
+		patternCache.lru.Remove(el)
+		delete(patternCache.m, el.Value.(*patternCacheEntry).key)
+	}
+	return cp
+}
+
+// compile compiles the patterns of y into cp, if not already done.
+func (cp *compiledPatterns) compile(y *YangType) (*Patterns, error) {
+	cp.once.Do(func() {
+		p := &Patterns{}
+		for _, s := range y.Pattern {
+			// XSD patterns are implicitly anchored at both ends.
+			re, err := regexp.Compile("^(?:" + s + ")$")
+			if err != nil {
+				cp.err = fmt.Errorf("bad pattern: %v", err)
+				return
+			}
+			p.XSD = append(p.XSD, re)
+		}
+		for _, s := range y.InvertedPattern {
+			re, err := regexp.Compile("^(?:" + s + ")$")
+			if err != nil {
+				cp.err = fmt.Errorf("bad pattern: %v", err)
+				return
+			}
+			p.Inverted = append(p.Inverted, re)
+		}
+		for _, s := range y.POSIXPattern {
+			re, err := regexp.CompilePOSIX(s)
+			if err != nil {
+				cp.err = fmt.Errorf("bad posix-pattern: %v", err)
+				return
+			}
+			p.POSIX = append(p.POSIX, re)
+		}
+		cp.patterns = p
+	})
+	return cp.patterns, cp.err
+}
+
+// CompiledPatterns returns the compiled forms of the patterns and POSIX
+// patterns of y.  The patterns are compiled on the first call and the
+// result is shared with all types that have the same patterns.
+// CompiledPatterns is safe to call from multiple goroutines.
+//
+// Not all XSD regular expressions can be expressed in Go, an error is
+// returned if any of the patterns cannot be compiled.
+func (y *YangType) CompiledPatterns() (*Patterns, error) {
+	if y.patterns == nil {
+		// y was not created by resolving a type.
+		return sharedPatterns(y).compile(y)
+	}
+	y.patterns.once.Do(func() {
+		y.patterns.cp = sharedPatterns(y)
+	})
+	return y.patterns.cp.compile(y)
+}
@@ -0,0 +1,147 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestCompiledPatterns(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"openconfig-extensions": `
+			module openconfig-extensions {
+				prefix "oc-ext";
+				namespace "urn:oc-ext";
+				extension posix-pattern { argument "pattern"; }
+			}
+		`,
+		"pattern-test": `
+			module pattern-test {
+				prefix "p";
+				namespace "urn:p";
+				import openconfig-extensions { prefix oc-ext; }
+				typedef lower {
+					type string {
+						pattern '[a-z]+';
+						oc-ext:posix-pattern '^[a-z]+$';
+					}
+				}
+				leaf a { type lower; }
+				leaf b { type lower; }
+				leaf c { type lower { pattern '[a-c]*'; } }
+				leaf d { type string; }
+				leaf e { type lower { pattern 'x.*' { modifier invert-match; } } }
+				leaf bad { type string { pattern '[a-z'; } }
+			}
+		`,
+	} {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatalf("cannot parse %s: %v", name, err)
+		}
+	}
+	// The bad pattern is not an error when processing, as not all XSD
+	// patterns can be expressed in Go.
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	e := ToEntry(ms.Modules["pattern-test"])
+
+	// Compile concurrently, all callers must see the same result.
+	var wg sync.WaitGroup
+	got := make([]*Patterns, 10)
+	for i := range got {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			leaf := "a"
+			if i%2 == 1 {
+				leaf = "b"
+			}
+			p, err := e.Dir[leaf].Type.CompiledPatterns()
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", leaf, err)
+			}
+			got[i] = p
+		}(i)
+	}
+	wg.Wait()
+	for i, p := range got {
+		if p != got[0] {
+			t.Errorf("#%d: leaves with the same patterns do not share compiled patterns", i)
+		}
+	}
+
+	for _, tt := range []struct {
+		leaf string
+		in   string
+		want bool
+	}{
+		{"a", "abc", true},
+		{"a", "abc1", false},
+		{"a", "", false},
+		{"c", "abc", true},
+		{"c", "abd", false},
+		{"d", "anything", true},
+		{"e", "abc", true},
+		{"e", "xyz", false},
+		{"e", "abx", true},
+	} {
+		p, err := e.Dir[tt.leaf].Type.CompiledPatterns()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.leaf, err)
+			continue
+		}
+		if got := p.MatchString(tt.in); got != tt.want {
+			t.Errorf("%s: MatchString(%q) got %v, want %v", tt.leaf, tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := e.Dir["bad"].Type.CompiledPatterns(); err == nil {
+		t.Errorf("bad: unexpectedly compiled bad pattern")
+	}
+}
+
+func TestPatternCacheBound(t *testing.T) {
+	defer func(n int) { maxPatternSets = n }(maxPatternSets)
+	maxPatternSets = 2
+
+	types := []*YangType{
+		{Kind: Ystring, Pattern: []string{"a"}},
+		{Kind: Ystring, Pattern: []string{"b"}},
+		{Kind: Ystring, Pattern: []string{"c"}},
+	}
+	first := sharedPatterns(types[0])
+	for _, y := range types {
+		if _, err := y.CompiledPatterns(); err != nil {
+			t.Fatalf("%v: unexpected error: %v", y.Pattern, err)
+		}
+	}
+	patternCache.mu.Lock()
+	n := len(patternCache.m)
+	patternCache.mu.Unlock()
+	if n > maxPatternSets {
+		t.Errorf("got %d cached pattern sets, want at most %d", n, maxPatternSets)
+	}
+	// The least recently used set was evicted and is compiled again.
+	if sharedPatterns(types[0]) == first {
+		t.Errorf("least recently used pattern set was not evicted")
+	}
+	// A recently used set is still shared.
+	if sharedPatterns(types[2]) != sharedPatterns(&YangType{Kind: Ystring, Pattern: []string{"c"}}) {
+		t.Errorf("recently used pattern set is not shared")
+	}
+}
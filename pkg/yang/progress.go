@@ -0,0 +1,79 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements reporting the progress of reading and processing
+// modules.
+
+// A Phase identifies the work being reported on by a Progress.
+type Phase int
+
+const (
+	// PhaseParse is reported as each module or submodule is parsed by
+	// Read or Parse.  The total is not known.
+	PhaseParse Phase = iota
+	// PhaseEntries is reported as the Entry tree of each module or
+	// submodule is built by Process.
+	PhaseEntries
+	// PhaseAugments is reported as the augments of each module or
+	// submodule are applied by Process.  Augments are applied in passes
+	// until no more can be applied, each pass is reported from the start.
+	PhaseAugments
+	// PhaseDeviations is reported as the deviations of each module or
+	// submodule are applied by Process.
+	PhaseDeviations
+)
+
+var phaseNames = map[Phase]string{
+	PhaseParse:      "parse",
+	PhaseEntries:    "entries",
+	PhaseAugments:   "augments",
+	PhaseDeviations: "deviations",
+}
+
+func (p Phase) String() string {
+	if n := phaseNames[p]; n != "" {
+		return n
+	}
+	return "unknown-phase"
+}
+
+// A Progress reports that the work of Phase on Module is done.  Done is
+// the number of modules for which the work of Phase is done so far, and
+// Total the number there are, or 0 if not known.
+type Progress struct {
+	Phase  Phase
+	Module string
+	Done   int
+	Total  int
+}
+
+// A ProgressFunc is called with the progress of reading and processing
+// modules.
+type ProgressFunc func(Progress)
+
+// SetProgressFunc sets the function called with the progress of reading and
+// processing the modules of ms.  It may be used to show a progress bar
+// when there are many modules.  A nil f stops reporting progress.
+func (ms *Modules) SetProgressFunc(f ProgressFunc) {
+	ms.progress = f
+}
+
+// reportProgress calls the progress function of ms, if any.
+func (ms *Modules) reportProgress(phase Phase, module string, done, total int) {
+	if ms.progress != nil {
+		ms.progress(Progress{Phase: phase, Module: module, Done: done, Total: total})
+	}
+}
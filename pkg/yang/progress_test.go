@@ -0,0 +1,64 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestProgress(t *testing.T) {
+	ms := NewModules()
+	var got []Progress
+	ms.SetProgressFunc(func(p Progress) { got = append(got, p) })
+	for name, text := range map[string]string{
+		"a": `module a { prefix "a"; namespace "urn:a"; import b { prefix "b"; } augment "/b:c" { leaf l { type string; } } }`,
+		"b": `module b { prefix "b"; namespace "urn:b"; container c; }`,
+	} {
+		if err := ms.Parse(text, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The order in which the modules are parsed is not known.
+	for i := range got {
+		got[i].Module = ""
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	want := []Progress{
+		{PhaseParse, "", 1, 0},
+		{PhaseParse, "", 2, 0},
+		{PhaseEntries, "a", 1, 2},
+		{PhaseEntries, "b", 2, 2},
+		{PhaseAugments, "a", 1, 2},
+		{PhaseAugments, "b", 2, 2},
+		{PhaseDeviations, "a", 1, 2},
+		{PhaseDeviations, "b", 2, 2},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want, +got):\n%s", diff)
+	}
+}
+
+func TestPhaseString(t *testing.T) {
+	if got, want := PhaseAugments.String(), "augments"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := Phase(42).String(), "unknown-phase"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
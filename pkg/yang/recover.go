@@ -0,0 +1,49 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the conversion of panics into errors.
+
+import "runtime/debug"
+
+// panicError returns the error reported for the recovered panic value r.
+// The stack of the panic is logged at LevelDebug to the Logger of o.
+func panicError(o *Options, r interface{}) error {
+	logf(o, LevelDebug, []Field{{"stack", string(debug.Stack())}}, "recovered panic: %v", r)
+	return errorf(nil, ErrInternal, "internal error: %v", r)
+}
+
+// recoverError is deferred by the public entry points of the package that
+// return an error.  It converts a panic into an error returned in *errp
+// unless the NoPanicRecovery option of o is set.
+func recoverError(o *Options, errp *error) {
+	if o.NoPanicRecovery {
+		return
+	}
+	if r := recover(); r != nil {
+		*errp = panicError(o, r)
+	}
+}
+
+// recoverErrors is like recoverError for entry points that return a list
+// of errors.  The panic's error is added to those already in *errsp.
+func recoverErrors(o *Options, errsp *Errors) {
+	if o.NoPanicRecovery {
+		return
+	}
+	if r := recover(); r != nil {
+		*errsp = append(*errsp, panicError(o, r))
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"testing"
+
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+// panicHook is a ProcessHook that panics.
+var panicHook = ProcessHookFunc(func(ProcessStage, *Modules) []error {
+	var m map[string]int
+	m["boom"]++
+	return nil
+})
+
+func TestRecoverProcess(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`module p { prefix "p"; namespace "urn:p"; }`, "p.yang"); err != nil {
+		t.Fatal(err)
+	}
+	ms.AddHook(panicHook)
+	errs := ms.Process()
+	if len(errs) != 1 {
+		t.Fatalf("got errors %v, want 1 error", errs)
+	}
+	if diff := errdiff.Substring(errs[0], "internal error: assignment to entry in nil map"); diff != "" {
+		t.Error(diff)
+	}
+	if got := ErrorCode(errs[0]); got != ErrInternal {
+		t.Errorf("got code %q, want %q", got, ErrInternal)
+	}
+}
+
+func TestNoPanicRecovery(t *testing.T) {
+	defer func(o Options) { ParseOptions = o }(ParseOptions)
+	ParseOptions.NoPanicRecovery = true
+
+	ms := NewModules()
+	if err := ms.Parse(`module p { prefix "p"; namespace "urn:p"; }`, "p.yang"); err != nil {
+		t.Fatal(err)
+	}
+	ms.AddHook(panicHook)
+	defer func() {
+		if recover() == nil {
+			t.Error("Process did not panic")
+		}
+	}()
+	ms.Process()
+}
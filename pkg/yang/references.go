@@ -0,0 +1,213 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the reverse reference index of a set of modules.
+
+import (
+	"sort"
+	"strings"
+)
+
+// ReferencesTo returns the nodes in the modules and submodules of ms that
+// refer to n:
+//
+//   - for a typedef, the types that use it, including the types of other
+//     typedefs
+//   - for an identity, the identityref types that use it as their base and
+//     the identities derived from it
+//   - for a leaf or leaf-list, the leaves and leaf-lists whose leafref
+//     paths point to it
+//
+// Only direct references are returned, e.g., the leaves using a typedef
+// derived from a typedef refer to the derived typedef.  The nodes are
+// ordered by the full name of their module and then by their position in
+// the module.  The modules of ms must have been processed.  The index is
+// built the first time ReferencesTo is called and is rebuilt after ms is
+// processed again.
+func (ms *Modules) ReferencesTo(n Node) []Node {
+	if ms.references == nil {
+		ms.references = ms.buildReferences()
+	}
+	return ms.references[n]
+}
+
+// buildReferences returns the reverse reference index of ms.
+func (ms *Modules) buildReferences() map[Node][]Node {
+	refs := map[Node][]Node{}
+	seen := map[[2]Node]bool{}
+	add := func(to, from Node) {
+		if to == nil || seen[[2]Node{to, from}] {
+			return
+		}
+		seen[[2]Node{to, from}] = true
+		refs[to] = append(refs[to], from)
+	}
+	mods := ms.sortedModules()
+	for _, m := range mods {
+		walkNodes(m, func(n Node) {
+			switch n := n.(type) {
+			case *Type:
+				y := n.YangType
+				if y == nil {
+					return
+				}
+				if y.Base != nil {
+					if td, ok := y.Base.Parent.(*Typedef); ok {
+						add(td, n)
+					}
+				}
+				if n.IdentityBase != nil && y.IdentityBase != nil {
+					add(y.IdentityBase, n)
+				}
+			case *Identity:
+				for _, b := range n.Base {
+					if id, errs := RootNode(n).findIdentityBase(b.Name); len(errs) == 0 {
+						add(id.Identity, n)
+					}
+				}
+			}
+		})
+	}
+	for _, m := range mods {
+		if m.Kind() != "module" {
+			continue
+		}
+		walkEntries(ToEntry(m), func(e *Entry) bool {
+			if e.Node == nil || !(e.IsLeaf() || e.IsLeafList()) {
+				return true
+			}
+			for _, p := range leafrefPaths(e.Type) {
+				if te := leafrefTarget(e, p); te != nil {
+					add(te.Node, e.Node)
+				}
+			}
+			return true
+		})
+	}
+	for _, ns := range refs {
+		sort.SliceStable(ns, func(i, j int) bool {
+			mi, mj := RootNode(ns[i]).FullName(), RootNode(ns[j]).FullName()
+			if mi != mj {
+				return mi < mj
+			}
+			return before(ns[i], ns[j])
+		})
+	}
+	return refs
+}
+
+// leafrefPaths returns the paths of y if y is a leafref, or of the
+// leafref members of y if y is a union.
+func leafrefPaths(y *YangType) []string {
+	if y == nil {
+		return nil
+	}
+	switch y.Kind {
+	case Yleafref:
+		return []string{y.Path}
+	case Yunion:
+		var paths []string
+		for _, t := range y.Type {
+			paths = append(paths, leafrefPaths(t)...)
+		}
+		return paths
+	}
+	return nil
+}
+
+// LeafrefTarget returns the leaf or leaf-list that the path of the leafref
+// type y, used by e, refers to.  Nil is returned if y is not a leafref or
+// the target cannot be found.  Predicates in the path are ignored.
+func LeafrefTarget(e *Entry, y *YangType) *Entry {
+	if e == nil || y == nil || y.Kind != Yleafref {
+		return nil
+	}
+	return leafrefTarget(e, y.Path)
+}
+
+// leafrefTarget returns the entry the leafref path p, used by e, points to,
+// or nil if it cannot be found.  Predicates in p are ignored.
+func leafrefTarget(e *Entry, p string) *Entry {
+	parts := strings.Split(stripPredicates(strings.TrimSpace(p)), "/")
+	if parts[0] == "" {
+		if len(parts) < 2 {
+			return nil
+		}
+		m, _, err := ResolvePrefixedName(e.Node, parts[1])
+		if err != nil {
+			return nil
+		}
+		e = ToEntry(m)
+		parts = parts[1:]
+	}
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		switch part {
+		case ".":
+			continue
+		case "..":
+			e = e.dataParent()
+		default:
+			_, name := getPrefix(part)
+			e = dataChild(e, name)
+		}
+		if e == nil {
+			return nil
+		}
+	}
+	return e
+}
+
+// dataChild returns the data node child of e named name, looking through
+// any choice and case statements, or nil.
+func dataChild(e *Entry, name string) *Entry {
+	if e.RPC != nil {
+		switch name {
+		case "input":
+			return e.RPC.Input
+		case "output":
+			return e.RPC.Output
+		}
+	}
+	if ce := e.Dir[name]; ce != nil && !ce.IsChoice() && !ce.IsCase() {
+		return ce
+	}
+	for _, ce := range e.Dir {
+		if ce.IsChoice() || ce.IsCase() {
+			if de := dataChild(ce, name); de != nil {
+				return de
+			}
+		}
+	}
+	return nil
+}
+
+// stripPredicates returns p without the bracketed predicates it contains.
+func stripPredicates(p string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range p {
+		switch {
+		case r == '[':
+			depth++
+		case r == ']' && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
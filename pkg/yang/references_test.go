@@ -0,0 +1,121 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReferencesTo(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"ref": `module ref {
+  prefix "r";
+  namespace "urn:ref";
+  identity animal;
+  identity dog { base animal; }
+  typedef name { type string; }
+  typedef short-name { type name { length "1..8"; } }
+  grouping g { leaf gl { type leafref { path "../key"; } } }
+  container c {
+    list l {
+      key "key";
+      leaf key { type name; }
+      leaf kind { type identityref { base animal; } }
+      uses g;
+      choice ch { leaf in-choice { type leafref { path "../key"; } } }
+    }
+    leaf abs { type leafref { path "/r:c/r:l[r:key = current()/../sel]/r:key"; } }
+    leaf sel { type short-name; }
+  }
+}`,
+		"other": `module other {
+  prefix "o";
+  namespace "urn:other";
+  import ref { prefix "x"; }
+  identity cat { base x:animal; }
+  leaf any { type union { type int8; type leafref { path "/x:c/x:l/x:key"; } } }
+  leaf n { type x:name; }
+}`,
+	} {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	m := ms.Modules["ref"]
+	refs := func(n Node) []string {
+		var s []string
+		for _, r := range ms.ReferencesTo(n) {
+			s = append(s, Source(r)+" "+r.Kind()+" "+r.NName())
+		}
+		return s
+	}
+	key := ToEntry(m).Find("/c/l/key").Node
+	for _, tt := range []struct {
+		desc string
+		n    Node
+		want []string
+	}{{
+		desc: "typedef",
+		n:    m.Typedef[0],
+		want: []string{
+			"other.yang:7:12 type x:name",
+			"ref.yang:7:24 type name",
+			"ref.yang:12:18 type name",
+		},
+	}, {
+		desc: "derived typedef",
+		n:    m.Typedef[1],
+		want: []string{"ref.yang:18:16 type short-name"},
+	}, {
+		desc: "identity",
+		n:    m.Identity[0],
+		want: []string{
+			"other.yang:5:3 identity cat",
+			"ref.yang:5:3 identity dog",
+			"ref.yang:13:19 type identityref",
+		},
+	}, {
+		desc: "leafref target",
+		n:    key,
+		want: []string{
+			"other.yang:6:3 leaf any",
+			"ref.yang:8:16 leaf gl",
+			"ref.yang:15:19 leaf in-choice",
+			"ref.yang:17:5 leaf abs",
+		},
+	}, {
+		desc: "unreferenced",
+		n:    m.Identity[1],
+	}} {
+		if diff := cmp.Diff(tt.want, refs(tt.n)); diff != "" {
+			t.Errorf("%s (-want, +got):\n%s", tt.desc, diff)
+		}
+	}
+	for _, p := range []string{"/c/abs", "/c/l/gl", "/c/l/ch/in-choice/in-choice"} {
+		e := ToEntry(m).Find(p)
+		if got := LeafrefTarget(e, e.Type); got == nil || got.Node != key {
+			t.Errorf("LeafrefTarget(%s) = %v, want %s", p, got, key.NName())
+		}
+	}
+	if got := LeafrefTarget(ToEntry(m).Find("/c/sel"), ToEntry(m).Find("/c/sel").Type); got != nil {
+		t.Errorf("LeafrefTarget(/c/sel) = %s, want nil", got.Path())
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the resolution of modules that are not found on
+// Path, including fetching them over HTTP.
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A ModuleResolver finds the source of modules that are not found on Path.
+type ModuleResolver interface {
+	// Resolve returns the name and contents of the .yang or .yin
+	// source associated with name, which is passed to Read, or names
+	// an imported or included module.  As with Read, name is either a
+	// file name or a module name, optionally followed by @revision.
+	// Resolve returns an error with the code ErrFileNotFound if it
+	// does not have the source.
+	Resolve(ctx context.Context, name string) (string, string, error)
+}
+
+// AddResolver adds r to the resolvers of ms.  When Read, or the processing
+// of an import or include statement, does not find a module on Path, the
+// resolvers are consulted, in the order they were added, before falling
+// back to the embedded modules.
+func (ms *Modules) AddResolver(r ModuleResolver) {
+	ms.resolvers = append(ms.resolvers, r)
+}
+
+// resolve returns the name and contents of the source associated with name
+// from the first resolver of ms that has it.  If no resolver has it, err,
+// the error from looking for name on Path, is returned unless a resolver
+// failed for another reason.
+func (ms *Modules) resolve(ctx context.Context, name string, err error) (string, string, error) {
+	ctx = context.WithValue(ctx, fileSizeKey{}, ms.opts.MaxFileSize)
+	for _, r := range ms.resolvers {
+		fname, data, rerr := r.Resolve(ctx, name)
+		if rerr == nil {
+			return fname, data, nil
+		}
+		if ErrorCode(rerr) != ErrFileNotFound {
+			err = rerr
+		}
+	}
+	return "", "", err
+}
+
+// fileSizeKey is the key of the context value that is the MaxFileSize
+// option of the Modules consulting a resolver.
+type fileSizeKey struct{}
+
+// maxFileSize returns the maximum size of the sources read by a resolver
+// called with ctx.  It is the MaxFileSize option of the Modules consulting
+// the resolver, or ParseOptions.MaxFileSize if there is none.
+func maxFileSize(ctx context.Context) int {
+	if max, ok := ctx.Value(fileSizeKey{}).(int); ok {
+		return max
+	}
+	return ParseOptions.MaxFileSize
+}
+
+// An HTTPResolver is a ModuleResolver that fetches modules over HTTP or
+// HTTPS.  Only module names, optionally with a revision, are resolved, not
+// file names with a directory.
+type HTTPResolver struct {
+	// URL is the template of the URL of a module.  In it, {file} is
+	// replaced by the name of the module's file, e.g.,
+	// "ietf-interfaces.yang" or "ietf-interfaces@2018-02-20.yang", and
+	// {module} by the module's name, e.g.,
+	// "https://raw.githubusercontent.com/openconfig/public/master/release/models/{file}".
+	URL string
+
+	// Client is used to fetch modules.  If nil, http.DefaultClient is
+	// used.
+	Client *http.Client
+
+	// CacheDir, if not empty, is the directory that fetched modules are
+	// saved in.  A module in CacheDir is read from it rather than being
+	// fetched again, so CacheDir must be emptied to fetch newer versions
+	// of modules named without a revision.
+	CacheDir string
+}
+
+// Resolve fetches the source of the module name from r.URL, see
+// ModuleResolver.
+func (r *HTTPResolver) Resolve(ctx context.Context, name string) (string, string, error) {
+	if strings.Contains(name, "/") {
+		return "", "", errorf(nil, ErrFileNotFound, "no such file: %s", name)
+	}
+	file := name
+	if ext := filepath.Ext(name); ext != ".yang" && ext != ".yin" {
+		file += ".yang"
+	}
+	module := strings.TrimSuffix(file, filepath.Ext(file))
+	if i := strings.Index(module, "@"); i >= 0 {
+		module = module[:i]
+	}
+	url := strings.NewReplacer("{file}", file, "{module}", module).Replace(r.URL)
+
+	var cache string
+	if r.CacheDir != "" {
+		cache = filepath.Join(r.CacheDir, file)
+		if data, err := readFile(cache, maxFileSize(ctx)); err == nil {
+			return cache, string(data), nil
+		}
+	}
+	data, err := r.fetch(ctx, url)
+	if err != nil {
+		return "", "", err
+	}
+	if cache != "" {
+		if err := writeCache(cache, data); err != nil {
+			return "", "", err
+		}
+		return cache, string(data), nil
+	}
+	return url, string(data), nil
+}
+
+// fetch returns the contents of url.
+func (r *HTTPResolver) fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, errorf(nil, ErrFileNotFound, "no such file: %s", url)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	body := io.Reader(resp.Body)
+	max := maxFileSize(ctx)
+	if max > 0 {
+		body = io.LimitReader(body, int64(max)+1)
+	}
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", url, err)
+	}
+	if max > 0 && len(data) > max {
+		return nil, &fileSizeError{name: url, max: max}
+	}
+	return data, nil
+}
+
+// writeCache atomically writes data to the file name, creating its
+// directory if needed.
+func writeCache(name string, data []byte) error {
+	dir := filepath.Dir(name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	fp, err := ioutil.TempFile(dir, "."+filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = fp.Write(data)
+	if err == nil {
+		err = fp.Chmod(0644)
+	}
+	if cerr := fp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(fp.Name(), name)
+	}
+	if err != nil {
+		os.Remove(fp.Name())
+	}
+	return err
+}
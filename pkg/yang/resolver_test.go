@@ -0,0 +1,122 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+func TestHTTPResolver(t *testing.T) {
+	sources := map[string]string{
+		"/models/remote-b.yang": `module remote-b {
+  namespace "urn:remote-b";
+  prefix "b";
+  typedef t { type int8; }
+}`,
+	}
+	var fetched []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched = append(fetched, r.URL.Path)
+		if r.URL.Path == "/models/broken.yang" {
+			http.Error(w, "broken", http.StatusInternalServerError)
+			return
+		}
+		src, ok := sources[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, src)
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "resolver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	const remoteA = `module remote-a {
+  namespace "urn:remote-a";
+  prefix "a";
+  import remote-b { prefix "b"; }
+  leaf l { type b:t; }
+}`
+	read := func() {
+		t.Helper()
+		ms := NewModules()
+		ms.AddResolver(&HTTPResolver{URL: srv.URL + "/models/{file}", CacheDir: dir})
+		if err := ms.Parse(remoteA, "remote-a.yang"); err != nil {
+			t.Fatal(err)
+		}
+		if errs := ms.Process(); len(errs) > 0 {
+			t.Fatal(errs)
+		}
+		if ms.Modules["remote-b"] == nil {
+			t.Fatal("remote-b was not read")
+		}
+	}
+	read()
+	if _, err := os.Stat(filepath.Join(dir, "remote-b.yang")); err != nil {
+		t.Errorf("remote-b was not cached: %v", err)
+	}
+	// The second time remote-b is read from the cache.
+	read()
+	if want := []string{"/models/remote-b.yang"}; len(fetched) != 1 || fetched[0] != want[0] {
+		t.Errorf("fetched %v, want %v", fetched, want)
+	}
+
+	r := &HTTPResolver{URL: srv.URL + "/models/{module}.yang"}
+	for _, tt := range []struct {
+		name     string
+		want     string
+		wantErr  string
+		wantCode Code
+	}{
+		{name: "remote-b", want: srv.URL + "/models/remote-b.yang"},
+		{name: "remote-b.yang", want: srv.URL + "/models/remote-b.yang"},
+		{name: "remote-b@2020-01-01", want: srv.URL + "/models/remote-b.yang"},
+		{name: "remote-c", wantErr: "no such file: " + srv.URL + "/models/remote-c.yang", wantCode: ErrFileNotFound},
+		{name: "dir/remote-b.yang", wantErr: "no such file: dir/remote-b.yang", wantCode: ErrFileNotFound},
+		{name: "broken", wantErr: "500 Internal Server Error"},
+	} {
+		got, _, err := r.Resolve(context.Background(), tt.name)
+		if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+			t.Errorf("Resolve(%q): %s", tt.name, diff)
+		}
+		if code := ErrorCode(err); code != tt.wantCode {
+			t.Errorf("Resolve(%q): got code %q, want %q", tt.name, code, tt.wantCode)
+		}
+		if got != tt.want {
+			t.Errorf("Resolve(%q): got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	// An error other than a missing file is reported by Read.
+	ms := NewModules()
+	ms.AddResolver(r)
+	if diff := errdiff.Substring(ms.Read("broken"), "500 Internal Server Error"); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements writing diagnostics as a SARIF log, the format
+// read by code scanning services such as GitHub's.  See
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// The following types are the subset of the SARIF object model written by
+// WriteDiagnosticsSARIF.
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules,omitempty"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId,omitempty"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+// WriteDiagnosticsSARIF writes the structured form of errs to w as a SARIF
+// 2.1.0 log with a single run of goyang.  Each diagnostic is a result whose
+// rule is the code of the diagnostic.
+func WriteDiagnosticsSARIF(w io.Writer, errs []error) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "goyang",
+			InformationURI: "https://github.com/openconfig/goyang",
+		}},
+		Results: []sarifResult{},
+	}
+	codes := map[Code]bool{}
+	for _, d := range Diagnostics(errs) {
+		r := sarifResult{
+			RuleID:  string(d.Code),
+			Level:   sarifLevel(d.Severity),
+			Message: sarifMessage{Text: d.Message},
+		}
+		var loc sarifLocation
+		if d.File != "" {
+			loc.PhysicalLocation = &sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(d.File)},
+			}
+			if d.Line > 0 {
+				loc.PhysicalLocation.Region = &sarifRegion{StartLine: d.Line, StartColumn: d.Column}
+			}
+		}
+		if d.Path != "" {
+			loc.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: d.Path}}
+		}
+		if loc.PhysicalLocation != nil || loc.LogicalLocations != nil {
+			r.Locations = []sarifLocation{loc}
+		}
+		run.Results = append(run.Results, r)
+		if d.Code != "" {
+			codes[d.Code] = true
+		}
+	}
+	for c := range codes {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: string(c)})
+	}
+	sort.Slice(run.Tool.Driver.Rules, func(i, j int) bool {
+		return run.Tool.Driver.Rules[i].ID < run.Tool.Driver.Rules[j].ID
+	})
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []sarifRun{run},
+	})
+}
+
+// sarifLevel returns the SARIF level of a diagnostic with severity.
+func sarifLevel(severity string) string {
+	if severity == SeverityWarning.String() {
+		return "warning"
+	}
+	return "error"
+}
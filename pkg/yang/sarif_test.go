@@ -0,0 +1,143 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteDiagnosticsSARIF(t *testing.T) {
+	errs := []error{
+		&Error{Code: ErrUnknownType, File: "dir/a.yang", Line: 3, Col: 7, Path: "module a / leaf l / type t", Msg: "unknown type: a:t"},
+		&Error{Severity: SeverityWarning, Code: WarnUnusedImport, File: "a.yang", Line: 2, Col: 1, Msg: "prefix b of imported module b is not used"},
+		&Error{Code: ErrUnknownType, File: "b.yang", Line: 4, Col: 1, Msg: "unknown type: b:t"},
+		errors.New("no location"),
+	}
+	want := `{
+  "version": "2.1.0",
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "goyang",
+          "informationUri": "https://github.com/openconfig/goyang",
+          "rules": [
+            {
+              "id": "unknown-type"
+            },
+            {
+              "id": "unused-import"
+            }
+          ]
+        }
+      },
+      "results": [
+        {
+          "ruleId": "unknown-type",
+          "level": "error",
+          "message": {
+            "text": "unknown type: a:t"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "dir/a.yang"
+                },
+                "region": {
+                  "startLine": 3,
+                  "startColumn": 7
+                }
+              },
+              "logicalLocations": [
+                {
+                  "fullyQualifiedName": "module a / leaf l / type t"
+                }
+              ]
+            }
+          ]
+        },
+        {
+          "ruleId": "unused-import",
+          "level": "warning",
+          "message": {
+            "text": "prefix b of imported module b is not used"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "a.yang"
+                },
+                "region": {
+                  "startLine": 2,
+                  "startColumn": 1
+                }
+              }
+            }
+          ]
+        },
+        {
+          "ruleId": "unknown-type",
+          "level": "error",
+          "message": {
+            "text": "unknown type: b:t"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "b.yang"
+                },
+                "region": {
+                  "startLine": 4,
+                  "startColumn": 1
+                }
+              }
+            }
+          ]
+        },
+        {
+          "level": "error",
+          "message": {
+            "text": "no location"
+          }
+        }
+      ]
+    }
+  ]
+}
+`
+	var buf bytes.Buffer
+	if err := WriteDiagnosticsSARIF(&buf, errs); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("WriteDiagnosticsSARIF (-want, +got):\n%s", diff)
+	}
+
+	buf.Reset()
+	if err := WriteDiagnosticsSARIF(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"results": []`)) {
+		t.Errorf("WriteDiagnosticsSARIF(nil): got %s, want an empty list of results", &buf)
+	}
+}
@@ -0,0 +1,917 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements saving the resolved Entry trees of a Modules to a
+// compact binary (gob) form and loading them back.  The AST is not saved.
+// Loaded entries reference a minimal AST: each module is represented by a
+// *Module holding its name, prefix, namespace and revisions, and every other
+// Node is the *Statement the Entry was originally built from (keyword,
+// argument, and location).
+//
+// Types are saved in a table so YangTypes shared between entries in the
+// original tree are also shared in the loaded tree.
+//
+// The uses, augment and deviation statements referenced by an Entry, e.g.,
+// those returned by UsedAt, AugmentedBy and DeviatedBy, are loaded as a
+// *Uses, *Augment, *Deviation or *Deviate holding only the name, statement
+// and module of the original.  The Extra fields of entries, which hold AST
+// nodes, and the Errors and Warnings of entries are not saved.
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/openconfig/goyang/pkg/xpath"
+)
+
+// saveVersion is the version of the format written by Save.  Load rejects
+// data written with any other version.  It must be incremented whenever the
+// saved form changes.
+const saveVersion = 2
+
+type savedSchema struct {
+	Version    int
+	Types      []*savedType
+	Identities []*savedIdentity
+	Modules    []*savedEntry
+}
+
+type savedModule struct {
+	Name      string
+	Prefix    string
+	Namespace string
+	Revisions []string
+
+	OpenConfigVersion   string
+	CatalogOrganization string
+	RegexpPOSIX         bool
+	SMIv2Aliases        map[string]string
+}
+
+type savedStatement struct {
+	Keyword     string
+	HasArgument bool
+	Argument    string
+	File        string
+	Line, Col   int
+	Span        Span
+	KeywordSpan Span
+	ArgSpan     Span
+	Statements  []*savedStatement
+}
+
+// A savedNode is a uses, augment, deviation or deviate statement
+// referenced by an entry.
+type savedNode struct {
+	Module    string // the name of the module the statement is in
+	Statement *savedStatement
+}
+
+type savedUses struct {
+	Uses     *savedNode
+	Grouping *savedEntry
+}
+
+type savedDeviation struct {
+	Type         deviationType
+	DeviatedPath string
+	Entry        *savedEntry
+}
+
+type savedAppliedDeviation struct {
+	Module    string
+	Deviation *savedNode
+	Deviate   *savedNode
+	Type      deviationType
+	Changes   []string
+}
+
+type savedCondition struct {
+	Keyword      string
+	Expr         string
+	Description  string
+	Reference    string
+	ErrorMessage string
+	ErrorAppTag  string
+	Statement    *savedStatement
+}
+
+type savedIdentity struct {
+	Module string // the name of the defining module
+	Name   string
+	Values []int // indices into savedSchema.Identities
+}
+
+type savedEnum struct {
+	Bits   bool
+	Names  []string
+	Values []int64
+}
+
+type savedType struct {
+	Name             string
+	Kind             TypeKind
+	IdentityBase     int // index+1 into savedSchema.Identities, 0 for none
+	Root             int // index into savedSchema.Types
+	Bit              *savedEnum
+	Enum             *savedEnum
+	Units            string
+	Default          string
+	FractionDigits   int
+	Length           YangRange
+	OptionalInstance bool
+	Path             string
+	Pattern          []string
+	POSIXPattern     []string
+	InvertedPattern  []string
+	Range            YangRange
+	Type             []int // indices into savedSchema.Types
+}
+
+type savedEntry struct {
+	Module      *savedModule // only set for module entries
+	Statement   *savedStatement
+	Name        string
+	Description string
+	Default     string
+	Units       string
+	Kind        EntryKind
+	Config      TriState
+	Prefix      string
+	Mandatory   TriState
+	Namespace   string
+	IsDir       bool
+	Dir         []*savedEntry
+	Key         string
+	Type        int // index+1 into savedSchema.Types, 0 for none
+	Exts        []*savedStatement
+	ListAttr    *savedListAttr
+	Input       *savedEntry
+	Output      *savedEntry
+	IsRPC       bool
+	Identities  []int // indices into savedSchema.Identities
+
+	Augments       []*savedEntry
+	Augmented      []*savedEntry
+	Uses           []*savedUses
+	Deviations     []*savedDeviation
+	Deviate        map[deviationType][]*savedEntry
+	HasMinElements bool // deviatePresence
+	HasMaxElements bool
+	Structures     map[string]*savedEntry
+	When           *savedCondition
+	Must           []*savedCondition
+	Annotation     map[string]interface{}
+	UsedAt         []*savedNode
+	AugmentedBy    *savedNode
+	DeviatedBy     []*savedAppliedDeviation
+
+	TelemetryAtomic  bool
+	Operational      bool
+	DefaultDenyWrite bool
+	DefaultDenyAll   bool
+	SMIv2            *SMIv2
+}
+
+type savedListAttr struct {
+	MinElements uint64
+	MaxElements uint64
+	OrderedBy   string
+}
+
+// A saver holds the state needed while converting Entry trees into their
+// saved form.
+type saver struct {
+	s          savedSchema
+	types      map[*YangType]int
+	identities map[*Identity]int
+}
+
+// Save writes the resolved Entry trees of all the modules in ms to w.  Process
+// must have been called without errors prior to calling Save.  Use Load to
+// read them back.  The values in the Annotation fields of entries must be
+// encodable by encoding/gob; values that are not of a basic type must be
+// registered with gob.Register.
+func (ms *Modules) Save(w io.Writer) error {
+	sv := &saver{
+		s:          savedSchema{Version: saveVersion},
+		types:      map[*YangType]int{},
+		identities: map[*Identity]int{},
+	}
+	var names []string
+	for _, m := range ms.Modules {
+		// ms.Modules contains both name and name@revision keys.
+		if ms.Modules[m.Name] == m {
+			names = append(names, m.Name)
+		}
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		e := ToEntry(ms.Modules[n])
+		if errs := e.GetErrors(); len(errs) > 0 {
+			return fmt.Errorf("cannot save module %s: %v", n, errs[0])
+		}
+		sv.s.Modules = append(sv.s.Modules, sv.entry(e))
+	}
+	return gob.NewEncoder(w).Encode(&sv.s)
+}
+
+// saveStatement returns the saved form of s.  The substatements of s are only
+// saved if deep is true.
+func saveStatement(s *Statement, deep bool) *savedStatement {
+	if s == nil {
+		return nil
+	}
+	ss := &savedStatement{
+		Keyword:     s.Keyword,
+		HasArgument: s.HasArgument,
+		Argument:    s.Argument,
+		File:        s.file,
+		Line:        s.line,
+		Col:         s.col,
+		Span:        s.span,
+		KeywordSpan: s.keywordSpan,
+		ArgSpan:     s.argSpan,
+	}
+	if !deep {
+		return ss
+	}
+	for _, sub := range s.statements {
+		ss.Statements = append(ss.Statements, saveStatement(sub, true))
+	}
+	return ss
+}
+
+// saveNode returns the saved form of the uses, augment, deviation or
+// deviate statement n.
+func saveNode(n Node) *savedNode {
+	sn := &savedNode{Statement: saveStatement(n.Statement(), false)}
+	if m := n.ParentModule(); m != nil {
+		sn.Module = m.Name
+	}
+	return sn
+}
+
+// saveCondition returns the saved form of c.
+func saveCondition(c *Condition) *savedCondition {
+	if c == nil {
+		return nil
+	}
+	sc := &savedCondition{
+		Keyword:      c.Keyword,
+		Expr:         c.Expr,
+		Description:  c.Description,
+		Reference:    c.Reference,
+		ErrorMessage: c.ErrorMessage,
+		ErrorAppTag:  c.ErrorAppTag,
+	}
+	if c.Node != nil {
+		sc.Statement = saveStatement(c.Node.Statement(), false)
+	}
+	return sc
+}
+
+func saveEnum(e *EnumType, bits bool) *savedEnum {
+	if e == nil {
+		return nil
+	}
+	se := &savedEnum{Bits: bits}
+	for _, n := range e.Names() {
+		se.Names = append(se.Names, n)
+		se.Values = append(se.Values, e.Value(n))
+	}
+	return se
+}
+
+// identity returns the index of i in the saved identity table, adding i, and
+// all the identities derived from it, if needed.
+func (sv *saver) identity(i *Identity) int {
+	if x, ok := sv.identities[i]; ok {
+		return x
+	}
+	x := len(sv.s.Identities)
+	si := &savedIdentity{Name: i.Name}
+	if m := RootNode(i); m != nil {
+		si.Module = m.Name
+		if m.BelongsTo != nil {
+			si.Module = m.BelongsTo.Name
+		}
+	}
+	sv.identities[i] = x
+	sv.s.Identities = append(sv.s.Identities, si)
+	for _, v := range i.Values {
+		si.Values = append(si.Values, sv.identity(v))
+	}
+	return x
+}
+
+// typ returns the index of y in the saved type table, adding y if needed.
+func (sv *saver) typ(y *YangType) int {
+	if x, ok := sv.types[y]; ok {
+		return x
+	}
+	x := len(sv.s.Types)
+	st := &savedType{
+		Name:             y.Name,
+		Kind:             y.Kind,
+		Root:             x,
+		Bit:              saveEnum(y.Bit, true),
+		Enum:             saveEnum(y.Enum, false),
+		Units:            y.Units,
+		Default:          y.Default,
+		FractionDigits:   y.FractionDigits,
+		Length:           y.Length,
+		OptionalInstance: y.OptionalInstance,
+		Path:             y.Path,
+		Pattern:          y.Pattern,
+		POSIXPattern:     y.POSIXPattern,
+		InvertedPattern:  y.InvertedPattern,
+		Range:            y.Range,
+	}
+	sv.types[y] = x
+	sv.s.Types = append(sv.s.Types, st)
+	if y.IdentityBase != nil {
+		st.IdentityBase = sv.identity(y.IdentityBase) + 1
+	}
+	if y.Root != nil && y.Root != y {
+		st.Root = sv.typ(y.Root)
+	}
+	for _, t := range y.Type {
+		st.Type = append(st.Type, sv.typ(t))
+	}
+	return x
+}
+
+func (sv *saver) entry(e *Entry) *savedEntry {
+	if e == nil {
+		return nil
+	}
+	se := &savedEntry{
+		Name:        e.Name,
+		Description: e.Description,
+		Default:     e.Default,
+		Units:       e.Units,
+		Kind:        e.Kind,
+		Config:      e.Config,
+		Mandatory:   e.Mandatory,
+		IsDir:       e.Dir != nil,
+		Key:         e.Key,
+
+		HasMinElements: e.deviatePresence.hasMinElements,
+		HasMaxElements: e.deviatePresence.hasMaxElements,
+		When:           saveCondition(e.When),
+		Annotation:     e.Annotation,
+
+		TelemetryAtomic:  e.TelemetryAtomic,
+		Operational:      e.Operational,
+		DefaultDenyWrite: e.DefaultDenyWrite,
+		DefaultDenyAll:   e.DefaultDenyAll,
+		SMIv2:            e.SMIv2,
+	}
+	if m, ok := e.Node.(*Module); ok && e.Parent == nil {
+		se.Module = &savedModule{
+			Name:      m.Name,
+			Prefix:    m.GetPrefix(),
+			Namespace: m.Namespace.asString(),
+
+			OpenConfigVersion:   m.OpenConfigVersion,
+			CatalogOrganization: m.CatalogOrganization,
+			RegexpPOSIX:         m.RegexpPOSIX,
+			SMIv2Aliases:        m.SMIv2Aliases,
+		}
+		for _, r := range m.Revision {
+			se.Module.Revisions = append(se.Module.Revisions, r.Name)
+		}
+	}
+	if e.Node != nil {
+		se.Statement = saveStatement(e.Node.Statement(), false)
+	}
+	if e.Prefix != nil {
+		se.Prefix = e.Prefix.Name
+	}
+	if e.namespace != nil {
+		se.Namespace = e.namespace.Name
+	}
+	if e.Type != nil {
+		se.Type = sv.typ(e.Type) + 1
+	}
+	for _, s := range e.Exts {
+		se.Exts = append(se.Exts, saveStatement(s, true))
+	}
+	if la := e.ListAttr; la != nil {
+		se.ListAttr = &savedListAttr{
+			MinElements: la.MinElements,
+			MaxElements: la.MaxElements,
+			OrderedBy:   la.OrderedBy.asString(),
+		}
+	}
+	if e.RPC != nil {
+		se.IsRPC = true
+		se.Input = sv.entry(e.RPC.Input)
+		se.Output = sv.entry(e.RPC.Output)
+	}
+	for _, i := range e.Identities {
+		se.Identities = append(se.Identities, sv.identity(i))
+	}
+	for _, c := range e.Must {
+		se.Must = append(se.Must, saveCondition(c))
+	}
+	for _, a := range e.Augments {
+		se.Augments = append(se.Augments, sv.entry(a))
+	}
+	for _, a := range e.Augmented {
+		se.Augmented = append(se.Augmented, sv.entry(a))
+	}
+	for _, u := range e.Uses {
+		su := &savedUses{Grouping: sv.entry(u.Grouping)}
+		if u.Uses != nil {
+			su.Uses = saveNode(u.Uses)
+		}
+		se.Uses = append(se.Uses, su)
+	}
+	for _, d := range e.Deviations {
+		se.Deviations = append(se.Deviations, &savedDeviation{
+			Type:         d.Type,
+			DeviatedPath: d.DeviatedPath,
+			Entry:        sv.entry(d.Entry),
+		})
+	}
+	for dt, des := range e.Deviate {
+		if se.Deviate == nil {
+			se.Deviate = map[deviationType][]*savedEntry{}
+		}
+		for _, de := range des {
+			se.Deviate[dt] = append(se.Deviate[dt], sv.entry(de))
+		}
+	}
+	for k, s := range e.Structures {
+		if se.Structures == nil {
+			se.Structures = map[string]*savedEntry{}
+		}
+		se.Structures[k] = sv.entry(s)
+	}
+	for _, u := range e.usedAt {
+		se.UsedAt = append(se.UsedAt, saveNode(u))
+	}
+	if e.augmentedBy != nil {
+		se.AugmentedBy = saveNode(e.augmentedBy)
+	}
+	for _, ad := range e.deviatedBy {
+		sd := &savedAppliedDeviation{Type: ad.Type, Changes: ad.Changes}
+		if ad.Module != nil {
+			sd.Module = ad.Module.Name
+		}
+		if ad.Deviation != nil {
+			sd.Deviation = saveNode(ad.Deviation)
+		}
+		if ad.Deviate != nil {
+			sd.Deviate = saveNode(ad.Deviate)
+		}
+		se.DeviatedBy = append(se.DeviatedBy, sd)
+	}
+	var names []string
+	for k := range e.Dir {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		se.Dir = append(se.Dir, sv.entry(e.Dir[k]))
+	}
+	return se
+}
+
+// A loader holds the state needed while rebuilding Entry trees from their
+// saved form.
+type loader struct {
+	s          *savedSchema
+	ms         *Modules
+	types      []*YangType
+	identities []*Identity
+}
+
+// Load reads Entry trees previously written by Modules.Save from r.  The
+// trees are returned in a map keyed by module name.  Entries in the returned
+// trees do not reference the original AST (see the description of this file
+// above), but otherwise are the same as the saved entries.
+func Load(r io.Reader) (map[string]*Entry, error) {
+	var s savedSchema
+	if err := gob.NewDecoder(r).Decode(&s); err != nil {
+		return nil, err
+	}
+	if s.Version != saveVersion {
+		return nil, fmt.Errorf("unsupported saved schema version %d, want %d", s.Version, saveVersion)
+	}
+	l := &loader{
+		s:          &s,
+		ms:         NewModules(),
+		types:      make([]*YangType, len(s.Types)),
+		identities: make([]*Identity, len(s.Identities)),
+	}
+
+	// Modules must be created first as identities refer to them.
+	for _, se := range s.Modules {
+		if se.Module == nil {
+			return nil, fmt.Errorf("saved entry %s is not a module", se.Name)
+		}
+		l.module(se.Module)
+	}
+	for x, si := range s.Identities {
+		i := &Identity{
+			Name:   si.Name,
+			Source: &Statement{Keyword: "identity", HasArgument: true, Argument: si.Name},
+		}
+		if m := l.ms.Modules[si.Module]; m != nil {
+			i.Parent = m
+		}
+		l.identities[x] = i
+	}
+	for x, si := range s.Identities {
+		for _, v := range si.Values {
+			if v < 0 || v >= len(l.identities) {
+				return nil, fmt.Errorf("saved identity %s has invalid value %d", si.Name, v)
+			}
+			l.identities[x].Values = append(l.identities[x].Values, l.identities[v])
+		}
+	}
+	if err := l.loadTypes(); err != nil {
+		return nil, err
+	}
+
+	entries := map[string]*Entry{}
+	for _, se := range s.Modules {
+		e, err := l.entry(se, nil)
+		if err != nil {
+			return nil, err
+		}
+		entries[e.Name] = e
+		// Make cross module references, such as absolute paths used
+		// by Find, resolve to the loaded entries.
+		l.ms.entries.cache[e.Node] = e
+	}
+	return entries, nil
+}
+
+// module creates the minimal *Module described by sm and adds it to l.ms.
+func (l *loader) module(sm *savedModule) *Module {
+	value := func(s string) *Value {
+		return &Value{Name: s, Source: &Statement{Argument: s, HasArgument: true}}
+	}
+	m := &Module{
+		Name:      sm.Name,
+		Source:    &Statement{Keyword: "module", HasArgument: true, Argument: sm.Name},
+		Prefix:    value(sm.Prefix),
+		Namespace: value(sm.Namespace),
+
+		OpenConfigVersion:   sm.OpenConfigVersion,
+		CatalogOrganization: sm.CatalogOrganization,
+		RegexpPOSIX:         sm.RegexpPOSIX,
+		SMIv2Aliases:        sm.SMIv2Aliases,
+	}
+	for _, r := range sm.Revisions {
+		m.Revision = append(m.Revision, &Revision{Name: r, Parent: m})
+	}
+	l.ms.add(m)
+	return m
+}
+
+func (l *loader) loadTypes() error {
+	for x, st := range l.s.Types {
+		l.types[x] = &YangType{
+			Name:             st.Name,
+			Kind:             st.Kind,
+			Units:            st.Units,
+			Default:          st.Default,
+			FractionDigits:   st.FractionDigits,
+			Length:           st.Length,
+			OptionalInstance: st.OptionalInstance,
+			Path:             st.Path,
+			Pattern:          st.Pattern,
+			POSIXPattern:     st.POSIXPattern,
+			InvertedPattern:  st.InvertedPattern,
+			Range:            st.Range,
+		}
+	}
+	for x, st := range l.s.Types {
+		y := l.types[x]
+		if st.Root < 0 || st.Root >= len(l.types) {
+			return fmt.Errorf("saved type %s has invalid root %d", st.Name, st.Root)
+		}
+		y.Root = l.types[st.Root]
+		if st.IdentityBase > 0 {
+			if st.IdentityBase > len(l.identities) {
+				return fmt.Errorf("saved type %s has invalid identity base %d", st.Name, st.IdentityBase)
+			}
+			y.IdentityBase = l.identities[st.IdentityBase-1]
+		}
+		for _, t := range st.Type {
+			if t < 0 || t >= len(l.types) {
+				return fmt.Errorf("saved type %s has invalid union member %d", st.Name, t)
+			}
+			y.Type = append(y.Type, l.types[t])
+		}
+		var err error
+		if y.Enum, err = loadEnum(st.Enum); err != nil {
+			return fmt.Errorf("saved type %s: %v", st.Name, err)
+		}
+		if y.Bit, err = loadEnum(st.Bit); err != nil {
+			return fmt.Errorf("saved type %s: %v", st.Name, err)
+		}
+	}
+	return nil
+}
+
+func loadEnum(se *savedEnum) (*EnumType, error) {
+	if se == nil {
+		return nil, nil
+	}
+	e := NewEnumType()
+	if se.Bits {
+		e = NewBitfield()
+	}
+	if len(se.Names) != len(se.Values) {
+		return nil, fmt.Errorf("mismatched enum names and values")
+	}
+	for x, n := range se.Names {
+		if err := e.Set(n, se.Values[x]); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+func loadStatement(ss *savedStatement) *Statement {
+	if ss == nil {
+		return nil
+	}
+	s := &Statement{
+		Keyword:     ss.Keyword,
+		HasArgument: ss.HasArgument,
+		Argument:    ss.Argument,
+		file:        ss.File,
+		line:        ss.Line,
+		col:         ss.Col,
+		span:        ss.Span,
+		keywordSpan: ss.KeywordSpan,
+		argSpan:     ss.ArgSpan,
+	}
+	for _, sub := range ss.Statements {
+		s.statements = append(s.statements, loadStatement(sub))
+	}
+	return s
+}
+
+// nodeParts returns the name, statement and parent of the minimal AST node
+// described by sn.  The parent is the module the statement is in.
+func (l *loader) nodeParts(sn *savedNode) (string, *Statement, Node) {
+	s := loadStatement(sn.Statement)
+	var name string
+	if s != nil {
+		name = s.Argument
+	}
+	if m := l.ms.Modules[sn.Module]; m != nil {
+		return name, s, m
+	}
+	return name, s, nil
+}
+
+func (l *loader) uses(sn *savedNode) *Uses {
+	if sn == nil {
+		return nil
+	}
+	u := &Uses{}
+	u.Name, u.Source, u.Parent = l.nodeParts(sn)
+	return u
+}
+
+func (l *loader) augment(sn *savedNode) *Augment {
+	if sn == nil {
+		return nil
+	}
+	a := &Augment{}
+	a.Name, a.Source, a.Parent = l.nodeParts(sn)
+	return a
+}
+
+func (l *loader) deviation(sn *savedNode) *Deviation {
+	if sn == nil {
+		return nil
+	}
+	d := &Deviation{}
+	d.Name, d.Source, d.Parent = l.nodeParts(sn)
+	return d
+}
+
+func (l *loader) deviate(sn *savedNode, parent *Deviation) *Deviate {
+	if sn == nil {
+		return nil
+	}
+	d := &Deviate{}
+	d.Name, d.Source, d.Parent = l.nodeParts(sn)
+	if parent != nil {
+		d.Parent = parent
+	}
+	return d
+}
+
+// loadCondition returns the Condition described by sc.  The XPath expression
+// is parsed again.
+func loadCondition(sc *savedCondition) *Condition {
+	if sc == nil {
+		return nil
+	}
+	c := &Condition{
+		Keyword:      sc.Keyword,
+		Expr:         sc.Expr,
+		Description:  sc.Description,
+		Reference:    sc.Reference,
+		ErrorMessage: sc.ErrorMessage,
+		ErrorAppTag:  sc.ErrorAppTag,
+	}
+	if s := loadStatement(sc.Statement); s != nil {
+		c.Node = s
+	}
+	c.XPath, c.Err = xpath.Parse(c.Expr)
+	return c
+}
+
+// entries returns the entries described by ses, each with the given parent.
+func (l *loader) entries(ses []*savedEntry, parent *Entry) ([]*Entry, error) {
+	var es []*Entry
+	for _, se := range ses {
+		e, err := l.entry(se, parent)
+		if err != nil {
+			return nil, err
+		}
+		if e == nil {
+			return nil, fmt.Errorf("saved entry %s has a missing entry", parent.Name)
+		}
+		es = append(es, e)
+	}
+	return es, nil
+}
+
+func (l *loader) entry(se *savedEntry, parent *Entry) (*Entry, error) {
+	if se == nil {
+		return nil, nil
+	}
+	e := &Entry{
+		Parent:      parent,
+		Name:        se.Name,
+		Description: se.Description,
+		Default:     se.Default,
+		Units:       se.Units,
+		Kind:        se.Kind,
+		Config:      se.Config,
+		Mandatory:   se.Mandatory,
+		Key:         se.Key,
+		Extra:       map[string][]interface{}{},
+		When:        loadCondition(se.When),
+		Annotation:  se.Annotation,
+
+		deviatePresence: deviationPresence{
+			hasMinElements: se.HasMinElements,
+			hasMaxElements: se.HasMaxElements,
+		},
+
+		TelemetryAtomic:  se.TelemetryAtomic,
+		Operational:      se.Operational,
+		DefaultDenyWrite: se.DefaultDenyWrite,
+		DefaultDenyAll:   se.DefaultDenyAll,
+		SMIv2:            se.SMIv2,
+	}
+	switch {
+	case se.Module != nil:
+		m := l.ms.Modules[se.Module.Name]
+		m.Source = loadStatement(se.Statement)
+		e.Node = m
+	default:
+		e.Node = loadStatement(se.Statement)
+	}
+	if se.Prefix != "" {
+		e.Prefix = &Value{Name: se.Prefix}
+	}
+	if se.Namespace != "" {
+		e.namespace = &Value{Name: se.Namespace}
+	}
+	if se.Type > 0 {
+		if se.Type > len(l.types) {
+			return nil, fmt.Errorf("saved entry %s has invalid type %d", se.Name, se.Type)
+		}
+		e.Type = l.types[se.Type-1]
+	}
+	for _, s := range se.Exts {
+		e.Exts = append(e.Exts, loadStatement(s))
+	}
+	if la := se.ListAttr; la != nil {
+		e.ListAttr = &ListAttr{
+			MinElements: la.MinElements,
+			MaxElements: la.MaxElements,
+		}
+		if la.OrderedBy != "" {
+			e.ListAttr.OrderedBy = &Value{Name: la.OrderedBy}
+		}
+	}
+	for _, i := range se.Identities {
+		if i < 0 || i >= len(l.identities) {
+			return nil, fmt.Errorf("saved entry %s has invalid identity %d", se.Name, i)
+		}
+		e.Identities = append(e.Identities, l.identities[i])
+	}
+	for _, sc := range se.Must {
+		e.Must = append(e.Must, loadCondition(sc))
+	}
+	for _, sn := range se.UsedAt {
+		e.usedAt = append(e.usedAt, l.uses(sn))
+	}
+	e.augmentedBy = l.augment(se.AugmentedBy)
+	for _, sd := range se.DeviatedBy {
+		ad := &AppliedDeviation{
+			Module:    l.ms.Modules[sd.Module],
+			Deviation: l.deviation(sd.Deviation),
+			Type:      sd.Type,
+			Changes:   sd.Changes,
+		}
+		ad.Deviate = l.deviate(sd.Deviate, ad.Deviation)
+		e.deviatedBy = append(e.deviatedBy, ad)
+	}
+
+	var err error
+	if se.IsRPC {
+		e.RPC = &RPCEntry{}
+		if e.RPC.Input, err = l.entry(se.Input, e); err != nil {
+			return nil, err
+		}
+		if e.RPC.Output, err = l.entry(se.Output, e); err != nil {
+			return nil, err
+		}
+	}
+	if e.Augments, err = l.entries(se.Augments, e); err != nil {
+		return nil, err
+	}
+	if e.Augmented, err = l.entries(se.Augmented, e); err != nil {
+		return nil, err
+	}
+	for _, su := range se.Uses {
+		g, err := l.entry(su.Grouping, nil)
+		if err != nil {
+			return nil, err
+		}
+		e.Uses = append(e.Uses, &UsesStmt{Uses: l.uses(su.Uses), Grouping: g})
+	}
+	for _, sd := range se.Deviations {
+		de, err := l.entry(sd.Entry, nil)
+		if err != nil {
+			return nil, err
+		}
+		if de == nil {
+			return nil, fmt.Errorf("saved entry %s has a deviation without an entry", se.Name)
+		}
+		e.Deviations = append(e.Deviations, &DeviatedEntry{
+			Type:         sd.Type,
+			DeviatedPath: sd.DeviatedPath,
+			Entry:        de,
+		})
+	}
+	for dt, sds := range se.Deviate {
+		if e.Deviate == nil {
+			e.Deviate = map[deviationType][]*Entry{}
+		}
+		if e.Deviate[dt], err = l.entries(sds, e); err != nil {
+			return nil, err
+		}
+	}
+	for k, ss := range se.Structures {
+		if e.Structures == nil {
+			e.Structures = map[string]*Entry{}
+		}
+		if e.Structures[k], err = l.entry(ss, e); err != nil {
+			return nil, err
+		}
+	}
+	if se.IsDir || len(se.Dir) > 0 {
+		e.Dir = make(map[string]*Entry, len(se.Dir))
+		cs, err := l.entries(se.Dir, e)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range cs {
+			e.Dir[c.Name] = c
+		}
+	}
+	return e, nil
+}
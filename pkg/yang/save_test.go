@@ -0,0 +1,348 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestSaveLoad(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"save-base": `
+			module save-base {
+				prefix "sb";
+				namespace "urn:sb";
+				revision 2020-01-01;
+				identity base-id;
+				identity derived-id { base base-id; }
+				typedef color { type enumeration { enum red; enum green { value 5; } } }
+			}
+		`,
+		"save-test": `
+			module save-test {
+				prefix "st";
+				namespace "urn:st";
+				import save-base { prefix sb; }
+				container c {
+					config false;
+					leaf a { type sb:color; default green; }
+					leaf b { type sb:color; }
+					leaf id { type identityref { base sb:base-id; } }
+					leaf u { type union { type int8 { range "1..10"; } type string; } }
+					list l {
+						key "k";
+						max-elements 5;
+						leaf k { type string; }
+					}
+				}
+				rpc r {
+					input { leaf in { type string; } }
+				}
+				augment "/st:c" {
+					leaf aug { type bits { bit one; bit two; } }
+				}
+			}
+		`,
+	} {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatalf("cannot parse %s: %v", name, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	var buf bytes.Buffer
+	if err := ms.Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	entries, err := Load(&buf)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	// Compare the printed forms of the original and loaded trees.
+	for _, name := range []string{"save-base", "save-test"} {
+		e := entries[name]
+		if e == nil {
+			t.Fatalf("module %s not loaded", name)
+		}
+		var want, got bytes.Buffer
+		ToEntry(ms.Modules[name]).Print(&want)
+		e.Print(&got)
+		if got.String() != want.String() {
+			t.Errorf("%s: loaded tree differs\ngot:\n%s\nwant:\n%s", name, &got, &want)
+		}
+	}
+
+	e := entries["save-test"]
+	a, b := e.Find("c/a"), e.Find("c/b")
+	if a.Type.Root != b.Type.Root {
+		t.Errorf("leaves sharing a typedef do not share a root YangType after Load")
+	}
+	if !a.ReadOnly() || a.Default != "green" {
+		t.Errorf("c/a: got ReadOnly %v, default %q", a.ReadOnly(), a.Default)
+	}
+	if v := a.Type.Enum.Value("green"); v != 5 {
+		t.Errorf("c/a: got enum value %d for green, want 5", v)
+	}
+	if id := e.Find("c/id").Type.IdentityBase; id == nil || id.PrefixedName() != "sb:base-id" || !id.IsDefined("derived-id") {
+		t.Errorf("c/id: bad identity base %v", id)
+	}
+	if u := e.Find("c/u").Type; len(u.Type) != 2 || u.Type[0].Range.String() != "1..10" {
+		t.Errorf("c/u: bad union %v", u.Type)
+	}
+	if l := e.Find("c/l"); l.Key != "k" || l.ListAttr.MaxElements != 5 {
+		t.Errorf("c/l: got key %q, max-elements %d", l.Key, l.ListAttr.MaxElements)
+	}
+	if in := e.Find("r/input/in"); in == nil {
+		t.Errorf("r/input/in: not found")
+	}
+	aug := e.Find("/st:c/aug")
+	if aug == nil {
+		t.Fatalf("/st:c/aug: not found")
+	}
+	if ns := aug.Namespace().Name; ns != "urn:st" {
+		t.Errorf("/st:c/aug: got namespace %q, want urn:st", ns)
+	}
+	if loc := aug.Node.Statement().Location(); !strings.HasPrefix(loc, "save-test.yang:") {
+		t.Errorf("/st:c/aug: got location %q", loc)
+	}
+
+	if _, err := Load(strings.NewReader("not a schema")); err == nil {
+		t.Errorf("Load of bad data unexpectedly succeeded")
+	}
+}
+
+// sameNode reports whether a and b are the same in the parts of a node
+// that are kept by Save and Load.  Load replaces most nodes by their
+// statements, so only the statement of a node, without its substatements,
+// is compared.
+func sameNode(a, b Node) bool {
+	isNil := func(n Node) bool {
+		v := reflect.ValueOf(n)
+		return n == nil || v.Kind() == reflect.Ptr && v.IsNil()
+	}
+	if isNil(a) || isNil(b) {
+		return isNil(a) == isNil(b)
+	}
+	ai, aok := a.(*Identity)
+	bi, bok := b.(*Identity)
+	if aok || bok {
+		return aok && bok && describeIdentity(ai) == describeIdentity(bi)
+	}
+	_, aok = a.(*Module)
+	_, bok = b.(*Module)
+	if aok || bok {
+		return a.NName() == b.NName()
+	}
+	if as, ok := a.(*Statement); ok {
+		if bs, ok := b.(*Statement); ok {
+			return describeStatement(as) == describeStatement(bs)
+		}
+	}
+	as, bs := a.Statement(), b.Statement()
+	if as == nil || bs == nil {
+		return a.NName() == b.NName()
+	}
+	describe := func(s *Statement) string {
+		return fmt.Sprintf("%s %q %v %v %v %v", s.Keyword, s.Argument, s.Position(), s.Span(), s.KeywordSpan(), s.ArgumentSpan())
+	}
+	return describe(as) == describe(bs)
+}
+
+// describeStatement returns s and its substatements in the form kept by
+// Save and Load.
+func describeStatement(s *Statement) string {
+	if s == nil {
+		return "nil"
+	}
+	desc := fmt.Sprintf("%s %q %v %v %v %v {", s.Keyword, s.Argument, s.Position(), s.Span(), s.KeywordSpan(), s.ArgumentSpan())
+	for _, ss := range s.SubStatements() {
+		desc += describeStatement(ss) + ";"
+	}
+	return desc + "}"
+}
+
+// describeIdentity returns the name of i and of the identities derived
+// from it.
+func describeIdentity(i *Identity) string {
+	if i == nil {
+		return "nil"
+	}
+	var vs []string
+	for _, v := range i.Values {
+		vs = append(vs, describeIdentity(v))
+	}
+	sort.Strings(vs)
+	return i.PrefixedName() + strings.Join(vs, ",")
+}
+
+func TestSaveLoadFullEntry(t *testing.T) {
+	defer func(b bool) { ParseOptions.StoreUses = b }(ParseOptions.StoreUses)
+	ParseOptions.StoreUses = true
+
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"full": `module full {
+  prefix "f";
+  namespace "urn:full";
+  yang-version 1.1;
+  identity base-id;
+  identity derived-id { base base-id; }
+  grouping g {
+    leaf gl { type string; must "string-length(.) > 1" { error-message "too short"; } }
+  }
+  container c {
+    when "../x = 'y'" { description "only with y"; }
+    uses g;
+    leaf id { type identityref { base base-id; } }
+    leaf x { type string; units "s"; }
+    leaf-list ll { type enumeration { enum a; enum b { value 7; } } ordered-by user; }
+    list l {
+      key "k";
+      leaf k { type bits { bit one; bit two { position 4; } } }
+      notification changed { leaf why { type string; } }
+    }
+    anydata any;
+  }
+  rpc r {
+    input { leaf in { type int32 { range "1..5"; } } }
+    output { leaf out { type union { type string { pattern "[a-z]+"; } type uint8; } } }
+  }
+  notification n { leaf what { type string; } }
+}`,
+		"full-aug": `module full-aug {
+  prefix "fa";
+  namespace "urn:full-aug";
+  import full { prefix f; }
+  augment "/f:c" {
+    leaf added { type string; }
+  }
+  deviation "/f:c/f:x" {
+    deviate replace { type int32; }
+    deviate delete { units "s"; }
+  }
+  deviation "/f:c/f:ll" {
+    deviate add { max-elements 3; }
+  }
+}`,
+	} {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatalf("cannot parse %s: %v", name, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	ToEntry(ms.Modules["full"]).Dir["c"].Annotation = map[string]interface{}{"count": 3, "note": "kept"}
+
+	var buf bytes.Buffer
+	if err := ms.Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	entries, err := Load(&buf)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	typeOpts := []cmp.Option{
+		// Base is the AST type statement, which is not saved.
+		cmpopts.IgnoreFields(YangType{}, "Base"),
+		cmpopts.IgnoreUnexported(YangType{}),
+		cmp.Comparer(func(a, b *EnumType) bool {
+			if a == nil || b == nil {
+				return a == b
+			}
+			return cmp.Equal(a.NameMap(), b.NameMap())
+		}),
+		cmp.Comparer(func(a, b *Identity) bool { return describeIdentity(a) == describeIdentity(b) }),
+	}
+	var typeComparer cmp.Option
+	typeComparer = cmp.Comparer(func(a, b *YangType) bool {
+		if a == nil || b == nil {
+			return a == b
+		}
+		if a.Root.Name != b.Root.Name || len(a.Type) != len(b.Type) {
+			return false
+		}
+		for i := range a.Type {
+			if !cmp.Equal(a.Type[i], b.Type[i], typeComparer) {
+				return false
+			}
+		}
+		return cmp.Equal(*a, *b, append(typeOpts, cmpopts.IgnoreFields(YangType{}, "Root", "Type"))...)
+	})
+	opts := []cmp.Option{
+		// Parent pointers are checked below, and Extra holds AST nodes,
+		// which are not saved.
+		cmpopts.IgnoreFields(Entry{}, "Parent", "Extra"),
+		cmp.AllowUnexported(Entry{}, deviationPresence{}),
+		cmp.Comparer(sameNode),
+		cmp.Comparer(func(a, b *Condition) bool {
+			if a == nil || b == nil {
+				return a == b
+			}
+			return cmp.Equal(*a, *b,
+				cmpopts.IgnoreFields(Condition{}, "XPath", "Err", "Node")) &&
+				fmt.Sprint(a.XPath) == fmt.Sprint(b.XPath) &&
+				fmt.Sprint(a.Err) == fmt.Sprint(b.Err) &&
+				sameNode(a.Node, b.Node)
+		}),
+		cmp.Comparer(func(a, b error) bool { return fmt.Sprint(a) == fmt.Sprint(b) }),
+		typeComparer,
+	}
+	for _, name := range []string{"full", "full-aug"} {
+		want, got := ToEntry(ms.Modules[name]), entries[name]
+		if got == nil {
+			t.Fatalf("module %s not loaded", name)
+		}
+		if diff := cmp.Diff(want, got, opts...); diff != "" {
+			t.Errorf("%s: loaded entry differs (-want, +got):\n%s", name, diff)
+		}
+		walkEntries(got, func(e *Entry) bool {
+			for _, c := range e.Dir {
+				if c.Parent != e {
+					t.Errorf("%s: parent of %s is not %s", name, c.Path(), e.Path())
+				}
+			}
+			return true
+		})
+	}
+
+	// Spot check the accessors built on the saved fields.
+	e := entries["full"]
+	if got, want := len(e.Notifications()), 2; got != want {
+		t.Errorf("got %d notifications, want %d", got, want)
+	}
+	if a := e.Find("/f:c/added").AugmentedBy(); a == nil || a.ParentModule().Name != "full-aug" {
+		t.Errorf("/f:c/added: got AugmentedBy %v, want the augment in full-aug", a)
+	}
+	if ps := e.Find("c/gl").ExpandedAt(); len(ps) != 1 || ps[0].Line != 12 {
+		t.Errorf("c/gl: got ExpandedAt %v, want line 12", ps)
+	}
+	if ds := e.Find("c/x").DeviatedBy(); len(ds) != 2 || ds[1].Deviate.Name != "delete" {
+		t.Errorf("c/x: got DeviatedBy %v", ds)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements support for the extensions defined in the
+// ietf-yang-smiv2 module (RFC 6643), which map YANG modules translated from
+// SMIv2 MIB modules back to their SNMP objects.
+
+// yangSMIv2 is the name of the module that defines the SMIv2 extensions.
+const yangSMIv2 = "ietf-yang-smiv2"
+
+// SMIv2 is the SMIv2 metadata of an entry.
+type SMIv2 struct {
+	OID         string // smiv2:oid, the object identifier of the node
+	MaxAccess   string // smiv2:max-access, e.g., "read-only"
+	Defval      string // smiv2:defval, the DEFVAL of the object
+	Implied     string // smiv2:implied, the name of the IMPLIED index leaf
+	DisplayHint string // smiv2:display-hint of the node's type, if any
+}
+
+// setSMIv2Extensions sets e.SMIv2 from the SMIv2 extensions used in n, the
+// node e was created from, and from the typedefs of e's type.  e.SMIv2 is
+// left nil if there are none.
+func (e *Entry) setSMIv2Extensions(n Node) {
+	var s SMIv2
+	for _, ext := range n.Exts() {
+		switch extensionFrom(ext, n, yangSMIv2) {
+		case "oid":
+			s.OID = ext.Argument
+		case "max-access":
+			s.MaxAccess = ext.Argument
+		case "defval":
+			s.Defval = ext.Argument
+		case "implied":
+			s.Implied = ext.Argument
+		}
+	}
+	s.DisplayHint = displayHint(e.Type)
+	if s != (SMIv2{}) {
+		e.SMIv2 = &s
+	}
+}
+
+// displayHint returns the argument of the first smiv2:display-hint found
+// on the chain of typedefs that y is derived from.
+func displayHint(y *YangType) string {
+	for ; y != nil && y.Base != nil; y = y.Base.YangType {
+		td, ok := y.Base.Parent.(*Typedef)
+		if !ok {
+			continue
+		}
+		for _, ext := range td.Exts() {
+			if extensionFrom(ext, td, yangSMIv2) == "display-hint" {
+				return ext.Argument
+			}
+		}
+	}
+	return ""
+}
+
+// setSMIv2Aliases sets m.SMIv2Aliases from the smiv2:alias statements of
+// m.  Each alias is mapped to the argument of its smiv2:oid substatement.
+func (m *Module) setSMIv2Aliases() {
+	m.SMIv2Aliases = nil
+	for _, ext := range m.Extensions {
+		if extensionFrom(ext, m, yangSMIv2) != "alias" {
+			continue
+		}
+		oid := ""
+		for _, ss := range ext.SubStatements() {
+			if extensionFrom(ss, m, yangSMIv2) == "oid" {
+				oid = ss.Argument
+			}
+		}
+		if m.SMIv2Aliases == nil {
+			m.SMIv2Aliases = map[string]string{}
+		}
+		m.SMIv2Aliases[ext.Argument] = oid
+	}
+}
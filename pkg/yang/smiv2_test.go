@@ -0,0 +1,136 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSMIv2(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"ietf-yang-smiv2": `
+			module ietf-yang-smiv2 {
+				prefix "smiv2";
+				namespace "urn:ietf:params:xml:ns:yang:ietf-yang-smiv2";
+				extension display-hint { argument "format"; }
+				extension max-access { argument "access"; }
+				extension defval { argument "value"; }
+				extension implied { argument "index"; }
+				extension alias { argument "descriptor"; }
+				extension oid { argument "value"; }
+			}
+		`,
+		"SMIV2-TC": `
+			module SMIV2-TC {
+				prefix "tc";
+				namespace "urn:tc";
+				import ietf-yang-smiv2 { prefix "smiv2"; }
+				typedef DisplayString {
+					type string { length "0..255"; }
+					smiv2:display-hint "255a";
+				}
+			}
+		`,
+		"SMIV2-MIB": `
+			module SMIV2-MIB {
+				prefix "mib";
+				namespace "urn:mib";
+				import ietf-yang-smiv2 { prefix "smiv2"; }
+				import SMIV2-TC { prefix "tc"; }
+				smiv2:alias "testMIB" { smiv2:oid "1.3.6.1.4.1.99"; }
+				smiv2:alias "testObjects" { smiv2:oid "1.3.6.1.4.1.99.1"; }
+				typedef Name { type tc:DisplayString; }
+				container SMIV2-MIB {
+					container system {
+						smiv2:oid "1.3.6.1.4.1.99.1.1";
+						leaf sysDescr {
+							type tc:DisplayString;
+							smiv2:oid "1.3.6.1.4.1.99.1.1.1";
+							smiv2:max-access "read-only";
+						}
+						leaf sysName {
+							type Name;
+							smiv2:max-access "read-write";
+							smiv2:defval "none";
+						}
+						leaf plain { type string; }
+					}
+					list ifEntry {
+						key "ifName";
+						smiv2:oid "1.3.6.1.4.1.99.1.2.1";
+						smiv2:implied "ifName";
+						leaf ifName { type string; }
+					}
+				}
+			}
+		`,
+	} {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	wantAliases := map[string]string{
+		"testMIB":     "1.3.6.1.4.1.99",
+		"testObjects": "1.3.6.1.4.1.99.1",
+	}
+	if diff := cmp.Diff(wantAliases, ms.Modules["SMIV2-MIB"].SMIv2Aliases); diff != "" {
+		t.Errorf("SMIv2Aliases (-want, +got):\n%s", diff)
+	}
+
+	var buf bytes.Buffer
+	if err := ms.Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	entries, err := Load(&buf)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if diff := cmp.Diff(wantAliases, entries["SMIV2-MIB"].Node.(*Module).SMIv2Aliases); diff != "" {
+		t.Errorf("loaded SMIv2Aliases (-want, +got):\n%s", diff)
+	}
+
+	for _, tt := range []struct {
+		path string
+		want *SMIv2
+	}{
+		{"/SMIV2-MIB/system", &SMIv2{OID: "1.3.6.1.4.1.99.1.1"}},
+		{"/SMIV2-MIB/system/sysDescr", &SMIv2{OID: "1.3.6.1.4.1.99.1.1.1", MaxAccess: "read-only", DisplayHint: "255a"}},
+		{"/SMIV2-MIB/system/sysName", &SMIv2{MaxAccess: "read-write", Defval: "none", DisplayHint: "255a"}},
+		{"/SMIV2-MIB/system/plain", nil},
+		{"/SMIV2-MIB/ifEntry", &SMIv2{OID: "1.3.6.1.4.1.99.1.2.1", Implied: "ifName"}},
+	} {
+		for source, root := range map[string]*Entry{
+			"processed": ToEntry(ms.Modules["SMIV2-MIB"]),
+			"loaded":    entries["SMIV2-MIB"],
+		} {
+			e := root.Find(tt.path)
+			if e == nil {
+				t.Errorf("%s %s: not found", source, tt.path)
+				continue
+			}
+			if diff := cmp.Diff(tt.want, e.SMIv2); diff != "" {
+				t.Errorf("%s %s (-want, +got):\n%s", source, tt.path, diff)
+			}
+		}
+	}
+}
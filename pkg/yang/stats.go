@@ -0,0 +1,136 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the collection of memory and timing statistics
+// for the modules in a Modules.
+
+import (
+	"reflect"
+	"sort"
+	"time"
+)
+
+// sourceStats records what is known about a module when it is parsed.
+type sourceStats struct {
+	parseTime time.Duration // time taken to parse the source file
+	size      int           // size of the source in bytes
+}
+
+// ModuleStats contains statistics about a single module or submodule.
+type ModuleStats struct {
+	Name       string        // name of the module
+	Submodule  bool          // true if this is a submodule
+	Source     string        // location of the module's source
+	ParseTime  time.Duration // time to parse the file the module is in
+	SourceSize int           // size of the source file in bytes
+	Statements int           // number of statements in the module
+	Entries    int           // number of entries in the module's Entry tree
+
+	// Memory is an approximation of the memory, in bytes, used by the
+	// module's source, statements and entries.  It does not include
+	// memory used by types, extensions, or other supporting structures.
+	Memory int
+}
+
+// Stats contains statistics about all the modules in a Modules.
+type Stats struct {
+	ParseTime   time.Duration  // total time spent parsing sources
+	ProcessTime time.Duration  // time spent in the last call to Process
+	Modules     []*ModuleStats // per module statistics, sorted by name
+}
+
+var (
+	statementSize = int(reflect.TypeOf(Statement{}).Size())
+	entrySize     = int(reflect.TypeOf(Entry{}).Size())
+)
+
+// Stats returns statistics about the modules in ms.  Entry counts are only
+// available after Process has been called.
+func (ms *Modules) Stats() Stats {
+	var st Stats
+	st.ProcessTime = ms.processTime
+	// A single source may contain more than one module.
+	counted := map[*sourceStats]bool{}
+	for _, ss := range ms.sources {
+		if !counted[ss] {
+			counted[ss] = true
+			st.ParseTime += ss.parseTime
+		}
+	}
+
+	seen := map[*Module]bool{}
+	for _, mods := range []map[string]*Module{ms.Modules, ms.SubModules} {
+		for _, m := range mods {
+			// Modules are stored under both their name and their
+			// full name.
+			if seen[m] {
+				continue
+			}
+			seen[m] = true
+			mst := &ModuleStats{
+				Name:       m.Name,
+				Submodule:  m.Kind() == "submodule",
+				Source:     Source(m),
+				Statements: countStatements(m.Source),
+			}
+			if ss := ms.sources[m]; ss != nil {
+				mst.ParseTime = ss.parseTime
+				mst.SourceSize = ss.size
+			}
+			if e := ms.entries.cache[m]; e != nil {
+				mst.Entries = countEntries(e)
+			}
+			mst.Memory = mst.SourceSize + mst.Statements*statementSize + mst.Entries*entrySize
+			st.Modules = append(st.Modules, mst)
+		}
+	}
+	sort.Slice(st.Modules, func(i, j int) bool {
+		mi, mj := st.Modules[i], st.Modules[j]
+		if mi.Name != mj.Name {
+			return mi.Name < mj.Name
+		}
+		return mi.Source < mj.Source
+	})
+	return st
+}
+
+// countStatements returns the number of statements in the tree rooted at s.
+func countStatements(s *Statement) int {
+	if s == nil {
+		return 0
+	}
+	n := 1
+	for _, ss := range s.statements {
+		n += countStatements(ss)
+	}
+	return n
+}
+
+// countEntries returns the number of entries in the tree rooted at e.
+func countEntries(e *Entry) int {
+	if e == nil {
+		return 0
+	}
+	n := 1
+	for _, de := range e.Dir {
+		n += countEntries(de)
+	}
+	if e.RPC != nil {
+		n += countEntries(e.RPC.Input)
+		n += countEntries(e.RPC.Output)
+	}
+	return n
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import "testing"
+
+func TestStats(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"stats-sub": `
+			submodule stats-sub {
+				belongs-to stats-test { prefix "t"; }
+				leaf s { type string; }
+			}
+		`,
+		"stats-test": `
+			module stats-test {
+				prefix "t";
+				namespace "urn:t";
+				revision 2020-01-01;
+				include stats-sub;
+				container c {
+					leaf a { type string; }
+					leaf b { type string; }
+				}
+				rpc r {
+					input { leaf in { type string; } }
+				}
+			}
+		`,
+	} {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatalf("cannot parse %s: %v", name, err)
+		}
+	}
+
+	if st := ms.Stats(); st.ProcessTime != 0 {
+		t.Errorf("got process time %v before Process, want 0", st.ProcessTime)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	st := ms.Stats()
+	if st.ParseTime <= 0 || st.ProcessTime <= 0 {
+		t.Errorf("got parse time %v, process time %v, want both > 0", st.ParseTime, st.ProcessTime)
+	}
+	if len(st.Modules) != 2 {
+		t.Fatalf("got %d modules, want 2", len(st.Modules))
+	}
+	for i, tt := range []struct {
+		name       string
+		submodule  bool
+		statements int
+		entries    int
+	}{
+		// The submodule's leaf is merged into the module entry tree.
+		{"stats-sub", true, 5, 2},
+		{"stats-test", false, 14, 8},
+	} {
+		got := st.Modules[i]
+		if got.Name != tt.name || got.Submodule != tt.submodule {
+			t.Errorf("#%d: got %s (submodule %v), want %s (submodule %v)", i, got.Name, got.Submodule, tt.name, tt.submodule)
+		}
+		if got.Statements != tt.statements {
+			t.Errorf("%s: got %d statements, want %d", tt.name, got.Statements, tt.statements)
+		}
+		if got.Entries != tt.entries {
+			t.Errorf("%s: got %d entries, want %d", tt.name, got.Entries, tt.entries)
+		}
+		if got.SourceSize == 0 || got.Memory <= got.SourceSize {
+			t.Errorf("%s: got source size %d, memory %d", tt.name, got.SourceSize, got.Memory)
+		}
+	}
+}
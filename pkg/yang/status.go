@@ -0,0 +1,115 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the checks of status statements.
+
+import "reflect"
+
+// statusRank orders the values of the status statement from current to
+// obsolete.
+var statusRank = map[string]int{
+	"current":    0,
+	"deprecated": 1,
+	"obsolete":   2,
+}
+
+// nodeStatus returns the status of n, which is "current" if n has no
+// status statement.
+func nodeStatus(n Node) string {
+	v := reflect.ValueOf(n)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return "current"
+	}
+	if f := v.Elem().FieldByName("Status"); f.IsValid() {
+		if s, ok := f.Interface().(*Value); ok && s != nil {
+			return s.Name
+		}
+	}
+	return "current"
+}
+
+// effectiveStatus returns the least current status of n and its ancestors.
+// A definition may refer to definitions with the same status as its own.
+func effectiveStatus(n Node) string {
+	status := "current"
+	for ; n != nil; n = n.ParentNode() {
+		if s := nodeStatus(n); statusRank[s] > statusRank[status] {
+			status = s
+		}
+	}
+	return status
+}
+
+// checkStatus returns warnings about the typedefs, groupings, and
+// identities that are deprecated or obsolete and that are referred to by
+// the definitions of the modules of ms that are not themselves deprecated
+// or obsolete (RFC 7950 section 7.21.2).
+func (ms *Modules) checkStatus() []error {
+	var ws []error
+	check := func(ref Node, kind, name string, def Node) {
+		status := nodeStatus(def)
+		if statusRank[status] <= statusRank[effectiveStatus(ref)] {
+			return
+		}
+		code := WarnDeprecated
+		if status == "obsolete" {
+			code = WarnObsolete
+		}
+		ws = append(ws, warnf(ref, code, "%s %s is %s", kind, name, status))
+	}
+	identity := func(ref Node, base string) {
+		if id, errs := RootNode(ref).findIdentityBase(base); len(errs) == 0 {
+			check(ref, "identity", base, id.Identity)
+		}
+	}
+	for _, m := range ms.sortedModules() {
+		walkNodes(m, func(n Node) {
+			switch n := n.(type) {
+			case *Type:
+				if y := n.YangType; y != nil && y.Base != nil {
+					if td, ok := y.Base.Parent.(*Typedef); ok {
+						check(n, "typedef", n.Name, td)
+					}
+				}
+				if n.IdentityBase != nil {
+					identity(n, n.IdentityBase.Name)
+				}
+			case *Uses:
+				if g := FindGrouping(n, n.Name, map[string]bool{}); g != nil {
+					check(n, "grouping", n.Name, g)
+				}
+			case *Identity:
+				for _, b := range n.Base {
+					identity(n, b.Name)
+				}
+			}
+		})
+	}
+	return ws
+}
+
+// pruneObsolete removes the entries of obsolete nodes from the Entry trees
+// of the modules of ms.
+func (ms *Modules) pruneObsolete() {
+	strip := StripNodes(func(e *Entry) bool {
+		return e.Node != nil && nodeStatus(e.Node) == "obsolete"
+	})
+	for _, m := range ms.sortedModules() {
+		if m.Kind() == "module" {
+			strip.Transform(ToEntry(m))
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const statusModule = `
+module status {
+  prefix "s";
+  namespace "urn:s";
+
+  typedef old { type string; status deprecated; }
+  typedef gone { type string; status obsolete; }
+  grouping g { leaf gl { type string; } status deprecated; }
+  identity base-id { status obsolete; }
+  identity derived { base base-id; status obsolete; }
+
+  container c {
+    leaf a { type old; }
+    leaf b { type gone; status obsolete; }
+    uses g;
+    leaf i { type identityref { base base-id; } }
+  }
+  container d {
+    status deprecated;
+    leaf e { type old; }
+  }
+}
+`
+
+func TestCheckStatus(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(statusModule, "status.yang"); err != nil {
+		t.Fatal(err)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	var got []string
+	for _, w := range ms.Warnings() {
+		got = append(got, string(ErrorCode(w))+" "+w.Error())
+	}
+	want := []string{
+		"deprecated status.yang:13:14: typedef old is deprecated",
+		"deprecated status.yang:15:5: grouping g is deprecated",
+		"obsolete status.yang:16:14: identity base-id is obsolete",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want, +got):\n%s", diff)
+	}
+}
+
+func TestPruneObsolete(t *testing.T) {
+	defer func(o Options) { ParseOptions = o }(ParseOptions)
+
+	for _, prune := range []bool{false, true} {
+		ParseOptions.PruneObsolete = prune
+		ms := NewModules()
+		if err := ms.Parse(statusModule, "status.yang"); err != nil {
+			t.Fatal(err)
+		}
+		if errs := ms.Process(); len(errs) > 0 {
+			t.Fatal(errs)
+		}
+		c := ToEntry(ms.Modules["status"]).Dir["c"]
+		if got := c.Dir["b"] == nil; got != prune {
+			t.Errorf("PruneObsolete %v: leaf b removed is %v", prune, got)
+		}
+		if c.Dir["a"] == nil {
+			t.Errorf("PruneObsolete %v: leaf a was removed", prune)
+		}
+	}
+}
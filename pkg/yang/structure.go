@@ -0,0 +1,189 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the structure and augment-structure extensions
+// defined by the ietf-yang-structure-ext module (RFC 8791).
+//
+// A structure is represented by a Container that has the module as its
+// parent and an augment-structure by an Augment.  Their Entry trees are
+// found in the Structures field of the module's Entry.
+
+import (
+	"reflect"
+	"strings"
+)
+
+// yangStructureExt is the name of the module that defines the structure
+// extensions.
+const yangStructureExt = "ietf-yang-structure-ext"
+
+// structureExtension returns the name of ext, used in m, if ext is defined
+// by ietf-yang-structure-ext.  Structures are built before imports are
+// resolved so the prefix is matched with the name of the imported module.
+func structureExtension(m *Module, ext *Statement) string {
+	prefix, name := getPrefix(ext.Keyword)
+	if prefix == "" {
+		return ""
+	}
+	for _, i := range m.Import {
+		if i.Prefix != nil && i.Prefix.Name == prefix && i.Name == yangStructureExt {
+			return name
+		}
+	}
+	return ""
+}
+
+// structureSubstatements are the substatements allowed in structure or
+// augment-structure statements.  Those only allowed in one of them, such
+// as case in augment-structure, are rejected for the other by build.
+var structureSubstatements = map[string]bool{
+	"anydata":     true,
+	"anyxml":      true,
+	"case":        true,
+	"choice":      true,
+	"container":   true,
+	"description": true,
+	"grouping":    true,
+	"leaf":        true,
+	"leaf-list":   true,
+	"list":        true,
+	"must":        true,
+	"reference":   true,
+	"status":      true,
+	"typedef":     true,
+	"uses":        true,
+}
+
+// buildStructures builds the ASTs of the sx:structure and
+// sx:augment-structure statements of m.  The substatements of a structure
+// are those of a container and the substatements of an augment-structure
+// are those of an augment.
+func (m *Module) buildStructures() error {
+	for _, ext := range m.Extensions {
+		var kind string
+		switch structureExtension(m, ext) {
+		case "structure":
+			kind = "container"
+		case "augment-structure":
+			kind = "augment"
+		default:
+			continue
+		}
+		for _, ss := range ext.statements {
+			if !structureSubstatements[ss.Keyword] && !strings.Contains(ss.Keyword, ":") {
+				return errorf(ss, ErrUnexpectedSubstatement, "unknown %s field: %s", ext.Keyword, ss.Keyword)
+			}
+		}
+		s := *ext
+		s.Keyword = kind
+		v, err := build(&s, reflect.ValueOf(m))
+		if err != nil {
+			return err
+		}
+		switch n := v.Interface().(type) {
+		case *Container:
+			m.Structures = append(m.Structures, n)
+		case *Augment:
+			m.StructureAugments = append(m.StructureAugments, n)
+		}
+	}
+	return nil
+}
+
+// buildStructureEntries builds the Entry trees of the structures of the
+// modules of ms and then applies all the augment-structure statements to
+// them.
+func (ms *Modules) buildStructureEntries() []error {
+	var errs []error
+	mods := ms.sortedModules()
+	for _, m := range mods {
+		if len(m.Structures) == 0 {
+			continue
+		}
+		// The structures of submodules belong to their module.
+		me := ToEntry(belongsTo(m))
+		if me.Structures == nil {
+			me.Structures = map[string]*Entry{}
+		}
+		for _, c := range m.Structures {
+			se := ToEntry(c)
+			se.Parent = me
+			me.Structures[c.Name] = se
+			errs = append(errs, se.GetErrors()...)
+		}
+	}
+
+	// Augments may target nodes added by other augments, so repeat until
+	// no progress is made.
+	var augments []*Augment
+	for _, m := range mods {
+		augments = append(augments, m.StructureAugments...)
+	}
+	for len(augments) > 0 {
+		var remaining []*Augment
+		for _, a := range augments {
+			ae := ToEntry(a)
+			target := findStructureNode(a, a.Name)
+			if target == nil {
+				remaining = append(remaining, a)
+				continue
+			}
+			ae.Parent = ToEntry(RootNode(a))
+			target.merge(nil, ae.Namespace(), ae)
+			target.Augmented = append(target.Augmented, ae.shallowDup())
+			errs = append(errs, ae.GetErrors()...)
+		}
+		if len(remaining) == len(augments) {
+			for _, a := range remaining {
+				errs = append(errs, errorf(a, ErrAugmentTargetMissing, "augment-structure %s not found", a.Name))
+			}
+			break
+		}
+		augments = remaining
+	}
+
+	for _, m := range mods {
+		if m != belongsTo(m) {
+			continue
+		}
+		for _, se := range ToEntry(m).Structures {
+			se.FixChoice()
+		}
+	}
+	return errs
+}
+
+// findStructureNode returns the Entry of the structure node named by the
+// absolute path, whose first element names a structure.  The prefixes of
+// path are resolved in the context of n.  Nil is returned if the node is
+// not found.
+func findStructureNode(n Node, path string) *Entry {
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	prefix, name := getPrefix(parts[0])
+	m := FindModuleByPrefix(n, prefix)
+	if m == nil {
+		return nil
+	}
+	e := ToEntry(belongsTo(m)).Structures[name]
+	for _, p := range parts[1:] {
+		if e == nil {
+			return nil
+		}
+		_, name := getPrefix(p)
+		e = e.Dir[name]
+	}
+	return e
+}
@@ -0,0 +1,197 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+const structureExtModule = `
+module ietf-yang-structure-ext {
+	prefix "sx";
+	namespace "urn:ietf:params:xml:ns:yang:ietf-yang-structure-ext";
+	extension structure { argument name; }
+	extension augment-structure { argument path; }
+}
+`
+
+// dirNames returns the sorted names of the children of e.
+func dirNames(e *Entry) string {
+	var names []string
+	for n := range e.Dir {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return strings.Join(names, " ")
+}
+
+func TestStructures(t *testing.T) {
+	ms := NewModules()
+	for n, text := range map[string]string{
+		"ietf-yang-structure-ext": structureExtModule,
+		"sx-base": `
+			module sx-base {
+				prefix "b";
+				namespace "urn:b";
+				import ietf-yang-structure-ext { prefix "sx"; }
+				include sx-base-sub;
+				typedef name { type string { length "1..8"; } }
+				grouping g { leaf from-grouping { type name; } }
+				container data { leaf d { type string; } }
+				sx:structure message {
+					description "A message.";
+					container header {
+						leaf id { type uint32; }
+						uses g;
+					}
+					choice body {
+						leaf text { type string; }
+						leaf binary { type binary; }
+					}
+				}
+			}
+		`,
+		"sx-base-sub": `
+			submodule sx-base-sub {
+				belongs-to sx-base { prefix "b"; }
+				import ietf-yang-structure-ext { prefix "sx"; }
+				sx:structure reply { leaf code { type int8; } }
+			}
+		`,
+		"sx-aug": `
+			module sx-aug {
+				prefix "a";
+				namespace "urn:a";
+				import ietf-yang-structure-ext { prefix "sx"; }
+				import sx-base { prefix "b"; }
+				sx:augment-structure "/b:message/b:header/a:extra" {
+					leaf more { type string; }
+				}
+				sx:augment-structure "/b:message/b:header" {
+					container extra { leaf priority { type uint8; } }
+				}
+			}
+		`,
+	} {
+		if err := ms.Parse(text, n+".yang"); err != nil {
+			t.Fatalf("%s: %v", n, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	base := ToEntry(ms.Modules["sx-base"])
+	if got, want := dirNames(base), "data"; got != want {
+		t.Errorf("data nodes: got %s, want %s", got, want)
+	}
+	var names []string
+	for n := range base.Structures {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	if got, want := strings.Join(names, " "), "message reply"; got != want {
+		t.Fatalf("structures: got %s, want %s", got, want)
+	}
+
+	msg := base.Structures["message"]
+	if msg.Description != "A message." || msg.Parent != base {
+		t.Errorf("message: got description %q, parent %v", msg.Description, msg.Parent)
+	}
+	header := msg.Dir["header"]
+	if got, want := dirNames(header), "extra from-grouping id"; got != want {
+		t.Errorf("header: got %s, want %s", got, want)
+	}
+	if l := header.Dir["from-grouping"]; l == nil || l.Type == nil || l.Type.Name != "name" {
+		t.Errorf("from-grouping: typedef not resolved: %+v", l)
+	}
+	extra := header.Dir["extra"]
+	if got, want := dirNames(extra), "more priority"; got != want {
+		t.Errorf("extra: got %s, want %s", got, want)
+	}
+	if ns := extra.Namespace(); ns == nil || ns.Name != "urn:a" {
+		t.Errorf("extra: got namespace %v, want urn:a", ns)
+	}
+	// FixChoice adds the implied cases.
+	if c := msg.Dir["body"].Dir["text"]; c == nil || !c.IsCase() {
+		t.Errorf("body: implied case not added: %+v", c)
+	}
+	if reply := base.Structures["reply"]; reply == nil || dirNames(reply) != "code" {
+		t.Errorf("reply: got %+v", reply)
+	}
+}
+
+func TestStructureErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		in           string
+		wantParseErr string
+		wantErr      string
+	}{{
+		name:         "bad substatement",
+		in:           `sx:structure s { config false; }`,
+		wantParseErr: "unknown sx:structure field: config",
+	}, {
+		name:         "typedef in augment-structure",
+		in:           `sx:augment-structure "/e:s" { typedef t { type string; } }`,
+		wantParseErr: "unknown augment field: typedef",
+	}, {
+		name:    "augment not found",
+		in:      `sx:structure s { leaf l { type string; } } sx:augment-structure "/e:s/e:none" { leaf x { type string; } }`,
+		wantErr: "augment-structure /e:s/e:none not found",
+	}, {
+		name:    "unknown structure",
+		in:      `sx:augment-structure "/e:none" { leaf x { type string; } }`,
+		wantErr: "augment-structure /e:none not found",
+	}, {
+		name:    "bad type",
+		in:      `sx:structure s { leaf l { type none; } }`,
+		wantErr: "unknown type: e:none",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := NewModules()
+			if err := ms.Parse(structureExtModule, "ietf-yang-structure-ext.yang"); err != nil {
+				t.Fatal(err)
+			}
+			err := ms.Parse(`
+				module sx-err {
+					prefix "e";
+					namespace "urn:e";
+					import ietf-yang-structure-ext { prefix "sx"; }
+					`+tt.in+`
+				}
+			`, "sx-err.yang")
+			if diff := errdiff.Substring(err, tt.wantParseErr); diff != "" {
+				t.Fatal(diff)
+			}
+			if err != nil {
+				return
+			}
+			errs := ms.Process()
+			if len(errs) == 0 {
+				err = nil
+			} else {
+				err = errs[0]
+			}
+			if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the "did you mean" suggestions added to errors
+// about names that cannot be resolved.
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// didYouMean returns a suggestion of the name in candidates closest to
+// name, in the form "; did you mean x?", to be appended to an error about
+// name not being found.  The empty string is returned if no candidate is
+// close enough to name to be a likely misspelling of it.
+func didYouMean(name string, candidates []string) string {
+	if s := closest(name, candidates); s != "" {
+		return fmt.Sprintf("; did you mean %s?", s)
+	}
+	return ""
+}
+
+// closest returns the name in candidates with the smallest edit distance
+// to name, or "" if there is none within a third of the length of name.
+// Ties are broken by choosing the first candidate in sorted order.
+func closest(name string, candidates []string) string {
+	max := len(name) / 3
+	if max < 1 {
+		max = 1
+	}
+	sorted := append([]string{}, candidates...)
+	sort.Strings(sorted)
+	best, bestDist := "", max+1
+	for _, c := range sorted {
+		if c == name {
+			continue
+		}
+		if d := editDistance(name, c); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b, i.e.,
+// the number of single byte insertions, deletions, and substitutions
+// needed to change a into b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func minInt(v int, vs ...int) int {
+	for _, x := range vs {
+		if x < v {
+			v = x
+		}
+	}
+	return v
+}
+
+// prefixNames returns the prefixes known in the module or submodule that
+// n is part of.
+func prefixNames(n Node) []string {
+	root := RootNode(n)
+	names := []string{root.GetPrefix()}
+	for _, i := range root.Import {
+		names = append(names, i.Prefix.Name)
+	}
+	return names
+}
+
+// typedefNames returns the names of the typedefs that are in scope at n,
+// including the built-in types.
+func typedefNames(n Node) []string {
+	var names []string
+	for name := range BaseTypedefs {
+		names = append(names, name)
+	}
+	root := RootNode(n)
+	for ; n != nil && n != Node(root); n = n.ParentNode() {
+		names = append(names, typedefNamesIn(n)...)
+	}
+	names = append(names, typedefNamesIn(root)...)
+	for _, in := range root.Include {
+		if in.Module != nil {
+			names = append(names, typedefNamesIn(in.Module)...)
+		}
+	}
+	return names
+}
+
+// groupingNames returns the names of the groupings that name, the name of
+// a grouping that was not found, may have been meant to refer to from n.
+// If name has a prefix, the names of the groupings of the module with that
+// prefix are returned with the same prefix.
+func groupingNames(n Node, name string) []string {
+	root := RootNode(n)
+	prefix, _ := getPrefix(name)
+	if prefix != "" {
+		prefix += ":"
+	}
+	if prefix != "" && prefix != root.GetPrefix()+":" {
+		m := FindModuleByPrefix(n, strings.TrimSuffix(prefix, ":"))
+		if m == nil {
+			return nil
+		}
+		var names []string
+		for _, g := range moduleGroupings(m) {
+			names = append(names, prefix+g.Name)
+		}
+		return names
+	}
+	var names []string
+	for ; n != nil && n != Node(root); n = n.ParentNode() {
+		if v := reflect.ValueOf(n).Elem().FieldByName("Grouping"); v.IsValid() {
+			for _, g := range v.Interface().([]*Grouping) {
+				names = append(names, prefix+g.Name)
+			}
+		}
+	}
+	for _, g := range moduleGroupings(root) {
+		names = append(names, prefix+g.Name)
+	}
+	return names
+}
+
+// moduleGroupings returns the top level groupings of m and of the
+// submodules it includes.
+func moduleGroupings(m *Module) []*Grouping {
+	gs := append([]*Grouping{}, m.Grouping...)
+	for _, in := range m.Include {
+		if in.Module != nil {
+			gs = append(gs, in.Module.Grouping...)
+		}
+	}
+	return gs
+}
+
+// augmentSuggestion returns a suggestion for the first node of the path of
+// the augment a that could not be found, or "" if there is none.
+func augmentSuggestion(a *Entry) string {
+	parts := strings.Split(a.Name, "/")
+	if len(parts) < 2 || parts[0] != "" {
+		return ""
+	}
+	for i := len(parts) - 1; i > 0; i-- {
+		var parent *Entry
+		if i == 1 {
+			prefix, _ := getPrefix(parts[1])
+			if m := FindModuleByPrefix(a.Node, prefix); m != nil {
+				parent = ToEntry(m)
+			}
+		} else {
+			parent = a.Find(strings.Join(parts[:i], "/"))
+		}
+		if parent == nil {
+			continue
+		}
+		prefix, name := getPrefix(parts[i])
+		var names []string
+		for n := range parent.Dir {
+			names = append(names, n)
+		}
+		if parent.RPC != nil {
+			names = append(names, "input", "output")
+		}
+		if s := closest(name, names); s != "" {
+			if prefix != "" {
+				s = prefix + ":" + s
+			}
+			return fmt.Sprintf("; did you mean %s?", strings.Join(append(parts[:i:i], s), "/"))
+		}
+		return ""
+	}
+	return ""
+}
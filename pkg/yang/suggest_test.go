@@ -0,0 +1,127 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+func TestEditDistance(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"abc", "abc", 0},
+		{"interface", "interfaces", 1},
+		{"kitten", "sitting", 3},
+		{"counter32", "counter64", 2},
+	} {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q): got %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestClosest(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		candidates []string
+		want       string
+	}{
+		{"interfce", []string{"interfaces", "interface", "system"}, "interface"},
+		{"x", []string{"y", "z"}, "y"},
+		{"system", []string{"interfaces"}, ""},
+		{"same", []string{"same"}, ""},
+		{"", nil, ""},
+	} {
+		if got := closest(tt.name, tt.candidates); got != tt.want {
+			t.Errorf("closest(%q, %v): got %q, want %q", tt.name, tt.candidates, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestions(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		in     string
+		want   string
+		noHint bool
+	}{{
+		name: "local type",
+		in:   `typedef percent { type uint8; } leaf l { type precent; }`,
+		want: "unknown type: s:precent; did you mean percent?",
+	}, {
+		name: "builtin type",
+		in:   `leaf l { type strin; }`,
+		want: "did you mean string?",
+	}, {
+		name: "imported type",
+		in:   `import t { prefix "t"; } leaf l { type t:adress; }`,
+		want: "unknown type t:adress; did you mean address?",
+	}, {
+		name: "prefix",
+		in:   `import t { prefix "t"; } leaf l { type tt:address; }`,
+		want: "unknown prefix: tt for type address; did you mean t?",
+	}, {
+		name: "grouping",
+		in:   `grouping common { leaf l { type string; } } container c { uses comon; }`,
+		want: "unknown group: comon; did you mean common?",
+	}, {
+		name: "imported grouping",
+		in:   `import t { prefix "t"; } container c { uses t:share; }`,
+		want: "unknown group: t:share; did you mean t:shared?",
+	}, {
+		name: "augment",
+		in:   `container interfaces { container interface; } augment "/s:interfaces/s:interfce" { leaf l { type string; } }`,
+		want: "augment /s:interfaces/s:interfce not found; did you mean /s:interfaces/s:interface?",
+	}, {
+		name: "top level augment",
+		in:   `import t { prefix "t"; } augment "/t:sytem" { leaf l { type string; } }`,
+		want: "augment /t:sytem not found; did you mean /t:system?",
+	}, {
+		name:   "no suggestion",
+		in:     `leaf l { type nothing-like-it; }`,
+		want:   "unknown type: s:nothing-like-it",
+		noHint: true,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := NewModules()
+			for name, text := range map[string]string{
+				"s": `module s { prefix "s"; namespace "urn:s"; ` + tt.in + ` }`,
+				"t": `module t { prefix "t"; namespace "urn:t"; typedef address { type string; } grouping shared { leaf x { type string; } } container system; }`,
+			} {
+				if err := ms.Parse(text, name); err != nil {
+					t.Fatal(err)
+				}
+			}
+			errs := ms.Process()
+			if len(errs) == 0 {
+				t.Fatalf("got no errors, want %q", tt.want)
+			}
+			if diff := errdiff.Substring(errs, tt.want); diff != "" {
+				t.Error(diff)
+			}
+			if tt.noHint && strings.Contains(errs.Error(), "did you mean") {
+				t.Errorf("got %q, want no suggestion", errs.Error())
+			}
+		})
+	}
+}
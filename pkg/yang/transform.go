@@ -0,0 +1,138 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements programmatic schema transforms, which are applied
+// to the Entry trees of modules after deviations.
+
+import "sort"
+
+// A Transform modifies the Entry tree of a module.  Transforms are applied
+// by Process once deviations have been applied, so they can be used in
+// place of deviation modules that would be tedious to write by hand.  e is
+// the Entry of a module, Transform is called once for each module.
+type Transform interface {
+	Transform(e *Entry) error
+}
+
+// A TransformFunc is a function used as a Transform.
+type TransformFunc func(e *Entry) error
+
+// Transform calls f(e).
+func (f TransformFunc) Transform(e *Entry) error {
+	return f(e)
+}
+
+// AddTransform adds t to the transforms applied when ms is processed.
+// Transforms are applied in the order they were added.
+func (ms *Modules) AddTransform(t Transform) {
+	ms.transforms = append(ms.transforms, t)
+}
+
+// applyTransforms applies the transforms of ms to the entries of each of
+// its modules.
+func (ms *Modules) applyTransforms() []error {
+	if len(ms.transforms) == 0 {
+		return nil
+	}
+	var errs []error
+	for _, m := range ms.sortedModules() {
+		if m.Kind() != "module" {
+			continue
+		}
+		e := ToEntry(m)
+		for _, t := range ms.transforms {
+			if err := t.Transform(e); err != nil {
+				errs = append(errs, errorf(m, ErrTransform, "transform: %v", err))
+			}
+		}
+	}
+	return errs
+}
+
+// walkEntries calls fn for e and all of its descendants, including the
+// input and output of RPCs, in name order.  The children of an entry are
+// not visited if fn returns false for it.
+func walkEntries(e *Entry, fn func(*Entry) bool) {
+	if !fn(e) {
+		return
+	}
+	names := make([]string, 0, len(e.Dir))
+	for n := range e.Dir {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		walkEntries(e.Dir[n], fn)
+	}
+	if e.RPC != nil {
+		for _, ce := range []*Entry{e.RPC.Input, e.RPC.Output} {
+			if ce != nil {
+				walkEntries(ce, fn)
+			}
+		}
+	}
+}
+
+// MarkConfigFalse returns a Transform that makes the entries with the
+// given paths, and their descendants, state rather than configuration.
+// Paths are as returned by the Path method of Entry, e.g., "/module/a/b".
+// Paths that are not found in a module are ignored.
+func MarkConfigFalse(paths ...string) Transform {
+	want := map[string]bool{}
+	for _, p := range paths {
+		want[p] = true
+	}
+	return TransformFunc(func(e *Entry) error {
+		walkEntries(e, func(e *Entry) bool {
+			if !want[e.Path()] {
+				return true
+			}
+			walkEntries(e, func(ce *Entry) bool {
+				if ce == e || ce.Config == TSTrue {
+					ce.Config = TSFalse
+				}
+				return true
+			})
+			return false
+		})
+		return nil
+	})
+}
+
+// StripNodes returns a Transform that removes each entry for which match
+// returns true, along with its descendants.  The module entry itself is
+// never removed.
+func StripNodes(match func(*Entry) bool) Transform {
+	return TransformFunc(func(e *Entry) error {
+		walkEntries(e, func(e *Entry) bool {
+			for n, ce := range e.Dir {
+				if match(ce) {
+					e.delete(n)
+				}
+			}
+			if e.RPC != nil {
+				if e.RPC.Input != nil && match(e.RPC.Input) {
+					e.RPC.Input = nil
+				}
+				if e.RPC.Output != nil && match(e.RPC.Output) {
+					e.RPC.Output = nil
+				}
+			}
+			return true
+		})
+		return nil
+	})
+}
@@ -0,0 +1,114 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+const transformModule = `
+module tr {
+	prefix "t";
+	namespace "urn:t";
+	container system {
+		leaf hostname { type string; }
+		container debug {
+			leaf level { type uint8; }
+			container forced { config true; leaf on { type boolean; } }
+		}
+		leaf vendor-secret { type string; }
+	}
+	container other { leaf vendor-flag { type boolean; } }
+	rpc reset { input { leaf vendor-arg { type string; } } }
+	deviation "/t:system/t:hostname" { deviate add { units "chars"; } }
+}
+`
+
+func processTransforms(t *testing.T, ts ...Transform) (*Entry, []error) {
+	t.Helper()
+	ms := NewModules()
+	if err := ms.Parse(transformModule, "tr.yang"); err != nil {
+		t.Fatal(err)
+	}
+	for _, tr := range ts {
+		ms.AddTransform(tr)
+	}
+	errs := ms.Process()
+	return ToEntry(ms.Modules["tr"]), errs
+}
+
+func TestTransforms(t *testing.T) {
+	var sawUnits string
+	e, errs := processTransforms(t,
+		// Transforms run after deviations.
+		TransformFunc(func(e *Entry) error {
+			sawUnits = e.Find("/system/hostname").Units
+			return nil
+		}),
+		MarkConfigFalse("/tr/system/debug", "/tr/none"),
+		StripNodes(func(e *Entry) bool { return strings.HasPrefix(e.Name, "vendor-") }),
+	)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if sawUnits != "chars" {
+		t.Errorf("transform saw units %q, want %q", sawUnits, "chars")
+	}
+
+	for _, tt := range []struct {
+		path     string
+		readOnly bool
+	}{
+		{"/system/hostname", false},
+		{"/system/debug", true},
+		{"/system/debug/level", true},
+		{"/system/debug/forced", true},
+		{"/system/debug/forced/on", true},
+	} {
+		ce := e.Find(tt.path)
+		if ce == nil {
+			t.Errorf("%s: not found", tt.path)
+			continue
+		}
+		if got := ce.ReadOnly(); got != tt.readOnly {
+			t.Errorf("%s: ReadOnly got %v, want %v", tt.path, got, tt.readOnly)
+		}
+	}
+
+	for _, p := range []string{"/system/vendor-secret", "/other/vendor-flag", "/reset/input/vendor-arg"} {
+		if ce := e.Find(p); ce != nil {
+			t.Errorf("%s: not stripped", p)
+		}
+	}
+	if e.Find("/other") == nil || e.Find("/reset/input") == nil {
+		t.Errorf("parents of stripped nodes were removed")
+	}
+}
+
+func TestTransformErrors(t *testing.T) {
+	_, errs := processTransforms(t, TransformFunc(func(e *Entry) error {
+		return errors.New("refused")
+	}))
+	if len(errs) != 1 {
+		t.Fatalf("got errors %v, want 1", errs)
+	}
+	if diff := errdiff.Substring(errs[0], "tr.yang:2:1: transform: refused"); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements listing the typedefs and identities of a set of
+// modules.
+
+import "sort"
+
+// Typedefs returns all the typedefs defined in the modules and submodules
+// of ms, including typedefs nested in other statements, in the same order
+// as Groupings.
+func (ms *Modules) Typedefs() []*Typedef {
+	var ts []*Typedef
+	for _, m := range ms.sortedModules() {
+		start := len(ts)
+		walkNodes(m, func(n Node) {
+			if t, ok := n.(*Typedef); ok {
+				ts = append(ts, t)
+			}
+		})
+		mts := ts[start:]
+		sort.SliceStable(mts, func(i, j int) bool { return before(mts[i], mts[j]) })
+	}
+	return ts
+}
+
+// Identities returns all the identities defined in the modules and
+// submodules of ms, in the same order as Groupings.
+func (ms *Modules) Identities() []*Identity {
+	var ids []*Identity
+	for _, m := range ms.sortedModules() {
+		ids = append(ids, m.Identity...)
+	}
+	return ids
+}
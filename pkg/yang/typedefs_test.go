@@ -0,0 +1,81 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTypedefsAndIdentities(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"t": `module t {
+  prefix "t";
+  namespace "urn:t";
+  include t-sub;
+  typedef top { type string; }
+  identity base;
+  identity derived { base base; }
+  container c {
+    typedef nested { type int8; }
+    leaf l { type nested; }
+  }
+  grouping g {
+    typedef in-grouping { type top; }
+  }
+}`,
+		"t-sub": `submodule t-sub {
+  belongs-to t { prefix "t"; }
+  typedef from-sub { type string; }
+  identity sub-id;
+}`,
+	} {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	var got []string
+	for _, td := range ms.Typedefs() {
+		got = append(got, td.Name+" "+Source(td))
+	}
+	want := []string{
+		"top t.yang:5:3",
+		"nested t.yang:9:5",
+		"in-grouping t.yang:13:5",
+		"from-sub t-sub.yang:3:3",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Typedefs() (-want, +got):\n%s", diff)
+	}
+
+	got = nil
+	for _, id := range ms.Identities() {
+		got = append(got, id.Name+" "+Source(id))
+	}
+	want = []string{
+		"base t.yang:6:3",
+		"derived t.yang:7:3",
+		"sub-id t-sub.yang:4:3",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Identities() (-want, +got):\n%s", diff)
+	}
+}
@@ -20,99 +20,111 @@ import (
 	"errors"
 	"fmt"
 	"regexp/syntax"
-	"sync"
+	"sort"
 )
 
-// A typeDictionary is a dictionary of all Typedefs defined in all Typedefers.
-// A map of Nodes is used rather than a map of Typedefers to simplify usage
-// when traversing up a Node tree.
-type typeDictionary struct {
-	mu   sync.Mutex
-	dict map[Node]map[string]*Typedef
-}
-
-// typeDict is a protected global dictionary of all typedefs.
-// TODO(borman): should this be made as part of some other structure, rather
-// than a singleton.  That can be done later when we replumb everything to more
-// or less pass around an extra pointer.  That is not needed until such time
-// that we plan for a single application to process completely independent YANG
-// modules where there may be conflicts between the modules or we plan to
-// process them completely independently of eachother.
-var typeDict = typeDictionary{dict: map[Node]map[string]*Typedef{}}
-
-// add adds an entry to the typeDictionary d.
-func (d *typeDictionary) add(n Node, name string, td *Typedef) {
-	defer d.mu.Unlock()
-	d.mu.Lock()
-	if d.dict[n] == nil {
-		d.dict[n] = map[string]*Typedef{}
-	}
-	d.dict[n][name] = td
-}
-
-// find returns the Typedef name define in node n, or nil.
-func (d *typeDictionary) find(n Node, name string) *Typedef {
-	defer d.mu.Unlock()
-	d.mu.Lock()
-	if d.dict[n] == nil {
+// findTypedef returns the typedef name defined in node n, or nil.  The
+// typedefs are found directly in n, rather than in a dictionary shared by all
+// modules, so that independently read modules cannot see each other's
+// typedefs.
+func findTypedef(n Node, name string) *Typedef {
+	t, ok := n.(Typedefer)
+	if !ok {
 		return nil
 	}
-	return d.dict[n][name]
+	tds := t.Typedefs()
+	// When a name is defined twice the last definition is used.
+	for i := len(tds) - 1; i >= 0; i-- {
+		if tds[i].Name == name {
+			return tds[i]
+		}
+	}
+	return nil
 }
 
-// findExternal finds the externally defined typedef name in the module imported
-// by n's root with the specified prefix.
-func (d *typeDictionary) findExternal(n Node, prefix, name string) (*Typedef, error) {
+// findExternalTypedef finds the externally defined typedef name in the
+// module imported by n's root with the specified prefix.
+func findExternalTypedef(n Node, prefix, name string) (*Typedef, error) {
 	root := FindModuleByPrefix(n, prefix)
 	if root == nil {
-		return nil, fmt.Errorf("%s: unknown prefix: %s for type %s", Source(n), prefix, name)
+		return nil, errorf(n, ErrUnknownPrefix, "unknown prefix: %s for type %s%s", prefix, name, didYouMean(prefix, prefixNames(n)))
 	}
-	if td := d.find(root, name); td != nil {
+	if td := findTypedef(root, name); td != nil {
 		return td, nil
 	}
+	hint := didYouMean(name, typedefNamesIn(root))
 	if prefix != "" {
 		name = prefix + ":" + name
 	}
-	return nil, fmt.Errorf("%s: unknown type %s", Source(n), name)
+	return nil, errorf(n, ErrUnknownType, "unknown type %s%s", name, hint)
 }
 
-// typedefs returns a slice of all typedefs in d.
-func (d *typeDictionary) typedefs() []*Typedef {
-	var tds []*Typedef
-	defer d.mu.Unlock()
-	d.mu.Lock()
-	for _, dict := range d.dict {
-		for _, td := range dict {
-			tds = append(tds, td)
-		}
+// typedefNamesIn returns the names of the typedefs defined in node n.
+func typedefNamesIn(n Node) []string {
+	t, ok := n.(Typedefer)
+	if !ok {
+		return nil
 	}
-	return tds
-}
-
-// addTypedefs is called from BuildAST after each Typedefer is defined.  There
-// are no error conditions in this process as it is simply used to build up the
-// typedef dictionary.
-func addTypedefs(t Typedefer) {
+	var names []string
 	for _, td := range t.Typedefs() {
-		typeDict.add(t, td.Name, td)
+		names = append(names, td.Name)
 	}
+	return names
+}
+
+// typedefs returns all the typedefs defined in the modules and submodules
+// of ms.
+func (ms *Modules) typedefs() []*Typedef {
+	var tds []*Typedef
+	for _, m := range ms.sortedModules() {
+		walkNodes(m, func(n Node) {
+			if t, ok := n.(Typedefer); ok {
+				tds = append(tds, t.Typedefs()...)
+			}
+		})
+	}
+	// Resolve the typedefs in a fixed order so the same errors are found
+	// each time.
+	sort.Slice(tds, func(i, j int) bool {
+		si, sj := Source(tds[i]), Source(tds[j])
+		if si != sj {
+			return si < sj
+		}
+		return tds[i].Name < tds[j].Name
+	})
+	return tds
 }
 
 // resolveTypedefs is called after all of modules and submodules have been read,
 // as well as their imports and includes.  It resolves all typedefs found in all
-// modules and submodules read in.
-func resolveTypedefs() []error {
+// modules and submodules of ms.
+func (ms *Modules) resolveTypedefs() []error {
 	var errs []error
-
-	// When resolve typedefs, we may need to look up other typedefs.
-	// We gather all typedefs into a slice so we don't deadlock on
-	// typeDict.
-	for _, td := range typeDict.typedefs() {
+	for _, td := range ms.typedefs() {
 		errs = append(errs, td.resolve()...)
+		if ms.tooManyErrors(errs) {
+			break
+		}
 	}
 	return errs
 }
 
+// typedefsInUse returns the typedefs currently being resolved in the
+// Modules that t was read into, or in the module of t if it is not part of
+// a Modules.  It is used to detect typedefs that are based on themselves.
+func typedefsInUse(t *Typedef) map[*Typedef]bool {
+	m := RootNode(t)
+	switch {
+	case m == nil:
+		return map[*Typedef]bool{}
+	case m.modules != nil && m.modules.typedefsInUse != nil:
+		return m.modules.typedefsInUse
+	case m.typedefsInUse == nil:
+		m.typedefsInUse = map[*Typedef]bool{}
+	}
+	return m.typedefsInUse
+}
+
 // resolve creates a YangType for t, if not already done.  Resolving t
 // requires resolving the Type that t is based on.
 func (t *Typedef) resolve() []error {
@@ -121,6 +133,12 @@ func (t *Typedef) resolve() []error {
 	if t.Parent == nil || t.YangType != nil {
 		return nil
 	}
+	inUse := typedefsInUse(t)
+	if inUse[t] {
+		return []error{errorf(t, ErrRecursiveType, "typedef %s is based on itself", t.Name)}
+	}
+	inUse[t] = true
+	defer delete(inUse, t)
 
 	if errs := t.Type.resolve(); len(errs) != 0 {
 		return errs
@@ -144,7 +162,7 @@ func (t *Typedef) resolve() []error {
 		if idBase, err := RootNode(t).findIdentityBase(t.Type.IdentityBase.Name); err == nil {
 			y.IdentityBase = idBase.Identity
 		} else {
-			return []error{fmt.Errorf("could not resolve identity base for typedef: %s", t.Type.IdentityBase.Name)}
+			return []error{errorf(nil, ErrUnknownIdentity, "could not resolve identity base for typedef: %s", t.Type.IdentityBase.Name)}
 		}
 	}
 
@@ -181,14 +199,27 @@ check:
 		source = "local"
 		// If we have no prefix, or the prefix is what we call our own
 		// root, then we look in our ancestors for a typedef of name.
-		for n := Node(t); n != nil; n = n.ParentNode() {
-			if td = typeDict.find(n, name); td != nil {
+		// The module itself is checked last as lookups at module scope
+		// are the same for every type in the module and are cached.
+		for n := Node(t); n != nil && n != Node(root); n = n.ParentNode() {
+			if td = findTypedef(n, name); td != nil {
 				break check
 			}
 		}
+		if td = cachedTypedef(root, name); td != nil {
+			break check
+		}
+		if td = findTypedef(root, name); td != nil {
+			cacheTypedef(root, name, td)
+			break check
+		}
 		// We need to check our sub-modules as well
 		for _, in := range root.Include {
-			if td = typeDict.find(in.Module, name); td != nil {
+			if in.Module == nil {
+				continue
+			}
+			if td = findTypedef(in.Module, name); td != nil {
+				cacheTypedef(root, name, td)
 				break check
 			}
 		}
@@ -200,18 +231,27 @@ check:
 			pname = fmt.Sprintf("%s[%s]:%s", prefix, root.Prefix.Name, t.Name)
 		}
 
-		return []error{fmt.Errorf("%s: unknown type: %s", Source(t), pname)}
+		return []error{errorf(t, ErrUnknownType, "unknown type: %s%s", pname, didYouMean(name, typedefNames(t)))}
 
 	default:
 		source = "imported"
 		// prefix is not local to our module, so we have to go find
 		// what module it is part of and if it is defined at the top
 		// level of that module.
+		if td = cachedTypedef(root, t.Name); td != nil {
+			break
+		}
 		var err error
-		td, err = typeDict.findExternal(t, prefix, name)
+		td, err = findExternalTypedef(t, prefix, name)
 		if err != nil {
 			return []error{err}
 		}
+		cacheTypedef(root, t.Name, td)
+	}
+	if source == "builtin" {
+		tracef(t, "type %s is builtin", t.Name)
+	} else {
+		tracef(t, "type %s is %s typedef %s at %s", t.Name, source, td.Name, Source(td))
 	}
 	if errs := td.resolve(); len(errs) > 0 {
 		return errs
@@ -220,11 +260,14 @@ check:
 	// Make a copy of the typedef we are based on so we can
 	// augment it.
 	if td.YangType == nil {
-		return []error{fmt.Errorf("%s: no YangType defined for %s %s", Source(td), source, td.Name)}
+		return []error{errorf(td, ErrUnknownType, "no YangType defined for %s %s", source, td.Name)}
 	}
 	y := *td.YangType
 
 	y.Base = td.Type
+	// Patterns may be added below, so y cannot share the compiled
+	// patterns of td.
+	y.patterns = nil
 	t.YangType = &y
 
 	if v := t.RequireInstance; v != nil {
@@ -241,7 +284,7 @@ check:
 	switch {
 	case isDecimal64 && y.FractionDigits != 0:
 		if t.FractionDigits != nil {
-			return append(errs, fmt.Errorf("%s: overriding of fraction-digits not allowed", Source(t)))
+			return append(errs, errorf(t, ErrBadFractionDigits, "overriding of fraction-digits not allowed"))
 		}
 		// FractionDigits already set via type inheritance.
 	case isDecimal64:
@@ -249,11 +292,11 @@ check:
 		// fraction-digits in the range from 1-18.
 		i, err := t.FractionDigits.asRangeInt(1, 18)
 		if err != nil {
-			errs = append(errs, fmt.Errorf("%s: %v", Source(t), err))
+			errs = append(errs, errorf(t, ErrBadFractionDigits, "%v", err))
 		}
 		y.FractionDigits = int(i)
 	case t.FractionDigits != nil:
-		errs = append(errs, fmt.Errorf("%s: fraction-digits only allowed for decimal64 values", Source(t)))
+		errs = append(errs, errorf(t, ErrBadFractionDigits, "fraction-digits only allowed for decimal64 values"))
 	case y.Kind == Yidentityref:
 		if source != "builtin" {
 			// This is a typedef that refers to an identityref, so we want to simply
@@ -262,7 +305,7 @@ check:
 		}
 
 		if t.IdentityBase == nil {
-			errs = append(errs, fmt.Errorf("%s: an identityref must specify a base", Source(t)))
+			errs = append(errs, errorf(t, ErrMissingSubstatement, "an identityref must specify a base"))
 			break
 		}
 
@@ -274,7 +317,7 @@ check:
 		}
 
 		if resolvedBase.Identity == nil {
-			errs = append(errs, fmt.Errorf("%s: identity has a null base", t.IdentityBase.Name))
+			errs = append(errs, errorf(nil, ErrUnknownIdentity, "%s: identity has a null base", t.IdentityBase.Name))
 			break
 		}
 		y.IdentityBase = resolvedBase.Identity
@@ -284,9 +327,9 @@ check:
 		yr, err := parseRanges(t.Range.Name, isDecimal64, uint8(y.FractionDigits))
 		switch {
 		case err != nil:
-			errs = append(errs, fmt.Errorf("%s: bad range: %v", Source(t.Range), err))
+			errs = append(errs, errorf(t.Range, ErrBadRange, "bad range: %v", err))
 		case !y.Range.Contains(yr):
-			errs = append(errs, fmt.Errorf("%s: bad range: %v not within %v", Source(t.Range), yr, y.Range))
+			errs = append(errs, errorf(t.Range, ErrBadRange, "bad range: %v not within %v", yr, y.Range))
 		case yr.Equal(y.Range):
 		default:
 			y.Range = yr
@@ -297,14 +340,14 @@ check:
 		yr, err := ParseRangesInt(t.Length.Name)
 		switch {
 		case err != nil:
-			errs = append(errs, fmt.Errorf("%s: bad length: %v", Source(t.Length), err))
+			errs = append(errs, errorf(t.Length, ErrBadLength, "bad length: %v", err))
 		case !y.Length.Contains(yr):
-			errs = append(errs, fmt.Errorf("%s: bad length: %v not within %v", Source(t.Length), yr, y.Length))
+			errs = append(errs, errorf(t.Length, ErrBadLength, "bad length: %v not within %v", yr, y.Length))
 		case yr.Equal(y.Length):
 		default:
 			for _, r := range yr {
 				if r.Min.Kind == Negative {
-					errs = append(errs, fmt.Errorf("%s: negative length: %v", Source(t.Length), yr))
+					errs = append(errs, errorf(t.Length, ErrBadLength, "negative length: %v", yr))
 					break
 				}
 			}
@@ -331,7 +374,7 @@ check:
 		enum := NewEnumType()
 		for _, e := range t.Enum {
 			if err := set(enum, e.Name, e.Value); err != nil {
-				errs = append(errs, fmt.Errorf("%s: %v", Source(e), err))
+				errs = append(errs, errorf(e, ErrBadEnum, "%v", err))
 			}
 		}
 		y.Enum = enum
@@ -341,7 +384,7 @@ check:
 		bit := NewBitfield()
 		for _, e := range t.Bit {
 			if err := set(bit, e.Name, e.Position); err != nil {
-				errs = append(errs, fmt.Errorf("%s: %v", Source(e), err))
+				errs = append(errs, errorf(e, ErrBadEnum, "%v", err))
 			}
 		}
 		y.Bit = bit
@@ -359,10 +402,27 @@ check:
 	for _, p := range y.POSIXPattern {
 		seenPOSIXPatterns[p] = true
 	}
+	seenInvertedPatterns := map[string]bool{}
+	for _, p := range y.InvertedPattern {
+		seenInvertedPatterns[p] = true
+	}
 
 	// First parse out the pattern statements.
 	// These patterns are not checked because there is no support for W3C regexes by Go.
+	// A pattern with "modifier invert-match" (YANG 1.1 section 9.4.6)
+	// must not be matched, so it is kept apart from the other patterns.
 	for _, pv := range t.Pattern {
+		if pv.Modifier != nil {
+			if pv.Modifier.Name != "invert-match" {
+				errs = append(errs, errorf(pv.Modifier, ErrInvalidArgument, "invalid modifier: %s", pv.Modifier.Name))
+				continue
+			}
+			if !seenInvertedPatterns[pv.Name] {
+				seenInvertedPatterns[pv.Name] = true
+				y.InvertedPattern = append(y.InvertedPattern, pv.Name)
+			}
+			continue
+		}
 		if !seenPatterns[pv.Name] {
 			seenPatterns[pv.Name] = true
 			y.Pattern = append(y.Pattern, pv.Name)
@@ -383,7 +443,7 @@ check:
 				// the error, re.Code is the real error.
 				err = errors.New(re.Code.String())
 			}
-			errs = append(errs, fmt.Errorf("%s: bad pattern: %v: %s", Source(n), err, p))
+			errs = append(errs, errorf(n, ErrBadPattern, "bad pattern: %v: %s", err, p))
 		}
 	}
 	for _, ext := range posixPatterns {
@@ -393,19 +453,31 @@ check:
 			y.POSIXPattern = append(y.POSIXPattern, ext.Argument)
 		}
 	}
-
-	// I don't know of an easy way to use a type as a key to a map,
-	// so we have to check equality the hard way.
-looking:
-	for _, ut := range t.Type {
-		errs = append(errs, ut.resolve()...)
-		if ut.YangType != nil {
-			for _, yt := range y.Type {
-				if ut.YangType.Equal(yt) {
-					continue looking
-				}
+	if len(y.Pattern) > 0 || len(y.POSIXPattern) > 0 || len(y.InvertedPattern) > 0 {
+		y.patterns = &lazyPatterns{}
+	}
+
+	// Add each distinct member type of a union only once.  Unions may
+	// have dozens of members, so rather than comparing each new member
+	// with all the previous ones, members are indexed by a key that is
+	// the same for Equal types.
+	if len(t.Type) > 0 {
+		// y.Type may be shared with the type y was copied from.
+		y.Type = append([]*YangType(nil), y.Type...)
+		keys := map[*YangType]string{}
+		members := map[string]bool{}
+		for _, yt := range y.Type {
+			members[yt.equalKey(keys)] = true
+		}
+		for _, ut := range t.Type {
+			errs = append(errs, ut.resolve()...)
+			if ut.YangType == nil {
+				continue
+			}
+			if k := ut.YangType.equalKey(keys); !members[k] {
+				members[k] = true
+				y.Type = append(y.Type, ut.YangType)
 			}
-			y.Type = append(y.Type, ut.YangType)
 		}
 	}
 
@@ -414,5 +486,9 @@ looking:
 		y.Root = &y
 	}
 
+	// Share the type with all other uses of an identical type.
+	if len(errs) == 0 {
+		t.YangType = sharedType(root, t.YangType)
+	}
 	return errs
 }
@@ -286,8 +286,11 @@ type YangType struct {
 	Path             string      `json:",omitempty"` // the path in a leafref
 	Pattern          []string    `json:",omitempty"` // limiting XSD-TYPES expressions on strings
 	POSIXPattern     []string    `json:",omitempty"` // limiting POSIX ERE on strings (specified by openconfig-extensions:posix-pattern)
+	InvertedPattern  []string    `json:",omitempty"` // XSD-TYPES expressions strings must not match (modifier invert-match)
 	Range            YangRange   `json:",omitempty"` // range for integers
 	Type             []*YangType `json:",omitempty"` // for unions
+
+	patterns *lazyPatterns // compiled patterns, see CompiledPatterns
 }
 
 // BaseTypedefs is a map of all base types to the Typedef structure manufactured
@@ -339,6 +342,10 @@ func tsEqual(t1, t2 []*YangType) bool {
 
 // Equal returns true if y and t describe the same type.
 func (y *YangType) Equal(t *YangType) bool {
+	if y == t {
+		// Identical resolved types are shared.
+		return true
+	}
 	switch {
 	case
 		// Don't check the Name, it contains no information
@@ -352,6 +359,7 @@ func (y *YangType) Equal(t *YangType) bool {
 		y.OptionalInstance != t.OptionalInstance,
 		y.Path != t.Path,
 		!ssEqual(y.Pattern, t.Pattern),
+		!ssEqual(y.InvertedPattern, t.InvertedPattern),
 		len(y.Range) != len(t.Range),
 		!y.Range.Equal(t.Range),
 		!tsEqual(y.Type, t.Type):
@@ -362,6 +370,33 @@ func (y *YangType) Equal(t *YangType) bool {
 	return true
 }
 
+// equalKey returns a key for y such that two types have the same key if
+// and only if they are Equal.  Keys of union members are memoized in keys,
+// as members are commonly shared between unions.
+func (y *YangType) equalKey(keys map[*YangType]string) string {
+	if k, ok := keys[y]; ok {
+		return k
+	}
+	// A nil and an empty range are Equal.
+	rangeKey := func(r YangRange) string {
+		if len(r) == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%#v", r)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d\x00%q\x00%q\x00%d\x00%p\x00%s\x00%v\x00%q\x00%q\x00%q\x00%s\x00[",
+		y.Kind, y.Units, y.Default, y.FractionDigits, y.IdentityBase, rangeKey(y.Length),
+		y.OptionalInstance, y.Path, y.Pattern, y.InvertedPattern, rangeKey(y.Range))
+	for _, ut := range y.Type {
+		fmt.Fprintf(&b, "%q,", ut.equalKey(keys))
+	}
+	b.WriteString("]")
+	k := b.String()
+	keys[y] = k
+	return k
+}
+
 // Install builtin types as know types
 func init() {
 	for k, v := range baseTypes {
@@ -46,26 +46,26 @@ func TestTypeResolve(t *testing.T) {
 				Name:           "boolean",
 				FractionDigits: &Value{Name: "42"},
 			},
-			err: "unknown: fraction-digits only allowed for decimal64 values",
+			err: "type boolean: fraction-digits only allowed for decimal64 values",
 		},
 		{
 			in: &Type{
 				Name: "decimal64",
 			},
-			err: "unknown: value is required in the range of [1..18]",
+			err: "type decimal64: value is required in the range of [1..18]",
 		},
 		{
 			in: &Type{
 				Name: "identityref",
 			},
-			err: "unknown: an identityref must specify a base",
+			err: "type identityref: an identityref must specify a base",
 		},
 		{
 			in: &Type{
 				Name:           "decimal64",
 				FractionDigits: &Value{Name: "42"},
 			},
-			err: "unknown: value 42 out of range [1..18]",
+			err: "type decimal64: value 42 out of range [1..18]",
 		},
 		{
 			in: &Type{
@@ -109,12 +109,13 @@ func TestTypeResolve(t *testing.T) {
 
 func TestPattern(t *testing.T) {
 	tests := []struct {
-		desc                string
-		inGetFn             func(*Modules) (*YangType, error)
-		leafNode            string
-		wantPatternsRegular []string
-		wantPatternsPOSIX   []string
-		wantErrSubstr       string
+		desc                 string
+		inGetFn              func(*Modules) (*YangType, error)
+		leafNode             string
+		wantPatternsRegular  []string
+		wantPatternsPOSIX    []string
+		wantPatternsInverted []string
+		wantErrSubstr        string
 	}{{
 		desc: "Only normal patterns",
 		leafNode: `
@@ -343,6 +344,57 @@ func TestPattern(t *testing.T) {
 			return e.Dir["test-leaf"].Type, nil
 		},
 		wantErrSubstr: "bad pattern",
+	}, {
+		desc: "Inverted patterns",
+		leafNode: `
+			leaf test-leaf {
+				type string {
+					pattern 'alpha';
+					pattern 'bravo' {
+						modifier invert-match;
+					}
+					pattern 'charlie' {
+						modifier "invert-match";
+					}
+				}
+			}
+		} // end module`,
+		inGetFn: func(ms *Modules) (*YangType, error) {
+			m, err := ms.FindModuleByPrefix("t")
+			if err != nil {
+				return nil, fmt.Errorf("can't find module in %v", ms)
+			}
+			if len(m.Leaf) == 0 {
+				return nil, fmt.Errorf("node %v is missing imports", m)
+			}
+			e := ToEntry(m)
+			return e.Dir["test-leaf"].Type, nil
+		},
+		wantPatternsRegular:  []string{"alpha"},
+		wantPatternsInverted: []string{"bravo", "charlie"},
+	}, {
+		desc: "Bad modifier",
+		leafNode: `
+			leaf test-leaf {
+				type string {
+					pattern 'alpha' {
+						modifier invert;
+					}
+				}
+			}
+		} // end module`,
+		inGetFn: func(ms *Modules) (*YangType, error) {
+			m, err := ms.FindModuleByPrefix("t")
+			if err != nil {
+				return nil, fmt.Errorf("can't find module in %v", ms)
+			}
+			if len(m.Leaf) == 0 {
+				return nil, fmt.Errorf("node %v is missing imports", m)
+			}
+			e := ToEntry(m)
+			return e.Dir["test-leaf"].Type, nil
+		},
+		wantErrSubstr: "invalid modifier: invert",
 	}}
 
 	for _, tt := range tests {
@@ -427,6 +479,107 @@ func TestPattern(t *testing.T) {
 			if diff := cmp.Diff(yangType.POSIXPattern, tt.wantPatternsPOSIX, cmpopts.EquateEmpty()); diff != "" {
 				t.Errorf("Type.resolve() posix-pattern test (-got, +want):\n%s", diff)
 			}
+
+			sort.Strings(yangType.InvertedPattern)
+			sort.Strings(tt.wantPatternsInverted)
+			if diff := cmp.Diff(yangType.InvertedPattern, tt.wantPatternsInverted, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Type.resolve() inverted pattern test (-got, +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWideUnion(t *testing.T) {
+	// Build a union of n distinct members, each of which appears twice.
+	const n = 100
+	members := ""
+	for i := 0; i < 2*n; i++ {
+		members += fmt.Sprintf("type string { length \"%d\"; }\n", i%n)
+	}
+	ms := NewModules()
+	if err := ms.Parse(fmt.Sprintf(`
+		module wide-union {
+			prefix "w";
+			namespace "urn:w";
+			typedef wide { type union { %s type int8; type int8; } }
+			leaf a { type wide; }
+			leaf b { type wide; }
+		}`, members), "wide-union.yang"); err != nil {
+		t.Fatalf("cannot parse: %v", err)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	e := ToEntry(ms.Modules["wide-union"])
+	a, b := e.Dir["a"].Type, e.Dir["b"].Type
+	if got := len(a.Type); got != n+1 {
+		t.Errorf("got %d union members, want %d", got, n+1)
+	}
+	for i, ut := range a.Type {
+		if ut != b.Type[i] {
+			t.Errorf("member %d is not shared between leaves", i)
+		}
+	}
+}
+
+func TestEqualKey(t *testing.T) {
+	str := func(min, max int64) *YangType {
+		return &YangType{Name: "string", Kind: Ystring, Length: YangRange{R(min, max)}}
+	}
+	for _, tt := range []struct {
+		desc string
+		a, b *YangType
+	}{
+		{"same", &YangType{Name: "string", Kind: Ystring}, &YangType{Name: "string", Kind: Ystring}},
+		{"different names", &YangType{Name: "a", Kind: Ystring}, &YangType{Name: "b", Kind: Ystring}},
+		{"empty and nil range", &YangType{Kind: Yint8, Range: YangRange{}}, &YangType{Kind: Yint8}},
+		{"different kinds", &YangType{Kind: Yint8}, &YangType{Kind: Yint16}},
+		{"different lengths", str(1, 2), str(1, 3)},
+		{"different defaults", &YangType{Kind: Yint8, Default: "1"}, &YangType{Kind: Yint8}},
+		{"same unions", &YangType{Kind: Yunion, Type: []*YangType{str(1, 1), str(2, 2)}}, &YangType{Kind: Yunion, Type: []*YangType{str(1, 1), str(2, 2)}}},
+		{"different unions", &YangType{Kind: Yunion, Type: []*YangType{str(1, 1), str(2, 2)}}, &YangType{Kind: Yunion, Type: []*YangType{str(1, 1), str(3, 3)}}},
+	} {
+		keys := map[*YangType]string{}
+		want := tt.a.Equal(tt.b)
+		if got := tt.a.equalKey(keys) == tt.b.equalKey(keys); got != want {
+			t.Errorf("%s: got equal keys %v, Equal returned %v", tt.desc, got, want)
+		}
+	}
+}
+
+func TestRecursiveTypedef(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		in   string
+		want string
+	}{{
+		name: "self",
+		in:   `typedef t { type t; } leaf l { type t; }`,
+		want: "typedef t is based on itself",
+	}, {
+		name: "loop",
+		in:   `typedef t { type u; } typedef u { type t; } leaf l { type t; }`,
+		want: "is based on itself",
+	}, {
+		name: "union",
+		in:   `typedef t { type union { type string; type t; } } leaf l { type t; }`,
+		want: "typedef t is based on itself",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := NewModules()
+			if err := ms.Parse(`module recursive { prefix "r"; namespace "urn:r"; `+tt.in+` }`, "recursive.yang"); err != nil {
+				t.Fatal(err)
+			}
+			errs := ms.Process()
+			if len(errs) == 0 {
+				t.Fatalf("got no errors, want %q", tt.want)
+			}
+			if diff := errdiff.Substring(errs[0], tt.want); diff != "" {
+				t.Error(diff)
+			}
+			if got := ErrorCode(errs[0]); got != ErrRecursiveType {
+				t.Errorf("got code %q, want %q", got, ErrRecursiveType)
+			}
 		})
 	}
 }
@@ -0,0 +1,115 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+package yang
+
+// This file implements checking that modules only use the statements
+// allowed by their YANG version, see Options.StrictYangVersion.
+
+import "strings"
+
+// yangVersion returns the YANG version of m, "1" if m has no yang-version
+// statement.
+func yangVersion(m *Module) string {
+	if m.YangVersion == nil {
+		return "1"
+	}
+	return m.YangVersion.Name
+}
+
+// onlyIfFeatureIn11 is the set of keywords of statements that may only
+// contain an if-feature statement in YANG 1.1 (RFC 7950 section 1.1).
+var onlyIfFeatureIn11 = map[string]bool{
+	"bit":      true,
+	"enum":     true,
+	"identity": true,
+	"refine":   true,
+}
+
+// checkYangVersion returns the errors for the statements of m that its
+// YANG version does not allow.
+func checkYangVersion(m *Module) []error {
+	var errs []error
+	version := yangVersion(m)
+	switch version {
+	case "1", "1.1":
+	default:
+		return []error{errorf(m.YangVersion, ErrYangVersion, "invalid yang-version %q, must be 1 or 1.1", version)}
+	}
+	for _, i := range m.Include {
+		if sm := i.Module; sm != nil && yangVersion(sm) != version {
+			errs = append(errs, errorf(i, ErrYangVersion, "YANG version %s module %s includes YANG version %s submodule %s", version, m.Name, yangVersion(sm), sm.Name))
+		}
+	}
+	if version != "1" || m.Statement() == nil {
+		return errs
+	}
+
+	var check func(s, parent *Statement)
+	check = func(s, parent *Statement) {
+		var what string
+		switch s.Keyword {
+		case "action", "anydata", "modifier":
+			what = s.Keyword
+		case "notification":
+			if parent.Keyword != "module" && parent.Keyword != "submodule" {
+				what = "notification in " + parent.Keyword
+			}
+		case "require-instance":
+			if parent.Keyword == "type" && parent.Argument == "leafref" {
+				what = "require-instance in a leafref"
+			}
+		case "if-feature":
+			if onlyIfFeatureIn11[parent.Keyword] {
+				what = "if-feature in " + parent.Keyword
+			} else if f := strings.Fields(s.Argument); len(f) > 1 || strings.ContainsAny(s.Argument, "()") {
+				what = "if-feature expression"
+			}
+		case "base":
+			if parent.Keyword == "identity" {
+				n := 0
+				for _, ss := range parent.SubStatements() {
+					if ss.Keyword == "base" {
+						n++
+					}
+				}
+				if n > 1 && s == firstSubstatement(parent, "base") {
+					what = "identity with multiple bases"
+				}
+			}
+		}
+		if what != "" {
+			errs = append(errs, errorf(s, ErrYangVersion, "%s requires yang-version 1.1", what))
+		}
+		for _, ss := range s.SubStatements() {
+			check(ss, s)
+		}
+	}
+	for _, s := range m.Statement().SubStatements() {
+		check(s, m.Statement())
+	}
+	return errs
+}
+
+// firstSubstatement returns the first substatement of s with keyword, or
+// nil.
+func firstSubstatement(s *Statement, keyword string) *Statement {
+	for _, ss := range s.SubStatements() {
+		if ss.Keyword == keyword {
+			return ss
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+package yang
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStrictYangVersion(t *testing.T) {
+	for _, tt := range []struct {
+		desc string
+		in   []string
+		want []string
+	}{{
+		desc: "version 1",
+		in: []string{`module v1 {
+  prefix "v";
+  namespace "urn:v";
+  feature f;
+  feature g;
+  identity a;
+  identity b;
+  identity c { base a; base b; }
+  container top {
+    action reset;
+    notification changed;
+    anydata blob;
+    leaf-list l { type string; }
+    leaf r { type leafref { path "../l"; require-instance false; } }
+    leaf e { type enumeration { enum x { if-feature f; } } }
+    leaf s { if-feature "f and g"; type string; }
+    leaf t { if-feature f; type string; }
+  }
+}`},
+		want: []string{
+			"v1.yang:8:16: identity with multiple bases requires yang-version 1.1",
+			"v1.yang:10:5: action requires yang-version 1.1",
+			"v1.yang:11:5: notification in container requires yang-version 1.1",
+			"v1.yang:12:5: anydata requires yang-version 1.1",
+			"v1.yang:14:42: require-instance in a leafref requires yang-version 1.1",
+			"v1.yang:15:42: if-feature in enum requires yang-version 1.1",
+			"v1.yang:16:14: if-feature expression requires yang-version 1.1",
+		},
+	}, {
+		desc: "version 1.1",
+		in: []string{`module v11 {
+  yang-version 1.1;
+  prefix "v";
+  namespace "urn:v";
+  container top { action reset; anydata blob; }
+}`},
+	}, {
+		desc: "invalid version",
+		in: []string{`module v2 {
+  yang-version 2;
+  prefix "v";
+  namespace "urn:v";
+}`},
+		want: []string{`v2.yang:2:3: invalid yang-version "2", must be 1 or 1.1`},
+	}, {
+		desc: "mismatched submodule",
+		in: []string{`module m {
+  yang-version 1.1;
+  prefix "m";
+  namespace "urn:m";
+  include s;
+}`, `submodule s {
+  belongs-to m { prefix "m"; }
+}`},
+		want: []string{"m.yang:5:3: YANG version 1.1 module m includes YANG version 1 submodule s"},
+	}} {
+		ms := NewModules()
+		for _, in := range tt.in {
+			name := in[strings.Index(in, " ")+1 : strings.Index(in, " {")]
+			if err := ms.Parse(in, name+".yang"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if errs := ms.Process(); len(errs) > 0 {
+			t.Fatalf("%s: Process without StrictYangVersion: %v", tt.desc, errs)
+		}
+		ms.opts.StrictYangVersion = true
+		var got []string
+		for _, err := range ms.Process() {
+			got = append(got, err.Error())
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("%s (-want, +got):\n%s", tt.desc, diff)
+		}
+	}
+}
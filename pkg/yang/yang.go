@@ -36,6 +36,8 @@ type Value struct {
 
 func (Value) Kind() string             { return "string" }
 func (s *Value) ParentNode() Node      { return s.Parent }
+func (s *Value) ParentModule() *Module { return parentModule(s) }
+func (s *Value) SchemaPath() string    { return schemaPath(s) }
 func (s *Value) NName() string         { return s.Name }
 func (s *Value) Statement() *Statement { return s.Source }
 func (s *Value) Exts() []*Statement    { return s.Extensions }
@@ -134,12 +136,42 @@ type Module struct {
 	Uses         []*Uses         `yang:"uses"`
 	YangVersion  *Value          `yang:"yang-version,nomerge"`
 
+	// The following are set by Process from the openconfig-extensions
+	// statements of the module.
+	OpenConfigVersion   string // argument of oc-ext:openconfig-version
+	CatalogOrganization string // argument of oc-ext:catalog-organization
+	RegexpPOSIX         bool   // oc-ext:regexp-posix is present
+
+	// SMIv2Aliases maps the smiv2:alias statements of the module to
+	// their object identifiers.  It is set by Process.
+	SMIv2Aliases map[string]string
+
+	// Annotations are the md:annotation statements of the module, set
+	// by Process.
+	Annotations []*Annotation
+
+	// Structures and StructureAugments are built from the sx:structure
+	// and sx:augment-structure statements of the module when it is
+	// parsed.
+	Structures        []*Container
+	StructureAugments []*Augment
+
 	// modules is used to get back to the Modules structure
 	// when searching for a rooted element in the schema tree
 	// as the schema tree has multiple root elements.
 	// typedefs is a list of all top level typedefs in this
 	// module.
 	modules *Modules
+
+	// entries and typedefsInUse are the state of ToEntry and of typedef
+	// resolution of a module that was not read into a Modules, e.g., one
+	// built by BuildAST.  They are only allocated when first needed.
+	entries       *entryState
+	typedefsInUse map[*Typedef]bool
+
+	// skipped are the warnings about the unknown substatements that
+	// were skipped when the module was parsed with Options.Lenient.
+	skipped []error
 }
 
 func (s *Module) Kind() string {
@@ -149,6 +181,8 @@ func (s *Module) Kind() string {
 	return "module"
 }
 func (s *Module) ParentNode() Node        { return s.Parent }
+func (s *Module) ParentModule() *Module   { return parentModule(s) }
+func (s *Module) SchemaPath() string      { return schemaPath(s) }
 func (s *Module) NName() string           { return s.Name }
 func (s *Module) Statement() *Statement   { return s.Source }
 func (s *Module) Exts() []*Statement      { return s.Extensions }
@@ -177,6 +211,39 @@ func (s *Module) FullName() string {
 	return s.Name
 }
 
+// Submodules returns the submodules included by s, including those
+// included by its submodules, each once in the order they are included.
+// Submodules that have not been loaded, which Process does, are omitted.
+func (s *Module) Submodules() []*Module {
+	var subs []*Module
+	seen := map[*Module]bool{s: true}
+	var add func(m *Module)
+	add = func(m *Module) {
+		for _, i := range m.Include {
+			if i.Module == nil || seen[i.Module] {
+				continue
+			}
+			seen[i.Module] = true
+			subs = append(subs, i.Module)
+			add(i.Module)
+		}
+	}
+	add(s)
+	return subs
+}
+
+// BelongsToModule returns the module the submodule s belongs to, or nil if
+// that module has not been read.  If s is a module, s is returned.
+func (s *Module) BelongsToModule() *Module {
+	if s.Kind() != "submodule" {
+		return s
+	}
+	if m := belongsTo(s); m != s {
+		return m
+	}
+	return nil
+}
+
 // GetPrefix returns the proper prefix of m.  Useful when looking up types
 // in modules found by FindModuleByPrefix.
 func (s *Module) GetPrefix() string {
@@ -220,6 +287,8 @@ type Import struct {
 
 func (Import) Kind() string             { return "import" }
 func (s *Import) ParentNode() Node      { return s.Parent }
+func (s *Import) ParentModule() *Module { return parentModule(s) }
+func (s *Import) SchemaPath() string    { return schemaPath(s) }
 func (s *Import) NName() string         { return s.Name }
 func (s *Import) Statement() *Statement { return s.Source }
 func (s *Import) Exts() []*Statement    { return s.Extensions }
@@ -240,6 +309,8 @@ type Include struct {
 
 func (Include) Kind() string             { return "include" }
 func (s *Include) ParentNode() Node      { return s.Parent }
+func (s *Include) ParentModule() *Module { return parentModule(s) }
+func (s *Include) SchemaPath() string    { return schemaPath(s) }
 func (s *Include) NName() string         { return s.Name }
 func (s *Include) Statement() *Statement { return s.Source }
 func (s *Include) Exts() []*Statement    { return s.Extensions }
@@ -257,6 +328,8 @@ type Revision struct {
 
 func (Revision) Kind() string             { return "revision" }
 func (s *Revision) ParentNode() Node      { return s.Parent }
+func (s *Revision) ParentModule() *Module { return parentModule(s) }
+func (s *Revision) SchemaPath() string    { return schemaPath(s) }
 func (s *Revision) NName() string         { return s.Name }
 func (s *Revision) Statement() *Statement { return s.Source }
 func (s *Revision) Exts() []*Statement    { return s.Extensions }
@@ -273,6 +346,8 @@ type BelongsTo struct {
 
 func (BelongsTo) Kind() string             { return "belongs-to" }
 func (s *BelongsTo) ParentNode() Node      { return s.Parent }
+func (s *BelongsTo) ParentModule() *Module { return parentModule(s) }
+func (s *BelongsTo) SchemaPath() string    { return schemaPath(s) }
 func (s *BelongsTo) NName() string         { return s.Name }
 func (s *BelongsTo) Statement() *Statement { return s.Source }
 func (s *BelongsTo) Exts() []*Statement    { return s.Extensions }
@@ -296,6 +371,8 @@ type Typedef struct {
 
 func (Typedef) Kind() string             { return "typedef" }
 func (s *Typedef) ParentNode() Node      { return s.Parent }
+func (s *Typedef) ParentModule() *Module { return parentModule(s) }
+func (s *Typedef) SchemaPath() string    { return schemaPath(s) }
 func (s *Typedef) NName() string         { return s.Name }
 func (s *Typedef) Statement() *Statement { return s.Source }
 func (s *Typedef) Exts() []*Statement    { return s.Extensions }
@@ -325,6 +402,8 @@ type Type struct {
 
 func (Type) Kind() string             { return "type" }
 func (s *Type) ParentNode() Node      { return s.Parent }
+func (s *Type) ParentModule() *Module { return parentModule(s) }
+func (s *Type) SchemaPath() string    { return schemaPath(s) }
 func (s *Type) NName() string         { return s.Name }
 func (s *Type) Statement() *Statement { return s.Source }
 func (s *Type) Exts() []*Statement    { return s.Extensions }
@@ -361,6 +440,8 @@ type Container struct {
 
 func (Container) Kind() string              { return "container" }
 func (s *Container) ParentNode() Node       { return s.Parent }
+func (s *Container) ParentModule() *Module  { return parentModule(s) }
+func (s *Container) SchemaPath() string     { return schemaPath(s) }
 func (s *Container) NName() string          { return s.Name }
 func (s *Container) Statement() *Statement  { return s.Source }
 func (s *Container) Exts() []*Statement     { return s.Extensions }
@@ -382,6 +463,8 @@ type Must struct {
 
 func (Must) Kind() string             { return "must" }
 func (s *Must) ParentNode() Node      { return s.Parent }
+func (s *Must) ParentModule() *Module { return parentModule(s) }
+func (s *Must) SchemaPath() string    { return schemaPath(s) }
 func (s *Must) NName() string         { return s.Name }
 func (s *Must) Statement() *Statement { return s.Source }
 func (s *Must) Exts() []*Statement    { return s.Extensions }
@@ -408,6 +491,8 @@ type Leaf struct {
 
 func (Leaf) Kind() string             { return "leaf" }
 func (s *Leaf) ParentNode() Node      { return s.Parent }
+func (s *Leaf) ParentModule() *Module { return parentModule(s) }
+func (s *Leaf) SchemaPath() string    { return schemaPath(s) }
 func (s *Leaf) NName() string         { return s.Name }
 func (s *Leaf) Statement() *Statement { return s.Source }
 func (s *Leaf) Exts() []*Statement    { return s.Extensions }
@@ -436,6 +521,8 @@ type LeafList struct {
 
 func (LeafList) Kind() string             { return "leaf-list" }
 func (s *LeafList) ParentNode() Node      { return s.Parent }
+func (s *LeafList) ParentModule() *Module { return parentModule(s) }
+func (s *LeafList) SchemaPath() string    { return schemaPath(s) }
 func (s *LeafList) NName() string         { return s.Name }
 func (s *LeafList) Statement() *Statement { return s.Source }
 func (s *LeafList) Exts() []*Statement    { return s.Extensions }
@@ -476,6 +563,8 @@ type List struct {
 
 func (List) Kind() string              { return "list" }
 func (s *List) ParentNode() Node       { return s.Parent }
+func (s *List) ParentModule() *Module  { return parentModule(s) }
+func (s *List) SchemaPath() string     { return schemaPath(s) }
 func (s *List) NName() string          { return s.Name }
 func (s *List) Statement() *Statement  { return s.Source }
 func (s *List) Exts() []*Statement     { return s.Extensions }
@@ -508,6 +597,8 @@ type Choice struct {
 
 func (Choice) Kind() string             { return "choice" }
 func (s *Choice) ParentNode() Node      { return s.Parent }
+func (s *Choice) ParentModule() *Module { return parentModule(s) }
+func (s *Choice) SchemaPath() string    { return schemaPath(s) }
 func (s *Choice) NName() string         { return s.Name }
 func (s *Choice) Statement() *Statement { return s.Source }
 func (s *Choice) Exts() []*Statement    { return s.Extensions }
@@ -536,6 +627,8 @@ type Case struct {
 
 func (Case) Kind() string             { return "case" }
 func (s *Case) ParentNode() Node      { return s.Parent }
+func (s *Case) ParentModule() *Module { return parentModule(s) }
+func (s *Case) SchemaPath() string    { return schemaPath(s) }
 func (s *Case) NName() string         { return s.Name }
 func (s *Case) Statement() *Statement { return s.Source }
 func (s *Case) Exts() []*Statement    { return s.Extensions }
@@ -559,6 +652,8 @@ type AnyXML struct {
 
 func (AnyXML) Kind() string             { return "anyxml" }
 func (s *AnyXML) ParentNode() Node      { return s.Parent }
+func (s *AnyXML) ParentModule() *Module { return parentModule(s) }
+func (s *AnyXML) SchemaPath() string    { return schemaPath(s) }
 func (s *AnyXML) NName() string         { return s.Name }
 func (s *AnyXML) Statement() *Statement { return s.Source }
 func (s *AnyXML) Exts() []*Statement    { return s.Extensions }
@@ -585,6 +680,8 @@ type AnyData struct {
 
 func (AnyData) Kind() string             { return "anydata" }
 func (s *AnyData) ParentNode() Node      { return s.Parent }
+func (s *AnyData) ParentModule() *Module { return parentModule(s) }
+func (s *AnyData) SchemaPath() string    { return schemaPath(s) }
 func (s *AnyData) NName() string         { return s.Name }
 func (s *AnyData) Statement() *Statement { return s.Source }
 func (s *AnyData) Exts() []*Statement    { return s.Extensions }
@@ -616,6 +713,8 @@ type Grouping struct {
 
 func (Grouping) Kind() string              { return "grouping" }
 func (s *Grouping) ParentNode() Node       { return s.Parent }
+func (s *Grouping) ParentModule() *Module  { return parentModule(s) }
+func (s *Grouping) SchemaPath() string     { return schemaPath(s) }
 func (s *Grouping) NName() string          { return s.Name }
 func (s *Grouping) Statement() *Statement  { return s.Source }
 func (s *Grouping) Exts() []*Statement     { return s.Extensions }
@@ -640,6 +739,8 @@ type Uses struct {
 
 func (Uses) Kind() string             { return "uses" }
 func (s *Uses) ParentNode() Node      { return s.Parent }
+func (s *Uses) ParentModule() *Module { return parentModule(s) }
+func (s *Uses) SchemaPath() string    { return schemaPath(s) }
 func (s *Uses) NName() string         { return s.Name }
 func (s *Uses) Statement() *Statement { return s.Source }
 func (s *Uses) Exts() []*Statement    { return s.Extensions }
@@ -665,6 +766,8 @@ type Refine struct {
 
 func (Refine) Kind() string             { return "refine" }
 func (s *Refine) ParentNode() Node      { return s.Parent }
+func (s *Refine) ParentModule() *Module { return parentModule(s) }
+func (s *Refine) SchemaPath() string    { return schemaPath(s) }
 func (s *Refine) NName() string         { return s.Name }
 func (s *Refine) Statement() *Statement { return s.Source }
 func (s *Refine) Exts() []*Statement    { return s.Extensions }
@@ -688,6 +791,8 @@ type RPC struct {
 
 func (RPC) Kind() string              { return "rpc" }
 func (s *RPC) ParentNode() Node       { return s.Parent }
+func (s *RPC) ParentModule() *Module  { return parentModule(s) }
+func (s *RPC) SchemaPath() string     { return schemaPath(s) }
 func (s *RPC) NName() string          { return s.Name }
 func (s *RPC) Statement() *Statement  { return s.Source }
 func (s *RPC) Exts() []*Statement     { return s.Extensions }
@@ -715,6 +820,8 @@ type Input struct {
 
 func (Input) Kind() string              { return "input" }
 func (s *Input) ParentNode() Node       { return s.Parent }
+func (s *Input) ParentModule() *Module  { return parentModule(s) }
+func (s *Input) SchemaPath() string     { return schemaPath(s) }
 func (s *Input) NName() string          { return s.Name }
 func (s *Input) Statement() *Statement  { return s.Source }
 func (s *Input) Exts() []*Statement     { return s.Extensions }
@@ -742,6 +849,8 @@ type Output struct {
 
 func (Output) Kind() string              { return "output" }
 func (s *Output) ParentNode() Node       { return s.Parent }
+func (s *Output) ParentModule() *Module  { return parentModule(s) }
+func (s *Output) SchemaPath() string     { return schemaPath(s) }
 func (s *Output) NName() string          { return s.Name }
 func (s *Output) Statement() *Statement  { return s.Source }
 func (s *Output) Exts() []*Statement     { return s.Extensions }
@@ -773,6 +882,8 @@ type Notification struct {
 
 func (Notification) Kind() string              { return "notification" }
 func (s *Notification) ParentNode() Node       { return s.Parent }
+func (s *Notification) ParentModule() *Module  { return parentModule(s) }
+func (s *Notification) SchemaPath() string     { return schemaPath(s) }
 func (s *Notification) NName() string          { return s.Name }
 func (s *Notification) Statement() *Statement  { return s.Source }
 func (s *Notification) Exts() []*Statement     { return s.Extensions }
@@ -807,6 +918,8 @@ type Augment struct {
 
 func (Augment) Kind() string             { return "augment" }
 func (s *Augment) ParentNode() Node      { return s.Parent }
+func (s *Augment) ParentModule() *Module { return parentModule(s) }
+func (s *Augment) SchemaPath() string    { return schemaPath(s) }
 func (s *Augment) NName() string         { return s.Name }
 func (s *Augment) Statement() *Statement { return s.Source }
 func (s *Augment) Exts() []*Statement    { return s.Extensions }
@@ -828,6 +941,8 @@ type Identity struct {
 
 func (Identity) Kind() string             { return "identity" }
 func (s *Identity) ParentNode() Node      { return s.Parent }
+func (s *Identity) ParentModule() *Module { return parentModule(s) }
+func (s *Identity) SchemaPath() string    { return schemaPath(s) }
 func (s *Identity) NName() string         { return s.Name }
 func (s *Identity) Statement() *Statement { return s.Source }
 func (s *Identity) Exts() []*Statement    { return s.Extensions }
@@ -870,6 +985,8 @@ type Extension struct {
 
 func (Extension) Kind() string             { return "extension" }
 func (s *Extension) ParentNode() Node      { return s.Parent }
+func (s *Extension) ParentModule() *Module { return parentModule(s) }
+func (s *Extension) SchemaPath() string    { return schemaPath(s) }
 func (s *Extension) NName() string         { return s.Name }
 func (s *Extension) Statement() *Statement { return s.Source }
 func (s *Extension) Exts() []*Statement    { return s.Extensions }
@@ -886,6 +1003,8 @@ type Argument struct {
 
 func (Argument) Kind() string             { return "argument" }
 func (s *Argument) ParentNode() Node      { return s.Parent }
+func (s *Argument) ParentModule() *Module { return parentModule(s) }
+func (s *Argument) SchemaPath() string    { return schemaPath(s) }
 func (s *Argument) NName() string         { return s.Name }
 func (s *Argument) Statement() *Statement { return s.Source }
 func (s *Argument) Exts() []*Statement    { return s.Extensions }
@@ -902,6 +1021,8 @@ type Element struct {
 
 func (Element) Kind() string             { return "element" }
 func (s *Element) ParentNode() Node      { return s.Parent }
+func (s *Element) ParentModule() *Module { return parentModule(s) }
+func (s *Element) SchemaPath() string    { return schemaPath(s) }
 func (s *Element) NName() string         { return s.Name }
 func (s *Element) Statement() *Statement { return s.Source }
 func (s *Element) Exts() []*Statement    { return s.Extensions }
@@ -921,6 +1042,8 @@ type Feature struct {
 
 func (Feature) Kind() string             { return "feature" }
 func (s *Feature) ParentNode() Node      { return s.Parent }
+func (s *Feature) ParentModule() *Module { return parentModule(s) }
+func (s *Feature) SchemaPath() string    { return schemaPath(s) }
 func (s *Feature) NName() string         { return s.Name }
 func (s *Feature) Statement() *Statement { return s.Source }
 func (s *Feature) Exts() []*Statement    { return s.Extensions }
@@ -939,6 +1062,8 @@ type Deviation struct {
 
 func (Deviation) Kind() string             { return "deviation" }
 func (s *Deviation) ParentNode() Node      { return s.Parent }
+func (s *Deviation) ParentModule() *Module { return parentModule(s) }
+func (s *Deviation) SchemaPath() string    { return schemaPath(s) }
 func (s *Deviation) NName() string         { return s.Name }
 func (s *Deviation) Statement() *Statement { return s.Source }
 func (s *Deviation) Exts() []*Statement    { return s.Extensions }
@@ -963,6 +1088,8 @@ type Deviate struct {
 
 func (Deviate) Kind() string             { return "deviate" }
 func (s *Deviate) ParentNode() Node      { return s.Parent }
+func (s *Deviate) ParentModule() *Module { return parentModule(s) }
+func (s *Deviate) SchemaPath() string    { return schemaPath(s) }
 func (s *Deviate) NName() string         { return s.Name }
 func (s *Deviate) Statement() *Statement { return s.Source }
 func (s *Deviate) Exts() []*Statement    { return s.Extensions }
@@ -983,6 +1110,8 @@ type Enum struct {
 
 func (Enum) Kind() string             { return "enum" }
 func (s *Enum) ParentNode() Node      { return s.Parent }
+func (s *Enum) ParentModule() *Module { return parentModule(s) }
+func (s *Enum) SchemaPath() string    { return schemaPath(s) }
 func (s *Enum) NName() string         { return s.Name }
 func (s *Enum) Statement() *Statement { return s.Source }
 func (s *Enum) Exts() []*Statement    { return s.Extensions }
@@ -1003,6 +1132,8 @@ type Bit struct {
 
 func (Bit) Kind() string             { return "bit" }
 func (s *Bit) ParentNode() Node      { return s.Parent }
+func (s *Bit) ParentModule() *Module { return parentModule(s) }
+func (s *Bit) SchemaPath() string    { return schemaPath(s) }
 func (s *Bit) NName() string         { return s.Name }
 func (s *Bit) Statement() *Statement { return s.Source }
 func (s *Bit) Exts() []*Statement    { return s.Extensions }
@@ -1022,6 +1153,8 @@ type Range struct {
 
 func (Range) Kind() string             { return "range" }
 func (s *Range) ParentNode() Node      { return s.Parent }
+func (s *Range) ParentModule() *Module { return parentModule(s) }
+func (s *Range) SchemaPath() string    { return schemaPath(s) }
 func (s *Range) NName() string         { return s.Name }
 func (s *Range) Statement() *Statement { return s.Source }
 func (s *Range) Exts() []*Statement    { return s.Extensions }
@@ -1041,6 +1174,8 @@ type Length struct {
 
 func (Length) Kind() string             { return "length" }
 func (s *Length) ParentNode() Node      { return s.Parent }
+func (s *Length) ParentModule() *Module { return parentModule(s) }
+func (s *Length) SchemaPath() string    { return schemaPath(s) }
 func (s *Length) NName() string         { return s.Name }
 func (s *Length) Statement() *Statement { return s.Source }
 func (s *Length) Exts() []*Statement    { return s.Extensions }
@@ -1055,11 +1190,14 @@ type Pattern struct {
 	Description  *Value `yang:"description"`
 	ErrorAppTag  *Value `yang:"error-app-tag"`
 	ErrorMessage *Value `yang:"error-message"`
+	Modifier     *Value `yang:"modifier"`
 	Reference    *Value `yang:"reference"`
 }
 
 func (Pattern) Kind() string             { return "pattern" }
 func (s *Pattern) ParentNode() Node      { return s.Parent }
+func (s *Pattern) ParentModule() *Module { return parentModule(s) }
+func (s *Pattern) SchemaPath() string    { return schemaPath(s) }
 func (s *Pattern) NName() string         { return s.Name }
 func (s *Pattern) Statement() *Statement { return s.Source }
 func (s *Pattern) Exts() []*Statement    { return s.Extensions }
@@ -1089,6 +1227,8 @@ type Action struct {
 
 func (Action) Kind() string              { return "action" }
 func (s *Action) ParentNode() Node       { return s.Parent }
+func (s *Action) ParentModule() *Module  { return parentModule(s) }
+func (s *Action) SchemaPath() string     { return schemaPath(s) }
 func (s *Action) NName() string          { return s.Name }
 func (s *Action) Statement() *Statement  { return s.Source }
 func (s *Action) Exts() []*Statement     { return s.Extensions }
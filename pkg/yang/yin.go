@@ -0,0 +1,540 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements parsing modules written in YIN, the XML syntax of
+// YANG (RFC 7950 section 13), into the same statements Parse produces, and
+// writing modules as YIN.
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// YINNamespace is the XML namespace of the YIN statements.
+const YINNamespace = "urn:ietf:params:xml:ns:yang:yin:1"
+
+// A yinArgument describes how the argument of a statement is represented
+// in YIN: as the attribute name, or, if element is set, as the text of the
+// child element name.
+type yinArgument struct {
+	name    string
+	element bool
+}
+
+// yinArguments maps each YANG keyword that has an argument to its YIN
+// representation (RFC 7950 section 13.1).  Keywords not in the map have no
+// argument.
+var yinArguments = map[string]yinArgument{
+	"action":           {"name", false},
+	"anydata":          {"name", false},
+	"anyxml":           {"name", false},
+	"argument":         {"name", false},
+	"augment":          {"target-node", false},
+	"base":             {"name", false},
+	"belongs-to":       {"module", false},
+	"bit":              {"name", false},
+	"case":             {"name", false},
+	"choice":           {"name", false},
+	"config":           {"value", false},
+	"contact":          {"text", true},
+	"container":        {"name", false},
+	"default":          {"value", false},
+	"description":      {"text", true},
+	"deviate":          {"value", false},
+	"deviation":        {"target-node", false},
+	"enum":             {"name", false},
+	"error-app-tag":    {"value", false},
+	"error-message":    {"value", true},
+	"extension":        {"name", false},
+	"feature":          {"name", false},
+	"fraction-digits":  {"value", false},
+	"grouping":         {"name", false},
+	"identity":         {"name", false},
+	"if-feature":       {"name", false},
+	"import":           {"module", false},
+	"include":          {"module", false},
+	"key":              {"value", false},
+	"leaf":             {"name", false},
+	"leaf-list":        {"name", false},
+	"length":           {"value", false},
+	"list":             {"name", false},
+	"mandatory":        {"value", false},
+	"max-elements":     {"value", false},
+	"min-elements":     {"value", false},
+	"modifier":         {"value", false},
+	"module":           {"name", false},
+	"must":             {"condition", false},
+	"namespace":        {"uri", false},
+	"notification":     {"name", false},
+	"ordered-by":       {"value", false},
+	"organization":     {"text", true},
+	"path":             {"value", false},
+	"pattern":          {"value", false},
+	"position":         {"value", false},
+	"prefix":           {"value", false},
+	"presence":         {"value", false},
+	"range":            {"value", false},
+	"reference":        {"text", true},
+	"refine":           {"target-node", false},
+	"require-instance": {"value", false},
+	"revision":         {"date", false},
+	"revision-date":    {"date", false},
+	"rpc":              {"name", false},
+	"status":           {"value", false},
+	"submodule":        {"name", false},
+	"type":             {"name", false},
+	"typedef":          {"name", false},
+	"unique":           {"tag", false},
+	"units":            {"name", false},
+	"uses":             {"name", false},
+	"value":            {"value", false},
+	"when":             {"condition", false},
+	"yang-version":     {"value", false},
+	"yin-element":      {"value", false},
+}
+
+// IsYIN reports whether the source named name, with contents data, is
+// written in YIN rather than YANG, i.e., name has the extension .yin or
+// data starts with an XML element or declaration.
+func IsYIN(data, name string) bool {
+	return strings.HasSuffix(name, ".yin") || strings.HasPrefix(strings.TrimLeft(data, " \t\r\n\ufeff"), "<")
+}
+
+// ParseYIN parses the input as a module written in YIN and returns the
+// statements of the module, as Parse does for YANG.  The path is used in
+// the locations of the statements and in error messages.
+//
+// The argument of an extension statement is represented as its definition
+// specifies if the extension is defined in input.  Otherwise the only
+// attribute of the element, if it has exactly one, is the argument.
+func ParseYIN(input, path string) ([]*Statement, error) {
+	return ParseYINContext(context.Background(), input, path)
+}
+
+// ParseYINContext is like ParseYIN but returns nil and ctx.Err() if ctx is
+// done before the input has been parsed.
+func ParseYINContext(ctx context.Context, input, path string) ([]*Statement, error) {
+	return parseYIN(ctx, input, path, &ParseOptions)
+}
+
+// parseYIN implements ParseYINContext using opts.
+func parseYIN(ctx context.Context, input, path string, opts *Options) (statements []*Statement, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	defer recoverError(opts, &err)
+	p := &yinParser{
+		opts:       opts,
+		d:          xml.NewDecoder(strings.NewReader(input)),
+		input:      input,
+		file:       path,
+		line:       1,
+		col:        1,
+		ctx:        ctx,
+		extensions: map[string]yinArgument{},
+	}
+	root, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	p.findExtensions(root)
+	s, err := p.statement(root)
+	if err != nil {
+		return nil, err
+	}
+	return []*Statement{s}, nil
+}
+
+// A yinElement is an XML element of a YIN document.
+type yinElement struct {
+	name      xml.Name
+	prefix    string // the prefix declared for name.Space
+	attrs     []xml.Attr
+	children  []*yinElement
+	text      strings.Builder
+	line, col int
+}
+
+// position returns the location of e in file.
+func (e *yinElement) position(file string) Position {
+	return Position{File: file, Line: e.line, Col: e.col}
+}
+
+// attr returns the value of the attribute of e named name, or "".
+func (e *yinElement) attr(name string) string {
+	for _, a := range e.attrs {
+		if a.Name.Space == "" && a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// isYIN reports whether e is the YIN statement keyword.
+func (e *yinElement) isYIN(keyword string) bool {
+	return e.name.Space == YINNamespace && e.name.Local == keyword
+}
+
+// A yinParser reads a YIN document.
+type yinParser struct {
+	d          *xml.Decoder
+	opts       *Options // options of the parse
+	input      string
+	file       string
+	off        int // the offset in input of line and col
+	line, col  int
+	ctx        context.Context
+	tokens     int
+	prefixes   map[string]string      // the prefixes declared by the root element, by namespace
+	namespace  string                 // the namespace of the module
+	extensions map[string]yinArgument // the extensions defined by the module
+}
+
+// position returns the line and column of the offset off in p.input, which
+// must not be before the offset of the previous call.
+func (p *yinParser) position(off int) (int, int) {
+	for ; p.off < off && p.off < len(p.input); p.off++ {
+		if p.input[p.off] == '\n' {
+			p.line++
+			p.col = 1
+		} else {
+			p.col++
+		}
+	}
+	return p.line, p.col
+}
+
+// parse reads the document and returns its root element, which must be a
+// YIN module or submodule.
+func (p *yinParser) parse() (*yinElement, error) {
+	var root *yinElement
+	var stack []*yinElement
+	// prefixes has the prefixes declared for each namespace, one map for
+	// each open element.
+	prefixes := []map[string]string{{}}
+	for {
+		off := int(p.d.InputOffset())
+		tok, err := p.d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			line, col := p.position(int(p.d.InputOffset()))
+			return nil, errorfAt(Position{File: p.file, Line: line, Col: col}, ErrSyntax, "%v", err)
+		}
+		if p.tokens++; p.tokens%ctxCheckInterval == 0 {
+			if err := p.ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			line, col := p.position(off)
+			if max := p.opts.MaxStatementDepth; max > 0 && len(stack) >= max {
+				return nil, errorfAt(Position{File: p.file, Line: line, Col: col}, ErrLimitExceeded, "statements nested more than %d deep", max)
+			}
+			ps := map[string]string{}
+			for ns, prefix := range prefixes[len(prefixes)-1] {
+				ps[ns] = prefix
+			}
+			e := &yinElement{name: tok.Name, line: line, col: col}
+			for _, a := range tok.Attr {
+				switch {
+				case a.Name.Space == "xmlns":
+					ps[a.Value] = a.Name.Local
+				case a.Name.Space == "" && a.Name.Local == "xmlns":
+				default:
+					e.attrs = append(e.attrs, a)
+				}
+			}
+			e.prefix = ps[tok.Name.Space]
+			prefixes = append(prefixes, ps)
+			switch {
+			case len(stack) > 0:
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, e)
+			case root != nil:
+				return nil, errorfAt(Position{File: p.file, Line: line, Col: col}, ErrSyntax, "more than one root element")
+			default:
+				root = e
+				p.prefixes = ps
+			}
+			stack = append(stack, e)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+			prefixes = prefixes[:len(prefixes)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(tok)
+			}
+		}
+	}
+	switch {
+	case root == nil:
+		return nil, errorfAt(Position{File: p.file}, ErrSyntax, "no YIN module or submodule found")
+	case !root.isYIN("module") && !root.isYIN("submodule"):
+		return nil, errorfAt(root.position(p.file), ErrSyntax, "root element is not a YIN module or submodule")
+	}
+	return root, nil
+}
+
+// findExtensions records the namespace of the module root and the
+// arguments of the extensions it defines.
+func (p *yinParser) findExtensions(root *yinElement) {
+	for _, e := range root.children {
+		switch {
+		case e.isYIN("namespace"):
+			p.namespace = e.attr("uri")
+		case e.isYIN("belongs-to"):
+			// A submodule has the namespace of its module, which is
+			// the namespace of its prefix.
+			for _, be := range e.children {
+				if !be.isYIN("prefix") {
+					continue
+				}
+				for ns, prefix := range p.prefixes {
+					if prefix == be.attr("value") {
+						p.namespace = ns
+					}
+				}
+			}
+		case e.isYIN("extension"):
+			var arg yinArgument
+			for _, ae := range e.children {
+				if !ae.isYIN("argument") {
+					continue
+				}
+				arg.name = ae.attr("name")
+				for _, ye := range ae.children {
+					if ye.isYIN("yin-element") {
+						arg.element = ye.attr("value") == "true"
+					}
+				}
+			}
+			p.extensions[e.attr("name")] = arg
+		}
+	}
+}
+
+// statement returns the statement represented by e.
+func (p *yinParser) statement(e *yinElement) (*Statement, error) {
+	s := &Statement{file: p.file, line: e.line, col: e.col}
+	var arg yinArgument
+	if e.name.Space == YINNamespace {
+		s.Keyword = e.name.Local
+		arg = yinArguments[s.Keyword]
+	} else {
+		if e.prefix == "" {
+			return nil, errorfAt(e.position(p.file), ErrSyntax, "extension element %s has no namespace prefix", e.name.Local)
+		}
+		s.Keyword = e.prefix + ":" + e.name.Local
+		var ok bool
+		if e.name.Space == p.namespace {
+			arg, ok = p.extensions[e.name.Local]
+		}
+		if !ok && len(e.attrs) == 1 {
+			arg = yinArgument{name: e.attrs[0].Name.Local}
+		}
+	}
+
+	children := e.children
+	if arg.name != "" {
+		s.HasArgument = true
+		if arg.element {
+			found := false
+			for x, ce := range children {
+				if ce.name.Space == e.name.Space && ce.name.Local == arg.name {
+					s.Argument = ce.text.String()
+					children = append(append([]*yinElement{}, children[:x]...), children[x+1:]...)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, errorfAt(e.position(p.file), ErrSyntax, "%s has no %s element", s.Keyword, arg.name)
+			}
+		} else {
+			found := false
+			for _, a := range e.attrs {
+				if a.Name.Space == "" && a.Name.Local == arg.name {
+					s.Argument = a.Value
+					found = true
+				}
+			}
+			if !found {
+				return nil, errorfAt(e.position(p.file), ErrSyntax, "%s has no %s attribute", s.Keyword, arg.name)
+			}
+		}
+	}
+	for _, ce := range children {
+		cs, err := p.statement(ce)
+		if err != nil {
+			return nil, err
+		}
+		s.statements = append(s.statements, cs)
+	}
+	return s, nil
+}
+
+// WriteYIN writes m as YIN to w.  The statements of m, including its
+// extension statements, are written in the order they appear in m's source.
+// The argument of an extension statement is written as the extension's
+// argument statement specifies, so m's imports must have been resolved,
+// e.g., by Process.  An error is returned if an extension with an argument
+// cannot be found, or if writing to w fails.
+func (m *Module) WriteYIN(w io.Writer) error {
+	s := m.Statement()
+	if s == nil {
+		return fmt.Errorf("%s %s has no statement", m.Kind(), m.Name)
+	}
+	yw := &yinWriter{w: bufio.NewWriter(w), m: m, prefixes: map[string]string{}}
+
+	// The root element declares the prefixes of m, or of the module a
+	// submodule belongs to, and of each import.
+	var attrs []string
+	prefix, ns := m.GetPrefix(), ""
+	if bm := belongsTo(m); bm.Namespace != nil {
+		ns = bm.Namespace.Name
+	}
+	attrs = append(attrs, `xmlns="`+YINNamespace+`"`)
+	if ns != "" {
+		attrs = append(attrs, yinAttr("xmlns:"+prefix, ns))
+	}
+	for _, i := range m.Import {
+		if i.Prefix == nil || i.Module == nil || i.Module.Namespace == nil {
+			continue
+		}
+		attrs = append(attrs, yinAttr("xmlns:"+i.Prefix.Name, i.Module.Namespace.Name))
+	}
+
+	fmt.Fprintf(yw.w, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	if err := yw.write(s, "", attrs); err != nil {
+		return err
+	}
+	return yw.w.Flush()
+}
+
+// A yinWriter writes the statements of the module m as YIN.
+type yinWriter struct {
+	w        *bufio.Writer
+	m        *Module
+	prefixes map[string]string
+}
+
+// yinAttr returns the XML attribute name with value.
+func yinAttr(name, value string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(value))
+	return name + `="` + b.String() + `"`
+}
+
+// write writes the element for s, and its substatements, each line indented
+// by indent.  attrs are added to the attributes of the element, on lines of
+// their own.
+func (yw *yinWriter) write(s *Statement, indent string, attrs []string) error {
+	name := s.Keyword
+	arg, ok := yinArguments[s.Keyword]
+	if prefix, ext := getPrefix(s.Keyword); prefix != "" {
+		var err error
+		if arg, err = yw.extensionArgument(s, prefix, ext); err != nil {
+			return err
+		}
+	} else if !ok && s.HasArgument {
+		return errorf(s, ErrSyntax, "%s cannot have an argument in YIN", s.Keyword)
+	}
+
+	if s.HasArgument && arg.name != "" && !arg.element {
+		attrs = append([]string{yinAttr(arg.name, s.Argument)}, attrs...)
+	}
+	yw.w.WriteString(indent + "<" + name)
+	for x, a := range attrs {
+		if x == 0 {
+			yw.w.WriteString(" " + a)
+			continue
+		}
+		// Attributes after the first are aligned with it.
+		yw.w.WriteString("\n" + indent + strings.Repeat(" ", len(name)+2) + a)
+	}
+	ss := s.SubStatements()
+	element := s.HasArgument && arg.element
+	if len(ss) == 0 && !element {
+		yw.w.WriteString("/>\n")
+		return nil
+	}
+	yw.w.WriteString(">\n")
+	if element {
+		// The argument element is in the namespace of its statement.
+		ename := arg.name
+		if i := strings.Index(name, ":"); i >= 0 {
+			ename = name[:i+1] + ename
+		}
+		yw.w.WriteString(indent + "  <" + ename + ">")
+		xml.EscapeText(yw.w, []byte(s.Argument))
+		yw.w.WriteString("</" + ename + ">\n")
+	}
+	for _, cs := range ss {
+		if err := yw.write(cs, indent+"  ", nil); err != nil {
+			return err
+		}
+	}
+	yw.w.WriteString(indent + "</" + name + ">\n")
+	return nil
+}
+
+// extensionArgument returns how the argument of the extension statement s,
+// the extension ext of the module with prefix, is written.
+func (yw *yinWriter) extensionArgument(s *Statement, prefix, ext string) (yinArgument, error) {
+	if !s.HasArgument {
+		return yinArgument{}, nil
+	}
+	var e *Extension
+	if em := FindModuleByPrefix(yw.m, prefix); em != nil {
+		e = findExtension(em, ext)
+	}
+	if e == nil {
+		return yinArgument{}, errorf(s, ErrBadExtension, "unknown extension %s", s.Keyword)
+	}
+	if e.Argument == nil {
+		return yinArgument{}, errorf(s, ErrBadExtension, "extension %s does not have an argument", s.Keyword)
+	}
+	arg := yinArgument{name: e.Argument.Name}
+	if y := e.Argument.YinElement; y != nil {
+		arg.element = y.Name == "true"
+	}
+	return arg, nil
+}
+
+// findExtension returns the extension name defined by m or by one of the
+// submodules it includes, or nil.
+func findExtension(m *Module, name string) *Extension {
+	for _, e := range m.Extension {
+		if e.Name == name {
+			return e
+		}
+	}
+	for _, i := range m.Include {
+		if i.Module != nil {
+			if e := findExtension(i.Module, name); e != nil {
+				return e
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+const yinModule = `<?xml version="1.0" encoding="UTF-8"?>
+<module name="y"
+        xmlns="urn:ietf:params:xml:ns:yang:yin:1"
+        xmlns:y="urn:y"
+        xmlns:e="urn:e">
+  <yang-version value="1.1"/>
+  <namespace uri="urn:y"/>
+  <prefix value="y"/>
+  <import module="e">
+    <prefix value="e"/>
+  </import>
+  <description>
+    <text>A module &lt;in&gt; YIN.</text>
+  </description>
+  <extension name="note">
+    <argument name="text">
+      <yin-element value="true"/>
+    </argument>
+  </extension>
+  <container name="c">
+    <y:note>
+      <y:text>local</y:text>
+    </y:note>
+    <e:label name="imported"/>
+    <leaf name="l">
+      <type name="string">
+        <pattern value="[a-z]+"/>
+      </type>
+    </leaf>
+  </container>
+</module>
+`
+
+const yangModule = `module y {
+  yang-version "1.1";
+  namespace "urn:y";
+  prefix "y";
+  import e {
+    prefix "e";
+  }
+  description "A module <in> YIN.";
+  extension note {
+    argument text {
+      yin-element "true";
+    }
+  }
+  container c {
+    y:note "local";
+    e:label "imported";
+    leaf l {
+      type string {
+        pattern "[a-z]+";
+      }
+    }
+  }
+}
+`
+
+func TestParseYIN(t *testing.T) {
+	got, err := ParseYIN(yinModule, "y.yin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := Parse(yangModule, "y.yang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || !got[0].equal(want[0]) {
+		t.Errorf("ParseYIN got:\n%s\nwant:\n%s", got[0], want[0])
+	}
+	if got, want := got[0].SubStatements()[6].Location(), "y.yin:20:3"; got != want {
+		t.Errorf("container location got %s, want %s", got, want)
+	}
+}
+
+func TestParseYINErrors(t *testing.T) {
+	for _, tt := range []struct {
+		desc    string
+		in      string
+		wantErr string
+	}{{
+		desc:    "not XML",
+		in:      `<module name="x"`,
+		wantErr: "x.yin:1:17: XML syntax error",
+	}, {
+		desc:    "not YIN",
+		in:      `<module name="x"/>`,
+		wantErr: "x.yin:1:1: root element is not a YIN module or submodule",
+	}, {
+		desc:    "empty",
+		in:      ` `,
+		wantErr: "x.yin: no YIN module or submodule found",
+	}, {
+		desc:    "missing argument",
+		in:      `<module xmlns="urn:ietf:params:xml:ns:yang:yin:1"/>`,
+		wantErr: "x.yin:1:1: module has no name attribute",
+	}, {
+		desc: "missing text",
+		in: `<module name="x" xmlns="urn:ietf:params:xml:ns:yang:yin:1">
+  <description/>
+</module>`,
+		wantErr: "x.yin:2:3: description has no text element",
+	}, {
+		desc: "extension without prefix",
+		in: `<module name="x" xmlns="urn:ietf:params:xml:ns:yang:yin:1">
+  <ext xmlns="urn:x"/>
+</module>`,
+		wantErr: "x.yin:2:3: extension element ext has no namespace prefix",
+	}} {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := ParseYIN(tt.in, "x.yin")
+			if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestModulesParseYIN(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`module e {
+  namespace "urn:e";
+  prefix "e";
+  extension label { argument name; }
+}`, "e.yang"); err != nil {
+		t.Fatal(err)
+	}
+	// The YIN module is recognized by its contents, not its name.
+	if err := ms.Parse(yinModule, "y"); err != nil {
+		t.Fatal(err)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	e, errs := ms.GetModule("y")
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if l := e.Find("c/l"); l == nil || l.Type.Kind != Ystring {
+		t.Errorf("leaf c/l not found in %s", e.Name)
+	}
+	if got, want := e.Description, "A module <in> YIN."; got != want {
+		t.Errorf("description got %q, want %q", got, want)
+	}
+}
+
+func TestWriteYIN(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`module e {
+  namespace "urn:e";
+  prefix "e";
+  extension label { argument name; }
+}`, "e.yang"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ms.Parse(yangModule, "y.yang"); err != nil {
+		t.Fatal(err)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	var b strings.Builder
+	if err := ms.Modules["y"].WriteYIN(&b); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(yinModule, b.String()); diff != "" {
+		t.Errorf("WriteYIN (-want, +got):\n%s", diff)
+	}
+
+	// Writing an extension requires its definition.
+	ms = NewModules()
+	if err := ms.Parse(`module u {
+  namespace "urn:u";
+  prefix "u";
+  u:missing "x";
+}`, "u.yang"); err != nil {
+		t.Fatal(err)
+	}
+	err := ms.Modules["u"].WriteYIN(&b)
+	if diff := errdiff.Substring(err, "u.yang:4:3: unknown extension u:missing"); diff != "" {
+		t.Error(diff)
+	}
+}
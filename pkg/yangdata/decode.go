@@ -0,0 +1,190 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+package yangdata
+
+// This file implements decoding JSON and XML documents into members.
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+// ValidateJSON returns the violations of the schema of ms, which must have
+// been processed, by the RFC 7951 JSON document data.  The document is a
+// JSON object whose members are top level data nodes, optionally wrapped
+// in an "ietf-restconf:data" object.  An error is returned if data is not
+// a valid JSON object.
+func ValidateJSON(ms *yang.Modules, data []byte) ([]*Violation, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var doc interface{}
+	if err := d.Decode(&doc); err != nil {
+		return nil, err
+	}
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("document is not a JSON object")
+	}
+	if data, ok := obj["ietf-restconf:data"].(map[string]interface{}); ok && len(obj) == 1 {
+		obj = data
+	}
+	return newValidator(ms, true).validate(jsonMembers(obj, "")), nil
+}
+
+// jsonMembers returns the members of the JSON object obj, sorted by name.
+// module is the name of the module of obj, which qualifies the names of
+// members that are not qualified by a module name.
+func jsonMembers(obj map[string]interface{}, module string) []*member {
+	names := make([]string, 0, len(obj))
+	for key := range obj {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+
+	var members []*member
+	for _, key := range names {
+		mod, name := module, key
+		if i := strings.Index(key, ":"); i >= 0 {
+			mod, name = key[:i], key[i+1:]
+		}
+		value := obj[key]
+		a, ok := value.([]interface{})
+		if !ok || isNullArray(a) {
+			members = append(members, jsonMember(mod, name, key, value, false))
+			continue
+		}
+		for _, v := range a {
+			members = append(members, jsonMember(mod, name, key, v, true))
+		}
+	}
+	return members
+}
+
+func jsonMember(module, name, qname string, value interface{}, array bool) *member {
+	m := &member{module: module, name: name, qname: qname, array: array}
+	if obj, ok := value.(map[string]interface{}); ok {
+		m.object = true
+		m.children = jsonMembers(obj, module)
+	} else {
+		m.value = scalar{isJSON: true, json: value}
+	}
+	return m
+}
+
+// ValidateXML returns the violations of the schema of ms, which must have
+// been processed, by the XML document data.  The root element of the
+// document is either a top level data node or an element named "data" or
+// "config", such as a NETCONF <config> or <data> element, whose child
+// elements are top level data nodes.  An error is returned if data is not
+// valid XML.
+func ValidateXML(ms *yang.Modules, data []byte) ([]*Violation, error) {
+	roots, err := xmlMembers(ms, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(roots) == 0 {
+		return nil, errors.New("document has no root element")
+	}
+	v := newValidator(ms, false)
+	if r := roots[0]; len(roots) == 1 && (r.name == "data" || r.name == "config") && v.child(nil, r.module, r.name) == nil {
+		roots = r.children
+	}
+	return v.validate(roots), nil
+}
+
+// xmlMembers returns the members of the root elements of the XML document
+// data.  The namespace of each element is mapped to the name of the module
+// with that namespace.
+func xmlMembers(ms *yang.Modules, data []byte) ([]*member, error) {
+	moduleOf := func(ns string) string {
+		if m, err := ms.FindModuleByNamespace(ns); err == nil {
+			return m.Name
+		}
+		return ns
+	}
+
+	d := xml.NewDecoder(bytes.NewReader(data))
+	var roots, stack []*member
+	var prefixes []map[string]string
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			var p map[string]string
+			if len(prefixes) > 0 {
+				p = prefixes[len(prefixes)-1]
+			}
+			for _, a := range tok.Attr {
+				var prefix string
+				switch {
+				case a.Name.Space == "xmlns":
+					prefix = a.Name.Local
+				case a.Name.Space == "" && a.Name.Local == "xmlns":
+				default:
+					continue
+				}
+				np := map[string]string{}
+				for k, v := range p {
+					np[k] = v
+				}
+				np[prefix] = moduleOf(a.Value)
+				p = np
+			}
+			m := &member{
+				module: moduleOf(tok.Name.Space),
+				name:   tok.Name.Local,
+				value:  scalar{prefixes: p},
+			}
+			if m.value.prefixes == nil {
+				m.value.prefixes = map[string]string{}
+			}
+			m.qname = m.module + ":" + m.name
+			if len(stack) > 0 && stack[len(stack)-1].module == m.module {
+				m.qname = m.name
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.object = true
+				parent.children = append(parent.children, m)
+			} else {
+				roots = append(roots, m)
+			}
+			stack = append(stack, m)
+			prefixes = append(prefixes, p)
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].value.text += string(tok)
+			}
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+			prefixes = prefixes[:len(prefixes)-1]
+		}
+	}
+	return roots, nil
+}
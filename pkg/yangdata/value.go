@@ -0,0 +1,263 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+package yangdata
+
+// This file implements checking the values of leaves and leaf-lists.
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+// A scalar is the value of a leaf or leaf-list entry in a document.
+type scalar struct {
+	isJSON   bool
+	json     interface{}       // the decoded JSON value
+	text     string            // the XML text
+	prefixes map[string]string // XML namespace prefixes in scope, mapped to module names
+}
+
+// lexical returns the lexical form of s (RFC 7950 section 9.1).
+func (s scalar) lexical() string {
+	if !s.isJSON {
+		return s.text
+	}
+	switch v := s.json.(type) {
+	case string:
+		return v
+	case json.Number:
+		return string(v)
+	case bool:
+		return fmt.Sprint(v)
+	case []interface{}:
+		if isNullArray(v) {
+			return ""
+		}
+	}
+	return s.String()
+}
+
+// String returns s as it appears in the document, for use in messages.
+func (s scalar) String() string {
+	if !s.isJSON {
+		return fmt.Sprintf("%q", s.text)
+	}
+	b, err := json.Marshal(s.json)
+	if err != nil {
+		return fmt.Sprint(s.json)
+	}
+	return string(b)
+}
+
+// isEmpty reports whether s is XML text containing only white space, as
+// found in elements that only contain child elements.
+func (s scalar) isEmpty() bool {
+	return !s.isJSON && strings.TrimSpace(s.text) == ""
+}
+
+// isNullArray reports whether a is [null], the JSON encoding of the empty
+// type (RFC 7951 section 6.9).
+func isNullArray(a []interface{}) bool {
+	return len(a) == 1 && a[0] == nil
+}
+
+// maxLeafrefDepth limits following leafrefs that refer to other leafrefs.
+const maxLeafrefDepth = 16
+
+// checkScalar returns an error if value is not a valid value of type t of
+// the leaf or leaf-list e.
+func (v *validator) checkScalar(e *yang.Entry, t *yang.YangType, value scalar) error {
+	return v.checkScalarDepth(e, t, value, 0)
+}
+
+func (v *validator) checkScalarDepth(e *yang.Entry, t *yang.YangType, value scalar, depth int) error {
+	switch t.Kind {
+	case yang.Yunion:
+		for _, mt := range t.Type {
+			if v.checkScalarDepth(e, mt, value, depth) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s is not a valid value of any type in union %s", value, t.Name)
+	case yang.Yleafref:
+		if target := yang.LeafrefTarget(e, t); target != nil && target.Type != nil && depth < maxLeafrefDepth {
+			return v.checkScalarDepth(target, target.Type, value, depth+1)
+		}
+		return nil
+	}
+	s := value.lexical()
+	if value.isJSON {
+		if err := checkJSONEncoding(t.Kind, value.json); err != nil {
+			return err
+		}
+	}
+	return v.checkValue(e, t, s, value.prefixes)
+}
+
+// checkJSONEncoding returns an error if the JSON value is not encoded as
+// required by RFC 7951 section 6 for values of kind k.
+func checkJSONEncoding(k yang.TypeKind, value interface{}) error {
+	var ok bool
+	var want string
+	switch k {
+	case yang.Yint8, yang.Yint16, yang.Yint32, yang.Yuint8, yang.Yuint16, yang.Yuint32:
+		_, ok = value.(json.Number)
+		want = "a number"
+	case yang.Ybool:
+		_, ok = value.(bool)
+		want = "true or false"
+	case yang.Yempty:
+		a, isArray := value.([]interface{})
+		ok = isArray && isNullArray(a)
+		want = "[null]"
+	default:
+		_, ok = value.(string)
+		want = "a string"
+	}
+	if !ok {
+		b, _ := json.Marshal(value)
+		return fmt.Errorf("%s value must be encoded as %s, got %s", yang.TypeKindToName[k], want, b)
+	}
+	return nil
+}
+
+var (
+	integerRE = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	decimalRE = regexp.MustCompile(`^[-+]?([0-9]+(\.[0-9]*)?|\.[0-9]+)$`)
+)
+
+// checkValue returns an error if s, the lexical form of a value, is not a
+// valid value of type t, which is not a union or leafref, of the leaf or
+// leaf-list e.  prefixes maps the prefixes of identityref values to module
+// names.  If prefixes is nil, the values are qualified by module names as
+// in JSON.
+func (v *validator) checkValue(e *yang.Entry, t *yang.YangType, s string, prefixes map[string]string) error {
+	switch t.Kind {
+	case yang.Yint8, yang.Yint16, yang.Yint32, yang.Yint64, yang.Yuint8, yang.Yuint16, yang.Yuint32, yang.Yuint64:
+		if !integerRE.MatchString(s) {
+			return fmt.Errorf("%q is not a valid %s", s, t.Name)
+		}
+		n, err := yang.ParseInt(s)
+		if err != nil || !inRange(t.Range, n) {
+			return fmt.Errorf("%s is not within range %v", s, t.Range)
+		}
+	case yang.Ydecimal64:
+		if !decimalRE.MatchString(s) {
+			return fmt.Errorf("%q is not a valid %s", s, t.Name)
+		}
+		n, err := yang.ParseDecimal(s, uint8(t.FractionDigits))
+		if err != nil {
+			return fmt.Errorf("%q is not a valid %s with %d fraction digits", s, t.Name, t.FractionDigits)
+		}
+		if !inRange(t.Range, n) {
+			return fmt.Errorf("%s is not within range %v", s, t.Range)
+		}
+	case yang.Ystring:
+		if n := utf8.RuneCountInString(s); !inRange(t.Length, yang.FromInt(int64(n))) {
+			return fmt.Errorf("length %d of %q is not within %v", n, s, t.Length)
+		}
+		if p, err := t.CompiledPatterns(); err == nil && !p.MatchString(s) {
+			return fmt.Errorf("%q does not match the patterns of %s", s, t.Name)
+		}
+	case yang.Ybinary:
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return fmt.Errorf("%q is not valid base64", s)
+		}
+		if !inRange(t.Length, yang.FromInt(int64(len(b)))) {
+			return fmt.Errorf("length %d is not within %v", len(b), t.Length)
+		}
+	case yang.Ybool:
+		if s != "true" && s != "false" {
+			return fmt.Errorf("%q is not a valid boolean", s)
+		}
+	case yang.Yempty:
+		if s != "" {
+			return fmt.Errorf("%q is not empty", s)
+		}
+	case yang.Yenum:
+		if t.Enum == nil || !t.Enum.IsDefined(s) {
+			return fmt.Errorf("%q is not an enum of %s", s, t.Name)
+		}
+	case yang.Ybits:
+		for _, b := range strings.Fields(s) {
+			if t.Bit == nil || !t.Bit.IsDefined(b) {
+				return fmt.Errorf("%q is not a bit of %s", b, t.Name)
+			}
+		}
+	case yang.Yidentityref:
+		return checkIdentityref(e, t, s, prefixes)
+	}
+	return nil
+}
+
+// checkIdentityref returns an error if s is not the name of an identity
+// derived from the base of t.  An unqualified name refers to an identity of
+// the module of e.
+func checkIdentityref(e *yang.Entry, t *yang.YangType, s string, prefixes map[string]string) error {
+	module, name := "", s
+	if i := strings.Index(s, ":"); i >= 0 {
+		module, name = s[:i], s[i+1:]
+		if prefixes != nil {
+			var ok bool
+			if module, ok = prefixes[module]; !ok {
+				return fmt.Errorf("%q has an undefined namespace prefix", s)
+			}
+		}
+	} else if m, ok := prefixes[""]; ok {
+		module = m
+	} else if m, err := e.InstantiatingModule(); err == nil {
+		module = m
+	}
+	if t.IdentityBase == nil {
+		return nil
+	}
+	for _, i := range t.IdentityBase.Values {
+		if i.Name == name && moduleName(i) == module {
+			return nil
+		}
+	}
+	return fmt.Errorf("%q is not derived from identity %s", s, t.IdentityBase.PrefixedName())
+}
+
+// moduleName returns the name of the module that defines n.
+func moduleName(n yang.Node) string {
+	m := yang.RootNode(n)
+	if m.BelongsTo != nil {
+		return m.BelongsTo.Name
+	}
+	return m.Name
+}
+
+// inRange reports whether n is within r.  All numbers are within an empty
+// range.
+func inRange(r yang.YangRange, n yang.Number) bool {
+	if len(r) == 0 {
+		return true
+	}
+	for _, yr := range r {
+		if !n.Less(yr.Min) && !yr.Max.Less(n) {
+			return true
+		}
+	}
+	return false
+}
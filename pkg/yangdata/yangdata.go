@@ -0,0 +1,382 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+// Package yangdata validates instance data documents against the schema
+// defined by a set of YANG modules.  Documents are either JSON encoded as
+// described by RFC 7951 or XML encoded as used by NETCONF (RFC 7950 section
+// 7.5).
+//
+// A document is checked for nodes that are not in the schema, values that
+// are not valid for the type of their leaf or leaf-list, missing mandatory
+// nodes and list keys, duplicate list entries, the number of entries of
+// lists and leaf-lists, nodes from more than one case of a choice, and
+// leafrefs that require an instance that is not in the document.  Missing
+// nodes are only checked for within the nodes that are in the document.
+// The "when", "must", and "unique" statements are not evaluated.
+package yangdata
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+// A Violation is a single way in which a document does not conform to the
+// schema.
+type Violation struct {
+	// Path is the data path of the offending node in the form used by
+	// yang.Entry.QualifiedPath, with the keys of list entries added,
+	// e.g., "/example:interfaces/interface[name=eth0]/mtu".
+	Path string `json:"path"`
+	// Message describes the violation.
+	Message string `json:"message"`
+}
+
+// String returns v as "path: message".
+func (v *Violation) String() string {
+	return v.Path + ": " + v.Message
+}
+
+// A member is a single node of a decoded document: a container, list
+// entry, leaf, leaf-list entry, or anydata node.
+type member struct {
+	module   string    // name of the module qualifying name
+	name     string    // name of the node
+	qname    string    // name as qualified in the document, for messages
+	object   bool      // true for a JSON object or an XML element with child elements
+	array    bool      // true if the member was an element of a JSON array
+	children []*member // members of an object
+	value    scalar    // value of a leaf or leaf-list entry
+}
+
+// A validator validates the members of a document against the entries of
+// a set of modules.
+type validator struct {
+	ms         *yang.Modules
+	json       bool                                   // the document is JSON encoded
+	violations []*Violation                           // violations found so far
+	children   map[*yang.Entry]map[string]*yang.Entry // see child
+	values     map[*yang.Entry]map[string]bool        // the values of each leaf and leaf-list
+	leafrefs   []leafref                              // leafrefs that require an instance
+}
+
+// A leafref is the value of a leaf or leaf-list of type leafref that
+// requires the value to be in the document.
+type leafref struct {
+	path   string
+	target *yang.Entry
+	value  string
+}
+
+func newValidator(ms *yang.Modules, json bool) *validator {
+	return &validator{
+		ms:       ms,
+		json:     json,
+		children: map[*yang.Entry]map[string]*yang.Entry{},
+		values:   map[*yang.Entry]map[string]bool{},
+	}
+}
+
+func (v *validator) errorf(path, format string, args ...interface{}) {
+	v.violations = append(v.violations, &Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// validate validates the top level members of a document and returns the
+// violations found.
+func (v *validator) validate(members []*member) []*Violation {
+	v.object(nil, "", members)
+	for _, l := range v.leafrefs {
+		if !v.values[l.target][l.value] {
+			v.errorf(l.path, "no %s %s with value %q", kindName(l.target), l.target.QualifiedPath(), l.value)
+		}
+	}
+	return v.violations
+}
+
+// child returns the data node child of e, or the top level data node if e
+// is nil, that the module qualified name module:name refers to, or nil.
+func (v *validator) child(e *yang.Entry, module, name string) *yang.Entry {
+	cs, ok := v.children[e]
+	if !ok {
+		cs = map[string]*yang.Entry{}
+		var add func(*yang.Entry)
+		add = func(e *yang.Entry) {
+			for _, ce := range e.Dir {
+				switch {
+				case ce.RPC != nil || ce.Kind == yang.NotificationEntry:
+				case ce.IsChoice() || ce.IsCase():
+					add(ce)
+				default:
+					if mod, err := ce.InstantiatingModule(); err == nil {
+						cs[mod+":"+ce.Name] = ce
+					}
+				}
+			}
+		}
+		if e != nil {
+			add(e)
+		} else {
+			for _, m := range v.ms.Modules {
+				add(yang.ToEntry(m))
+			}
+		}
+		v.children[e] = cs
+	}
+	return cs[module+":"+name]
+}
+
+// object validates members, the children of e, or the top level members if
+// e is nil.  path is the path of e.  The set of entries that members refer
+// to is returned.
+func (v *validator) object(e *yang.Entry, path string, members []*member) map[*yang.Entry]bool {
+	var order []*yang.Entry
+	groups := map[*yang.Entry][]*member{}
+	for _, m := range members {
+		ce := v.child(e, m.module, m.name)
+		if ce == nil {
+			v.errorf(path+"/"+m.qname, "unknown data node")
+			continue
+		}
+		if groups[ce] == nil {
+			order = append(order, ce)
+		}
+		groups[ce] = append(groups[ce], m)
+	}
+	present := map[*yang.Entry]bool{}
+	for _, ce := range order {
+		present[ce] = true
+		v.node(ce, path+"/"+ce.QualifiedName(), groups[ce])
+	}
+	if e != nil {
+		v.mandatory(e, path, present)
+	}
+	return present
+}
+
+// node validates members, the instances of e.  path is the path of e.
+func (v *validator) node(e *yang.Entry, path string, members []*member) {
+	switch {
+	case e.Kind == yang.AnyDataEntry || e.Kind == yang.AnyXMLEntry:
+		v.single(e, path, members)
+	case e.IsList():
+		v.list(e, path, members)
+	case e.IsLeafList():
+		v.leafList(e, path, members)
+	case e.IsDir():
+		if !v.single(e, path, members) {
+			return
+		}
+		m := members[0]
+		if !m.object && !m.value.isEmpty() {
+			v.errorf(path, "expected a container, got %s", m.value)
+			return
+		}
+		v.object(e, path, m.children)
+	default:
+		if !v.single(e, path, members) {
+			return
+		}
+		m := members[0]
+		if m.object {
+			v.errorf(path, "expected a value of type %s, got child nodes", e.Type.Name)
+			return
+		}
+		v.leaf(e, path, m.value)
+	}
+}
+
+// single reports whether members, the instances of e, consist of a single
+// member that is not an element of a JSON array.
+func (v *validator) single(e *yang.Entry, path string, members []*member) bool {
+	switch {
+	case len(members) > 1:
+		v.errorf(path, "%s appears %d times", kindName(e), len(members))
+		return false
+	case members[0].array:
+		v.errorf(path, "%s is encoded as an array", kindName(e))
+		return false
+	}
+	return true
+}
+
+// list validates members, the entries of the list e.
+func (v *validator) list(e *yang.Entry, path string, members []*member) {
+	v.count(e, path, members)
+	keys := strings.Fields(e.Key)
+	seen := map[string]bool{}
+	for _, m := range members {
+		if v.json && !m.array {
+			v.errorf(path, "list is not encoded as an array")
+			return
+		}
+		if !m.object && !m.value.isEmpty() {
+			v.errorf(path, "expected a list entry, got %s", m.value)
+			continue
+		}
+		// Add the values of the keys to the path of the entry.
+		epath := path
+		for _, k := range keys {
+			for _, cm := range m.children {
+				if cm.name == k && v.child(e, cm.module, cm.name) == e.Dir[k] {
+					epath += "[" + k + "=" + cm.value.lexical() + "]"
+					break
+				}
+			}
+		}
+		present := v.object(e, epath, m.children)
+		for _, k := range keys {
+			if ke := e.Dir[k]; ke != nil && !present[ke] {
+				v.errorf(epath, "missing key %s", k)
+			}
+		}
+		if len(keys) > 0 && epath != path {
+			if seen[epath] {
+				v.errorf(epath, "duplicate list entry")
+			}
+			seen[epath] = true
+		}
+	}
+}
+
+// leafList validates members, the entries of the leaf-list e.
+func (v *validator) leafList(e *yang.Entry, path string, members []*member) {
+	v.count(e, path, members)
+	seen := map[string]bool{}
+	for _, m := range members {
+		if v.json && !m.array {
+			v.errorf(path, "leaf-list is not encoded as an array")
+			return
+		}
+		if m.object {
+			v.errorf(path, "expected a value of type %s, got child nodes", e.Type.Name)
+			continue
+		}
+		s := m.value.lexical()
+		if seen[s] && e.IsConfig() {
+			v.errorf(path, "duplicate value %s", m.value)
+		}
+		seen[s] = true
+		v.leaf(e, path, m.value)
+	}
+}
+
+// count validates the number of entries of the list or leaf-list e.
+func (v *validator) count(e *yang.Entry, path string, members []*member) {
+	if e.ListAttr == nil {
+		return
+	}
+	n := uint64(len(members))
+	switch {
+	case n < e.ListAttr.MinElements:
+		v.errorf(path, "%d entries, fewer than min-elements %d", n, e.ListAttr.MinElements)
+	case n > e.ListAttr.MaxElements:
+		v.errorf(path, "%d entries, more than max-elements %d", n, e.ListAttr.MaxElements)
+	}
+}
+
+// leaf validates the value of the leaf or leaf-list e.
+func (v *validator) leaf(e *yang.Entry, path string, value scalar) {
+	if err := v.checkScalar(e, e.Type, value); err != nil {
+		v.errorf(path, "%v", err)
+		return
+	}
+	s := value.lexical()
+	if v.values[e] == nil {
+		v.values[e] = map[string]bool{}
+	}
+	v.values[e][s] = true
+	if e.Type.Kind == yang.Yleafref && !e.Type.OptionalInstance {
+		if target := yang.LeafrefTarget(e, e.Type); target != nil {
+			v.leafrefs = append(v.leafrefs, leafref{path: path, target: target, value: s})
+		}
+	}
+}
+
+// mandatory validates that the mandatory nodes of e, as well as the lists
+// and leaf-lists with min-elements, are present.  present is the set of
+// children of e in the document.  Choices are followed into the case that
+// is present.
+func (v *validator) mandatory(e *yang.Entry, path string, present map[*yang.Entry]bool) {
+	for _, name := range sortedNames(e.Dir) {
+		ce := e.Dir[name]
+		switch {
+		case ce.RPC != nil || ce.Kind == yang.NotificationEntry:
+		case ce.IsChoice():
+			var cases []*yang.Entry
+			for _, cname := range sortedNames(ce.Dir) {
+				if c := ce.Dir[cname]; hasPresent(c, present) {
+					cases = append(cases, c)
+				}
+			}
+			switch len(cases) {
+			case 0:
+				if ce.Mandatory == yang.TSTrue {
+					v.errorf(path, "missing mandatory choice %s", ce.Name)
+				}
+			case 1:
+				v.mandatory(cases[0], path, present)
+			default:
+				v.errorf(path, "nodes from more than one case of choice %s: %s and %s", ce.Name, cases[0].Name, cases[1].Name)
+			}
+		case present[ce]:
+		case ce.Mandatory == yang.TSTrue:
+			v.errorf(path+"/"+ce.QualifiedName(), "missing mandatory %s", kindName(ce))
+		case ce.ListAttr != nil && ce.ListAttr.MinElements > 0:
+			v.errorf(path+"/"+ce.QualifiedName(), "0 entries, fewer than min-elements %d", ce.ListAttr.MinElements)
+		}
+	}
+}
+
+// hasPresent reports whether e, or a data node below e through choices and
+// cases, is in present.
+func hasPresent(e *yang.Entry, present map[*yang.Entry]bool) bool {
+	if !e.IsChoice() && !e.IsCase() {
+		return present[e]
+	}
+	for _, ce := range e.Dir {
+		if hasPresent(ce, present) {
+			return true
+		}
+	}
+	return false
+}
+
+// kindName returns the YANG keyword of e, e.g., "leaf-list".
+func kindName(e *yang.Entry) string {
+	switch {
+	case e.Kind == yang.AnyDataEntry:
+		return "anydata"
+	case e.Kind == yang.AnyXMLEntry:
+		return "anyxml"
+	case e.IsList():
+		return "list"
+	case e.IsLeafList():
+		return "leaf-list"
+	case e.IsDir():
+		return "container"
+	}
+	return "leaf"
+}
+
+func sortedNames(d map[string]*yang.Entry) []string {
+	names := make([]string, 0, len(d))
+	for name := range d {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,200 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+package yangdata
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/gnmi/errdiff"
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+const testModule = `module ex {
+  prefix ex;
+  namespace "urn:ex";
+  identity base-id;
+  identity a { base base-id; }
+  typedef pct { type uint8 { range 0..100; } }
+  container top {
+    leaf name { type string { length 1..4; pattern '[a-z]+'; } mandatory true; }
+    leaf pct { type pct; }
+    leaf big { type int64; }
+    leaf dec { type decimal64 { fraction-digits 2; } }
+    leaf flag { type empty; }
+    leaf on { type boolean; }
+    leaf color { type enumeration { enum red; enum blue; } }
+    leaf kind { type identityref { base base-id; } }
+    leaf ref { type leafref { path "../item/id"; } }
+    leaf-list tags { type string; max-elements 2; }
+    list item {
+      key id;
+      leaf id { type uint16; }
+      leaf v { type union { type int8; type enumeration { enum none; } } }
+    }
+    choice ch {
+      case x { leaf x1 { type string; } }
+      case y { leaf y1 { type string; } }
+    }
+  }
+}`
+
+func testModules(t *testing.T) *yang.Modules {
+	t.Helper()
+	ms := yang.NewModules()
+	if err := ms.Parse(testModule, "ex.yang"); err != nil {
+		t.Fatal(err)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	return ms
+}
+
+func violations(vs []*Violation) []string {
+	var s []string
+	for _, v := range vs {
+		s = append(s, v.String())
+	}
+	return s
+}
+
+func TestValidateJSON(t *testing.T) {
+	ms := testModules(t)
+	for _, tt := range []struct {
+		desc    string
+		in      string
+		want    []string
+		wantErr string
+	}{{
+		desc: "valid",
+		in: `{"ex:top": {
+  "name": "abc", "pct": 100, "big": "-5", "dec": "1.25", "flag": [null],
+  "on": true, "color": "blue", "kind": "ex:a", "ref": 7,
+  "tags": ["x", "y"], "item": [{"id": 7, "v": "none"}, {"id": 8, "v": -3}],
+  "x1": "x"
+}}`,
+	}, {
+		desc: "restconf data",
+		in:   `{"ietf-restconf:data": {"ex:top": {"name": "a"}}}`,
+	}, {
+		desc: "bad values",
+		in: `{"ex:top": {
+  "name": "abcde", "pct": 101, "big": 5, "dec": "1.255", "flag": null,
+  "on": "true", "color": "green", "kind": "a", "ref": 9
+}}`,
+		want: []string{
+			`/ex:top/big: int64 value must be encoded as a string, got 5`,
+			`/ex:top/color: "green" is not an enum of enumeration`,
+			`/ex:top/dec: "1.255" is not a valid decimal64 with 2 fraction digits`,
+			`/ex:top/flag: empty value must be encoded as [null], got null`,
+			`/ex:top/name: length 5 of "abcde" is not within 1..4`,
+			`/ex:top/on: boolean value must be encoded as true or false, got "true"`,
+			`/ex:top/pct: 101 is not within range 0..100`,
+			`/ex:top/ref: no leaf /ex:top/item/id with value "9"`,
+		},
+	}, {
+		desc: "structure",
+		in: `{"ex:top": {
+  "bogus": 1, "tags": ["a", "b", "a"], "item": [{"id": 1}, {"id": 1}, {"v": 2}],
+  "x1": "a", "y1": "b"
+}, "other:top": {}}`,
+		want: []string{
+			`/other:top: unknown data node`,
+			`/ex:top/bogus: unknown data node`,
+			`/ex:top/item[id=1]: duplicate list entry`,
+			`/ex:top/item: missing key id`,
+			`/ex:top/tags: 3 entries, more than max-elements 2`,
+			`/ex:top/tags: duplicate value "a"`,
+			`/ex:top: nodes from more than one case of choice ch: x and y`,
+			`/ex:top/name: missing mandatory leaf`,
+		},
+	}, {
+		desc: "arrays",
+		in:   `{"ex:top": {"name": ["a"], "item": {"id": 1}}}`,
+		want: []string{
+			`/ex:top/item: list is not encoded as an array`,
+			`/ex:top/name: leaf is encoded as an array`,
+		},
+	}, {
+		desc:    "not an object",
+		in:      `[1]`,
+		wantErr: "not a JSON object",
+	}, {
+		desc:    "bad JSON",
+		in:      `{`,
+		wantErr: "unexpected EOF",
+	}} {
+		vs, err := ValidateJSON(ms, []byte(tt.in))
+		if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+			t.Errorf("%s: %s", tt.desc, diff)
+			continue
+		}
+		if diff := cmp.Diff(tt.want, violations(vs)); diff != "" {
+			t.Errorf("%s: ValidateJSON (-want, +got):\n%s", tt.desc, diff)
+		}
+	}
+}
+
+func TestValidateXML(t *testing.T) {
+	ms := testModules(t)
+	for _, tt := range []struct {
+		desc    string
+		in      string
+		want    []string
+		wantErr string
+	}{{
+		desc: "valid",
+		in: `<config xmlns="urn:ietf:params:xml:ns:netconf:base:1.0">
+  <top xmlns="urn:ex" xmlns:e="urn:ex">
+    <name>abc</name><big>-5</big><flag/><kind>e:a</kind>
+    <tags>x</tags><tags>y</tags>
+    <item><id>7</id><v>none</v></item>
+    <ref>7</ref>
+  </top>
+</config>`,
+	}, {
+		desc: "bad values",
+		in: `<top xmlns="urn:ex">
+  <name>abc</name><name>d</name><pct>x</pct><flag>1</flag><kind>x:a</kind>
+  <item><id>1</id><v>200</v></item>
+</top>`,
+		want: []string{
+			`/ex:top/name: leaf appears 2 times`,
+			`/ex:top/pct: "x" is not a valid pct`,
+			`/ex:top/flag: "1" is not empty`,
+			`/ex:top/kind: "x:a" has an undefined namespace prefix`,
+			`/ex:top/item[id=1]/v: "200" is not a valid value of any type in union union`,
+		},
+	}, {
+		desc: "unknown namespace",
+		in:   `<top xmlns="urn:other"/>`,
+		want: []string{`/urn:other:top: unknown data node`},
+	}, {
+		desc:    "bad XML",
+		in:      `<top>`,
+		wantErr: "unexpected EOF",
+	}} {
+		vs, err := ValidateXML(ms, []byte(tt.in))
+		if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+			t.Errorf("%s: %s", tt.desc, diff)
+			continue
+		}
+		if diff := cmp.Diff(tt.want, violations(vs)); diff != "" {
+			t.Errorf("%s: ValidateXML (-want, +got):\n%s", tt.desc, diff)
+		}
+	}
+}
@@ -0,0 +1,574 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package yangdiff reports the changes between two revisions of YANG
+// modules and classifies them using the module update rules of RFC 7950
+// section 11.  A change is compatible if a client written for the old
+// revision continues to work with the new one, e.g., adding a node that is
+// not mandatory, widening a range, or deprecating a node.  Removing or
+// renaming nodes, narrowing the values a type allows, or adding mandatory
+// nodes and constraints are incompatible changes.
+//
+// The comparison is made between the Entry trees of the modules, so
+// changes that do not change the schema, such as moving nodes into a
+// grouping or renaming a typedef, are not reported.  Changes to
+// descriptions and references are not reported either.
+package yangdiff
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+// Kind is the kind of a Change.
+type Kind int
+
+const (
+	// Added is a node or module that is only in the new revision.
+	Added Kind = iota
+	// Removed is a node or module that is only in the old revision.
+	Removed
+	// Changed is a node or module that is in both revisions but differs.
+	Changed
+)
+
+var kindNames = map[Kind]string{
+	Added:   "added",
+	Removed: "removed",
+	Changed: "changed",
+}
+
+func (k Kind) String() string {
+	if s, ok := kindNames[k]; ok {
+		return s
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// A Change is a single difference between two revisions.
+type Change struct {
+	Kind Kind
+	// Path is the path of the node that changed, as returned by
+	// yang.Entry.Path, or the name of a module.
+	Path string
+	// Message describes the change, e.g., "added mandatory leaf" or
+	// "range 0..100 -> 0..10".
+	Message string
+	// Compatible is true if the change is allowed by RFC 7950 section 11.
+	Compatible bool
+}
+
+// String returns c as "path: message", followed by " (incompatible)" if c
+// is not compatible.
+func (c *Change) String() string {
+	s := c.Path + ": " + c.Message
+	if !c.Compatible {
+		s += " (incompatible)"
+	}
+	return s
+}
+
+// Incompatible returns the changes of cs that are not compatible.
+func Incompatible(cs []*Change) []*Change {
+	var ics []*Change
+	for _, c := range cs {
+		if !c.Compatible {
+			ics = append(ics, c)
+		}
+	}
+	return ics
+}
+
+// Modules returns the changes from the modules of old to the modules of
+// new, which must both have been processed.  Modules are matched by name.
+// Submodules are compared as part of the modules they belong to.  The
+// changes are sorted by path.
+func Modules(old, new *yang.Modules) []*Change {
+	var cs []*Change
+	for _, name := range moduleNames(old, new) {
+		om, nm := old.Modules[name], new.Modules[name]
+		switch {
+		case om == nil:
+			cs = append(cs, &Change{Kind: Added, Path: name, Message: "added module", Compatible: true})
+		case nm == nil:
+			cs = append(cs, &Change{Kind: Removed, Path: name, Message: "removed module"})
+		default:
+			cs = append(cs, Entries(yang.ToEntry(om), yang.ToEntry(nm))...)
+		}
+	}
+	sortChanges(cs)
+	return cs
+}
+
+// moduleNames returns the names of the modules of old and new, each once,
+// in sorted order.
+func moduleNames(old, new *yang.Modules) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, ms := range []*yang.Modules{old, new} {
+		for _, m := range ms.Modules {
+			if !seen[m.Name] {
+				seen[m.Name] = true
+				names = append(names, m.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Entries returns the changes from the Entry tree old to the Entry tree
+// new, sorted by path.  old and new are normally the entries of two
+// revisions of a module.
+func Entries(old, new *yang.Entry) []*Change {
+	d := &differ{}
+	d.entry(old, new)
+	if om, ok := old.Node.(*yang.Module); ok {
+		if nm, ok := new.Node.(*yang.Module); ok {
+			d.revision(new.Path(), om, nm)
+		}
+	}
+	sortChanges(d.changes)
+	return d.changes
+}
+
+func sortChanges(cs []*Change) {
+	sort.SliceStable(cs, func(i, j int) bool { return cs[i].Path < cs[j].Path })
+}
+
+// A differ collects the changes between two Entry trees.
+type differ struct {
+	changes []*Change
+}
+
+func (d *differ) add(kind Kind, path string, compatible bool, format string, v ...interface{}) {
+	d.changes = append(d.changes, &Change{
+		Kind:       kind,
+		Path:       path,
+		Message:    fmt.Sprintf(format, v...),
+		Compatible: compatible,
+	})
+}
+
+// changed records a change to path.
+func (d *differ) changed(path string, compatible bool, format string, v ...interface{}) {
+	d.add(Changed, path, compatible, format, v...)
+}
+
+// revision records a module whose revision was not updated, or that went
+// backwards.  RFC 7950 requires a new revision statement for every
+// published change, so an unchanged revision is only reported if there are
+// other changes.
+func (d *differ) revision(path string, om, nm *yang.Module) {
+	switch oc, nc := om.Current(), nm.Current(); {
+	case oc == nc:
+		if len(d.changes) > 0 {
+			d.changed(path, false, "revision %s not updated", orNone(nc))
+		}
+	case nc < oc:
+		d.changed(path, false, "revision %s -> %s is older", orNone(oc), orNone(nc))
+	}
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// kind returns the kind of statement e was built from, e.g., "leaf-list".
+func kind(e *yang.Entry) string {
+	switch {
+	case e.IsLeafList():
+		return "leaf-list"
+	case e.IsList():
+		return "list"
+	case e.IsChoice():
+		return "choice"
+	case e.IsCase():
+		return "case"
+	case e.IsContainer():
+		return "container"
+	case e.IsLeaf():
+		return "leaf"
+	}
+	return "node"
+}
+
+// mandatory reports whether e is a mandatory node, i.e., a mandatory leaf
+// or choice, a list or leaf-list with a min-elements greater than zero, or
+// a container without a presence statement that has a mandatory child.
+func mandatory(e *yang.Entry) bool {
+	switch {
+	case e.Mandatory == yang.TSTrue:
+		return true
+	case e.ListAttr != nil:
+		return e.ListAttr.MinElements > 0
+	case e.IsContainer() && len(e.Extra["presence"]) == 0:
+		for _, ce := range e.Dir {
+			if mandatory(ce) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// entry records the changes from old to new, which have the same path.
+func (d *differ) entry(old, new *yang.Entry) {
+	path := new.Path()
+	if ok, nk := kind(old), kind(new); ok != nk {
+		d.changed(path, false, "%s -> %s", ok, nk)
+		return
+	}
+	d.config(path, old, new)
+	d.mandatory(path, old, new)
+	d.list(path, old, new)
+	d.value(path, "default", old.Default, new.Default)
+	d.value(path, "units", old.Units, new.Units)
+	d.status(path, status(old), status(new))
+	d.ifFeatures(path, ifFeatures(old), ifFeatures(new))
+	d.conditions(path, "when", conditions(old.When), conditions(new.When))
+	d.conditions(path, "must", conditions(old.Must...), conditions(new.Must...))
+	if old.Type != nil && new.Type != nil {
+		d.typ(path, "type", old.Type, new.Type)
+	}
+	d.children(old.Dir, new.Dir)
+	if old.RPC != nil && new.RPC != nil {
+		d.rpcPart(old.RPC.Input, new.RPC.Input)
+		d.rpcPart(old.RPC.Output, new.RPC.Output)
+	}
+}
+
+// rpcPart compares the input or output of an RPC or action.
+func (d *differ) rpcPart(old, new *yang.Entry) {
+	switch {
+	case old == nil && new == nil:
+	case old == nil:
+		d.children(nil, new.Dir)
+	case new == nil:
+		d.children(old.Dir, nil)
+	default:
+		d.children(old.Dir, new.Dir)
+	}
+}
+
+// children records the changes between the children of two entries.
+func (d *differ) children(old, new map[string]*yang.Entry) {
+	for _, name := range sortedNames(old) {
+		oe := old[name]
+		if ne := new[name]; ne != nil {
+			d.entry(oe, ne)
+		} else {
+			d.add(Removed, oe.Path(), false, "removed %s", kind(oe))
+		}
+	}
+	for _, name := range sortedNames(new) {
+		ne := new[name]
+		if old[name] != nil {
+			continue
+		}
+		if mandatory(ne) && !ne.IsCase() {
+			d.add(Added, ne.Path(), false, "added mandatory %s", kind(ne))
+		} else {
+			d.add(Added, ne.Path(), true, "added %s", kind(ne))
+		}
+	}
+}
+
+func sortedNames(dir map[string]*yang.Entry) []string {
+	names := make([]string, 0, len(dir))
+	for name := range dir {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// config records a change between configuration and state data.  State
+// data may become configuration data if it is not mandatory.
+func (d *differ) config(path string, old, new *yang.Entry) {
+	or, nr := old.ReadOnly(), new.ReadOnly()
+	switch {
+	case or == nr:
+	case nr:
+		d.changed(path, false, "config true -> false")
+	default:
+		d.changed(path, !mandatory(new), "config false -> true")
+	}
+}
+
+// mandatory records a change to the mandatory statement.
+func (d *differ) mandatory(path string, old, new *yang.Entry) {
+	om, nm := old.Mandatory == yang.TSTrue, new.Mandatory == yang.TSTrue
+	if om != nm {
+		d.changed(path, om, "mandatory %v -> %v", om, nm)
+	}
+}
+
+// list records the changes to the key and the number of elements of a
+// list or leaf-list.
+func (d *differ) list(path string, old, new *yang.Entry) {
+	if old.Key != new.Key {
+		d.changed(path, false, "key %q -> %q", old.Key, new.Key)
+	}
+	if old.ListAttr == nil || new.ListAttr == nil {
+		return
+	}
+	oa, na := old.ListAttr, new.ListAttr
+	if oa.MinElements != na.MinElements {
+		d.changed(path, na.MinElements < oa.MinElements, "min-elements %d -> %d", oa.MinElements, na.MinElements)
+	}
+	if oa.MaxElements != na.MaxElements {
+		d.changed(path, na.MaxElements > oa.MaxElements, "max-elements %s -> %s", maxElements(oa.MaxElements), maxElements(na.MaxElements))
+	}
+	if ob, nb := orderedBy(oa), orderedBy(na); ob != nb {
+		d.changed(path, false, "ordered-by %s -> %s", ob, nb)
+	}
+}
+
+func maxElements(n uint64) string {
+	if n == yang.NewDefaultListAttr().MaxElements {
+		return "unbounded"
+	}
+	return fmt.Sprint(n)
+}
+
+func orderedBy(a *yang.ListAttr) string {
+	if a.OrderedBy == nil {
+		return "system"
+	}
+	return a.OrderedBy.Name
+}
+
+// value records a change to a statement, such as default, that may be
+// added but not changed or removed.
+func (d *differ) value(path, keyword, old, new string) {
+	switch {
+	case old == new:
+	case old == "":
+		d.changed(path, true, "added %s %s", keyword, new)
+	case new == "":
+		d.changed(path, false, "removed %s %s", keyword, old)
+	default:
+		d.changed(path, false, "%s %s -> %s", keyword, old, new)
+	}
+}
+
+// statusRank orders the values of the status statement.
+var statusRank = map[string]int{"current": 0, "deprecated": 1, "obsolete": 2}
+
+// status records a change to the status statement, which may only become
+// less current.
+func (d *differ) status(path, old, new string) {
+	if old != new {
+		d.changed(path, statusRank[new] > statusRank[old], "status %s -> %s", old, new)
+	}
+}
+
+// status returns the status of e.
+func status(e *yang.Entry) string {
+	for _, v := range values(e.Extra["status"]) {
+		return v
+	}
+	return "current"
+}
+
+// ifFeatures returns the if-feature expressions of e.
+func ifFeatures(e *yang.Entry) []string {
+	return values(e.Extra["if-feature"])
+}
+
+// values returns the arguments of the statements in the Extra field xs.
+func values(xs []interface{}) []string {
+	var vs []string
+	for _, x := range xs {
+		switch x := x.(type) {
+		case *yang.Value:
+			if x != nil {
+				vs = append(vs, x.Name)
+			}
+		case []*yang.Value:
+			for _, v := range x {
+				vs = append(vs, v.Name)
+			}
+		}
+	}
+	return vs
+}
+
+// ifFeatures records the if-feature statements added or removed.  Adding
+// an if-feature statement may make a node unavailable.
+func (d *differ) ifFeatures(path string, old, new []string) {
+	d.set(path, "if-feature", old, new, false, true)
+}
+
+// conditions returns the normalized expressions of cs.
+func conditions(cs ...*yang.Condition) []string {
+	var xs []string
+	for _, c := range cs {
+		if c == nil {
+			continue
+		}
+		if c.XPath != nil {
+			xs = append(xs, c.XPath.String())
+		} else {
+			xs = append(xs, c.Expr)
+		}
+	}
+	return xs
+}
+
+// conditions records the when or must statements that were added or
+// removed.  A changed expression is reported as the removal of the old
+// expression and the addition of the new one, as whether the constraint
+// was relaxed cannot be determined.
+func (d *differ) conditions(path, keyword string, old, new []string) {
+	d.set(path, keyword, old, new, false, true)
+}
+
+// set records the elements that were added to, or removed from, a set of
+// statements.  added and removed are whether such changes are compatible.
+func (d *differ) set(path, keyword string, old, new []string, added, removed bool) {
+	in := func(s string, ss []string) bool {
+		for _, x := range ss {
+			if x == s {
+				return true
+			}
+		}
+		return false
+	}
+	for _, s := range old {
+		if !in(s, new) {
+			d.changed(path, removed, "removed %s %q", keyword, s)
+		}
+	}
+	for _, s := range new {
+		if !in(s, old) {
+			d.changed(path, added, "added %s %q", keyword, s)
+		}
+	}
+}
+
+// typ records the changes from the type old to the type new.  what names
+// the type in messages, e.g., "type" or "type member 2".
+func (d *differ) typ(path, what string, old, new *yang.YangType) {
+	if old.Kind != new.Kind {
+		d.changed(path, false, "%s %s -> %s", what, typeName(old), typeName(new))
+		return
+	}
+	switch old.Kind {
+	case yang.Yenum:
+		d.enums(path, what, "enum", old.Enum, new.Enum)
+	case yang.Ybits:
+		d.enums(path, what, "bit", old.Bit, new.Bit)
+	case yang.Yleafref:
+		if old.Path != new.Path {
+			d.changed(path, false, "%s path %s -> %s", what, old.Path, new.Path)
+		}
+	case yang.Yidentityref:
+		if on, nn := identityName(old.IdentityBase), identityName(new.IdentityBase); on != nn {
+			d.changed(path, false, "%s base %s -> %s", what, on, nn)
+		}
+	case yang.Ydecimal64:
+		if old.FractionDigits != new.FractionDigits {
+			d.changed(path, false, "%s fraction-digits %d -> %d", what, old.FractionDigits, new.FractionDigits)
+		}
+	case yang.Yunion:
+		if len(new.Type) < len(old.Type) {
+			d.changed(path, false, "%s union members %d -> %d", what, len(old.Type), len(new.Type))
+		}
+		for i, ot := range old.Type {
+			if i < len(new.Type) {
+				d.typ(path, fmt.Sprintf("%s member %d", what, i+1), ot, new.Type[i])
+			}
+		}
+		if len(new.Type) > len(old.Type) {
+			d.changed(path, true, "%s union members %d -> %d", what, len(old.Type), len(new.Type))
+		}
+	}
+	if !old.Range.Equal(new.Range) {
+		d.changed(path, contains(new.Range, old.Range), "%s range %s -> %s", what, old.Range, new.Range)
+	}
+	if !old.Length.Equal(new.Length) {
+		d.changed(path, contains(new.Length, old.Length), "%s length %s -> %s", what, old.Length, new.Length)
+	}
+	d.set(path, what+" pattern", old.Pattern, new.Pattern, false, true)
+	d.set(path, what+" posix-pattern", old.POSIXPattern, new.POSIXPattern, false, true)
+	d.set(path, what+" inverted pattern", old.InvertedPattern, new.InvertedPattern, false, true)
+	if old.OptionalInstance != new.OptionalInstance {
+		d.changed(path, new.OptionalInstance, "%s require-instance %v -> %v", what, !old.OptionalInstance, !new.OptionalInstance)
+	}
+}
+
+// typeName returns the name of the built-in type of t.
+func typeName(t *yang.YangType) string {
+	return yang.TypeKindToName[t.Kind]
+}
+
+func identityName(i *yang.Identity) string {
+	if i == nil {
+		return "none"
+	}
+	return i.PrefixedName()
+}
+
+// enums records the changes to the values of an enumeration or bits type.
+// Values may be added, but not removed or renumbered.
+func (d *differ) enums(path, what, keyword string, old, new *yang.EnumType) {
+	if old == nil || new == nil {
+		return
+	}
+	om, nm := old.NameMap(), new.NameMap()
+	for _, name := range old.Names() {
+		v := om[name]
+		nv, ok := nm[name]
+		switch {
+		case !ok:
+			d.changed(path, false, "%s removed %s %s", what, keyword, name)
+		case nv != v:
+			d.changed(path, false, "%s %s %s value %d -> %d", what, keyword, name, v, nv)
+		}
+	}
+	for _, name := range new.Names() {
+		if _, ok := om[name]; ok {
+			continue
+		}
+		d.changed(path, true, "%s added %s %s", what, keyword, name)
+	}
+}
+
+// contains reports whether every value allowed by the range old is allowed
+// by the range new.
+func contains(new, old yang.YangRange) bool {
+	if len(new) == 0 {
+		return true
+	}
+	if len(old) == 0 {
+		return false
+	}
+next:
+	for _, o := range old {
+		for _, n := range new {
+			if !o.Min.Less(n.Min) && !n.Max.Less(o.Max) {
+				continue next
+			}
+		}
+		return false
+	}
+	return true
+}
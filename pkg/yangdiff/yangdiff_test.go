@@ -0,0 +1,161 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yangdiff
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+func process(t *testing.T, mods map[string]string) *yang.Modules {
+	t.Helper()
+	ms := yang.NewModules()
+	for name, text := range mods {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatalf("Parse(%s): %v", name, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatalf("Process: %v", errs)
+	}
+	return ms
+}
+
+func TestModules(t *testing.T) {
+	const head = `module m {
+  prefix m;
+  namespace urn:m;
+`
+	for _, tt := range []struct {
+		desc string
+		old  string
+		new  string
+		want []string
+	}{{
+		desc: "no changes",
+		old:  head + `revision 2020-01-01; leaf a { type string; } }`,
+		new:  head + `revision 2020-01-01; leaf a { type string; } }`,
+	}, {
+		desc: "added and removed nodes",
+		old: head + `revision 2020-01-01;
+  container c { leaf a { type string; } leaf b { type string; } } }`,
+		new: head + `revision 2021-01-01;
+  container c {
+    leaf b { type string; }
+    leaf x { type string; }
+    leaf y { type string; mandatory true; }
+  }
+}`,
+		want: []string{
+			"/m/c/a: removed leaf (incompatible)",
+			"/m/c/x: added leaf",
+			"/m/c/y: added mandatory leaf (incompatible)",
+		},
+	}, {
+		desc: "revision not updated",
+		old:  head + `revision 2020-01-01; leaf a { type string; } }`,
+		new:  head + `revision 2020-01-01; leaf a { type string; } leaf b { type string; } }`,
+		want: []string{
+			"/m: revision 2020-01-01 not updated (incompatible)",
+			"/m/b: added leaf",
+		},
+	}, {
+		desc: "types",
+		old: head + `revision 2020-01-01;
+  leaf r { type int32 { range "0..100"; } }
+  leaf s { type int32 { range "0..100"; } }
+  leaf e { type enumeration { enum one; enum two; } }
+  leaf f { type enumeration { enum one; enum two; } }
+  leaf k { type string; }
+  leaf p { type string { pattern "[a-z]*"; } }
+}`,
+		new: head + `revision 2021-01-01;
+  leaf r { type int32 { range "0..1000"; } }
+  leaf s { type int32 { range "0..10"; } }
+  leaf e { type enumeration { enum one; enum two; enum three; } }
+  leaf f { type enumeration { enum one; } }
+  leaf k { type int32; }
+  leaf p { type string; }
+}`,
+		want: []string{
+			"/m/e: type added enum three",
+			"/m/f: type removed enum two (incompatible)",
+			"/m/k: type string -> int32 (incompatible)",
+			"/m/p: removed type pattern \"[a-z]*\"",
+			"/m/r: type range 0..100 -> 0..1000",
+			"/m/s: type range 0..100 -> 0..10 (incompatible)",
+		},
+	}, {
+		desc: "properties",
+		old: head + `revision 2020-01-01;
+  leaf a { type string; mandatory true; }
+  leaf b { type string; }
+  leaf c { type string; status deprecated; }
+  leaf d { type string; default x; }
+  leaf w { type string; when "../a = 'x'"; }
+  list l { key k; max-elements 10; leaf k { type string; } }
+  container s { config false; leaf v { type string; } }
+}`,
+		new: head + `revision 2021-01-01;
+  leaf a { type string; }
+  leaf b { type string; mandatory true; }
+  leaf c { type string; status current; }
+  leaf d { type string; default y; }
+  leaf w { type string; when "../a='x'"; }
+  list l { key k; max-elements 5; leaf k { type string; } }
+  container s { leaf v { type string; } }
+}`,
+		want: []string{
+			"/m/a: mandatory true -> false",
+			"/m/b: mandatory false -> true (incompatible)",
+			"/m/c: status deprecated -> current (incompatible)",
+			"/m/d: default x -> y (incompatible)",
+			"/m/l: max-elements 10 -> 5 (incompatible)",
+			"/m/s: config false -> true",
+			"/m/s/v: config false -> true",
+		},
+	}} {
+		t.Run(tt.desc, func(t *testing.T) {
+			old := process(t, map[string]string{"m": tt.old})
+			new := process(t, map[string]string{"m": tt.new})
+			var got []string
+			for _, c := range Modules(old, new) {
+				got = append(got, c.String())
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Modules (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAddedRemovedModules(t *testing.T) {
+	old := process(t, map[string]string{"a": `module a { prefix a; namespace urn:a; }`})
+	new := process(t, map[string]string{"b": `module b { prefix b; namespace urn:b; }`})
+	cs := Modules(old, new)
+	var got []string
+	for _, c := range cs {
+		got = append(got, c.Kind.String()+" "+c.String())
+	}
+	want := []string{"removed a: removed module (incompatible)", "added b: added module"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Modules (-want, +got):\n%s", diff)
+	}
+	if got := len(Incompatible(cs)); got != 1 {
+		t.Errorf("got %d incompatible changes, want 1", got)
+	}
+}
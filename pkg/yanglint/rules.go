@@ -0,0 +1,121 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+package yanglint
+
+// This file contains the rules.
+
+import (
+	"regexp"
+
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+// rules are all the rules.  Add new rules here.
+var rules = []*Rule{
+	{
+		Name:        "description",
+		Description: "definitions and data nodes have a description (RFC 8407 section 4.14)",
+		Severity:    Warning,
+		check:       checkDescription,
+	},
+	{
+		Name:        "identifier",
+		Description: "identifiers use only lowercase letters, digits, and hyphens (RFC 8407 section 4.3.1)",
+		Severity:    Info,
+		check:       checkIdentifier,
+	},
+	{
+		Name:        "revision",
+		Description: "modules and submodules have a revision statement (RFC 8407 section 4.8)",
+		Severity:    Error,
+		check:       checkRevision,
+	},
+	{
+		Name:        "revision-order",
+		Description: "revision statements are in reverse chronological order (RFC 7950 section 7.1.9)",
+		Severity:    Warning,
+		check:       checkRevisionOrder,
+	},
+}
+
+// described is the set of keywords of the statements that should have a
+// description.
+var described = map[string]bool{
+	"module":       true,
+	"submodule":    true,
+	"container":    true,
+	"list":         true,
+	"leaf":         true,
+	"leaf-list":    true,
+	"choice":       true,
+	"anydata":      true,
+	"anyxml":       true,
+	"typedef":      true,
+	"identity":     true,
+	"grouping":     true,
+	"rpc":          true,
+	"action":       true,
+	"notification": true,
+	"feature":      true,
+	"extension":    true,
+}
+
+// find returns the first substatement of s with keyword, or nil.
+func find(s *yang.Statement, keyword string) *yang.Statement {
+	for _, ss := range s.SubStatements() {
+		if ss.Keyword == keyword {
+			return ss
+		}
+	}
+	return nil
+}
+
+func checkDescription(s *yang.Statement, report func(*yang.Statement, string, ...interface{})) {
+	if described[s.Keyword] && find(s, "description") == nil {
+		report(s, "%s %s has no description", s.Keyword, s.Argument)
+	}
+}
+
+// identifierRE matches the identifiers recommended by RFC 8407.
+var identifierRE = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+func checkIdentifier(s *yang.Statement, report func(*yang.Statement, string, ...interface{})) {
+	if described[s.Keyword] && !identifierRE.MatchString(s.Argument) {
+		report(s, "%s %s should use only lowercase letters, digits, and hyphens", s.Keyword, s.Argument)
+	}
+}
+
+func checkRevision(s *yang.Statement, report func(*yang.Statement, string, ...interface{})) {
+	if (s.Keyword == "module" || s.Keyword == "submodule") && find(s, "revision") == nil {
+		report(s, "%s %s has no revision", s.Keyword, s.Argument)
+	}
+}
+
+func checkRevisionOrder(s *yang.Statement, report func(*yang.Statement, string, ...interface{})) {
+	if s.Keyword != "module" && s.Keyword != "submodule" {
+		return
+	}
+	var prev *yang.Statement
+	for _, ss := range s.SubStatements() {
+		if ss.Keyword != "revision" {
+			continue
+		}
+		if prev != nil && ss.Argument >= prev.Argument {
+			report(ss, "revision %s follows revision %s", ss.Argument, prev.Argument)
+		}
+		prev = ss
+	}
+}
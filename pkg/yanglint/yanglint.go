@@ -0,0 +1,216 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+// Package yanglint checks YANG modules against a set of style rules, such
+// as those of RFC 8407, that go beyond what is required to process them.
+// Each Rule has a name and a default severity.  Lint runs a selection of
+// the rules, with the severity of each optionally overridden, and returns
+// the Findings.  WriteSARIF writes findings in the SARIF format used by
+// code scanning tools.
+package yanglint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+// Severity is the severity of a Finding.
+type Severity int
+
+const (
+	// Off disables a rule when used as a severity override.
+	Off Severity = iota
+	// Info is the severity of a finding that is only informational.
+	Info
+	// Warning is the severity of a finding that should be fixed.
+	Warning
+	// Error is the severity of a finding that must be fixed.
+	Error
+)
+
+var severityNames = map[Severity]string{
+	Off:     "off",
+	Info:    "info",
+	Warning: "warning",
+	Error:   "error",
+}
+
+func (s Severity) String() string {
+	if n, ok := severityNames[s]; ok {
+		return n
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// MarshalText returns the name of s, e.g., "warning".
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ParseSeverity returns the Severity named name, e.g., "warning".
+func ParseSeverity(name string) (Severity, error) {
+	for s, n := range severityNames {
+		if n == name {
+			return s, nil
+		}
+	}
+	return Off, fmt.Errorf("unknown severity %q", name)
+}
+
+// A Rule is a single check of a module.  The check function is called for
+// each statement of a module, including the module statement itself, and
+// calls report for each problem it finds.
+type Rule struct {
+	Name        string
+	Description string
+	Severity    Severity // default severity of the findings of the rule
+	check       func(s *yang.Statement, report func(s *yang.Statement, format string, v ...interface{}))
+}
+
+// A Finding is a problem found by a Rule.
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	File     string   `json:"file,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// String returns f as "file:line:col: severity: message [rule]".
+func (f *Finding) String() string {
+	loc := f.File
+	if f.Line > 0 {
+		loc = fmt.Sprintf("%s:%d:%d", f.File, f.Line, f.Column)
+	}
+	return fmt.Sprintf("%s: %v: %s [%s]", loc, f.Severity, f.Message, f.Rule)
+}
+
+// Rules returns all the rules, sorted by name.
+func Rules() []*Rule {
+	rs := append([]*Rule(nil), rules...)
+	sort.Slice(rs, func(i, j int) bool { return rs[i].Name < rs[j].Name })
+	return rs
+}
+
+// FindRule returns the rule named name, or nil.
+func FindRule(name string) *Rule {
+	for _, r := range rules {
+		if r.Name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+// Config selects the rules run by Lint.
+type Config struct {
+	// Rules are the names of the rules to run.  All rules are run if
+	// Rules is empty.
+	Rules []string
+	// Severity overrides the severity of the rules it names.  A rule
+	// whose severity is Off is not run.
+	Severity map[string]Severity
+}
+
+// Lint runs the rules selected by c on mods and returns the findings,
+// sorted by location.  Only the statements of mods are checked, not those
+// of the modules they import or include.  An error is returned if c names
+// a rule that does not exist.
+func Lint(mods []*yang.Module, c Config) ([]*Finding, error) {
+	selected := rules
+	if len(c.Rules) > 0 {
+		selected = nil
+		for _, name := range c.Rules {
+			r := FindRule(name)
+			if r == nil {
+				return nil, fmt.Errorf("unknown rule %q", name)
+			}
+			selected = append(selected, r)
+		}
+	}
+	for name := range c.Severity {
+		if FindRule(name) == nil {
+			return nil, fmt.Errorf("unknown rule %q", name)
+		}
+	}
+
+	var fs []*Finding
+	for _, r := range selected {
+		sev := r.Severity
+		if s, ok := c.Severity[r.Name]; ok {
+			sev = s
+		}
+		if sev == Off {
+			continue
+		}
+		report := func(s *yang.Statement, format string, v ...interface{}) {
+			f := &Finding{Rule: r.Name, Severity: sev, Message: fmt.Sprintf(format, v...)}
+			f.File, f.Line, f.Column = location(s)
+			fs = append(fs, f)
+		}
+		for _, m := range mods {
+			walk(m.Statement(), func(s *yang.Statement) { r.check(s, report) })
+		}
+	}
+	sort.SliceStable(fs, func(i, j int) bool {
+		a, b := fs[i], fs[j]
+		switch {
+		case a.File != b.File:
+			return a.File < b.File
+		case a.Line != b.Line:
+			return a.Line < b.Line
+		case a.Column != b.Column:
+			return a.Column < b.Column
+		}
+		return a.Rule < b.Rule
+	})
+	return fs, nil
+}
+
+// walk calls fn for s and each of its descendants, parents before their
+// children.
+func walk(s *yang.Statement, fn func(*yang.Statement)) {
+	if s == nil {
+		return
+	}
+	fn(s)
+	for _, ss := range s.SubStatements() {
+		walk(ss, fn)
+	}
+}
+
+// locationRE matches the locations returned by Statement.Location.
+var locationRE = regexp.MustCompile(`^(.*):(\d+):(\d+)$`)
+
+// location returns the file, line, and column of s.
+func location(s *yang.Statement) (string, int, int) {
+	loc := s.Location()
+	m := locationRE.FindStringSubmatch(loc)
+	if m == nil {
+		if loc == "unknown" || strings.HasPrefix(loc, "line ") {
+			return "", 0, 0
+		}
+		return loc, 0, 0
+	}
+	line, _ := strconv.Atoi(m[2])
+	col, _ := strconv.Atoi(m[3])
+	return m[1], line, col
+}
@@ -0,0 +1,150 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+package yanglint
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/gnmi/errdiff"
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+const testModule = `module m {
+  prefix m;
+  namespace "urn:m";
+  description "test";
+  revision 2020-01-01;
+  revision 2021-01-01;
+  container Top {
+    description "top";
+    leaf a { type string; }
+  }
+}`
+
+func testModules(t *testing.T) []*yang.Module {
+	t.Helper()
+	ms := yang.NewModules()
+	if err := ms.Parse(testModule, "m.yang"); err != nil {
+		t.Fatal(err)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	return []*yang.Module{ms.Modules["m"]}
+}
+
+func TestLint(t *testing.T) {
+	mods := testModules(t)
+	for _, tt := range []struct {
+		desc    string
+		c       Config
+		want    []string
+		wantErr string
+	}{{
+		desc: "all rules",
+		want: []string{
+			"m.yang:6:3: warning: revision 2021-01-01 follows revision 2020-01-01 [revision-order]",
+			"m.yang:7:3: info: container Top should use only lowercase letters, digits, and hyphens [identifier]",
+			"m.yang:9:5: warning: leaf a has no description [description]",
+		},
+	}, {
+		desc: "selected rules",
+		c:    Config{Rules: []string{"description", "revision"}},
+		want: []string{
+			"m.yang:9:5: warning: leaf a has no description [description]",
+		},
+	}, {
+		desc: "severity overrides",
+		c: Config{Severity: map[string]Severity{
+			"description": Error,
+			"identifier":  Off,
+		}},
+		want: []string{
+			"m.yang:6:3: warning: revision 2021-01-01 follows revision 2020-01-01 [revision-order]",
+			"m.yang:9:5: error: leaf a has no description [description]",
+		},
+	}, {
+		desc:    "unknown rule",
+		c:       Config{Rules: []string{"bogus"}},
+		wantErr: `unknown rule "bogus"`,
+	}, {
+		desc:    "unknown override",
+		c:       Config{Severity: map[string]Severity{"bogus": Error}},
+		wantErr: `unknown rule "bogus"`,
+	}} {
+		fs, err := Lint(mods, tt.c)
+		if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+			t.Errorf("%s: %s", tt.desc, diff)
+			continue
+		}
+		var got []string
+		for _, f := range fs {
+			got = append(got, f.String())
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("%s: Lint (-want, +got):\n%s", tt.desc, diff)
+		}
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	for _, s := range []Severity{Off, Info, Warning, Error} {
+		got, err := ParseSeverity(s.String())
+		if err != nil || got != s {
+			t.Errorf("ParseSeverity(%q) = %v, %v, want %v", s.String(), got, err, s)
+		}
+	}
+	if _, err := ParseSeverity("fatal"); err == nil {
+		t.Error("ParseSeverity(fatal) did not fail")
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	fs, err := Lint(testModules(t), Config{Rules: []string{"description"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := WriteSARIF(&b, fs); err != nil {
+		t.Fatal(err)
+	}
+	var got sarifLog
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != "2.1.0" || len(got.Runs) != 1 {
+		t.Fatalf("got version %q with %d runs, want 2.1.0 with 1 run", got.Version, len(got.Runs))
+	}
+	run := got.Runs[0]
+	if len(run.Tool.Driver.Rules) != len(rules) {
+		t.Errorf("got %d rules, want %d", len(run.Tool.Driver.Rules), len(rules))
+	}
+	want := []sarifResult{{
+		RuleID:  "description",
+		Level:   "warning",
+		Message: sarifMessage{Text: "leaf a has no description"},
+		Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: "m.yang"},
+			Region:           &sarifRegion{StartLine: 9, StartColumn: 5},
+		}}},
+	}}
+	if diff := cmp.Diff(want, run.Results); diff != "" {
+		t.Errorf("WriteSARIF results (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/pborman/getopt"
+)
+
+func init() {
+	registerCommand(&command{
+		name: "resolve",
+		run:  runResolve,
+		help: "display the modules required by modules in dependency order",
+	})
+}
+
+// A resolvedModule is a module or submodule required by the named modules.
+type resolvedModule struct {
+	Name     string `json:"name"`
+	Kind     string `json:"kind"` // "module" or "submodule"
+	Revision string `json:"revision,omitempty"`
+	File     string `json:"file"`
+}
+
+// runResolve implements "goyang resolve MODULE...".  The named modules and
+// all the modules and submodules they import or include, transitively, are
+// displayed, each after the modules it depends on, as "name revision
+// file", or as JSON with --format json.  With --copy DIR, the files of the
+// modules are also copied into DIR, creating a bundle of all the modules
+// needed to process the named ones.
+func runResolve(args []string) int {
+	flags := getopt.New()
+	flags.SetProgram("goyang resolve")
+	flags.SetParameters("MODULE...")
+	var paths []string
+	format := "text"
+	var copyDir string
+	var help bool
+	flags.ListVarLong(&paths, "path", 'p', "comma separated list of directories to add to search path", "DIR[,DIR...]")
+	flags.StringVarLong(&format, "format", 0, "format of the list: text or json", "FORMAT")
+	flags.StringVarLong(&copyDir, "copy", 0, "copy the files of the modules into DIR", "DIR")
+	commonFlags(flags)
+	flags.BoolVarLong(&help, "help", 'h', "display help")
+	if err := flags.Getopt(append([]string{"goyang resolve"}, args...), nil); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flags.PrintUsage(os.Stderr)
+		return exitUsage
+	}
+	if help {
+		flags.PrintUsage(os.Stderr)
+		return exitOK
+	}
+	if format != "text" && format != "json" {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", format)
+		return exitUsage
+	}
+	if flags.NArgs() == 0 {
+		flags.PrintUsage(os.Stderr)
+		return exitUsage
+	}
+	for _, path := range paths {
+		addPath(path)
+	}
+	ms, status := readModules(flags.Args())
+	if status != exitOK {
+		return status
+	}
+
+	var rms []resolvedModule
+	for _, m := range dependencyOrder(namedModules(ms, flags.Args())) {
+		rms = append(rms, resolvedModule{
+			Name:     m.Name,
+			Kind:     m.Kind(),
+			Revision: m.Current(),
+			File:     sourceFile(m),
+		})
+	}
+	if format == "json" {
+		if rms == nil {
+			rms = []resolvedModule{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(rms)
+	} else {
+		for _, rm := range rms {
+			rev := rm.Revision
+			if rev == "" {
+				rev = "-"
+			}
+			fmt.Printf("%s %s %s\n", rm.Name, rev, rm.File)
+		}
+	}
+
+	if copyDir != "" {
+		if err := os.MkdirAll(copyDir, 0755); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		for _, rm := range rms {
+			data, err := ioutil.ReadFile(rm.File)
+			if err == nil {
+				err = writeFile(filepath.Join(copyDir, filepath.Base(rm.File)), func(w io.Writer) error {
+					_, err := w.Write(data)
+					return err
+				})
+			}
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return exitFailure
+			}
+		}
+	}
+	return exitOK
+}
+
+// dependencyOrder returns roots and the modules and submodules they import
+// or include, transitively, with each module after the modules it depends
+// on.  Modules that do not depend on each other are in the order they are
+// first reached from roots, following dependencies in name order.
+func dependencyOrder(roots []*yang.Module) []*yang.Module {
+	var order []*yang.Module
+	visited := map[*yang.Module]bool{}
+	var visit func(m *yang.Module)
+	visit = func(m *yang.Module) {
+		if visited[m] {
+			return
+		}
+		// Mark m before its dependencies so the includes between
+		// submodules of the same module, which may be circular in YANG
+		// 1.1, terminate.
+		visited[m] = true
+		var deps []*yang.Module
+		for _, d := range moduleDependencies(m) {
+			if d.kind != "augment" {
+				deps = append(deps, d.module)
+			}
+		}
+		sort.Slice(deps, func(i, j int) bool { return deps[i].Name < deps[j].Name })
+		for _, dm := range deps {
+			visit(dm)
+		}
+		order = append(order, m)
+	}
+	for _, m := range roots {
+		visit(m)
+	}
+	return order
+}
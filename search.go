@@ -0,0 +1,218 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/pborman/getopt"
+)
+
+func init() {
+	registerCommand(&command{
+		name: "search",
+		run:  runSearch,
+		help: "find nodes, typedefs, identities, and groupings matching a regular expression",
+	})
+}
+
+// searchKinds are the kinds of definitions searched by goyang search.
+var searchKinds = []string{"node", "typedef", "identity", "grouping"}
+
+// A searchResult is a definition found by goyang search.
+type searchResult struct {
+	Kind   string `json:"kind"` // a statement keyword, e.g., "leaf" or "typedef"
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Type   string `json:"type,omitempty"`
+	Source string `json:"source"`
+}
+
+// runSearch implements "goyang search REGEX MODULE...".  The schema nodes,
+// typedefs, identities, and groupings of the named modules, and of the
+// modules they import and include, whose name, path, or description
+// matches REGEX are displayed.
+func runSearch(args []string) int {
+	flags := getopt.New()
+	flags.SetProgram("goyang search")
+	flags.SetParameters("REGEX MODULE...")
+	var paths []string
+	kinds := searchKinds
+	format := "text"
+	var ignoreCase bool
+	var help bool
+	flags.ListVarLong(&paths, "path", 'p', "comma separated list of directories to add to search path", "DIR[,DIR...]")
+	flags.ListVarLong(&kinds, "kind", 'k', "only search definitions of KIND: "+strings.Join(searchKinds, ", "), "KIND[,KIND...]")
+	flags.StringVarLong(&format, "format", 0, "format of the results: text or json", "FORMAT")
+	flags.BoolVarLong(&ignoreCase, "ignore-case", 'i', "ignore case when matching")
+	commonFlags(flags)
+	flags.BoolVarLong(&help, "help", 'h', "display help")
+	if err := flags.Getopt(append([]string{"goyang search"}, args...), nil); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flags.PrintUsage(os.Stderr)
+		return exitUsage
+	}
+	if help {
+		flags.PrintUsage(os.Stderr)
+		return exitOK
+	}
+	if format != "text" && format != "json" {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", format)
+		return exitUsage
+	}
+	searched := map[string]bool{}
+	for _, k := range kinds {
+		valid := false
+		for _, sk := range searchKinds {
+			valid = valid || k == sk
+		}
+		if !valid {
+			fmt.Fprintf(os.Stderr, "%s: unknown kind.  Choices are %s\n", k, strings.Join(searchKinds, ", "))
+			return exitUsage
+		}
+		searched[k] = true
+	}
+	if flags.NArgs() < 2 {
+		flags.PrintUsage(os.Stderr)
+		return exitUsage
+	}
+	expr := flags.Arg(0)
+	if ignoreCase {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	for _, path := range paths {
+		addPath(path)
+	}
+	ms, status := readModules(flags.Args()[1:])
+	if status != exitOK {
+		return status
+	}
+
+	rs := []searchResult{}
+	match := func(r searchResult, description string) {
+		if re.MatchString(r.Name) || re.MatchString(r.Path) || re.MatchString(description) {
+			rs = append(rs, r)
+		}
+	}
+	if searched["node"] {
+		var walk func(*yang.Entry)
+		walk = func(e *yang.Entry) {
+			if e == nil {
+				return
+			}
+			if e.Parent != nil {
+				r := searchResult{
+					Kind:   e.Node.Kind(),
+					Name:   e.Name,
+					Path:   e.Path(),
+					Source: yang.Source(e.Node),
+				}
+				if e.Type != nil {
+					r.Type = e.Type.Name
+				}
+				match(r, e.Description)
+			}
+			if e.RPC != nil {
+				walk(e.RPC.Input)
+				walk(e.RPC.Output)
+			}
+			for _, ce := range children(e) {
+				walk(ce)
+			}
+		}
+		for _, m := range searchModules(ms) {
+			walk(yang.ToEntry(m))
+		}
+	}
+	if searched["typedef"] {
+		for _, t := range ms.Typedefs() {
+			r := searchResult{Kind: "typedef", Name: t.Name, Path: yang.NodePath(t), Source: yang.Source(t)}
+			if t.Type != nil {
+				r.Type = t.Type.Name
+			}
+			match(r, valueName(t.Description))
+		}
+	}
+	if searched["identity"] {
+		for _, id := range ms.Identities() {
+			var bases []string
+			for _, b := range id.Base {
+				bases = append(bases, b.Name)
+			}
+			match(searchResult{
+				Kind:   "identity",
+				Name:   id.Name,
+				Path:   yang.NodePath(id),
+				Type:   strings.Join(bases, " "),
+				Source: yang.Source(id),
+			}, valueName(id.Description))
+		}
+	}
+	if searched["grouping"] {
+		for _, g := range ms.Groupings() {
+			match(searchResult{Kind: "grouping", Name: g.Name, Path: yang.NodePath(g), Source: yang.Source(g)}, valueName(g.Description))
+		}
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(rs)
+		return exitOK
+	}
+	for _, r := range rs {
+		line := fmt.Sprintf("%s: %s %s", r.Source, r.Kind, r.Path)
+		if r.Type != "" {
+			line += " " + r.Type
+		}
+		fmt.Println(line)
+	}
+	return exitOK
+}
+
+// searchModules returns the modules, but not the submodules, of ms sorted
+// by name.  The contents of submodules are part of the Entry trees of the
+// modules they belong to.
+func searchModules(ms *yang.Modules) []*yang.Module {
+	seen := map[*yang.Module]bool{}
+	var mods []*yang.Module
+	for _, m := range ms.Modules {
+		if !seen[m] {
+			seen[m] = true
+			mods = append(mods, m)
+		}
+	}
+	sort.Slice(mods, func(i, j int) bool { return mods[i].Name < mods[j].Name })
+	return mods
+}
+
+// valueName returns the name of v, or "" if v is nil.
+func valueName(v *yang.Value) string {
+	if v == nil {
+		return ""
+	}
+	return v.Name
+}
@@ -21,24 +21,42 @@ import (
 
 	"github.com/openconfig/goyang/pkg/indent"
 	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/pborman/getopt"
 )
 
+var treeUnexpanded bool
+
 func init() {
+	flags := getopt.New()
 	register(&formatter{
-		name: "tree",
-		f:    doTree,
-		help: "display in a tree format",
+		name:  "tree",
+		f:     doTree,
+		help:  "display in a tree format",
+		flags: flags,
 	})
+	flags.BoolVarLong(&treeUnexpanded, "tree_unexpanded", 0, "display uses statements in place of the nodes of their groupings, and augments apart from the nodes they augment")
 }
 
-func doTree(w io.Writer, entries []*yang.Entry) {
+func doTree(w io.Writer, entries []*yang.Entry) error {
 	for _, e := range entries {
+		if treeUnexpanded {
+			writeUnexpanded(w, e)
+			continue
+		}
 		Write(w, e)
 	}
+	return nil
 }
 
 // Write writes e, formatted, and all of its children, to w.
 func Write(w io.Writer, e *yang.Entry) {
+	writeEntry(w, e, nil)
+}
+
+// writeEntry writes e, formatted, and all of its children, to w.  If u is
+// not nil, the children are written unexpanded, as described by
+// writeUnexpanded.
+func writeEntry(w io.Writer, e *yang.Entry, u *unexpanded) {
 	if e.Description != "" {
 		fmt.Fprintln(w)
 		fmt.Fprintln(indent.NewWriter(w, "// "), e.Description)
@@ -57,6 +75,8 @@ func Write(w io.Writer, e *yang.Entry) {
 	switch {
 	case e.RPC != nil:
 		fmt.Fprintf(w, "RPC: ")
+	case e.IsNotification():
+		fmt.Fprintf(w, "Notification: ")
 	case e.ReadOnly():
 		fmt.Fprintf(w, "RO: ")
 	default:
@@ -70,6 +90,10 @@ func Write(w io.Writer, e *yang.Entry) {
 		name = e.Prefix.Name + ":" + name
 	}
 	switch {
+	case e.Kind == yang.AnyDataEntry || e.Kind == yang.AnyXMLEntry:
+		// The contents of anydata and anyxml nodes are not modeled.
+		fmt.Fprintf(w, "%s %s\n", e.Node.Kind(), name)
+		return
 	case e.Dir == nil && e.ListAttr != nil:
 		fmt.Fprintf(w, "[]%s\n", name)
 		return
@@ -83,22 +107,160 @@ func Write(w io.Writer, e *yang.Entry) {
 	}
 	if r := e.RPC; r != nil {
 		if r.Input != nil {
-			Write(indent.NewWriter(w, "  "), r.Input)
+			writeEntry(indent.NewWriter(w, "  "), r.Input, u)
 		}
 		if r.Output != nil {
-			Write(indent.NewWriter(w, "  "), r.Output)
+			writeEntry(indent.NewWriter(w, "  "), r.Output, u)
+		}
+	}
+	if u != nil {
+		u.writeDir(indent.NewWriter(w, "  "), children(e), parentUses, e.AugmentedBy())
+	} else {
+		for _, ce := range children(e) {
+			writeEntry(indent.NewWriter(w, "  "), ce, nil)
 		}
 	}
+	// { to match the brace below to keep brace matching working
+	fmt.Fprintln(w, "}")
+}
+
+// children returns the children of e sorted by name.
+func children(e *yang.Entry) []*yang.Entry {
 	var names []string
 	for k := range e.Dir {
 		names = append(names, k)
 	}
 	sort.Strings(names)
-	for _, k := range names {
-		Write(indent.NewWriter(w, "  "), e.Dir[k])
+	es := make([]*yang.Entry, len(names))
+	for x, k := range names {
+		es[x] = e.Dir[k]
+	}
+	return es
+}
+
+// An unexpanded holds the groupings found while writing the unexpanded
+// tree of a module.
+type unexpanded struct {
+	groupings []*usedGrouping
+	byName    map[string]*usedGrouping
+}
+
+// A usedGrouping is a grouping named by a uses statement.  The grouping is
+// written using entries, the children instantiated by uses, the first uses
+// statement found that names it.
+type usedGrouping struct {
+	uses    *yang.Uses
+	entries []*yang.Entry
+}
+
+// parentUses returns the uses statements that instantiated e but not its
+// parent, outermost last.
+func parentUses(e *yang.Entry) []*yang.Uses {
+	n := len(e.UsedAt()) - len(e.Parent.UsedAt())
+	if n <= 0 {
+		return nil
+	}
+	return e.UsedAt()[:n]
+}
+
+// writeUnexpanded writes the unexpanded tree of the module e to w.  Unlike
+// Write, the nodes instantiated from a grouping are written as the uses
+// statement that names the grouping, and the nodes added by an augment are
+// not written.  The augments defined by the module, and the groupings
+// named by uses statements, are then written after the tree.
+func writeUnexpanded(w io.Writer, e *yang.Entry) {
+	u := &unexpanded{byName: map[string]*usedGrouping{}}
+	writeEntry(w, e, u)
+
+	// The nodes added by the augments of e may be in any module.
+	augmented := map[*yang.Augment][]*yang.Entry{}
+	var augments []*yang.Augment
+	seen := map[*yang.Entry]bool{}
+	var walk func(*yang.Entry)
+	walk = func(e *yang.Entry) {
+		if e == nil || seen[e] {
+			return
+		}
+		seen[e] = true
+		if a := e.AugmentedBy(); a != nil && a != e.Parent.AugmentedBy() {
+			if augmented[a] == nil {
+				augments = append(augments, a)
+			}
+			augmented[a] = append(augmented[a], e)
+		}
+		if e.RPC != nil {
+			walk(e.RPC.Input)
+			walk(e.RPC.Output)
+		}
+		for _, ce := range e.Dir {
+			walk(ce)
+		}
+	}
+	ms := e.Modules()
+	for _, m := range ms.Modules {
+		walk(yang.ToEntry(m))
+	}
+	sort.Slice(augments, func(i, j int) bool {
+		return yang.Source(augments[i]) < yang.Source(augments[j])
+	})
+	for _, a := range augments {
+		if m := a.ParentModule(); m == nil || m.Name != e.Name {
+			continue
+		}
+		es := augmented[a]
+		sort.Slice(es, func(i, j int) bool { return es[i].Name < es[j].Name })
+		fmt.Fprintf(w, "augment %s {\n", a.Name) //}
+		u.writeDir(indent.NewWriter(w, "  "), es, func(e *yang.Entry) []*yang.Uses { return e.UsedAt() }, a)
+		fmt.Fprintln(w, "}")
+	}
+
+	// Writing a grouping may find more groupings.
+	for x := 0; x < len(u.groupings); x++ {
+		g := u.groupings[x]
+		fmt.Fprintf(w, "grouping %s {\n", g.uses.Name) //}
+		u.writeDir(indent.NewWriter(w, "  "), g.entries, func(e *yang.Entry) []*yang.Uses {
+			for i, us := range e.UsedAt() {
+				if us == g.uses {
+					return e.UsedAt()[:i]
+				}
+			}
+			return nil
+		}, g.entries[0].AugmentedBy())
+		fmt.Fprintln(w, "}")
+	}
+}
+
+// writeDir writes the entries es, which are siblings, to w.  own returns
+// the uses statements that instantiated an entry within the context es are
+// written in, outermost last.  An entry with such a uses statement is
+// written as that uses statement.  An entry added by an augment other than
+// aug is not written.
+func (u *unexpanded) writeDir(w io.Writer, es []*yang.Entry, own func(*yang.Entry) []*yang.Uses, aug *yang.Augment) {
+	written := map[*yang.Uses]bool{}
+	for _, e := range es {
+		if a := e.AugmentedBy(); a != nil && a != aug {
+			continue
+		}
+		uses := own(e)
+		if len(uses) == 0 {
+			writeEntry(w, e, u)
+			continue
+		}
+		us := uses[len(uses)-1]
+		if !written[us] {
+			written[us] = true
+			fmt.Fprintf(w, "uses %s\n", us.Name)
+		}
+		g := u.byName[us.Name]
+		if g == nil {
+			g = &usedGrouping{uses: us}
+			u.byName[us.Name] = g
+			u.groupings = append(u.groupings, g)
+		}
+		if g.uses == us {
+			g.entries = append(g.entries, e)
+		}
 	}
-	// { to match the brace below to keep brace matching working
-	fmt.Fprintln(w, "}")
 }
 
 func getTypeName(e *yang.Entry) string {
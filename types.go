@@ -41,7 +41,7 @@ func init() {
 	flags.BoolVarLong(&typesVerbose, "types_verbose", 0, "include base information")
 }
 
-func doTypes(w io.Writer, entries []*yang.Entry) {
+func doTypes(w io.Writer, entries []*yang.Entry) error {
 	types := Types{}
 	for _, e := range entries {
 		types.AddEntry(e)
@@ -55,6 +55,7 @@ func doTypes(w io.Writer, entries []*yang.Entry) {
 			showall(w, e)
 		}
 	}
+	return nil
 }
 
 // Types keeps track of all the YangTypes defined.
@@ -107,6 +108,9 @@ func printType(w io.Writer, t *yang.YangType, verbose bool) {
 	if len(t.Pattern) > 0 {
 		fmt.Fprintf(w, " pattern=%s", strings.Join(t.Pattern, "|"))
 	}
+	if len(t.InvertedPattern) > 0 {
+		fmt.Fprintf(w, " invert-match=%s", strings.Join(t.InvertedPattern, "|"))
+	}
 	b := yang.BaseTypedefs[t.Kind.String()].YangType
 	if len(t.Range) > 0 && !t.Range.Equal(b.Range) {
 		fmt.Fprintf(w, " range=%s", t.Range)
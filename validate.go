@@ -0,0 +1,110 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/yangdata"
+	"github.com/pborman/getopt"
+)
+
+func init() {
+	registerCommand(&command{
+		name: "validate",
+		run:  runValidate,
+		help: "validate a JSON or XML instance data document",
+	})
+}
+
+// runValidate implements "goyang validate --data FILE MODULE...".  The
+// document is XML if FILE ends in .xml or starts with "<", and RFC 7951 JSON
+// otherwise.  Each violation is displayed as "path: message", or, with
+// --json, as a JSON array of objects with "path" and "message" members.
+// The exit status is exitErrors if there are violations and exitParse if
+// the document cannot be read or parsed.
+func runValidate(args []string) int {
+	flags := getopt.New()
+	flags.SetProgram("goyang validate")
+	flags.SetParameters("MODULE...")
+	var paths []string
+	var data string
+	var asJSON, help bool
+	flags.StringVarLong(&data, "data", 'd', "instance data document to validate", "FILE")
+	flags.ListVarLong(&paths, "path", 'p', "comma separated list of directories to add to search path", "DIR[,DIR...]")
+	flags.BoolVarLong(&asJSON, "json", 0, "display the violations as JSON")
+	commonFlags(flags)
+	flags.BoolVarLong(&help, "help", 'h', "display help")
+	if err := flags.Getopt(append([]string{"goyang validate"}, args...), nil); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flags.PrintUsage(os.Stderr)
+		return exitUsage
+	}
+	if help {
+		flags.PrintUsage(os.Stderr)
+		return exitOK
+	}
+	if data == "" || flags.NArgs() == 0 {
+		flags.PrintUsage(os.Stderr)
+		return exitUsage
+	}
+	for _, path := range paths {
+		addPath(path)
+	}
+
+	ms, status := readModules(flags.Args())
+	if status != exitOK {
+		return status
+	}
+
+	b, err := ioutil.ReadFile(data)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitParse
+	}
+	validate := yangdata.ValidateJSON
+	if strings.EqualFold(filepath.Ext(data), ".xml") || bytes.HasPrefix(bytes.TrimSpace(b), []byte("<")) {
+		validate = yangdata.ValidateXML
+	}
+	vs, err := validate(ms, b)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", data, err)
+		return exitParse
+	}
+
+	if asJSON {
+		if vs == nil {
+			vs = []*yangdata.Violation{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(vs)
+	} else {
+		for _, v := range vs {
+			fmt.Println(v)
+		}
+	}
+	if len(vs) > 0 {
+		return exitErrors
+	}
+	return exitOK
+}
@@ -0,0 +1,112 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// watchInterval is how often --watch checks for changes.
+const watchInterval = 500 * time.Millisecond
+
+// A fileState is the state of a file that is compared to detect changes.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// watch runs g, and then runs it again each time one of files or a .yang
+// file in the search path changes.  The report of each run is ended with
+// flushReport.  It never returns.
+func watch(g *generator, files []string) {
+	g.generate()
+	flushReport()
+	prev := snapshot(files, g.searchPath)
+	for {
+		time.Sleep(watchInterval)
+		cur := snapshot(files, g.searchPath)
+		if changed := changedFiles(prev, cur); len(changed) > 0 {
+			if !quiet {
+				fmt.Fprintf(os.Stderr, "%s changed, regenerating\n", strings.Join(changed, ", "))
+			}
+			g.generate()
+			flushReport()
+			// The search path may have grown while reading the modules.
+			cur = snapshot(files, g.searchPath)
+		}
+		prev = cur
+	}
+}
+
+// snapshot returns the states of files and of the .yang files in the
+// directories of path, a search path.  Files that do not exist are
+// omitted.
+func snapshot(files, path []string) map[string]fileState {
+	states := map[string]fileState{}
+	add := func(name string, fi os.FileInfo) {
+		states[name] = fileState{size: fi.Size(), modTime: fi.ModTime()}
+	}
+	for _, name := range files {
+		if fi, err := os.Stat(name); err == nil && !fi.IsDir() {
+			add(name, fi)
+		}
+	}
+	for _, dir := range path {
+		if strings.HasSuffix(dir, "/...") {
+			filepath.Walk(strings.TrimSuffix(dir, "/..."), func(p string, fi os.FileInfo, err error) error {
+				if err == nil && !fi.IsDir() && isSourceFile(p) {
+					add(p, fi)
+				}
+				return nil
+			})
+			continue
+		}
+		fis, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, fi := range fis {
+			if !fi.IsDir() && isSourceFile(fi.Name()) {
+				add(filepath.Join(dir, fi.Name()), fi)
+			}
+		}
+	}
+	return states
+}
+
+// changedFiles returns the sorted names of the files that were added,
+// removed, or modified between the snapshots prev and cur.
+func changedFiles(prev, cur map[string]fileState) []string {
+	var changed []string
+	for name, s := range cur {
+		if ps, ok := prev[name]; !ok || ps != s {
+			changed = append(changed, name)
+		}
+	}
+	for name := range prev {
+		if _, ok := cur[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
@@ -16,37 +16,144 @@
 // something related to the input on output.
 //
 // Usage: yang [--path DIR] [--format FORMAT] [FORMAT OPTIONS] [MODULE] [FILE ...]
+//        yang COMMAND [OPTIONS] [ARGS ...]
 //
-// If MODULE is specified (an argument that does not end in .yang), it is taken
-// as the name of the module to display.  Any FILEs specified are read, and the
-// tree for MODULE is displayed.  If MODULE was not defined in FILEs (or no
-// files were specified), then the file MODULES.yang is read as well.  An error
-// is displayed if no definition for MODULE was found.
+// If MODULE is specified (an argument that does not end in .yang or .yin), it
+// is taken as the name of the module to display.  Any FILEs specified are
+// read, and the tree for MODULE is displayed.  If MODULE was not defined in
+// FILEs (or no files were specified), then the file MODULES.yang, or
+// MODULE.yin, is read as well.  An error is displayed if no definition for
+// MODULE was found.  Files ending in .yin, or starting with "<", are read as
+// YIN (RFC 7950 section 13).
 //
 // If MODULE is missing, then all base modules read from the FILEs are
 // displayed.  If there are no arguments then standard input is parsed.
 //
 // If DIR is specified, it is considered a comma separated list of paths
 // to append to the search directory.  If DIR appears as DIR/... then
-// DIR and all direct and indirect subdirectories are checked.
+// DIR and all direct and indirect subdirectories are checked.  DIR may
+// also be a glob pattern, as used by filepath.Match, e.g., vendor/*/yang
+// or vendor/*/..., in which case each matching directory is added.  With
+// --verbose, the file that satisfied each import and include is displayed.
+//
+// --config names a JSON file that supplies the values of other flags and
+// the SOURCEs, see config.  Lists are extended, and other values are
+// overridden, by the command line.
+//
+// --jobs sets how many source files are read and parsed, and how many
+// files are written with --output-dir, at once.  It defaults to GOMAXPROCS.
 //
 // FORMAT, which defaults to "tree", specifies the format of output to produce.
-// Use "goyang --help" for a list of available formats.
+// Use "goyang --help" for a list of available formats and their options, or
+// "goyang --help --format FORMAT" for those of FORMAT alone.
 //
 // FORMAT OPTIONS are flags that apply to a specific format.  They must follow
 // --format.
 //
+// The deviations of the modules named by --deviation-module, which may be
+// repeated, are applied to the modules being displayed.  The deviation
+// modules themselves are not displayed.
+//
+// If --enable-feature or --disable-feature is given, the nodes whose
+// if-feature statements are false are removed.  If --enable-feature names
+// any feature of a module, only the named features of that module are
+// enabled ("MODULE:" enables none of them).  All the features of the other
+// modules are enabled.  The features named by --disable-feature are then
+// disabled.
+//
+// The modules displayed may be restricted with --include-module and
+// --exclude-module, whose values are glob patterns as used by path.Match.
+// If --include-module is given, only the modules whose names match one of
+// its patterns are displayed.  Modules whose names match a pattern of
+// --exclude-module are not displayed.  The modules that are not displayed
+// are still read as needed to process the others.
+//
+// Output is written to standard output unless --output-file or --output-dir
+// is given.  --output-file writes the output for all the modules to a
+// single file.  --output-dir writes the output for each module to its own
+// file in the directory, named after the module with the format as its
+// extension, e.g., DIR/openconfig-interfaces.tree.  Files are written
+// atomically: the output is written to a temporary file that is renamed
+// once it is complete, so a failed run never leaves a partial file.
+//
+// With --watch, the sources, the .yang files in the search path, and the
+// --config file are checked for changes twice a second.  The output is
+// regenerated whenever any of them change.  Errors are reported without
+// exiting.  Output files whose contents do not change are not rewritten.
+//
+// --completion writes a completion script for bash, zsh, or fish to standard
+// output, e.g., "source <(goyang --completion bash)".  The script completes
+// the flags, commands, and formats, including the flags of each format.
+//
+// --strict is intended for publishing modules.  It reports an error for
+// each statement that the yang-version of its module does not allow and for
+// each violation of the constraints of RFC 7950 that are otherwise not
+// checked, such as a configuration list without a key.  It also treats
+// warnings as errors, and may not be combined with --ignore-circdep.
+//
+// --lenient is intended for converting real-world modules that do not
+// conform to RFC 7950.  Unknown substatements are skipped with a warning
+// rather than reported as errors.  It may not be combined with --strict.
+//
+// --error-format selects how errors and warnings are written to standard
+// error: text, the default; json, one object per line; or sarif, a single
+// SARIF 2.1.0 log, as read by GitHub code scanning, written when goyang
+// exits.  The json and sarif formats give the file, line, column, code,
+// and severity of each diagnostic.  sarif may not be used with --watch.
+//
+// --quiet suppresses warnings and informational messages.  --max-errors
+// stops reading and processing once N errors have been reported.
+// --severity changes the severity of the diagnostics with the given codes,
+// e.g., "--severity unused-import=ignore,missing-description=warning".  The
+// import-without-revision and missing-description warnings are only
+// reported when given a severity.
+//
+// --module-url fetches modules that are not found in the search path over
+// HTTP or HTTPS.  In the URL, {file} is replaced by the module's file name,
+// e.g., ietf-interfaces.yang, and {module} by the module's name.  Fetched
+// modules are saved in the --module-cache directory, which defaults to
+// goyang/modules in the user's cache directory, and are not fetched again.
+// --bundle reads modules that are not found in the search path from zip or
+// gzip compressed tar archives.  --git reads them from a branch, tag, or
+// commit of a git repository, e.g.,
+// "--git https://github.com/openconfig/public#v2.0.0".  The commits are
+// checked out in the git directory of the --module-cache directory.
+//
+// The exit status of goyang and its commands is one of:
+//
+//   0  success
+//   1  the modules have errors and could not be processed, incompatible
+//      changes were reported by "diff --exit-code", violations were
+//      reported by "validate", or findings with severity error were
+//      reported by "lint"
+//   2  invalid flags or arguments
+//   3  a source, or a module it imports or includes, could not be found,
+//      read, or parsed
+//   4  findings with severity warning, but none with severity error, were
+//      reported by "lint"
+//   5  another failure, such as a failure to write the output
+//
+// A COMMAND, such as diff, performs a task other than displaying modules.
+// Use "goyang --help" for a list of commands.
+//
 // THIS PROGRAM IS STILL JUST A DEVELOPMENT TOOL.
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"path"
+	"path/filepath"
+	"runtime"
 	"runtime/trace"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/openconfig/goyang/pkg/indent"
 	"github.com/openconfig/goyang/pkg/yang"
@@ -54,10 +161,12 @@ import (
 )
 
 // Each format must register a formatter with register.  The function f will
-// be called once with the set of yang Entry trees generated.
+// be called once with the set of yang Entry trees generated, or, with
+// --output-dir, once for each tree.  In the latter case the calls may be
+// made concurrently, so f must not modify the entries or any shared state.
 type formatter struct {
 	name  string
-	f     func(io.Writer, []*yang.Entry)
+	f     func(io.Writer, []*yang.Entry) error
 	help  string
 	flags *getopt.Set
 }
@@ -68,20 +177,287 @@ func register(f *formatter) {
 	formatters[f.name] = f
 }
 
-// exitIfError writes errs to standard error and exits with an exit status of 1.
-// If errs is empty then exitIfError does nothing and simply returns.
-func exitIfError(errs []error) {
-	if len(errs) > 0 {
+// Each subcommand must register a command with registerCommand.  A
+// subcommand is run by naming it as the first argument, e.g., "goyang diff".
+// run is called with the remaining arguments and returns the exit status.
+type command struct {
+	name string
+	run  func(args []string) int
+	help string
+}
+
+var commands = map[string]*command{}
+
+func registerCommand(c *command) {
+	commands[c.name] = c
+}
+
+// The exit statuses of goyang and its subcommands.
+const (
+	exitOK       = 0 // success
+	exitErrors   = 1 // the modules have errors, or errors were found
+	exitUsage    = 2 // invalid flags or arguments
+	exitParse    = 3 // a source could not be found, read, or parsed
+	exitWarnings = 4 // only warnings were found, see goyang lint
+	exitFailure  = 5 // another failure, such as writing the output
+)
+
+// exitStatus returns the exit status for errs, the errors returned by
+// Process, which must not be empty.  Errors finding, reading, or parsing
+// imported and included modules are parse errors.
+func exitStatus(errs []error) int {
+	for _, err := range errs {
+		switch yang.ErrorCode(err) {
+		case yang.ErrFileNotFound, yang.ErrUnknownModule, yang.ErrSyntax, yang.ErrLimitExceeded, yang.ErrNotModule:
+			return exitParse
+		}
+	}
+	return exitErrors
+}
+
+// quiet suppresses warnings and informational messages.
+var quiet bool
+
+// moduleURLs are the URL templates of yang.HTTPResolvers that fetch the
+// modules not found in the search path, and moduleCache the directory they
+// save fetched modules in.
+var (
+	moduleURLs  []string
+	moduleCache string
+)
+
+// bundles are the archives that modules not found in the search path are
+// read from.
+var bundles []string
+
+// gitSources are the REPO#REF git repositories that modules not found in
+// the search path are read from, and gitCommits the commits that they
+// resolved to, which are used, rather than looking up REF again, when
+// newModules is called again.
+var (
+	gitSources []string
+	gitCommits = map[string]string{}
+)
+
+// reportedErrors is the number of errors, not including warnings, that
+// report has written.
+var reportedErrors int
+
+// droppedErrors is the number of errors that report has not written
+// because of parseOptions.MaxErrors, including those dropped by Process.
+var droppedErrors int
+
+// errorFormat is the format in which errors and warnings are reported.
+var errorFormat = "text"
+
+// errorFormats are the valid values of errorFormat.
+var errorFormats = []string{"text", "json", "sarif"}
+
+// sarifErrors are the errors and warnings reported so far when errorFormat
+// is sarif.  They are written as a single SARIF log by flushReport.
+var sarifErrors []error
+
+// report writes errs, which may include warnings, to standard error in
+// errorFormat.  Warnings are not written if quiet is set.  Once
+// parseOptions.MaxErrors errors have been written, further errors are
+// dropped and counted, see flushReport.  In the sarif format, errs are not
+// written until flushReport is called.
+func report(errs []error) {
+	writeReport(limitReport(errs))
+}
+
+// writeReport writes errs to standard error in errorFormat.
+func writeReport(errs []error) {
+	switch errorFormat {
+	case "json":
+		yang.WriteDiagnosticsJSON(os.Stderr, errs)
+	case "sarif":
+		sarifErrors = append(sarifErrors, errs...)
+	default:
 		for _, err := range errs {
+			if isWarning(err) {
+				fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+				continue
+			}
 			fmt.Fprintln(os.Stderr, err)
 		}
-		stop(1)
 	}
 }
 
-var stop = os.Exit
+// flushReport ends the report of errors and warnings.  If errors were
+// dropped, it writes an ErrTooManyErrors error saying how many.  In the
+// sarif format, it then writes the errors and warnings reported to standard
+// error.  The errors reported after flushReport are counted afresh.
+func flushReport() {
+	writeReport(droppedReport())
+	reportedErrors = 0
+	if errorFormat == "sarif" {
+		yang.WriteDiagnosticsSARIF(os.Stderr, sarifErrors)
+		sarifErrors = nil
+	}
+}
+
+// limitReport returns the errors of errs that report writes.  The errors
+// following the first parseOptions.MaxErrors errors are counted in
+// droppedErrors, as are the errors counted by the ErrTooManyErrors error
+// of Process, rather than returned.
+func limitReport(errs []error) []error {
+	max := parseOptions.MaxErrors
+	var out []error
+	for _, err := range errs {
+		switch {
+		case isWarning(err):
+			if quiet {
+				continue
+			}
+		case yang.ErrorCode(err) == yang.ErrTooManyErrors:
+			droppedErrors += yang.DroppedErrors(err)
+			continue
+		case max > 0 && reportedErrors >= max:
+			droppedErrors++
+			continue
+		default:
+			reportedErrors++
+		}
+		out = append(out, err)
+	}
+	return out
+}
+
+// droppedReport returns the ErrTooManyErrors error saying how many errors
+// were dropped by limitReport, if any were, and resets the count.
+func droppedReport() []error {
+	if droppedErrors == 0 {
+		return nil
+	}
+	err := &yang.Error{Code: yang.ErrTooManyErrors, Msg: fmt.Sprintf("at least %d more errors were not reported, only the first %d are reported", droppedErrors, parseOptions.MaxErrors)}
+	droppedErrors = 0
+	return []error{err}
+}
+
+// isWarning reports whether err is a warning.
+func isWarning(err error) bool {
+	e, ok := err.(*yang.Error)
+	return ok && e.Severity == yang.SeverityWarning
+}
+
+// tooManyErrors reports whether parseOptions.MaxErrors errors have been
+// reported.
+func tooManyErrors() bool {
+	max := parseOptions.MaxErrors
+	return max > 0 && reportedErrors >= max
+}
+
+// commonFlags adds the flags shared by goyang and its subcommands that are
+// not specific to a task to flags.
+func commonFlags(flags *getopt.Set) {
+	flags.BoolVarLong(&quiet, "quiet", 'q', "do not display warnings or informational messages")
+	flags.IntVarLong(&parseOptions.MaxErrors, "max-errors", 0, "stop after N errors (0 means no limit)", "N")
+	flags.VarLong(severityFlag{}, "severity", 0, "report diagnostics with CODE as LEVEL: error, warning, or ignore", "CODE=LEVEL[,...]")
+	flags.ListVarLong(&moduleURLs, "module-url", 0, "fetch modules not in the search path from URL, in which {file} and {module} are replaced", "URL[,URL...]")
+	flags.StringVarLong(&moduleCache, "module-cache", 0, "save the modules fetched by --module-url in DIR", "DIR")
+	flags.ListVarLong(&bundles, "bundle", 0, "read modules not in the search path from the zip or tar.gz ARCHIVE", "ARCHIVE[,ARCHIVE...]")
+	flags.ListVarLong(&gitSources, "git", 0, "read modules not in the search path from REF (default HEAD) of the git repository REPO", "REPO[#REF][,...]")
+}
+
+// severityLevels are the levels accepted by --severity.
+var severityLevels = map[string]yang.Severity{
+	"error":   yang.SeverityError,
+	"warning": yang.SeverityWarning,
+	"ignore":  yang.SeverityIgnore,
+}
+
+// A severityFlag is the value of --severity, which sets
+// parseOptions.Severities.
+type severityFlag struct{}
+
+func (severityFlag) Set(value string, _ getopt.Option) error {
+	for _, v := range strings.Split(value, ",") {
+		x := strings.Index(v, "=")
+		if x < 0 {
+			return fmt.Errorf("--severity: %s is not CODE=LEVEL", v)
+		}
+		level, ok := severityLevels[v[x+1:]]
+		if !ok {
+			return fmt.Errorf("--severity: unknown level %s, want error, warning, or ignore", v[x+1:])
+		}
+		if parseOptions.Severities == nil {
+			parseOptions.Severities = map[yang.Code]yang.Severity{}
+		}
+		parseOptions.Severities[yang.Code(v[:x])] = level
+	}
+	return nil
+}
+
+func (severityFlag) String() string {
+	var vs []string
+	for code, s := range parseOptions.Severities {
+		vs = append(vs, fmt.Sprintf("%s=%s", code, s))
+	}
+	sort.Strings(vs)
+	return strings.Join(vs, ",")
+}
+
+// parseOptions are the options of the yang.Modules returned by newModules.
+// They are set by the flags.
+var parseOptions yang.Options
+
+// searchPath is the search path of the yang.Modules returned by newModules,
+// see addPath.
+var searchPath []string
+
+// newModules returns a new yang.Modules that reads the modules not found in
+// the search path from the --bundle archives, then the --git repositories,
+// and then fetches them from the --module-url URLs.  goyang exits if an
+// archive or repository cannot be read.
+func newModules() *yang.Modules {
+	ms := yang.NewModulesWithOptions(parseOptions)
+	ms.AddPath(searchPath...)
+	for _, b := range bundles {
+		if err := ms.AddBundle(b); err != nil {
+			report([]error{err})
+			stop(exitParse)
+		}
+	}
+	cache := moduleCache
+	if cache == "" {
+		if dir, err := os.UserCacheDir(); err == nil {
+			cache = filepath.Join(dir, "goyang", "modules")
+		}
+	}
+	for _, src := range gitSources {
+		g := &yang.GitSource{Repository: src, CacheDir: filepath.Join(cache, "git")}
+		if i := strings.LastIndex(src, "#"); i >= 0 {
+			g.Repository, g.Ref = src[:i], src[i+1:]
+		}
+		if c, ok := gitCommits[src]; ok {
+			g.Ref = c
+		}
+		c, err := ms.AddGitSource(context.Background(), g)
+		if err != nil {
+			report([]error{err})
+			stop(exitParse)
+		}
+		gitCommits[src] = c
+	}
+	for _, url := range moduleURLs {
+		ms.AddResolver(&yang.HTTPResolver{URL: url, CacheDir: cache})
+	}
+	return ms
+}
+
+// stop exits with status c once the report of errors and warnings has been
+// ended by flushReport.
+var stop = func(c int) { flushReport(); os.Exit(c) }
 
 func main() {
+	if len(os.Args) > 1 {
+		if c, ok := commands[os.Args[1]]; ok {
+			stop(c.run(os.Args[2:]))
+			return
+		}
+	}
+
 	var format string
 	formats := make([]string, 0, len(formatters))
 	for k := range formatters {
@@ -92,11 +468,39 @@ func main() {
 	var traceP string
 	var help bool
 	var paths []string
+	var deviations []string
+	var enableFeatures, disableFeatures []string
+	var includeModules, excludeModules []string
+	var outputFile, outputDir string
+	var verbose bool
+	var configFile string
+	var watchMode bool
+	var strict bool
+	var completionShell string
+	jobs := runtime.GOMAXPROCS(0)
 	getopt.ListVarLong(&paths, "path", 'p', "comma separated list of directories to add to search path", "DIR[,DIR...]")
+	getopt.ListVarLong(&deviations, "deviation-module", 0, "apply the deviations in MODULE, which is not displayed", "MODULE[,MODULE...]")
+	getopt.ListVarLong(&enableFeatures, "enable-feature", 0, "enable only the named features of MODULE", "MODULE:FEATURE[,...]")
+	getopt.ListVarLong(&disableFeatures, "disable-feature", 0, "disable the named features", "MODULE:FEATURE[,...]")
+	getopt.ListVarLong(&includeModules, "include-module", 0, "only display the modules matching PATTERN", "PATTERN[,...]")
+	getopt.ListVarLong(&excludeModules, "exclude-module", 0, "do not display the modules matching PATTERN", "PATTERN[,...]")
+	getopt.StringVarLong(&outputFile, "output-file", 'o', "write the output to FILE", "FILE")
+	getopt.StringVarLong(&outputDir, "output-dir", 0, "write the output of each module to a file in DIR", "DIR")
+	getopt.BoolVarLong(&verbose, "verbose", 'v', "display the file that satisfied each import and include")
+	getopt.IntVarLong(&jobs, "jobs", 'j', "read and write up to N files at once (default GOMAXPROCS)", "N")
+	getopt.StringVarLong(&configFile, "config", 'c', "read flags and sources from the JSON file CONFIG", "CONFIG")
+	getopt.BoolVarLong(&watchMode, "watch", 0, "regenerate the output whenever a source or a module in the search path changes")
 	getopt.StringVarLong(&format, "format", 'f', "format to display: "+strings.Join(formats, ", "), "FORMAT")
 	getopt.StringVarLong(&traceP, "trace", 't', "write trace into to TRACEFILE", "TRACEFILE")
+	getopt.StringVarLong(&completionShell, "completion", 0, "write a completion script for SHELL ("+strings.Join(shells, ", ")+") to standard output", "SHELL")
 	getopt.BoolVarLong(&help, "help", 'h', "display help")
-	getopt.BoolVarLong(&yang.ParseOptions.IgnoreSubmoduleCircularDependencies, "ignore-circdep", 'g', "ignore circular dependencies between submodules")
+	getopt.BoolVarLong(&parseOptions.IgnoreSubmoduleCircularDependencies, "ignore-circdep", 'g', "ignore circular dependencies between submodules")
+	getopt.BoolVarLong(&parseOptions.WarningsAsErrors, "warnings-as-errors", 'W', "treat warnings as errors")
+	getopt.BoolVarLong(&strict, "strict", 0, "check YANG version and RFC 7950 conformance, treat warnings as errors, and disable --ignore-circdep")
+	getopt.BoolVarLong(&parseOptions.Lenient, "lenient", 0, "skip unknown substatements with a warning")
+	getopt.BoolVarLong(&parseOptions.Debug, "debug", 0, "trace the resolution of types, groupings, augments, and deviations")
+	commonFlags(getopt.CommandLine)
+	getopt.StringVarLong(&errorFormat, "error-format", 0, "format of errors and warnings: "+strings.Join(errorFormats, ", "), "FORMAT")
 	getopt.SetParameters("[FORMAT OPTIONS] [SOURCE] [...]")
 
 	if err := getopt.Getopt(func(o getopt.Option) bool {
@@ -104,7 +508,7 @@ func main() {
 			f, ok := formatters[format]
 			if !ok {
 				fmt.Fprintf(os.Stderr, "%s: invalid format.  Choices are %s\n", format, strings.Join(formats, ", "))
-				stop(1)
+				stop(exitUsage)
 			}
 			if f.flags != nil {
 				f.flags.VisitAll(func(o getopt.Option) {
@@ -116,28 +520,38 @@ func main() {
 	}); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		getopt.PrintUsage(os.Stderr)
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 
 	if traceP != "" {
 		fp, err := os.Create(traceP)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
+			os.Exit(exitFailure)
 		}
 		trace.Start(fp)
-		stop = func(c int) { trace.Stop(); os.Exit(c) }
+		exit := stop
+		stop = func(c int) { trace.Stop(); exit(c) }
 		defer func() { trace.Stop() }()
 	}
 
+	if parseOptions.Debug {
+		parseOptions.Logger = yang.NewLogger(os.Stderr, yang.LevelDebug)
+	}
+
 	if help {
 		getopt.CommandLine.PrintUsage(os.Stderr)
 		fmt.Fprintf(os.Stderr, `
-SOURCE may be a module name or a .yang file.
+SOURCE may be a module name or a .yang or .yin file.
 
 Formats:
 `)
-		for _, fn := range formats {
+		// With --format, only that format is described.
+		helpFormats := formats
+		if getopt.IsSet("format") {
+			helpFormats = []string{format}
+		}
+		for _, fn := range helpFormats {
 			f := formatters[fn]
 			fmt.Fprintf(os.Stderr, "    %s - %s\n", f.name, f.help)
 			if f.flags != nil {
@@ -145,16 +559,105 @@ Formats:
 			}
 			fmt.Fprintln(os.Stderr)
 		}
-		stop(0)
+		var names []string
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintf(os.Stderr, "Commands (use \"goyang COMMAND --help\" for their options):\n")
+		for _, name := range names {
+			fmt.Fprintf(os.Stderr, "    %s - %s\n", name, commands[name].help)
+		}
+		fmt.Fprintf(os.Stderr, `
+Exit status:
+    0 - success
+    1 - the modules have errors, or a command found errors
+    2 - invalid flags or arguments
+    3 - a source could not be found, read, or parsed
+    4 - a command found only warnings
+    5 - another failure, such as writing the output
+`)
+		stop(exitOK)
 	}
 
-	for _, path := range paths {
-		expanded, err := yang.PathsWithModules(path)
+	if completionShell != "" {
+		if err := newCompletion(getopt.CommandLine, formats).write(os.Stdout, completionShell); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			stop(exitUsage)
+		}
+		stop(exitOK)
+	}
+
+	var cfg config
+	if configFile != "" {
+		c, err := readConfig(configFile)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
-			continue
+			stop(exitUsage)
+		}
+		cfg = *c
+	}
+	// Lists in the configuration file are extended by the command line
+	// while other values are overridden by it.
+	paths = append(cfg.Path, paths...)
+	deviations = append(cfg.DeviationModules, deviations...)
+	enableFeatures = append(cfg.EnableFeatures, enableFeatures...)
+	disableFeatures = append(cfg.DisableFeatures, disableFeatures...)
+	includeModules = append(cfg.IncludeModules, includeModules...)
+	excludeModules = append(cfg.ExcludeModules, excludeModules...)
+	if format == "" {
+		format = cfg.Format
+	}
+	if !getopt.IsSet("output-file") && !getopt.IsSet("output-dir") {
+		outputFile, outputDir = cfg.OutputFile, cfg.OutputDir
+	}
+
+	for _, path := range paths {
+		addPath(path)
+	}
+
+	if jobs < 1 {
+		fmt.Fprintf(os.Stderr, "--jobs must be at least 1, not %d\n", jobs)
+		stop(exitUsage)
+	}
+
+	if strict {
+		if parseOptions.IgnoreSubmoduleCircularDependencies {
+			fmt.Fprintln(os.Stderr, "--ignore-circdep may not be used with --strict")
+			stop(exitUsage)
+		}
+		if parseOptions.Lenient {
+			fmt.Fprintln(os.Stderr, "--lenient may not be used with --strict")
+			stop(exitUsage)
+		}
+		parseOptions.StrictYangVersion = true
+		parseOptions.StrictConformance = true
+		parseOptions.WarningsAsErrors = true
+	}
+
+	if outputFile != "" && outputDir != "" {
+		fmt.Fprintln(os.Stderr, "only one of --output-file and --output-dir may be given")
+		stop(exitUsage)
+	}
+
+	if err := checkPatterns(append(append([]string{}, includeModules...), excludeModules...)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		stop(exitUsage)
+	}
+
+	validFormat := false
+	for _, f := range errorFormats {
+		validFormat = validFormat || f == errorFormat
+	}
+	if !validFormat {
+		fmt.Fprintf(os.Stderr, "%s: invalid error format.  Choices are %s\n", errorFormat, strings.Join(errorFormats, ", "))
+		stop(exitUsage)
+	}
+	if errorFormat == "sarif" {
+		if watchMode {
+			fmt.Fprintln(os.Stderr, "--error-format sarif may not be used with --watch")
+			stop(exitUsage)
 		}
-		yang.AddPath(expanded...)
 	}
 
 	if format == "" {
@@ -162,34 +665,102 @@ Formats:
 	}
 	if _, ok := formatters[format]; !ok {
 		fmt.Fprintf(os.Stderr, "%s: invalid format.  Choices are %s\n", format, strings.Join(formats, ", "))
-		stop(1)
+		stop(exitUsage)
 
 	}
 
 	files := getopt.Args()
+	if len(files) == 0 {
+		files = cfg.Modules
+	}
 
-	ms := yang.NewModules()
+	g := &generator{
+		files:           files,
+		jobs:            jobs,
+		deviations:      deviations,
+		enableFeatures:  enableFeatures,
+		disableFeatures: disableFeatures,
+		includeModules:  includeModules,
+		excludeModules:  excludeModules,
+		verbose:         verbose,
+		format:          format,
+		outputFile:      outputFile,
+		outputDir:       outputDir,
+	}
+	if watchMode {
+		if len(files) == 0 {
+			fmt.Fprintln(os.Stderr, "--watch requires at least one SOURCE")
+			stop(exitUsage)
+		}
+		g.skipUnchanged = true
+		watch(g, append([]string{configFile}, files...))
+		return
+	}
 
 	if len(files) == 0 {
+		ms := newModules()
 		data, err := ioutil.ReadAll(os.Stdin)
 		if err == nil {
 			err = ms.Parse(string(data), "<STDIN>")
 		}
 		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			stop(1)
+			report([]error{err})
+			stop(exitParse)
 		}
+		g.ms = ms
 	}
+	stop(g.generate())
+}
 
-	for _, name := range files {
-		if err := ms.Read(name); err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			continue
-		}
+// A generator reads and processes the source modules and writes the output
+// of the selected formatter.
+type generator struct {
+	ms              *yang.Modules // if not nil, the Modules to read the files into
+	files           []string
+	jobs            int
+	deviations      []string
+	enableFeatures  []string
+	disableFeatures []string
+	includeModules  []string
+	excludeModules  []string
+	verbose         bool
+	format          string
+	outputFile      string
+	outputDir       string
+	skipUnchanged   bool     // do not rewrite output files whose contents are unchanged
+	searchPath      []string // search path of the Modules of the last generate, see watch
+}
+
+// generate reads and processes the source modules and writes the output.
+// It returns the exit status.  Errors are reported and an exit status other
+// than exitOK is returned if a module cannot be read or processed or the
+// output cannot be written.
+func (g *generator) generate() int {
+	ms := g.ms
+	if ms == nil {
+		ms = newModules()
+	}
+	defer func() { g.searchPath = ms.Path() }()
+
+	if !readFiles(ms, g.files, g.jobs) {
+		return exitParse
+	}
+
+	deviationModules := readDeviationModules(ms, g.deviations)
+	if len(g.enableFeatures) > 0 || len(g.disableFeatures) > 0 {
+		ms.AddTransform(yang.PruneFeatures(g.enableFeatures, g.disableFeatures))
 	}
 
-	// Process the read files, exiting if any errors were found.
-	exitIfError(ms.Process())
+	// Process the read files, failing if any errors were found.
+	errs := ms.Process()
+	report(ms.Warnings())
+	if len(errs) > 0 {
+		report(errs)
+		return exitStatus(errs)
+	}
+	if g.verbose && !quiet {
+		reportSources(ms)
+	}
 
 	// Keep track of the top level modules we read in.
 	// Those are the only modules we want to print below.
@@ -197,6 +768,9 @@ Formats:
 	var names []string
 
 	for _, m := range ms.Modules {
+		if deviationModules[m] || !selected(m.Name, g.includeModules, g.excludeModules) {
+			continue
+		}
 		if mods[m.Name] == nil {
 			mods[m.Name] = m
 			names = append(names, m.Name)
@@ -208,5 +782,301 @@ Formats:
 		entries[x] = yang.ToEntry(mods[n])
 	}
 
-	formatters[format].f(os.Stdout, entries)
+	f := formatters[g.format].f
+	switch {
+	case g.outputDir != "":
+		if err := os.MkdirAll(g.outputDir, 0755); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		errs := make([]error, len(entries))
+		parallel(len(entries), g.jobs, func(i int) {
+			e := entries[i]
+			name := filepath.Join(g.outputDir, e.Name+"."+g.format)
+			errs[i] = g.writeFile(name, func(w io.Writer) error { return f(w, []*yang.Entry{e}) })
+		})
+		failed := false
+		for _, err := range errs {
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				failed = true
+			}
+		}
+		if failed {
+			return exitFailure
+		}
+	case g.outputFile != "":
+		if err := g.writeFile(g.outputFile, func(w io.Writer) error { return f(w, entries) }); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+	default:
+		// Output is buffered as formatters write many small pieces.
+		w := bufio.NewWriter(os.Stdout)
+		err := f(w, entries)
+		if ferr := w.Flush(); err == nil {
+			err = ferr
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+	}
+	return exitOK
+}
+
+// writeFile writes the output written by write to the file name using
+// writeFile.  If g.skipUnchanged is set and name already contains the
+// output, name is not rewritten.
+func (g *generator) writeFile(name string, write func(io.Writer) error) error {
+	if !g.skipUnchanged {
+		return writeFile(name, write)
+	}
+	var b bytes.Buffer
+	if err := write(&b); err != nil {
+		return err
+	}
+	if old, err := ioutil.ReadFile(name); err == nil && bytes.Equal(old, b.Bytes()) {
+		return nil
+	}
+	return writeFile(name, func(w io.Writer) error {
+		_, err := w.Write(b.Bytes())
+		return err
+	})
+}
+
+// writeFile atomically replaces the file name with the output written by
+// write.  The output is written to a temporary file in the same directory
+// that is renamed to name once it has been written.  If an error occurs,
+// including an error returned by write, or write panics, the temporary file
+// is removed and name is left unchanged.
+func writeFile(name string, write func(io.Writer) error) error {
+	dir, base := filepath.Split(name)
+	if dir == "" {
+		dir = "."
+	}
+	fp, err := ioutil.TempFile(dir, "."+base+".*")
+	if err != nil {
+		return err
+	}
+	done := false
+	defer func() {
+		if !done {
+			fp.Close()
+			os.Remove(fp.Name())
+		}
+	}()
+	// Output is buffered as formatters write many small pieces.
+	w := bufio.NewWriter(fp)
+	if err := write(w); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if err := fp.Chmod(0644); err != nil {
+		return err
+	}
+	if err := fp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(fp.Name(), name); err != nil {
+		return err
+	}
+	done = true
+	return nil
+}
+
+// isSourceFile reports whether name is the name of a module's source file,
+// written in either YANG or YIN.
+func isSourceFile(name string) bool {
+	return strings.HasSuffix(name, ".yang") || strings.HasSuffix(name, ".yin")
+}
+
+// readDeviationModules reads the modules named by names into ms and returns
+// the modules that were read.  A module without any deviation statements is
+// reported, as it was most likely named by mistake.
+func readDeviationModules(ms *yang.Modules, names []string) map[*yang.Module]bool {
+	read := map[*yang.Module]bool{}
+	for _, name := range names {
+		before := map[*yang.Module]bool{}
+		for _, m := range ms.Modules {
+			before[m] = true
+		}
+		if err := ms.Read(name); err != nil {
+			report([]error{err})
+			continue
+		}
+		for _, m := range ms.Modules {
+			if before[m] {
+				continue
+			}
+			read[m] = true
+			if len(m.Deviation) == 0 && !quiet {
+				fmt.Fprintf(os.Stderr, "warning: %s: module %s has no deviations\n", name, m.Name)
+			}
+		}
+	}
+	return read
+}
+
+// checkPatterns returns an error if one of patterns is not a valid module
+// name pattern.
+func checkPatterns(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("%s: invalid module pattern: %v", p, err)
+		}
+	}
+	return nil
+}
+
+// selected reports whether the module named name is to be displayed, i.e.,
+// it matches one of the patterns of include, if there are any, and none of
+// the patterns of exclude.  The patterns have already been checked.
+func selected(name string, include, exclude []string) bool {
+	match := func(patterns []string) bool {
+		for _, p := range patterns {
+			if ok, _ := path.Match(p, name); ok {
+				return true
+			}
+		}
+		return false
+	}
+	if len(include) > 0 && !match(include) {
+		return false
+	}
+	return !match(exclude)
+}
+
+// addPath adds the directories named by the --path value path to the search
+// path.  path may be a glob pattern and may end in /..., see the package
+// comment.
+func addPath(path string) {
+	recurse := strings.HasSuffix(path, "/...")
+	dirs := []string{strings.TrimSuffix(path, "/...")}
+	if strings.ContainsAny(dirs[0], "*?[") {
+		var err error
+		if dirs, err = filepath.Glob(dirs[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			return
+		}
+		if len(dirs) == 0 {
+			if !quiet {
+				fmt.Fprintf(os.Stderr, "warning: %s: no such directory\n", path)
+			}
+		}
+	}
+	for _, dir := range dirs {
+		if recurse {
+			searchPath = append(searchPath, dir+"/...")
+			continue
+		}
+		expanded, err := yang.PathsWithModules(dir)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+		searchPath = append(searchPath, expanded...)
+	}
+}
+
+// reportSources writes the file that satisfied each import and include of
+// the modules and submodules of ms to standard error.
+func reportSources(ms *yang.Modules) {
+	seen := map[*yang.Module]bool{}
+	var mods []*yang.Module
+	for _, mm := range []map[string]*yang.Module{ms.Modules, ms.SubModules} {
+		for _, m := range mm {
+			if !seen[m] {
+				seen[m] = true
+				mods = append(mods, m)
+			}
+		}
+	}
+	sort.Slice(mods, func(i, j int) bool { return mods[i].FullName() < mods[j].FullName() })
+	for _, m := range mods {
+		for _, i := range m.Import {
+			if i.Module != nil {
+				fmt.Fprintf(os.Stderr, "%s: import %s: %s\n", m.FullName(), i.Name, sourceFile(i.Module))
+			}
+		}
+		for _, i := range m.Include {
+			if i.Module != nil {
+				fmt.Fprintf(os.Stderr, "%s: include %s: %s\n", m.FullName(), i.Name, sourceFile(i.Module))
+			}
+		}
+	}
+}
+
+// sourceFile returns the name of the file n was read from.
+func sourceFile(n yang.Node) string {
+	loc := yang.Source(n)
+	// Strip the line and column from file:line:col.
+	for i := 0; i < 2; i++ {
+		if x := strings.LastIndex(loc, ":"); x >= 0 {
+			loc = loc[:x]
+		}
+	}
+	return loc
+}
+
+// readFiles reads the modules named by names into ms.  Up to jobs files are
+// read and parsed at once, but they are added to ms in the order of names
+// so the results do not depend on jobs.  Errors are reported, up to
+// --max-errors of them.  False is returned if there were any errors.
+func readFiles(ms *yang.Modules, names []string, jobs int) bool {
+	errs := ms.ReadAll(names, jobs)
+	if len(errs) > 0 {
+		report(errs)
+		return false
+	}
+	return true
+}
+
+// parallel calls fn(i) for each i from 0 to n-1 using up to jobs
+// goroutines, and returns once all the calls have returned.
+func parallel(n, jobs int, fn func(i int)) {
+	if jobs > n {
+		jobs = n
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for j := 0; j < jobs; j++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
+
+// readModules reads and processes the modules named by names for a
+// subcommand.  Errors are reported and an exit status other than exitOK is
+// returned if any module cannot be read or processed.
+func readModules(names []string) (*yang.Modules, int) {
+	ms := newModules()
+	var errs []error
+	for _, name := range names {
+		if err := ms.Read(name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		report(errs)
+		return nil, exitParse
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		report(errs)
+		return nil, exitStatus(errs)
+	}
+	report(ms.Warnings())
+	return ms, exitOK
 }
@@ -0,0 +1,266 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+// dirFiles returns the names of the files in dir.
+func dirFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, fi := range fis {
+		names = append(names, fi.Name())
+	}
+	return names
+}
+
+// writeString returns a function that writes s, for writeFile.
+func writeString(s string) func(io.Writer) error {
+	return func(w io.Writer) error {
+		_, err := io.WriteString(w, s)
+		return err
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "writefile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "out.txt")
+
+	if err := writeFile(name, writeString("old\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The output is not written when the generator panics part way
+	// through.
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("writeFile did not panic")
+			}
+		}()
+		writeFile(name, func(w io.Writer) error {
+			io.WriteString(w, "new\n")
+			panic("generation failed")
+		})
+	}()
+
+	// Nor when the generator fails.
+	if err := writeFile(name, func(w io.Writer) error {
+		io.WriteString(w, "new\n")
+		return errors.New("generation failed")
+	}); err == nil {
+		t.Errorf("writeFile with a failing generator succeeded, want error")
+	}
+
+	// Nor when the file cannot be renamed into place.
+	sub := filepath.Join(dir, "sub")
+	if err := os.MkdirAll(filepath.Join(sub, "x"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(sub, writeString("new\n")); err == nil {
+		t.Errorf("writeFile(%s) succeeded, want error", sub)
+	}
+
+	got, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "old\n" {
+		t.Errorf("got %q, want %q", got, "old\n")
+	}
+	if diff := cmp.Diff([]string{"out.txt", "sub"}, dirFiles(t, dir)); diff != "" {
+		t.Errorf("files left in %s (-want, +got):\n%s", dir, diff)
+	}
+
+	if err := writeFile(name, writeString("new\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ioutil.ReadFile(name); err != nil || string(got) != "new\n" {
+		t.Errorf("got %q, %v, want %q", got, err, "new\n")
+	}
+	fi, err := os.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fi.Mode().Perm(), os.FileMode(0644); got != want {
+		t.Errorf("got mode %v, want %v", got, want)
+	}
+}
+
+// writeChain writes n modules to dir, each of which imports, uses the
+// types of, and augments the one before it, and returns their file names.
+func writeChain(t *testing.T, dir string, n int) []string {
+	t.Helper()
+	var files []string
+	for i := 0; i < n; i++ {
+		m := fmt.Sprintf(`module m%02d {
+  prefix "m%02d";
+  namespace "urn:m%02d";
+  typedef t { type string { length "1..%d"; } }
+  grouping g { leaf name { type t; } }
+  container c%02d { uses g; list l { key "k"; leaf k { type uint32; } } }
+`, i, i, i, i+1, i)
+		if i > 0 {
+			m += fmt.Sprintf(`  import m%02d { prefix "p"; }
+  leaf ref { type p:t; }
+  augment /p:c%02d { container from-m%02d { leaf v { type t; } } }
+`, i-1, i-1, i)
+		}
+		m += "}\n"
+		name := filepath.Join(dir, fmt.Sprintf("m%02d.yang", i))
+		if err := ioutil.WriteFile(name, []byte(m), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, name)
+	}
+	return files
+}
+
+func TestGenerateJobs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "src")
+	if err := os.Mkdir(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := writeChain(t, src, 16)
+
+	for _, format := range []string{"tree", "json"} {
+		// output generates the output with jobs, both to a single file
+		// and to a directory, and returns the contents of the files
+		// written.
+		output := func(jobs int) map[string]string {
+			out := filepath.Join(dir, fmt.Sprintf("%s-%d", format, jobs))
+			if err := os.Mkdir(out, 0755); err != nil {
+				t.Fatal(err)
+			}
+			g := &generator{files: files, jobs: jobs, format: format, outputFile: filepath.Join(out, "all")}
+			if status := g.generate(); status != exitOK {
+				t.Fatalf("%s: jobs %d: got exit status %d", format, jobs, status)
+			}
+			g = &generator{files: files, jobs: jobs, format: format, outputDir: filepath.Join(out, "dir")}
+			if status := g.generate(); status != exitOK {
+				t.Fatalf("%s: jobs %d: got exit status %d with an output directory", format, jobs, status)
+			}
+			got := map[string]string{}
+			for _, name := range append([]string{"all"}, dirFiles(t, filepath.Join(out, "dir"))...) {
+				if name != "all" {
+					name = filepath.Join("dir", name)
+				}
+				data, err := ioutil.ReadFile(filepath.Join(out, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				got[name] = string(data)
+			}
+			return got
+		}
+		want := output(1)
+		if len(want) != 17 {
+			t.Fatalf("%s: got %d files, want 17", format, len(want))
+		}
+		if diff := cmp.Diff(want, output(8)); diff != "" {
+			t.Errorf("%s: output with jobs 8 differs from jobs 1 (-jobs 1, +jobs 8):\n%s", format, diff)
+		}
+	}
+}
+
+// errWriter is an io.Writer that always fails.
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) { return 0, errors.New("write failed") }
+
+func TestFormatterError(t *testing.T) {
+	ms := yang.NewModulesWithOptions(yang.Options{})
+	if err := ms.Parse(`module a { prefix "a"; namespace "urn:a"; leaf l { type string; } }`, "a.yang"); err != nil {
+		t.Fatal(err)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	entries := []*yang.Entry{yang.ToEntry(ms.Modules["a"])}
+	// The formatters that can fail return their error rather than exiting.
+	for _, name := range []string{"json", "yin"} {
+		if err := formatters[name].f(errWriter{}, entries); err == nil {
+			t.Errorf("%s: got no error writing to a failing writer", name)
+		}
+	}
+}
+
+func TestLimitReport(t *testing.T) {
+	defer func(o yang.Options, q bool) {
+		parseOptions, quiet = o, q
+		reportedErrors, droppedErrors = 0, 0
+	}(parseOptions, quiet)
+	parseOptions.MaxErrors = 2
+	quiet = false
+
+	ms := yang.NewModulesWithOptions(parseOptions)
+	if err := ms.Parse(`module bad {
+  prefix "b";
+  namespace "urn:b";
+  leaf a { type t1; }
+  leaf b { type t2; }
+  leaf c { type t3; }
+  leaf d { type t4; }
+}`, "bad.yang"); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	add := func(errs []error) {
+		for _, err := range errs {
+			got = append(got, err.Error())
+		}
+	}
+	// The errors dropped by Process and by limitReport are counted by
+	// the single error that ends the report.
+	add(limitReport(ms.Process()))
+	add(limitReport([]error{errors.New("c.yang:1:1: another error"), &yang.Error{Severity: yang.SeverityWarning, Msg: "a warning"}}))
+	add(droppedReport())
+	want := []string{
+		"bad.yang:4:12: unknown type: b:t1",
+		"bad.yang:5:12: unknown type: b:t2",
+		"a warning",
+		"at least 3 more errors were not reported, only the first 2 are reported",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("reported errors (-want, +got):\n%s", diff)
+	}
+	if errs := droppedReport(); errs != nil {
+		t.Errorf("droppedReport did not reset the count, got %v", errs)
+	}
+}
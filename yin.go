@@ -0,0 +1,42 @@
+// Copyright 2020 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io"
+
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+func init() {
+	register(&formatter{
+		name: "yin",
+		f:    doYIN,
+		help: "display each module as YIN, the XML form of YANG",
+	})
+}
+
+func doYIN(w io.Writer, entries []*yang.Entry) error {
+	for _, e := range entries {
+		m, ok := e.Node.(*yang.Module)
+		if !ok {
+			continue
+		}
+		if err := m.WriteYIN(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}